@@ -4,10 +4,12 @@ import "github.com/flipped-aurora/gin-vue-admin/server/service"
 
 type ApiGroup struct {
 	CustomerApi
+	OssMigrationApi
 	FileUploadAndDownloadApi
 }
 
 var (
 	customerService              = service.ServiceGroupApp.ExampleServiceGroup.CustomerService
+	ossMigrationService          = service.ServiceGroupApp.ExampleServiceGroup.OssMigrationService
 	fileUploadAndDownloadService = service.ServiceGroupApp.ExampleServiceGroup.FileUploadAndDownloadService
 )
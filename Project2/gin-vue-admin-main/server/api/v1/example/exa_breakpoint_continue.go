@@ -55,22 +55,9 @@ func (b *FileUploadAndDownloadApi) BreakpointContinue(c *gin.Context) {
 		response.FailWithMessage("检查md5失败", c)
 		return
 	}
-	file, err := fileUploadAndDownloadService.FindOrCreateFile(fileMd5, fileName, chunkTotal)
-	if err != nil {
-		global.GVA_LOG.Error("查找或创建记录失败!", zap.Error(err))
-		response.FailWithMessage("查找或创建记录失败", c)
-		return
-	}
-	pathC, err := utils.BreakPointContinue(cen, fileName, chunkNumber, chunkTotal, fileMd5)
-	if err != nil {
+	if err = fileUploadAndDownloadService.SaveBreakpointChunk(fileMd5, fileName, chunkNumber, chunkTotal, cen); err != nil {
 		global.GVA_LOG.Error("断点续传失败!", zap.Error(err))
-		response.FailWithMessage("断点续传失败", c)
-		return
-	}
-
-	if err = fileUploadAndDownloadService.CreateFileChunk(file.ID, pathC, chunkNumber); err != nil {
-		global.GVA_LOG.Error("创建文件记录失败!", zap.Error(err))
-		response.FailWithMessage("创建文件记录失败", c)
+		response.FailWithMessage("断点续传失败:"+err.Error(), c)
 		return
 	}
 	response.OkWithMessage("切片创建成功", c)
@@ -110,10 +97,10 @@ func (b *FileUploadAndDownloadApi) FindFile(c *gin.Context) {
 func (b *FileUploadAndDownloadApi) BreakpointContinueFinish(c *gin.Context) {
 	fileMd5 := c.Query("fileMd5")
 	fileName := c.Query("fileName")
-	filePath, err := utils.MakeFile(fileName, fileMd5)
+	filePath, err := fileUploadAndDownloadService.FinishBreakpoint(fileMd5, fileName, utils.GetUserID(c), utils.GetUserAuthorityId(c))
 	if err != nil {
 		global.GVA_LOG.Error("文件创建失败!", zap.Error(err))
-		response.FailWithDetailed(exampleRes.FilePathResponse{FilePath: filePath}, "文件创建失败", c)
+		response.FailWithDetailed(exampleRes.FilePathResponse{FilePath: filePath}, "文件创建失败:"+err.Error(), c)
 	} else {
 		response.OkWithDetailed(exampleRes.FilePathResponse{FilePath: filePath}, "文件创建成功", c)
 	}
@@ -135,14 +122,9 @@ func (b *FileUploadAndDownloadApi) RemoveChunk(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	err = utils.RemoveChunk(file.FileMd5)
+	err = fileUploadAndDownloadService.RemoveBreakpointChunks(file.FileMd5)
 	if err != nil {
 		global.GVA_LOG.Error("缓存切片删除失败!", zap.Error(err))
-		return
-	}
-	err = fileUploadAndDownloadService.DeleteFileChunk(file.FileMd5, file.FilePath)
-	if err != nil {
-		global.GVA_LOG.Error(err.Error(), zap.Error(err))
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
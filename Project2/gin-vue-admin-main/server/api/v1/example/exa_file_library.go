@@ -0,0 +1,238 @@
+package example
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	exampleReq "github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SearchFile
+// @Tags      ExaFileUploadAndDownload
+// @Summary   媒体库搜索文件
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      exampleReq.ExaFileSearch                                true  "文件夹, 名称, 标签, 类型, 大小, 上传时间"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "媒体库搜索文件"
+// @Router    /fileUploadAndDownload/searchFile [post]
+func (b *FileUploadAndDownloadApi) SearchFile(c *gin.Context) {
+	var search exampleReq.ExaFileSearch
+	err := c.ShouldBindJSON(&search)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := fileUploadAndDownloadService.SearchFiles(search)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     search.Page,
+		PageSize: search.PageSize,
+	}, "获取成功", c)
+}
+
+// MoveFiles
+// @Tags      ExaFileUploadAndDownload
+// @Summary   批量移动文件到文件夹
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      exampleReq.MoveFiles           true  "文件ID列表与目标文件夹ID"
+// @Success   200   {object}  response.Response{msg=string}  "批量移动文件到文件夹"
+// @Router    /fileUploadAndDownload/moveFiles [post]
+func (b *FileUploadAndDownloadApi) MoveFiles(c *gin.Context) {
+	var move exampleReq.MoveFiles
+	err := c.ShouldBindJSON(&move)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = fileUploadAndDownloadService.MoveFiles(move); err != nil {
+		global.GVA_LOG.Error("移动失败!", zap.Error(err))
+		response.FailWithMessage("移动失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("移动成功", c)
+}
+
+// CreateFolder
+// @Tags      ExaFileUploadAndDownload
+// @Summary   创建文件夹
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      example.ExaFileFolder          true  "文件夹名称与父文件夹ID"
+// @Success   200   {object}  response.Response{msg=string}  "创建文件夹"
+// @Router    /fileUploadAndDownload/createFolder [post]
+func (b *FileUploadAndDownloadApi) CreateFolder(c *gin.Context) {
+	var folder example.ExaFileFolder
+	err := c.ShouldBindJSON(&folder)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	folder.UserID = utils.GetUserID(c)
+	if err = fileUploadAndDownloadService.CreateFolder(folder); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("创建成功", c)
+}
+
+// UpdateFolder
+// @Tags      ExaFileUploadAndDownload
+// @Summary   重命名或移动文件夹
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      example.ExaFileFolder          true  "文件夹ID, 名称与父文件夹ID"
+// @Success   200   {object}  response.Response{msg=string}  "重命名或移动文件夹"
+// @Router    /fileUploadAndDownload/updateFolder [post]
+func (b *FileUploadAndDownloadApi) UpdateFolder(c *gin.Context) {
+	var folder example.ExaFileFolder
+	err := c.ShouldBindJSON(&folder)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = fileUploadAndDownloadService.UpdateFolder(folder); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteFolder
+// @Tags      ExaFileUploadAndDownload
+// @Summary   删除空文件夹
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "文件夹ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除空文件夹"
+// @Router    /fileUploadAndDownload/deleteFolder [post]
+func (b *FileUploadAndDownloadApi) DeleteFolder(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = fileUploadAndDownloadService.DeleteFolder(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// GetFolderTree
+// @Tags      ExaFileUploadAndDownload
+// @Summary   获取文件夹树
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]example.ExaFileFolder,msg=string}  "获取文件夹树"
+// @Router    /fileUploadAndDownload/getFolderTree [get]
+func (b *FileUploadAndDownloadApi) GetFolderTree(c *gin.Context) {
+	tree, err := fileUploadAndDownloadService.GetFolderTree()
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(tree, "获取成功", c)
+}
+
+// GetUsage
+// @Tags      ExaFileUploadAndDownload
+// @Summary   获取当前用户的存储用量与配额
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=exampleRes.ExaFileUsage,msg=string}  "获取当前用户的存储用量与配额"
+// @Router    /fileUploadAndDownload/getUsage [get]
+func (b *FileUploadAndDownloadApi) GetUsage(c *gin.Context) {
+	usage, err := fileUploadAndDownloadService.GetUsage(utils.GetUserID(c), utils.GetUserAuthorityId(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(usage, "获取成功", c)
+}
+
+// SetQuota
+// @Tags      ExaFileUploadAndDownload
+// @Summary   设置用户或角色的存储配额
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      example.ExaFileQuota           true  "配额对象类型, 对象ID, 配额(MB)"
+// @Success   200   {object}  response.Response{msg=string}  "设置用户或角色的存储配额"
+// @Router    /fileUploadAndDownload/setQuota [post]
+func (b *FileUploadAndDownloadApi) SetQuota(c *gin.Context) {
+	var quota example.ExaFileQuota
+	err := c.ShouldBindJSON(&quota)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = fileUploadAndDownloadService.SetQuota(quota); err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// DeleteQuota
+// @Tags      ExaFileUploadAndDownload
+// @Summary   删除单独设置的存储配额
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "配额ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除单独设置的存储配额"
+// @Router    /fileUploadAndDownload/deleteQuota [post]
+func (b *FileUploadAndDownloadApi) DeleteQuota(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = fileUploadAndDownloadService.DeleteQuota(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败", c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// GetQuotaList
+// @Tags      ExaFileUploadAndDownload
+// @Summary   获取单独设置的存储配额列表
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]example.ExaFileQuota,msg=string}  "获取单独设置的存储配额列表"
+// @Router    /fileUploadAndDownload/getQuotaList [get]
+func (b *FileUploadAndDownloadApi) GetQuotaList(c *gin.Context) {
+	list, err := fileUploadAndDownloadService.GetQuotaList()
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
@@ -0,0 +1,181 @@
+package example
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	exampleReq "github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PresignUpload
+// @Tags      ExaFileUploadAndDownload
+// @Summary   申请直传地址
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      exampleReq.PresignUpload                                           true  "文件名, 大小, 类型, 文件夹, 是否分片"
+// @Success   200   {object}  response.Response{data=exampleRes.PresignUpload,msg=string}  "申请直传地址"
+// @Router    /fileUploadAndDownload/presignUpload [post]
+func (b *FileUploadAndDownloadApi) PresignUpload(c *gin.Context) {
+	var info exampleReq.PresignUpload
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	res, err := fileUploadAndDownloadService.PresignUpload(info, utils.GetUserID(c), utils.GetUserAuthorityId(c))
+	if err != nil {
+		global.GVA_LOG.Error("申请直传失败!", zap.Error(err))
+		response.FailWithMessage("申请直传失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(res, "申请成功", c)
+}
+
+// PresignParts
+// @Tags      ExaFileUploadAndDownload
+// @Summary   获取分片直传地址
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      exampleReq.PresignParts                                             true  "直传凭证与分片序号"
+// @Success   200   {object}  response.Response{data=[]exampleRes.PresignPart,msg=string}  "获取分片直传地址"
+// @Router    /fileUploadAndDownload/presignParts [post]
+func (b *FileUploadAndDownloadApi) PresignParts(c *gin.Context) {
+	var info exampleReq.PresignParts
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	parts, err := fileUploadAndDownloadService.PresignParts(info, utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("获取分片地址失败!", zap.Error(err))
+		response.FailWithMessage("获取分片地址失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(parts, "获取成功", c)
+}
+
+// CompletePresign
+// @Tags      ExaFileUploadAndDownload
+// @Summary   直传完成回调
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      exampleReq.PresignComplete                                                 true  "直传凭证, 文件信息, 分片列表"
+// @Success   200   {object}  response.Response{data=example.ExaFileUploadAndDownload,msg=string}  "直传完成回调"
+// @Router    /fileUploadAndDownload/presignComplete [post]
+func (b *FileUploadAndDownloadApi) CompletePresign(c *gin.Context) {
+	var info exampleReq.PresignComplete
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	file, err := fileUploadAndDownloadService.CompletePresign(info, utils.GetUserID(c), utils.GetUserAuthorityId(c))
+	if err != nil {
+		global.GVA_LOG.Error("上传失败!", zap.Error(err))
+		response.FailWithMessage("上传失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(file, "上传成功", c)
+}
+
+// AbortPresign
+// @Tags      ExaFileUploadAndDownload
+// @Summary   取消直传
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      exampleReq.PresignTicket       true  "直传凭证"
+// @Success   200   {object}  response.Response{msg=string}  "取消直传"
+// @Router    /fileUploadAndDownload/presignAbort [post]
+func (b *FileUploadAndDownloadApi) AbortPresign(c *gin.Context) {
+	var info exampleReq.PresignTicket
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = fileUploadAndDownloadService.AbortPresign(info, utils.GetUserID(c)); err != nil {
+		global.GVA_LOG.Error("取消失败!", zap.Error(err))
+		response.FailWithMessage("取消失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("取消成功", c)
+}
+
+// PresignDownload
+// @Tags      ExaFileUploadAndDownload
+// @Summary   获取签名下载地址
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                                          true  "文件ID"
+// @Success   200   {object}  response.Response{data=map[string]string,msg=string}  "获取签名下载地址"
+// @Router    /fileUploadAndDownload/presignDownload [post]
+func (b *FileUploadAndDownloadApi) PresignDownload(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	url, err := fileUploadAndDownloadService.PresignDownload(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(gin.H{"url": url}, "获取成功", c)
+}
+
+// PutLocalObject
+// @Tags      ExaFileUploadAndDownload
+// @Summary   本地存储签名直传 由签名代替登录鉴权
+// @accept    application/octet-stream
+// @Produce   application/json
+// @Param     key      query     string                         true  "对象key"
+// @Param     expires  query     int                            true  "过期时间戳"
+// @Param     sign     query     string                         true  "签名"
+// @Success   200      {object}  response.Response{msg=string}  "本地存储签名直传"
+// @Router    /fileUploadAndDownload/localObject [put]
+func (b *FileUploadAndDownloadApi) PutLocalObject(c *gin.Context) {
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+	body := c.Request.Body
+	if maxSize := global.GVA_CONFIG.FileLibrary.MaxSize; maxSize > 0 {
+		body = http.MaxBytesReader(c.Writer, body, maxSize*1024*1024)
+	}
+	err := fileUploadAndDownloadService.PutLocalObject(c.Query("key"), expires, c.Query("sign"), body)
+	if err != nil {
+		global.GVA_LOG.Error("上传失败!", zap.Error(err))
+		response.FailWithMessage("上传失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("上传成功", c)
+}
+
+// GetLocalObject
+// @Tags      ExaFileUploadAndDownload
+// @Summary   本地存储签名下载 由签名代替登录鉴权
+// @Produce   application/octet-stream
+// @Param     key      query  string  true  "对象key"
+// @Param     expires  query  int     true  "过期时间戳"
+// @Param     sign     query  string  true  "签名"
+// @Router    /fileUploadAndDownload/localObject [get]
+func (b *FileUploadAndDownloadApi) GetLocalObject(c *gin.Context) {
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+	path, err := fileUploadAndDownloadService.LocalObjectPath(c.Query("key"), expires, c.Query("sign"))
+	if err != nil {
+		c.String(http.StatusForbidden, err.Error())
+		return
+	}
+	c.File(path)
+}
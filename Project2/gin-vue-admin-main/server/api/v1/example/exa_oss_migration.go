@@ -0,0 +1,193 @@
+package example
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	exampleReq "github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type OssMigrationApi struct{}
+
+// CreateOssMigration
+// @Tags      ExaOssMigration
+// @Summary   创建存储迁移任务
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      exampleReq.CreateOssMigration                                  true  "源存储类型, 目标存储类型"
+// @Success   200   {object}  response.Response{data=example.ExaOssMigration,msg=string}  "创建存储迁移任务"
+// @Router    /ossMigration/createOssMigration [post]
+func (o *OssMigrationApi) CreateOssMigration(c *gin.Context) {
+	var info exampleReq.CreateOssMigration
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	m, err := ossMigrationService.CreateOssMigration(info)
+	if err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(m, "创建成功, 迁移已开始", c)
+}
+
+// ResumeOssMigration
+// @Tags      ExaOssMigration
+// @Summary   继续存储迁移任务
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "迁移任务ID"
+// @Success   200   {object}  response.Response{msg=string}  "继续存储迁移任务"
+// @Router    /ossMigration/resumeOssMigration [post]
+func (o *OssMigrationApi) ResumeOssMigration(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = ossMigrationService.ResumeOssMigration(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("继续迁移失败!", zap.Error(err))
+		response.FailWithMessage("继续迁移失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("迁移已继续", c)
+}
+
+// StopOssMigration
+// @Tags      ExaOssMigration
+// @Summary   停止存储迁移任务
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "迁移任务ID"
+// @Success   200   {object}  response.Response{msg=string}  "停止存储迁移任务"
+// @Router    /ossMigration/stopOssMigration [post]
+func (o *OssMigrationApi) StopOssMigration(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = ossMigrationService.StopOssMigration(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("停止失败!", zap.Error(err))
+		response.FailWithMessage("停止失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("停止成功", c)
+}
+
+// ApplyOssMigration
+// @Tags      ExaOssMigration
+// @Summary   应用存储迁移 将媒体库中的地址改写为目标存储
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "迁移任务ID"
+// @Success   200   {object}  response.Response{msg=string}  "应用存储迁移"
+// @Router    /ossMigration/applyOssMigration [post]
+func (o *OssMigrationApi) ApplyOssMigration(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = ossMigrationService.ApplyOssMigration(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("应用失败!", zap.Error(err))
+		response.FailWithMessage("应用失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("应用成功, 请将 system.oss-type 修改为目标存储", c)
+}
+
+// DeleteOssMigration
+// @Tags      ExaOssMigration
+// @Summary   删除存储迁移任务
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.GetById                true  "迁移任务ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除存储迁移任务"
+// @Router    /ossMigration/deleteOssMigration [delete]
+func (o *OssMigrationApi) DeleteOssMigration(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = ossMigrationService.DeleteOssMigration(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// GetOssMigrationList
+// @Tags      ExaOssMigration
+// @Summary   分页获取存储迁移任务列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     request.PageInfo                                        true  "页码, 每页大小"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取存储迁移任务列表"
+// @Router    /ossMigration/getOssMigrationList [get]
+func (o *OssMigrationApi) GetOssMigrationList(c *gin.Context) {
+	var pageInfo request.PageInfo
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := ossMigrationService.GetOssMigrationList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// GetOssMigrationItemList
+// @Tags      ExaOssMigration
+// @Summary   分页获取迁移对象列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     exampleReq.OssMigrationItemSearch                       true  "迁移任务ID, 状态, 页码, 每页大小"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "分页获取迁移对象列表"
+// @Router    /ossMigration/getOssMigrationItemList [get]
+func (o *OssMigrationApi) GetOssMigrationItemList(c *gin.Context) {
+	var info exampleReq.OssMigrationItemSearch
+	err := c.ShouldBindQuery(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := ossMigrationService.GetOssMigrationItemList(info)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     info.Page,
+		PageSize: info.PageSize,
+	}, "获取成功", c)
+}
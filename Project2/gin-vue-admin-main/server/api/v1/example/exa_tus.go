@@ -0,0 +1,179 @@
+package example
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	exampleService "github.com/flipped-aurora/gin-vue-admin/server/service/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// tus 1.0 协议 https://tus.io/protocols/resumable-upload 响应遵循协议约定的状态码与响应头 不使用统一的 response 结构
+
+const tusVersion = "1.0.0"
+
+func tusCheckVersion(c *gin.Context) bool {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		c.AbortWithStatus(http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+func tusFail(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, exampleService.ErrTusNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, exampleService.ErrTusOffsetMismatch):
+		status = http.StatusConflict
+	case errors.Is(err, exampleService.ErrTusTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, exampleService.ErrTusChecksumMismatch):
+		status = 460 // tus checksum 扩展约定的 Checksum Mismatch
+	}
+	c.String(status, err.Error())
+}
+
+func tusUploadHeaders(c *gin.Context, up example.ExaTusUpload) {
+	c.Header("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(up.Length, 10))
+	c.Header("Upload-Expires", up.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-store")
+	if up.FileID != 0 {
+		c.Header("Upload-File-Id", strconv.Itoa(int(up.FileID)))
+	}
+}
+
+// TusOptions
+// @Tags      ExaFileUploadAndDownload
+// @Summary   tus 服务能力查询
+// @Success   204
+// @Router    /fileUploadAndDownload/tus [options]
+func (b *FileUploadAndDownloadApi) TusOptions(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", "creation,termination,checksum,expiration")
+	c.Header("Tus-Checksum-Algorithm", exampleService.TusChecksumAlgorithms)
+	if maxSize := global.GVA_CONFIG.FileLibrary.MaxSize; maxSize > 0 {
+		c.Header("Tus-Max-Size", strconv.FormatInt(maxSize*1024*1024, 10))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// TusCreate
+// @Tags      ExaFileUploadAndDownload
+// @Summary   tus 创建上传
+// @Security  ApiKeyAuth
+// @Param     Tus-Resumable    header  string  true   "1.0.0"
+// @Param     Upload-Length    header  int     true   "文件总大小"
+// @Param     Upload-Metadata  header  string  false  "filename, filetype, folderId 值为base64"
+// @Success   201
+// @Router    /fileUploadAndDownload/tus [post]
+func (b *FileUploadAndDownloadApi) TusCreate(c *gin.Context) {
+	if !tusCheckVersion(c) {
+		return
+	}
+	// 未实现 creation-defer-length 扩展 创建时必须给出文件总大小
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		c.String(http.StatusBadRequest, "不支持 Upload-Defer-Length")
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Upload-Length 不合法")
+		return
+	}
+	up, err := fileUploadAndDownloadService.CreateTusUpload(length, c.GetHeader("Upload-Metadata"), utils.GetUserID(c), utils.GetUserAuthorityId(c))
+	if err != nil {
+		global.GVA_LOG.Error("创建上传失败!", zap.Error(err))
+		tusFail(c, err)
+		return
+	}
+	// 相对地址 由客户端基于创建地址解析 兼容前端代理前缀
+	c.Header("Location", "tus/"+up.UploadID)
+	tusUploadHeaders(c, up)
+	c.Status(http.StatusCreated)
+}
+
+// TusHead
+// @Tags      ExaFileUploadAndDownload
+// @Summary   tus 查询上传进度
+// @Security  ApiKeyAuth
+// @Param     Tus-Resumable  header  string  true  "1.0.0"
+// @Param     id             path    string  true  "上传ID"
+// @Success   200
+// @Router    /fileUploadAndDownload/tus/{id} [head]
+func (b *FileUploadAndDownloadApi) TusHead(c *gin.Context) {
+	if !tusCheckVersion(c) {
+		return
+	}
+	up, err := fileUploadAndDownloadService.GetTusUpload(c.Param("id"), utils.GetUserID(c))
+	if err != nil {
+		c.Header("Cache-Control", "no-store")
+		tusFail(c, err)
+		return
+	}
+	tusUploadHeaders(c, up)
+	c.Status(http.StatusOK)
+}
+
+// TusPatch
+// @Tags      ExaFileUploadAndDownload
+// @Summary   tus 追加上传数据
+// @Security  ApiKeyAuth
+// @accept    application/offset+octet-stream
+// @Param     Tus-Resumable    header  string  true   "1.0.0"
+// @Param     Upload-Offset    header  int     true   "本次数据的起始位置"
+// @Param     Upload-Checksum  header  string  false  "算法 base64(摘要)"
+// @Param     id               path    string  true   "上传ID"
+// @Success   204
+// @Router    /fileUploadAndDownload/tus/{id} [patch]
+func (b *FileUploadAndDownloadApi) TusPatch(c *gin.Context) {
+	if !tusCheckVersion(c) {
+		return
+	}
+	if c.ContentType() != "application/offset+octet-stream" {
+		c.String(http.StatusUnsupportedMediaType, "Content-Type 必须为 application/offset+octet-stream")
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.String(http.StatusBadRequest, "Upload-Offset 不合法")
+		return
+	}
+	up, err := fileUploadAndDownloadService.PatchTusUpload(c.Param("id"), offset, c.GetHeader("Upload-Checksum"), c.Request.Body, utils.GetUserID(c))
+	if err != nil {
+		global.GVA_LOG.Error("上传失败!", zap.String("uploadId", c.Param("id")), zap.Error(err))
+		tusFail(c, err)
+		return
+	}
+	tusUploadHeaders(c, up)
+	c.Status(http.StatusNoContent)
+}
+
+// TusDelete
+// @Tags      ExaFileUploadAndDownload
+// @Summary   tus 取消上传
+// @Security  ApiKeyAuth
+// @Param     Tus-Resumable  header  string  true  "1.0.0"
+// @Param     id             path    string  true  "上传ID"
+// @Success   204
+// @Router    /fileUploadAndDownload/tus/{id} [delete]
+func (b *FileUploadAndDownloadApi) TusDelete(c *gin.Context) {
+	if !tusCheckVersion(c) {
+		return
+	}
+	if err := fileUploadAndDownloadService.TerminateTusUpload(c.Param("id"), utils.GetUserID(c)); err != nil {
+		tusFail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,196 @@
+package example
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"github.com/gin-gonic/gin"
+)
+
+// tusTestRouter 注册 tus 路由 userID 为请求使用的登录用户 数据库与存储使用临时目录
+func tusTestRouter(t *testing.T, userID *uint) *gin.Engine {
+	t.Helper()
+	testdb.Open(t, "", &example.ExaFileUploadAndDownload{}, &example.ExaFileFolder{}, &example.ExaFileQuota{}, &example.ExaTusUpload{}, &example.ExaTusChunk{})
+	library, local, tus, system := global.GVA_CONFIG.FileLibrary, global.GVA_CONFIG.Local, global.GVA_CONFIG.Tus, global.GVA_CONFIG.System
+	t.Cleanup(func() {
+		global.GVA_CONFIG.FileLibrary, global.GVA_CONFIG.Local, global.GVA_CONFIG.Tus, global.GVA_CONFIG.System = library, local, tus, system
+	})
+	global.GVA_CONFIG.FileLibrary = config.FileLibrary{MaxSize: 1}
+	global.GVA_CONFIG.Local = config.Local{Path: "uploads/file", StorePath: t.TempDir()}
+	global.GVA_CONFIG.Tus = config.Tus{ChunkDir: t.TempDir()}
+	global.GVA_CONFIG.System.OssType, global.GVA_CONFIG.System.OssMirror = "local", ""
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("claims", &systemReq.CustomClaims{BaseClaims: systemReq.BaseClaims{ID: *userID, AuthorityId: 888}})
+	})
+	api := new(FileUploadAndDownloadApi)
+	group := router.Group("fileUploadAndDownload")
+	group.OPTIONS("tus", api.TusOptions)
+	group.POST("tus", api.TusCreate)
+	group.HEAD("tus/:id", api.TusHead)
+	group.PATCH("tus/:id", api.TusPatch)
+	group.DELETE("tus/:id", api.TusDelete)
+	return router
+}
+
+func tusRequest(router http.Handler, method, target string, headers map[string]string, body []byte) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, bytes.NewReader(body))
+	request.Header.Set("Tus-Resumable", tusVersion)
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func tusPatchHeaders(offset int, checksum string) map[string]string {
+	headers := map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": strconv.Itoa(offset)}
+	if checksum != "" {
+		headers["Upload-Checksum"] = checksum
+	}
+	return headers
+}
+
+func tusSha256(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestTusCreate(t *testing.T) {
+	userID := uint(1)
+	router := tusTestRouter(t, &userID)
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("a.txt"))
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "缺少 Upload-Length", headers: map[string]string{"Upload-Metadata": metadata}, status: http.StatusBadRequest},
+		{name: "不支持 Upload-Defer-Length", headers: map[string]string{"Upload-Defer-Length": "1", "Upload-Metadata": metadata}, status: http.StatusBadRequest},
+		{name: "Upload-Length 为0", headers: map[string]string{"Upload-Length": "0", "Upload-Metadata": metadata}, status: http.StatusBadRequest},
+		{name: "超过大小限制", headers: map[string]string{"Upload-Length": strconv.Itoa(2 * 1024 * 1024), "Upload-Metadata": metadata}, status: http.StatusRequestEntityTooLarge},
+		{name: "缺少文件名", headers: map[string]string{"Upload-Length": "5"}, status: http.StatusBadRequest},
+		{name: "版本不一致", headers: map[string]string{"Tus-Resumable": "0.2.0", "Upload-Length": "5", "Upload-Metadata": metadata}, status: http.StatusPreconditionFailed},
+		{name: "创建成功", headers: map[string]string{"Upload-Length": "5", "Upload-Metadata": metadata}, status: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := tusRequest(router, http.MethodPost, "/fileUploadAndDownload/tus", tt.headers, nil)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status == http.StatusCreated && (!strings.HasPrefix(w.Header().Get("Location"), "tus/") || w.Header().Get("Upload-Offset") != "0" || w.Header().Get("Upload-Length") != "5") {
+				t.Fatalf("unexpected headers: %v", w.Header())
+			}
+		})
+	}
+}
+
+func TestTusUpload(t *testing.T) {
+	userID := uint(1)
+	router := tusTestRouter(t, &userID)
+	content := []byte("hello tus world")
+	w := tusRequest(router, http.MethodPost, "/fileUploadAndDownload/tus", map[string]string{
+		"Upload-Length":   strconv.Itoa(len(content)),
+		"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte("a.txt")),
+	}, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", w.Code, w.Body.String())
+	}
+	target := "/fileUploadAndDownload/" + w.Header().Get("Location")
+
+	if w = tusRequest(router, http.MethodPatch, target, tusPatchHeaders(0, ""), content[:5]); w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "5" {
+		t.Fatalf("patch status = %d offset = %s: %s", w.Code, w.Header().Get("Upload-Offset"), w.Body.String())
+	}
+	if w = tusRequest(router, http.MethodHead, target, nil, nil); w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "5" || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("head status = %d headers = %v", w.Code, w.Header())
+	}
+	if w = tusRequest(router, http.MethodPatch, target, tusPatchHeaders(0, ""), content[:5]); w.Code != http.StatusConflict {
+		t.Fatalf("offset mismatch status = %d, want 409", w.Code)
+	}
+	if w = tusRequest(router, http.MethodPatch, target, tusPatchHeaders(5, tusSha256([]byte("other"))), content[5:10]); w.Code != 460 {
+		t.Fatalf("checksum mismatch status = %d, want 460", w.Code)
+	}
+	if w = tusRequest(router, http.MethodHead, target, nil, nil); w.Header().Get("Upload-Offset") != "5" {
+		t.Fatalf("data failing the checksum should be discarded, offset = %s", w.Header().Get("Upload-Offset"))
+	}
+	headers := tusPatchHeaders(5, "")
+	headers["Content-Type"] = "application/octet-stream"
+	if w = tusRequest(router, http.MethodPatch, target, headers, content[5:]); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("wrong content type status = %d, want 415", w.Code)
+	}
+
+	userID = 2
+	if w = tusRequest(router, http.MethodHead, target, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("upload of another user status = %d, want 404", w.Code)
+	}
+	userID = 1
+
+	w = tusRequest(router, http.MethodPatch, target, tusPatchHeaders(5, tusSha256(content[5:])), content[5:])
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != strconv.Itoa(len(content)) {
+		t.Fatalf("final patch status = %d headers = %v: %s", w.Code, w.Header(), w.Body.String())
+	}
+	fileID, _ := strconv.Atoi(w.Header().Get("Upload-File-Id"))
+	var file example.ExaFileUploadAndDownload
+	if err := global.GVA_DB.First(&file, fileID).Error; err != nil || file.Size != int64(len(content)) || file.UserID != 1 {
+		t.Fatalf("completed upload should be saved to the library: %+v %v", file, err)
+	}
+}
+
+func TestTusDeleteAndExpire(t *testing.T) {
+	userID := uint(1)
+	router := tusTestRouter(t, &userID)
+	create := func() string {
+		w := tusRequest(router, http.MethodPost, "/fileUploadAndDownload/tus", map[string]string{
+			"Upload-Length":   "10",
+			"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte("a.txt")),
+		}, nil)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create status = %d: %s", w.Code, w.Body.String())
+		}
+		return "/fileUploadAndDownload/" + w.Header().Get("Location")
+	}
+
+	target := create()
+	if w := tusRequest(router, http.MethodPatch, target, tusPatchHeaders(0, ""), []byte("hello")); w.Code != http.StatusNoContent {
+		t.Fatalf("patch status = %d", w.Code)
+	}
+	if w := tusRequest(router, http.MethodDelete, target, nil, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d", w.Code)
+	}
+	if w := tusRequest(router, http.MethodHead, target, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("deleted upload status = %d, want 404", w.Code)
+	}
+	if w := tusRequest(router, http.MethodDelete, target, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("deleting twice status = %d, want 404", w.Code)
+	}
+
+	target = create()
+	w := tusRequest(router, http.MethodHead, target, nil, nil)
+	if expires, err := http.ParseTime(w.Header().Get("Upload-Expires")); err != nil || expires.Before(time.Now()) {
+		t.Fatalf("Upload-Expires should be in the future: %q %v", w.Header().Get("Upload-Expires"), err)
+	}
+	global.GVA_DB.Model(&example.ExaTusUpload{}).Where("upload_id = ?", strings.TrimPrefix(target, "/fileUploadAndDownload/tus/")).
+		Update("expires_at", time.Now().Add(-time.Minute))
+	if w = tusRequest(router, http.MethodHead, target, nil, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expired upload status = %d, want 404", w.Code)
+	}
+	if w = tusRequest(router, http.MethodPatch, target, tusPatchHeaders(0, ""), []byte("hello")); w.Code != http.StatusNotFound {
+		t.Fatalf("patching an expired upload status = %d, want 404", w.Code)
+	}
+}
@@ -113,3 +113,83 @@ func (a *AutoCodeHistoryApi) GetList(c *gin.Context) {
 		PageSize: info.PageSize,
 	}, "获取成功", c)
 }
+
+// GetMigrationList
+// @Tags      AutoCode
+// @Summary   查询重新生成产生的数据库迁移
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.SysAutoCodeMigrationSearch                      true  "请求参数"
+// @Success   200   {object}  response.Response{data=response.PageResult,msg=string}  "查询数据库迁移,返回包括列表,总数,页码,每页数量"
+// @Router    /autoCode/getMigrationList [post]
+func (a *AutoCodeHistoryApi) GetMigrationList(c *gin.Context) {
+	var info request.SysAutoCodeMigrationSearch
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := autoCodeMigrationService.GetList(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     info.Page,
+		PageSize: info.PageSize,
+	}, "获取成功", c)
+}
+
+// ApplyMigration
+// @Tags      AutoCode
+// @Summary   执行数据库迁移
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      common.GetById                 true  "迁移ID"
+// @Success   200   {object}  response.Response{msg=string}  "执行数据库迁移"
+// @Router    /autoCode/applyMigration [post]
+func (a *AutoCodeHistoryApi) ApplyMigration(c *gin.Context) {
+	var info common.GetById
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = autoCodeMigrationService.Apply(c.Request.Context(), info.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("迁移失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithMessage("迁移成功", c)
+}
+
+// RevertMigration
+// @Tags      AutoCode
+// @Summary   回退数据库迁移
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      common.GetById                 true  "迁移ID"
+// @Success   200   {object}  response.Response{msg=string}  "回退数据库迁移"
+// @Router    /autoCode/revertMigration [post]
+func (a *AutoCodeHistoryApi) RevertMigration(c *gin.Context) {
+	var info common.GetById
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = autoCodeMigrationService.Revert(c.Request.Context(), info.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("回退失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithMessage("回退成功", c)
+}
@@ -0,0 +1,85 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type AutoCodeImportApi struct{}
+
+// Inspect
+// @Tags      AutoCodeImport
+// @Summary   检查数据库 推断批量导入的结构化信息、关联关系与字典
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.AutoCodeInspect                                            true  "业务库、数据库名、包与表 tables 为空时检查全部表"
+// @Success   200   {object}  response.Response{data=systemRes.AutoCodeInspect,msg=string}  "检查结果"
+// @Router    /autoCode/inspectTables [post]
+func (a *AutoCodeImportApi) Inspect(c *gin.Context) {
+	var info request.AutoCodeInspect
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if info.Package == "" {
+		response.FailWithMessage("Package为空!", c)
+		return
+	}
+	var result systemRes.AutoCodeInspect
+	result, err = autoCodeImportService.Inspect(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("检查失败!", zap.Error(err))
+		response.FailWithMessage("检查失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(result, "检查成功", c)
+}
+
+// Import
+// @Tags      AutoCodeImport
+// @Summary   批量导入数据库表生成代码 只记录一条历史 dryRun 为 true 时只返回变更
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.AutoCodeImport                            true  "检查结果中确认或修改后的结构化信息与字典"
+// @Success   200   {object}  response.Response{data=[]interface{},msg=string}  "导入成功 试运行时返回文件变更"
+// @Router    /autoCode/importTables [post]
+func (a *AutoCodeImportApi) Import(c *gin.Context) {
+	var info request.AutoCodeImport
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	for i := range info.Codes {
+		info.Codes[i].Package = info.Package
+		info.Codes[i].BusinessDB = info.BusinessDB
+		err = utils.Verify(info.Codes[i], utils.AutoCodeVerify)
+		if err == nil {
+			err = info.Codes[i].Pretreatment()
+		}
+		if err != nil {
+			response.FailWithMessage(errors.Wrapf(err, "[%s]", info.Codes[i].TableName).Error(), c)
+			return
+		}
+	}
+	changes, err := autoCodeImportService.Import(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("导入失败!", zap.Error(err))
+		response.FailWithMessage("导入失败:"+err.Error(), c)
+		return
+	}
+	if info.DryRun {
+		response.OkWithDetailed(changes, "试运行成功", c)
+		return
+	}
+	response.OkWithMessage("导入成功", c)
+}
@@ -98,3 +98,28 @@ func (a *AutoCodePackageApi) Templates(c *gin.Context) {
 	}
 	response.OkWithDetailed(data, "获取成功", c)
 }
+
+// SetPack
+// @Tags      AutoCodePackage
+// @Summary   设置包的默认模板包
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.SysAutoCodePackageSetPack  true  "包ID与模板包名 模板包名为空时使用内置模板"
+// @Success   200   {object}  response.Response{msg=string}      "设置成功"
+// @Router    /autoCode/setPackagePack [post]
+func (a *AutoCodePackageApi) SetPack(c *gin.Context) {
+	var info request.SysAutoCodePackageSetPack
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = autoCodePackageService.SetPack(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
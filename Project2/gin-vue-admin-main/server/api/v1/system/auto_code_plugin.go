@@ -2,8 +2,11 @@ package system
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -25,7 +28,7 @@ func (a *AutoCodePluginApi) Install(c *gin.Context) {
 		response.FailWithMessage(err.Error(), c)
 		return
 	}
-	web, server, err := autoCodePluginService.Install(header)
+	web, server, report, err := autoCodePluginService.Install(c.Request.Context(), header)
 	webStr := "web插件安装成功"
 	serverStr := "server插件安装成功"
 	if web == -1 {
@@ -35,9 +38,14 @@ func (a *AutoCodePluginApi) Install(c *gin.Context) {
 		serverStr = "server端插件未成功安装，请按照文档自行解压安装，如果为纯前端插件请忽略此条提示"
 	}
 	if err != nil {
-		response.FailWithMessage(err.Error(), c)
+		global.GVA_LOG.Error("安装插件失败!", zap.Error(err), zap.Any("report", report))
+		response.FailWithDetailed(report, err.Error(), c)
 		return
 	}
+	reportStr := "插件已签名, 签名密钥: " + report.KeyID
+	if !report.Signed {
+		reportStr = "插件未通过签名校验: " + strings.Join(report.Warnings, "; ")
+	}
 	response.OkWithData([]interface{}{
 		gin.H{
 			"code": web,
@@ -46,6 +54,11 @@ func (a *AutoCodePluginApi) Install(c *gin.Context) {
 		gin.H{
 			"code": server,
 			"msg":  serverStr,
+		},
+		gin.H{
+			"code":   1,
+			"msg":    reportStr,
+			"report": report,
 		}}, c)
 }
 
@@ -68,3 +81,45 @@ func (a *AutoCodePluginApi) Packaged(c *gin.Context) {
 	}
 	response.OkWithMessage(fmt.Sprintf("打包成功,文件路径为:%s", zipPath), c)
 }
+
+// GetInstalled
+// @Tags      AutoCodePlugin
+// @Summary   获取已安装插件
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200   {object}  response.Response{data=[]system.SysPlugin,msg=string}  "获取已安装插件成功"
+// @Router    /autoCode/getInstalledPlugins [get]
+func (a *AutoCodePluginApi) GetInstalled(c *gin.Context) {
+	list, err := autoCodePluginService.Installed(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// Uninstall
+// @Tags      AutoCodePlugin
+// @Summary   卸载插件
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.SysPluginUninstall  true  "插件名及是否删除接口、菜单、表"
+// @Success   200   {object}  response.Response{msg=string}  "卸载插件成功"
+// @Router    /autoCode/uninstallPlugin [post]
+func (a *AutoCodePluginApi) Uninstall(c *gin.Context) {
+	var info request.SysPluginUninstall
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = autoCodePluginService.Uninstall(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("卸载失败!", zap.Error(err))
+		response.FailWithMessage("卸载失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("卸载成功, 重启服务后生效", c)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -47,6 +48,42 @@ func (a *AutoCodeTemplateApi) Preview(c *gin.Context) {
 	}
 }
 
+// DryRun
+// @Tags      AutoCodeTemplate
+// @Summary   试运行代码生成 返回每个文件的 diff、已注入与冲突信息
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.AutoCode                                         true  "试运行创建代码"
+// @Success   200   {object}  response.Response{data=[]interface{},msg=string}  "文件变更 路径、状态、diff、冲突与警告"
+// @Router    /autoCode/dryRun [post]
+func (a *AutoCodeTemplateApi) DryRun(c *gin.Context) {
+	var info request.AutoCode
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(info, utils.AutoCodeVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = info.Pretreatment()
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	info.PackageT = utils.FirstUpper(info.Package)
+	changes, err := autoCodeTemplateService.DryRun(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("试运行失败!", zap.Error(err))
+		response.FailWithMessage("试运行失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(changes, "试运行成功", c)
+}
+
 // Create
 // @Tags      AutoCodeTemplate
 // @Summary   自动代码模板
@@ -82,6 +119,42 @@ func (a *AutoCodeTemplateApi) Create(c *gin.Context) {
 	}
 }
 
+// Regenerate
+// @Tags      AutoCodeTemplate
+// @Summary   结构变更后重新生成代码 合并本地修改并生成数据库迁移
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.AutoCodeRegenerate                                        true  "重新生成代码"
+// @Success   200   {object}  response.Response{data=systemRes.AutoCodeRegenerate,msg=string}  "重新生成结果"
+// @Router    /autoCode/regenerate [post]
+func (a *AutoCodeTemplateApi) Regenerate(c *gin.Context) {
+	var info request.AutoCodeRegenerate
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(info.AutoCode, utils.AutoCodeVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = info.Pretreatment()
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	var result systemRes.AutoCodeRegenerate
+	result, err = autoCodeTemplateService.Regenerate(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("重新生成失败!", zap.Error(err))
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(result, "重新生成成功", c)
+}
+
 // Create
 // @Tags      AddFunc
 // @Summary   增加方法
@@ -0,0 +1,112 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	common "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AutoCodeTemplatePackApi struct{}
+
+// List
+// @Tags      AutoCodeTemplatePack
+// @Summary   获取已安装的模板包
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200  {object}  response.Response{data=[]system.SysAutoCodeTemplatePack,msg=string}  "获取成功"
+// @Router    /autoCode/getPacks [get]
+func (a *AutoCodeTemplatePackApi) List(c *gin.Context) {
+	list, err := autoCodePackService.List(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// Install
+// @Tags      AutoCodeTemplatePack
+// @Summary   安装模板包
+// @Security  ApiKeyAuth
+// @accept    multipart/form-data
+// @Produce   application/json
+// @Param     pack   formData  file    true   "模板包压缩包"
+// @Param     force  formData  bool    false  "覆盖已安装的相同或更高版本"
+// @Success   200    {object}  response.Response{data=system.SysAutoCodeTemplatePack,msg=string}  "安装成功"
+// @Router    /autoCode/installPack [post]
+func (a *AutoCodeTemplatePackApi) Install(c *gin.Context) {
+	header, err := c.FormFile("pack")
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	entity, err := autoCodePackService.Install(c.Request.Context(), header, c.PostForm("force") == "true")
+	if err != nil {
+		global.GVA_LOG.Error("安装模板包失败!", zap.Error(err))
+		response.FailWithMessage("安装模板包失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(entity, "安装成功", c)
+}
+
+// Delete
+// @Tags      AutoCodeTemplatePack
+// @Summary   删除模板包
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      common.GetById                 true  "模板包ID"
+// @Success   200   {object}  response.Response{msg=string}  "删除成功"
+// @Router    /autoCode/delPack [post]
+func (a *AutoCodeTemplatePackApi) Delete(c *gin.Context) {
+	var info common.GetById
+	_ = c.ShouldBindJSON(&info)
+	err := autoCodePackService.Delete(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// Files
+// @Tags      AutoCodeTemplatePack
+// @Summary   预览生成时使用的模板与生成路径
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  body      request.AutoCode                                                    true  "自动代码信息 pack 为空时使用包的默认模板包"
+// @Success   200   {object}  response.Response{data=[]interface{},msg=string}  "模板、生成路径、来源与是否生成"
+// @Router    /autoCode/getPackFiles [post]
+func (a *AutoCodeTemplatePackApi) Files(c *gin.Context) {
+	var info request.AutoCode
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = utils.Verify(info, utils.AutoCodeVerify)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = info.Pretreatment()
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	info.PackageT = utils.FirstUpper(info.Package)
+	files, err := autoCodePackService.Files(c.Request.Context(), info)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(files, "获取成功", c)
+}
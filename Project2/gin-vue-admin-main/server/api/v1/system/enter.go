@@ -17,29 +17,46 @@ type ApiGroup struct {
 	DictionaryDetailApi
 	AuthorityBtnApi
 	SysExportTemplateApi
+	SysExportScheduleApi
+	SysJobApi
+	SysRetentionApi
+	OpenApiApi
+	PluginLifecycleApi
+	DBConnectionApi
 	AutoCodePluginApi
 	AutoCodePackageApi
 	AutoCodeHistoryApi
 	AutoCodeTemplateApi
+	AutoCodeTemplatePackApi
+	AutoCodeImportApi
 }
 
 var (
-	apiService              = service.ServiceGroupApp.SystemServiceGroup.ApiService
-	jwtService              = service.ServiceGroupApp.SystemServiceGroup.JwtService
-	menuService             = service.ServiceGroupApp.SystemServiceGroup.MenuService
-	userService             = service.ServiceGroupApp.SystemServiceGroup.UserService
-	initDBService           = service.ServiceGroupApp.SystemServiceGroup.InitDBService
-	casbinService           = service.ServiceGroupApp.SystemServiceGroup.CasbinService
-	baseMenuService         = service.ServiceGroupApp.SystemServiceGroup.BaseMenuService
-	authorityService        = service.ServiceGroupApp.SystemServiceGroup.AuthorityService
-	dictionaryService       = service.ServiceGroupApp.SystemServiceGroup.DictionaryService
-	authorityBtnService     = service.ServiceGroupApp.SystemServiceGroup.AuthorityBtnService
-	systemConfigService     = service.ServiceGroupApp.SystemServiceGroup.SystemConfigService
-	operationRecordService  = service.ServiceGroupApp.SystemServiceGroup.OperationRecordService
-	dictionaryDetailService = service.ServiceGroupApp.SystemServiceGroup.DictionaryDetailService
-	autoCodeService         = service.ServiceGroupApp.SystemServiceGroup.AutoCodeService
-	autoCodePluginService   = service.ServiceGroupApp.SystemServiceGroup.AutoCodePlugin
-	autoCodePackageService  = service.ServiceGroupApp.SystemServiceGroup.AutoCodePackage
-	autoCodeHistoryService  = service.ServiceGroupApp.SystemServiceGroup.AutoCodeHistory
-	autoCodeTemplateService = service.ServiceGroupApp.SystemServiceGroup.AutoCodeTemplate
+	apiService               = service.ServiceGroupApp.SystemServiceGroup.ApiService
+	jwtService               = service.ServiceGroupApp.SystemServiceGroup.JwtService
+	menuService              = service.ServiceGroupApp.SystemServiceGroup.MenuService
+	userService              = service.ServiceGroupApp.SystemServiceGroup.UserService
+	initDBService            = service.ServiceGroupApp.SystemServiceGroup.InitDBService
+	casbinService            = service.ServiceGroupApp.SystemServiceGroup.CasbinService
+	baseMenuService          = service.ServiceGroupApp.SystemServiceGroup.BaseMenuService
+	authorityService         = service.ServiceGroupApp.SystemServiceGroup.AuthorityService
+	dictionaryService        = service.ServiceGroupApp.SystemServiceGroup.DictionaryService
+	authorityBtnService      = service.ServiceGroupApp.SystemServiceGroup.AuthorityBtnService
+	systemConfigService      = service.ServiceGroupApp.SystemServiceGroup.SystemConfigService
+	operationRecordService   = service.ServiceGroupApp.SystemServiceGroup.OperationRecordService
+	dictionaryDetailService  = service.ServiceGroupApp.SystemServiceGroup.DictionaryDetailService
+	autoCodeService          = service.ServiceGroupApp.SystemServiceGroup.AutoCodeService
+	autoCodePluginService    = service.ServiceGroupApp.SystemServiceGroup.AutoCodePlugin
+	autoCodePackageService   = service.ServiceGroupApp.SystemServiceGroup.AutoCodePackage
+	autoCodeHistoryService   = service.ServiceGroupApp.SystemServiceGroup.AutoCodeHistory
+	autoCodeTemplateService  = service.ServiceGroupApp.SystemServiceGroup.AutoCodeTemplate
+	autoCodeMigrationService = service.ServiceGroupApp.SystemServiceGroup.AutoCodeMigration
+	autoCodePackService      = service.ServiceGroupApp.SystemServiceGroup.AutoCodeTemplatePack
+	autoCodeImportService    = service.ServiceGroupApp.SystemServiceGroup.AutoCodeImport
+	sysExportScheduleService = service.ServiceGroupApp.SystemServiceGroup.SysExportScheduleService
+	sysJobService            = service.ServiceGroupApp.SystemServiceGroup.SysJobService
+	sysRetentionService      = service.ServiceGroupApp.SystemServiceGroup.SysRetentionService
+	openApiService           = service.ServiceGroupApp.SystemServiceGroup.OpenApiService
+	pluginLifecycleService   = service.ServiceGroupApp.SystemServiceGroup.PluginLifecycleService
+	dbConnectionService      = service.ServiceGroupApp.SystemServiceGroup.DBConnectionService
 )
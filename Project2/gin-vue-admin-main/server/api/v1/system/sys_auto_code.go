@@ -26,7 +26,7 @@ func (autoApi *AutoCodeApi) GetDB(c *gin.Context) {
 	businessDB := c.Query("businessDB")
 	dbs, err := autoCodeService.Database(businessDB).GetDB(businessDB)
 	var dbList []map[string]interface{}
-	for _, db := range global.GVA_CONFIG.DBList {
+	for _, db := range global.GetGlobalDBInfoList() {
 		var item = make(map[string]interface{})
 		item["aliasName"] = db.AliasName
 		item["dbName"] = db.Dbname
@@ -56,10 +56,8 @@ func (autoApi *AutoCodeApi) GetTables(c *gin.Context) {
 	if dbName == "" {
 		dbName = *global.GVA_ACTIVE_DBNAME
 		if businessDB != "" {
-			for _, db := range global.GVA_CONFIG.DBList {
-				if db.AliasName == businessDB {
-					dbName = db.Dbname
-				}
+			if db, ok := global.GetGlobalDBInfo(businessDB); ok {
+				dbName = db.Dbname
 			}
 		}
 	}
@@ -87,10 +85,8 @@ func (autoApi *AutoCodeApi) GetColumn(c *gin.Context) {
 	if dbName == "" {
 		dbName = *global.GVA_ACTIVE_DBNAME
 		if businessDB != "" {
-			for _, db := range global.GVA_CONFIG.DBList {
-				if db.AliasName == businessDB {
-					dbName = db.Dbname
-				}
+			if db, ok := global.GetGlobalDBInfo(businessDB); ok {
+				dbName = db.Dbname
 			}
 		}
 	}
@@ -111,7 +107,8 @@ func (autoApi *AutoCodeApi) LLMAuto(c *gin.Context) {
 	params["prompt"] = prompt
 	params["mode"] = mode
 	path := strings.ReplaceAll(global.GVA_CONFIG.AutoCode.AiPath, "{FUNC}", "api/chat/ai")
-	res, err := request.HttpRequest(
+	res, err := request.HttpRequestWithContext(
+		c.Request.Context(),
 		path,
 		"POST",
 		nil,
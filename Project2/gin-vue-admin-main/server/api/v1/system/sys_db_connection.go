@@ -0,0 +1,183 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DBConnectionApi struct{}
+
+// CreateDBConnection 新增业务库
+// @Tags SysDBConnection
+// @Summary 新增业务库 连接成功后立即可用 无需重启
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.SysDBConnection true "新增业务库"
+// @Success 200 {object} response.Response{msg=string} "新增业务库"
+// @Router /dbConnection/createDBConnection [post]
+func (a *DBConnectionApi) CreateDBConnection(c *gin.Context) {
+	var conn systemReq.SysDBConnection
+	err := c.ShouldBindJSON(&conn)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"AliasName": {utils.NotEmpty()},
+		"Type":      {utils.NotEmpty()},
+		"Dbname":    {utils.NotEmpty()},
+	}
+	if err = utils.Verify(conn.SysDBConnection, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = dbConnectionService.CreateDBConnection(conn); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("创建成功", c)
+}
+
+// UpdateDBConnection 更新业务库
+// @Tags SysDBConnection
+// @Summary 更新业务库 新连接建立成功后替换旧连接 密码为空时保留原密码
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.SysDBConnection true "更新业务库"
+// @Success 200 {object} response.Response{msg=string} "更新业务库"
+// @Router /dbConnection/updateDBConnection [put]
+func (a *DBConnectionApi) UpdateDBConnection(c *gin.Context) {
+	var conn systemReq.SysDBConnection
+	err := c.ShouldBindJSON(&conn)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"ID":        {utils.NotEmpty()},
+		"AliasName": {utils.NotEmpty()},
+		"Type":      {utils.NotEmpty()},
+		"Dbname":    {utils.NotEmpty()},
+	}
+	if err = utils.Verify(conn.SysDBConnection, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = dbConnectionService.UpdateDBConnection(conn); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// DeleteDBConnection 删除业务库
+// @Tags SysDBConnection
+// @Summary 删除业务库 仍被导出模板使用时拒绝删除
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "删除业务库"
+// @Success 200 {object} response.Response{msg=string} "删除业务库"
+// @Router /dbConnection/deleteDBConnection [delete]
+func (a *DBConnectionApi) DeleteDBConnection(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = dbConnectionService.DeleteDBConnection(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// TestDBConnection 测试业务库连接
+// @Tags SysDBConnection
+// @Summary 测试业务库连接 不保存
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.SysDBConnection true "测试业务库连接"
+// @Success 200 {object} response.Response{msg=string} "测试业务库连接"
+// @Router /dbConnection/testDBConnection [post]
+func (a *DBConnectionApi) TestDBConnection(c *gin.Context) {
+	var conn systemReq.SysDBConnection
+	err := c.ShouldBindJSON(&conn)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = dbConnectionService.TestDBConnection(conn); err != nil {
+		global.GVA_LOG.Error("连接失败!", zap.Error(err))
+		response.FailWithMessage("连接失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("连接成功", c)
+}
+
+// FindDBConnection 用id查询业务库
+// @Tags SysDBConnection
+// @Summary 用id查询业务库 不返回密码
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query request.GetById true "用id查询业务库"
+// @Success 200 {object} response.Response{data=system.SysDBConnection,msg=string} "用id查询业务库"
+// @Router /dbConnection/findDBConnection [get]
+func (a *DBConnectionApi) FindDBConnection(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindQuery(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	conn, err := dbConnectionService.GetDBConnection(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("查询失败!", zap.Error(err))
+		response.FailWithMessage("查询失败", c)
+		return
+	}
+	response.OkWithData(gin.H{"connection": conn}, c)
+}
+
+// GetDBConnectionList 分页获取业务库列表
+// @Tags SysDBConnection
+// @Summary 分页获取在系统中维护的业务库列表 不含配置文件中的业务库
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.SysDBConnectionSearch true "分页获取业务库列表"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取业务库列表"
+// @Router /dbConnection/getDBConnectionList [get]
+func (a *DBConnectionApi) GetDBConnectionList(c *gin.Context) {
+	var pageInfo systemReq.SysDBConnectionSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := dbConnectionService.GetDBConnectionInfoList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
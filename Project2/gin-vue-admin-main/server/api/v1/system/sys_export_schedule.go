@@ -0,0 +1,274 @@
+package system
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SysExportScheduleApi struct{}
+
+// CreateSysExportSchedule 创建定时报表
+// @Tags SysExportSchedule
+// @Summary 创建定时报表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysExportSchedule true "创建定时报表"
+// @Success 200 {object} response.Response{msg=string} "创建定时报表"
+// @Router /sysExportSchedule/createSysExportSchedule [post]
+func (a *SysExportScheduleApi) CreateSysExportSchedule(c *gin.Context) {
+	var schedule system.SysExportSchedule
+	err := c.ShouldBindJSON(&schedule)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"Name":       {utils.NotEmpty()},
+		"TemplateID": {utils.NotEmpty()},
+		"Spec":       {utils.NotEmpty()},
+	}
+	if err = utils.Verify(schedule, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysExportScheduleService.CreateSysExportSchedule(&schedule); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("创建成功", c)
+}
+
+// DeleteSysExportSchedule 删除定时报表
+// @Tags SysExportSchedule
+// @Summary 删除定时报表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "删除定时报表"
+// @Success 200 {object} response.Response{msg=string} "删除定时报表"
+// @Router /sysExportSchedule/deleteSysExportSchedule [delete]
+func (a *SysExportScheduleApi) DeleteSysExportSchedule(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysExportScheduleService.DeleteSysExportSchedule(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败", c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// DeleteSysExportScheduleByIds 批量删除定时报表
+// @Tags SysExportSchedule
+// @Summary 批量删除定时报表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.IdsReq true "批量删除定时报表"
+// @Success 200 {object} response.Response{msg=string} "批量删除定时报表"
+// @Router /sysExportSchedule/deleteSysExportScheduleByIds [delete]
+func (a *SysExportScheduleApi) DeleteSysExportScheduleByIds(c *gin.Context) {
+	var IDS request.IdsReq
+	err := c.ShouldBindJSON(&IDS)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysExportScheduleService.DeleteSysExportScheduleByIds(IDS); err != nil {
+		global.GVA_LOG.Error("批量删除失败!", zap.Error(err))
+		response.FailWithMessage("批量删除失败", c)
+		return
+	}
+	response.OkWithMessage("批量删除成功", c)
+}
+
+// UpdateSysExportSchedule 更新定时报表
+// @Tags SysExportSchedule
+// @Summary 更新定时报表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysExportSchedule true "更新定时报表"
+// @Success 200 {object} response.Response{msg=string} "更新定时报表"
+// @Router /sysExportSchedule/updateSysExportSchedule [put]
+func (a *SysExportScheduleApi) UpdateSysExportSchedule(c *gin.Context) {
+	var schedule system.SysExportSchedule
+	err := c.ShouldBindJSON(&schedule)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"ID":         {utils.NotEmpty()},
+		"Name":       {utils.NotEmpty()},
+		"TemplateID": {utils.NotEmpty()},
+		"Spec":       {utils.NotEmpty()},
+	}
+	if err = utils.Verify(schedule, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysExportScheduleService.UpdateSysExportSchedule(schedule); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// FindSysExportSchedule 用id查询定时报表
+// @Tags SysExportSchedule
+// @Summary 用id查询定时报表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query request.GetById true "用id查询定时报表"
+// @Success 200 {object} response.Response{data=system.SysExportSchedule,msg=string} "用id查询定时报表"
+// @Router /sysExportSchedule/findSysExportSchedule [get]
+func (a *SysExportScheduleApi) FindSysExportSchedule(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindQuery(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	schedule, err := sysExportScheduleService.GetSysExportSchedule(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("查询失败!", zap.Error(err))
+		response.FailWithMessage("查询失败", c)
+		return
+	}
+	response.OkWithData(gin.H{"schedule": schedule}, c)
+}
+
+// GetSysExportScheduleList 分页获取定时报表列表
+// @Tags SysExportSchedule
+// @Summary 分页获取定时报表列表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.SysExportScheduleSearch true "分页获取定时报表列表"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取定时报表列表"
+// @Router /sysExportSchedule/getSysExportScheduleList [get]
+func (a *SysExportScheduleApi) GetSysExportScheduleList(c *gin.Context) {
+	var pageInfo systemReq.SysExportScheduleSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := sysExportScheduleService.GetSysExportScheduleInfoList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// RunSysExportSchedule 立即执行定时报表
+// @Tags SysExportSchedule
+// @Summary 立即执行定时报表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "立即执行定时报表"
+// @Success 200 {object} response.Response{data=system.SysExportScheduleRun,msg=string} "立即执行定时报表"
+// @Router /sysExportSchedule/runSysExportSchedule [post]
+func (a *SysExportScheduleApi) RunSysExportSchedule(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	run, err := sysExportScheduleService.RunSysExportSchedule(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("执行失败!", zap.Error(err))
+		response.FailWithDetailed(run, "执行失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(run, "执行成功", c)
+}
+
+// GetSysExportScheduleRunList 分页获取定时报表执行记录
+// @Tags SysExportSchedule
+// @Summary 分页获取定时报表执行记录
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.SysExportScheduleRunSearch true "分页获取定时报表执行记录"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取定时报表执行记录"
+// @Router /sysExportSchedule/getSysExportScheduleRunList [get]
+func (a *SysExportScheduleApi) GetSysExportScheduleRunList(c *gin.Context) {
+	var pageInfo systemReq.SysExportScheduleRunSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := sysExportScheduleService.GetSysExportScheduleRunList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// DownloadSysExportScheduleRun 下载执行记录产出文件
+// @Tags SysExportSchedule
+// @Summary 下载执行记录产出文件
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/octet-stream
+// @Param data query request.GetById true "执行记录ID"
+// @Router /sysExportSchedule/downloadSysExportScheduleRun [get]
+func (a *SysExportScheduleApi) DownloadSysExportScheduleRun(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindQuery(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	run, content, err := sysExportScheduleService.GetSysExportScheduleRunFile(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("获取文件失败!", zap.Error(err))
+		response.FailWithMessage("获取文件失败", c)
+		return
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(run.FileName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", run.FileName))
+	c.Header("success", "true")
+	c.Data(http.StatusOK, contentType, content)
+}
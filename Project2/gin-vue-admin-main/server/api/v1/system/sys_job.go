@@ -0,0 +1,250 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SysJobApi struct{}
+
+// CreateSysJob 创建定时任务
+// @Tags SysJob
+// @Summary 创建定时任务
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysJob true "创建定时任务"
+// @Success 200 {object} response.Response{msg=string} "创建定时任务"
+// @Router /sysJob/createSysJob [post]
+func (a *SysJobApi) CreateSysJob(c *gin.Context) {
+	var job system.SysJob
+	err := c.ShouldBindJSON(&job)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"Name":    {utils.NotEmpty()},
+		"Spec":    {utils.NotEmpty()},
+		"Handler": {utils.NotEmpty()},
+	}
+	if err = utils.Verify(job, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysJobService.CreateSysJob(&job); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("创建成功", c)
+}
+
+// DeleteSysJob 删除定时任务
+// @Tags SysJob
+// @Summary 删除定时任务
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "删除定时任务"
+// @Success 200 {object} response.Response{msg=string} "删除定时任务"
+// @Router /sysJob/deleteSysJob [delete]
+func (a *SysJobApi) DeleteSysJob(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysJobService.DeleteSysJob(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败", c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// DeleteSysJobByIds 批量删除定时任务
+// @Tags SysJob
+// @Summary 批量删除定时任务
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.IdsReq true "批量删除定时任务"
+// @Success 200 {object} response.Response{msg=string} "批量删除定时任务"
+// @Router /sysJob/deleteSysJobByIds [delete]
+func (a *SysJobApi) DeleteSysJobByIds(c *gin.Context) {
+	var IDS request.IdsReq
+	err := c.ShouldBindJSON(&IDS)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysJobService.DeleteSysJobByIds(IDS); err != nil {
+		global.GVA_LOG.Error("批量删除失败!", zap.Error(err))
+		response.FailWithMessage("批量删除失败", c)
+		return
+	}
+	response.OkWithMessage("批量删除成功", c)
+}
+
+// UpdateSysJob 更新定时任务
+// @Tags SysJob
+// @Summary 更新定时任务
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysJob true "更新定时任务"
+// @Success 200 {object} response.Response{msg=string} "更新定时任务"
+// @Router /sysJob/updateSysJob [put]
+func (a *SysJobApi) UpdateSysJob(c *gin.Context) {
+	var job system.SysJob
+	err := c.ShouldBindJSON(&job)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"ID":      {utils.NotEmpty()},
+		"Name":    {utils.NotEmpty()},
+		"Spec":    {utils.NotEmpty()},
+		"Handler": {utils.NotEmpty()},
+	}
+	if err = utils.Verify(job, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysJobService.UpdateSysJob(job); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// FindSysJob 用id查询定时任务
+// @Tags SysJob
+// @Summary 用id查询定时任务
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query request.GetById true "用id查询定时任务"
+// @Success 200 {object} response.Response{data=system.SysJob,msg=string} "用id查询定时任务"
+// @Router /sysJob/findSysJob [get]
+func (a *SysJobApi) FindSysJob(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindQuery(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	job, err := sysJobService.GetSysJob(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("查询失败!", zap.Error(err))
+		response.FailWithMessage("查询失败", c)
+		return
+	}
+	response.OkWithData(gin.H{"job": job}, c)
+}
+
+// GetSysJobList 分页获取定时任务列表
+// @Tags SysJob
+// @Summary 分页获取定时任务列表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.SysJobSearch true "分页获取定时任务列表"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取定时任务列表"
+// @Router /sysJob/getSysJobList [get]
+func (a *SysJobApi) GetSysJobList(c *gin.Context) {
+	var pageInfo systemReq.SysJobSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := sysJobService.GetSysJobInfoList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// RunSysJob 立即执行定时任务
+// @Tags SysJob
+// @Summary 立即执行定时任务 异步执行 通过执行记录查看结果
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "立即执行定时任务"
+// @Success 200 {object} response.Response{data=system.SysJobRun,msg=string} "立即执行定时任务"
+// @Router /sysJob/runSysJob [post]
+func (a *SysJobApi) RunSysJob(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	run, err := sysJobService.RunSysJob(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("执行失败!", zap.Error(err))
+		response.FailWithMessage("执行失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(run, "已开始执行", c)
+}
+
+// GetSysJobRunList 分页获取定时任务执行记录
+// @Tags SysJob
+// @Summary 分页获取定时任务执行记录
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.SysJobRunSearch true "分页获取定时任务执行记录"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取定时任务执行记录"
+// @Router /sysJob/getSysJobRunList [get]
+func (a *SysJobApi) GetSysJobRunList(c *gin.Context) {
+	var pageInfo systemReq.SysJobRunSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := sysJobService.GetSysJobRunList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// GetSysJobHandlers 获取可用的处理函数
+// @Tags SysJob
+// @Summary 获取可用的处理函数
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Success 200 {object} response.Response{data=[]timer.JobHandlerInfo,msg=string} "获取可用的处理函数"
+// @Router /sysJob/getSysJobHandlers [get]
+func (a *SysJobApi) GetSysJobHandlers(c *gin.Context) {
+	response.OkWithDetailed(sysJobService.GetJobHandlers(), "获取成功", c)
+}
@@ -0,0 +1,33 @@
+package system
+
+import (
+	"net/http"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type OpenApiApi struct{}
+
+// GetOpenApiSpec 获取OpenAPI 3.1文档
+// @Tags OpenApi
+// @Summary 获取OpenAPI 3.1文档 由路由、api信息与代码生成记录构建
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Param refresh query bool false "是否重新构建文档"
+// @Success 200 {object} openapi.Document "OpenAPI文档"
+// @Router /openapi/getSpec [get]
+func (a *OpenApiApi) GetOpenApiSpec(c *gin.Context) {
+	if c.Query("refresh") == "true" {
+		openApiService.Invalidate()
+	}
+	doc, err := openApiService.Spec(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取OpenAPI文档失败!", zap.Error(err))
+		response.FailWithMessage("获取失败:"+err.Error(), c)
+		return
+	}
+	c.JSON(http.StatusOK, doc)
+}
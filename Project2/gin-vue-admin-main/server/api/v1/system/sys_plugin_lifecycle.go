@@ -0,0 +1,78 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type PluginLifecycleApi struct{}
+
+// GetPluginStatus 获取插件运行状态
+// @Tags PluginLifecycle
+// @Summary 获取已加载插件的启用状态、健康检查结果与配置
+// @Security ApiKeyAuth
+// @Produce application/json
+// @Success 200 {object} response.Response{data=[]response.SysPluginStatus,msg=string} "获取成功"
+// @Router /plugin/getPluginStatus [get]
+func (a *PluginLifecycleApi) GetPluginStatus(c *gin.Context) {
+	list, err := pluginLifecycleService.Status(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(list, "获取成功", c)
+}
+
+// SetPluginEnabled 启用或停用插件
+// @Tags PluginLifecycle
+// @Summary 启用或停用插件 停用后插件路由返回404
+// @Security ApiKeyAuth
+// @Accept application/json
+// @Produce application/json
+// @Param data body request.SysPluginEnable true "插件名与是否启用"
+// @Success 200 {object} response.Response{msg=string} "设置成功"
+// @Router /plugin/setPluginEnabled [post]
+func (a *PluginLifecycleApi) SetPluginEnabled(c *gin.Context) {
+	var info request.SysPluginEnable
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = pluginLifecycleService.SetEnabled(c.Request.Context(), info.Name, info.Enabled)
+	if err != nil {
+		global.GVA_LOG.Error("设置失败!", zap.Error(err))
+		response.FailWithMessage("设置失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("设置成功", c)
+}
+
+// SetPluginConfig 修改插件配置
+// @Tags PluginLifecycle
+// @Summary 修改插件配置 保存到数据库并通知插件
+// @Security ApiKeyAuth
+// @Accept application/json
+// @Produce application/json
+// @Param data body request.SysPluginConfig true "插件名与配置"
+// @Success 200 {object} response.Response{msg=string} "修改成功"
+// @Router /plugin/setPluginConfig [put]
+func (a *PluginLifecycleApi) SetPluginConfig(c *gin.Context) {
+	var info request.SysPluginConfig
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	err = pluginLifecycleService.UpdateConfig(c.Request.Context(), info.Name, info.Config)
+	if err != nil {
+		global.GVA_LOG.Error("修改失败!", zap.Error(err))
+		response.FailWithMessage("修改失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("修改成功", c)
+}
@@ -0,0 +1,239 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SysRetentionApi struct{}
+
+// CreateSysRetentionPolicy 创建数据保留策略
+// @Tags SysRetention
+// @Summary 创建数据保留策略
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysRetentionPolicy true "创建数据保留策略"
+// @Success 200 {object} response.Response{msg=string} "创建数据保留策略"
+// @Router /sysRetention/createSysRetentionPolicy [post]
+func (a *SysRetentionApi) CreateSysRetentionPolicy(c *gin.Context) {
+	var policy system.SysRetentionPolicy
+	err := c.ShouldBindJSON(&policy)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"Name":     {utils.NotEmpty()},
+		"Table":    {utils.NotEmpty()},
+		"Interval": {utils.NotEmpty()},
+	}
+	if err = utils.Verify(policy, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysRetentionService.CreateSysRetentionPolicy(&policy); err != nil {
+		global.GVA_LOG.Error("创建失败!", zap.Error(err))
+		response.FailWithMessage("创建失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("创建成功", c)
+}
+
+// DeleteSysRetentionPolicy 删除数据保留策略
+// @Tags SysRetention
+// @Summary 删除数据保留策略
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.GetById true "删除数据保留策略"
+// @Success 200 {object} response.Response{msg=string} "删除数据保留策略"
+// @Router /sysRetention/deleteSysRetentionPolicy [delete]
+func (a *SysRetentionApi) DeleteSysRetentionPolicy(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindJSON(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysRetentionService.DeleteSysRetentionPolicy(reqId.Uint()); err != nil {
+		global.GVA_LOG.Error("删除失败!", zap.Error(err))
+		response.FailWithMessage("删除失败", c)
+		return
+	}
+	response.OkWithMessage("删除成功", c)
+}
+
+// DeleteSysRetentionPolicyByIds 批量删除数据保留策略
+// @Tags SysRetention
+// @Summary 批量删除数据保留策略
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body request.IdsReq true "批量删除数据保留策略"
+// @Success 200 {object} response.Response{msg=string} "批量删除数据保留策略"
+// @Router /sysRetention/deleteSysRetentionPolicyByIds [delete]
+func (a *SysRetentionApi) DeleteSysRetentionPolicyByIds(c *gin.Context) {
+	var IDS request.IdsReq
+	err := c.ShouldBindJSON(&IDS)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysRetentionService.DeleteSysRetentionPolicyByIds(IDS); err != nil {
+		global.GVA_LOG.Error("批量删除失败!", zap.Error(err))
+		response.FailWithMessage("批量删除失败", c)
+		return
+	}
+	response.OkWithMessage("批量删除成功", c)
+}
+
+// UpdateSysRetentionPolicy 更新数据保留策略
+// @Tags SysRetention
+// @Summary 更新数据保留策略
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body system.SysRetentionPolicy true "更新数据保留策略"
+// @Success 200 {object} response.Response{msg=string} "更新数据保留策略"
+// @Router /sysRetention/updateSysRetentionPolicy [put]
+func (a *SysRetentionApi) UpdateSysRetentionPolicy(c *gin.Context) {
+	var policy system.SysRetentionPolicy
+	err := c.ShouldBindJSON(&policy)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	verify := utils.Rules{
+		"ID":       {utils.NotEmpty()},
+		"Name":     {utils.NotEmpty()},
+		"Table":    {utils.NotEmpty()},
+		"Interval": {utils.NotEmpty()},
+	}
+	if err = utils.Verify(policy, verify); err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err = sysRetentionService.UpdateSysRetentionPolicy(policy); err != nil {
+		global.GVA_LOG.Error("更新失败!", zap.Error(err))
+		response.FailWithMessage("更新失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithMessage("更新成功", c)
+}
+
+// FindSysRetentionPolicy 用id查询数据保留策略
+// @Tags SysRetention
+// @Summary 用id查询数据保留策略
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query request.GetById true "用id查询数据保留策略"
+// @Success 200 {object} response.Response{data=system.SysRetentionPolicy,msg=string} "用id查询数据保留策略"
+// @Router /sysRetention/findSysRetentionPolicy [get]
+func (a *SysRetentionApi) FindSysRetentionPolicy(c *gin.Context) {
+	var reqId request.GetById
+	err := c.ShouldBindQuery(&reqId)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	policy, err := sysRetentionService.GetSysRetentionPolicy(reqId.Uint())
+	if err != nil {
+		global.GVA_LOG.Error("查询失败!", zap.Error(err))
+		response.FailWithMessage("查询失败", c)
+		return
+	}
+	response.OkWithData(gin.H{"policy": policy}, c)
+}
+
+// GetSysRetentionPolicyList 分页获取数据保留策略列表
+// @Tags SysRetention
+// @Summary 分页获取数据保留策略列表
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.SysRetentionPolicySearch true "分页获取数据保留策略列表"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取数据保留策略列表"
+// @Router /sysRetention/getSysRetentionPolicyList [get]
+func (a *SysRetentionApi) GetSysRetentionPolicyList(c *gin.Context) {
+	var pageInfo systemReq.SysRetentionPolicySearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := sysRetentionService.GetSysRetentionPolicyInfoList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
+
+// RunSysRetentionPolicy 执行数据保留策略
+// @Tags SysRetention
+// @Summary 执行数据保留策略 dryRun 时只统计将被删除的行数
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data body systemReq.RunSysRetention true "策略ID, 是否仅统计"
+// @Success 200 {object} response.Response{data=system.SysRetentionRun,msg=string} "执行数据保留策略"
+// @Router /sysRetention/runSysRetentionPolicy [post]
+func (a *SysRetentionApi) RunSysRetentionPolicy(c *gin.Context) {
+	var info systemReq.RunSysRetention
+	err := c.ShouldBindJSON(&info)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	run, err := sysRetentionService.RunSysRetentionPolicy(info)
+	if err != nil {
+		global.GVA_LOG.Error("执行失败!", zap.Error(err))
+		response.FailWithDetailed(run, "执行失败:"+err.Error(), c)
+		return
+	}
+	response.OkWithDetailed(run, "执行成功", c)
+}
+
+// GetSysRetentionRunList 分页获取数据保留执行报告
+// @Tags SysRetention
+// @Summary 分页获取数据保留执行报告
+// @Security ApiKeyAuth
+// @accept application/json
+// @Produce application/json
+// @Param data query systemReq.SysRetentionRunSearch true "分页获取数据保留执行报告"
+// @Success 200 {object} response.Response{data=response.PageResult,msg=string} "分页获取数据保留执行报告"
+// @Router /sysRetention/getSysRetentionRunList [get]
+func (a *SysRetentionApi) GetSysRetentionRunList(c *gin.Context) {
+	var pageInfo systemReq.SysRetentionRunSearch
+	err := c.ShouldBindQuery(&pageInfo)
+	if err != nil {
+		response.FailWithMessage(err.Error(), c)
+		return
+	}
+	list, total, err := sysRetentionService.GetSysRetentionRunList(pageInfo)
+	if err != nil {
+		global.GVA_LOG.Error("获取失败!", zap.Error(err))
+		response.FailWithMessage("获取失败", c)
+		return
+	}
+	response.OkWithDetailed(response.PageResult{
+		List:     list,
+		Total:    total,
+		Page:     pageInfo.Page,
+		PageSize: pageInfo.PageSize,
+	}, "获取成功", c)
+}
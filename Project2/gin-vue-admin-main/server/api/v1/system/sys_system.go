@@ -6,6 +6,8 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/cache"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -84,5 +86,20 @@ func (s *SystemApi) GetServerInfo(c *gin.Context) {
 		response.FailWithMessage("获取失败", c)
 		return
 	}
-	response.OkWithDetailed(gin.H{"server": server}, "获取成功", c)
+	plugins, err := pluginLifecycleService.Status(c.Request.Context())
+	if err != nil {
+		global.GVA_LOG.Error("获取插件状态失败!", zap.Error(err))
+	}
+	response.OkWithDetailed(gin.H{"server": server, "plugins": plugins, "cache": cache.Stats()}, "获取成功", c)
+}
+
+// GetMetrics
+// @Tags      System
+// @Summary   获取运行指标
+// @Security  ApiKeyAuth
+// @Produce   text/plain
+// @Success   200  {string}  string  "Prometheus 文本格式的指标"
+// @Router    /system/getMetrics [get]
+func (s *SystemApi) GetMetrics(c *gin.Context) {
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
 }
@@ -11,6 +11,7 @@ import (
 	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
 	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -56,6 +57,7 @@ func (b *BaseApi) Login(c *gin.Context) {
 			global.GVA_LOG.Error("登陆失败! 用户名不存在或者密码错误!", zap.Error(err))
 			// 验证码次数+1
 			global.BlackCache.Increment(key, 1)
+			metrics.Login(metrics.LoginPassword)
 			response.FailWithMessage("用户名不存在或者密码错误", c)
 			return
 		}
@@ -63,14 +65,17 @@ func (b *BaseApi) Login(c *gin.Context) {
 			global.GVA_LOG.Error("登陆失败! 用户被禁止登录!")
 			// 验证码次数+1
 			global.BlackCache.Increment(key, 1)
+			metrics.Login(metrics.LoginDisabled)
 			response.FailWithMessage("用户被禁止登录", c)
 			return
 		}
+		metrics.Login(metrics.LoginSuccess)
 		b.TokenNext(c, *user)
 		return
 	}
 	// 验证码次数+1
 	global.BlackCache.Increment(key, 1)
+	metrics.Login(metrics.LoginCaptcha)
 	response.FailWithMessage("验证码错误", c)
 }
 
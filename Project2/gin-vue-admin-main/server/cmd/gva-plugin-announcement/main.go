@@ -0,0 +1,102 @@
+// gva-plugin-announcement 以进程外插件方式运行的公告插件
+//
+// 构建后在 config.yaml 的 plugin-host.plugins 中配置可执行文件路径即可由服务端启动,
+// 数据库通过插件配置接口下发 如 {"db-type":"mysql","dsn":"user:pass@tcp(127.0.0.1:3306)/gva?charset=utf8mb4&parseTime=True&loc=Local"}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement/model"
+	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement/router"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote/pb"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Config 插件配置
+type Config struct {
+	DbType string `json:"db-type"` // 数据库类型 mysql pgsql sqlite
+	Dsn    string `json:"dsn"`     // 数据库连接
+}
+
+func main() {
+	global.GVA_LOG, _ = zap.NewProduction()
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	router.Info.Register(engine.Group(""), engine.Group(""))
+
+	plugin := &remote.HTTPPlugin{
+		Info: &pb.DescribeResponse{
+			Name:        "announcement",
+			Version:     "1.0.0",
+			Description: "公告插件",
+			Routes:      remote.Routes(engine.Routes(), "/info/getInfoDataSource", "/info/getInfoPublic"),
+			Apis: []*pb.Api{
+				{Path: "/info/createInfo", Method: "POST", ApiGroup: "公告", Description: "新建公告"},
+				{Path: "/info/deleteInfo", Method: "DELETE", ApiGroup: "公告", Description: "删除公告"},
+				{Path: "/info/deleteInfoByIds", Method: "DELETE", ApiGroup: "公告", Description: "批量删除公告"},
+				{Path: "/info/updateInfo", Method: "PUT", ApiGroup: "公告", Description: "更新公告"},
+				{Path: "/info/findInfo", Method: "GET", ApiGroup: "公告", Description: "根据ID获取公告"},
+				{Path: "/info/getInfoList", Method: "GET", ApiGroup: "公告", Description: "获取公告列表"},
+			},
+			Menus: []*pb.Menu{
+				{ParentId: 24, Path: "anInfo", Name: "anInfo", Component: "plugin/announcement/view/info.vue", Sort: 5, Title: "公告管理", Icon: "box"},
+			},
+		},
+		Handler:     engine,
+		OnConfigure: configure,
+		OnHealth: func(ctx context.Context) error {
+			if global.GVA_DB == nil {
+				return errors.New("未配置数据库")
+			}
+			db, err := global.GVA_DB.DB()
+			if err != nil {
+				return err
+			}
+			return db.PingContext(ctx)
+		},
+	}
+	if err := remote.Serve(plugin); err != nil {
+		global.GVA_LOG.Error("插件运行失败!", zap.Error(err))
+		os.Exit(1)
+	}
+}
+
+// configure 按下发的配置连接数据库并同步公告表
+func configure(ctx context.Context, raw json.RawMessage) error {
+	var config Config
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return err
+	}
+	var dialector gorm.Dialector
+	switch config.DbType {
+	case "mysql":
+		dialector = mysql.Open(config.Dsn)
+	case "pgsql":
+		dialector = postgres.Open(config.Dsn)
+	case "sqlite":
+		dialector = sqlite.Open(config.Dsn)
+	default:
+		return errors.Errorf("不支持的数据库类型: %s", config.DbType)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return errors.Wrap(err, "连接数据库失败!")
+	}
+	if err = db.WithContext(ctx).AutoMigrate(new(model.Info)); err != nil {
+		return errors.Wrap(err, "注册表失败!")
+	}
+	global.GVA_DB = db
+	return nil
+}
@@ -0,0 +1,59 @@
+// gva-plugin-email 以进程外插件方式运行的邮件插件
+//
+// 构建后在 config.yaml 的 plugin-host.plugins 中配置可执行文件路径即可由服务端启动,
+// 邮件配置通过插件配置接口下发, 结构同 plugin/email/config.Email
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/plugin/email/api"
+	emailGlobal "github.com/flipped-aurora/gin-vue-admin/server/plugin/email/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote/pb"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func main() {
+	global.GVA_LOG, _ = zap.NewProduction()
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	group := engine.Group("email")
+	{
+		group.POST("emailTest", api.ApiGroupApp.EmailApi.EmailTest) // 发送测试邮件
+		group.POST("sendEmail", api.ApiGroupApp.EmailApi.SendEmail) // 发送邮件
+	}
+
+	plugin := &remote.HTTPPlugin{
+		Info: &pb.DescribeResponse{
+			Name:        "email",
+			Version:     "1.0.0",
+			Description: "邮件插件",
+			Routes:      remote.Routes(engine.Routes()),
+			Apis: []*pb.Api{
+				{Path: "/email/emailTest", Method: "POST", ApiGroup: "email", Description: "发送测试邮件"},
+				{Path: "/email/sendEmail", Method: "POST", ApiGroup: "email", Description: "发送邮件"},
+			},
+		},
+		Handler: engine,
+		OnConfigure: func(ctx context.Context, config json.RawMessage) error {
+			return json.Unmarshal(config, emailGlobal.GlobalConfig)
+		},
+		OnHealth: func(ctx context.Context) error {
+			if emailGlobal.GlobalConfig.Host == "" {
+				return errors.New("未配置邮件服务器")
+			}
+			return nil
+		},
+	}
+	if err := remote.Serve(plugin); err != nil {
+		global.GVA_LOG.Error("插件运行失败!", zap.Error(err))
+		os.Exit(1)
+	}
+}
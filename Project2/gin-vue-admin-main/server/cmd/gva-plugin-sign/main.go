@@ -0,0 +1,175 @@
+// gva-plugin-sign 插件包签名工具
+//
+//	gva-plugin-sign keygen                          生成 ed25519 密钥对 公钥配置到 plugin-security.trusted-keys
+//	gva-plugin-sign sign -id <密钥ID> -key <私钥> 插件.zip  为插件包签名 签名文件写入压缩包根目录
+//
+// 私钥也可以通过环境变量 GVA_PLUGIN_SIGNING_KEY 传入 避免出现在命令历史中
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/sign"
+)
+
+const usage = `用法:
+  gva-plugin-sign keygen
+  gva-plugin-sign sign -id <密钥ID> [-key <私钥>] <插件.zip>
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		var public, private string
+		if public, private, err = sign.GenerateKey(); err == nil {
+			fmt.Printf("public-key: %s\nsigning-key: %s\n", public, private)
+		}
+	case "sign":
+		fs := flag.NewFlagSet("sign", flag.ExitOnError)
+		id := fs.String("id", "", "密钥ID")
+		key := fs.String("key", os.Getenv("GVA_PLUGIN_SIGNING_KEY"), "私钥 base64")
+		_ = fs.Parse(os.Args[2:])
+		if *id == "" || *key == "" || fs.NArg() != 1 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = signZip(fs.Arg(0), *id, *key)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gva-plugin-sign:", err)
+		os.Exit(1)
+	}
+}
+
+// signZip 解压插件包计算文件摘要 签名后重新写入压缩包
+func signZip(file string, id string, key string) error {
+	private, err := sign.ParsePrivateKey(key)
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "gva-plugin-sign")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if _, err = utils.Unzip(file, dir); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var roots []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != "__MACOSX" {
+			roots = append(roots, entry.Name())
+		}
+	}
+	if len(roots) != 1 {
+		return fmt.Errorf("压缩包只能包含一个根目录")
+	}
+	root := filepath.Join(dir, roots[0])
+
+	signature := &sign.Signature{KeyID: id, Files: make(map[string]string)}
+	if err = sign.Digest(root, "", signature.Files); err != nil {
+		return err
+	}
+	signature.Name, signature.Version, err = manifests(root)
+	if err != nil {
+		return err
+	}
+	signature.Sign(private)
+	bytes, err := json.MarshalIndent(signature, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = rewrite(file, path.Join(roots[0], sign.SignatureName), bytes); err != nil {
+		return err
+	}
+	fmt.Printf("已签名 %s@%s, 共%d个文件\n", signature.Name, signature.Version, len(signature.Files))
+	return nil
+}
+
+// manifests 按目录顺序读取插件清单 与服务端安装时的顺序一致
+func manifests(root string) (name string, version string, err error) {
+	pluginDir := filepath.Join(root, "server", "plugin")
+	if _, err = os.Stat(pluginDir); os.IsNotExist(err) {
+		pluginDir = filepath.Join(root, "web", "plugin")
+	}
+	entries, err := os.ReadDir(pluginDir)
+	if err != nil {
+		return "", "", err
+	}
+	var names, versions []string
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "__MACOSX" {
+			continue
+		}
+		manifest := model.PluginManifest{Name: entry.Name(), Version: "0.0.0"}
+		bytes, rErr := os.ReadFile(filepath.Join(root, "server", "plugin", entry.Name(), model.PluginManifestName))
+		if rErr == nil {
+			if err = json.Unmarshal(bytes, &manifest); err != nil {
+				return "", "", err
+			}
+		}
+		names = append(names, manifest.Name)
+		versions = append(versions, manifest.Version)
+	}
+	return strings.Join(names, ","), strings.Join(versions, ","), nil
+}
+
+// rewrite 复制压缩包内容并写入签名文件 已有的签名文件会被替换
+func rewrite(file string, name string, content []byte) error {
+	reader, err := zip.OpenReader(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	tmp := file + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	writer := zip.NewWriter(out)
+	for _, f := range reader.File {
+		if f.Name == name {
+			continue
+		}
+		if err = writer.Copy(f); err != nil {
+			_ = out.Close()
+			return err
+		}
+	}
+	w, err := writer.Create(name)
+	if err == nil {
+		_, err = w.Write(content)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if cErr := out.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
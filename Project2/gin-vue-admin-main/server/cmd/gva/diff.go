@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// printDiff 以 unified diff 输出生成内容与磁盘文件的差异 返回存在差异的文件数
+// injected 为注入的文件 注入后的内容经过AST重新格式化 比较前对磁盘文件做同样的格式化 避免注释位置等无关差异
+func printDiff(files map[string]string, injected map[string]bool) (changed int, err error) {
+	for _, key := range sortedKeys(files) {
+		var current string
+		content, rErr := os.ReadFile(key)
+		if rErr != nil && !os.IsNotExist(rErr) {
+			return changed, rErr
+		}
+		current = string(content)
+		if injected[key] && rErr == nil {
+			if current, err = reformat(key); err != nil {
+				return changed, err
+			}
+		}
+		if current == files[key] {
+			continue
+		}
+		changed++
+		from := "a/" + relPath(key)
+		if os.IsNotExist(rErr) {
+			from = "/dev/null"
+		}
+		text, dErr := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(current),
+			B:        difflib.SplitLines(files[key]),
+			FromFile: from,
+			ToFile:   "b/" + relPath(key),
+			Context:  3,
+		})
+		if dErr != nil {
+			return changed, dErr
+		}
+		fmt.Print(text)
+	}
+	return changed, nil
+}
+
+// reformat 以注入时相同的方式解析并输出文件
+func reformat(filename string) (string, error) {
+	var base ast.Base
+	file, err := base.Parse(filename, nil)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	if err = base.Format(filename, &builder, file); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
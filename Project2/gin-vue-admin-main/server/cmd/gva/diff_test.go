@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// setRoot 将项目根目录设置为临时目录 测试结束后恢复配置
+func setRoot(t *testing.T) string {
+	t.Helper()
+	autoCode := global.GVA_CONFIG.AutoCode
+	t.Cleanup(func() { global.GVA_CONFIG.AutoCode = autoCode })
+	root := t.TempDir()
+	global.GVA_CONFIG.AutoCode.Root = root
+	global.GVA_CONFIG.AutoCode.Server = "server"
+	return root
+}
+
+// captureStdout 捕获 fn 写入标准输出的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		content, _ := io.ReadAll(r)
+		done <- string(content)
+	}()
+	defer func() { os.Stdout = stdout }()
+	fn()
+	_ = w.Close()
+	return <-done
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrintDiff(t *testing.T) {
+	root := setRoot(t)
+	unchanged := filepath.Join(root, "server", "model", "a.go")
+	created := filepath.Join(root, "server", "model", "b.go")
+	injected := filepath.Join(root, "server", "initialize", "c.go")
+	writeFile(t, unchanged, "package model\n")
+	// 注入文件比较前会重新格式化 多余的空行不算差异
+	writeFile(t, injected, "package initialize\n\n\n\nfunc F() {}\n")
+
+	files := map[string]string{
+		unchanged: "package model\n",
+		created:   "package model\n\ntype B struct{}\n",
+		injected:  "package initialize\n\nfunc F() {}\n\nfunc G() {}\n",
+	}
+	var changed int
+	var err error
+	output := captureStdout(t, func() {
+		changed, err = printDiff(files, map[string]bool{injected: true})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 2 {
+		t.Errorf("printDiff() changed = %d, want 2\n%s", changed, output)
+	}
+	for _, want := range []string{
+		"--- /dev/null\n+++ b/server/model/b.go\n",
+		"+type B struct{}\n",
+		"--- a/server/initialize/c.go\n+++ b/server/initialize/c.go\n",
+		"+func G() {}\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("printDiff() output should contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "a.go") || strings.Contains(output, "-\n") {
+		t.Errorf("unchanged file and formatting should not be reported:\n%s", output)
+	}
+	if strings.Index(output, "c.go") > strings.Index(output, "b.go") {
+		t.Errorf("files should be reported in sorted order:\n%s", output)
+	}
+
+	output = captureStdout(t, func() {
+		changed, err = printDiff(map[string]string{unchanged: "package model\n"}, nil)
+	})
+	if err != nil || changed != 0 || output != "" {
+		t.Errorf("printDiff() without changes = %d %v %q", changed, err, output)
+	}
+}
+
+func TestInjectedFiles(t *testing.T) {
+	files := map[string]string{"model.go": "", "enter.go": ""}
+	templates := map[string]string{"model.go.tpl": "model.go"}
+	injected := injectedFiles(files, templates)
+	if len(injected) != 1 || !injected["enter.go"] {
+		t.Errorf("injectedFiles() = %v, want only enter.go", injected)
+	}
+}
@@ -0,0 +1,226 @@
+// gva 代码生成命令行工具
+// 离线使用与服务端相同的模板与AST注入生成代码 不需要启动服务与连接数据库 在 server 目录下执行
+//
+//	gva preview  -f autocode.yaml  预览 以 unified diff 输出与现有文件的差异
+//	gva create   -f autocode.yaml  生成代码 并在 .gva 目录记录回滚清单
+//	gva rollback -f autocode.yaml  回滚生成的代码与注入
+//	gva verify   -f autocode.yaml  校验代码是否为最新 有差异时输出diff并以非0退出 可用于CI
+//	gva openapi  -f autocode.yaml  输出生成接口的 OpenAPI 3.1 文档
+//
+// 定义文件为 request.AutoCode 的 YAML 或 JSON 格式 额外的 template 字段指定模板类型 package(默认) 或 plugin
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/openapi"
+	"github.com/spf13/viper"
+)
+
+const usage = `用法: gva <preview|create|rollback|verify|openapi> -f <定义文件> [-c config.yaml] [-root ..] [-force]
+
+  preview   预览 以 unified diff 输出与现有文件的差异
+  create    生成代码 并在 .gva 目录记录回滚清单
+  rollback  回滚生成的代码与注入
+  verify    校验代码是否为最新 有差异时输出diff并以非0退出
+  openapi   输出生成接口的 OpenAPI 3.1 文档
+`
+
+// errOutdated verify 发现差异
+var errOutdated = errors.New("生成的代码不是最新的")
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	file := fs.String("f", "", "代码生成定义文件 yaml 或 json")
+	config := fs.String("c", "config.yaml", "配置文件 读取 autocode 配置")
+	root := fs.String("root", "..", "项目根目录")
+	force := fs.Bool("force", false, "create 时覆盖已存在的文件与回滚清单")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[2:])
+	if *file == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	err := loadConfig(*config, *root)
+	if err == nil {
+		err = run(command, *file, *force)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gva:", err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig 只读取代码生成需要的配置 不初始化日志与数据库
+func loadConfig(config string, root string) error {
+	v := viper.New()
+	v.SetConfigFile(config)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	if err := v.Unmarshal(&global.GVA_CONFIG); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	global.GVA_CONFIG.AutoCode.Root = abs
+	return nil
+}
+
+func run(command string, file string, force bool) error {
+	def, err := loadDefinition(file)
+	if err != nil {
+		return err
+	}
+	info := def.AutoCode
+	if err = utils.Verify(info, utils.AutoCodeVerify); err != nil {
+		return err
+	}
+	if err = info.Pretreatment(); err != nil {
+		return err
+	}
+	info.PackageT = utils.FirstUpper(info.Package)
+	entity := model.SysAutoCodePackage{PackageName: info.Package, Template: def.Template}
+
+	ctx := context.Background()
+	switch command {
+	case "preview", "verify":
+		files, templates, _, err := system.AutoCodeTemplate.Generate(ctx, info, entity)
+		if err != nil {
+			return err
+		}
+		changed, err := printDiff(files, injectedFiles(files, templates))
+		if err != nil {
+			return err
+		}
+		if command == "verify" && changed > 0 {
+			return fmt.Errorf("%w: %d 个文件存在差异", errOutdated, changed)
+		}
+		if changed == 0 {
+			fmt.Println("代码已是最新")
+		}
+		return nil
+	case "create":
+		return create(ctx, info, entity, force)
+	case "rollback":
+		return rollback(info)
+	case "openapi":
+		doc := openapi.New(info.Description, "1.0.0")
+		system.OpenApiServiceApp.AutoCode(doc, info, global.GVA_CONFIG.System.RouterPrefix)
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	default:
+		return fmt.Errorf("未知命令: %s", command)
+	}
+}
+
+func create(ctx context.Context, info request.AutoCode, entity model.SysAutoCodePackage, force bool) error {
+	path := manifestPath(info)
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("已经创建过此数据结构, 回滚清单: %s", path)
+	}
+	files, templates, injections, err := system.AutoCodeTemplate.Generate(ctx, info, entity)
+	if err != nil {
+		return err
+	}
+	if !force {
+		for _, created := range templates {
+			if _, err = os.Stat(created); err == nil {
+				return fmt.Errorf("[filepath:%s]文件已存在, 使用 -force 覆盖", created)
+			}
+		}
+	}
+	keys := sortedKeys(files)
+	for _, key := range keys {
+		if err = os.MkdirAll(filepath.Dir(key), os.ModePerm); err != nil {
+			return fmt.Errorf("[filepath:%s]创建文件夹失败: %w", key, err)
+		}
+		if err = os.WriteFile(key, []byte(files[key]), 0o666); err != nil {
+			return fmt.Errorf("[filepath:%s]写入文件失败: %w", key, err)
+		}
+		fmt.Println("写入", relPath(key))
+	}
+	return saveManifest(path, manifest{Request: info, Template: entity.Template, Templates: templates, Injections: injections})
+}
+
+func rollback(info request.AutoCode) error {
+	path := manifestPath(info)
+	m, err := loadManifest(path)
+	if err != nil {
+		return err
+	}
+	if err = system.AutocodeHistory.RollbackInjections(m.Injections, nil, false); err != nil {
+		return err
+	}
+	for _, created := range m.Templates {
+		if err = os.Remove(created); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("[filepath:%s]删除文件失败: %w", created, err)
+		}
+		fmt.Println("删除", relPath(created))
+	}
+	if err = os.Remove(path); err != nil {
+		return err
+	}
+	// 清单目录为空时一并删除
+	dir := filepath.Dir(path)
+	if os.Remove(dir) == nil {
+		_ = os.Remove(filepath.Dir(dir))
+	}
+	return nil
+}
+
+// injectedFiles 不是由模板创建的文件即为注入的文件
+func injectedFiles(files map[string]string, templates map[string]string) map[string]bool {
+	created := make(map[string]bool, len(templates))
+	for _, value := range templates {
+		created[value] = true
+	}
+	injected := make(map[string]bool, len(files))
+	for key := range files {
+		if !created[key] {
+			injected[key] = true
+		}
+	}
+	return injected
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func relPath(path string) string {
+	if rel, err := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"gopkg.in/yaml.v3"
+)
+
+// definition 代码生成定义文件
+type definition struct {
+	Template string `json:"template"` // 模板类型 package 或 plugin
+	request.AutoCode
+}
+
+// manifest 回滚清单 对应服务端的 SysAutoCodeHistory
+type manifest struct {
+	Request    request.AutoCode  `json:"request"`
+	Template   string            `json:"template"`
+	Templates  map[string]string `json:"templates"`  // 模板与生成文件的对应关系
+	Injections map[string]string `json:"injections"` // 注入类型与序列化后的注入信息
+}
+
+// loadDefinition 读取定义文件 yaml 先转换为 json 以复用 request.AutoCode 的 json 标签
+func loadDefinition(file string) (def definition, err error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return def, err
+	}
+	if ext := strings.ToLower(filepath.Ext(file)); ext == ".yaml" || ext == ".yml" {
+		var raw map[string]interface{}
+		if err = yaml.Unmarshal(content, &raw); err != nil {
+			return def, fmt.Errorf("解析定义文件失败: %w", err)
+		}
+		if content, err = json.Marshal(raw); err != nil {
+			return def, err
+		}
+	}
+	if err = json.Unmarshal(content, &def); err != nil {
+		return def, fmt.Errorf("解析定义文件失败: %w", err)
+	}
+	if def.Template == "" {
+		def.Template = "package"
+	}
+	if def.Template != "package" && def.Template != "plugin" {
+		return def, fmt.Errorf("不支持的模板类型: %s", def.Template)
+	}
+	return def, nil
+}
+
+func manifestPath(info request.AutoCode) string {
+	name := fmt.Sprintf("%s_%s.json", info.Package, info.StructName)
+	return filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, ".gva", "autocode", name)
+}
+
+func saveManifest(path string, m manifest) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+func loadManifest(path string) (m manifest, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, fmt.Errorf("未找到回滚清单 %s, 请确认代码由 gva create 生成", path)
+		}
+		return m, err
+	}
+	err = json.Unmarshal(content, &m)
+	return m, err
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
+func TestLoadDefinition(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		template string
+		wantErr  bool
+	}{
+		{
+			name:     "yaml 默认 package 模板",
+			file:     "book.yaml",
+			content:  "package: demo\nstructName: Book\nfields:\n  - fieldName: Title\n    fieldType: string\n",
+			template: "package",
+		},
+		{
+			name:     "json plugin 模板",
+			file:     "book.json",
+			content:  `{"template":"plugin","package":"demo","structName":"Book","fields":[{"fieldName":"Title","fieldType":"string"}]}`,
+			template: "plugin",
+		},
+		{
+			name:    "不支持的模板类型",
+			file:    "bad.yml",
+			content: "template: web\npackage: demo\n",
+			wantErr: true,
+		},
+		{
+			name:    "格式错误",
+			file:    "bad.json",
+			content: "{",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			writeFile(t, path, tt.content)
+			def, err := loadDefinition(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadDefinition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if def.Template != tt.template || def.Package != "demo" || def.StructName != "Book" {
+				t.Errorf("loadDefinition() = %+v", def)
+			}
+			if len(def.Fields) != 1 || def.Fields[0].FieldName != "Title" || def.Fields[0].FieldType != "string" {
+				t.Errorf("loadDefinition() fields = %+v", def.Fields)
+			}
+		})
+	}
+}
+
+func TestManifest(t *testing.T) {
+	root := setRoot(t)
+	info := request.AutoCode{Package: "demo", StructName: "Book"}
+	path := manifestPath(info)
+	if want := filepath.Join(root, "server", ".gva", "autocode", "demo_Book.json"); path != want {
+		t.Fatalf("manifestPath() = %s, want %s", path, want)
+	}
+	if _, err := loadManifest(path); err == nil || !strings.Contains(err.Error(), "gva create") {
+		t.Fatalf("loadManifest() without manifest should point to gva create: %v", err)
+	}
+
+	m := manifest{
+		Request:    info,
+		Template:   "package",
+		Templates:  map[string]string{"model.go.tpl": filepath.Join(root, "server", "model", "demo", "book.go")},
+		Injections: map[string]string{"PackageInitializeGorm": `{"StructName":"Book"}`},
+	}
+	if err := saveManifest(path, m); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal(content, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"request", "template", "templates", "injections"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("manifest should contain %q:\n%s", key, content)
+		}
+	}
+	got, err := loadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Template != m.Template || got.Request.StructName != "Book" || !reflect.DeepEqual(got.Templates, m.Templates) || !reflect.DeepEqual(got.Injections, m.Injections) {
+		t.Errorf("loadManifest() = %+v, want %+v", got, m)
+	}
+}
@@ -0,0 +1,7 @@
+package config
+
+type Cache struct {
+	Size     int  `mapstructure:"size" json:"size" yaml:"size"`                // 进程内缓存的最大条目数 默认10000
+	TTL      int  `mapstructure:"ttl" json:"ttl" yaml:"ttl"`                   // 缓存过期时间(秒) 默认600
+	UseRedis bool `mapstructure:"use-redis" json:"use-redis" yaml:"use-redis"` // 使用redis作为二级缓存并通过发布订阅同步各实例的失效 需开启 system.use-redis
+}
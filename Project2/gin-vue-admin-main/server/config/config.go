@@ -25,6 +25,18 @@ type Server struct {
 	TencentCOS   TencentCOS   `mapstructure:"tencent-cos" json:"tencent-cos" yaml:"tencent-cos"`
 	AwsS3        AwsS3        `mapstructure:"aws-s3" json:"aws-s3" yaml:"aws-s3"`
 	CloudflareR2 CloudflareR2 `mapstructure:"cloudflare-r2" json:"cloudflare-r2" yaml:"cloudflare-r2"`
+	FileLibrary  FileLibrary  `mapstructure:"file-library" json:"file-library" yaml:"file-library"`
+	Tus          Tus          `mapstructure:"tus" json:"tus" yaml:"tus"`
+
+	Retention Retention `mapstructure:"retention" json:"retention" yaml:"retention"`
+
+	// 菜单 字典 角色等热点数据的缓存
+	Cache Cache `mapstructure:"cache" json:"cache" yaml:"cache"`
+
+	// Prometheus 指标
+	Metrics Metrics `mapstructure:"metrics" json:"metrics" yaml:"metrics"`
+	// OpenTelemetry 链路追踪
+	Tracing Tracing `mapstructure:"tracing" json:"tracing" yaml:"tracing"`
 
 	Excel Excel `mapstructure:"excel" json:"excel" yaml:"excel"`
 
@@ -32,4 +44,9 @@ type Server struct {
 
 	// 跨域配置
 	Cors CORS `mapstructure:"cors" json:"cors" yaml:"cors"`
+
+	// 进程外插件
+	PluginHost PluginHost `mapstructure:"plugin-host" json:"plugin-host" yaml:"plugin-host"`
+	// 插件签名与安装检查
+	PluginSecurity PluginSecurity `mapstructure:"plugin-security" json:"plugin-security" yaml:"plugin-security"`
 }
@@ -27,6 +27,38 @@ type GeneralDB struct {
 	MaxOpenConns int    `mapstructure:"max-open-conns" json:"max-open-conns" yaml:"max-open-conns"` // 打开到数据库的最大连接数
 	Singular     bool   `mapstructure:"singular" json:"singular" yaml:"singular"`                   // 是否开启全局禁用复数，true表示开启
 	LogZap       bool   `mapstructure:"log-zap" json:"log-zap" yaml:"log-zap"`                      // 是否通过zap写入日志文件
+
+	// 读写分离 配置了只读副本时查询路由到健康的副本 事务中 请求内写入后 以及本进程写入后的 replica-sticky 时长内使用主库
+	Replicas        []Replica `mapstructure:"replicas" json:"replicas" yaml:"replicas"`                         // 只读副本 为空时不开启读写分离
+	ReplicaMaxLag   int       `mapstructure:"replica-max-lag" json:"replica-max-lag" yaml:"replica-max-lag"`    // 允许的最大复制延迟(秒) 超过时摘除副本 0为不检查延迟
+	ReplicaInterval int       `mapstructure:"replica-interval" json:"replica-interval" yaml:"replica-interval"` // 副本健康检查间隔(秒) 默认10
+	ReplicaSticky   int       `mapstructure:"replica-sticky" json:"replica-sticky" yaml:"replica-sticky"`       // 写入后未传入请求 ctx 的查询使用主库的时长(秒) 默认与 replica-max-lag 相同
+}
+
+// Replica 只读副本 未填写的字段沿用主库的配置
+type Replica struct {
+	Path     string `mapstructure:"path" json:"path" yaml:"path"`             // 副本地址
+	Port     string `mapstructure:"port" json:"port" yaml:"port"`             // 副本端口
+	Username string `mapstructure:"username" json:"username" yaml:"username"` // 副本账号
+	Password string `mapstructure:"password" json:"password" yaml:"password"` // 副本密码
+}
+
+// Replica 以副本的连接信息覆盖主库配置 得到副本的配置
+func (c GeneralDB) Replica(r Replica) GeneralDB {
+	c.Replicas = nil
+	if r.Path != "" {
+		c.Path = r.Path
+	}
+	if r.Port != "" {
+		c.Port = r.Port
+	}
+	if r.Username != "" {
+		c.Username = r.Username
+	}
+	if r.Password != "" {
+		c.Password = r.Password
+	}
+	return c
 }
 
 func (c GeneralDB) LogLevel() logger.LogLevel {
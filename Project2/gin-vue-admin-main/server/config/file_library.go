@@ -0,0 +1,13 @@
+package config
+
+type FileLibrary struct {
+	MaxSize       int64            `mapstructure:"max-size" json:"max-size" yaml:"max-size"`                   // 单文件大小上限(MB) 0为不限制
+	UserQuota     int64            `mapstructure:"user-quota" json:"user-quota" yaml:"user-quota"`             // 每个用户默认存储配额(MB) 0为不限制
+	RoleQuota     map[string]int64 `mapstructure:"role-quota" json:"role-quota" yaml:"role-quota"`             // 角色存储配额(MB) key为角色ID 角色下所有用户共享
+	AllowMime     []string         `mapstructure:"allow-mime" json:"allow-mime" yaml:"allow-mime"`             // 允许上传的MIME 支持 image/* 通配 为空不限制
+	DenyMime      []string         `mapstructure:"deny-mime" json:"deny-mime" yaml:"deny-mime"`                // 禁止上传的MIME 优先于allow-mime
+	StripExif     bool             `mapstructure:"strip-exif" json:"strip-exif" yaml:"strip-exif"`             // 是否去除JPEG中的EXIF等元数据
+	ThumbWidth    int              `mapstructure:"thumb-width" json:"thumb-width" yaml:"thumb-width"`          // 缩略图最大宽度 0不生成缩略图
+	ThumbHeight   int              `mapstructure:"thumb-height" json:"thumb-height" yaml:"thumb-height"`       // 缩略图最大高度
+	PresignExpire int              `mapstructure:"presign-expire" json:"presign-expire" yaml:"presign-expire"` // 直传签名地址有效期(分钟) 0时默认15分钟
+}
@@ -0,0 +1,10 @@
+package config
+
+type Metrics struct {
+	Enabled bool   `mapstructure:"enabled" json:"enabled" yaml:"enabled"` // 开启指标采集与指标接口
+	Path    string `mapstructure:"path" json:"path" yaml:"path"`          // Prometheus 采集接口路径 不带路由前缀 默认 /metrics
+	Token   string `mapstructure:"token" json:"token" yaml:"token"`       // 采集接口的 Bearer Token 为空时不开放采集接口 只能通过 /system/getMetrics 查看
+	HTTP    bool   `mapstructure:"http" json:"http" yaml:"http"`          // 采集接口请求数与耗时
+	Gorm    bool   `mapstructure:"gorm" json:"gorm" yaml:"gorm"`          // 采集数据库语句耗时与错误
+	Redis   bool   `mapstructure:"redis" json:"redis" yaml:"redis"`       // 采集redis命令与连接池
+}
@@ -0,0 +1,16 @@
+package config
+
+type PluginHost struct {
+	StartTimeout   int            `mapstructure:"start-timeout" json:"start-timeout" yaml:"start-timeout"`       // 等待插件握手的时长(秒)
+	RequestTimeout int            `mapstructure:"request-timeout" json:"request-timeout" yaml:"request-timeout"` // 代理请求的超时时长(秒)
+	HealthInterval int            `mapstructure:"health-interval" json:"health-interval" yaml:"health-interval"` // 健康检查间隔(秒) 连续3次失败将重启插件
+	MaxBodySize    int            `mapstructure:"max-body-size" json:"max-body-size" yaml:"max-body-size"`       // 代理请求体的大小上限(MB)
+	Plugins        []RemotePlugin `mapstructure:"plugins" json:"plugins" yaml:"plugins"`                         // 进程外插件
+}
+
+type RemotePlugin struct {
+	Name string   `mapstructure:"name" json:"name" yaml:"name"` // 插件名 与插件 Describe 返回的名称一致
+	Path string   `mapstructure:"path" json:"path" yaml:"path"` // 可执行文件路径
+	Args []string `mapstructure:"args" json:"args" yaml:"args"` // 启动参数
+	Env  []string `mapstructure:"env" json:"env" yaml:"env"`    // 额外的环境变量 格式为 KEY=VALUE
+}
@@ -0,0 +1,14 @@
+package config
+
+type PluginSecurity struct {
+	RequireSignature bool               `mapstructure:"require-signature" json:"require-signature" yaml:"require-signature"` // 只允许安装信任库中的密钥签名的插件
+	TrustedKeys      []PluginTrustedKey `mapstructure:"trusted-keys" json:"trusted-keys" yaml:"trusted-keys"`                // 信任库
+	SigningKeyID     string             `mapstructure:"signing-key-id" json:"signing-key-id" yaml:"signing-key-id"`          // 打包插件时使用的密钥ID
+	SigningKey       string             `mapstructure:"signing-key" json:"signing-key" yaml:"signing-key"`                   // 打包插件时使用的私钥 base64 为空时不签名
+	ForbiddenImports []string           `mapstructure:"forbidden-imports" json:"forbidden-imports" yaml:"forbidden-imports"` // 禁止未签名插件引用的包 以 /... 结尾时包含子包
+}
+
+type PluginTrustedKey struct {
+	ID        string `mapstructure:"id" json:"id" yaml:"id"`                         // 密钥ID
+	PublicKey string `mapstructure:"public-key" json:"public-key" yaml:"public-key"` // 公钥 base64
+}
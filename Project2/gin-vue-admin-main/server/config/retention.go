@@ -0,0 +1,14 @@
+package config
+
+type Retention struct {
+	BatchSize  int             `mapstructure:"batch-size" json:"batch-size" yaml:"batch-size"`    // 每批删除的行数 避免长时间锁表
+	ArchiveDir string          `mapstructure:"archive-dir" json:"archive-dir" yaml:"archive-dir"` // 归档文件目录
+	Rules      []RetentionRule `mapstructure:"rules" json:"rules" yaml:"rules"`                   // 内置保留规则 首次启动时写入数据库 之后在数据保留策略中维护
+}
+
+type RetentionRule struct {
+	Table    string `mapstructure:"table" json:"table" yaml:"table"`          // 表名
+	Column   string `mapstructure:"column" json:"column" yaml:"column"`       // 时间字段
+	Interval string `mapstructure:"interval" json:"interval" yaml:"interval"` // 保留时长 如 2160h 或 90d
+	Archive  string `mapstructure:"archive" json:"archive" yaml:"archive"`    // 删除前归档 空:不归档 file:本地文件 oss:对象存储
+}
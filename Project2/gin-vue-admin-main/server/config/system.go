@@ -1,13 +1,17 @@
 package config
 
 type System struct {
-	DbType        string `mapstructure:"db-type" json:"db-type" yaml:"db-type"`    // 数据库类型:mysql(默认)|sqlite|sqlserver|postgresql
-	OssType       string `mapstructure:"oss-type" json:"oss-type" yaml:"oss-type"` // Oss类型
-	RouterPrefix  string `mapstructure:"router-prefix" json:"router-prefix" yaml:"router-prefix"`
-	Addr          int    `mapstructure:"addr" json:"addr" yaml:"addr"` // 端口值
-	LimitCountIP  int    `mapstructure:"iplimit-count" json:"iplimit-count" yaml:"iplimit-count"`
-	LimitTimeIP   int    `mapstructure:"iplimit-time" json:"iplimit-time" yaml:"iplimit-time"`
-	UseMultipoint bool   `mapstructure:"use-multipoint" json:"use-multipoint" yaml:"use-multipoint"` // 多点登录拦截
-	UseRedis      bool   `mapstructure:"use-redis" json:"use-redis" yaml:"use-redis"`                // 使用redis
-	UseMongo      bool   `mapstructure:"use-mongo" json:"use-mongo" yaml:"use-mongo"`                // 使用mongo
+	DbType          string `mapstructure:"db-type" json:"db-type" yaml:"db-type"`          // 数据库类型:mysql(默认)|sqlite|sqlserver|postgresql
+	OssType         string `mapstructure:"oss-type" json:"oss-type" yaml:"oss-type"`       // Oss类型
+	OssMirror       string `mapstructure:"oss-mirror" json:"oss-mirror" yaml:"oss-mirror"` // 镜像Oss类型 为空不开启 开启后同时写入oss-type与oss-mirror 读取时主存储不可用则回退到镜像
+	RouterPrefix    string `mapstructure:"router-prefix" json:"router-prefix" yaml:"router-prefix"`
+	Addr            int    `mapstructure:"addr" json:"addr" yaml:"addr"` // 端口值
+	LimitCountIP    int    `mapstructure:"iplimit-count" json:"iplimit-count" yaml:"iplimit-count"`
+	LimitTimeIP     int    `mapstructure:"iplimit-time" json:"iplimit-time" yaml:"iplimit-time"`
+	UseMultipoint   bool   `mapstructure:"use-multipoint" json:"use-multipoint" yaml:"use-multipoint"`       // 多点登录拦截
+	UseRedis        bool   `mapstructure:"use-redis" json:"use-redis" yaml:"use-redis"`                      // 使用redis
+	UseMongo        bool   `mapstructure:"use-mongo" json:"use-mongo" yaml:"use-mongo"`                      // 使用mongo
+	OpenApiValidate bool   `mapstructure:"openapi-validate" json:"openapi-validate" yaml:"openapi-validate"` // 按OpenAPI文档校验请求
+	AutoMigrate     bool   `mapstructure:"auto-migrate" json:"auto-migrate" yaml:"auto-migrate"`             // 启动时执行未执行的迁移 关闭时数据库结构落后将拒绝启动
+	SecretKey       string `mapstructure:"secret-key" json:"secret-key" yaml:"secret-key"`                   // 加密保存在数据库中的敏感信息 为空时无法在系统中维护业务库
 }
@@ -0,0 +1,13 @@
+package config
+
+type Tracing struct {
+	Enabled     bool              `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                // 开启链路追踪 关闭时不创建也不导出 span
+	ServiceName string            `mapstructure:"service-name" json:"service-name" yaml:"service-name"` // 服务名 默认 gin-vue-admin
+	Endpoint    string            `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`             // OTLP/HTTP 采集端地址 host:port
+	URLPath     string            `mapstructure:"url-path" json:"url-path" yaml:"url-path"`             // 采集端路径 默认 /v1/traces
+	Insecure    bool              `mapstructure:"insecure" json:"insecure" yaml:"insecure"`             // 使用 http 而不是 https 连接采集端
+	Headers     map[string]string `mapstructure:"headers" json:"headers" yaml:"headers"`                // 发送到采集端的附加请求头
+	SampleRatio float64           `mapstructure:"sample-ratio" json:"sample-ratio" yaml:"sample-ratio"` // 采样比例 0-1 上游已采样的请求始终采样
+	Gorm        bool              `mapstructure:"gorm" json:"gorm" yaml:"gorm"`                         // 追踪数据库语句
+	Redis       bool              `mapstructure:"redis" json:"redis" yaml:"redis"`                      // 追踪redis命令
+}
@@ -0,0 +1,7 @@
+package config
+
+type Tus struct {
+	ChunkStore string `mapstructure:"chunk-store" json:"chunk-store" yaml:"chunk-store"` // 分片存储 disk:本地磁盘 oss:当前对象存储(多实例部署时使用)
+	ChunkDir   string `mapstructure:"chunk-dir" json:"chunk-dir" yaml:"chunk-dir"`       // disk 存储的分片目录
+	Expire     int    `mapstructure:"expire" json:"expire" yaml:"expire"`                // 未完成上传的保留时长(小时) 过期由定时任务清理
+}
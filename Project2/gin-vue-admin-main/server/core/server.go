@@ -17,6 +17,8 @@ func RunWindowsServer() {
 		// 初始化redis服务
 		initialize.Redis()
 	}
+	// 初始化多级缓存 开启 cache.use-redis 时使用上面的redis
+	initialize.Cache()
 	if global.GVA_CONFIG.System.UseMongo {
 		err := initialize.Mongo.Initialization()
 		if err != nil {
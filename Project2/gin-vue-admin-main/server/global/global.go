@@ -2,7 +2,9 @@ package global
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	"github.com/qiniu/qmgo"
+	"sort"
 	"sync"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/timer"
@@ -36,6 +38,12 @@ var (
 	lock                    sync.RWMutex
 )
 
+// ErrDBNotFound 业务库不存在或未启用
+var ErrDBNotFound = errors.New("db no init")
+
+// dbInfos 已注册业务库的配置 包含配置文件中禁用的业务库
+var dbInfos = make(map[string]config.SpecializedDB)
+
 // GetGlobalDBByDBName 通过名称获取db list中的db
 func GetGlobalDBByDBName(dbname string) *gorm.DB {
 	lock.RLock()
@@ -43,13 +51,75 @@ func GetGlobalDBByDBName(dbname string) *gorm.DB {
 	return GVA_DBList[dbname]
 }
 
-// MustGetGlobalDBByDBName 通过名称获取db 如果不存在则panic
-func MustGetGlobalDBByDBName(dbname string) *gorm.DB {
+// FindGlobalDBByDBName 通过名称获取db 不存在时返回 ErrDBNotFound
+func FindGlobalDBByDBName(dbname string) (*gorm.DB, error) {
 	lock.RLock()
 	defer lock.RUnlock()
 	db, ok := GVA_DBList[dbname]
 	if !ok || db == nil {
-		panic("db no init")
+		return nil, errors.Wrap(ErrDBNotFound, dbname)
+	}
+	return db, nil
+}
+
+// MustGetGlobalDBByDBName 通过名称获取db 如果不存在则返回携带 ErrDBNotFound 的db 在执行时返回错误
+func MustGetGlobalDBByDBName(dbname string) *gorm.DB {
+	db, err := FindGlobalDBByDBName(dbname)
+	if err == nil {
+		return db
+	}
+	if GVA_DB == nil {
+		panic(err)
 	}
+	db = GVA_DB.Session(&gorm.Session{NewDB: true})
+	_ = db.AddError(err)
 	return db
 }
+
+// SetGlobalDB 注册或替换业务库 返回被替换的旧连接 由调用方在旧连接上的请求结束后关闭
+// db 为 nil 时只记录配置 用于禁用的业务库
+func SetGlobalDB(info config.SpecializedDB, db *gorm.DB) (old *gorm.DB) {
+	lock.Lock()
+	defer lock.Unlock()
+	if GVA_DBList == nil {
+		GVA_DBList = make(map[string]*gorm.DB)
+	}
+	old = GVA_DBList[info.AliasName]
+	if db == nil {
+		delete(GVA_DBList, info.AliasName)
+	} else {
+		GVA_DBList[info.AliasName] = db
+	}
+	dbInfos[info.AliasName] = info
+	return old
+}
+
+// RemoveGlobalDB 移除业务库 返回被移除的连接
+func RemoveGlobalDB(dbname string) (old *gorm.DB) {
+	lock.Lock()
+	defer lock.Unlock()
+	old = GVA_DBList[dbname]
+	delete(GVA_DBList, dbname)
+	delete(dbInfos, dbname)
+	return old
+}
+
+// GetGlobalDBInfo 通过名称获取业务库的配置
+func GetGlobalDBInfo(dbname string) (config.SpecializedDB, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	info, ok := dbInfos[dbname]
+	return info, ok
+}
+
+// GetGlobalDBInfoList 获取所有业务库的配置 按名称排序
+func GetGlobalDBInfoList() []config.SpecializedDB {
+	lock.RLock()
+	defer lock.RUnlock()
+	list := make([]config.SpecializedDB, 0, len(dbInfos))
+	for _, info := range dbInfos {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].AliasName < list[j].AliasName })
+	return list
+}
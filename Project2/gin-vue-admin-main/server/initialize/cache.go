@@ -0,0 +1,32 @@
+package initialize
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/cache"
+	"go.uber.org/zap"
+)
+
+// Cache 初始化多级缓存 需在 Redis 之后调用
+func Cache() {
+	cfg := global.GVA_CONFIG.Cache
+	options := cache.Options{Size: cfg.Size, TTL: time.Duration(cfg.TTL) * time.Second}
+	if cfg.UseRedis {
+		if global.GVA_REDIS == nil {
+			global.GVA_LOG.Warn("cache.use-redis 已开启但未初始化redis 仅使用进程内缓存")
+		} else {
+			options.Redis = global.GVA_REDIS
+		}
+	}
+	cache.Setup(options)
+	// 其他实例修改了策略 重新从数据库加载
+	cache.OnInvalidate(system.CasbinCacheNamespace, func() {
+		if e := system.CasbinServiceApp.Casbin(); e != nil {
+			if err := e.LoadPolicy(); err != nil {
+				global.GVA_LOG.Error("重新加载casbin策略失败", zap.Error(err))
+			}
+		}
+	})
+}
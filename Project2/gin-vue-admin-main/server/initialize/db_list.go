@@ -3,34 +3,44 @@ package initialize
 import (
 	"github.com/flipped-aurora/gin-vue-admin/server/config"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 const sys = "system"
 
 func DBList() {
-	dbMap := make(map[string]*gorm.DB)
 	for _, info := range global.GVA_CONFIG.DBList {
-		if info.Disable {
-			continue
-		}
-		switch info.Type {
-		case "mysql":
-			dbMap[info.AliasName] = GormMysqlByConfig(config.Mysql{GeneralDB: info.GeneralDB})
-		case "mssql":
-			dbMap[info.AliasName] = GormMssqlByConfig(config.Mssql{GeneralDB: info.GeneralDB})
-		case "pgsql":
-			dbMap[info.AliasName] = GormPgSqlByConfig(config.Pgsql{GeneralDB: info.GeneralDB})
-		case "oracle":
-			dbMap[info.AliasName] = GormOracleByConfig(config.Oracle{GeneralDB: info.GeneralDB})
-		default:
-			continue
+		var db *gorm.DB
+		if !info.Disable {
+			switch info.Type {
+			case "mysql":
+				db = GormMysqlByConfig(config.Mysql{GeneralDB: info.GeneralDB})
+			case "mssql":
+				db = GormMssqlByConfig(config.Mssql{GeneralDB: info.GeneralDB})
+			case "pgsql":
+				db = GormPgSqlByConfig(config.Pgsql{GeneralDB: info.GeneralDB})
+			case "oracle":
+				db = GormOracleByConfig(config.Oracle{GeneralDB: info.GeneralDB})
+			default:
+				continue
+			}
 		}
+		useMetrics(db, info.AliasName)
+		useTracing(db, info.AliasName)
+		global.SetGlobalDB(info, db)
 	}
 	// 做特殊判断,是否有迁移
 	// 适配低版本迁移多数据库版本
-	if sysDB, ok := dbMap[sys]; ok {
+	if sysDB := global.GetGlobalDBByDBName(sys); sysDB != nil {
 		global.GVA_DB = sysDB
 	}
-	global.GVA_DBList = dbMap
+}
+
+// DBConnections 加载在系统中维护的业务库 连接失败的业务库跳过并记录日志
+func DBConnections() {
+	if err := system.DBConnectionServiceApp.LoadDBConnections(); err != nil {
+		global.GVA_LOG.Error("加载业务库失败!", zap.Error(err))
+	}
 }
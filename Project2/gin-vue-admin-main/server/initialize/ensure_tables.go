@@ -2,10 +2,8 @@ package initialize
 
 import (
 	"context"
-	adapter "github.com/casbin/gorm-adapter/v3"
-	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
-	sysModel "github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/migrate"
 	"gorm.io/gorm"
 )
 
@@ -34,37 +32,10 @@ func (e *ensureTables) MigrateTable(ctx context.Context) (context.Context, error
 	if !ok {
 		return ctx, system.ErrMissingDBContext
 	}
-	tables := []interface{}{
-		sysModel.SysApi{},
-		sysModel.SysUser{},
-		sysModel.SysBaseMenu{},
-		sysModel.SysAuthority{},
-		sysModel.JwtBlacklist{},
-		sysModel.SysDictionary{},
-		sysModel.SysAutoCodeHistory{},
-		sysModel.SysOperationRecord{},
-		sysModel.SysDictionaryDetail{},
-		sysModel.SysBaseMenuParameter{},
-		sysModel.SysBaseMenuBtn{},
-		sysModel.SysAuthorityBtn{},
-		sysModel.SysAutoCodePackage{},
-		sysModel.SysExportTemplate{},
-		sysModel.Condition{},
-		sysModel.JoinTemplate{},
-
-		adapter.CasbinRule{},
-
-		example.ExaFile{},
-		example.ExaCustomer{},
-		example.ExaFileChunk{},
-		example.ExaFileUploadAndDownload{},
-	}
-	for _, t := range tables {
-		_ = db.AutoMigrate(&t)
-		// 视图 authority_menu 会被当成表来创建，引发冲突错误（更新版本的gorm似乎不会）
-		// 由于 AutoMigrate() 基本无需考虑错误，因此显式忽略
-	}
-	return ctx, nil
+	dbType, _ := ctx.Value("dbtype").(string)
+	// 新建的数据库按模型建表 并把各模块已有的迁移记为已执行
+	_, err := migrate.New(db, dbType).Up(ctx)
+	return ctx, err
 }
 
 func (e *ensureTables) TableCreated(ctx context.Context) bool {
@@ -72,34 +43,6 @@ func (e *ensureTables) TableCreated(ctx context.Context) bool {
 	if !ok {
 		return false
 	}
-	tables := []interface{}{
-		sysModel.SysApi{},
-		sysModel.SysUser{},
-		sysModel.SysBaseMenu{},
-		sysModel.SysAuthority{},
-		sysModel.JwtBlacklist{},
-		sysModel.SysDictionary{},
-		sysModel.SysAutoCodeHistory{},
-		sysModel.SysOperationRecord{},
-		sysModel.SysDictionaryDetail{},
-		sysModel.SysBaseMenuParameter{},
-		sysModel.SysBaseMenuBtn{},
-		sysModel.SysAuthorityBtn{},
-		sysModel.SysAutoCodePackage{},
-		sysModel.SysExportTemplate{},
-		sysModel.Condition{},
-		sysModel.JoinTemplate{},
-
-		adapter.CasbinRule{},
-
-		example.ExaFile{},
-		example.ExaCustomer{},
-		example.ExaFileChunk{},
-		example.ExaFileUploadAndDownload{},
-	}
-	yes := true
-	for _, t := range tables {
-		yes = yes && db.Migrator().HasTable(t)
-	}
-	return yes
+	dbType, _ := ctx.Value("dbtype").(string)
+	return migrate.New(db, dbType).Check(ctx) == nil
 }
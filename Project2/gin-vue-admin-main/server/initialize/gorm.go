@@ -1,69 +1,69 @@
 package initialize
 
 import (
+	"context"
 	"os"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
-	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
-	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/migrate"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 func Gorm() *gorm.DB {
+	var db *gorm.DB
 	switch global.GVA_CONFIG.System.DbType {
 	case "mysql":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Mysql.Dbname
-		return GormMysql()
+		db = GormMysql()
 	case "pgsql":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Pgsql.Dbname
-		return GormPgSql()
+		db = GormPgSql()
 	case "oracle":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Oracle.Dbname
-		return GormOracle()
+		db = GormOracle()
 	case "mssql":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Mssql.Dbname
-		return GormMssql()
+		db = GormMssql()
 	case "sqlite":
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Sqlite.Dbname
-		return GormSqlite()
+		db = GormSqlite()
 	default:
 		global.GVA_ACTIVE_DBNAME = &global.GVA_CONFIG.Mysql.Dbname
-		return GormMysql()
+		db = GormMysql()
 	}
+	useMetrics(db, sys)
+	useTracing(db, sys)
+	return db
 }
 
+// RegisterTables 检查数据库结构是否为最新 存在未执行或被修改的迁移时拒绝启动
 func RegisterTables() {
-	db := global.GVA_DB
-	err := db.AutoMigrate(
-
-		system.SysApi{},
-		system.SysIgnoreApi{},
-		system.SysUser{},
-		system.SysBaseMenu{},
-		system.JwtBlacklist{},
-		system.SysAuthority{},
-		system.SysDictionary{},
-		system.SysOperationRecord{},
-		system.SysAutoCodeHistory{},
-		system.SysDictionaryDetail{},
-		system.SysBaseMenuParameter{},
-		system.SysBaseMenuBtn{},
-		system.SysAuthorityBtn{},
-		system.SysAutoCodePackage{},
-		system.SysExportTemplate{},
-		system.Condition{},
-		system.JoinTemplate{},
-
-		example.ExaFile{},
-		example.ExaCustomer{},
-		example.ExaFileChunk{},
-		example.ExaFileUploadAndDownload{},
-	)
+	ctx := context.Background()
+	m := migrate.New(global.GVA_DB, global.GVA_CONFIG.System.DbType)
+	if global.GVA_CONFIG.System.AutoMigrate {
+		done, err := m.Up(ctx)
+		for _, state := range done {
+			global.GVA_LOG.Info("migrate success", zap.String("module", state.Module), zap.String("version", state.Version), zap.String("name", state.Name))
+		}
+		if err != nil {
+			global.GVA_LOG.Error("migrate failed", zap.Error(err))
+			os.Exit(1)
+		}
+	}
+	states, err := m.Status(ctx)
+	if err == nil {
+		for _, state := range states {
+			if state.Status == migrate.StatusMissing {
+				global.GVA_LOG.Warn("迁移已执行但当前版本中不存在该迁移脚本", zap.String("module", state.Module), zap.String("version", state.Version), zap.String("name", state.Name))
+			}
+		}
+		err = migrate.Behind(states)
+	}
 	if err != nil {
-		global.GVA_LOG.Error("register table failed", zap.Error(err))
-		os.Exit(0)
+		global.GVA_LOG.Error("数据库结构不是最新的 请先执行 go run main.go migrate up 或开启 system.auto-migrate", zap.Error(err))
+		os.Exit(1)
 	}
 
 	err = bizModel()
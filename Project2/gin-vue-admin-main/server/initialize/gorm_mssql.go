@@ -34,6 +34,7 @@ func GormMssql() *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(m.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(m.MaxOpenConns)
+		useReplicas(db, "mssql", m.GeneralDB)
 		return db
 	}
 }
@@ -54,6 +55,7 @@ func GormMssqlByConfig(m config.Mssql) *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(m.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(m.MaxOpenConns)
+		useReplicas(db, "mssql", m.GeneralDB)
 		return db
 	}
 }
@@ -29,6 +29,7 @@ func GormMysql() *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(m.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(m.MaxOpenConns)
+		useReplicas(db, "mysql", m.GeneralDB)
 		return db
 	}
 }
@@ -50,6 +51,7 @@ func GormMysqlByConfig(m config.Mysql) *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(m.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(m.MaxOpenConns)
+		useReplicas(db, "mysql", m.GeneralDB)
 		return db
 	}
 }
@@ -28,6 +28,7 @@ func GormOracle() *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(m.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(m.MaxOpenConns)
+		useReplicas(db, "oracle", m.GeneralDB)
 		return db
 	}
 }
@@ -47,6 +48,7 @@ func GormOracleByConfig(m config.Oracle) *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(m.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(m.MaxOpenConns)
+		useReplicas(db, "oracle", m.GeneralDB)
 		return db
 	}
 }
@@ -26,6 +26,7 @@ func GormPgSql() *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(p.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(p.MaxOpenConns)
+		useReplicas(db, "pgsql", p.GeneralDB)
 		return db
 	}
 }
@@ -45,6 +46,7 @@ func GormPgSqlByConfig(p config.Pgsql) *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(p.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(p.MaxOpenConns)
+		useReplicas(db, "pgsql", p.GeneralDB)
 		return db
 	}
 }
@@ -0,0 +1,69 @@
+package initialize
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/initialize/internal"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/replica"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+// useReplicas 配置了只读副本时为数据库开启读写分离
+// 副本连接失败不影响启动 由健康检查在副本可用后再加入
+func useReplicas(db *gorm.DB, dbType string, general config.GeneralDB) {
+	if len(general.Replicas) == 0 {
+		return
+	}
+	replicas := make(map[string]*gorm.DB, len(general.Replicas))
+	for _, r := range general.Replicas {
+		conf := general.Replica(r)
+		gormConfig := internal.Gorm.Config(conf.Prefix, conf.Singular)
+		gormConfig.DisableAutomaticPing = true
+		replicaDB, err := gorm.Open(replicaDialector(dbType, conf), gormConfig)
+		if err != nil {
+			global.GVA_LOG.Error("连接只读副本失败!", zap.String("replica", conf.Path+":"+conf.Port), zap.Error(err))
+			continue
+		}
+		sqlDB, _ := replicaDB.DB()
+		sqlDB.SetMaxIdleConns(conf.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(conf.MaxOpenConns)
+		replicas[conf.Path+":"+conf.Port] = replicaDB
+	}
+	resolver := replica.New(replica.Options{
+		Dialect:  dbType,
+		MaxLag:   time.Duration(general.ReplicaMaxLag) * time.Second,
+		Interval: time.Duration(general.ReplicaInterval) * time.Second,
+		Sticky:   time.Duration(general.ReplicaSticky) * time.Second,
+	}, replicas)
+	if err := db.Use(resolver); err != nil {
+		global.GVA_LOG.Error("开启读写分离失败!", zap.Error(err))
+	}
+}
+
+// replicaDialector 副本只提供连接 SQL 仍由主库的方言生成 因此跳过版本探测
+func replicaDialector(dbType string, conf config.GeneralDB) gorm.Dialector {
+	switch dbType {
+	case "pgsql":
+		p := config.Pgsql{GeneralDB: conf}
+		return postgres.New(postgres.Config{DSN: p.Dsn()})
+	case "mssql":
+		m := config.Mssql{GeneralDB: conf}
+		return sqlserver.New(sqlserver.Config{DSN: m.Dsn()})
+	case "oracle":
+		m := config.Oracle{GeneralDB: conf}
+		return mysql.New(mysql.Config{DSN: m.Dsn(), SkipInitializeWithVersion: true})
+	case "sqlite":
+		s := config.Sqlite{GeneralDB: conf}
+		return sqlite.Open(s.Dsn())
+	default:
+		m := config.Mysql{GeneralDB: conf}
+		return mysql.New(mysql.Config{DSN: m.Dsn(), SkipInitializeWithVersion: true})
+	}
+}
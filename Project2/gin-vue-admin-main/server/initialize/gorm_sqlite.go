@@ -21,6 +21,7 @@ func GormSqlite() *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(s.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(s.MaxOpenConns)
+		useReplicas(db, "sqlite", s.GeneralDB)
 		return db
 	}
 }
@@ -37,6 +38,7 @@ func GormSqliteByConfig(s config.Sqlite) *gorm.DB {
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(s.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(s.MaxOpenConns)
+		useReplicas(db, "sqlite", s.GeneralDB)
 		return db
 	}
 }
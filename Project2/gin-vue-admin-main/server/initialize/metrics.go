@@ -0,0 +1,39 @@
+package initialize
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// useMetrics 开启数据库语句指标 name 为指标中的数据库名
+func useMetrics(db *gorm.DB, name string) {
+	cfg := global.GVA_CONFIG.Metrics
+	if !cfg.Enabled || !cfg.Gorm {
+		return
+	}
+	if err := metrics.UseGorm(db, name); err != nil {
+		global.GVA_LOG.Error("开启数据库指标失败!", zap.String("db", name), zap.Error(err))
+	}
+}
+
+// MetricsRouter 注册 Prometheus 采集接口 未配置 token 时不开放
+func MetricsRouter(Router *gin.Engine) {
+	cfg := global.GVA_CONFIG.Metrics
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.Token == "" {
+		global.GVA_LOG.Warn("metrics.token 为空 不开放采集接口")
+		return
+	}
+	path := cfg.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	Router.GET(path, middleware.MetricsAuth(cfg.Token), gin.WrapH(metrics.Handler()))
+	global.GVA_LOG.Info("register metrics handler", zap.String("path", path))
+}
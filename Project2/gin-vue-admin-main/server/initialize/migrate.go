@@ -0,0 +1,160 @@
+package initialize
+
+import (
+	"context"
+	"embed"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	adapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/migrate"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+// tables 系统模块的模型 新建数据库时按模型建表
+// 修改模型的同时需要在 migrations 目录下新增迁移 已有的数据库通过迁移得到相同的结构
+var tables = []interface{}{
+	system.SysApi{},
+	system.SysIgnoreApi{},
+	system.SysUser{},
+	system.SysBaseMenu{},
+	system.JwtBlacklist{},
+	system.SysAuthority{},
+	system.SysDictionary{},
+	system.SysOperationRecord{},
+	system.SysAutoCodeHistory{},
+	system.SysAutoCodeMigration{},
+	system.SysAutoCodeTemplatePack{},
+	system.SysPlugin{},
+	system.SysPluginState{},
+	system.SysDictionaryDetail{},
+	system.SysBaseMenuParameter{},
+	system.SysBaseMenuBtn{},
+	system.SysAuthorityBtn{},
+	system.SysAutoCodePackage{},
+	system.SysExportTemplate{},
+	system.Condition{},
+	system.JoinTemplate{},
+	system.SysExportSchedule{},
+	system.SysExportScheduleRun{},
+	system.SysJob{},
+	system.SysJobRun{},
+	system.SysJobLock{},
+	system.SysRetentionPolicy{},
+	system.SysRetentionRun{},
+	system.SysDBConnection{},
+
+	adapter.CasbinRule{},
+
+	example.ExaFile{},
+	example.ExaCustomer{},
+	example.ExaFileChunk{},
+	example.ExaFileUploadAndDownload{},
+	example.ExaFileFolder{},
+	example.ExaFileQuota{},
+	example.ExaTusUpload{},
+	example.ExaTusChunk{},
+	example.ExaOssMigration{},
+	example.ExaOssMigrationItem{},
+}
+
+func init() {
+	dir, _ := fs.Sub(migrations, "migrations")
+	migrate.Register(migrate.OrderSystem, migrate.Module{
+		Name: sys,
+		FS:   dir,
+		Dir:  "initialize/migrations",
+		Schema: func(db *gorm.DB) error {
+			return db.AutoMigrate(tables...)
+		},
+	})
+}
+
+const migrateUsage = `用法: go run main.go [-c config.yaml] migrate <up|down|status|create> [参数]
+
+  up                                           执行所有未执行的迁移
+  down   [-module system] [-n 1]               按执行的逆序回退迁移
+  status                                       查看各模块的迁移状态
+  create -module system -name add_xxx [-dialects mysql,pgsql]
+                                               创建迁移脚本 不指定方言时创建通用脚本
+`
+
+// Migrate 执行 migrate 命令 args 为 migrate 之后的参数
+func Migrate(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return errors.New("缺少迁移命令")
+	}
+	command := args[0]
+	set := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	module := set.String("module", "", "模块或插件名")
+	steps := set.Int("n", 1, "回退的迁移数量")
+	name := set.String("name", "", "迁移名称")
+	dialects := set.String("dialects", "", "逗号分隔的方言 "+strings.Join(migrate.Dialects, "|"))
+	set.Usage = func() {
+		fmt.Fprint(os.Stderr, migrateUsage)
+	}
+	if err := set.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if command == "create" {
+		var list []string
+		if *dialects != "" {
+			list = strings.Split(*dialects, ",")
+		}
+		files, err := migrate.Create(".", *module, *name, list, time.Now())
+		for _, file := range files {
+			fmt.Println("created", file)
+		}
+		return err
+	}
+
+	if global.GVA_DB == nil {
+		return errors.New("数据库未配置 请先初始化数据库")
+	}
+	ctx := context.Background()
+	m := migrate.New(global.GVA_DB, global.GVA_CONFIG.System.DbType)
+	var (
+		states []migrate.State
+		err    error
+	)
+	switch command {
+	case "up":
+		states, err = m.Up(ctx)
+	case "down":
+		states, err = m.Down(ctx, *module, *steps)
+	case "status":
+		states, err = m.Status(ctx)
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return fmt.Errorf("未知的迁移命令: %s", command)
+	}
+	if len(states) == 0 && err == nil {
+		fmt.Println("没有需要执行的迁移")
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "MODULE\tVERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, state := range states {
+		appliedAt := "-"
+		if state.AppliedAt != nil {
+			appliedAt = state.AppliedAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", state.Module, state.Version, state.Name, state.Status, appliedAt)
+	}
+	_ = writer.Flush()
+	return err
+}
@@ -0,0 +1 @@
+DROP TABLE sys_db_connections;
@@ -0,0 +1,23 @@
+CREATE TABLE "sys_db_connections" (
+  "id" bigint IDENTITY(1,1),
+  "created_at" datetimeoffset,
+  "updated_at" datetimeoffset,
+  "deleted_at" datetimeoffset,
+  "alias_name" nvarchar(64),
+  "type" nvarchar(16),
+  "path" nvarchar(255),
+  "port" nvarchar(16),
+  "db_name" nvarchar(128),
+  "username" nvarchar(128),
+  "password" nvarchar(MAX),
+  "config" nvarchar(255),
+  "prefix" nvarchar(64),
+  "singular" bit,
+  "max_idle_conns" bigint,
+  "max_open_conns" bigint,
+  "disable" bit,
+  "remark" nvarchar(255),
+  PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_sys_db_connections_deleted_at" ON "sys_db_connections" ("deleted_at");
+CREATE UNIQUE INDEX "idx_sys_db_connections_alias_name" ON "sys_db_connections" ("alias_name");
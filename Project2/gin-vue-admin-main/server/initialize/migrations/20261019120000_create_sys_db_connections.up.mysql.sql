@@ -0,0 +1,23 @@
+CREATE TABLE `sys_db_connections` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `alias_name` varchar(64) COMMENT '别名',
+  `type` varchar(16) COMMENT '数据库类型 mysql|pgsql|mssql|oracle|sqlite',
+  `path` varchar(255) COMMENT '数据库地址',
+  `port` varchar(16) COMMENT '数据库端口',
+  `db_name` varchar(128) COMMENT '数据库名',
+  `username` varchar(128) COMMENT '数据库账号',
+  `password` text COMMENT '加密后的数据库密码',
+  `config` varchar(255) COMMENT '高级配置',
+  `prefix` varchar(64) COMMENT '表前缀',
+  `singular` boolean COMMENT '是否禁用复数表名',
+  `max_idle_conns` bigint COMMENT '空闲中的最大连接数',
+  `max_open_conns` bigint COMMENT '打开到数据库的最大连接数',
+  `disable` boolean COMMENT '是否禁用',
+  `remark` varchar(255) COMMENT '备注',
+  PRIMARY KEY (`id`),
+  INDEX `idx_sys_db_connections_deleted_at` (`deleted_at`),
+  UNIQUE INDEX `idx_sys_db_connections_alias_name` (`alias_name`)
+);
@@ -0,0 +1,23 @@
+CREATE TABLE "sys_db_connections" (
+  "id" NUMBER(20) GENERATED BY DEFAULT AS IDENTITY,
+  "created_at" TIMESTAMP WITH TIME ZONE,
+  "updated_at" TIMESTAMP WITH TIME ZONE,
+  "deleted_at" TIMESTAMP WITH TIME ZONE,
+  "alias_name" VARCHAR2(64),
+  "type" VARCHAR2(16),
+  "path" VARCHAR2(255),
+  "port" VARCHAR2(16),
+  "db_name" VARCHAR2(128),
+  "username" VARCHAR2(128),
+  "password" CLOB,
+  "config" VARCHAR2(255),
+  "prefix" VARCHAR2(64),
+  "singular" NUMBER(1),
+  "max_idle_conns" NUMBER(20),
+  "max_open_conns" NUMBER(20),
+  "disable" NUMBER(1),
+  "remark" VARCHAR2(255),
+  PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_sys_db_connections_deleted_at" ON "sys_db_connections" ("deleted_at");
+CREATE UNIQUE INDEX "idx_sys_db_connections_alias_name" ON "sys_db_connections" ("alias_name");
@@ -0,0 +1,23 @@
+CREATE TABLE "sys_db_connections" (
+  "id" bigserial,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "alias_name" varchar(64),
+  "type" varchar(16),
+  "path" varchar(255),
+  "port" varchar(16),
+  "db_name" varchar(128),
+  "username" varchar(128),
+  "password" text,
+  "config" varchar(255),
+  "prefix" varchar(64),
+  "singular" boolean,
+  "max_idle_conns" bigint,
+  "max_open_conns" bigint,
+  "disable" boolean,
+  "remark" varchar(255),
+  PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_sys_db_connections_deleted_at" ON "sys_db_connections" ("deleted_at");
+CREATE UNIQUE INDEX "idx_sys_db_connections_alias_name" ON "sys_db_connections" ("alias_name");
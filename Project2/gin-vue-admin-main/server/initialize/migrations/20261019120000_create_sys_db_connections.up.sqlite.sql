@@ -0,0 +1,23 @@
+CREATE TABLE `sys_db_connections` (
+  `id` integer,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  `alias_name` text,
+  `type` text,
+  `path` text,
+  `port` text,
+  `db_name` text,
+  `username` text,
+  `password` text,
+  `config` text,
+  `prefix` text,
+  `singular` numeric,
+  `max_idle_conns` integer,
+  `max_open_conns` integer,
+  `disable` numeric,
+  `remark` text,
+  PRIMARY KEY (`id`)
+);
+CREATE INDEX `idx_sys_db_connections_deleted_at` ON `sys_db_connections`(`deleted_at`);
+CREATE UNIQUE INDEX `idx_sys_db_connections_alias_name` ON `sys_db_connections`(`alias_name`);
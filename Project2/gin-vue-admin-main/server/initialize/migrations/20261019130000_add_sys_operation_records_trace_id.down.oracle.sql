@@ -0,0 +1,2 @@
+DROP INDEX "idx_sys_operation_records_trace_id";
+ALTER TABLE "sys_operation_records" DROP COLUMN "trace_id";
@@ -0,0 +1,2 @@
+ALTER TABLE "sys_operation_records" ADD "trace_id" nvarchar(32);
+CREATE INDEX "idx_sys_operation_records_trace_id" ON "sys_operation_records" ("trace_id");
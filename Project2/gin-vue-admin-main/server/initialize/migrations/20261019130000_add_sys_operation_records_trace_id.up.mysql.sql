@@ -0,0 +1,2 @@
+ALTER TABLE `sys_operation_records` ADD COLUMN `trace_id` varchar(32) COMMENT '链路ID';
+CREATE INDEX `idx_sys_operation_records_trace_id` ON `sys_operation_records` (`trace_id`);
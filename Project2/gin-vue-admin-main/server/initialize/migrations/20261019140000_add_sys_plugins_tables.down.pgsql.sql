@@ -0,0 +1 @@
+ALTER TABLE "sys_plugins" DROP COLUMN "tables";
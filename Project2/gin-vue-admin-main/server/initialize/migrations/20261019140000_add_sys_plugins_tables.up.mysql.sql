@@ -0,0 +1 @@
+ALTER TABLE `sys_plugins` ADD COLUMN `tables` text COMMENT '安装时新建的表';
@@ -0,0 +1 @@
+ALTER TABLE `sys_plugins` ADD `tables` text;
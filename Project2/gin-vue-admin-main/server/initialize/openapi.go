@@ -0,0 +1,22 @@
+package initialize
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	systemRes "github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/openapi"
+)
+
+// OpenApi 登记手写接口的请求与响应结构 代码生成的接口由生成记录推导 无需登记
+func OpenApi() {
+	openapi.Bind("POST", "/base/login", openapi.Binding{Body: systemReq.Login{}, Response: systemRes.LoginResponse{}})
+	openapi.Bind("POST", "/user/admin_register", openapi.Binding{Body: systemReq.Register{}, Response: systemRes.SysUserResponse{}})
+	openapi.Bind("POST", "/user/changePassword", openapi.Binding{Body: systemReq.ChangePasswordReq{}})
+	openapi.Bind("POST", "/api/createApi", openapi.Binding{Body: system.SysApi{}})
+	openapi.Bind("POST", "/api/updateApi", openapi.Binding{Body: system.SysApi{}})
+	openapi.Bind("POST", "/api/deleteApi", openapi.Binding{Body: system.SysApi{}})
+	openapi.Bind("POST", "/api/getApiById", openapi.Binding{Body: request.GetById{}, Response: systemRes.SysAPIResponse{}})
+	openapi.Bind("POST", "/api/getAllApis", openapi.Binding{Response: systemRes.SysAPIListResponse{}})
+	openapi.Bind("DELETE", "/api/deleteApisByIds", openapi.Binding{Body: request.IdsReq{}})
+}
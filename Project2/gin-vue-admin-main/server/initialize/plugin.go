@@ -1,8 +1,12 @@
 package initialize
 
 import (
+	"context"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 func InstallPlugin(PrivateGroup *gin.RouterGroup, PublicRouter *gin.RouterGroup, engine *gin.Engine) {
@@ -12,4 +16,9 @@ func InstallPlugin(PrivateGroup *gin.RouterGroup, PublicRouter *gin.RouterGroup,
 	}
 	bizPluginV1(PrivateGroup, PublicRouter)
 	bizPluginV2(engine)
+	system.PluginHostServiceApp.Start(context.Background(), PrivateGroup, PublicRouter)
+	system.PluginLifecycleServiceApp.Bind(engine.Routes())
+	if err := system.PluginLifecycleServiceApp.Load(context.Background()); err != nil {
+		global.GVA_LOG.Error("加载插件运行状态失败!", zap.Error(err))
+	}
 }
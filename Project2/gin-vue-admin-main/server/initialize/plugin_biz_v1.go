@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/email"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin"
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +14,7 @@ func PluginInit(group *gin.RouterGroup, Plugin ...plugin.Plugin) {
 		fmt.Println(Plugin[i].RouterPath(), "注册开始!")
 		PluginGroup := group.Group(Plugin[i].RouterPath())
 		Plugin[i].Register(PluginGroup)
+		system.PluginLifecycleServiceApp.Register(Plugin[i], PluginGroup.BasePath())
 		fmt.Println(Plugin[i].RouterPath(), "注册成功!")
 	}
 }
@@ -2,13 +2,25 @@ package initialize
 
 import (
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/v2"
 	"github.com/gin-gonic/gin"
 )
 
 func PluginInitV2(group *gin.Engine, plugins ...plugin.Plugin) {
 	for i := 0; i < len(plugins); i++ {
+		before := make(map[string]struct{}, len(group.Routes()))
+		for _, route := range group.Routes() {
+			before[route.Method+" "+route.Path] = struct{}{}
+		}
 		plugins[i].Register(group)
+		var routes gin.RoutesInfo
+		for _, route := range group.Routes() {
+			if _, ok := before[route.Method+" "+route.Path]; !ok {
+				routes = append(routes, route)
+			}
+		}
+		system.PluginLifecycleServiceApp.Register(plugins[i], "", routes...)
 	}
 }
 func bizPluginV2(engine *gin.Engine) {
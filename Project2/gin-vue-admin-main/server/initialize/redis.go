@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -33,5 +35,13 @@ func Redis() {
 	} else {
 		global.GVA_LOG.Info("redis connect ping response:", zap.String("pong", pong))
 		global.GVA_REDIS = client
+		if global.GVA_CONFIG.Metrics.Enabled && global.GVA_CONFIG.Metrics.Redis {
+			if err = metrics.UseRedis(client); err != nil {
+				global.GVA_LOG.Error("开启redis指标失败!", zap.Error(err))
+			}
+		}
+		if global.GVA_CONFIG.Tracing.Enabled && global.GVA_CONFIG.Tracing.Redis {
+			tracing.UseRedis(client)
+		}
 	}
 }
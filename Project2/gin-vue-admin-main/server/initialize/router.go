@@ -36,9 +36,17 @@ func (fs justFilesFilesystem) Open(name string) (http.File, error) {
 func Routers() *gin.Engine {
 	Router := gin.New()
 	Router.Use(gin.Recovery())
+	if global.GVA_CONFIG.Tracing.Enabled {
+		Router.Use(middleware.Tracing()) // 链路追踪 需在其他中间件之前
+	}
+	if global.GVA_CONFIG.Metrics.Enabled && global.GVA_CONFIG.Metrics.HTTP {
+		Router.Use(middleware.Metrics()) // 接口请求数与耗时
+	}
 	if gin.Mode() == gin.DebugMode {
 		Router.Use(gin.Logger())
 	}
+	Router.Use(middleware.PluginGate())     // 已停用插件的路由返回404 需在创建路由组前挂载
+	Router.Use(middleware.ReplicaSession()) // 读写分离 使用请求 ctx 的查询在请求内写入后使用主库
 
 	systemRouter := router.RouterGroupApp.System
 	exampleRouter := router.RouterGroupApp.Example
@@ -58,12 +66,18 @@ func Routers() *gin.Engine {
 	docs.SwaggerInfo.BasePath = global.GVA_CONFIG.System.RouterPrefix
 	Router.GET(global.GVA_CONFIG.System.RouterPrefix+"/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	global.GVA_LOG.Info("register swagger handler")
+	MetricsRouter(Router) // Prometheus 采集接口 不带路由前缀
 	// 方便统一添加路由组前缀 多服务器上线使用
 
 	PublicGroup := Router.Group(global.GVA_CONFIG.System.RouterPrefix)
 	PrivateGroup := Router.Group(global.GVA_CONFIG.System.RouterPrefix)
 
 	PrivateGroup.Use(middleware.JWTAuth()).Use(middleware.CasbinHandler())
+	OpenApi() // 登记接口的请求与响应结构
+	if global.GVA_CONFIG.System.OpenApiValidate {
+		PublicGroup.Use(middleware.OpenApiValidator())
+		PrivateGroup.Use(middleware.OpenApiValidator())
+	} // 按OpenAPI文档校验请求
 
 	{
 		// 健康监测
@@ -77,22 +91,29 @@ func Routers() *gin.Engine {
 	}
 
 	{
-		systemRouter.InitApiRouter(PrivateGroup, PublicGroup)       // 注册功能api路由
-		systemRouter.InitJwtRouter(PrivateGroup)                    // jwt相关路由
-		systemRouter.InitUserRouter(PrivateGroup)                   // 注册用户路由
-		systemRouter.InitMenuRouter(PrivateGroup)                   // 注册menu路由
-		systemRouter.InitSystemRouter(PrivateGroup)                 // system相关路由
-		systemRouter.InitCasbinRouter(PrivateGroup)                 // 权限相关路由
-		systemRouter.InitAutoCodeRouter(PrivateGroup, PublicGroup)  // 创建自动化代码
-		systemRouter.InitAuthorityRouter(PrivateGroup)              // 注册角色路由
-		systemRouter.InitSysDictionaryRouter(PrivateGroup)          // 字典管理
-		systemRouter.InitAutoCodeHistoryRouter(PrivateGroup)        // 自动化代码历史
-		systemRouter.InitSysOperationRecordRouter(PrivateGroup)     // 操作记录
-		systemRouter.InitSysDictionaryDetailRouter(PrivateGroup)    // 字典详情管理
-		systemRouter.InitAuthorityBtnRouterRouter(PrivateGroup)     // 按钮权限管理
-		systemRouter.InitSysExportTemplateRouter(PrivateGroup)      // 导出模板
-		exampleRouter.InitCustomerRouter(PrivateGroup)              // 客户路由
-		exampleRouter.InitFileUploadAndDownloadRouter(PrivateGroup) // 文件上传下载功能路由
+		systemRouter.InitApiRouter(PrivateGroup, PublicGroup)                    // 注册功能api路由
+		systemRouter.InitJwtRouter(PrivateGroup)                                 // jwt相关路由
+		systemRouter.InitUserRouter(PrivateGroup)                                // 注册用户路由
+		systemRouter.InitMenuRouter(PrivateGroup)                                // 注册menu路由
+		systemRouter.InitSystemRouter(PrivateGroup)                              // system相关路由
+		systemRouter.InitCasbinRouter(PrivateGroup)                              // 权限相关路由
+		systemRouter.InitAutoCodeRouter(PrivateGroup, PublicGroup)               // 创建自动化代码
+		systemRouter.InitAuthorityRouter(PrivateGroup)                           // 注册角色路由
+		systemRouter.InitSysDictionaryRouter(PrivateGroup)                       // 字典管理
+		systemRouter.InitAutoCodeHistoryRouter(PrivateGroup)                     // 自动化代码历史
+		systemRouter.InitSysOperationRecordRouter(PrivateGroup)                  // 操作记录
+		systemRouter.InitSysDictionaryDetailRouter(PrivateGroup)                 // 字典详情管理
+		systemRouter.InitAuthorityBtnRouterRouter(PrivateGroup)                  // 按钮权限管理
+		systemRouter.InitSysExportTemplateRouter(PrivateGroup)                   // 导出模板
+		systemRouter.InitSysExportScheduleRouter(PrivateGroup)                   // 定时报表
+		systemRouter.InitSysJobRouter(PrivateGroup)                              // 定时任务
+		systemRouter.InitSysRetentionRouter(PrivateGroup)                        // 数据保留策略
+		systemRouter.InitOpenApiRouter(PrivateGroup)                             // OpenAPI文档
+		systemRouter.InitPluginLifecycleRouter(PrivateGroup)                     // 插件运行状态
+		systemRouter.InitDBConnectionRouter(PrivateGroup)                        // 业务库管理
+		exampleRouter.InitCustomerRouter(PrivateGroup)                           // 客户路由
+		exampleRouter.InitFileUploadAndDownloadRouter(PrivateGroup, PublicGroup) // 文件上传下载功能路由
+		exampleRouter.InitOssMigrationRouter(PrivateGroup)                       // 存储迁移路由
 
 	}
 
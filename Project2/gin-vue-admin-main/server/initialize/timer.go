@@ -1,37 +1,69 @@
 package initialize
 
 import (
+	"context"
 	"fmt"
-	"github.com/flipped-aurora/gin-vue-admin/server/task"
+	"io"
+	"strings"
 
-	"github.com/robfig/cron/v3"
-
-	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	sysModel "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/service/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/timer"
 )
 
+// Timer 注册定时任务处理函数 任务的调度配置保存在数据库中 可在定时任务管理中维护
 func Timer() {
-	go func() {
-		var option []cron.Option
-		option = append(option, cron.WithSeconds())
-		// 清理DB定时任务
-		_, err := global.GVA_Timer.AddTaskByFunc("ClearDB", "@daily", func() {
-			err := task.ClearTable(global.GVA_DB) // 定时任务方法定在task文件包中
-			if err != nil {
-				fmt.Println("timer error:", err)
+	// 按数据保留策略清理过期数据 参数为逗号分隔的策略名称 为空时执行全部策略
+	timer.RegisterJobHandler("dataRetention", "按数据保留策略清理过期数据", func(ctx context.Context, args string, out io.Writer) error {
+		var names []string
+		for _, name := range strings.Split(args, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
 			}
-		}, "定时清理数据库【日志，黑名单】内容", option...)
-		if err != nil {
-			fmt.Println("add timer error:", err)
 		}
+		return system.SysRetentionServiceApp.RunSysRetentionPolicies(names, out)
+	})
+
+	// 清理过期的断点续传上传与分片
+	timer.RegisterJobHandler("tusCleanup", "定时清理过期的断点续传上传", func(ctx context.Context, args string, out io.Writer) error {
+		return example.FileUploadAndDownloadServiceApp.CleanExpiredUploads()
+	})
+
+	// 其他定时任务处理函数注册在这里 参考上方使用方法 注册后在定时任务管理中配置执行计划
+
+	//timer.RegisterJobHandler("处理函数标识", "描述", func(ctx context.Context, args string, out io.Writer) error {
+	//	具体执行内容... args 为任务参数 写入 out 的内容会保存到执行记录
+	//  ......
+	//	return nil
+	//})
+}
 
-		// 其他定时任务定在这里 参考上方使用方法
+// defaultJobs 内置任务 首次启动时写入数据库
+var defaultJobs = []sysModel.SysJob{
+	{Name: "数据保留", Spec: "@daily", Handler: "dataRetention", Misfire: sysModel.JobMisfireOnce, Remark: "按数据保留策略清理过期数据"},
+	{Name: "清理过期上传", Spec: "@every 1h", Handler: "tusCleanup", Misfire: sysModel.JobMisfireSkip, Timeout: 1800, Remark: "清理过期的断点续传上传与分片"},
+}
+
+// JobTimer 初始化内置任务并将数据库中的定时任务注册到 GVA_Timer 需在表初始化之后调用
+func JobTimer() {
+	// 数据保留策略由 dataRetention 任务执行
+	if err := system.SysRetentionServiceApp.EnsureSysRetentionPolicies(); err != nil {
+		fmt.Println("init retention policy error:", err)
+	}
+	for _, job := range defaultJobs {
+		if err := system.SysJobServiceApp.EnsureSysJob(job); err != nil {
+			fmt.Println("init job error:", err)
+		}
+	}
+	if err := system.SysJobServiceApp.StartSysJobs(); err != nil {
+		fmt.Println("add job timer error:", err)
+	}
+}
 
-		//_, err := global.GVA_Timer.AddTaskByFunc("定时任务标识", "corn表达式", func() {
-		//	具体执行内容...
-		//  ......
-		//}, option...)
-		//if err != nil {
-		//	fmt.Println("add timer error:", err)
-		//}
-	}()
+// ExportScheduleTimer 将数据库中启用的定时报表注册到 GVA_Timer 需在表初始化之后调用
+func ExportScheduleTimer() {
+	if err := system.SysExportScheduleServiceApp.RegisterExportSchedules(); err != nil {
+		fmt.Println("add export schedule timer error:", err)
+	}
 }
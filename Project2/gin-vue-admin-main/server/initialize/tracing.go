@@ -0,0 +1,54 @@
+package initialize
+
+import (
+	"context"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Tracing 按配置开启链路追踪 返回的函数在程序退出前调用 导出尚未发送的 span
+func Tracing() func() {
+	cfg := global.GVA_CONFIG.Tracing
+	if !cfg.Enabled {
+		return func() {}
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "gin-vue-admin"
+	}
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: serviceName,
+		Endpoint:    cfg.Endpoint,
+		URLPath:     cfg.URLPath,
+		Insecure:    cfg.Insecure,
+		Headers:     cfg.Headers,
+		SampleRatio: cfg.SampleRatio,
+	})
+	if err != nil {
+		global.GVA_LOG.Error("开启链路追踪失败!", zap.Error(err))
+		return func() {}
+	}
+	global.GVA_LOG.Info("tracing enabled", zap.String("endpoint", cfg.Endpoint))
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			global.GVA_LOG.Error("导出链路数据失败!", zap.Error(err))
+		}
+	}
+}
+
+// useTracing 开启数据库语句追踪 name 为 span 中的数据库名
+func useTracing(db *gorm.DB, name string) {
+	cfg := global.GVA_CONFIG.Tracing
+	if !cfg.Enabled || !cfg.Gorm {
+		return
+	}
+	if err := tracing.UseGorm(db, name); err != nil {
+		global.GVA_LOG.Error("开启数据库追踪失败!", zap.String("db", name), zap.Error(err))
+	}
+}
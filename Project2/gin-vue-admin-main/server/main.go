@@ -1,6 +1,10 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	_ "go.uber.org/automaxprocs"
 	"go.uber.org/zap"
 
@@ -26,11 +30,23 @@ func main() {
 	initialize.OtherInit()
 	global.GVA_LOG = core.Zap() // 初始化zap日志库
 	zap.ReplaceGlobals(global.GVA_LOG)
+	defer initialize.Tracing()()      // 链路追踪 退出前导出尚未发送的数据
 	global.GVA_DB = initialize.Gorm() // gorm连接数据库
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		// 数据库迁移命令 go run main.go migrate <up|down|status|create>
+		if err := initialize.Migrate(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "migrate:", err)
+			os.Exit(1)
+		}
+		return
+	}
 	initialize.Timer()
 	initialize.DBList()
 	if global.GVA_DB != nil {
 		initialize.RegisterTables() // 初始化表
+		initialize.DBConnections()  // 注册在系统中维护的业务库
+		initialize.ExportScheduleTimer()
+		initialize.JobTimer()
 		// 程序结束前关闭数据库链接
 		db, _ := global.GVA_DB.DB()
 		defer db.Close()
@@ -8,7 +8,10 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/service"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
 )
 
 var casbinService = service.ServiceGroupApp.SystemServiceGroup.CasbinService
@@ -25,7 +28,14 @@ func CasbinHandler() gin.HandlerFunc {
 		// 获取用户的角色
 		sub := strconv.Itoa(int(waitUse.AuthorityId))
 		e := casbinService.Casbin() // 判断策略中是否存在
-		success, _ := e.Enforce(sub, obj, act)
+		_, span := tracing.Tracer().Start(c.Request.Context(), "casbin.enforce")
+		success, err := e.Enforce(sub, obj, act)
+		span.SetAttributes(attribute.String("casbin.sub", sub), attribute.Bool("casbin.allowed", success))
+		if err != nil {
+			span.RecordError(err)
+			tracing.Logger(c.Request.Context()).Error("casbin鉴权失败!", zap.String("path", obj), zap.Error(err))
+		}
+		span.End()
 		if !success {
 			response.FailWithDetailed(gin.H{}, "权限不足", c)
 			c.Abort()
@@ -9,9 +9,9 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/email/utils"
 	utils2 "github.com/flipped-aurora/gin-vue-admin/server/utils"
 
-	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/service"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -53,7 +53,7 @@ func ErrorToEmail() gin.HandlerFunc {
 		if status != 200 {
 			subject := username + "" + record.Ip + "调用了" + record.Path + "报错了"
 			if err := utils.ErrorToEmail(subject, str); err != nil {
-				global.GVA_LOG.Error("ErrorToEmail Failed, err:", zap.Error(err))
+				tracing.Logger(c.Request.Context()).Error("ErrorToEmail Failed, err:", zap.Error(err))
 			}
 		}
 	}
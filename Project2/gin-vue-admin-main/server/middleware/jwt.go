@@ -13,6 +13,7 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/service"
 
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -69,7 +70,7 @@ func JWTAuth() gin.HandlerFunc {
 			if global.GVA_CONFIG.System.UseMultipoint {
 				RedisJwtToken, err := jwtService.GetRedisJWT(newClaims.Username)
 				if err != nil {
-					global.GVA_LOG.Error("get redis jwt failed", zap.Error(err))
+					tracing.Logger(c.Request.Context()).Error("get redis jwt failed", zap.Error(err))
 				} else { // 当之前的取成功时才进行拉黑操作
 					_ = jwtService.JsonInBlacklist(system.JwtBlacklist{Jwt: RedisJwtToken})
 				}
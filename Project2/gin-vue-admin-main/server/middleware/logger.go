@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,6 +24,7 @@ type LogLayout struct {
 	Error     string                 // 错误
 	Cost      time.Duration          // 花费时间
 	Source    string                 // 来源
+	TraceID   string                 // 链路ID
 }
 
 type Logger struct {
@@ -60,6 +62,7 @@ func (l Logger) SetLoggerMiddleware() gin.HandlerFunc {
 			Error:     strings.TrimRight(c.Errors.ByType(gin.ErrorTypePrivate).String(), "\n"),
 			Cost:      cost,
 			Source:    l.Source,
+			TraceID:   tracing.TraceID(c.Request.Context()),
 		}
 		if l.Filter != nil && !l.Filter(c) {
 			layout.Body = string(body)
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 记录接口请求数与耗时 以路由模板区分接口
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := metrics.StartRequest(c.Request.Method)
+		c.Next()
+		done(c.FullPath(), c.Writer.Status())
+	}
+}
+
+// MetricsAuth 校验采集端携带的 Bearer Token
+func MetricsAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/service"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var openApiService = service.ServiceGroupApp.SystemServiceGroup.OpenApiService
+
+// OpenApiValidator 按 OpenAPI 文档校验查询参数与 JSON 请求体 文档中没有的接口直接放行
+func OpenApiValidator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if global.GVA_DB == nil {
+			c.Next()
+			return
+		} // 未初始化数据库时无法构建文档
+		doc, err := openApiService.Spec(c.Request.Context())
+		if err != nil {
+			tracing.Logger(c.Request.Context()).Error("获取OpenAPI文档失败, 跳过校验!", zap.Error(err))
+			c.Next()
+			return
+		}
+		operation := doc.Find(c.Request.Method, c.FullPath())
+		if operation == nil {
+			c.Next()
+			return
+		}
+		if err = doc.ValidateQuery(operation, c.Request.URL.Query()); err != nil {
+			response.FailWithMessage("参数校验失败: "+err.Error(), c)
+			c.Abort()
+			return
+		}
+		if operation.RequestBody != nil && c.ContentType() == "application/json" {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				response.FailWithMessage("读取请求体失败", c)
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+			var value interface{}
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			if err = decoder.Decode(&value); err != nil {
+				response.FailWithMessage("请求体不是合法的JSON", c)
+				c.Abort()
+				return
+			}
+			if media := operation.RequestBody.Content["application/json"]; media != nil {
+				if err = doc.Validate(media.Schema, value); err != nil {
+					response.FailWithMessage("参数校验失败: "+err.Error(), c)
+					c.Abort()
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
@@ -12,8 +12,9 @@ import (
 	"time"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
 
-	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/service"
 	"github.com/gin-gonic/gin"
@@ -39,7 +40,7 @@ func OperationRecord() gin.HandlerFunc {
 			var err error
 			body, err = io.ReadAll(c.Request.Body)
 			if err != nil {
-				global.GVA_LOG.Error("read body from request error:", zap.Error(err))
+				tracing.Logger(c.Request.Context()).Error("read body from request error:", zap.Error(err))
 			} else {
 				c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
 			}
@@ -67,12 +68,13 @@ func OperationRecord() gin.HandlerFunc {
 			userId = id
 		}
 		record := system.SysOperationRecord{
-			Ip:     c.ClientIP(),
-			Method: c.Request.Method,
-			Path:   c.Request.URL.Path,
-			Agent:  c.Request.UserAgent(),
-			Body:   "",
-			UserID: userId,
+			Ip:      c.ClientIP(),
+			Method:  c.Request.Method,
+			Path:    c.Request.URL.Path,
+			Agent:   c.Request.UserAgent(),
+			Body:    "",
+			UserID:  userId,
+			TraceID: tracing.TraceID(c.Request.Context()),
 		}
 
 		// 上传文件时候 中间件日志进行裁断操作
@@ -116,8 +118,10 @@ func OperationRecord() gin.HandlerFunc {
 			}
 		}
 
+		metrics.OperationRecordPending.Inc()
+		defer metrics.OperationRecordPending.Dec()
 		if err := operationRecordService.CreateSysOperationRecord(record); err != nil {
-			global.GVA_LOG.Error("create operation record error:", zap.Error(err))
+			tracing.Logger(c.Request.Context()).Error("create operation record error:", zap.Error(err))
 		}
 	}
 }
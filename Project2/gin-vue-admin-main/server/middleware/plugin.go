@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/service"
+	"github.com/gin-gonic/gin"
+)
+
+var pluginLifecycleService = service.ServiceGroupApp.SystemServiceGroup.PluginLifecycleService
+
+// PluginGate 已停用插件的路由返回404 需在注册插件路由前挂载到引擎上
+func PluginGate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if pluginLifecycleService.Disabled(c.Request.Method, c.FullPath()) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.Next()
+	}
+}
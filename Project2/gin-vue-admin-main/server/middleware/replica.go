@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/replica"
+	"github.com/gin-gonic/gin"
+)
+
+// ReplicaSession 为每个请求开启读写分离会话 使用请求 ctx 的查询按请求区分写入 请求内执行过写入后 之后的查询都走主库
+// 未使用请求 ctx 的查询按本进程最近一次写入判断 见 replica 包说明
+func ReplicaSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(replica.WithSession(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -1,7 +0,0 @@
-package common
-
-type ClearDB struct {
-	TableName    string
-	CompareField string
-	Interval     string
-}
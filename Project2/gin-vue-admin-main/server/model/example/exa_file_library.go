@@ -0,0 +1,35 @@
+package example
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+const (
+	FileQuotaUser = "user"
+	FileQuotaRole = "role"
+)
+
+// ExaFileFolder 媒体库文件夹
+type ExaFileFolder struct {
+	global.GVA_MODEL
+	Name     string          `json:"name" gorm:"comment:文件夹名称"`            // 文件夹名称
+	ParentID uint            `json:"parentId" gorm:"index;comment:父文件夹ID"` // 父文件夹ID 0为根目录
+	UserID   uint            `json:"userId" gorm:"comment:创建人ID"`          // 创建人ID
+	Children []ExaFileFolder `json:"children" gorm:"-"`
+}
+
+func (ExaFileFolder) TableName() string {
+	return "exa_file_folders"
+}
+
+// ExaFileQuota 针对单个用户或角色的存储配额 优先于配置文件中的默认配额
+type ExaFileQuota struct {
+	global.GVA_MODEL
+	TargetType string `json:"targetType" gorm:"uniqueIndex:idx_file_quota_target;size:16;comment:配额对象类型 user|role"` // 配额对象类型
+	TargetID   uint   `json:"targetId" gorm:"uniqueIndex:idx_file_quota_target;comment:用户ID或角色ID"`                  // 用户ID或角色ID
+	Quota      int64  `json:"quota" gorm:"comment:配额(MB) 0为不限制"`                                                    // 配额(MB)
+}
+
+func (ExaFileQuota) TableName() string {
+	return "exa_file_quotas"
+}
@@ -6,10 +6,20 @@ import (
 
 type ExaFileUploadAndDownload struct {
 	global.GVA_MODEL
-	Name string `json:"name" gorm:"comment:文件名"` // 文件名
-	Url  string `json:"url" gorm:"comment:文件地址"` // 文件地址
-	Tag  string `json:"tag" gorm:"comment:文件标签"` // 文件标签
-	Key  string `json:"key" gorm:"comment:编号"`   // 编号
+	Name        string `json:"name" gorm:"comment:文件名"`                            // 文件名
+	Url         string `json:"url" gorm:"comment:文件地址"`                            // 文件地址
+	Tag         string `json:"tag" gorm:"comment:文件标签"`                            // 文件标签
+	Key         string `json:"key" gorm:"comment:编号"`                              // 编号
+	FolderID    uint   `json:"folderId" gorm:"index;comment:所属文件夹ID 0为根目录"`        // 所属文件夹ID
+	Hash        string `json:"hash" gorm:"index;size:64;comment:文件内容SHA-256"`      // 文件内容SHA-256
+	Size        int64  `json:"size" gorm:"comment:文件大小(字节)"`                       // 文件大小
+	MimeType    string `json:"mimeType" gorm:"index;size:128;comment:嗅探得到的MIME类型"` // MIME类型
+	Width       int    `json:"width" gorm:"comment:图片宽度"`                          // 图片宽度
+	Height      int    `json:"height" gorm:"comment:图片高度"`                         // 图片高度
+	ThumbUrl    string `json:"thumbUrl" gorm:"comment:缩略图地址"`                      // 缩略图地址
+	ThumbKey    string `json:"thumbKey" gorm:"comment:缩略图编号"`                      // 缩略图编号
+	UserID      uint   `json:"userId" gorm:"index;comment:上传用户ID"`                 // 上传用户ID
+	AuthorityID uint   `json:"authorityId" gorm:"index;comment:上传时的角色ID 用于角色配额统计"` // 上传时的角色ID
 }
 
 func (ExaFileUploadAndDownload) TableName() string {
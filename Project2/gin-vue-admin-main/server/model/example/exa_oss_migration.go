@@ -0,0 +1,57 @@
+package example
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+const (
+	OssMigrationPending = "pending"
+	OssMigrationRunning = "running"
+	OssMigrationStopped = "stopped"
+	OssMigrationSuccess = "success"
+	OssMigrationFailed  = "failed"
+	OssMigrationApplied = "applied"
+
+	OssMigrationKindFile  = "file"
+	OssMigrationKindThumb = "thumb"
+)
+
+// ExaOssMigration 存储迁移任务 将媒体库对象从源存储复制到目标存储
+type ExaOssMigration struct {
+	global.GVA_MODEL
+	Source     string     `json:"source" gorm:"comment:源存储类型"`         // 源存储类型 同 system.oss-type
+	Target     string     `json:"target" gorm:"comment:目标存储类型"`        // 目标存储类型
+	Status     string     `json:"status" gorm:"index;comment:状态"`      // pending|running|stopped|success|failed|applied
+	Total      int        `json:"total" gorm:"comment:对象总数"`           // 对象总数
+	Done       int        `json:"done" gorm:"comment:已完成数"`            // 已复制并校验通过的对象数
+	Failed     int        `json:"failed" gorm:"comment:失败数"`           // 失败的对象数
+	StartedAt  *time.Time `json:"startedAt" gorm:"comment:最近一次开始时间"`   // 最近一次开始时间
+	FinishedAt *time.Time `json:"finishedAt" gorm:"comment:最近一次结束时间"`  // 最近一次结束时间
+	AppliedAt  *time.Time `json:"appliedAt" gorm:"comment:改写文件地址的时间"`  // 改写文件地址的时间
+	Error      string     `json:"error" gorm:"type:text;comment:错误信息"` // 错误信息
+}
+
+func (ExaOssMigration) TableName() string {
+	return "exa_oss_migrations"
+}
+
+// ExaOssMigrationItem 迁移任务中的单个对象 同一个key被多条文件记录引用时只复制一次
+type ExaOssMigrationItem struct {
+	global.GVA_MODEL
+	MigrationID uint   `json:"migrationId" gorm:"index;comment:迁移任务ID"`
+	Kind        string `json:"kind" gorm:"size:16;comment:对象类型 file|thumb"`
+	Name        string `json:"name" gorm:"comment:文件名"`
+	SourceKey   string `json:"sourceKey" gorm:"comment:源存储key"`
+	SourceUrl   string `json:"sourceUrl" gorm:"comment:源存储地址"`
+	TargetKey   string `json:"targetKey" gorm:"comment:目标存储key"`
+	TargetUrl   string `json:"targetUrl" gorm:"comment:目标存储地址"`
+	Checksum    string `json:"checksum" gorm:"comment:sha256"`
+	Status      string `json:"status" gorm:"index;size:16;comment:状态 pending|success|failed"`
+	Error       string `json:"error" gorm:"type:text;comment:错误信息"`
+}
+
+func (ExaOssMigrationItem) TableName() string {
+	return "exa_oss_migration_items"
+}
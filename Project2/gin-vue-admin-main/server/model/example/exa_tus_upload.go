@@ -0,0 +1,40 @@
+package example
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// ExaTusUpload tus 1.0 断点续传上传记录
+type ExaTusUpload struct {
+	global.GVA_MODEL
+	UploadID    string            `json:"uploadId" gorm:"uniqueIndex;size:64;comment:上传ID"`                  // 上传ID 出现在上传地址中
+	Length      int64             `json:"length" gorm:"column:upload_length;comment:文件总大小"`                  // 文件总大小(字节)
+	Offset      int64             `json:"offset" gorm:"column:upload_offset;comment:已上传大小"`                  // 已上传大小(字节)
+	Metadata    map[string]string `json:"metadata" gorm:"serializer:json;type:text;comment:Upload-Metadata"` // 客户端提交的元数据
+	FileName    string            `json:"fileName" gorm:"comment:文件名"`
+	MimeType    string            `json:"mimeType" gorm:"comment:MIME类型"`
+	FolderID    uint              `json:"folderId" gorm:"comment:目标文件夹ID"`
+	UserID      uint              `json:"userId" gorm:"index;comment:上传者ID"`
+	AuthorityID uint              `json:"authorityId" gorm:"comment:上传者角色ID"`
+	ExpiresAt   time.Time         `json:"expiresAt" gorm:"index;comment:过期时间"`
+	FileID      uint              `json:"fileId" gorm:"comment:完成后媒体库文件ID"` // 上传完成并入库后的 exa_file_upload_and_downloads.id
+}
+
+func (ExaTusUpload) TableName() string {
+	return "exa_tus_uploads"
+}
+
+// ExaTusChunk tus 上传已接收的数据段 按 Offset 顺序拼接
+type ExaTusChunk struct {
+	global.GVA_MODEL
+	UploadID string `json:"uploadId" gorm:"index;size:64;comment:上传ID"`
+	Offset   int64  `json:"offset" gorm:"column:chunk_offset;comment:数据段起始位置"`
+	Size     int64  `json:"size" gorm:"comment:数据段大小"`
+	Key      string `json:"key" gorm:"comment:分片存储key"`
+}
+
+func (ExaTusChunk) TableName() string {
+	return "exa_tus_chunks"
+}
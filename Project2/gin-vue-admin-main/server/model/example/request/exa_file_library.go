@@ -0,0 +1,26 @@
+package request
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+// ExaFileSearch 媒体库文件搜索条件
+type ExaFileSearch struct {
+	request.PageInfo
+	FolderID       *uint      `json:"folderId" form:"folderId"`             // 文件夹ID 为空时搜索全部
+	Name           string     `json:"name" form:"name"`                     // 文件名 模糊匹配
+	Tag            string     `json:"tag" form:"tag"`                       // 文件标签
+	MimeType       string     `json:"mimeType" form:"mimeType"`             // MIME类型 以/结尾时按前缀匹配 例: image/
+	MinSize        int64      `json:"minSize" form:"minSize"`               // 最小文件大小(字节)
+	MaxSize        int64      `json:"maxSize" form:"maxSize"`               // 最大文件大小(字节)
+	StartCreatedAt *time.Time `json:"startCreatedAt" form:"startCreatedAt"` // 上传时间起
+	EndCreatedAt   *time.Time `json:"endCreatedAt" form:"endCreatedAt"`     // 上传时间止
+}
+
+// MoveFiles 移动文件到文件夹
+type MoveFiles struct {
+	Ids      []uint `json:"ids" form:"ids"`
+	FolderID uint   `json:"folderId" form:"folderId"`
+}
@@ -0,0 +1,41 @@
+package request
+
+// PresignUpload 申请直传
+type PresignUpload struct {
+	Name      string `json:"name" form:"name"`           // 文件名
+	Size      int64  `json:"size" form:"size"`           // 文件大小(字节) 用于大小与配额校验
+	MimeType  string `json:"mimeType" form:"mimeType"`   // 文件MIME类型
+	FolderID  uint   `json:"folderId" form:"folderId"`   // 目标文件夹ID
+	Multipart bool   `json:"multipart" form:"multipart"` // 是否使用分片直传 仅S3兼容存储支持
+}
+
+// PresignTicket 申请直传时签发的凭证 后续分片签名、完成与取消均需回传
+type PresignTicket struct {
+	Key      string `json:"key" form:"key"`
+	Url      string `json:"url" form:"url"`
+	UploadID string `json:"uploadId" form:"uploadId"` // 分片上传ID 单次PUT直传为空
+	Size     int64  `json:"size" form:"size"`
+	Expires  int64  `json:"expires" form:"expires"`
+	Token    string `json:"token" form:"token"`
+}
+
+// PresignParts 批量获取分片上传地址
+type PresignParts struct {
+	PresignTicket
+	PartNumbers []int `json:"partNumbers" form:"partNumbers"` // 分片序号 从1开始
+}
+
+// PresignPart 已上传的分片
+type PresignPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"` // 分片PUT响应头中的ETag
+}
+
+// PresignComplete 直传完成回调
+type PresignComplete struct {
+	PresignTicket
+	Name     string        `json:"name" form:"name"`
+	MimeType string        `json:"mimeType" form:"mimeType"`
+	FolderID uint          `json:"folderId" form:"folderId"`
+	Parts    []PresignPart `json:"parts" form:"parts"` // 分片直传时必填
+}
@@ -0,0 +1,18 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+// CreateOssMigration 创建存储迁移
+type CreateOssMigration struct {
+	Source string `json:"source" form:"source"` // 源存储类型 同 system.oss-type
+	Target string `json:"target" form:"target"` // 目标存储类型
+}
+
+// OssMigrationItemSearch 迁移对象查询
+type OssMigrationItemSearch struct {
+	MigrationID uint   `json:"migrationId" form:"migrationId"`
+	Status      string `json:"status" form:"status"`
+	request.PageInfo
+}
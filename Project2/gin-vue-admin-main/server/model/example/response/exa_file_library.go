@@ -0,0 +1,9 @@
+package response
+
+// ExaFileUsage 存储用量
+type ExaFileUsage struct {
+	UserUsage int64 `json:"userUsage"` // 当前用户已用(字节)
+	UserQuota int64 `json:"userQuota"` // 当前用户配额(字节) 0为不限制
+	RoleUsage int64 `json:"roleUsage"` // 当前角色已用(字节)
+	RoleQuota int64 `json:"roleQuota"` // 当前角色配额(字节) 0为不限制
+}
@@ -0,0 +1,18 @@
+package response
+
+// PresignUpload 直传申请结果
+type PresignUpload struct {
+	Key       string `json:"key"`
+	Url       string `json:"url"`       // 上传完成后的访问地址
+	UploadUrl string `json:"uploadUrl"` // 单次直传的签名地址 分片直传时为空
+	UploadID  string `json:"uploadId"`  // 分片上传ID 单次直传时为空
+	Size      int64  `json:"size"`
+	Expires   int64  `json:"expires"` // 凭证过期时间戳
+	Token     string `json:"token"`   // 凭证签名
+}
+
+// PresignPart 分片上传地址
+type PresignPart struct {
+	PartNumber int    `json:"partNumber"`
+	Url        string `json:"url"`
+}
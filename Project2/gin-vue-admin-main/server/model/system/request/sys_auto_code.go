@@ -6,6 +6,7 @@ import (
 	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/pkg/errors"
 	"go/token"
+	"gorm.io/gorm/schema"
 	"strings"
 )
 
@@ -24,6 +25,8 @@ type AutoCode struct {
 	AutoCreateResource  bool                   `json:"autoCreateResource" example:"false"`  // 是否自动创建资源标识
 	AutoCreateApiToSql  bool                   `json:"autoCreateApiToSql" example:"false"`  // 是否自动创建api
 	AutoCreateMenuToSql bool                   `json:"autoCreateMenuToSql" example:"false"` // 是否自动创建menu
+	Pack                string                 `json:"pack" example:"模板包"`                  // 模板包 为空时使用包的默认模板包
+	Variables           map[string]string      `json:"variables"`                           // 模板包额外变量
 	Fields              []*AutoCodeField       `json:"fields"`
 	DictTypes           []string               `json:"-"`
 	FrontFields         []*AutoCodeField       `json:"-"`
@@ -37,6 +40,10 @@ type AutoCode struct {
 	HasRichText         bool                   `json:"-"`
 	HasDataSource       bool                   `json:"-"`
 	HasSearchTimer      bool                   `json:"-"`
+	Relations           []*AutoCodeField       `json:"-"` // 关联关系字段
+	RelationImports     []string               `json:"-"` // 关联结构体所在的其他包
+	HasCascade          bool                   `json:"-"` // 删除时需要级联删除关联数据
+	HasNestedUpdate     bool                   `json:"-"` // 更新时需要同步一对多与多对多关联
 }
 
 type DataSource struct {
@@ -112,11 +119,15 @@ func (r *AutoCode) Pretreatment() error {
 	if strings.HasSuffix(r.HumpPackageName, "test") {
 		r.HumpPackageName = r.HumpPackageName + "_"
 	} // test
+	r.foreignKeys()
 	length := len(r.Fields)
 	dict := make(map[string]string, length)
 	r.FrontFields = make([]*AutoCodeField, 0, length)
 	r.DataSourceMap = make(map[string]*DataSource, length)
 	for i := 0; i < length; i++ {
+		if r.Fields[i].Relation != nil {
+			continue
+		} // 关联关系字段不是数据库字段
 		if r.Fields[i].DictType != "" {
 			dict[r.Fields[i].DictType] = ""
 		}
@@ -178,6 +189,9 @@ func (r *AutoCode) Pretreatment() error {
 			}
 		}
 	} // GvaModel
+	if err := r.relations(); err != nil {
+		return err
+	} // 关联关系
 	if r.Package == "" {
 		return errors.New("Package为空!")
 	} // 增加判断：Package不为空
@@ -203,27 +217,177 @@ func (r *AutoCode) History() SysAutoHistoryCreate {
 	}
 }
 
+// foreignKeys 为 belongsTo 关联补充外键字段
+func (r *AutoCode) foreignKeys() {
+	names := make(map[string]bool, len(r.Fields))
+	for _, field := range r.Fields {
+		names[field.FieldName] = true
+	}
+	for _, field := range r.Fields {
+		if field.Relation == nil || field.Relation.Type != RelationBelongsTo {
+			continue
+		}
+		if field.Relation.ForeignKey == "" {
+			field.Relation.ForeignKey = field.FieldName + "ID"
+		}
+		if names[field.Relation.ForeignKey] {
+			continue
+		}
+		names[field.Relation.ForeignKey] = true
+		foreignKey := &AutoCodeField{
+			FieldName:  field.Relation.ForeignKey,
+			FieldDesc:  field.FieldDesc + "ID",
+			FieldType:  "uint", // 与 GVA_MODEL 的 ID 类型一致 否则无法创建外键约束
+			FieldJson:  strings.ToLower(field.Relation.ForeignKey[:1]) + field.Relation.ForeignKey[1:],
+			Comment:    field.FieldDesc + "ID",
+			ColumnName: relationNamer.ColumnName("", field.Relation.ForeignKey),
+			Front:      true,
+			Clearable:  true,
+		}
+		if field.Relation.Table != "" && field.Relation.SearchColumn != "" {
+			foreignKey.DataSource = &DataSource{Table: field.Relation.Table, Label: field.Relation.SearchColumn, Value: "id", Association: 1}
+		} // 前端以下拉框选择关联数据
+		r.Fields = append(r.Fields, foreignKey)
+	}
+}
+
+// relations 校验关联关系并计算模板需要的类型 标签与字段名
+func (r *AutoCode) relations() error {
+	r.Relations = r.Relations[:0]
+	r.RelationImports = r.RelationImports[:0]
+	r.HasCascade, r.HasNestedUpdate = false, false
+	imports := make(map[string]bool)
+	columns := make(map[string]string, len(r.Fields))
+	for _, field := range r.Fields {
+		columns[field.FieldName] = field.ColumnName
+	}
+	for _, field := range r.Fields {
+		relation := field.Relation
+		if relation == nil {
+			continue
+		}
+		if relation.StructName == "" {
+			return errors.Errorf("[%s]关联结构体不能为空!", field.FieldName)
+		}
+		if relation.OnDelete != "" && !relationOnDelete[relation.OnDelete] {
+			return errors.Errorf("[%s]不支持的删除策略: %s", field.FieldName, relation.OnDelete)
+		}
+		if relation.SearchColumn != "" && relation.Table == "" {
+			return errors.Errorf("[%s]按关联字段搜索需要指定关联表名!", field.FieldName)
+		}
+		if field.FieldSearchType == "BETWEEN" || field.FieldSearchType == "NOT BETWEEN" {
+			return errors.Errorf("[%s]关联字段不支持范围搜索!", field.FieldName)
+		}
+		if relation.SearchColumn == "" {
+			field.FieldSearchType = ""
+		}
+		if relation.References == "" {
+			relation.References = "ID"
+		}
+		relation.Model = relation.StructName
+		if relation.Package != "" && relation.Package != r.Package {
+			relation.Model = relation.Package + "." + relation.StructName
+			if !imports[relation.Package] {
+				imports[relation.Package] = true
+				r.RelationImports = append(r.RelationImports, relation.Package)
+			}
+		}
+		relation.ReferenceColumn = relationNamer.ColumnName("", relation.References)
+		var tags []string
+		switch relation.Type {
+		case RelationBelongsTo:
+			relation.ForeignColumn = columns[relation.ForeignKey]
+			tags = append(tags, "foreignKey:"+relation.ForeignKey, "references:"+relation.References)
+		case RelationHasMany:
+			if relation.ForeignKey == "" {
+				relation.ForeignKey = r.StructName + "ID"
+			}
+			relation.ForeignColumn = relationNamer.ColumnName("", relation.ForeignKey)
+			tags = append(tags, "foreignKey:"+relation.ForeignKey)
+			r.HasNestedUpdate = true
+		case RelationMany2Many:
+			if relation.JoinTable == "" {
+				relation.JoinTable = relationNamer.ColumnName("", r.StructName) + "_" + relationNamer.ColumnName("", field.FieldName)
+			}
+			if r.PrimaryField == nil {
+				return errors.Errorf("[%s]多对多关联需要主键!", field.FieldName)
+			}
+			relation.JoinForeignColumn = relationNamer.ColumnName("", r.StructName+r.PrimaryField.FieldName)
+			relation.JoinReferenceColumn = relationNamer.ColumnName("", relation.StructName+relation.References)
+			tags = append(tags, "many2many:"+relation.JoinTable)
+			r.HasNestedUpdate = true
+		default:
+			return errors.Errorf("[%s]不支持的关联类型: %s", field.FieldName, relation.Type)
+		}
+		if relation.OnDelete != "" {
+			tags = append(tags, "constraint:OnDelete:"+relation.OnDelete)
+			if relation.OnDelete == "CASCADE" && relation.Type != RelationBelongsTo {
+				relation.Cascade = true
+				r.HasCascade = true
+			}
+		}
+		relation.Tag = strings.Join(tags, ";") + ";"
+		r.Relations = append(r.Relations, field)
+	}
+	return nil
+}
+
+const (
+	RelationBelongsTo = "belongsTo"
+	RelationHasMany   = "hasMany"
+	RelationMany2Many = "many2many"
+)
+
+// relationOnDelete 支持的删除策略
+var relationOnDelete = map[string]bool{"CASCADE": true, "SET NULL": true, "RESTRICT": true, "NO ACTION": true}
+
+// relationNamer 与 gorm 默认命名规则一致 用于推导外键与中间表字段名
+var relationNamer = schema.NamingStrategy{}
+
+// AutoCodeRelation 关联关系
+type AutoCodeRelation struct {
+	Type         string `json:"type"`         // 关联类型 belongsTo hasMany many2many
+	Package      string `json:"package"`      // 关联结构体所在包 为空时与当前包相同
+	StructName   string `json:"structName"`   // 关联结构体
+	Table        string `json:"table"`        // 关联表名 按关联字段搜索时使用
+	ForeignKey   string `json:"foreignKey"`   // 外键 belongsTo 为当前结构体字段 默认 字段名+ID hasMany 为关联结构体字段 默认 当前结构体名+ID
+	References   string `json:"references"`   // 引用字段 默认 ID
+	JoinTable    string `json:"joinTable"`    // 多对多中间表 默认 当前结构体_字段名
+	OnDelete     string `json:"onDelete"`     // 删除策略 CASCADE SET NULL RESTRICT NO ACTION
+	Preload      bool   `json:"preload"`      // 查询时预加载
+	SearchColumn string `json:"searchColumn"` // 按关联表的该字段搜索
+
+	Model               string `json:"-"` // 关联结构体类型
+	Tag                 string `json:"-"` // gorm 标签
+	Cascade             bool   `json:"-"` // 软删除时由代码级联删除
+	ForeignColumn       string `json:"-"` // 外键字段名
+	ReferenceColumn     string `json:"-"` // 引用字段名
+	JoinForeignColumn   string `json:"-"` // 中间表中当前表的外键字段名
+	JoinReferenceColumn string `json:"-"` // 中间表中关联表的外键字段名
+}
+
 type AutoCodeField struct {
-	FieldName       string      `json:"fieldName"`       // Field名
-	FieldDesc       string      `json:"fieldDesc"`       // 中文名
-	FieldType       string      `json:"fieldType"`       // Field数据类型
-	FieldJson       string      `json:"fieldJson"`       // FieldJson
-	DataTypeLong    string      `json:"dataTypeLong"`    // 数据库字段长度
-	Comment         string      `json:"comment"`         // 数据库字段描述
-	ColumnName      string      `json:"columnName"`      // 数据库字段
-	FieldSearchType string      `json:"fieldSearchType"` // 搜索条件
-	FieldSearchHide bool        `json:"fieldSearchHide"` // 是否隐藏查询条件
-	DictType        string      `json:"dictType"`        // 字典
-	Front           bool        `json:"front"`           // 是否前端可见
-	Require         bool        `json:"require"`         // 是否必填
-	DefaultValue    string      `json:"defaultValue"`    // 是否必填
-	ErrorText       string      `json:"errorText"`       // 校验失败文字
-	Clearable       bool        `json:"clearable"`       // 是否可清空
-	Sort            bool        `json:"sort"`            // 是否增加排序
-	PrimaryKey      bool        `json:"primaryKey"`      // 是否主键
-	DataSource      *DataSource `json:"dataSource"`      // 数据源
-	CheckDataSource bool        `json:"checkDataSource"` // 是否检查数据源
-	FieldIndexType  string      `json:"fieldIndexType"`  // 索引类型
+	FieldName       string            `json:"fieldName"`       // Field名
+	FieldDesc       string            `json:"fieldDesc"`       // 中文名
+	FieldType       string            `json:"fieldType"`       // Field数据类型
+	FieldJson       string            `json:"fieldJson"`       // FieldJson
+	DataTypeLong    string            `json:"dataTypeLong"`    // 数据库字段长度
+	Comment         string            `json:"comment"`         // 数据库字段描述
+	ColumnName      string            `json:"columnName"`      // 数据库字段
+	FieldSearchType string            `json:"fieldSearchType"` // 搜索条件
+	FieldSearchHide bool              `json:"fieldSearchHide"` // 是否隐藏查询条件
+	DictType        string            `json:"dictType"`        // 字典
+	Front           bool              `json:"front"`           // 是否前端可见
+	Require         bool              `json:"require"`         // 是否必填
+	DefaultValue    string            `json:"defaultValue"`    // 是否必填
+	ErrorText       string            `json:"errorText"`       // 校验失败文字
+	Clearable       bool              `json:"clearable"`       // 是否可清空
+	Sort            bool              `json:"sort"`            // 是否增加排序
+	PrimaryKey      bool              `json:"primaryKey"`      // 是否主键
+	DataSource      *DataSource       `json:"dataSource"`      // 数据源
+	CheckDataSource bool              `json:"checkDataSource"` // 是否检查数据源
+	FieldIndexType  string            `json:"fieldIndexType"`  // 索引类型
+	Relation        *AutoCodeRelation `json:"relation"`        // 关联关系 设置后该字段为关联字段 不对应数据库字段
 }
 
 type AutoFunc struct {
@@ -239,3 +403,11 @@ type AutoFunc struct {
 	Method          string `json:"method"`          // 方法
 	IsPlugin        bool   `json:"isPlugin"`        // 是否插件
 }
+
+// AutoCodeRegenerate 结构变更后重新生成代码
+type AutoCodeRegenerate struct {
+	AutoCode
+	DryRun      bool `json:"dryRun"`      // 只预览合并结果与迁移 不写入文件
+	Migrate     bool `json:"migrate"`     // 立即执行生成的数据库迁移
+	ConfirmDrop bool `json:"confirmDrop"` // 确认立即执行删除字段的迁移 删除的字段中的数据无法恢复
+}
@@ -0,0 +1,79 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AutoCodeInspect 检查业务库 推断批量导入的结构化信息
+type AutoCodeInspect struct {
+	BusinessDB  string   `json:"businessDB" form:"businessDB"`   // 业务库 为空时为系统库
+	DbName      string   `json:"dbName" form:"dbName"`           // 数据库名
+	Package     string   `json:"package" form:"package"`         // 生成到的包
+	Tables      []string `json:"tables" form:"tables"`           // 导入的表 为空时导入全部表
+	TablePrefix string   `json:"tablePrefix" form:"tablePrefix"` // 生成结构体名称时去掉的表前缀
+	DictLimit   int      `json:"dictLimit" form:"dictLimit"`     // 不同值数量不超过该值的字段视为枚举并建议字典 为0时为10 小于0时不建议
+}
+
+// AutoCodeImportDictValue 建议的字典值
+type AutoCodeImportDictValue struct {
+	Label string `json:"label"` // 展示值 默认与字典值相同
+	Value string `json:"value"` // 字典值
+}
+
+// AutoCodeImportDict 为枚举字段建议的字典
+type AutoCodeImportDict struct {
+	Table  string                    `json:"table"`  // 来源表
+	Column string                    `json:"column"` // 来源字段
+	Name   string                    `json:"name"`   // 字典名（中）
+	Type   string                    `json:"type"`   // 字典名（英） 字段的 dictType
+	Exists bool                      `json:"exists"` // 同名字典已存在 导入时不重复创建
+	Values []AutoCodeImportDictValue `json:"values"` // 字典值
+}
+
+// AutoCodeImport 批量生成 多张表生成到同一个包 只记录一条历史
+type AutoCodeImport struct {
+	Package      string               `json:"package"`      // 生成到的包
+	BusinessDB   string               `json:"businessDB"`   // 业务库
+	DbName       string               `json:"dbName"`       // 数据库名
+	Description  string               `json:"description"`  // 历史记录描述 为空时自动生成
+	Codes        []AutoCode           `json:"codes"`        // 每张表的结构化信息 可在检查结果上修改
+	Dictionaries []AutoCodeImportDict `json:"dictionaries"` // 需要创建的字典 只创建被字段引用且不存在的字典
+	DryRun       bool                 `json:"dryRun"`       // 试运行 只返回变更不写入
+}
+
+// Structs 全部结构体名称
+func (r *AutoCodeImport) Structs() []string {
+	structs := make([]string, 0, len(r.Codes))
+	for i := range r.Codes {
+		structs = append(structs, r.Codes[i].StructName)
+	}
+	return structs
+}
+
+// History 批量生成的历史记录 表名与结构体名称为摘要 全部表名与结构体名称记录在 Tables Structs 中
+func (r *AutoCodeImport) History() SysAutoHistoryCreate {
+	bytes, _ := json.Marshal(r)
+	tables := make([]string, 0, len(r.Codes))
+	for i := range r.Codes {
+		tables = append(tables, r.Codes[i].TableName)
+	}
+	structs := r.Structs()
+	description := r.Description
+	if description == "" {
+		description = fmt.Sprintf("从%s批量导入%d张表", r.DbName, len(r.Codes))
+	}
+	create := SysAutoHistoryCreate{
+		Package:     r.Package,
+		Request:     string(bytes),
+		BusinessDB:  r.BusinessDB,
+		Description: description,
+		Tables:      tables,
+		Structs:     structs,
+	}
+	if len(tables) > 0 {
+		create.Table = fmt.Sprintf("%s等%d张表", tables[0], len(tables))
+		create.StructName = fmt.Sprintf("%s等%d个结构体", structs[0], len(structs))
+	}
+	return create
+}
@@ -9,6 +9,7 @@ type SysAutoCodePackageCreate struct {
 	Label       string `json:"label" example:"展示名"`
 	Template    string `json:"template"  example:"模版"`
 	PackageName string `json:"packageName" example:"包名"`
+	Pack        string `json:"pack" example:"默认模板包"`
 }
 
 func (r *SysAutoCodePackageCreate) AutoCode() AutoCode {
@@ -23,5 +24,12 @@ func (r *SysAutoCodePackageCreate) Create() model.SysAutoCodePackage {
 		Label:       r.Label,
 		Template:    r.Template,
 		PackageName: r.PackageName,
+		Pack:        r.Pack,
 	}
 }
+
+// SysAutoCodePackageSetPack 设置包的默认模板包 Pack 为空时使用内置模板
+type SysAutoCodePackageSetPack struct {
+	ID   uint   `json:"id" form:"id"`
+	Pack string `json:"pack" form:"pack"`
+}
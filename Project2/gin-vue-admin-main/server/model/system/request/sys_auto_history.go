@@ -14,8 +14,12 @@ type SysAutoHistoryCreate struct {
 	Description string            // Struct中文名称
 	Injections  map[string]string // 注入路径
 	Templates   map[string]string // 模板信息
+	Hashes      map[string]string // 生成与注入后的文件摘要
 	ApiIDs      []uint            // api表注册内容
 	MenuID      uint              // 菜单ID
+	Tables      []string          // 批量导入的全部表名
+	Structs     []string          // 批量导入的全部结构体名称
+	MenuIDs     []uint            // 批量导入的全部菜单ID
 }
 
 func (r *SysAutoHistoryCreate) Create() model.SysAutoCodeHistory {
@@ -28,8 +32,12 @@ func (r *SysAutoHistoryCreate) Create() model.SysAutoCodeHistory {
 		Description: r.Description,
 		Injections:  r.Injections,
 		Templates:   r.Templates,
+		Hashes:      r.Hashes,
 		ApiIDs:      r.ApiIDs,
 		MenuID:      r.MenuID,
+		Tables:      r.Tables,
+		Structs:     r.Structs,
+		MenuIDs:     r.MenuIDs,
 	}
 	if entity.Table == "" {
 		entity.Table = r.StructName
@@ -42,6 +50,7 @@ type SysAutoHistoryRollBack struct {
 	DeleteApi   bool `json:"deleteApi" form:"deleteApi"`     // 是否删除接口
 	DeleteMenu  bool `json:"deleteMenu" form:"deleteMenu"`   // 是否删除菜单
 	DeleteTable bool `json:"deleteTable" form:"deleteTable"` // 是否删除表
+	Force       bool `json:"force" form:"force"`             // 注入的代码在生成后被修改时仍然回滚
 }
 
 func (r *SysAutoHistoryRollBack) ApiIds(entity model.SysAutoCodeHistory) common.IdsReq {
@@ -52,3 +61,15 @@ func (r *SysAutoHistoryRollBack) ApiIds(entity model.SysAutoCodeHistory) common.
 	}
 	return common.IdsReq{Ids: ids}
 }
+
+type SysAutoCodeMigrationSearch struct {
+	common.PageInfo
+	HistoryID uint `json:"historyId" form:"historyId"` // 代码生成历史ID
+}
+
+type SysPluginUninstall struct {
+	Name        string `json:"name" form:"name" binding:"required"` // 插件名
+	DeleteApi   bool   `json:"deleteApi" form:"deleteApi"`          // 是否删除插件注册的接口
+	DeleteMenu  bool   `json:"deleteMenu" form:"deleteMenu"`        // 是否删除插件注册的菜单
+	DeleteTable bool   `json:"deleteTable" form:"deleteTable"`      // 是否执行回退迁移并删除插件的表
+}
@@ -0,0 +1,18 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+)
+
+// SysDBConnection 新增或更新业务库 更新时密码为空则保留原密码
+type SysDBConnection struct {
+	system.SysDBConnection
+	Password string `json:"password"` // 数据库密码 明文 保存时加密
+}
+
+type SysDBConnectionSearch struct {
+	AliasName string `json:"aliasName" form:"aliasName"`
+	Type      string `json:"type" form:"type"`
+	request.PageInfo
+}
@@ -0,0 +1,18 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+type SysExportScheduleSearch struct {
+	Name       string `json:"name" form:"name"`
+	TemplateID string `json:"templateID" form:"templateID"`
+	Channel    string `json:"channel" form:"channel"`
+	request.PageInfo
+}
+
+type SysExportScheduleRunSearch struct {
+	ScheduleID uint   `json:"scheduleID" form:"scheduleID"`
+	Status     string `json:"status" form:"status"`
+	request.PageInfo
+}
@@ -0,0 +1,18 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+type SysJobSearch struct {
+	Name    string `json:"name" form:"name"`
+	Handler string `json:"handler" form:"handler"`
+	request.PageInfo
+}
+
+type SysJobRunSearch struct {
+	JobID   uint   `json:"jobID" form:"jobID"`
+	Status  string `json:"status" form:"status"`
+	Trigger string `json:"trigger" form:"trigger"`
+	request.PageInfo
+}
@@ -0,0 +1,13 @@
+package request
+
+import "encoding/json"
+
+type SysPluginEnable struct {
+	Name    string `json:"name" binding:"required"` // 插件名
+	Enabled bool   `json:"enabled"`                 // 是否启用
+}
+
+type SysPluginConfig struct {
+	Name   string          `json:"name" binding:"required"`   // 插件名
+	Config json.RawMessage `json:"config" binding:"required"` // 插件配置 JSON对象
+}
@@ -0,0 +1,24 @@
+package request
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+)
+
+type SysRetentionPolicySearch struct {
+	Name  string `json:"name" form:"name"`
+	Table string `json:"table" form:"table"`
+	request.PageInfo
+}
+
+type SysRetentionRunSearch struct {
+	PolicyID uint   `json:"policyID" form:"policyID"`
+	Status   string `json:"status" form:"status"`
+	DryRun   *bool  `json:"dryRun" form:"dryRun"`
+	request.PageInfo
+}
+
+// RunSysRetention 执行数据保留策略
+type RunSysRetention struct {
+	ID     uint `json:"id" form:"id"`         // 策略ID
+	DryRun bool `json:"dryRun" form:"dryRun"` // 仅统计将被删除的行数 不删除
+}
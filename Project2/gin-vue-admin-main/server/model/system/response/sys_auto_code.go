@@ -1,5 +1,10 @@
 package response
 
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
 type Db struct {
 	Database string `json:"database" gorm:"column:database"`
 }
@@ -14,4 +19,61 @@ type Column struct {
 	DataTypeLong  string `json:"dataTypeLong" gorm:"column:data_type_long"`
 	ColumnComment string `json:"columnComment" gorm:"column:column_comment"`
 	PrimaryKey    bool   `json:"primaryKey" gorm:"column:primary_key"`
+	Nullable      bool   `json:"nullable" gorm:"column:nullable"`          // 是否可为空
+	DefaultValue  string `json:"defaultValue" gorm:"column:default_value"` // 默认值 数据库中的原始表达式
+}
+
+// Index 索引 组合索引每个字段一行 按字段在索引中的顺序排列
+type Index struct {
+	IndexName  string `json:"indexName" gorm:"column:index_name"`
+	ColumnName string `json:"columnName" gorm:"column:column_name"`
+	Unique     bool   `json:"unique" gorm:"column:is_unique"`
+	PrimaryKey bool   `json:"primaryKey" gorm:"column:primary_key"`
+}
+
+// ForeignKey 外键 组合外键每个字段一行
+type ForeignKey struct {
+	ConstraintName   string `json:"constraintName" gorm:"column:constraint_name"`
+	ColumnName       string `json:"columnName" gorm:"column:column_name"`
+	ReferencedTable  string `json:"referencedTable" gorm:"column:referenced_table"`
+	ReferencedColumn string `json:"referencedColumn" gorm:"column:referenced_column"`
+	OnDelete         string `json:"onDelete" gorm:"column:on_delete"`
+}
+
+const (
+	AutoCodeFileCreated   = "created"   // 新建
+	AutoCodeFileUpdated   = "updated"   // 本地未修改 直接覆盖
+	AutoCodeFileMerged    = "merged"    // 与本地修改合并
+	AutoCodeFileConflict  = "conflict"  // 存在冲突 已写入冲突标记
+	AutoCodeFileUnchanged = "unchanged" // 无变化
+)
+
+// AutoCodeRegenerateFile 重新生成的文件
+type AutoCodeRegenerateFile struct {
+	Path      string `json:"path"`              // 文件路径
+	Status    string `json:"status"`            // 合并状态
+	Conflicts int    `json:"conflicts"`         // 冲突数量
+	Content   string `json:"content,omitempty"` // 合并后的内容 仅预览时返回
+}
+
+// AutoCodeRegenerate 重新生成结果
+type AutoCodeRegenerate struct {
+	Files     []AutoCodeRegenerateFile     `json:"files"`
+	Migration *system.SysAutoCodeMigration `json:"migration"` // 数据库迁移 结构无变化时为空
+}
+
+// AutoCodePackFile 模板包将生成的文件
+type AutoCodePackFile struct {
+	Template string `json:"template"`         // 模板文件 相对项目根目录
+	Target   string `json:"target"`           // 生成路径 相对项目根目录
+	Source   string `json:"source"`           // 来源 内置模板名或模板包名
+	Emit     bool   `json:"emit"`             // 是否生成
+	Reason   string `json:"reason,omitempty"` // 不生成的原因
+}
+
+// AutoCodeInspect 批量导入的检查结果 确认或修改后提交批量生成
+type AutoCodeInspect struct {
+	Codes        []request.AutoCode           `json:"codes"`        // 每张表推断的结构化信息
+	Dictionaries []request.AutoCodeImportDict `json:"dictionaries"` // 为枚举字段建议的字典
+	Warnings     []string                     `json:"warnings"`     // 无法推断的组合索引 关联到未导入表的外键等
 }
@@ -0,0 +1,26 @@
+package response
+
+import "encoding/json"
+
+type SysPluginStatus struct {
+	Name    string          `json:"name"`    // 插件名
+	Kind    string          `json:"kind"`    // 插件类型 v1 v2 remote
+	Version string          `json:"version"` // 通过插件安装登记的版本 内置插件为空
+	Enabled bool            `json:"enabled"` // 是否启用
+	Healthy bool            `json:"healthy"` // 健康检查是否通过 停用的插件不检查
+	Error   string          `json:"error"`   // 健康检查失败原因
+	Routes  []string        `json:"routes"`  // 插件注册的路由
+	Config  json.RawMessage `json:"config"`  // 插件配置
+}
+
+// PluginInstallReport 插件安装报告 安装失败时同样返回已完成的检查结果
+type PluginInstallReport struct {
+	Name       string   `json:"name"`       // 插件名 多个插件以英文逗号分隔
+	Version    string   `json:"version"`    // 插件版本 多个插件以英文逗号分隔
+	Files      int      `json:"files"`      // 压缩包中的文件数
+	Signed     bool     `json:"signed"`     // 是否由信任库中的密钥签名
+	KeyID      string   `json:"keyId"`      // 签名密钥ID
+	Violations []string `json:"violations"` // 引用了禁止的包 文件: 包
+	Warnings   []string `json:"warnings"`   // 警告
+	Installed  bool     `json:"installed"`  // 是否安装成功
+}
@@ -20,9 +20,14 @@ type SysAutoCodeHistory struct {
 	Description     string             `json:"description" gorm:"column:description;comment:Struct中文名称"`
 	Templates       map[string]string  `json:"template" gorm:"serializer:json;type:text;column:templates;comment:模板信息"`
 	Injections      map[string]string  `json:"injections" gorm:"serializer:json;type:text;column:Injections;comment:注入路径"`
+	Hashes          map[string]string  `json:"hashes" gorm:"serializer:json;type:text;column:hashes;comment:生成与注入后的文件摘要"`
 	Flag            int                `json:"flag" gorm:"column:flag;comment:[0:创建,1:回滚]"`
+	Version         int                `json:"version" gorm:"column:version;default:1;comment:结构版本 每次重新生成递增"`
 	ApiIDs          []uint             `json:"apiIDs" gorm:"serializer:json;column:api_ids;comment:api表注册内容"`
 	MenuID          uint               `json:"menuId" gorm:"column:menu_id;comment:菜单ID"`
+	Tables          []string           `json:"tables" gorm:"serializer:json;type:text;column:tables;comment:批量导入的全部表名"`
+	Structs         []string           `json:"structs" gorm:"serializer:json;type:text;column:structs;comment:批量导入的全部结构体名称"`
+	MenuIDs         []uint             `json:"menuIDs" gorm:"serializer:json;column:menu_ids;comment:批量导入的全部菜单ID"`
 	AutoCodePackage SysAutoCodePackage `json:"autoCodePackage" gorm:"foreignKey:ID;references:PackageID"`
 	PackageID       uint               `json:"packageID" gorm:"column:package_id;comment:包ID"`
 }
@@ -0,0 +1,59 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+const (
+	AutoCodeMigrationAddColumn    = "add_column"
+	AutoCodeMigrationAlterColumn  = "alter_column"
+	AutoCodeMigrationRenameColumn = "rename_column"
+	AutoCodeMigrationDropColumn   = "drop_column"
+	AutoCodeMigrationCreateIndex  = "create_index"
+	AutoCodeMigrationDropIndex    = "drop_index"
+
+	AutoCodeMigrationPending  = "pending"
+	AutoCodeMigrationApplied  = "applied"
+	AutoCodeMigrationReverted = "reverted"
+	AutoCodeMigrationFailed   = "failed"
+)
+
+// AutoCodeMigrationOp 单个迁移操作
+type AutoCodeMigrationOp struct {
+	Action string `json:"action"`          // 操作类型
+	Column string `json:"column"`          // 字段名
+	From   string `json:"from,omitempty"`  // 重命名前的字段名
+	Index  string `json:"index,omitempty"` // 索引名
+}
+
+// SysAutoCodeMigration 重新生成代码时根据结构变化生成的数据库迁移
+type SysAutoCodeMigration struct {
+	global.GVA_MODEL
+	HistoryID  uint                  `json:"historyId" form:"historyId" gorm:"column:history_id;index;comment:代码生成历史ID;"` // 代码生成历史ID
+	BusinessDB string                `json:"businessDb" gorm:"column:business_db;comment:业务库;"`                           // 业务库
+	Table      string                `json:"tableName" gorm:"column:table_name;comment:表名;"`                              // 表名
+	Version    int                   `json:"version" gorm:"column:version;comment:迁移后的结构版本;"`                             // 迁移后的结构版本
+	From       string                `json:"from" gorm:"column:from_spec;type:text;comment:迁移前的结构化信息;"`                   // 迁移前的结构化信息
+	To         string                `json:"to" gorm:"column:to_spec;type:text;comment:迁移后的结构化信息;"`                       // 迁移后的结构化信息
+	Up         []AutoCodeMigrationOp `json:"up" gorm:"column:up;serializer:json;type:text;comment:迁移操作;"`                 // 迁移操作
+	Down       []AutoCodeMigrationOp `json:"down" gorm:"column:down;serializer:json;type:text;comment:回退操作;"`             // 回退操作
+	Status     string                `json:"status" gorm:"column:status;size:16;comment:状态;"`                             // 状态
+	Error      string                `json:"error" gorm:"column:error;type:text;comment:错误信息;"`                           // 错误信息
+	AppliedAt  *time.Time            `json:"appliedAt" gorm:"column:applied_at;comment:执行时间;"`                            // 执行时间
+}
+
+func (SysAutoCodeMigration) TableName() string {
+	return "sys_auto_code_migrations"
+}
+
+// DropColumns 迁移中将删除的字段 删除后数据无法恢复
+func (m SysAutoCodeMigration) DropColumns() (columns []string) {
+	for _, op := range m.Up {
+		if op.Action == AutoCodeMigrationDropColumn {
+			columns = append(columns, op.Column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,50 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// TemplatePackManifestName 模板包清单文件名 位于 resource/packs/{name}/ 目录下
+const TemplatePackManifestName = "pack.json"
+
+// TemplatePackManifest 模板包清单
+type TemplatePackManifest struct {
+	Name        string                 `json:"name"`        // 模板包名 与 resource/packs 下的目录名一致
+	Version     string                 `json:"version"`     // 模板包版本 语义化版本
+	Description string                 `json:"description"` // 模板包描述
+	Language    string                 `json:"language"`    // 目标语言 如 go vue react
+	Extends     string                 `json:"extends"`     // 继承的内置模板 package plugin, 为空时只生成清单中的文件
+	Excludes    []string               `json:"excludes"`    // 不生成的内置模板 相对 resource/{extends}/ 的路径前缀 如 web 表示不生成 vue 前端
+	Files       []TemplatePackFile     `json:"files"`       // 模板包中的文件
+	Variables   []TemplatePackVariable `json:"variables"`   // 额外变量 模板中以 {{.Variables.name}} 引用
+}
+
+// TemplatePackFile 模板包文件
+type TemplatePackFile struct {
+	Template    string   `json:"template"`    // 模板文件 相对 resource/packs/{name}/
+	Target      string   `json:"target"`      // 生成路径 相对项目根目录, 支持模板语法 如 {{.Web}}/view/{{.Package}}/{{.PackageName}}/index.tsx
+	Requires    []string `json:"requires"`    // 需要开启的 AutoCode 开关 如 autoMigrate, 以 ! 开头表示需要关闭, 不满足时不生成
+	Description string   `json:"description"` // 文件描述
+}
+
+// TemplatePackVariable 模板包额外变量
+type TemplatePackVariable struct {
+	Name        string `json:"name"`        // 变量名
+	Default     string `json:"default"`     // 默认值 未传入时使用
+	Required    bool   `json:"required"`    // 是否必填 必填且无默认值时未传入则拒绝生成
+	Description string `json:"description"` // 描述
+}
+
+// SysAutoCodeTemplatePack 已安装的模板包
+type SysAutoCodeTemplatePack struct {
+	global.GVA_MODEL
+	Name     string               `json:"name" gorm:"column:name;size:64;index;comment:模板包名"`
+	Version  string               `json:"version" gorm:"column:version;size:32;comment:模板包版本"`
+	Language string               `json:"language" gorm:"column:language;size:32;comment:目标语言"`
+	Extends  string               `json:"extends" gorm:"column:extends;size:32;comment:继承的内置模板"`
+	Manifest TemplatePackManifest `json:"manifest" gorm:"serializer:json;type:text;column:manifest;comment:模板包清单"`
+}
+
+func (SysAutoCodeTemplatePack) TableName() string {
+	return "sys_auto_code_template_packs"
+}
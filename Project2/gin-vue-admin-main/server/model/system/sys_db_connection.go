@@ -0,0 +1,50 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// SysDBConnection 在系统中维护的业务库 与配置文件中的 db-list 一同注册到 GVA_DBList
+type SysDBConnection struct {
+	global.GVA_MODEL
+	AliasName    string `json:"aliasName" form:"aliasName" gorm:"column:alias_name;size:64;uniqueIndex;comment:别名;"`        // 别名 即代码生成器与导出模板中使用的业务库名
+	Type         string `json:"type" form:"type" gorm:"column:type;size:16;comment:数据库类型 mysql|pgsql|mssql|oracle|sqlite;"` // 数据库类型
+	Path         string `json:"path" form:"path" gorm:"column:path;size:255;comment:数据库地址;"`                                // 数据库地址 sqlite为文件所在目录
+	Port         string `json:"port" form:"port" gorm:"column:port;size:16;comment:数据库端口;"`                                 // 数据库端口
+	Dbname       string `json:"dbName" form:"dbName" gorm:"column:db_name;size:128;comment:数据库名;"`                          // 数据库名
+	Username     string `json:"username" form:"username" gorm:"column:username;size:128;comment:数据库账号;"`                    // 数据库账号
+	Password     string `json:"-" gorm:"column:password;type:text;comment:加密后的数据库密码;"`                                      // 加密后的数据库密码 不返回给前端
+	Config       string `json:"config" form:"config" gorm:"column:config;size:255;comment:高级配置;"`                           // 高级配置
+	Prefix       string `json:"prefix" form:"prefix" gorm:"column:prefix;size:64;comment:表前缀;"`                             // 表前缀
+	Singular     bool   `json:"singular" form:"singular" gorm:"column:singular;comment:是否禁用复数表名;"`                          // 是否禁用复数表名
+	MaxIdleConns int    `json:"maxIdleConns" form:"maxIdleConns" gorm:"column:max_idle_conns;comment:空闲中的最大连接数;"`           // 空闲中的最大连接数
+	MaxOpenConns int    `json:"maxOpenConns" form:"maxOpenConns" gorm:"column:max_open_conns;comment:打开到数据库的最大连接数;"`        // 打开到数据库的最大连接数
+	Disable      bool   `json:"disable" form:"disable" gorm:"column:disable;comment:是否禁用;"`                                 // 是否禁用 禁用时只保留配置不建立连接
+	Remark       string `json:"remark" form:"remark" gorm:"column:remark;size:255;comment:备注;"`                             // 备注
+}
+
+func (SysDBConnection) TableName() string {
+	return "sys_db_connections"
+}
+
+// SpecializedDB 转换为业务库配置 password 为解密后的密码
+func (c SysDBConnection) SpecializedDB(password string) config.SpecializedDB {
+	return config.SpecializedDB{
+		Type:      c.Type,
+		AliasName: c.AliasName,
+		Disable:   c.Disable,
+		GeneralDB: config.GeneralDB{
+			Prefix:       c.Prefix,
+			Port:         c.Port,
+			Config:       c.Config,
+			Dbname:       c.Dbname,
+			Username:     c.Username,
+			Password:     password,
+			Path:         c.Path,
+			MaxIdleConns: c.MaxIdleConns,
+			MaxOpenConns: c.MaxOpenConns,
+			Singular:     c.Singular,
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+const (
+	ExportFormatExcel = "xlsx"
+	ExportFormatCsv   = "csv"
+
+	ExportChannelEmail   = "email"
+	ExportChannelWebhook = "webhook"
+
+	ExportRunRunning = "running"
+	ExportRunSuccess = "success"
+	ExportRunFailed  = "failed"
+)
+
+// SysExportSchedule 导出模板定时报表
+type SysExportSchedule struct {
+	global.GVA_MODEL
+	Name         string            `json:"name" form:"name" gorm:"column:name;comment:报表名称;"`                                      // 报表名称
+	TemplateID   string            `json:"templateID" form:"templateID" gorm:"column:template_id;comment:导出模板标识;"`                 // 导出模板标识
+	Spec         string            `json:"spec" form:"spec" gorm:"column:spec;comment:cron表达式;"`                                   // cron表达式
+	Params       map[string]string `json:"params" form:"-" gorm:"serializer:json;type:text;column:params;comment:固定查询参数;"`         // 固定查询参数 与导出接口的query一致
+	Format       string            `json:"format" form:"format" gorm:"column:format;default:xlsx;comment:输出格式 xlsx|csv;"`          // 输出格式
+	Channel      string            `json:"channel" form:"channel" gorm:"column:channel;default:email;comment:投递方式 email|webhook;"` // 投递方式
+	Recipients   string            `json:"recipients" form:"recipients" gorm:"column:recipients;type:text;comment:收件人 多个以英文逗号分隔;"` // 收件人
+	WebhookURL   string            `json:"webhookURL" form:"webhookURL" gorm:"column:webhook_url;comment:webhook地址;"`              // webhook地址
+	AlertEmail   string            `json:"alertEmail" form:"alertEmail" gorm:"column:alert_email;comment:失败告警邮箱 多个以英文逗号分隔;"`       // 失败告警邮箱
+	AlertWebhook string            `json:"alertWebhook" form:"alertWebhook" gorm:"column:alert_webhook;comment:失败告警webhook;"`      // 失败告警webhook
+	Enabled      *bool             `json:"enabled" form:"enabled" gorm:"column:enabled;default:true;comment:是否启用;"`                // 是否启用
+	LastRunAt    *time.Time        `json:"lastRunAt" form:"-" gorm:"column:last_run_at;comment:最近执行时间;"`                           // 最近执行时间
+	LastStatus   string            `json:"lastStatus" form:"-" gorm:"column:last_status;comment:最近执行结果;"`                          // 最近执行结果
+}
+
+func (SysExportSchedule) TableName() string {
+	return "sys_export_schedules"
+}
+
+// IsEnabled 未设置时视为启用
+func (s SysExportSchedule) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// SysExportScheduleRun 定时报表执行记录
+type SysExportScheduleRun struct {
+	global.GVA_MODEL
+	ScheduleID uint       `json:"scheduleID" form:"scheduleID" gorm:"column:schedule_id;index;comment:报表ID;"` // 报表ID
+	TemplateID string     `json:"templateID" form:"templateID" gorm:"column:template_id;comment:导出模板标识;"`     // 导出模板标识
+	Manual     bool       `json:"manual" form:"manual" gorm:"column:manual;comment:是否手动触发;"`                  // 是否手动触发
+	Status     string     `json:"status" form:"status" gorm:"column:status;comment:执行状态;"`                    // 执行状态
+	StartedAt  time.Time  `json:"startedAt" gorm:"column:started_at;comment:开始时间;"`                           // 开始时间
+	FinishedAt *time.Time `json:"finishedAt" gorm:"column:finished_at;comment:结束时间;"`                         // 结束时间
+	FileName   string     `json:"fileName" gorm:"column:file_name;comment:产出文件名;"`                            // 产出文件名
+	FilePath   string     `json:"filePath" gorm:"column:file_path;comment:产出文件访问路径;"`                         // 产出文件访问路径
+	FileSize   int64      `json:"fileSize" gorm:"column:file_size;comment:产出文件大小;"`                           // 产出文件大小
+	Delivered  bool       `json:"delivered" gorm:"column:delivered;comment:是否投递成功;"`                          // 是否投递成功
+	Error      string     `json:"error" gorm:"column:error;type:text;comment:错误信息;"`                          // 错误信息
+}
+
+func (SysExportScheduleRun) TableName() string {
+	return "sys_export_schedule_runs"
+}
@@ -0,0 +1,79 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+const (
+	// JobMisfireSkip 错过的执行直接跳过
+	JobMisfireSkip = "skip"
+	// JobMisfireOnce 启动时补执行一次错过的执行
+	JobMisfireOnce = "once"
+
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+	JobTriggerMisfire  = "misfire"
+
+	JobRunRunning = "running"
+	JobRunSuccess = "success"
+	JobRunFailed  = "failed"
+	JobRunTimeout = "timeout"
+)
+
+// SysJob 持久化的定时任务
+type SysJob struct {
+	global.GVA_MODEL
+	Name       string     `json:"name" form:"name" gorm:"column:name;comment:任务名称;"`                                      // 任务名称
+	Spec       string     `json:"spec" form:"spec" gorm:"column:spec;comment:cron表达式 含秒;"`                                // cron表达式
+	Handler    string     `json:"handler" form:"handler" gorm:"column:handler;index;comment:处理函数名称;"`                     // 处理函数名称
+	Args       string     `json:"args" form:"args" gorm:"column:args;type:text;comment:任务参数;"`                            // 任务参数 由处理函数自行解析
+	Enabled    *bool      `json:"enabled" form:"enabled" gorm:"column:enabled;default:true;comment:是否启用;"`                // 是否启用
+	Timeout    int        `json:"timeout" form:"timeout" gorm:"column:timeout;comment:超时时间 秒 0为不限制;"`                     // 超时时间 秒
+	Misfire    string     `json:"misfire" form:"misfire" gorm:"column:misfire;default:skip;comment:错过执行的处理策略 skip|once;"` // 错过执行的处理策略
+	Concurrent bool       `json:"concurrent" form:"concurrent" gorm:"column:concurrent;comment:是否允许上次未结束时再次执行;"`          // 是否允许并发执行
+	Remark     string     `json:"remark" form:"remark" gorm:"column:remark;comment:备注;"`                                  // 备注
+	NextRunAt  *time.Time `json:"nextRunAt" form:"-" gorm:"column:next_run_at;comment:下次执行时间;"`                           // 下次执行时间
+	LastRunAt  *time.Time `json:"lastRunAt" form:"-" gorm:"column:last_run_at;comment:最近执行时间;"`                           // 最近执行时间
+	LastStatus string     `json:"lastStatus" form:"-" gorm:"column:last_status;comment:最近执行结果;"`                          // 最近执行结果
+}
+
+func (SysJob) TableName() string {
+	return "sys_jobs"
+}
+
+// IsEnabled 未设置时视为启用
+func (j SysJob) IsEnabled() bool {
+	return j.Enabled == nil || *j.Enabled
+}
+
+// SysJobRun 定时任务执行记录
+type SysJobRun struct {
+	global.GVA_MODEL
+	JobID      uint       `json:"jobID" form:"jobID" gorm:"column:job_id;index;comment:任务ID;"`     // 任务ID
+	Handler    string     `json:"handler" form:"handler" gorm:"column:handler;comment:处理函数名称;"`    // 处理函数名称
+	Trigger    string     `json:"trigger" form:"trigger" gorm:"column:trigger_type;comment:触发方式;"` // 触发方式 schedule|manual|misfire
+	Node       string     `json:"node" form:"node" gorm:"column:node;comment:执行节点;"`               // 执行节点
+	Status     string     `json:"status" form:"status" gorm:"column:status;comment:执行状态;"`         // 执行状态
+	StartedAt  time.Time  `json:"startedAt" gorm:"column:started_at;comment:开始时间;"`                // 开始时间
+	FinishedAt *time.Time `json:"finishedAt" gorm:"column:finished_at;comment:结束时间;"`              // 结束时间
+	Duration   int64      `json:"duration" gorm:"column:duration;comment:耗时 毫秒;"`                  // 耗时 毫秒
+	Output     string     `json:"output" gorm:"column:output;type:text;comment:日志输出;"`             // 日志输出
+	Error      string     `json:"error" gorm:"column:error;type:text;comment:错误信息;"`               // 错误信息
+}
+
+func (SysJobRun) TableName() string {
+	return "sys_job_runs"
+}
+
+// SysJobLock 集群锁 未启用redis时用于保证同一次调度只在一个节点执行
+type SysJobLock struct {
+	Name      string    `json:"name" gorm:"primarykey;size:191;comment:锁名称;"` // 锁名称
+	Owner     string    `json:"owner" gorm:"column:owner;comment:持有节点;"`      // 持有节点
+	ExpiresAt time.Time `json:"expiresAt" gorm:"column:expires_at;index;comment:过期时间;"`
+}
+
+func (SysJobLock) TableName() string {
+	return "sys_job_locks"
+}
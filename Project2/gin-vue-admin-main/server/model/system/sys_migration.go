@@ -0,0 +1,20 @@
+package system
+
+import (
+	"time"
+)
+
+// SysMigration 已执行的数据库迁移 每个模块的每个版本只有一条记录
+type SysMigration struct {
+	ID        uint      `gorm:"primarykey" json:"ID"`
+	Module    string    `json:"module" gorm:"column:module;size:64;uniqueIndex:idx_sys_migrations_version;comment:模块或插件名;"` // 模块或插件名
+	Version   string    `json:"version" gorm:"column:version;size:32;uniqueIndex:idx_sys_migrations_version;comment:迁移版本;"` // 迁移版本
+	Name      string    `json:"name" gorm:"column:name;size:128;comment:迁移名称;"`                                             // 迁移名称
+	Checksum  string    `json:"checksum" gorm:"column:checksum;size:64;comment:执行时的脚本校验和;"`                                 // 执行时的脚本校验和
+	Duration  int64     `json:"duration" gorm:"column:duration;comment:执行耗时(毫秒);"`                                          // 执行耗时(毫秒)
+	AppliedAt time.Time `json:"appliedAt" gorm:"column:applied_at;comment:执行时间;"`                                           // 执行时间
+}
+
+func (SysMigration) TableName() string {
+	return "sys_migrations"
+}
@@ -0,0 +1,70 @@
+package system
+
+import (
+	"encoding/json"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// PluginManifestName 插件清单文件名 位于插件压缩包的 server/plugin/{name}/ 目录下
+const PluginManifestName = "manifest.json"
+
+// PluginManifest 插件清单
+type PluginManifest struct {
+	Name         string              `json:"name"`         // 插件名 与 server/plugin 下的目录名一致
+	Version      string              `json:"version"`      // 插件版本 语义化版本
+	Description  string              `json:"description"`  // 插件描述
+	Gva          string              `json:"gva"`          // 需要的gva版本约束 如 >=2.7.0 <3.0.0
+	Dependencies map[string]string   `json:"dependencies"` // 依赖的插件及其版本约束
+	Config       []PluginConfigField `json:"config"`       // 配置项结构 位于 config.yaml 中以插件名为键的节点下
+	Migrations   []PluginMigration   `json:"migrations"`   // 迁移脚本 按顺序执行
+	Tables       []string            `json:"tables"`       // 插件创建的表 安装时实际新建的表卸载时可选删除
+	Apis         []SysApi            `json:"apis"`         // 需要注册的api
+	Menus        []SysBaseMenu       `json:"menus"`        // 需要注册的菜单 支持 children
+}
+
+// PluginConfigField 插件配置项
+type PluginConfigField struct {
+	Key         string      `json:"key"`         // 配置键
+	Type        string      `json:"type"`        // 类型 string number boolean object array
+	Default     interface{} `json:"default"`     // 默认值 未配置时写入
+	Required    bool        `json:"required"`    // 是否必填
+	Description string      `json:"description"` // 描述
+}
+
+// PluginMigration 插件迁移脚本 路径相对于 server/plugin/{name}/
+type PluginMigration struct {
+	Version string `json:"version"` // 迁移版本
+	Up      string `json:"up"`      // 安装时执行的sql文件
+	Down    string `json:"down"`    // 卸载并删除表时执行的sql文件
+}
+
+// SysPlugin 已安装插件
+type SysPlugin struct {
+	global.GVA_MODEL
+	Name       string            `json:"name" gorm:"column:name;size:64;index;comment:插件名"`
+	Version    string            `json:"version" gorm:"column:version;size:32;comment:插件版本"`
+	Manifest   PluginManifest    `json:"manifest" gorm:"serializer:json;type:text;column:manifest;comment:插件清单"`
+	Injections map[string]string `json:"injections" gorm:"serializer:json;type:text;column:injections;comment:注入内容"`
+	ApiIDs     []uint            `json:"apiIDs" gorm:"serializer:json;column:api_ids;comment:api表注册内容"`
+	MenuIDs    []uint            `json:"menuIDs" gorm:"serializer:json;column:menu_ids;comment:菜单表注册内容"`
+	Tables     []string          `json:"tables" gorm:"serializer:json;type:text;column:tables;comment:安装时新建的表"`
+	Server     bool              `json:"server" gorm:"column:server;comment:是否安装了服务端"`
+	Web        bool              `json:"web" gorm:"column:web;comment:是否安装了前端"`
+}
+
+func (SysPlugin) TableName() string {
+	return "sys_plugins"
+}
+
+// SysPluginState 插件运行状态 启动时为已加载的插件自动创建
+type SysPluginState struct {
+	global.GVA_MODEL
+	Name    string          `json:"name" gorm:"column:name;size:64;index;comment:插件名"`
+	Enabled bool            `json:"enabled" gorm:"column:enabled;comment:是否启用"`
+	Config  json.RawMessage `json:"config" gorm:"type:text;column:config;comment:插件配置"`
+}
+
+func (SysPluginState) TableName() string {
+	return "sys_plugin_states"
+}
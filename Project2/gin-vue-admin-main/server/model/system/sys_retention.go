@@ -0,0 +1,62 @@
+package system
+
+import (
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+const (
+	RetentionArchiveNone = ""
+	RetentionArchiveFile = "file"
+	RetentionArchiveOss  = "oss"
+
+	RetentionRunRunning = "running"
+	RetentionRunSuccess = "success"
+	RetentionRunFailed  = "failed"
+)
+
+// SysRetentionPolicy 数据保留策略 删除指定表中超过保留时长的数据
+type SysRetentionPolicy struct {
+	global.GVA_MODEL
+	Name      string     `json:"name" form:"name" gorm:"column:name;comment:策略名称;"`                                    // 策略名称
+	Table     string     `json:"table" form:"table" gorm:"column:table_name;comment:表名;"`                              // 表名
+	AgeColumn string     `json:"ageColumn" form:"ageColumn" gorm:"column:age_column;default:created_at;comment:时间字段;"` // 时间字段
+	KeyColumn string     `json:"keyColumn" form:"keyColumn" gorm:"column:key_column;default:id;comment:主键字段 用于分批删除;"`  // 主键字段
+	Interval  string     `json:"interval" form:"interval" gorm:"column:interval_value;comment:保留时长 如 2160h 或 90d;"`    // 保留时长
+	Archive   string     `json:"archive" form:"archive" gorm:"column:archive;comment:删除前归档 file|oss;"`                 // 删除前归档
+	BatchSize int        `json:"batchSize" form:"batchSize" gorm:"column:batch_size;comment:每批删除行数 0使用全局配置;"`          // 每批删除行数
+	Enabled   *bool      `json:"enabled" form:"enabled" gorm:"column:enabled;default:true;comment:是否启用;"`              // 是否启用
+	Remark    string     `json:"remark" form:"remark" gorm:"column:remark;comment:备注;"`                                // 备注
+	LastRunAt *time.Time `json:"lastRunAt" form:"-" gorm:"column:last_run_at;comment:最近执行时间;"`                         // 最近执行时间
+}
+
+func (SysRetentionPolicy) TableName() string {
+	return "sys_retention_policies"
+}
+
+// IsEnabled 未设置时视为启用
+func (p SysRetentionPolicy) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// SysRetentionRun 数据保留策略执行报告
+type SysRetentionRun struct {
+	global.GVA_MODEL
+	PolicyID   uint       `json:"policyID" form:"policyID" gorm:"column:policy_id;index;comment:策略ID;"` // 策略ID
+	Table      string     `json:"table" form:"table" gorm:"column:table_name;comment:表名;"`              // 表名
+	DryRun     bool       `json:"dryRun" form:"dryRun" gorm:"column:dry_run;comment:是否仅统计;"`            // 是否仅统计
+	Cutoff     time.Time  `json:"cutoff" gorm:"column:cutoff;comment:删除此时间之前的数据;"`                      // 删除此时间之前的数据
+	Matched    int64      `json:"matched" gorm:"column:matched;comment:符合条件的行数;"`                       // 符合条件的行数
+	Deleted    int64      `json:"deleted" gorm:"column:deleted;comment:已删除行数;"`                         // 已删除行数
+	Archived   int64      `json:"archived" gorm:"column:archived;comment:已归档行数;"`                       // 已归档行数
+	ArchiveUrl string     `json:"archiveUrl" gorm:"column:archive_url;comment:归档文件地址;"`                 // 归档文件地址
+	Status     string     `json:"status" form:"status" gorm:"column:status;comment:执行状态;"`              // 执行状态
+	StartedAt  time.Time  `json:"startedAt" gorm:"column:started_at;comment:开始时间;"`                     // 开始时间
+	FinishedAt *time.Time `json:"finishedAt" gorm:"column:finished_at;comment:结束时间;"`                   // 结束时间
+	Error      string     `json:"error" gorm:"column:error;type:text;comment:错误信息;"`                    // 错误信息
+}
+
+func (SysRetentionRun) TableName() string {
+	return "sys_retention_runs"
+}
@@ -2,17 +2,36 @@ package initialize
 
 import (
 	"context"
+	"embed"
 	"fmt"
+	"io/fs"
+
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement/model"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/migrate"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+//go:embed migrations
+var migrations embed.FS
+
+func init() {
+	dir, _ := fs.Sub(migrations, "migrations")
+	migrate.Register(migrate.OrderPlugin, migrate.Module{
+		Name: "announcement",
+		FS:   dir,
+		Dir:  "plugin/announcement/initialize/migrations",
+		Schema: func(db *gorm.DB) error {
+			return db.AutoMigrate(new(model.Info))
+		},
+	})
+}
+
+// Gorm 插件自有的表由 migrations 管理 这里只自动迁移代码生成器注入的模型
 func Gorm(ctx context.Context) {
-	err := global.GVA_DB.WithContext(ctx).AutoMigrate(
-		new(model.Info),
-	)
+	err := global.GVA_DB.WithContext(ctx).AutoMigrate()
 	if err != nil {
 		err = errors.Wrap(err, "注册表失败!")
 		zap.L().Error(fmt.Sprintf("%+v", err))
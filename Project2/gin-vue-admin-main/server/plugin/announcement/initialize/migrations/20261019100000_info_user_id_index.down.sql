@@ -0,0 +1 @@
+DROP INDEX idx_gva_announcements_info_user_id;
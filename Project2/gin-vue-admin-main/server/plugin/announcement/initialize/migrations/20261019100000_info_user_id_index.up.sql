@@ -0,0 +1,2 @@
+-- 按发布者筛选公告
+CREATE INDEX idx_gva_announcements_info_user_id ON gva_announcements_info (user_id);
@@ -2,6 +2,7 @@ package announcement
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/flipped-aurora/gin-vue-admin/server/plugin/announcement/initialize"
 	interfaces "github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/v2"
 	"github.com/gin-gonic/gin"
@@ -24,3 +25,20 @@ func (p *plugin) Register(group *gin.Engine) {
 	initialize.Gorm(ctx)
 	initialize.Router(group)
 }
+
+// 以下为插件生命周期钩子 启停插件、修改插件配置及健康检查时调用
+func (p *plugin) OnEnable(ctx context.Context) error {
+	return nil
+}
+
+func (p *plugin) OnDisable(ctx context.Context) error {
+	return nil
+}
+
+func (p *plugin) OnConfigChange(ctx context.Context, config json.RawMessage) error {
+	return nil
+}
+
+func (p *plugin) HealthCheck(ctx context.Context) error {
+	return nil
+}
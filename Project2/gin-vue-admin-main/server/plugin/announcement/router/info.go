@@ -11,8 +11,14 @@ type info struct{}
 
 // Init 初始化 公告 路由信息
 func (r *info) Init(public *gin.RouterGroup, private *gin.RouterGroup) {
+	r.Register(public, private, middleware.OperationRecord())
+}
+
+// Register 注册 公告 路由 record 为写操作使用的中间件
+// 进程外插件的请求由服务端转发 不携带 x-token 也没有操作记录表 不传 record 即可
+func (r *info) Register(public *gin.RouterGroup, private *gin.RouterGroup, record ...gin.HandlerFunc) {
 	{
-		group := private.Group("info").Use(middleware.OperationRecord())
+		group := private.Group("info").Use(record...)
 		group.POST("createInfo", apiInfo.CreateInfo)             // 新建公告
 		group.DELETE("deleteInfo", apiInfo.DeleteInfo)           // 删除公告
 		group.DELETE("deleteInfoByIds", apiInfo.DeleteInfoByIds) // 批量删除公告
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
 	"net/smtp"
@@ -22,6 +23,16 @@ func Email(To, subject string, body string) error {
 	return send(to, subject, body)
 }
 
+//@function: EmailWithAttach
+//@description: 带附件的Email发送方法
+//@param: To string, subject string, body string, filename string, content []byte
+//@return: error
+
+func EmailWithAttach(To, subject string, body string, filename string, content []byte) error {
+	to := strings.Split(To, ",")
+	return send(to, subject, body, attachment{filename: filename, content: content})
+}
+
 //@author: [SliverHorn](https://github.com/SliverHorn)
 //@function: ErrorToEmail
 //@description: 给email中间件错误发送邮件到指定邮箱
@@ -47,13 +58,19 @@ func EmailTest(subject string, body string) error {
 	return send(to, subject, body)
 }
 
+// attachment 邮件附件
+type attachment struct {
+	filename string
+	content  []byte
+}
+
 //@author: [maplepie](https://github.com/maplepie)
 //@function: send
 //@description: Email发送方法
-//@param: subject string, body string
+//@param: subject string, body string, attachments ...attachment
 //@return: error
 
-func send(to []string, subject string, body string) error {
+func send(to []string, subject string, body string, attachments ...attachment) error {
 	from := global.GlobalConfig.From
 	nickname := global.GlobalConfig.Nickname
 	secret := global.GlobalConfig.Secret
@@ -71,6 +88,11 @@ func send(to []string, subject string, body string) error {
 	e.To = to
 	e.Subject = subject
 	e.HTML = []byte(body)
+	for _, a := range attachments {
+		if _, err := e.Attach(bytes.NewReader(a.content), a.filename, ""); err != nil {
+			return err
+		}
+	}
 	var err error
 	hostAddr := fmt.Sprintf("%s:%d", host, port)
 	if isSSL {
@@ -0,0 +1,188 @@
+package {{.Package}}_test
+
+{{- $hasEnum := false }}
+{{- $hasRequire := false }}
+{{- range .Fields }}
+ {{- if not .Relation }}
+  {{- if eq .FieldType "enum" }}{{ $hasEnum = true }}{{ end }}
+  {{- if .Require }}{{ $hasRequire = true }}{{ end }}
+ {{- end }}
+{{- end }}
+{{- $models := printf "&%s.%s{}, &system.SysOperationRecord{}" .Package .StructName }}
+{{- range .Relations }}
+ {{- $models = printf "%s, &%s%s{}" $models (or (and (eq .Relation.Model .Relation.StructName) (printf "%s." $.Package)) "") .Relation.Model }}
+{{- end }}
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	{{- if $hasEnum }}
+	"strings"
+	{{- end }}
+	"testing"
+	{{- if .HasTimer }}
+	"time"
+	{{- end }}
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/{{.Package}}"
+	{{- range .RelationImports }}
+	{{- if ne . "system" }}
+	"github.com/flipped-aurora/gin-vue-admin/server/model/{{.}}"
+	{{- end }}
+	{{- end }}
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/router"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"github.com/gin-gonic/gin"
+	{{- if .NeedJSON }}
+	"gorm.io/datatypes"
+	{{- end }}
+)
+
+// newApiTest{{.StructName}} 生成第n条{{.Description}}测试数据
+func newApiTest{{.StructName}}(n int) {{.Package}}.{{.StructName}} {
+	return {{.Package}}.{{.StructName}}{
+	{{- range .Fields }}
+		{{- if not .Relation }}{{ template "apiTestValue" . }}{{ end }}
+	{{- end }}
+	}
+}
+
+// apiTest{{.StructName}}ID 获取{{.Description}}的主键
+func apiTest{{.StructName}}ID({{.Abbreviation}} {{.Package}}.{{.StructName}}) string {
+	{{- if .GvaModel }}
+	return fmt.Sprint({{.Abbreviation}}.ID)
+	{{- else if eq .PrimaryField.FieldType "string" "enum" "picture" "video" "richtext" }}
+	return fmt.Sprint({{.Abbreviation}}.{{.PrimaryField.FieldName}})
+	{{- else }}
+	return fmt.Sprint(*{{.Abbreviation}}.{{.PrimaryField.FieldName}})
+	{{- end }}
+}
+
+// list{{.StructName}} 请求{{.Description}}列表接口
+func list{{.StructName}}(t *testing.T, handler http.Handler, query string) (list []{{.Package}}.{{.StructName}}, total int64) {
+	t.Helper()
+	result := testdb.Do(t, handler, http.MethodGet, "/{{.Abbreviation}}/get{{.StructName}}List?page=1&pageSize=10"+query, nil)
+	if result.Code != 0 {
+		t.Fatalf("获取列表失败: %s", result.Msg)
+	}
+	var data struct {
+		List  []{{.Package}}.{{.StructName}} `json:"list"`
+		Total int64 `json:"total"`
+	}
+	if err := json.Unmarshal(result.Data, &data); err != nil {
+		t.Fatalf("解析列表失败: %v", err)
+	}
+	return data.List, data.Total
+}
+
+func Test{{.StructName}}Api(t *testing.T) {
+	testdb.Open(t, "{{.BusinessDB}}", {{$models}})
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	router.RouterGroupApp.{{.PackageT}}.Init{{.StructName}}Router(engine.Group("/"), engine.Group("/"))
+
+	for _, n := range []int{1, 2} {
+		if result := testdb.Do(t, engine, http.MethodPost, "/{{.Abbreviation}}/create{{.StructName}}", newApiTest{{.StructName}}(n)); result.Code != 0 {
+			t.Fatalf("创建失败: %s", result.Msg)
+		}
+	}
+	if result := testdb.Do(t, engine, http.MethodPost, "/{{.Abbreviation}}/create{{.StructName}}", []byte("{")); result.Code == 0 {
+		t.Errorf("非法JSON应创建失败")
+	}
+	{{- if $hasRequire }}
+	if result := testdb.Do(t, engine, http.MethodPost, "/{{.Abbreviation}}/create{{.StructName}}", map[string]any{}); result.Code == 0 {
+		t.Errorf("缺少必填字段应创建失败")
+	}
+	{{- end }}
+	if result := testdb.Do(t, engine, http.MethodGet, "/{{.Abbreviation}}/get{{.StructName}}List?page=abc", nil); result.Code == 0 {
+		t.Errorf("非法分页参数应查询失败")
+	}
+
+	list, total := list{{.StructName}}(t, engine, "")
+	if total != 2 || len(list) != 2 {
+		t.Fatalf("获取列表 total=%d len=%d, want 2", total, len(list))
+	}
+	first := list[0]
+	for _, item := range list {
+		if apiTest{{.StructName}}ID(item) < apiTest{{.StructName}}ID(first) {
+			first = item
+		}
+	}
+	{{- range .Fields }}
+	{{- if and (not .Relation) (or (and (eq .FieldSearchType "LIKE") (eq .FieldType "string" "picture" "video" "richtext")) (and (eq .FieldSearchType "=") (eq .FieldType "string" "picture" "video" "richtext" "int" "uint" "float64" "bool"))) }}
+
+	if _, total = list{{$.StructName}}(t, engine, "&{{.FieldJson}}="+url.QueryEscape(fmt.Sprint({{ if not (eq .FieldType "string" "picture" "video" "richtext") }}*{{ end }}first.{{.FieldName}}))); total != 1 {
+		t.Errorf("按{{.FieldDesc}}搜索 total=%d, want 1", total)
+	}
+	{{- end }}
+	{{- end }}
+	{{- range .Fields }}
+	{{- if and .Sort (not .Relation) }}
+
+	if list, _ = list{{$.StructName}}(t, engine, "&sort={{.ColumnName}}&order=descending"); len(list) != 2 {
+		t.Errorf("按{{.FieldDesc}}排序 len=%d, want 2", len(list))
+	}
+	{{- end }}
+	{{- end }}
+
+	query := "?{{.PrimaryField.FieldJson}}=" + url.QueryEscape(apiTest{{.StructName}}ID(first))
+	result := testdb.Do(t, engine, http.MethodGet, "/{{.Abbreviation}}/find{{.StructName}}"+query, nil)
+	if result.Code != 0 {
+		t.Fatalf("查询失败: %s", result.Msg)
+	}
+	var found {{.Package}}.{{.StructName}}
+	if err := json.Unmarshal(result.Data, &found); err != nil || apiTest{{.StructName}}ID(found) != apiTest{{.StructName}}ID(first) {
+		t.Errorf("查询结果 = %s, want %s, err=%v", apiTest{{.StructName}}ID(found), apiTest{{.StructName}}ID(first), err)
+	}
+	if result = testdb.Do(t, engine, http.MethodGet, "/{{.Abbreviation}}/find{{.StructName}}?{{.PrimaryField.FieldJson}}=0", nil); result.Code == 0 {
+		t.Errorf("查询不存在的记录应失败")
+	}
+	if result = testdb.Do(t, engine, http.MethodPut, "/{{.Abbreviation}}/update{{.StructName}}", found); result.Code != 0 {
+		t.Errorf("更新失败: %s", result.Msg)
+	}
+	if result = testdb.Do(t, engine, http.MethodPut, "/{{.Abbreviation}}/update{{.StructName}}", []byte("{")); result.Code == 0 {
+		t.Errorf("非法JSON应更新失败")
+	}
+
+	if result = testdb.Do(t, engine, http.MethodDelete, "/{{.Abbreviation}}/delete{{.StructName}}"+query, nil); result.Code != 0 {
+		t.Fatalf("删除失败: %s", result.Msg)
+	}
+	if result = testdb.Do(t, engine, http.MethodGet, "/{{.Abbreviation}}/find{{.StructName}}"+query, nil); result.Code == 0 {
+		t.Errorf("删除后仍可查询")
+	}
+	list, _ = list{{.StructName}}(t, engine, "")
+	ids := url.Values{}
+	for _, item := range list {
+		ids.Add("{{.PrimaryField.FieldJson}}s[]", apiTest{{.StructName}}ID(item))
+	}
+	if result = testdb.Do(t, engine, http.MethodDelete, "/{{.Abbreviation}}/delete{{.StructName}}ByIds?"+ids.Encode(), nil); result.Code != 0 {
+		t.Fatalf("批量删除失败: %s", result.Msg)
+	}
+	if _, total = list{{.StructName}}(t, engine, ""); total != 0 {
+		t.Errorf("批量删除后 total=%d, want 0", total)
+	}
+}
+{{- define "apiTestValue" }}
+	{{- if eq .FieldType "string" "picture" "video" "richtext" }}
+		{{.FieldName}}: fmt.Sprintf("{{.FieldJson}}-%d", n),
+	{{- else if eq .FieldType "enum" }}
+		{{.FieldName}}: strings.Trim(strings.Split("{{.DataTypeLong}}", ",")[0], "' "),
+	{{- else if eq .FieldType "int" }}
+		{{.FieldName}}: testdb.Ptr(n),
+	{{- else if eq .FieldType "uint" }}
+		{{.FieldName}}: testdb.Ptr(uint(n)),
+	{{- else if eq .FieldType "float64" }}
+		{{.FieldName}}: testdb.Ptr(float64(n)),
+	{{- else if eq .FieldType "bool" }}
+		{{.FieldName}}: testdb.Ptr(n%2 == 0),
+	{{- else if eq .FieldType "time.Time" }}
+		{{.FieldName}}: testdb.Ptr(time.Unix(int64(1700000000+n), 0)),
+	{{- else if eq .FieldType "json" }}
+		{{.FieldName}}: datatypes.JSON(`{"n":1}`),
+	{{- else if eq .FieldType "file" "pictures" "array" }}
+		{{.FieldName}}: datatypes.JSON(`[]`),
+	{{- end }}
+{{- end }}
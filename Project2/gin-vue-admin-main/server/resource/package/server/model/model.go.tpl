@@ -11,6 +11,9 @@ import (
 	{{- if .NeedJSON }}
 	"gorm.io/datatypes"
 	{{- end }}
+	{{- range .RelationImports }}
+	"github.com/flipped-aurora/gin-vue-admin/server/model/{{.}}"
+	{{- end }}
 )
 
 // {{.Description}} 结构体  {{.StructName}}
@@ -19,7 +22,13 @@ type {{.StructName}} struct {
     global.GVA_MODEL
 {{- end }}
 {{- range .Fields}}
-    {{- if eq .FieldType "enum" }}
+    {{- if .Relation }}
+    {{- if eq .Relation.Type "belongsTo" }}
+    {{.FieldName}}  *{{.Relation.Model}} `json:"{{.FieldJson}},omitempty" form:"-" gorm:"{{.Relation.Tag}}"`
+    {{- else }}
+    {{.FieldName}}  []{{.Relation.Model}} `json:"{{.FieldJson}}" form:"-" gorm:"{{.Relation.Tag}}"`
+    {{- end }}
+    {{- else if eq .FieldType "enum" }}
     {{.FieldName}}  string `json:"{{.FieldJson}}" form:"{{.FieldJson}}" gorm:"{{- if ne .FieldIndexType "" -}}{{ .FieldIndexType }};{{- end -}}{{- if .PrimaryKey -}}primarykey;{{- end -}}{{- if .DefaultValue -}}default:{{ .DefaultValue }};{{- end -}}column:{{.ColumnName}};type:enum({{.DataTypeLong}});comment:{{.Comment}};" {{- if .Require }} binding:"required"{{- end -}}`
     {{- else if eq .FieldType "picture" }}
     {{.FieldName}}  string `json:"{{.FieldJson}}" form:"{{.FieldJson}}" gorm:"{{- if ne .FieldIndexType "" -}}{{ .FieldIndexType }};{{- end -}}{{- if .PrimaryKey -}}primarykey;{{- end -}}{{- if .DefaultValue -}}default:{{ .DefaultValue }};{{- end -}}column:{{.ColumnName}};comment:{{.Comment}};{{- if .DataTypeLong -}}size:{{.DataTypeLong}};{{- end -}}" {{- if .Require }} binding:"required"{{- end -}}`
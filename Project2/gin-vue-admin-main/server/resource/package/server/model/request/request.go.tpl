@@ -12,7 +12,9 @@ type {{.StructName}}Search struct{
 {{- end }}
 {{- range .Fields}}
     {{- if ne .FieldSearchType ""}}
-        {{- if eq .FieldSearchType "BETWEEN" "NOT BETWEEN"}}
+        {{- if .Relation }}
+    {{.FieldName}}  string `json:"{{.FieldJson}}" form:"{{.FieldJson}}"` // 按{{.FieldDesc}}的{{.Relation.SearchColumn}}搜索
+        {{- else if eq .FieldSearchType "BETWEEN" "NOT BETWEEN"}}
     Start{{.FieldName}}  *{{.FieldType}}  `json:"start{{.FieldName}}" form:"start{{.FieldName}}"`
     End{{.FieldName}}  *{{.FieldType}}  `json:"end{{.FieldName}}" form:"end{{.FieldName}}"`
         {{- else }}
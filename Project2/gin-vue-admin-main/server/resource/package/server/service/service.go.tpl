@@ -4,9 +4,12 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/{{.Package}}"
     {{.Package}}Req "github.com/flipped-aurora/gin-vue-admin/server/model/{{.Package}}/request"
-    {{- if .AutoCreateResource }}
+    {{- if or .AutoCreateResource .HasCascade .HasNestedUpdate }}
     "gorm.io/gorm"
     {{- end}}
+    {{- if .HasNestedUpdate }}
+    "gorm.io/gorm/clause"
+    {{- end}}
 )
 
 type {{.StructName}}Service struct {}
@@ -17,6 +20,19 @@ type {{.StructName}}Service struct {}
 {{- else}}
  {{- $db =  printf "global.MustGetGlobalDBByDBName(\"%s\")" .BusinessDB   }}
 {{- end}}
+{{- $preload := "" }}
+{{- range .Relations }}
+ {{- if .Relation.Preload }}
+  {{- $preload = printf "%s.Preload(\"%s\")" $preload .FieldName }}
+ {{- end }}
+{{- end}}
+{{- $cascade := "" }}
+{{- range .Relations }}
+ {{- if .Relation.Cascade }}
+  {{- if $cascade }}{{ $cascade = printf "%s, " $cascade }}{{ end }}
+  {{- $cascade = printf "%s\"%s\"" $cascade .FieldName }}
+ {{- end }}
+{{- end}}
 
 // Create{{.StructName}} 创建{{.Description}}记录
 // Author [piexlmax](https://github.com/piexlmax)
@@ -28,7 +44,21 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service) Create{{.StructName}}({{
 // Delete{{.StructName}} 删除{{.Description}}记录
 // Author [piexlmax](https://github.com/piexlmax)
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Delete{{.StructName}}({{.PrimaryField.FieldJson}} string{{- if .AutoCreateResource -}},userID uint{{- end -}}) (err error) {
-	{{- if .AutoCreateResource }}
+	{{- if .HasCascade }}
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    var {{.Abbreviation}} {{.Package}}.{{.StructName}}
+	    if err := tx.Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).First(&{{.Abbreviation}}).Error; err != nil {
+	        return err
+	    }
+	    {{- if .AutoCreateResource }}
+	    if err := tx.Model(&{{.Abbreviation}}).Update("deleted_by", userID).Error; err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    // 级联删除关联数据
+	    return tx.Select({{$cascade}}).Delete(&{{.Abbreviation}}).Error
+	})
+	{{- else if .AutoCreateResource }}
 	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
 	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).Update("deleted_by", userID).Error; err != nil {
               return err
@@ -47,7 +77,24 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Delete{{.StructName}}({{.
 // Delete{{.StructName}}ByIds 批量删除{{.Description}}记录
 // Author [piexlmax](https://github.com/piexlmax)
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Delete{{.StructName}}ByIds({{.PrimaryField.FieldJson}}s []string {{- if .AutoCreateResource }},deleted_by uint{{- end}}) (err error) {
-	{{- if .AutoCreateResource }}
+	{{- if .HasCascade }}
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    var {{.Abbreviation}}s []{{.Package}}.{{.StructName}}
+	    if err := tx.Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Find(&{{.Abbreviation}}s).Error; err != nil {
+	        return err
+	    }
+	    if len({{.Abbreviation}}s) == 0 {
+	        return nil
+	    }
+	    {{- if .AutoCreateResource }}
+	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Update("deleted_by", deleted_by).Error; err != nil {
+	        return err
+	    }
+	    {{- end }}
+	    // 级联删除关联数据
+	    return tx.Select({{$cascade}}).Delete(&{{.Abbreviation}}s).Error
+	})
+	{{- else if .AutoCreateResource }}
 	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
 	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} in ?", {{.PrimaryField.FieldJson}}s).Update("deleted_by", deleted_by).Error; err != nil {
             return err
@@ -66,14 +113,34 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Delete{{.StructName}}ById
 // Update{{.StructName}} 更新{{.Description}}记录
 // Author [piexlmax](https://github.com/piexlmax)
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Update{{.StructName}}({{.Abbreviation}} {{.Package}}.{{.StructName}}) (err error) {
+	{{- if .HasNestedUpdate }}
+	{{- $abbr := .Abbreviation }}
+	err = {{$db}}.Transaction(func(tx *gorm.DB) error {
+	    if err := tx.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Omit(clause.Associations).Updates(&{{.Abbreviation}}).Error; err != nil {
+	        return err
+	    }
+	    {{- range .Relations }}
+	    {{- if ne .Relation.Type "belongsTo" }}
+	    // 同步{{.FieldDesc}} 未传入时保持不变
+	    if {{$abbr}}.{{.FieldName}} != nil {
+	        if err := tx.Model(&{{$abbr}}).Association("{{.FieldName}}").Replace({{$abbr}}.{{.FieldName}}); err != nil {
+	            return err
+	        }
+	    }
+	    {{- end }}
+	    {{- end }}
+	    return nil
+	})
+	{{- else }}
 	err = {{$db}}.Model(&{{.Package}}.{{.StructName}}{}).Where("{{.PrimaryField.ColumnName}} = ?",{{.Abbreviation}}.{{.PrimaryField.FieldName}}).Updates(&{{.Abbreviation}}).Error
+	{{- end }}
 	return err
 }
 
 // Get{{.StructName}} 根据{{.PrimaryField.FieldJson}}获取{{.Description}}记录
 // Author [piexlmax](https://github.com/piexlmax)
 func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}({{.PrimaryField.FieldJson}} string) ({{.Abbreviation}} {{.Package}}.{{.StructName}}, err error) {
-	err = {{$db}}.Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).First(&{{.Abbreviation}}).Error
+	err = {{$db}}{{$preload}}.Where("{{.PrimaryField.ColumnName}} = ?", {{.PrimaryField.FieldJson}}).First(&{{.Abbreviation}}).Error
 	return
 }
 
@@ -93,7 +160,21 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}InfoLis
 {{- end }}
         {{- range .Fields}}
             {{- if .FieldSearchType}}
-                {{- if or (eq .FieldType "string") (eq .FieldType "enum") (eq .FieldType "picture") (eq .FieldType "video") (eq .FieldType "richtext") }}
+                {{- if .Relation }}
+    if info.{{.FieldName}} != "" {
+        // 按{{.FieldDesc}}的{{.Relation.SearchColumn}}搜索
+        {{- $value := printf "info.%s" .FieldName }}
+        {{- if eq .FieldSearchType "LIKE" }}{{ $value = printf "\"%%\"+info.%s+\"%%\"" .FieldName }}{{ end }}
+        {{- $related := printf "%s.Table(\"%s\").Select(\"%s\").Where(\"%s %s ?\", %s)" $db .Relation.Table .Relation.ReferenceColumn .Relation.SearchColumn .FieldSearchType $value }}
+        {{- if eq .Relation.Type "belongsTo" }}
+        db = db.Where("{{.Relation.ForeignColumn}} IN (?)", {{$related}})
+        {{- else if eq .Relation.Type "hasMany" }}
+        db = db.Where("{{$.PrimaryField.ColumnName}} IN (?)", {{$db}}.Table("{{.Relation.Table}}").Select("{{.Relation.ForeignColumn}}").Where("{{.Relation.SearchColumn}} {{.FieldSearchType}} ?", {{$value}}))
+        {{- else }}
+        db = db.Where("{{$.PrimaryField.ColumnName}} IN (?)", {{$db}}.Table("{{.Relation.JoinTable}}").Select("{{.Relation.JoinForeignColumn}}").Where("{{.Relation.JoinReferenceColumn}} IN (?)", {{$related}}))
+        {{- end }}
+    }
+                {{- else if or (eq .FieldType "string") (eq .FieldType "enum") (eq .FieldType "picture") (eq .FieldType "video") (eq .FieldType "richtext") }}
     if info.{{.FieldName}} != "" {
         db = db.Where("{{.ColumnName}} {{.FieldSearchType}} ?",{{if eq .FieldSearchType "LIKE"}}"%"+ {{ end }}info.{{.FieldName}}{{if eq .FieldSearchType "LIKE"}}+"%"{{ end }})
     }
@@ -132,6 +213,9 @@ func ({{.Abbreviation}}Service *{{.StructName}}Service)Get{{.StructName}}InfoLis
 	if limit != 0 {
        db = db.Limit(limit).Offset(offset)
     }
+    {{- if $preload }}
+    db = db{{$preload}}
+    {{- end }}
 	
 	err = db.Find(&{{.Abbreviation}}s).Error
 	return  {{.Abbreviation}}s, total, err
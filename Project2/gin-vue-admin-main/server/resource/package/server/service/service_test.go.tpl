@@ -0,0 +1,167 @@
+package {{.Package}}
+
+{{- $hasEnum := false }}
+{{- $str := "" }}
+{{- range .Fields }}
+ {{- if not .Relation }}
+  {{- if eq .FieldType "enum" }}{{ $hasEnum = true }}{{ end }}
+  {{- if and (eq .FieldType "string") (not .PrimaryKey) (not $str) }}{{ $str = .FieldName }}{{ end }}
+ {{- end }}
+{{- end }}
+{{- $models := printf "&%s.%s{}" .Package .StructName }}
+{{- range .Relations }}
+ {{- $models = printf "%s, &%s%s{}" $models (or (and (eq .Relation.Model .Relation.StructName) (printf "%s." $.Package)) "") .Relation.Model }}
+{{- end }}
+
+import (
+	"errors"
+	"fmt"
+	{{- if $hasEnum }}
+	"strings"
+	{{- end }}
+	"testing"
+	{{- if or .HasTimer .GvaModel }}
+	"time"
+	{{- end }}
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/{{.Package}}"
+	{{.Package}}Req "github.com/flipped-aurora/gin-vue-admin/server/model/{{.Package}}/request"
+	{{- range .RelationImports }}
+	"github.com/flipped-aurora/gin-vue-admin/server/model/{{.}}"
+	{{- end }}
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	{{- if .NeedJSON }}
+	"gorm.io/datatypes"
+	{{- end }}
+	"gorm.io/gorm"
+)
+
+// newServiceTest{{.StructName}} 生成第n条{{.Description}}测试数据
+func newServiceTest{{.StructName}}(n int) {{.Package}}.{{.StructName}} {
+	return {{.Package}}.{{.StructName}}{
+	{{- range .Fields }}
+		{{- if not .Relation }}{{ template "serviceTestValue" . }}{{ end }}
+	{{- end }}
+	}
+}
+
+// serviceTest{{.StructName}}ID 获取{{.Description}}的主键
+func serviceTest{{.StructName}}ID({{.Abbreviation}} {{.Package}}.{{.StructName}}) string {
+	{{- if .GvaModel }}
+	return fmt.Sprint({{.Abbreviation}}.ID)
+	{{- else if eq .PrimaryField.FieldType "string" "enum" "picture" "video" "richtext" }}
+	return fmt.Sprint({{.Abbreviation}}.{{.PrimaryField.FieldName}})
+	{{- else }}
+	return fmt.Sprint(*{{.Abbreviation}}.{{.PrimaryField.FieldName}})
+	{{- end }}
+}
+
+func Test{{.StructName}}Service(t *testing.T) {
+	testdb.Open(t, "{{.BusinessDB}}", {{$models}})
+	service := new({{.StructName}}Service)
+	page := request.PageInfo{Page: 1, PageSize: 10}
+
+	first, second := newServiceTest{{.StructName}}(1), newServiceTest{{.StructName}}(2)
+	if err := service.Create{{.StructName}}(&first); err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	if err := service.Create{{.StructName}}(&second); err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+
+	got, err := service.Get{{.StructName}}(serviceTest{{.StructName}}ID(first))
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if serviceTest{{.StructName}}ID(got) != serviceTest{{.StructName}}ID(first) {
+		t.Errorf("查询结果 = %s, want %s", serviceTest{{.StructName}}ID(got), serviceTest{{.StructName}}ID(first))
+	}
+
+	list, total, err := service.Get{{.StructName}}InfoList({{.Package}}Req.{{.StructName}}Search{PageInfo: page})
+	if err != nil || total != 2 || len(list) != 2 {
+		t.Fatalf("获取列表失败: total=%d len=%d err=%v", total, len(list), err)
+	}
+	list, total, err = service.Get{{.StructName}}InfoList({{.Package}}Req.{{.StructName}}Search{PageInfo: request.PageInfo{Page: 2, PageSize: 1}})
+	if err != nil || total != 2 || len(list) != 1 {
+		t.Fatalf("分页失败: total=%d len=%d err=%v", total, len(list), err)
+	}
+	{{- if .GvaModel }}
+
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	_, total, err = service.Get{{.StructName}}InfoList({{.Package}}Req.{{.StructName}}Search{PageInfo: page, StartCreatedAt: &start, EndCreatedAt: &end})
+	if err != nil || total != 2 {
+		t.Errorf("按创建时间搜索失败: total=%d err=%v", total, err)
+	}
+	{{- end }}
+	{{- range .Fields }}
+	{{- if and (not .Relation) (or (and (eq .FieldSearchType "LIKE") (eq .FieldType "string" "picture" "video" "richtext")) (and (eq .FieldSearchType "=") (eq .FieldType "string" "picture" "video" "richtext" "int" "uint" "float64" "bool"))) }}
+
+	_, total, err = service.Get{{$.StructName}}InfoList({{$.Package}}Req.{{$.StructName}}Search{PageInfo: page, {{.FieldName}}: first.{{.FieldName}}})
+	if err != nil || total != 1 {
+		t.Errorf("按{{.FieldDesc}}搜索失败: total=%d err=%v", total, err)
+	}
+	{{- end }}
+	{{- end }}
+	{{- range .Fields }}
+	{{- if and .Sort (not .Relation) (eq .FieldType "string" "picture" "video" "richtext" "int" "uint" "float64" "bool" "time.Time") }}
+
+	list, _, err = service.Get{{$.StructName}}InfoList({{$.Package}}Req.{{$.StructName}}Search{PageInfo: page, Sort: "{{.ColumnName}}", Order: "descending"})
+	if err != nil || len(list) != 2 || serviceTest{{$.StructName}}ID(list[0]) != serviceTest{{$.StructName}}ID(second) {
+		t.Errorf("按{{.FieldDesc}}排序失败: len=%d err=%v", len(list), err)
+	}
+	{{- end }}
+	{{- end }}
+	{{- if .HasDataSource }}
+
+	if _, err = service.Get{{.StructName}}DataSource(); err != nil {
+		t.Errorf("获取数据源失败: %v", err)
+	}
+	{{- end }}
+	{{- if $str }}
+
+	got.{{$str}} = "updated"
+	{{- end }}
+	if err = service.Update{{.StructName}}(got); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	{{- if $str }}
+	if got, err = service.Get{{.StructName}}(serviceTest{{.StructName}}ID(first)); err != nil || got.{{$str}} != "updated" {
+		t.Errorf("更新未生效: {{$str}}=%s err=%v", got.{{$str}}, err)
+	}
+	{{- end }}
+
+	if err = service.Delete{{.StructName}}(serviceTest{{.StructName}}ID(first){{ if .AutoCreateResource }}, 0{{ end }}); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if _, err = service.Get{{.StructName}}(serviceTest{{.StructName}}ID(first)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("删除后查询 err = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+	if err = service.Delete{{.StructName}}ByIds([]string{serviceTest{{.StructName}}ID(second)}{{ if .AutoCreateResource }}, 0{{ end }}); err != nil {
+		t.Fatalf("批量删除失败: %v", err)
+	}
+	if _, total, err = service.Get{{.StructName}}InfoList({{.Package}}Req.{{.StructName}}Search{PageInfo: page}); err != nil || total != 0 {
+		t.Errorf("批量删除后 total=%d err=%v", total, err)
+	}
+}
+{{- define "serviceTestValue" }}
+	{{- if eq .FieldType "string" "picture" "video" "richtext" }}
+		{{.FieldName}}: fmt.Sprintf("{{.FieldJson}}-%d", n),
+	{{- else if eq .FieldType "enum" }}
+		{{.FieldName}}: strings.Trim(strings.Split("{{.DataTypeLong}}", ",")[0], "' "),
+	{{- else if eq .FieldType "int" }}
+		{{.FieldName}}: testdb.Ptr(n),
+	{{- else if eq .FieldType "uint" }}
+		{{.FieldName}}: testdb.Ptr(uint(n)),
+	{{- else if eq .FieldType "float64" }}
+		{{.FieldName}}: testdb.Ptr(float64(n)),
+	{{- else if eq .FieldType "bool" }}
+		{{.FieldName}}: testdb.Ptr(n%2 == 0),
+	{{- else if eq .FieldType "time.Time" }}
+		{{.FieldName}}: testdb.Ptr(time.Unix(int64(1700000000+n), 0)),
+	{{- else if eq .FieldType "json" }}
+		{{.FieldName}}: datatypes.JSON(`{"n":1}`),
+	{{- else if eq .FieldType "file" "pictures" "array" }}
+		{{.FieldName}}: datatypes.JSON(`[]`),
+	{{- end }}
+{{- end }}
@@ -0,0 +1,42 @@
+import service from '{{index .Variables "request"}}'
+
+export interface {{.StructName}} {
+  {{- range .FrontFields}}
+  {{.FieldJson}}?: {{if or (eq .FieldType "int") (eq .FieldType "float64")}}number{{else if eq .FieldType "bool"}}boolean{{else}}string{{end}}
+  {{- end}}
+  {{- if .GvaModel}}
+  ID?: number
+  CreatedAt?: string
+  {{- end}}
+}
+
+export interface PageResult<T> {
+  list: T[]
+  total: number
+  page: number
+  pageSize: number
+}
+
+// 创建{{.Description}}
+export const create{{.StructName}} = (data: {{.StructName}}) =>
+  service({ url: '/{{.Abbreviation}}/create{{.StructName}}', method: 'post', data })
+
+// 删除{{.Description}}
+export const delete{{.StructName}} = (params: Record<string, unknown>) =>
+  service({ url: '/{{.Abbreviation}}/delete{{.StructName}}', method: 'delete', params })
+
+// 批量删除{{.Description}}
+export const delete{{.StructName}}ByIds = (params: Record<string, unknown>) =>
+  service({ url: '/{{.Abbreviation}}/delete{{.StructName}}ByIds', method: 'delete', params })
+
+// 更新{{.Description}}
+export const update{{.StructName}} = (data: {{.StructName}}) =>
+  service({ url: '/{{.Abbreviation}}/update{{.StructName}}', method: 'put', data })
+
+// 用id查询{{.Description}}
+export const find{{.StructName}} = (params: Record<string, unknown>) =>
+  service({ url: '/{{.Abbreviation}}/find{{.StructName}}', method: 'get', params })
+
+// 分页获取{{.Description}}列表
+export const get{{.StructName}}List = (params: Record<string, unknown>) =>
+  service({ url: '/{{.Abbreviation}}/get{{.StructName}}List', method: 'get', params })
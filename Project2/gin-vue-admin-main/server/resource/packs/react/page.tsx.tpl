@@ -0,0 +1,96 @@
+import { useEffect, useState } from 'react'
+import {
+  {{.StructName}},
+  create{{.StructName}},
+  delete{{.StructName}},
+  get{{.StructName}}List,
+  update{{.StructName}}
+} from '@/api/{{.Package}}/{{.PackageName}}'
+
+const pageSize = {{index .Variables "pageSize"}}
+{{- $primary := "ID"}}{{if not .GvaModel}}{{$primary = .PrimaryField.FieldJson}}{{end}}
+
+// {{.Description}}
+export default function {{.StructName}}Page() {
+  const [list, setList] = useState<{{.StructName}}[]>([])
+  const [total, setTotal] = useState(0)
+  const [page, setPage] = useState(1)
+  const [editing, setEditing] = useState<{{.StructName}} | null>(null)
+
+  const load = async (current = page) => {
+    const res = await get{{.StructName}}List({ page: current, pageSize })
+    if (res.code === 0) {
+      setList(res.data.list)
+      setTotal(res.data.total)
+      setPage(current)
+    }
+  }
+
+  useEffect(() => {
+    load(1)
+  }, [])
+
+  const save = async () => {
+    if (!editing) return
+    const res = editing.{{$primary}} ? await update{{.StructName}}(editing) : await create{{.StructName}}(editing)
+    if (res.code === 0) {
+      setEditing(null)
+      load()
+    }
+  }
+
+  const remove = async (row: {{.StructName}}) => {
+    if (!window.confirm('确定要删除吗?')) return
+    const res = await delete{{.StructName}}({ {{$primary}}: row.{{$primary}} })
+    if (res.code === 0) load()
+  }
+
+  return (
+    <div className="gva-table-box">
+      <button type="button" onClick={() => setEditing({})}>新增</button>
+      <table>
+        <thead>
+          <tr>
+            {{- range .FrontFields}}
+            <th>{{.FieldDesc}}</th>
+            {{- end}}
+            <th>操作</th>
+          </tr>
+        </thead>
+        <tbody>
+          {list.map((row) => (
+            <tr key={String(row.{{$primary}})}>
+              {{- range .FrontFields}}
+              <td>{String(row.{{.FieldJson}} ?? '')}</td>
+              {{- end}}
+              <td>
+                <button type="button" onClick={() => setEditing(row)}>编辑</button>
+                <button type="button" onClick={() => remove(row)}>删除</button>
+              </td>
+            </tr>
+          ))}
+        </tbody>
+      </table>
+      <div>
+        <button type="button" disabled={page <= 1} onClick={() => load(page - 1)}>上一页</button>
+        <span>{page} / {Math.max(1, Math.ceil(total / pageSize))}</span>
+        <button type="button" disabled={page * pageSize >= total} onClick={() => load(page + 1)}>下一页</button>
+      </div>
+      {editing && (
+        <form onSubmit={(e) => { e.preventDefault(); save() }}>
+          {{- range .FrontFields}}
+          <label>
+            {{.FieldDesc}}
+            <input
+              value={String(editing.{{.FieldJson}} ?? '')}
+              onChange={(e) => setEditing({ ...editing, {{.FieldJson}}: e.target.value as never })}
+            />
+          </label>
+          {{- end}}
+          <button type="submit">保存</button>
+          <button type="button" onClick={() => setEditing(null)}>取消</button>
+        </form>
+      )}
+    </div>
+  )
+}
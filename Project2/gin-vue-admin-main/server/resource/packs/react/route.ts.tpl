@@ -0,0 +1,9 @@
+import { lazy } from 'react'
+
+// {{.Description}} 路由 与自动创建的菜单 {{.Abbreviation}} 对应
+export default {
+  path: '{{.Abbreviation}}',
+  name: '{{.Abbreviation}}',
+  meta: { title: '{{.Description}}' },
+  component: lazy(() => import('@/view/{{.Package}}/{{.PackageName}}/index'))
+}
@@ -6,10 +6,12 @@ import (
 
 type RouterGroup struct {
 	CustomerRouter
+	OssMigrationRouter
 	FileUploadAndDownloadRouter
 }
 
 var (
 	exaCustomerApi              = api.ApiGroupApp.ExampleApiGroup.CustomerApi
+	exaOssMigrationApi          = api.ApiGroupApp.ExampleApiGroup.OssMigrationApi
 	exaFileUploadAndDownloadApi = api.ApiGroupApp.ExampleApiGroup.FileUploadAndDownloadApi
 )
@@ -6,7 +6,7 @@ import (
 
 type FileUploadAndDownloadRouter struct{}
 
-func (e *FileUploadAndDownloadRouter) InitFileUploadAndDownloadRouter(Router *gin.RouterGroup) {
+func (e *FileUploadAndDownloadRouter) InitFileUploadAndDownloadRouter(Router *gin.RouterGroup, PublicRouter *gin.RouterGroup) {
 	fileUploadAndDownloadRouter := Router.Group("fileUploadAndDownload")
 	{
 		fileUploadAndDownloadRouter.POST("upload", exaFileUploadAndDownloadApi.UploadFile)                                 // 上传文件
@@ -17,5 +17,30 @@ func (e *FileUploadAndDownloadRouter) InitFileUploadAndDownloadRouter(Router *gi
 		fileUploadAndDownloadRouter.GET("findFile", exaFileUploadAndDownloadApi.FindFile)                                  // 查询当前文件成功的切片
 		fileUploadAndDownloadRouter.POST("breakpointContinueFinish", exaFileUploadAndDownloadApi.BreakpointContinueFinish) // 切片传输完成
 		fileUploadAndDownloadRouter.POST("removeChunk", exaFileUploadAndDownloadApi.RemoveChunk)                           // 删除切片
+		fileUploadAndDownloadRouter.POST("searchFile", exaFileUploadAndDownloadApi.SearchFile)                             // 媒体库搜索文件
+		fileUploadAndDownloadRouter.POST("moveFiles", exaFileUploadAndDownloadApi.MoveFiles)                               // 批量移动文件到文件夹
+		fileUploadAndDownloadRouter.POST("createFolder", exaFileUploadAndDownloadApi.CreateFolder)                         // 创建文件夹
+		fileUploadAndDownloadRouter.POST("updateFolder", exaFileUploadAndDownloadApi.UpdateFolder)                         // 重命名或移动文件夹
+		fileUploadAndDownloadRouter.POST("deleteFolder", exaFileUploadAndDownloadApi.DeleteFolder)                         // 删除空文件夹
+		fileUploadAndDownloadRouter.GET("getFolderTree", exaFileUploadAndDownloadApi.GetFolderTree)                        // 获取文件夹树
+		fileUploadAndDownloadRouter.GET("getUsage", exaFileUploadAndDownloadApi.GetUsage)                                  // 获取存储用量与配额
+		fileUploadAndDownloadRouter.POST("setQuota", exaFileUploadAndDownloadApi.SetQuota)                                 // 设置存储配额
+		fileUploadAndDownloadRouter.POST("deleteQuota", exaFileUploadAndDownloadApi.DeleteQuota)                           // 删除存储配额
+		fileUploadAndDownloadRouter.GET("getQuotaList", exaFileUploadAndDownloadApi.GetQuotaList)                          // 获取存储配额列表
+		fileUploadAndDownloadRouter.POST("presignUpload", exaFileUploadAndDownloadApi.PresignUpload)                       // 申请直传地址
+		fileUploadAndDownloadRouter.POST("presignParts", exaFileUploadAndDownloadApi.PresignParts)                         // 获取分片直传地址
+		fileUploadAndDownloadRouter.POST("presignComplete", exaFileUploadAndDownloadApi.CompletePresign)                   // 直传完成回调
+		fileUploadAndDownloadRouter.POST("presignAbort", exaFileUploadAndDownloadApi.AbortPresign)                         // 取消直传
+		fileUploadAndDownloadRouter.POST("presignDownload", exaFileUploadAndDownloadApi.PresignDownload)                   // 获取签名下载地址
+		fileUploadAndDownloadRouter.POST("tus", exaFileUploadAndDownloadApi.TusCreate)                                     // tus 创建上传
+		fileUploadAndDownloadRouter.HEAD("tus/:id", exaFileUploadAndDownloadApi.TusHead)                                   // tus 查询上传进度
+		fileUploadAndDownloadRouter.PATCH("tus/:id", exaFileUploadAndDownloadApi.TusPatch)                                 // tus 追加上传数据
+		fileUploadAndDownloadRouter.DELETE("tus/:id", exaFileUploadAndDownloadApi.TusDelete)                               // tus 取消上传
+	}
+	fileUploadAndDownloadPublicRouter := PublicRouter.Group("fileUploadAndDownload")
+	{
+		fileUploadAndDownloadPublicRouter.PUT("localObject", exaFileUploadAndDownloadApi.PutLocalObject) // 本地存储签名直传
+		fileUploadAndDownloadPublicRouter.GET("localObject", exaFileUploadAndDownloadApi.GetLocalObject) // 本地存储签名下载
+		fileUploadAndDownloadPublicRouter.OPTIONS("tus", exaFileUploadAndDownloadApi.TusOptions)         // tus 服务能力查询
 	}
 }
@@ -0,0 +1,24 @@
+package example
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type OssMigrationRouter struct{}
+
+func (e *OssMigrationRouter) InitOssMigrationRouter(Router *gin.RouterGroup) {
+	ossMigrationRouter := Router.Group("ossMigration").Use(middleware.OperationRecord())
+	ossMigrationRouterWithoutRecord := Router.Group("ossMigration")
+	{
+		ossMigrationRouter.POST("createOssMigration", exaOssMigrationApi.CreateOssMigration)   // 创建存储迁移任务
+		ossMigrationRouter.POST("resumeOssMigration", exaOssMigrationApi.ResumeOssMigration)   // 继续存储迁移任务
+		ossMigrationRouter.POST("stopOssMigration", exaOssMigrationApi.StopOssMigration)       // 停止存储迁移任务
+		ossMigrationRouter.POST("applyOssMigration", exaOssMigrationApi.ApplyOssMigration)     // 应用存储迁移
+		ossMigrationRouter.DELETE("deleteOssMigration", exaOssMigrationApi.DeleteOssMigration) // 删除存储迁移任务
+	}
+	{
+		ossMigrationRouterWithoutRecord.GET("getOssMigrationList", exaOssMigrationApi.GetOssMigrationList)         // 获取存储迁移任务列表
+		ossMigrationRouterWithoutRecord.GET("getOssMigrationItemList", exaOssMigrationApi.GetOssMigrationItemList) // 获取迁移对象列表
+	}
+}
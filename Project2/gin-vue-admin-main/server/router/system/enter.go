@@ -18,6 +18,12 @@ type RouterGroup struct {
 	DictionaryDetailRouter
 	AuthorityBtnRouter
 	SysExportTemplateRouter
+	SysExportScheduleRouter
+	SysJobRouter
+	SysRetentionRouter
+	OpenApiRouter
+	PluginLifecycleRouter
+	DBConnectionRouter
 }
 
 var (
@@ -38,5 +44,13 @@ var (
 	autoCodePackageApi  = api.ApiGroupApp.SystemApiGroup.AutoCodePackageApi
 	dictionaryDetailApi = api.ApiGroupApp.SystemApiGroup.DictionaryDetailApi
 	autoCodeTemplateApi = api.ApiGroupApp.SystemApiGroup.AutoCodeTemplateApi
+	autoCodePackApi     = api.ApiGroupApp.SystemApiGroup.AutoCodeTemplatePackApi
+	autoCodeImportApi   = api.ApiGroupApp.SystemApiGroup.AutoCodeImportApi
 	exportTemplateApi   = api.ApiGroupApp.SystemApiGroup.SysExportTemplateApi
+	exportScheduleApi   = api.ApiGroupApp.SystemApiGroup.SysExportScheduleApi
+	sysJobApi           = api.ApiGroupApp.SystemApiGroup.SysJobApi
+	sysRetentionApi     = api.ApiGroupApp.SystemApiGroup.SysRetentionApi
+	openApiApi          = api.ApiGroupApp.SystemApiGroup.OpenApiApi
+	pluginLifecycleApi  = api.ApiGroupApp.SystemApiGroup.PluginLifecycleApi
+	dbConnectionApi     = api.ApiGroupApp.SystemApiGroup.DBConnectionApi
 )
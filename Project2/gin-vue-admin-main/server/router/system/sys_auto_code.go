@@ -15,9 +15,15 @@ func (s *AutoCodeRouter) InitAutoCodeRouter(Router *gin.RouterGroup, RouterPubli
 		autoCodeRouter.GET("getColumn", autoCodeApi.GetColumn) // 获取指定表所有字段信息
 	}
 	{
-		autoCodeRouter.POST("preview", autoCodeTemplateApi.Preview)   // 获取自动创建代码预览
-		autoCodeRouter.POST("createTemp", autoCodeTemplateApi.Create) // 创建自动化代码
-		autoCodeRouter.POST("addFunc", autoCodeTemplateApi.AddFunc)   // 为代码插入方法
+		autoCodeRouter.POST("inspectTables", autoCodeImportApi.Inspect) // 检查数据库 推断批量导入的结构化信息
+		autoCodeRouter.POST("importTables", autoCodeImportApi.Import)   // 批量导入数据库表生成代码
+	}
+	{
+		autoCodeRouter.POST("preview", autoCodeTemplateApi.Preview)       // 获取自动创建代码预览
+		autoCodeRouter.POST("dryRun", autoCodeTemplateApi.DryRun)         // 试运行代码生成 返回文件diff与冲突
+		autoCodeRouter.POST("createTemp", autoCodeTemplateApi.Create)     // 创建自动化代码
+		autoCodeRouter.POST("addFunc", autoCodeTemplateApi.AddFunc)       // 为代码插入方法
+		autoCodeRouter.POST("regenerate", autoCodeTemplateApi.Regenerate) // 结构变更后重新生成代码
 	}
 	{
 		autoCodeRouter.POST("getPackage", autoCodePackageApi.All)       // 获取package包
@@ -25,11 +31,20 @@ func (s *AutoCodeRouter) InitAutoCodeRouter(Router *gin.RouterGroup, RouterPubli
 		autoCodeRouter.POST("createPackage", autoCodePackageApi.Create) // 创建package包
 	}
 	{
-		autoCodeRouter.GET("getTemplates", autoCodePackageApi.Templates) // 创建package包
+		autoCodeRouter.GET("getTemplates", autoCodePackageApi.Templates)  // 创建package包
+		autoCodeRouter.POST("setPackagePack", autoCodePackageApi.SetPack) // 设置包的默认模板包
+	}
+	{
+		autoCodeRouter.GET("getPacks", autoCodePackApi.List)        // 获取已安装的模板包
+		autoCodeRouter.POST("installPack", autoCodePackApi.Install) // 安装模板包
+		autoCodeRouter.POST("delPack", autoCodePackApi.Delete)      // 删除模板包
+		autoCodeRouter.POST("getPackFiles", autoCodePackApi.Files)  // 预览模板包将生成的文件
 	}
 	{
-		autoCodeRouter.POST("pubPlug", autoCodePluginApi.Packaged)      // 打包插件
-		autoCodeRouter.POST("installPlugin", autoCodePluginApi.Install) // 自动安装插件
+		autoCodeRouter.POST("pubPlug", autoCodePluginApi.Packaged)                // 打包插件
+		autoCodeRouter.POST("installPlugin", autoCodePluginApi.Install)           // 自动安装插件
+		autoCodeRouter.POST("uninstallPlugin", autoCodePluginApi.Uninstall)       // 卸载插件
+		autoCodeRouter.GET("getInstalledPlugins", autoCodePluginApi.GetInstalled) // 获取已安装插件
 	}
 	{
 		publicAutoCodeRouter.POST("llmAuto", autoCodeApi.LLMAuto)
@@ -9,9 +9,12 @@ type AutoCodeHistoryRouter struct{}
 func (s *AutoCodeRouter) InitAutoCodeHistoryRouter(Router *gin.RouterGroup) {
 	autoCodeHistoryRouter := Router.Group("autoCode")
 	{
-		autoCodeHistoryRouter.POST("getMeta", autocodeHistoryApi.First)         // 根据id获取meta信息
-		autoCodeHistoryRouter.POST("rollback", autocodeHistoryApi.RollBack)     // 回滚
-		autoCodeHistoryRouter.POST("delSysHistory", autocodeHistoryApi.Delete)  // 删除回滚记录
-		autoCodeHistoryRouter.POST("getSysHistory", autocodeHistoryApi.GetList) // 获取回滚记录分页
+		autoCodeHistoryRouter.POST("getMeta", autocodeHistoryApi.First)                     // 根据id获取meta信息
+		autoCodeHistoryRouter.POST("rollback", autocodeHistoryApi.RollBack)                 // 回滚
+		autoCodeHistoryRouter.POST("delSysHistory", autocodeHistoryApi.Delete)              // 删除回滚记录
+		autoCodeHistoryRouter.POST("getSysHistory", autocodeHistoryApi.GetList)             // 获取回滚记录分页
+		autoCodeHistoryRouter.POST("getMigrationList", autocodeHistoryApi.GetMigrationList) // 获取数据库迁移分页
+		autoCodeHistoryRouter.POST("applyMigration", autocodeHistoryApi.ApplyMigration)     // 执行数据库迁移
+		autoCodeHistoryRouter.POST("revertMigration", autocodeHistoryApi.RevertMigration)   // 回退数据库迁移
 	}
 }
@@ -0,0 +1,24 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type DBConnectionRouter struct{}
+
+// InitDBConnectionRouter 初始化 业务库 路由信息
+func (s *DBConnectionRouter) InitDBConnectionRouter(Router *gin.RouterGroup) {
+	dbConnectionRouter := Router.Group("dbConnection").Use(middleware.OperationRecord())
+	dbConnectionRouterWithoutRecord := Router.Group("dbConnection")
+	{
+		dbConnectionRouter.POST("createDBConnection", dbConnectionApi.CreateDBConnection)   // 新增业务库
+		dbConnectionRouter.PUT("updateDBConnection", dbConnectionApi.UpdateDBConnection)    // 更新业务库
+		dbConnectionRouter.DELETE("deleteDBConnection", dbConnectionApi.DeleteDBConnection) // 删除业务库
+		dbConnectionRouter.POST("testDBConnection", dbConnectionApi.TestDBConnection)       // 测试业务库连接
+	}
+	{
+		dbConnectionRouterWithoutRecord.GET("findDBConnection", dbConnectionApi.FindDBConnection)       // 根据ID获取业务库
+		dbConnectionRouterWithoutRecord.GET("getDBConnectionList", dbConnectionApi.GetDBConnectionList) // 获取业务库列表
+	}
+}
@@ -0,0 +1,27 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type SysExportScheduleRouter struct{}
+
+// InitSysExportScheduleRouter 初始化 定时报表 路由信息
+func (s *SysExportScheduleRouter) InitSysExportScheduleRouter(Router *gin.RouterGroup) {
+	sysExportScheduleRouter := Router.Group("sysExportSchedule").Use(middleware.OperationRecord())
+	sysExportScheduleRouterWithoutRecord := Router.Group("sysExportSchedule")
+	{
+		sysExportScheduleRouter.POST("createSysExportSchedule", exportScheduleApi.CreateSysExportSchedule)             // 新建定时报表
+		sysExportScheduleRouter.DELETE("deleteSysExportSchedule", exportScheduleApi.DeleteSysExportSchedule)           // 删除定时报表
+		sysExportScheduleRouter.DELETE("deleteSysExportScheduleByIds", exportScheduleApi.DeleteSysExportScheduleByIds) // 批量删除定时报表
+		sysExportScheduleRouter.PUT("updateSysExportSchedule", exportScheduleApi.UpdateSysExportSchedule)              // 更新定时报表
+		sysExportScheduleRouter.POST("runSysExportSchedule", exportScheduleApi.RunSysExportSchedule)                   // 立即执行定时报表
+	}
+	{
+		sysExportScheduleRouterWithoutRecord.GET("findSysExportSchedule", exportScheduleApi.FindSysExportSchedule)               // 根据ID获取定时报表
+		sysExportScheduleRouterWithoutRecord.GET("getSysExportScheduleList", exportScheduleApi.GetSysExportScheduleList)         // 获取定时报表列表
+		sysExportScheduleRouterWithoutRecord.GET("getSysExportScheduleRunList", exportScheduleApi.GetSysExportScheduleRunList)   // 获取定时报表执行记录
+		sysExportScheduleRouterWithoutRecord.GET("downloadSysExportScheduleRun", exportScheduleApi.DownloadSysExportScheduleRun) // 下载执行记录产出文件
+	}
+}
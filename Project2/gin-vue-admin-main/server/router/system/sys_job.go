@@ -0,0 +1,27 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type SysJobRouter struct{}
+
+// InitSysJobRouter 初始化 定时任务 路由信息
+func (s *SysJobRouter) InitSysJobRouter(Router *gin.RouterGroup) {
+	sysJobRouter := Router.Group("sysJob").Use(middleware.OperationRecord())
+	sysJobRouterWithoutRecord := Router.Group("sysJob")
+	{
+		sysJobRouter.POST("createSysJob", sysJobApi.CreateSysJob)             // 新建定时任务
+		sysJobRouter.DELETE("deleteSysJob", sysJobApi.DeleteSysJob)           // 删除定时任务
+		sysJobRouter.DELETE("deleteSysJobByIds", sysJobApi.DeleteSysJobByIds) // 批量删除定时任务
+		sysJobRouter.PUT("updateSysJob", sysJobApi.UpdateSysJob)              // 更新定时任务
+		sysJobRouter.POST("runSysJob", sysJobApi.RunSysJob)                   // 立即执行定时任务
+	}
+	{
+		sysJobRouterWithoutRecord.GET("findSysJob", sysJobApi.FindSysJob)               // 根据ID获取定时任务
+		sysJobRouterWithoutRecord.GET("getSysJobList", sysJobApi.GetSysJobList)         // 获取定时任务列表
+		sysJobRouterWithoutRecord.GET("getSysJobRunList", sysJobApi.GetSysJobRunList)   // 获取定时任务执行记录
+		sysJobRouterWithoutRecord.GET("getSysJobHandlers", sysJobApi.GetSysJobHandlers) // 获取可用的处理函数
+	}
+}
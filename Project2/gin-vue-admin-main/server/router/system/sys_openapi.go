@@ -0,0 +1,15 @@
+package system
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type OpenApiRouter struct{}
+
+// InitOpenApiRouter 初始化 OpenAPI文档 路由信息
+func (s *OpenApiRouter) InitOpenApiRouter(Router *gin.RouterGroup) {
+	openApiRouterWithoutRecord := Router.Group("openapi")
+	{
+		openApiRouterWithoutRecord.GET("getSpec", openApiApi.GetOpenApiSpec) // 获取OpenAPI文档
+	}
+}
@@ -0,0 +1,21 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type PluginLifecycleRouter struct{}
+
+// InitPluginLifecycleRouter 初始化 插件运行状态 路由信息
+func (s *PluginLifecycleRouter) InitPluginLifecycleRouter(Router *gin.RouterGroup) {
+	pluginRouter := Router.Group("plugin").Use(middleware.OperationRecord())
+	pluginRouterWithoutRecord := Router.Group("plugin")
+	{
+		pluginRouter.POST("setPluginEnabled", pluginLifecycleApi.SetPluginEnabled) // 启用或停用插件
+		pluginRouter.PUT("setPluginConfig", pluginLifecycleApi.SetPluginConfig)    // 修改插件配置
+	}
+	{
+		pluginRouterWithoutRecord.GET("getPluginStatus", pluginLifecycleApi.GetPluginStatus) // 获取插件运行状态
+	}
+}
@@ -0,0 +1,26 @@
+package system
+
+import (
+	"github.com/flipped-aurora/gin-vue-admin/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type SysRetentionRouter struct{}
+
+// InitSysRetentionRouter 初始化 数据保留策略 路由信息
+func (s *SysRetentionRouter) InitSysRetentionRouter(Router *gin.RouterGroup) {
+	sysRetentionRouter := Router.Group("sysRetention").Use(middleware.OperationRecord())
+	sysRetentionRouterWithoutRecord := Router.Group("sysRetention")
+	{
+		sysRetentionRouter.POST("createSysRetentionPolicy", sysRetentionApi.CreateSysRetentionPolicy)             // 新建数据保留策略
+		sysRetentionRouter.DELETE("deleteSysRetentionPolicy", sysRetentionApi.DeleteSysRetentionPolicy)           // 删除数据保留策略
+		sysRetentionRouter.DELETE("deleteSysRetentionPolicyByIds", sysRetentionApi.DeleteSysRetentionPolicyByIds) // 批量删除数据保留策略
+		sysRetentionRouter.PUT("updateSysRetentionPolicy", sysRetentionApi.UpdateSysRetentionPolicy)              // 更新数据保留策略
+		sysRetentionRouter.POST("runSysRetentionPolicy", sysRetentionApi.RunSysRetentionPolicy)                   // 执行数据保留策略
+	}
+	{
+		sysRetentionRouterWithoutRecord.GET("findSysRetentionPolicy", sysRetentionApi.FindSysRetentionPolicy)       // 根据ID获取数据保留策略
+		sysRetentionRouterWithoutRecord.GET("getSysRetentionPolicyList", sysRetentionApi.GetSysRetentionPolicyList) // 获取数据保留策略列表
+		sysRetentionRouterWithoutRecord.GET("getSysRetentionRunList", sysRetentionApi.GetSysRetentionRunList)       // 获取数据保留执行报告
+	}
+}
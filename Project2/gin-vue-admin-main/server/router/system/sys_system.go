@@ -18,5 +18,6 @@ func (s *SysRouter) InitSystemRouter(Router *gin.RouterGroup) {
 	{
 		sysRouterWithoutRecord.POST("getSystemConfig", systemApi.GetSystemConfig) // 获取配置文件内容
 		sysRouterWithoutRecord.POST("getServerInfo", systemApi.GetServerInfo)     // 获取服务器信息
+		sysRouterWithoutRecord.GET("getMetrics", systemApi.GetMetrics)            // 获取运行指标
 	}
 }
@@ -2,5 +2,6 @@ package example
 
 type ServiceGroup struct {
 	CustomerService
+	OssMigrationService
 	FileUploadAndDownloadService
 }
@@ -1,13 +1,23 @@
 package example
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"regexp"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+var md5Pattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
 type FileUploadAndDownloadService struct{}
 
 var FileUploadAndDownloadServiceApp = new(FileUploadAndDownloadService)
@@ -49,23 +59,135 @@ func (e *FileUploadAndDownloadService) CreateFileChunk(id uint, fileChunkPath st
 	return err
 }
 
-//@author: [piexlmax](https://github.com/piexlmax)
-//@function: DeleteFileChunk
-//@description: 删除文件切片记录
-//@param: fileMd5 string, fileName string, filePath string
+//@function: SaveBreakpointChunk
+//@description: 保存断点续传切片 切片写入分片存储 重传的切片覆盖旧切片
+//@param: fileMd5 string, fileName string, chunkNumber int, chunkTotal int, content []byte
 //@return: error
 
-func (e *FileUploadAndDownloadService) DeleteFileChunk(fileMd5 string, filePath string) error {
-	var chunks []example.ExaFileChunk
+func (e *FileUploadAndDownloadService) SaveBreakpointChunk(fileMd5 string, fileName string, chunkNumber int, chunkTotal int, content []byte) error {
+	if !md5Pattern.MatchString(fileMd5) {
+		return errors.New("fileMd5不合法")
+	}
+	if chunkTotal <= 0 || chunkNumber < 0 || chunkNumber >= chunkTotal {
+		return errors.New("切片序号不合法")
+	}
+	// 切片并发上传 查找或创建文件记录需要串行 避免重复创建
+	unlock := lockUpload("bp_" + fileMd5)
+	file, err := e.FindOrCreateFile(fileMd5, fileName, chunkTotal)
+	unlock()
+	if err != nil {
+		return err
+	}
+	if file.IsFinish {
+		return nil
+	}
+	store := upload.NewChunkStore()
+	key, _, err := store.PutChunk(fmt.Sprintf("bp_%d_%d", file.ID, chunkNumber), bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	var old []example.ExaFileChunk
+	global.GVA_DB.Where("exa_file_id = ? AND file_chunk_number = ?", file.ID, chunkNumber).Find(&old)
+	for _, chunk := range old {
+		if chunk.FileChunkPath != key {
+			_ = store.DeleteChunk(chunk.FileChunkPath)
+		}
+		global.GVA_DB.Unscoped().Delete(&chunk)
+	}
+	return e.CreateFileChunk(file.ID, key, chunkNumber)
+}
+
+//@function: FinishBreakpoint
+//@description: 合并断点续传切片 校验整体MD5后通过媒体库流程写入对象存储
+//@param: fileMd5 string, fileName string, userID uint, authorityID uint
+//@return: filePath string, err error
+
+func (e *FileUploadAndDownloadService) FinishBreakpoint(fileMd5 string, fileName string, userID uint, authorityID uint) (filePath string, err error) {
+	unlock := lockUpload("bp_" + fileMd5)
+	defer unlock()
 	var file example.ExaFile
-	err := global.GVA_DB.Where("file_md5 = ? ", fileMd5).First(&file).
-		Updates(map[string]interface{}{
-			"IsFinish":  true,
-			"file_path": filePath,
-		}).Error
+	err = global.GVA_DB.Where("file_md5 = ? AND file_name = ?", fileMd5, fileName).Preload("ExaFileChunk").First(&file).Error
+	if err != nil {
+		return "", errors.New("文件不存在")
+	}
+	if file.IsFinish {
+		return file.FilePath, nil
+	}
+	keys := make(map[int]string, len(file.ExaFileChunk))
+	for _, chunk := range file.ExaFileChunk {
+		keys[chunk.FileChunkNumber] = chunk.FileChunkPath
+	}
+	chunkKeys := make([]string, file.ChunkTotal)
+	for i := range chunkKeys {
+		key, ok := keys[i]
+		if !ok {
+			return "", fmt.Errorf("切片 %d 尚未上传", i)
+		}
+		chunkKeys[i] = key
+	}
+	store := upload.NewChunkStore()
+	sum := md5.New()
+	header, remove, err := upload.StreamFileHeader(fileName, mergeMemory, func(w io.Writer) error {
+		for _, key := range chunkKeys {
+			if _, err := copyChunk(store, key, w, sum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer remove()
+	if hex.EncodeToString(sum.Sum(nil)) != fileMd5 {
+		return "", errors.New("文件MD5校验失败, 请重新上传")
+	}
+	f, err := e.UploadFile(header, "0", 0, userID, authorityID)
+	if err != nil {
+		return "", err
+	}
+	err = global.GVA_DB.Model(&file).Updates(map[string]interface{}{"is_finish": true, "file_path": f.Url}).Error
 	if err != nil {
+		return "", err
+	}
+	e.removeBreakpointChunks(file.ID)
+	return f.Url, nil
+}
+
+//@function: RemoveBreakpointChunks
+//@description: 删除断点续传切片 未完成的上传同时删除文件记录
+//@param: fileMd5 string
+//@return: error
+
+func (e *FileUploadAndDownloadService) RemoveBreakpointChunks(fileMd5 string) error {
+	var files []example.ExaFile
+	if err := global.GVA_DB.Where("file_md5 = ?", fileMd5).Find(&files).Error; err != nil {
 		return err
 	}
-	err = global.GVA_DB.Where("exa_file_id = ?", file.ID).Delete(&chunks).Unscoped().Error
-	return err
+	for _, f := range files {
+		if err := e.removeBreakpointFile(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *FileUploadAndDownloadService) removeBreakpointFile(file example.ExaFile) error {
+	e.removeBreakpointChunks(file.ID)
+	if file.IsFinish {
+		return nil
+	}
+	return global.GVA_DB.Delete(&file).Error
+}
+
+func (e *FileUploadAndDownloadService) removeBreakpointChunks(fileID uint) {
+	var chunks []example.ExaFileChunk
+	global.GVA_DB.Where("exa_file_id = ?", fileID).Find(&chunks)
+	store := upload.NewChunkStore()
+	for _, chunk := range chunks {
+		if err := store.DeleteChunk(chunk.FileChunkPath); err != nil {
+			global.GVA_LOG.Warn("删除切片失败", zap.String("key", chunk.FileChunkPath), zap.Error(err))
+		}
+	}
+	global.GVA_DB.Unscoped().Where("exa_file_id = ?", fileID).Delete(&example.ExaFileChunk{})
 }
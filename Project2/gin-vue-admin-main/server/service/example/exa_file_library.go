@@ -0,0 +1,402 @@
+package example
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	exampleReq "github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	exampleRes "github.com/flipped-aurora/gin-vue-admin/server/model/example/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/media"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const mb = 1024 * 1024
+
+var (
+	// uploadLock 串行化配额校验与配额占用(或入库) 网络上传不在锁内进行
+	uploadLock sync.Mutex
+	// uploadReserved 已通过配额校验但尚未入库的上传占用的空间 键为 user:ID 或 role:ID 由 uploadLock 保护
+	uploadReserved = map[string]int64{}
+)
+
+// uploadToLibrary 上传文件到媒体库: 嗅探类型 -> 去除EXIF(仅图片读入内存) -> 计算哈希 -> 占用配额 -> 去重或上传 -> 生成缩略图
+// 成功时返回的 release 需在记录入库后调用 释放占用的配额
+func (e *FileUploadAndDownloadService) uploadToLibrary(header *multipart.FileHeader, folderID, userID, authorityID uint) (file example.ExaFileUploadAndDownload, release func(), err error) {
+	cfg := global.GVA_CONFIG.FileLibrary
+	if cfg.MaxSize > 0 && header.Size > cfg.MaxSize*mb {
+		return file, nil, fmt.Errorf("文件大小超过限制 %dMB", cfg.MaxSize)
+	}
+	if folderID != 0 {
+		if err = global.GVA_DB.First(&example.ExaFileFolder{}, folderID).Error; err != nil {
+			return file, nil, errors.New("文件夹不存在")
+		}
+	}
+	src, err := header.Open()
+	if err != nil {
+		return file, nil, err
+	}
+	defer src.Close()
+	head := make([]byte, media.SniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return file, nil, err
+	}
+	head = head[:n]
+
+	mimeType := media.Sniff(head)
+	if err = media.CheckMime(mimeType, cfg.AllowMime, cfg.DenyMime); err != nil {
+		return file, nil, err
+	}
+	// 图片需要去除EXIF与生成缩略图 读入内存处理 其他文件流式计算哈希后上传原文件
+	var content []byte
+	size := header.Size
+	sum := sha256.New()
+	if media.IsImage(mimeType) {
+		rest, rErr := io.ReadAll(src)
+		if rErr != nil {
+			return file, nil, rErr
+		}
+		content = append(head, rest...)
+		if cfg.StripExif && mimeType == "image/jpeg" {
+			if stripped, sErr := media.StripJpegMetadata(content); sErr == nil {
+				content = stripped
+			} else {
+				global.GVA_LOG.Warn("去除EXIF失败, 保留原文件", zap.String("name", header.Filename), zap.Error(sErr))
+			}
+		}
+		sum.Write(content)
+		size = int64(len(content))
+	} else if _, err = io.Copy(sum, io.MultiReader(bytes.NewReader(head), src)); err != nil {
+		return file, nil, err
+	}
+	hash := hex.EncodeToString(sum.Sum(nil))
+
+	ext := strings.TrimPrefix(filepath.Ext(header.Filename), ".")
+	file = example.ExaFileUploadAndDownload{
+		Name:        header.Filename,
+		Tag:         ext,
+		FolderID:    folderID,
+		Hash:        hash,
+		Size:        size,
+		MimeType:    mimeType,
+		UserID:      userID,
+		AuthorityID: authorityID,
+	}
+
+	// 先占用配额再上传 并发上传在入库前也会计入用量
+	reserved, err := e.reserveQuota(userID, authorityID, file.Size)
+	if err != nil {
+		return file, nil, err
+	}
+	defer func() {
+		if err != nil {
+			reserved()
+		}
+	}()
+
+	// 内容相同的文件直接复用已存储的对象
+	var exist example.ExaFileUploadAndDownload
+	err = global.GVA_DB.Where("hash = ?", hash).Not(map[string]interface{}{"key": ""}).First(&exist).Error
+	if err == nil {
+		file.Url, file.Key = exist.Url, exist.Key
+		file.ThumbUrl, file.ThumbKey = exist.ThumbUrl, exist.ThumbKey
+		file.Width, file.Height = exist.Width, exist.Height
+		return file, reserved, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return file, nil, err
+	}
+
+	oss := upload.NewOss()
+	fh := header
+	if content != nil {
+		if fh, err = upload.NewFileHeader(header.Filename, content); err != nil {
+			return file, nil, err
+		}
+	}
+	file.Url, file.Key, err = oss.UploadFile(fh)
+	if err != nil {
+		return file, nil, err
+	}
+	if content != nil {
+		file.Width, file.Height, _ = media.Dimensions(content)
+		if cfg.ThumbWidth > 0 || cfg.ThumbHeight > 0 {
+			file.ThumbUrl, file.ThumbKey = e.uploadThumbnail(oss, header.Filename, content)
+		}
+	}
+	return file, reserved, nil
+}
+
+// uploadThumbnail 生成并上传缩略图 失败不影响原文件上传
+func (e *FileUploadAndDownloadService) uploadThumbnail(oss upload.OSS, filename string, content []byte) (url string, key string) {
+	cfg := global.GVA_CONFIG.FileLibrary
+	thumb, ext, err := media.Thumbnail(content, cfg.ThumbWidth, cfg.ThumbHeight)
+	if err != nil {
+		global.GVA_LOG.Warn("生成缩略图失败", zap.String("name", filename), zap.Error(err))
+		return "", ""
+	}
+	name := "thumb_" + strings.TrimSuffix(filename, filepath.Ext(filename)) + ext
+	fh, err := upload.NewFileHeader(name, thumb)
+	if err != nil {
+		return "", ""
+	}
+	url, key, err = oss.UploadFile(fh)
+	if err != nil {
+		global.GVA_LOG.Warn("上传缩略图失败", zap.String("name", filename), zap.Error(err))
+		return "", ""
+	}
+	return url, key
+}
+
+// checkQuota 校验用户与角色配额
+func (e *FileUploadAndDownloadService) checkQuota(userID, authorityID uint, size int64) error {
+	uploadLock.Lock()
+	defer uploadLock.Unlock()
+	return e.checkQuotaLocked(userID, authorityID, size)
+}
+
+// reserveQuota 校验配额并占用 size 直到调用返回的 release
+func (e *FileUploadAndDownloadService) reserveQuota(userID, authorityID uint, size int64) (release func(), err error) {
+	uploadLock.Lock()
+	defer uploadLock.Unlock()
+	if err = e.checkQuotaLocked(userID, authorityID, size); err != nil {
+		return nil, err
+	}
+	userKey, roleKey := quotaKey(example.FileQuotaUser, userID), quotaKey(example.FileQuotaRole, authorityID)
+	uploadReserved[userKey] += size
+	uploadReserved[roleKey] += size
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			uploadLock.Lock()
+			defer uploadLock.Unlock()
+			for _, key := range []string{userKey, roleKey} {
+				if uploadReserved[key] -= size; uploadReserved[key] <= 0 {
+					delete(uploadReserved, key)
+				}
+			}
+		})
+	}, nil
+}
+
+func quotaKey(targetType string, targetID uint) string {
+	return targetType + ":" + strconv.Itoa(int(targetID))
+}
+
+// checkQuotaLocked 校验配额 已占用未入库的空间计入用量 调用方需持有 uploadLock
+func (e *FileUploadAndDownloadService) checkQuotaLocked(userID, authorityID uint, size int64) error {
+	usage, err := e.GetUsage(userID, authorityID)
+	if err != nil {
+		return err
+	}
+	usage.UserUsage += uploadReserved[quotaKey(example.FileQuotaUser, userID)]
+	usage.RoleUsage += uploadReserved[quotaKey(example.FileQuotaRole, authorityID)]
+	if usage.UserQuota > 0 && usage.UserUsage+size > usage.UserQuota {
+		return fmt.Errorf("超出用户存储配额, 已用 %dMB / %dMB", usage.UserUsage/mb, usage.UserQuota/mb)
+	}
+	if usage.RoleQuota > 0 && usage.RoleUsage+size > usage.RoleQuota {
+		return fmt.Errorf("超出角色存储配额, 已用 %dMB / %dMB", usage.RoleUsage/mb, usage.RoleQuota/mb)
+	}
+	return nil
+}
+
+// GetUsage 获取用户与其角色的存储用量和配额
+func (e *FileUploadAndDownloadService) GetUsage(userID, authorityID uint) (usage exampleRes.ExaFileUsage, err error) {
+	db := global.GVA_DB.Model(&example.ExaFileUploadAndDownload{}).Select("COALESCE(SUM(size), 0)")
+	if err = db.Session(&gorm.Session{}).Where("user_id = ?", userID).Scan(&usage.UserUsage).Error; err != nil {
+		return
+	}
+	if err = db.Session(&gorm.Session{}).Where("authority_id = ?", authorityID).Scan(&usage.RoleUsage).Error; err != nil {
+		return
+	}
+	usage.UserQuota = e.quota(example.FileQuotaUser, userID, global.GVA_CONFIG.FileLibrary.UserQuota) * mb
+	usage.RoleQuota = e.quota(example.FileQuotaRole, authorityID, global.GVA_CONFIG.FileLibrary.RoleQuota[strconv.Itoa(int(authorityID))]) * mb
+	return
+}
+
+func (e *FileUploadAndDownloadService) quota(targetType string, targetID uint, fallback int64) int64 {
+	var q example.ExaFileQuota
+	err := global.GVA_DB.Where("target_type = ? AND target_id = ?", targetType, targetID).First(&q).Error
+	if err != nil {
+		return fallback
+	}
+	return q.Quota
+}
+
+// SetQuota 设置用户或角色配额 已存在则覆盖
+func (e *FileUploadAndDownloadService) SetQuota(q example.ExaFileQuota) error {
+	if q.TargetType != example.FileQuotaUser && q.TargetType != example.FileQuotaRole {
+		return errors.New("配额对象类型只能为 user 或 role")
+	}
+	var exist example.ExaFileQuota
+	err := global.GVA_DB.Where("target_type = ? AND target_id = ?", q.TargetType, q.TargetID).First(&exist).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return global.GVA_DB.Create(&q).Error
+	}
+	if err != nil {
+		return err
+	}
+	return global.GVA_DB.Model(&exist).Update("quota", q.Quota).Error
+}
+
+// DeleteQuota 删除配额 恢复为配置文件中的默认配额
+func (e *FileUploadAndDownloadService) DeleteQuota(id uint) error {
+	return global.GVA_DB.Unscoped().Delete(&example.ExaFileQuota{}, id).Error
+}
+
+// GetQuotaList 获取全部单独设置的配额
+func (e *FileUploadAndDownloadService) GetQuotaList() (list []example.ExaFileQuota, err error) {
+	err = global.GVA_DB.Order("target_type, target_id").Find(&list).Error
+	return
+}
+
+// CreateFolder 创建文件夹
+func (e *FileUploadAndDownloadService) CreateFolder(folder example.ExaFileFolder) error {
+	if strings.TrimSpace(folder.Name) == "" {
+		return errors.New("文件夹名称不能为空")
+	}
+	if folder.ParentID != 0 {
+		if err := global.GVA_DB.First(&example.ExaFileFolder{}, folder.ParentID).Error; err != nil {
+			return errors.New("父文件夹不存在")
+		}
+	}
+	if e.folderNameExists(folder) {
+		return errors.New("同级目录下已存在同名文件夹")
+	}
+	return global.GVA_DB.Create(&folder).Error
+}
+
+// UpdateFolder 重命名或移动文件夹
+func (e *FileUploadAndDownloadService) UpdateFolder(folder example.ExaFileFolder) error {
+	if strings.TrimSpace(folder.Name) == "" {
+		return errors.New("文件夹名称不能为空")
+	}
+	var folders []example.ExaFileFolder
+	if err := global.GVA_DB.Find(&folders).Error; err != nil {
+		return err
+	}
+	parents := make(map[uint]uint, len(folders))
+	for _, f := range folders {
+		parents[f.ID] = f.ParentID
+	}
+	if _, ok := parents[folder.ID]; !ok {
+		return errors.New("文件夹不存在")
+	}
+	// 不能移动到自身或自身的子文件夹下
+	for p := folder.ParentID; p != 0; p = parents[p] {
+		if p == folder.ID {
+			return errors.New("不能移动到自身或子文件夹下")
+		}
+		if _, ok := parents[p]; !ok {
+			return errors.New("父文件夹不存在")
+		}
+	}
+	if e.folderNameExists(folder) {
+		return errors.New("同级目录下已存在同名文件夹")
+	}
+	return global.GVA_DB.Model(&example.ExaFileFolder{}).Where("id = ?", folder.ID).
+		Updates(map[string]interface{}{"name": folder.Name, "parent_id": folder.ParentID}).Error
+}
+
+func (e *FileUploadAndDownloadService) folderNameExists(folder example.ExaFileFolder) bool {
+	var count int64
+	global.GVA_DB.Model(&example.ExaFileFolder{}).
+		Where("parent_id = ? AND name = ? AND id <> ?", folder.ParentID, folder.Name, folder.ID).Count(&count)
+	return count > 0
+}
+
+// DeleteFolder 删除空文件夹
+func (e *FileUploadAndDownloadService) DeleteFolder(id uint) error {
+	var count int64
+	global.GVA_DB.Model(&example.ExaFileFolder{}).Where("parent_id = ?", id).Count(&count)
+	if count > 0 {
+		return errors.New("请先删除子文件夹")
+	}
+	global.GVA_DB.Model(&example.ExaFileUploadAndDownload{}).Where("folder_id = ?", id).Count(&count)
+	if count > 0 {
+		return errors.New("请先移出或删除文件夹内的文件")
+	}
+	return global.GVA_DB.Delete(&example.ExaFileFolder{}, id).Error
+}
+
+// GetFolderTree 获取文件夹树
+func (e *FileUploadAndDownloadService) GetFolderTree() (tree []example.ExaFileFolder, err error) {
+	var folders []example.ExaFileFolder
+	if err = global.GVA_DB.Order("name").Find(&folders).Error; err != nil {
+		return nil, err
+	}
+	children := make(map[uint][]example.ExaFileFolder)
+	for _, f := range folders {
+		children[f.ParentID] = append(children[f.ParentID], f)
+	}
+	var build func(parentID uint) []example.ExaFileFolder
+	build = func(parentID uint) []example.ExaFileFolder {
+		list := children[parentID]
+		for i := range list {
+			list[i].Children = build(list[i].ID)
+		}
+		return list
+	}
+	return build(0), nil
+}
+
+// MoveFiles 批量移动文件到文件夹
+func (e *FileUploadAndDownloadService) MoveFiles(move exampleReq.MoveFiles) error {
+	if move.FolderID != 0 {
+		if err := global.GVA_DB.First(&example.ExaFileFolder{}, move.FolderID).Error; err != nil {
+			return errors.New("文件夹不存在")
+		}
+	}
+	return global.GVA_DB.Model(&example.ExaFileUploadAndDownload{}).Where("id in ?", move.Ids).Update("folder_id", move.FolderID).Error
+}
+
+// SearchFiles 按文件夹、名称、标签、类型、大小与上传时间搜索
+func (e *FileUploadAndDownloadService) SearchFiles(info exampleReq.ExaFileSearch) (list []example.ExaFileUploadAndDownload, total int64, err error) {
+	db := global.GVA_DB.Model(&example.ExaFileUploadAndDownload{})
+	if info.FolderID != nil {
+		db = db.Where("folder_id = ?", *info.FolderID)
+	}
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if info.Tag != "" {
+		db = db.Where("tag = ?", info.Tag)
+	}
+	if info.MimeType != "" {
+		if strings.HasSuffix(info.MimeType, "/") {
+			db = db.Where("mime_type LIKE ?", info.MimeType+"%")
+		} else {
+			db = db.Where("mime_type = ?", info.MimeType)
+		}
+	}
+	if info.MinSize > 0 {
+		db = db.Where("size >= ?", info.MinSize)
+	}
+	if info.MaxSize > 0 {
+		db = db.Where("size <= ?", info.MaxSize)
+	}
+	if info.StartCreatedAt != nil {
+		db = db.Where("created_at >= ?", info.StartCreatedAt)
+	}
+	if info.EndCreatedAt != nil {
+		db = db.Where("created_at <= ?", info.EndCreatedAt)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("updated_at desc").Find(&list).Error
+	return list, total, err
+}
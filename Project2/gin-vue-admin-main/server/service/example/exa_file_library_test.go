@@ -0,0 +1,65 @@
+package example
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+)
+
+// openLibraryTestDB 使用内存数据库与临时目录下的本地存储 测试结束后恢复配置
+func openLibraryTestDB(t *testing.T, models ...interface{}) {
+	t.Helper()
+	testdb.Open(t, "", append([]interface{}{&example.ExaFileUploadAndDownload{}, &example.ExaFileFolder{}, &example.ExaFileQuota{}}, models...)...)
+	library, local, ossType, mirror := global.GVA_CONFIG.FileLibrary, global.GVA_CONFIG.Local, global.GVA_CONFIG.System.OssType, global.GVA_CONFIG.System.OssMirror
+	t.Cleanup(func() {
+		global.GVA_CONFIG.FileLibrary, global.GVA_CONFIG.Local = library, local
+		global.GVA_CONFIG.System.OssType, global.GVA_CONFIG.System.OssMirror = ossType, mirror
+	})
+	global.GVA_CONFIG.FileLibrary = config.FileLibrary{}
+	global.GVA_CONFIG.Local = config.Local{Path: "uploads/file", StorePath: t.TempDir()}
+	global.GVA_CONFIG.System.OssType, global.GVA_CONFIG.System.OssMirror = "local", ""
+}
+
+func TestFileUploadAndDownloadService_UploadFileQuota(t *testing.T) {
+	openLibraryTestDB(t)
+	global.GVA_CONFIG.FileLibrary.UserQuota = 1
+	service := new(FileUploadAndDownloadService)
+
+	// 两个上传各占配额的 60% 并发上传时只能有一个入库
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		header, err := upload.NewFileHeader("report.txt", bytes.Repeat([]byte{'a' + byte(i)}, 6*mb/10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.UploadFile(header, "0", 0, 1, 888)
+		}(i)
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("exactly one concurrent upload should pass the quota: %v", errs)
+	}
+	usage, err := service.GetUsage(1, 888)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.UserUsage != 6*mb/10 {
+		t.Fatalf("only the accepted upload should be recorded, usage = %d", usage.UserUsage)
+	}
+	uploadLock.Lock()
+	reserved := len(uploadReserved)
+	uploadLock.Unlock()
+	if reserved != 0 {
+		t.Fatalf("reservations should be released after the uploads, got %d", reserved)
+	}
+}
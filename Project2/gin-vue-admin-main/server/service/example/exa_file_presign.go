@@ -0,0 +1,277 @@
+package example
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	exampleReq "github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	exampleRes "github.com/flipped-aurora/gin-vue-admin/server/model/example/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/media"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"go.uber.org/zap"
+)
+
+// presignTicketExpire 直传凭证有效期 需覆盖大文件分片上传的耗时 分片签名地址可在有效期内重复申请
+const presignTicketExpire = 24 * time.Hour
+
+// maxPartNumber S3协议允许的最大分片序号
+const maxPartNumber = 10000
+
+// presignExpire 签名地址有效期
+func presignExpire() time.Duration {
+	if m := global.GVA_CONFIG.FileLibrary.PresignExpire; m > 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return 15 * time.Minute
+}
+
+func signTicket(t exampleReq.PresignTicket, userID uint) string {
+	return upload.Sign(t.Expires, "presign", t.Key, t.Url, t.UploadID, strconv.FormatInt(t.Size, 10), strconv.Itoa(int(userID)))
+}
+
+func verifyTicket(t exampleReq.PresignTicket, userID uint) error {
+	if err := upload.VerifySign(t.Token, t.Expires, "presign", t.Key, t.Url, t.UploadID, strconv.FormatInt(t.Size, 10), strconv.Itoa(int(userID))); err != nil {
+		return errors.New("直传凭证无效: " + err.Error())
+	}
+	return nil
+}
+
+func presignMimeType(name, mimeType string) string {
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if mimeType == "" {
+		return "application/octet-stream"
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return mimeType
+	}
+	return mediaType
+}
+
+// PresignUpload 校验大小、类型与配额后签发直传地址 文件内容由客户端直接上传到存储
+func (e *FileUploadAndDownloadService) PresignUpload(info exampleReq.PresignUpload, userID, authorityID uint) (res exampleRes.PresignUpload, err error) {
+	cfg := global.GVA_CONFIG.FileLibrary
+	if strings.TrimSpace(info.Name) == "" {
+		return res, errors.New("文件名不能为空")
+	}
+	if info.Size <= 0 {
+		return res, errors.New("文件大小不能为空")
+	}
+	if cfg.MaxSize > 0 && info.Size > cfg.MaxSize*mb {
+		return res, fmt.Errorf("文件大小超过限制 %dMB", cfg.MaxSize)
+	}
+	mimeType := presignMimeType(info.Name, info.MimeType)
+	if err = media.CheckMime(mimeType, cfg.AllowMime, cfg.DenyMime); err != nil {
+		return res, err
+	}
+	if info.FolderID != 0 {
+		if err = global.GVA_DB.First(&example.ExaFileFolder{}, info.FolderID).Error; err != nil {
+			return res, errors.New("文件夹不存在")
+		}
+	}
+	if err = e.checkQuota(userID, authorityID, info.Size); err != nil {
+		return res, err
+	}
+
+	oss := upload.NewOss()
+	var obj upload.PresignedObject
+	ticket := exampleReq.PresignTicket{Size: info.Size}
+	if info.Multipart {
+		mu, ok := oss.(upload.MultipartUploader)
+		if !ok {
+			return res, errors.New("当前存储不支持分片直传")
+		}
+		obj, ticket.UploadID, err = mu.InitiateMultipart(info.Name, mimeType)
+	} else {
+		p, ok := oss.(upload.Presigner)
+		if !ok {
+			return res, errors.New("当前存储不支持直传")
+		}
+		obj, err = p.PresignPut(info.Name, presignExpire())
+	}
+	if err != nil {
+		return res, err
+	}
+	ticket.Key, ticket.Url = obj.Key, obj.Url
+	ticket.Expires = time.Now().Add(presignTicketExpire).Unix()
+	ticket.Token = signTicket(ticket, userID)
+	return exampleRes.PresignUpload{
+		Key:       ticket.Key,
+		Url:       ticket.Url,
+		UploadUrl: obj.UploadUrl,
+		UploadID:  ticket.UploadID,
+		Size:      ticket.Size,
+		Expires:   ticket.Expires,
+		Token:     ticket.Token,
+	}, nil
+}
+
+// PresignParts 签发分片上传地址 可重复调用以续期
+func (e *FileUploadAndDownloadService) PresignParts(info exampleReq.PresignParts, userID uint) (parts []exampleRes.PresignPart, err error) {
+	if err = verifyTicket(info.PresignTicket, userID); err != nil {
+		return nil, err
+	}
+	if info.UploadID == "" {
+		return nil, errors.New("非分片直传")
+	}
+	mu, ok := upload.NewOss().(upload.MultipartUploader)
+	if !ok {
+		return nil, errors.New("当前存储不支持分片直传")
+	}
+	for _, n := range info.PartNumbers {
+		if n < 1 || n > maxPartNumber {
+			return nil, fmt.Errorf("分片序号需在 1-%d 之间", maxPartNumber)
+		}
+		u, pErr := mu.PresignPart(info.Key, info.UploadID, n, presignExpire())
+		if pErr != nil {
+			return nil, pErr
+		}
+		parts = append(parts, exampleRes.PresignPart{PartNumber: n, Url: u})
+	}
+	return parts, nil
+}
+
+// CompletePresign 直传完成回调 分片直传时先合并分片 再记录文件
+func (e *FileUploadAndDownloadService) CompletePresign(info exampleReq.PresignComplete, userID, authorityID uint) (file example.ExaFileUploadAndDownload, err error) {
+	if err = verifyTicket(info.PresignTicket, userID); err != nil {
+		return file, err
+	}
+	if info.FolderID != 0 {
+		if err = global.GVA_DB.First(&example.ExaFileFolder{}, info.FolderID).Error; err != nil {
+			return file, errors.New("文件夹不存在")
+		}
+	}
+
+	if e.keyReferenced(0, "key", info.Key) {
+		return file, errors.New("文件已记录, 请勿重复提交")
+	}
+	oss := upload.NewOss()
+	if info.UploadID != "" {
+		if len(info.Parts) == 0 {
+			return file, errors.New("分片列表不能为空")
+		}
+		mu, ok := oss.(upload.MultipartUploader)
+		if !ok {
+			return file, errors.New("当前存储不支持分片直传")
+		}
+		parts := make([]upload.Part, 0, len(info.Parts))
+		for _, p := range info.Parts {
+			parts = append(parts, upload.Part{PartNumber: p.PartNumber, ETag: p.ETag})
+		}
+		sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+		if err = mu.CompleteMultipart(info.Key, info.UploadID, parts); err != nil {
+			return file, errors.New("合并分片失败: " + err.Error())
+		}
+	}
+	reject := func(err error) (example.ExaFileUploadAndDownload, error) {
+		if dErr := oss.DeleteFile(info.Key); dErr != nil {
+			global.GVA_LOG.Warn("删除未通过校验的直传文件失败", zap.String("key", info.Key), zap.Error(dErr))
+		}
+		return example.ExaFileUploadAndDownload{}, err
+	}
+	// 客户端实际上传的内容可能与申请时不同 以存储中的对象为准校验大小与类型
+	size, head, err := upload.StatObject(oss, info.Key, media.SniffLen)
+	if err != nil {
+		return file, errors.New("读取直传文件失败: " + err.Error())
+	}
+	if size != info.Size {
+		return reject(fmt.Errorf("文件大小 %d 与申请时的 %d 不一致", size, info.Size))
+	}
+	cfg := global.GVA_CONFIG.FileLibrary
+	mimeType := media.Sniff(head)
+	if err = media.CheckMime(mimeType, cfg.AllowMime, cfg.DenyMime); err != nil {
+		return reject(err)
+	}
+	name := info.Name
+	if name == "" {
+		name = filepath.Base(info.Key)
+	}
+	file = example.ExaFileUploadAndDownload{
+		Name:        name,
+		Url:         info.Url,
+		Tag:         strings.TrimPrefix(filepath.Ext(name), "."),
+		Key:         info.Key,
+		FolderID:    info.FolderID,
+		Size:        size,
+		MimeType:    mimeType,
+		UserID:      userID,
+		AuthorityID: authorityID,
+	}
+	// 申请直传到完成期间配额可能已被其他上传占用 配额校验与入库在同一把锁内完成
+	uploadLock.Lock()
+	if e.keyReferenced(0, "key", info.Key) {
+		uploadLock.Unlock()
+		return example.ExaFileUploadAndDownload{}, errors.New("文件已记录, 请勿重复提交")
+	}
+	if err = e.checkQuotaLocked(userID, authorityID, size); err != nil {
+		uploadLock.Unlock()
+		return reject(err)
+	}
+	err = global.GVA_DB.Create(&file).Error
+	uploadLock.Unlock()
+	return file, err
+}
+
+// AbortPresign 取消直传 分片直传时丢弃已上传的分片
+func (e *FileUploadAndDownloadService) AbortPresign(info exampleReq.PresignTicket, userID uint) error {
+	if err := verifyTicket(info, userID); err != nil {
+		return err
+	}
+	oss := upload.NewOss()
+	if info.UploadID != "" {
+		mu, ok := oss.(upload.MultipartUploader)
+		if !ok {
+			return errors.New("当前存储不支持分片直传")
+		}
+		return mu.AbortMultipart(info.Key, info.UploadID)
+	}
+	if e.keyReferenced(0, "key", info.Key) {
+		return errors.New("文件已记录, 请通过删除文件接口删除")
+	}
+	// 客户端可能尚未上传 删除失败不视为错误
+	if err := oss.DeleteFile(info.Key); err != nil {
+		global.GVA_LOG.Debug("取消直传时删除对象失败", zap.String("key", info.Key), zap.Error(err))
+	}
+	return nil
+}
+
+// PresignDownload 生成文件的签名下载地址
+func (e *FileUploadAndDownloadService) PresignDownload(id uint) (string, error) {
+	var file example.ExaFileUploadAndDownload
+	if err := global.GVA_DB.First(&file, id).Error; err != nil {
+		return "", errors.New("文件不存在")
+	}
+	p, ok := upload.NewOss().(upload.Presigner)
+	if !ok {
+		return "", errors.New("当前存储不支持签名下载")
+	}
+	return p.PresignGet(file.Key, presignExpire())
+}
+
+// PutLocalObject 校验签名后写入本地存储 对应 Local.PresignPut 签发的地址
+func (e *FileUploadAndDownloadService) PutLocalObject(key string, expires int64, sign string, r io.Reader) error {
+	if err := upload.VerifyLocalSign(http.MethodPut, key, expires, sign); err != nil {
+		return err
+	}
+	return (&upload.Local{}).PutObject(key, r)
+}
+
+// LocalObjectPath 校验签名后返回本地文件路径 对应 Local.PresignGet 签发的地址
+func (e *FileUploadAndDownloadService) LocalObjectPath(key string, expires int64, sign string) (string, error) {
+	if err := upload.VerifyLocalSign(http.MethodGet, key, expires, sign); err != nil {
+		return "", err
+	}
+	return (&upload.Local{}).ObjectPath(key)
+}
@@ -0,0 +1,105 @@
+package example
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	exampleReq "github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+)
+
+// presignLocal 申请本地存储直传并写入 content 返回完成回调的参数
+func presignLocal(t *testing.T, service *FileUploadAndDownloadService, content string) exampleReq.PresignComplete {
+	t.Helper()
+	res, err := service.PresignUpload(exampleReq.PresignUpload{Name: "a.txt", Size: int64(len(content))}, 1, 888)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = (&upload.Local{}).PutObject(res.Key, strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	return exampleReq.PresignComplete{
+		PresignTicket: exampleReq.PresignTicket{Key: res.Key, Url: res.Url, Size: res.Size, Expires: res.Expires, Token: res.Token},
+		Name:          "a.txt",
+	}
+}
+
+func TestFileUploadAndDownloadService_CompletePresign(t *testing.T) {
+	openLibraryTestDB(t)
+	jwt := global.GVA_CONFIG.JWT
+	t.Cleanup(func() { global.GVA_CONFIG.JWT = jwt })
+	global.GVA_CONFIG.JWT.SigningKey = "test-signing-key"
+	service := new(FileUploadAndDownloadService)
+	info := presignLocal(t, service, "hello")
+
+	expired := info
+	expired.Expires = time.Now().Add(-time.Second).Unix()
+	expired.Token = signTicket(expired.PresignTicket, 1)
+	tamperedKey := info
+	tamperedKey.Key = "other_" + info.Key
+	tamperedSize := info
+	tamperedSize.Size = 1
+	tamperedExpires := info
+	tamperedExpires.Expires += 3600
+	tests := []struct {
+		name   string
+		info   exampleReq.PresignComplete
+		userID uint
+	}{
+		{name: "凭证已过期", info: expired, userID: 1},
+		{name: "篡改路径", info: tamperedKey, userID: 1},
+		{name: "篡改大小", info: tamperedSize, userID: 1},
+		{name: "篡改过期时间", info: tamperedExpires, userID: 1},
+		{name: "其他用户的凭证", info: info, userID: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CompletePresign(tt.info, tt.userID, 888); err == nil || !strings.HasPrefix(err.Error(), "直传凭证无效") {
+				t.Fatalf("CompletePresign() should reject the ticket: %v", err)
+			}
+			if err := service.AbortPresign(tt.info.PresignTicket, tt.userID); err == nil {
+				t.Fatal("AbortPresign() should reject the ticket")
+			}
+			if _, err := service.PresignParts(exampleReq.PresignParts{PresignTicket: tt.info.PresignTicket}, tt.userID); err == nil {
+				t.Fatal("PresignParts() should reject the ticket")
+			}
+		})
+	}
+	var count int64
+	global.GVA_DB.Model(&example.ExaFileUploadAndDownload{}).Count(&count)
+	if _, err := os.Stat(filepath.Join(global.GVA_CONFIG.Local.StorePath, info.Key)); count != 0 || err != nil {
+		t.Fatalf("rejected tickets should neither record nor delete the object: %d %v", count, err)
+	}
+
+	file, err := service.CompletePresign(info, 1, 888)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.ID == 0 || file.Key != info.Key || file.Size != 5 || file.UserID != 1 {
+		t.Fatalf("unexpected file: %+v", file)
+	}
+	if _, err = service.CompletePresign(info, 1, 888); err == nil {
+		t.Fatal("completing a ticket twice should fail")
+	}
+}
+
+func TestFileUploadAndDownloadService_CompletePresignSizeMismatch(t *testing.T) {
+	openLibraryTestDB(t)
+	service := new(FileUploadAndDownloadService)
+	info := presignLocal(t, service, "hello")
+	// 客户端实际上传的内容比申请时更大
+	if err := (&upload.Local{}).PutObject(info.Key, strings.NewReader("hello world")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.CompletePresign(info, 1, 888); err == nil || !strings.Contains(err.Error(), "不一致") {
+		t.Fatalf("object larger than the ticket should be rejected: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(global.GVA_CONFIG.Local.StorePath, info.Key)); !os.IsNotExist(err) {
+		t.Fatalf("rejected object should be deleted: %v", err)
+	}
+}
@@ -3,12 +3,12 @@ package example
 import (
 	"errors"
 	"mime/multipart"
-	"strings"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"go.uber.org/zap"
 )
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -46,13 +46,28 @@ func (e *FileUploadAndDownloadService) DeleteFile(file example.ExaFileUploadAndD
 		return
 	}
 	oss := upload.NewOss()
-	if err = oss.DeleteFile(fileFromDb.Key); err != nil {
-		return errors.New("文件删除失败")
+	// 内容去重后多条记录可能共用同一个对象 仅在最后一条引用删除时删除对象
+	if fileFromDb.Key != "" && !e.keyReferenced(fileFromDb.ID, "key", fileFromDb.Key) {
+		if err = oss.DeleteFile(fileFromDb.Key); err != nil {
+			return errors.New("文件删除失败")
+		}
+	}
+	if fileFromDb.ThumbKey != "" && !e.keyReferenced(fileFromDb.ID, "thumb_key", fileFromDb.ThumbKey) {
+		if err = oss.DeleteFile(fileFromDb.ThumbKey); err != nil {
+			global.GVA_LOG.Warn("缩略图删除失败", zap.String("key", fileFromDb.ThumbKey), zap.Error(err))
+		}
 	}
 	err = global.GVA_DB.Where("id = ?", file.ID).Unscoped().Delete(&file).Error
 	return err
 }
 
+// keyReferenced 除自身外是否还有记录引用该对象
+func (e *FileUploadAndDownloadService) keyReferenced(id uint, column string, key string) bool {
+	var count int64
+	global.GVA_DB.Model(&example.ExaFileUploadAndDownload{}).Where("id <> ?", id).Where(map[string]interface{}{column: key}).Count(&count)
+	return count > 0
+}
+
 // EditFileName 编辑文件名或者备注
 func (e *FileUploadAndDownloadService) EditFileName(file example.ExaFileUploadAndDownload) (err error) {
 	var fileFromDb example.ExaFileUploadAndDownload
@@ -84,25 +99,19 @@ func (e *FileUploadAndDownloadService) GetFileRecordInfoList(info request.PageIn
 
 //@author: [piexlmax](https://github.com/piexlmax)
 //@function: UploadFile
-//@description: 根据配置文件判断是文件上传到本地或者七牛云
-//@param: header *multipart.FileHeader, noSave string
+//@description: 根据配置文件判断是文件上传到本地或者七牛云 经过媒体库的类型检查、去重与配额校验
+//@param: header *multipart.FileHeader, noSave string, folderID uint, userID uint, authorityID uint
 //@return: file model.ExaFileUploadAndDownload, err error
 
-func (e *FileUploadAndDownloadService) UploadFile(header *multipart.FileHeader, noSave string) (file example.ExaFileUploadAndDownload, err error) {
-	oss := upload.NewOss()
-	filePath, key, uploadErr := oss.UploadFile(header)
-	if uploadErr != nil {
-		panic(uploadErr)
-	}
-	s := strings.Split(header.Filename, ".")
-	f := example.ExaFileUploadAndDownload{
-		Url:  filePath,
-		Name: header.Filename,
-		Tag:  s[len(s)-1],
-		Key:  key,
+func (e *FileUploadAndDownloadService) UploadFile(header *multipart.FileHeader, noSave string, folderID, userID, authorityID uint) (file example.ExaFileUploadAndDownload, err error) {
+	f, release, err := e.uploadToLibrary(header, folderID, userID, authorityID)
+	if err != nil {
+		return f, err
 	}
+	defer release()
+	// 直接写入 f 使返回的记录带有ID(断点续传完成后需记录文件ID)
 	if noSave == "0" {
-		return f, e.Upload(f)
+		err = global.GVA_DB.Create(&f).Error
 	}
-	return f, nil
+	return f, err
 }
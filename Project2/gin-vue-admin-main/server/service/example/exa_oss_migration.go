@@ -0,0 +1,381 @@
+package example
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	exampleReq "github.com/flipped-aurora/gin-vue-admin/server/model/example/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type OssMigrationService struct{}
+
+var OssMigrationServiceApp = new(OssMigrationService)
+
+// ossMigrationRunning 本实例中正在执行的迁移任务 值为停止标记 *atomic.Bool
+var ossMigrationRunning sync.Map
+
+const ossMigrationBatch = 100
+
+func validOssType(ossType string) bool {
+	for _, t := range upload.OssTypes {
+		if t == ossType {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateOssMigration 创建存储迁移任务并开始执行
+func (s *OssMigrationService) CreateOssMigration(info exampleReq.CreateOssMigration) (m example.ExaOssMigration, err error) {
+	if !validOssType(info.Source) || !validOssType(info.Target) {
+		return m, errors.New("不支持的存储类型")
+	}
+	if info.Source == info.Target {
+		return m, errors.New("源存储与目标存储不能相同")
+	}
+	var count int64
+	global.GVA_DB.Model(&example.ExaOssMigration{}).Where("status = ?", example.OssMigrationRunning).Count(&count)
+	if count > 0 {
+		return m, errors.New("已有正在执行的迁移任务")
+	}
+	m = example.ExaOssMigration{Source: info.Source, Target: info.Target, Status: example.OssMigrationPending}
+	if err = global.GVA_DB.Create(&m).Error; err != nil {
+		return m, err
+	}
+	go s.runOssMigration(m.ID)
+	return m, nil
+}
+
+// ResumeOssMigration 续传 只处理未成功的对象 并补充迁移创建后新上传的文件
+func (s *OssMigrationService) ResumeOssMigration(id uint) error {
+	var m example.ExaOssMigration
+	if err := global.GVA_DB.First(&m, id).Error; err != nil {
+		return err
+	}
+	if m.Status == example.OssMigrationApplied {
+		return errors.New("迁移已应用, 无需继续")
+	}
+	if _, ok := ossMigrationRunning.Load(id); ok {
+		return errors.New("迁移正在执行中")
+	}
+	go s.runOssMigration(id)
+	return nil
+}
+
+// StopOssMigration 停止迁移 当前对象处理完成后生效
+func (s *OssMigrationService) StopOssMigration(id uint) error {
+	v, ok := ossMigrationRunning.Load(id)
+	if !ok {
+		return errors.New("迁移未在执行")
+	}
+	v.(*atomic.Bool).Store(true)
+	return nil
+}
+
+func (s *OssMigrationService) runOssMigration(id uint) {
+	stop := new(atomic.Bool)
+	if _, loaded := ossMigrationRunning.LoadOrStore(id, stop); loaded {
+		return
+	}
+	defer ossMigrationRunning.Delete(id)
+	defer func() {
+		if r := recover(); r != nil {
+			global.GVA_LOG.Error("存储迁移异常", zap.Uint("id", id), zap.Any("panic", r))
+			s.finishOssMigration(id, example.OssMigrationFailed, fmt.Sprint(r))
+		}
+	}()
+
+	var m example.ExaOssMigration
+	if err := global.GVA_DB.First(&m, id).Error; err != nil {
+		global.GVA_LOG.Error("存储迁移任务不存在", zap.Uint("id", id), zap.Error(err))
+		return
+	}
+	now := time.Now()
+	global.GVA_DB.Model(&m).Updates(map[string]interface{}{"status": example.OssMigrationRunning, "started_at": &now, "error": ""})
+	if _, err := s.collectOssMigrationItems(m, true); err != nil {
+		s.finishOssMigration(id, example.OssMigrationFailed, err.Error())
+		return
+	}
+
+	src, dst := upload.NewOssByType(m.Source), upload.NewOssByType(m.Target)
+	var lastID uint
+	for {
+		var items []example.ExaOssMigrationItem
+		err := global.GVA_DB.Where("migration_id = ? AND status <> ? AND id > ?", id, example.OssMigrationSuccess, lastID).
+			Order("id").Limit(ossMigrationBatch).Find(&items).Error
+		if err != nil {
+			s.finishOssMigration(id, example.OssMigrationFailed, err.Error())
+			return
+		}
+		if len(items) == 0 {
+			break
+		}
+		for i := range items {
+			if stop.Load() {
+				s.finishOssMigration(id, example.OssMigrationStopped, "")
+				return
+			}
+			item := &items[i]
+			lastID = item.ID
+			if err = s.copyOssObject(src, dst, item); err != nil {
+				global.GVA_LOG.Warn("对象迁移失败", zap.String("key", item.SourceKey), zap.Error(err))
+				item.Status, item.Error = example.OssMigrationFailed, err.Error()
+			} else {
+				item.Status, item.Error = example.OssMigrationSuccess, ""
+			}
+			global.GVA_DB.Save(item)
+			s.countOssMigration(id)
+		}
+	}
+	s.finishOssMigration(id, "", "")
+}
+
+// copyOssObject 从源存储流式读取对象 计算sha256的同时写入临时文件后上传到目标存储 再流式读回目标对象校验sha256
+func (s *OssMigrationService) copyOssObject(src, dst upload.OSS, item *example.ExaOssMigrationItem) error {
+	key, _ := upload.SplitMirrorKey(item.SourceKey)
+	r, err := openOssObject(src, key, item.SourceUrl)
+	if err != nil {
+		return fmt.Errorf("读取源对象失败: %w", err)
+	}
+	defer r.Close()
+	sum := sha256.New()
+	fh, remove, err := upload.StreamFileHeader(item.Name, mergeMemory, func(w io.Writer) error {
+		_, err := io.Copy(io.MultiWriter(w, sum), r)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("读取源对象失败: %w", err)
+	}
+	defer remove()
+	checksum := hex.EncodeToString(sum.Sum(nil))
+	url, targetKey, err := dst.UploadFile(fh)
+	if err != nil {
+		return fmt.Errorf("写入目标存储失败: %w", err)
+	}
+	written, err := hashOssObject(dst, targetKey, url)
+	if err == nil && written != checksum {
+		err = errors.New("sha256 不一致")
+	}
+	if err != nil {
+		if dErr := dst.DeleteFile(targetKey); dErr != nil {
+			global.GVA_LOG.Warn("删除校验失败的目标对象失败", zap.String("key", targetKey), zap.Error(dErr))
+		}
+		return fmt.Errorf("校验目标对象失败: %w", err)
+	}
+	item.TargetKey, item.TargetUrl, item.Checksum = targetKey, url, checksum
+	return nil
+}
+
+// openOssObject 打开对象 存储不支持读取时通过公开地址读取(如七牛)
+func openOssObject(oss upload.OSS, key string, url string) (io.ReadCloser, error) {
+	r, err := upload.OpenObject(oss, key)
+	if err == nil {
+		return r, nil
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, err
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("status: %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// hashOssObject 流式计算对象的sha256
+func hashOssObject(oss upload.OSS, key string, url string) (string, error) {
+	r, err := openOssObject(oss, key, url)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	sum := sha256.New()
+	if _, err = io.Copy(sum, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// collectOssMigrationItems 分批收集媒体库中尚未加入迁移的对象 save 为 false 时只统计数量
+func (s *OssMigrationService) collectOssMigrationItems(m example.ExaOssMigration, save bool) (int, error) {
+	// 不保存时后续批次查询不到本次已统计的对象 在内存中去重
+	counted := make(map[string]bool)
+	var total int
+	var lastID uint
+	for {
+		var files []example.ExaFileUploadAndDownload
+		err := global.GVA_DB.Not(map[string]interface{}{"key": ""}).Where("id > ?", lastID).
+			Order("id").Limit(ossMigrationBatch).Find(&files).Error
+		if err != nil {
+			return 0, err
+		}
+		if len(files) == 0 {
+			break
+		}
+		lastID = files[len(files)-1].ID
+
+		keys := make([]string, 0, 2*len(files))
+		for _, f := range files {
+			keys = append(keys, f.Key)
+			if f.ThumbKey != "" {
+				keys = append(keys, f.ThumbKey)
+			}
+		}
+		var exist []string
+		err = global.GVA_DB.Model(&example.ExaOssMigrationItem{}).Where("migration_id = ? AND source_key IN ?", m.ID, keys).
+			Pluck("source_key", &exist).Error
+		if err != nil {
+			return 0, err
+		}
+		seen := make(map[string]bool, len(exist))
+		for _, k := range exist {
+			seen[k] = true
+		}
+		var items []example.ExaOssMigrationItem
+		add := func(kind, name, key, url string) {
+			if key == "" || seen[key] || counted[key] {
+				return
+			}
+			seen[key] = true
+			if !save {
+				counted[key] = true
+			}
+			items = append(items, example.ExaOssMigrationItem{
+				MigrationID: m.ID, Kind: kind, Name: name, SourceKey: key, SourceUrl: url, Status: example.OssMigrationPending,
+			})
+		}
+		for _, f := range files {
+			add(example.OssMigrationKindFile, f.Name, f.Key, f.Url)
+			add(example.OssMigrationKindThumb, "thumb_"+f.Name, f.ThumbKey, f.ThumbUrl)
+		}
+		total += len(items)
+		if save && len(items) > 0 {
+			if err = global.GVA_DB.CreateInBatches(&items, ossMigrationBatch).Error; err != nil {
+				return 0, err
+			}
+		}
+	}
+	if save && total > 0 {
+		s.countOssMigration(m.ID)
+	}
+	return total, nil
+}
+
+func (s *OssMigrationService) countOssMigration(id uint) {
+	var total, done, failed int64
+	db := global.GVA_DB.Model(&example.ExaOssMigrationItem{}).Where("migration_id = ?", id)
+	db.Session(&gorm.Session{}).Count(&total)
+	db.Session(&gorm.Session{}).Where("status = ?", example.OssMigrationSuccess).Count(&done)
+	db.Session(&gorm.Session{}).Where("status = ?", example.OssMigrationFailed).Count(&failed)
+	global.GVA_DB.Model(&example.ExaOssMigration{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"total": total, "done": done, "failed": failed})
+}
+
+// finishOssMigration 结束迁移 status 为空时根据失败数判断
+func (s *OssMigrationService) finishOssMigration(id uint, status string, errMsg string) {
+	s.countOssMigration(id)
+	if status == "" {
+		var m example.ExaOssMigration
+		global.GVA_DB.First(&m, id)
+		status = example.OssMigrationSuccess
+		if m.Failed > 0 {
+			status = example.OssMigrationFailed
+			errMsg = fmt.Sprintf("%d 个对象迁移失败, 可查看失败明细后续传", m.Failed)
+		}
+	}
+	now := time.Now()
+	global.GVA_DB.Model(&example.ExaOssMigration{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "finished_at": &now, "error": errMsg})
+}
+
+// ApplyOssMigration 将文件记录中的地址与key改写为目标存储 应用后需将 system.oss-type 切换为目标存储
+func (s *OssMigrationService) ApplyOssMigration(id uint) error {
+	var m example.ExaOssMigration
+	if err := global.GVA_DB.First(&m, id).Error; err != nil {
+		return err
+	}
+	if m.Status != example.OssMigrationSuccess {
+		return errors.New("只有全部对象迁移成功的任务才能应用")
+	}
+	if _, ok := ossMigrationRunning.Load(id); ok {
+		return errors.New("迁移正在执行中")
+	}
+	if n, err := s.collectOssMigrationItems(m, false); err != nil {
+		return err
+	} else if n > 0 {
+		return fmt.Errorf("迁移后新上传了 %d 个对象, 请先续传", n)
+	}
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		var items []example.ExaOssMigrationItem
+		if err := tx.Where("migration_id = ?", id).Find(&items).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			keyColumn, urlColumn := "key", "url"
+			if item.Kind == example.OssMigrationKindThumb {
+				keyColumn, urlColumn = "thumb_key", "thumb_url"
+			}
+			err := tx.Model(&example.ExaFileUploadAndDownload{}).Where(map[string]interface{}{keyColumn: item.SourceKey}).
+				Updates(map[string]interface{}{keyColumn: item.TargetKey, urlColumn: item.TargetUrl}).Error
+			if err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		return tx.Model(&m).Updates(map[string]interface{}{"status": example.OssMigrationApplied, "applied_at": &now}).Error
+	})
+}
+
+// DeleteOssMigration 删除迁移任务记录 不删除已复制的对象
+func (s *OssMigrationService) DeleteOssMigration(id uint) error {
+	if _, ok := ossMigrationRunning.Load(id); ok {
+		return errors.New("迁移正在执行中")
+	}
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("migration_id = ?", id).Delete(&example.ExaOssMigrationItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&example.ExaOssMigration{}, id).Error
+	})
+}
+
+// GetOssMigrationList 分页获取迁移任务
+func (s *OssMigrationService) GetOssMigrationList(info request.PageInfo) (list []example.ExaOssMigration, total int64, err error) {
+	db := global.GVA_DB.Model(&example.ExaOssMigration{})
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// GetOssMigrationItemList 分页获取迁移对象 可按状态筛选失败明细
+func (s *OssMigrationService) GetOssMigrationItemList(info exampleReq.OssMigrationItemSearch) (list []example.ExaOssMigrationItem, total int64, err error) {
+	db := global.GVA_DB.Model(&example.ExaOssMigrationItem{}).Where("migration_id = ?", info.MigrationID)
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if err = db.Count(&total).Error; err != nil {
+		return
+	}
+	err = db.Scopes(info.Paginate()).Order("id").Find(&list).Error
+	return list, total, err
+}
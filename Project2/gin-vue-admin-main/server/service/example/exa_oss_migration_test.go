@@ -0,0 +1,121 @@
+package example
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+)
+
+// memoryOss 内存中的目标存储 corrupt 为 true 时写入的内容被篡改
+type memoryOss struct {
+	objects map[string][]byte
+	corrupt bool
+}
+
+func (m *memoryOss) UploadFile(file *multipart.FileHeader) (string, string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return "", "", err
+	}
+	if m.corrupt {
+		content = append(content, '!')
+	}
+	key := fmt.Sprintf("%d_%s", len(m.objects), file.Filename)
+	m.objects[key] = content
+	return "https://memory.test/" + key, key, nil
+}
+
+func (m *memoryOss) DeleteFile(key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryOss) Open(key string) (io.ReadCloser, error) {
+	content, ok := m.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func TestOssMigrationService_copyOssObject(t *testing.T) {
+	openLibraryTestDB(t)
+	content := bytes.Repeat([]byte("migrate"), mb)
+	fh, err := upload.NewFileHeader("report.bin", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := upload.NewOssByType("local")
+	url, key, err := src.UploadFile(fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	service := OssMigrationServiceApp
+
+	dst := &memoryOss{objects: map[string][]byte{}}
+	item := example.ExaOssMigrationItem{Name: "report.bin", SourceKey: key, SourceUrl: url}
+	if err = service.copyOssObject(src, dst, &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.Checksum != hex.EncodeToString(sum[:]) || !bytes.Equal(dst.objects[item.TargetKey], content) {
+		t.Fatalf("copyOssObject() should copy the object and record its sha256: %+v", item)
+	}
+
+	dst = &memoryOss{objects: map[string][]byte{}, corrupt: true}
+	item = example.ExaOssMigrationItem{Name: "report.bin", SourceKey: key, SourceUrl: url}
+	if err = service.copyOssObject(src, dst, &item); err == nil {
+		t.Fatal("copyOssObject() should reject a target with a different sha256")
+	}
+	if len(dst.objects) != 0 || item.TargetKey != "" {
+		t.Fatalf("target object failing verification should be deleted: %v", dst.objects)
+	}
+}
+
+func TestOssMigrationService_collectOssMigrationItems(t *testing.T) {
+	openLibraryTestDB(t, &example.ExaOssMigration{}, &example.ExaOssMigrationItem{})
+	// 跨越多个批次 且不同记录引用同一对象
+	var files []example.ExaFileUploadAndDownload
+	for i := 0; i < ossMigrationBatch*2+10; i++ {
+		files = append(files, example.ExaFileUploadAndDownload{Name: "a.png", Key: fmt.Sprintf("k%d", i%(ossMigrationBatch+5)), ThumbKey: fmt.Sprintf("t%d", i)})
+	}
+	files = append(files, example.ExaFileUploadAndDownload{Name: "remote"})
+	if err := global.GVA_DB.CreateInBatches(&files, ossMigrationBatch).Error; err != nil {
+		t.Fatal(err)
+	}
+	m := example.ExaOssMigration{Source: "local", Target: "aws-s3"}
+	if err := global.GVA_DB.Create(&m).Error; err != nil {
+		t.Fatal(err)
+	}
+	service := OssMigrationServiceApp
+	want := ossMigrationBatch + 5 + ossMigrationBatch*2 + 10
+
+	if n, err := service.collectOssMigrationItems(m, false); err != nil || n != want {
+		t.Fatalf("collectOssMigrationItems(false) = %d %v, want %d", n, err, want)
+	}
+	if n, err := service.collectOssMigrationItems(m, true); err != nil || n != want {
+		t.Fatalf("collectOssMigrationItems(true) = %d %v, want %d", n, err, want)
+	}
+	var saved int64
+	global.GVA_DB.Model(&example.ExaOssMigrationItem{}).Where("migration_id = ?", m.ID).Count(&saved)
+	if saved != int64(want) {
+		t.Fatalf("each object should be saved once, got %d", saved)
+	}
+	if n, err := service.collectOssMigrationItems(m, false); err != nil || n != 0 {
+		t.Fatalf("collected objects should not be collected again: %d %v", n, err)
+	}
+}
@@ -0,0 +1,359 @@
+package example
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/media"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"github.com/gofrs/uuid/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TusChecksumAlgorithms 支持的 Upload-Checksum 算法
+const TusChecksumAlgorithms = "md5,sha1,sha256"
+
+var (
+	ErrTusNotFound         = errors.New("上传不存在或已过期")
+	ErrTusOffsetMismatch   = errors.New("Upload-Offset 与已上传大小不一致")
+	ErrTusChecksumMismatch = errors.New("分片校验失败")
+	ErrTusTooLarge         = errors.New("上传内容超过 Upload-Length")
+)
+
+// tusLock 带引用计数的上传锁 最后一个使用者释放后从 tusLocks 中移除
+type tusLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+var (
+	// tusLocks 按上传ID串行化写入与合并 跨实例的并发由数据库中 offset 的条件更新兜底
+	tusLocks   = map[string]*tusLock{}
+	tusLocksMu sync.Mutex
+)
+
+func lockUpload(id string) func() {
+	tusLocksMu.Lock()
+	l, ok := tusLocks[id]
+	if !ok {
+		l = &tusLock{}
+		tusLocks[id] = l
+	}
+	l.refs++
+	tusLocksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		tusLocksMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(tusLocks, id)
+		}
+		tusLocksMu.Unlock()
+	}
+}
+
+func tusExpire() time.Duration {
+	if h := global.GVA_CONFIG.Tus.Expire; h > 0 {
+		return time.Duration(h) * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// parseTusMetadata 解析 Upload-Metadata: key base64(value),key2 base64(value2)
+func parseTusMetadata(header string) (map[string]string, error) {
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, " ", 2)
+		if len(kv) == 1 {
+			meta[kv[0]] = ""
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("Upload-Metadata 中 %s 的值不是合法的base64", kv[0])
+		}
+		meta[kv[0]] = string(value)
+	}
+	return meta, nil
+}
+
+// parseTusChecksum 解析 Upload-Checksum: <算法> base64(摘要)
+func parseTusChecksum(header string) (hash.Hash, []byte, error) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 {
+		return nil, nil, errors.New("Upload-Checksum 格式错误")
+	}
+	sum, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, errors.New("Upload-Checksum 摘要不是合法的base64")
+	}
+	switch parts[0] {
+	case "md5":
+		return md5.New(), sum, nil
+	case "sha1":
+		return sha1.New(), sum, nil
+	case "sha256":
+		return sha256.New(), sum, nil
+	default:
+		return nil, nil, errors.New("不支持的校验算法: " + parts[0])
+	}
+}
+
+// CreateTusUpload tus creation: 校验大小、类型与配额后创建上传
+func (e *FileUploadAndDownloadService) CreateTusUpload(length int64, metadata string, userID, authorityID uint) (up example.ExaTusUpload, err error) {
+	cfg := global.GVA_CONFIG.FileLibrary
+	if length <= 0 {
+		return up, errors.New("Upload-Length 必须大于0")
+	}
+	if cfg.MaxSize > 0 && length > cfg.MaxSize*mb {
+		return up, ErrTusTooLarge
+	}
+	meta, err := parseTusMetadata(metadata)
+	if err != nil {
+		return up, err
+	}
+	up.FileName = meta["filename"]
+	if up.FileName == "" {
+		up.FileName = meta["name"]
+	}
+	if up.FileName == "" {
+		return up, errors.New("Upload-Metadata 缺少 filename")
+	}
+	up.FileName = filepath.Base(up.FileName)
+	up.MimeType = presignMimeType(up.FileName, meta["filetype"])
+	if err = media.CheckMime(up.MimeType, cfg.AllowMime, cfg.DenyMime); err != nil {
+		return up, err
+	}
+	if v := meta["folderId"]; v != "" {
+		folderID, _ := strconv.Atoi(v)
+		if err = global.GVA_DB.First(&example.ExaFileFolder{}, folderID).Error; err != nil {
+			return up, errors.New("文件夹不存在")
+		}
+		up.FolderID = uint(folderID)
+	}
+	if err = e.checkQuota(userID, authorityID, length); err != nil {
+		return up, err
+	}
+	up.UploadID = strings.ReplaceAll(uuid.Must(uuid.NewV4()).String(), "-", "")
+	up.Length = length
+	up.Metadata = meta
+	up.UserID = userID
+	up.AuthorityID = authorityID
+	up.ExpiresAt = time.Now().Add(tusExpire())
+	err = global.GVA_DB.Create(&up).Error
+	return up, err
+}
+
+// GetTusUpload 获取当前用户未过期的上传
+func (e *FileUploadAndDownloadService) GetTusUpload(id string, userID uint) (up example.ExaTusUpload, err error) {
+	err = global.GVA_DB.Where("upload_id = ? AND user_id = ? AND expires_at > ?", id, userID, time.Now()).First(&up).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return up, ErrTusNotFound
+	}
+	return up, err
+}
+
+// PatchTusUpload tus core: 在 offset 处追加数据 数据接收完整后合并并写入媒体库
+// checksum 为 Upload-Checksum 请求头 为空时不校验 校验失败的数据段会被丢弃
+func (e *FileUploadAndDownloadService) PatchTusUpload(id string, offset int64, checksum string, body io.Reader, userID uint) (up example.ExaTusUpload, err error) {
+	unlock := lockUpload(id)
+	defer unlock()
+	if up, err = e.GetTusUpload(id, userID); err != nil {
+		return up, err
+	}
+	if up.FileID != 0 || offset != up.Offset {
+		return up, ErrTusOffsetMismatch
+	}
+	var hasher hash.Hash
+	var expected []byte
+	if checksum != "" {
+		if hasher, expected, err = parseTusChecksum(checksum); err != nil {
+			return up, err
+		}
+		body = io.TeeReader(body, hasher)
+	}
+	// 多读1字节用于判断是否超出 Upload-Length
+	remain := up.Length - up.Offset
+	store := upload.NewChunkStore()
+	key, n, readErr := store.PutChunk(fmt.Sprintf("tus_%s_%d", up.UploadID, offset), io.LimitReader(body, remain+1))
+	if key == "" {
+		return up, readErr
+	}
+	discard := func(cause error) (example.ExaTusUpload, error) {
+		if dErr := store.DeleteChunk(key); dErr != nil {
+			global.GVA_LOG.Warn("删除分片失败", zap.String("key", key), zap.Error(dErr))
+		}
+		return up, cause
+	}
+	if n > remain {
+		return discard(ErrTusTooLarge)
+	}
+	if hasher != nil && (readErr != nil || !bytes.Equal(hasher.Sum(nil), expected)) {
+		return discard(ErrTusChecksumMismatch)
+	}
+	// 客户端中断时保留已接收的数据 以便从新的 offset 续传
+	if readErr != nil {
+		global.GVA_LOG.Info("分片接收中断, 保留已接收数据", zap.String("uploadId", id), zap.Int64("size", n), zap.Error(readErr))
+	}
+	if n == 0 {
+		return discard(nil)
+	}
+
+	err = global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&example.ExaTusUpload{}).Where("id = ? AND upload_offset = ?", up.ID, offset).
+			Updates(map[string]interface{}{"upload_offset": offset + n, "expires_at": time.Now().Add(tusExpire())})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTusOffsetMismatch
+		}
+		return tx.Create(&example.ExaTusChunk{UploadID: up.UploadID, Offset: offset, Size: n, Key: key}).Error
+	})
+	if err != nil {
+		return discard(err)
+	}
+	up.Offset = offset + n
+	if up.Offset == up.Length {
+		var file example.ExaFileUploadAndDownload
+		if file, err = e.finishTusUpload(up); err != nil {
+			// 合并或入库失败的上传无法继续 直接清理
+			e.removeTusUpload(up.UploadID)
+			return up, err
+		}
+		up.FileID = file.ID
+	}
+	return up, nil
+}
+
+// finishTusUpload 按顺序拼接数据段 通过媒体库流程(类型校验、配额、去重、缩略图)写入对象存储
+func (e *FileUploadAndDownloadService) finishTusUpload(up example.ExaTusUpload) (file example.ExaFileUploadAndDownload, err error) {
+	var chunks []example.ExaTusChunk
+	if err = global.GVA_DB.Where("upload_id = ?", up.UploadID).Order("chunk_offset").Find(&chunks).Error; err != nil {
+		return file, err
+	}
+	store := upload.NewChunkStore()
+	header, remove, err := upload.StreamFileHeader(up.FileName, mergeMemory, func(w io.Writer) error {
+		var offset int64
+		for _, chunk := range chunks {
+			if chunk.Offset != offset {
+				return fmt.Errorf("数据段不连续, 期望 offset %d 实际 %d", offset, chunk.Offset)
+			}
+			n, err := copyChunk(store, chunk.Key, w, nil)
+			if err != nil {
+				return err
+			}
+			offset += n
+		}
+		return nil
+	})
+	if err != nil {
+		return file, err
+	}
+	defer remove()
+	if header.Size != up.Length {
+		return file, fmt.Errorf("合并后大小 %d 与 Upload-Length %d 不一致", header.Size, up.Length)
+	}
+	if file, err = e.UploadFile(header, "0", up.FolderID, up.UserID, up.AuthorityID); err != nil {
+		return file, err
+	}
+	if err = global.GVA_DB.Model(&example.ExaTusUpload{}).Where("id = ?", up.ID).Update("file_id", file.ID).Error; err != nil {
+		return file, err
+	}
+	e.removeTusChunks(up.UploadID)
+	return file, nil
+}
+
+// mergeMemory 合并分片时在内存中保留的大小 超过的部分写入临时文件
+const mergeMemory = 32 * mb
+
+// copyChunk 将分片写入 w sum 不为空时同时计算哈希
+func copyChunk(store upload.ChunkStore, key string, w io.Writer, sum hash.Hash) (int64, error) {
+	r, err := store.GetChunk(key)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	var src io.Reader = r
+	if sum != nil {
+		src = io.TeeReader(r, sum)
+	}
+	return io.Copy(w, src)
+}
+
+// TerminateTusUpload tus termination: 取消上传并删除已接收的数据
+func (e *FileUploadAndDownloadService) TerminateTusUpload(id string, userID uint) error {
+	unlock := lockUpload(id)
+	defer unlock()
+	if _, err := e.GetTusUpload(id, userID); err != nil {
+		return err
+	}
+	e.removeTusUpload(id)
+	return nil
+}
+
+func (e *FileUploadAndDownloadService) removeTusChunks(id string) {
+	var chunks []example.ExaTusChunk
+	global.GVA_DB.Where("upload_id = ?", id).Find(&chunks)
+	store := upload.NewChunkStore()
+	for _, chunk := range chunks {
+		if err := store.DeleteChunk(chunk.Key); err != nil {
+			global.GVA_LOG.Warn("删除分片失败", zap.String("key", chunk.Key), zap.Error(err))
+		}
+	}
+	global.GVA_DB.Unscoped().Where("upload_id = ?", id).Delete(&example.ExaTusChunk{})
+}
+
+func (e *FileUploadAndDownloadService) removeTusUpload(id string) {
+	e.removeTusChunks(id)
+	global.GVA_DB.Unscoped().Where("upload_id = ?", id).Delete(&example.ExaTusUpload{})
+}
+
+// CleanExpiredUploads 清理过期的 tus 上传与未完成的断点续传分片 由 GVA_Timer 定时调用
+func (e *FileUploadAndDownloadService) CleanExpiredUploads() error {
+	var ids []string
+	err := global.GVA_DB.Model(&example.ExaTusUpload{}).Where("expires_at < ?", time.Now()).Pluck("upload_id", &ids).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		unlock := lockUpload(id)
+		e.removeTusUpload(id)
+		unlock()
+	}
+
+	var files []example.ExaFile
+	err = global.GVA_DB.Where("is_finish = ? AND updated_at < ?", false, time.Now().Add(-tusExpire())).Find(&files).Error
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err = e.removeBreakpointFile(f); err != nil {
+			global.GVA_LOG.Warn("清理断点续传分片失败", zap.String("fileMd5", f.FileMd5), zap.Error(err))
+		}
+	}
+	if len(ids) > 0 || len(files) > 0 {
+		global.GVA_LOG.Info("清理过期上传", zap.Int("tus", len(ids)), zap.Int("breakpoint", len(files)))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package example
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/example"
+)
+
+func openTusTestDB(t *testing.T) string {
+	t.Helper()
+	openLibraryTestDB(t, &example.ExaTusUpload{}, &example.ExaTusChunk{}, &example.ExaFile{}, &example.ExaFileChunk{})
+	tus := global.GVA_CONFIG.Tus
+	t.Cleanup(func() { global.GVA_CONFIG.Tus = tus })
+	global.GVA_CONFIG.Tus.ChunkDir = t.TempDir()
+	global.GVA_CONFIG.Tus.ChunkStore = ""
+	return global.GVA_CONFIG.Tus.ChunkDir
+}
+
+func TestLockUpload(t *testing.T) {
+	unlockA := lockUpload("demo")
+	acquiredB := make(chan func())
+	go func() { acquiredB <- lockUpload("demo") }()
+	// 等待 B 进入等待 持有者在锁内删除上传后 B 仍需等到 A 释放
+	for {
+		tusLocksMu.Lock()
+		refs := tusLocks["demo"].refs
+		tusLocksMu.Unlock()
+		if refs == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	unlockA()
+	unlockB := <-acquiredB
+
+	acquiredC := make(chan func())
+	go func() { acquiredC <- lockUpload("demo") }()
+	select {
+	case <-acquiredC:
+		t.Fatal("a new caller should wait for the current holder")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlockB()
+	(<-acquiredC)()
+
+	tusLocksMu.Lock()
+	defer tusLocksMu.Unlock()
+	if len(tusLocks) != 0 {
+		t.Fatalf("unused locks should be removed: %v", tusLocks)
+	}
+}
+
+func TestFileUploadAndDownloadService_TusExpire(t *testing.T) {
+	dir := openTusTestDB(t)
+	service := new(FileUploadAndDownloadService)
+	up, err := service.CreateTusUpload(10, "filename "+tusBase64("a.txt"), 1, 888)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = service.PatchTusUpload(up.UploadID, 0, "", strings.NewReader("hello"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("received data should be kept as one chunk, got %d", len(entries))
+	}
+
+	global.GVA_DB.Model(&example.ExaTusUpload{}).Where("id = ?", up.ID).Update("expires_at", time.Now().Add(-time.Minute))
+	if _, err = service.GetTusUpload(up.UploadID, 1); !errors.Is(err, ErrTusNotFound) {
+		t.Fatalf("expired upload should not be found: %v", err)
+	}
+	if _, err = service.PatchTusUpload(up.UploadID, 5, "", strings.NewReader("world"), 1); !errors.Is(err, ErrTusNotFound) {
+		t.Fatalf("expired upload should not accept data: %v", err)
+	}
+	if err = service.CleanExpiredUploads(); err != nil {
+		t.Fatal(err)
+	}
+	var uploads, chunks int64
+	global.GVA_DB.Model(&example.ExaTusUpload{}).Count(&uploads)
+	global.GVA_DB.Model(&example.ExaTusChunk{}).Count(&chunks)
+	if uploads != 0 || chunks != 0 {
+		t.Fatalf("expired upload should be removed, got %d uploads %d chunks", uploads, chunks)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("chunks of an expired upload should be deleted: %s", filepath.Join(dir, entries[0].Name()))
+	}
+}
+
+func TestFileUploadAndDownloadService_PatchTusUpload(t *testing.T) {
+	openTusTestDB(t)
+	service := new(FileUploadAndDownloadService)
+	content := []byte("hello world")
+	up, err := service.CreateTusUpload(int64(len(content)), "filename "+tusBase64("a.txt"), 1, 888)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = service.PatchTusUpload(up.UploadID, 0, "", bytes.NewReader(content[:5]), 2); !errors.Is(err, ErrTusNotFound) {
+		t.Fatalf("upload of another user should not be found: %v", err)
+	}
+	if up, err = service.PatchTusUpload(up.UploadID, 0, "", bytes.NewReader(content[:5]), 1); err != nil || up.Offset != 5 {
+		t.Fatalf("PatchTusUpload() = %+v %v", up, err)
+	}
+	if _, err = service.PatchTusUpload(up.UploadID, 5, "", bytes.NewReader(append(content[5:], '!')), 1); !errors.Is(err, ErrTusTooLarge) {
+		t.Fatalf("data beyond Upload-Length should be rejected: %v", err)
+	}
+	if up, err = service.PatchTusUpload(up.UploadID, 5, "", bytes.NewReader(content[5:]), 1); err != nil || up.FileID == 0 {
+		t.Fatalf("completed upload should be saved to the library: %+v %v", up, err)
+	}
+	var file example.ExaFileUploadAndDownload
+	global.GVA_DB.First(&file, up.FileID)
+	if file.Name != "a.txt" || file.Size != int64(len(content)) {
+		t.Fatalf("unexpected library file: %+v", file)
+	}
+	var chunks int64
+	global.GVA_DB.Model(&example.ExaTusChunk{}).Count(&chunks)
+	if chunks != 0 {
+		t.Fatalf("chunks should be removed after merging, got %d", chunks)
+	}
+}
+
+func tusBase64(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
@@ -28,13 +28,13 @@ type autoCodeHistory struct{}
 // Create 创建代码生成器历史记录
 // Author [SliverHorn](https://github.com/SliverHorn)
 // Author [songzhibin97](https://github.com/songzhibin97)
-func (s *autoCodeHistory) Create(ctx context.Context, info request.SysAutoHistoryCreate) error {
+func (s *autoCodeHistory) Create(ctx context.Context, info request.SysAutoHistoryCreate) (uint, error) {
 	create := info.Create()
 	err := global.GVA_DB.WithContext(ctx).Create(&create).Error
 	if err != nil {
-		return errors.Wrap(err, "创建失败!")
+		return 0, errors.Wrap(err, "创建失败!")
 	}
-	return nil
+	return create.ID, nil
 }
 
 // First 根据id获取代码生成器历史的数据
@@ -54,7 +54,9 @@ func (s *autoCodeHistory) First(ctx context.Context, info common.GetById) (strin
 // Author [songzhibin97](https://github.com/songzhibin97)
 func (s *autoCodeHistory) Repeat(businessDB, structName, Package string) bool {
 	var count int64
-	global.GVA_DB.Model(&model.SysAutoCodeHistory{}).Where("business_db = ? and struct_name = ? and package = ? and flag = 0", businessDB, structName, Package).Count(&count)
+	global.GVA_DB.Model(&model.SysAutoCodeHistory{}).Where("business_db = ? and package = ? and flag = 0", businessDB, Package).
+		Where("struct_name = ? or structs like ?", structName, `%"`+structName+`"%`).
+		Count(&count) // 批量导入的全部结构体名称记录在 structs 中
 	return count > 0
 }
 
@@ -67,6 +69,10 @@ func (s *autoCodeHistory) RollBack(ctx context.Context, info request.SysAutoHist
 	if err != nil {
 		return err
 	}
+	err = s.RollbackInjections(history.Injections, history.Hashes, info.Force)
+	if err != nil {
+		return err
+	} // 清除注入代码 注入的代码被手工修改时在删除数据前失败
 	if info.DeleteApi {
 		ids := info.ApiIds(history)
 		err = ApiServiceApp.DeleteApisByIds(ids)
@@ -75,15 +81,27 @@ func (s *autoCodeHistory) RollBack(ctx context.Context, info request.SysAutoHist
 		}
 	} // 清除API表
 	if info.DeleteMenu {
-		err = BaseMenuServiceApp.DeleteBaseMenu(int(history.MenuID))
-		if err != nil {
-			return errors.Wrap(err, "删除菜单失败!")
+		menuIDs := history.MenuIDs
+		if len(menuIDs) == 0 {
+			menuIDs = []uint{history.MenuID}
+		}
+		for _, id := range menuIDs {
+			err = BaseMenuServiceApp.DeleteBaseMenu(int(id))
+			if err != nil {
+				return errors.Wrapf(err, "[id:%d]删除菜单失败!", id)
+			}
 		}
 	} // 清除菜单表
 	if info.DeleteTable {
-		err = s.DropTable(history.BusinessDB, history.Table)
-		if err != nil {
-			return errors.Wrap(err, "删除表失败!")
+		tables := history.Tables
+		if len(tables) == 0 {
+			tables = []string{history.Table}
+		}
+		for _, table := range tables {
+			err = s.DropTable(history.BusinessDB, table)
+			if err != nil {
+				return errors.Wrapf(err, "[table:%s]删除表失败!", table)
+			}
 		}
 	} // 删除表
 	templates := make(map[string]string, len(history.Templates))
@@ -110,9 +128,35 @@ func (s *autoCodeHistory) RollBack(ctx context.Context, info request.SysAutoHist
 		templates[key] = template
 	}
 	history.Templates = templates
-	for key, value := range history.Injections {
+	removeBasePath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, "rm_file", strconv.FormatInt(int64(time.Now().Nanosecond()), 10))
+	for _, value := range history.Templates {
+		if !filepath.IsAbs(value) {
+			continue
+		}
+		removePath := filepath.Join(removeBasePath, strings.TrimPrefix(value, global.GVA_CONFIG.AutoCode.Root))
+		err = utils.FileMove(value, removePath)
+		if err != nil {
+			return errors.Wrapf(err, "[src:%s][dst:%s]文件移动失败!", value, removePath)
+		}
+	} // 移动文件
+	err = global.GVA_DB.WithContext(ctx).Model(&model.SysAutoCodeHistory{}).Where("id = ?", info.ID).Update("flag", 1).Error
+	if err != nil {
+		return errors.Wrap(err, "更新失败!")
+	}
+	OpenApiServiceApp.Invalidate()
+	return nil
+}
+
+// RollbackInjections 回滚注入的代码 injections 为注入类型与序列化后的注入信息
+// hashes 为生成时记录的文件摘要, 文件在生成后被修改且注入的代码无法确认时拒绝回滚, force 为 true 时仍然回滚
+// 全部文件在事务中回滚, 任一注入回滚失败时不写入任何文件, 任一文件写入失败时恢复已写入的文件
+func (s *autoCodeHistory) RollbackInjections(injections map[string]string, hashes map[string]string, force bool) error {
+	tx := ast.NewTransaction(global.GVA_CONFIG.AutoCode.Root)
+	tx.Force = force
+	for key, value := range injections {
 		var injection ast.Ast
-		switch key {
+		kind, _, _ := strings.Cut(key, ":") // 批量导入时以 注入类型:结构体名称 区分
+		switch kind {
 		case ast.TypePackageApiEnter, ast.TypePackageRouterEnter, ast.TypePackageServiceEnter:
 
 		case ast.TypePackageApiModuleEnter, ast.TypePackageRouterModuleEnter, ast.TypePackageServiceModuleEnter:
@@ -143,36 +187,27 @@ func (s *autoCodeHistory) RollBack(ctx context.Context, info request.SysAutoHist
 			var entity ast.PluginInitializeRouter
 			_ = json.Unmarshal([]byte(value), &entity)
 			injection = &entity
+		case ast.TypePluginInitializeV2:
+			var entity ast.PluginInitializeV2
+			_ = json.Unmarshal([]byte(value), &entity)
+			injection = &entity
 		}
 		if injection == nil {
 			continue
 		}
-		file, _ := injection.Parse("", nil)
-		if file != nil {
-			_ = injection.Rollback(file)
-			err = injection.Format("", nil, file)
-			if err != nil {
-				return err
-			}
-			fmt.Printf("[filepath:%s]回滚注入代码成功!\n", key)
-		}
-	} // 清除注入代码
-	removeBasePath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, "rm_file", strconv.FormatInt(int64(time.Now().Nanosecond()), 10))
-	for _, value := range history.Templates {
-		if !filepath.IsAbs(value) {
+		filename := ast.Filename(injection)
+		if filename == "" {
 			continue
 		}
-		removePath := filepath.Join(removeBasePath, strings.TrimPrefix(value, global.GVA_CONFIG.AutoCode.Root))
-		err = utils.FileMove(value, removePath)
-		if err != nil {
-			return errors.Wrapf(err, "[src:%s][dst:%s]文件移动失败!", value, removePath)
+		if err := tx.Expect(filename, hashes[tx.Rel(filename)]); err != nil {
+			return err
 		}
-	} // 移动文件
-	err = global.GVA_DB.WithContext(ctx).Model(&model.SysAutoCodeHistory{}).Where("id = ?", info.ID).Update("flag", 1).Error
-	if err != nil {
-		return errors.Wrap(err, "更新失败!")
+		if err := tx.Revert(injection); err != nil {
+			return errors.Wrapf(err, "[filepath:%s]回滚注入代码失败!", key)
+		}
+		fmt.Printf("[filepath:%s]回滚注入代码成功!\n", key)
 	}
-	return nil
+	return tx.Commit()
 }
 
 // Delete 删除历史数据
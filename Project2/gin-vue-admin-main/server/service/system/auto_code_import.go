@@ -0,0 +1,662 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	utilsAst "github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var AutoCodeImport = new(autoCodeImport)
+
+type autoCodeImport struct{}
+
+// autoCodeImportDictLimit 默认的枚举字段不同值数量上限
+const autoCodeImportDictLimit = 10
+
+// autoCodeImportTypes 数据库类型对应的字段类型 与字典 int bool float64 time.Time 的数据库类型一致 未列出的类型为 string
+var autoCodeImportTypes = map[string]string{
+	"tinyint":          "bool",
+	"bool":             "bool",
+	"boolean":          "bool",
+	"bit":              "bool",
+	"smallint":         "int",
+	"mediumint":        "int",
+	"int":              "int",
+	"integer":          "int",
+	"bigint":           "int",
+	"int2":             "int",
+	"int4":             "int",
+	"int6":             "int",
+	"int8":             "int",
+	"serial":           "int",
+	"bigserial":        "int",
+	"smallserial":      "int",
+	"float":            "float64",
+	"double":           "float64",
+	"double precision": "float64",
+	"decimal":          "float64",
+	"numeric":          "float64",
+	"number":           "float64",
+	"real":             "float64",
+	"money":            "float64",
+	"float4":           "float64",
+	"float8":           "float64",
+	"date":             "time.Time",
+	"time":             "time.Time",
+	"year":             "time.Time",
+	"datetime":         "time.Time",
+	"datetime2":        "time.Time",
+	"smalldatetime":    "time.Time",
+	"datetimeoffset":   "time.Time",
+	"timestamp":        "time.Time",
+	"timestamptz":      "time.Time",
+	"json":             "json",
+	"jsonb":            "json",
+	"enum":             "enum",
+}
+
+// autoCodeImportTexts 长文本类型 不视为枚举
+var autoCodeImportTexts = map[string]bool{"text": true, "tinytext": true, "mediumtext": true, "longtext": true, "ntext": true, "clob": true, "nclob": true, "blob": true, "longblob": true, "mediumblob": true, "tinyblob": true, "bytea": true}
+
+// autoCodeImportGvaColumns GVA_MODEL 对应的字段 全部存在时使用 GvaModel
+var autoCodeImportGvaColumns = []string{"id", "created_at", "updated_at", "deleted_at"}
+
+// autoCodeImportResourceColumns 资源标识对应的字段 全部存在时开启 AutoCreateResource
+var autoCodeImportResourceColumns = []string{"created_by", "updated_by", "deleted_by"}
+
+// autoCodeImportNamer 与 gorm 默认命名规则一致
+var autoCodeImportNamer = schema.NamingStrategy{}
+
+// autoCodeImportDataType 类型中的长度 如 varchar(191) decimal(10,2) timestamp(6)
+var autoCodeImportDataType = regexp.MustCompile(`^([a-z0-9 ]+?)\s*(?:\((.*)\))?(?:\s+unsigned)?$`)
+
+// autoCodeImportTable 检查中的表
+type autoCodeImportTable struct {
+	name        string
+	code        *request.AutoCode
+	columns     []response.Column
+	indexes     []response.Index
+	foreignKeys []response.ForeignKey
+	fields      map[string]*request.AutoCodeField // 数据库字段名 => 字段
+	primary     string                            // 主键字段名 组合主键时为空
+	related     map[string]bool                   // 外键字段 不建议字典
+}
+
+// Inspect 检查业务库中的表 推断每张表的结构化信息、索引、关联关系并为枚举字段建议字典
+// 结果不写入任何数据 确认或修改后通过 Import 批量生成
+func (s *autoCodeImport) Inspect(ctx context.Context, info request.AutoCodeInspect) (result response.AutoCodeInspect, err error) {
+	database := new(AutoCodeService).Database(info.BusinessDB)
+	names := info.Tables
+	if len(names) == 0 {
+		var tables []response.Table
+		tables, err = database.GetTables(info.BusinessDB, info.DbName)
+		if err != nil {
+			return result, errors.Wrap(err, "获取表失败!")
+		}
+		for _, table := range tables {
+			if strings.HasPrefix(table.TableName, "sqlite_") {
+				continue
+			} // sqlite 内部表
+			names = append(names, table.TableName)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return result, errors.New("没有需要导入的表!")
+	}
+
+	tables := make([]*autoCodeImportTable, 0, len(names))
+	byName := make(map[string]*autoCodeImportTable, len(names))
+	for _, name := range names {
+		table := &autoCodeImportTable{name: name}
+		table.columns, err = database.GetColumn(info.BusinessDB, name, info.DbName)
+		if err != nil {
+			return result, errors.Wrapf(err, "[table:%s]获取字段失败!", name)
+		}
+		if len(table.columns) == 0 {
+			return result, errors.Errorf("[table:%s]表不存在或没有字段!", name)
+		}
+		table.indexes, err = database.GetIndexes(info.BusinessDB, name, info.DbName)
+		if err != nil {
+			return result, errors.Wrapf(err, "[table:%s]获取索引失败!", name)
+		}
+		table.foreignKeys, err = database.GetForeignKeys(info.BusinessDB, name, info.DbName)
+		if err != nil {
+			return result, errors.Wrapf(err, "[table:%s]获取外键失败!", name)
+		}
+		result.Warnings = append(result.Warnings, s.table(info, table)...)
+		if AutocodeHistory.Repeat(info.BusinessDB, table.code.StructName, info.Package) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("[table:%s]结构体%s已经创建过, 导入前请移除或重命名", name, table.code.StructName))
+		}
+		tables = append(tables, table)
+		byName[strings.ToLower(name)] = table
+	}
+	for _, table := range tables {
+		result.Warnings = append(result.Warnings, s.relations(info, table, byName)...)
+	}
+
+	if info.DictLimit == 0 {
+		info.DictLimit = autoCodeImportDictLimit
+	}
+	for _, table := range tables {
+		if info.DictLimit > 0 {
+			dictionaries, warnings := s.dictionaries(ctx, info, table)
+			result.Dictionaries = append(result.Dictionaries, dictionaries...)
+			result.Warnings = append(result.Warnings, warnings...)
+		}
+		result.Codes = append(result.Codes, *table.code)
+	}
+	return result, nil
+}
+
+// Import 批量生成 全部表的代码在一个事务中生成与注入 创建引用的字典 只记录一条历史 回滚时一并回滚
+func (s *autoCodeImport) Import(ctx context.Context, info request.AutoCodeImport) ([]utilsAst.Change, error) {
+	if len(info.Codes) == 0 {
+		return nil, errors.New("没有需要生成的表!")
+	}
+	var autoPkg model.SysAutoCodePackage
+	err := global.GVA_DB.WithContext(ctx).Where("package_name = ?", info.Package).First(&autoPkg).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "查询包失败!")
+	}
+	structs := make(map[string]bool, len(info.Codes))
+	for _, code := range info.Codes {
+		if structs[code.StructName] {
+			return nil, errors.Errorf("[%s]结构体名称重复!", code.StructName)
+		}
+		structs[code.StructName] = true
+		if AutocodeHistory.Repeat(info.BusinessDB, code.StructName, info.Package) {
+			return nil, errors.Errorf("[%s]已经创建过此数据结构,请勿重复创建!", code.StructName)
+		}
+	}
+
+	tx := utilsAst.NewTransaction(global.GVA_CONFIG.AutoCode.Root)
+	templates := make(map[string]string)
+	injections := make(map[string]utilsAst.Ast)
+	for _, code := range info.Codes {
+		generated, injected, err := AutoCodeTemplate.stageIn(ctx, tx, code, autoPkg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[%s]生成失败!", code.StructName)
+		}
+		for key, value := range generated {
+			templates[key+":"+code.StructName] = value
+		}
+		for key, value := range injected {
+			injections[key+":"+code.StructName] = value
+		}
+	} // 多个结构体注入同一文件时依次注入 回滚时按 注入类型:结构体名称 逐个回滚
+	changes, err := tx.Changes()
+	if err != nil || info.DryRun {
+		return changes, err
+	}
+
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+		for _, dictionary := range s.referenced(info) {
+			var count int64
+			if err := db.Model(&model.SysDictionary{}).Where("type = ?", dictionary.Type).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			} // 已存在的字典直接使用
+			status := true
+			entity := model.SysDictionary{Name: dictionary.Name, Type: dictionary.Type, Status: &status, Desc: fmt.Sprintf("%s.%s", dictionary.Table, dictionary.Column)}
+			for i, value := range dictionary.Values {
+				label := value.Label
+				if label == "" {
+					label = value.Value
+				}
+				entity.SysDictionaryDetails = append(entity.SysDictionaryDetails, model.SysDictionaryDetail{Label: label, Value: value.Value, Status: &status, Sort: i + 1})
+			}
+			if err := db.Create(&entity).Error; err != nil {
+				return errors.Wrapf(err, "[type:%s]创建字典失败!", dictionary.Type)
+			}
+		}
+		return tx.Commit()
+	}) // 文件写入失败时不保留字典
+	if err != nil {
+		return nil, err
+	}
+
+	// 代码写入后立即记录历史 创建api或菜单失败时仍可通过历史回滚已写入的代码
+	history := info.History()
+	history.Templates = templates
+	history.Hashes = tx.Hashes()
+	history.Injections = make(map[string]string, len(injections))
+	for key, value := range injections {
+		bytes, _ := json.Marshal(value)
+		history.Injections[key] = string(bytes)
+	}
+	id, err := AutocodeHistory.Create(ctx, history)
+	if err != nil {
+		return nil, err
+	}
+
+	var created model.SysAutoCodeHistory
+	for _, code := range info.Codes {
+		if code.AutoCreateApiToSql {
+			var ids []uint
+			if ids, err = AutoCodeTemplate.createApis(ctx, code); err != nil {
+				break
+			}
+			created.ApiIDs = append(created.ApiIDs, ids...)
+		}
+		if code.AutoCreateMenuToSql {
+			var menuID uint
+			if menuID, err = AutoCodeTemplate.createMenu(ctx, code, autoPkg); err != nil {
+				break
+			}
+			created.MenuIDs = append(created.MenuIDs, menuID)
+		}
+	}
+	// 失败时同样记录已创建的api与菜单 回滚时一并删除
+	if uErr := global.GVA_DB.WithContext(ctx).Model(&model.SysAutoCodeHistory{}).Where("id = ?", id).Select("api_ids", "menu_ids").Updates(&created).Error; uErr != nil && err == nil {
+		err = errors.Wrap(uErr, "更新历史失败!")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// table 推断表的结构化信息 返回无法推断的索引等提示
+func (s *autoCodeImport) table(info request.AutoCodeInspect, table *autoCodeImportTable) (warnings []string) {
+	columns := make(map[string]bool, len(table.columns))
+	primaries := 0
+	for _, column := range table.columns {
+		columns[strings.ToLower(column.ColumnName)] = true
+		if column.PrimaryKey {
+			primaries++
+			table.primary = strings.ToLower(column.ColumnName)
+		}
+	}
+	if primaries != 1 {
+		table.primary = ""
+	}
+	hump := s.hump(strings.TrimPrefix(table.name, info.TablePrefix))
+	code := &request.AutoCode{
+		Package:             info.Package,
+		TableName:           table.name,
+		BusinessDB:          info.BusinessDB,
+		StructName:          utils.FirstUpper(hump),
+		PackageName:         hump,
+		Abbreviation:        hump,
+		HumpPackageName:     autoCodeImportNamer.ColumnName("", hump),
+		Description:         hump + "表",
+		GvaModel:            s.contains(columns, autoCodeImportGvaColumns) && table.primary == "id",
+		AutoMigrate:         true,
+		AutoCreateApiToSql:  true,
+		AutoCreateMenuToSql: true,
+	}
+	code.AutoCreateResource = code.GvaModel && s.contains(columns, autoCodeImportResourceColumns)
+	skips := make(map[string]bool)
+	if code.GvaModel {
+		for _, column := range autoCodeImportGvaColumns {
+			skips[column] = true
+		}
+	}
+	if code.AutoCreateResource {
+		for _, column := range autoCodeImportResourceColumns {
+			skips[column] = true
+		}
+	}
+
+	indexes := make(map[string][]response.Index)
+	for _, index := range table.indexes {
+		indexes[index.IndexName] = append(indexes[index.IndexName], index)
+	}
+	indexTypes := make(map[string]string)
+	for name, index := range indexes {
+		if index[0].PrimaryKey {
+			continue
+		}
+		if len(index) > 1 {
+			warnings = append(warnings, fmt.Sprintf("[table:%s]组合索引%s不支持推断, 请在生成的代码中手动添加", table.name, name))
+			continue
+		}
+		kind := "index"
+		if index[0].Unique {
+			kind = "uniqueIndex"
+		}
+		indexTypes[strings.ToLower(index[0].ColumnName)] = kind + ":" + name // 使用已有的索引名 自动迁移时不重复创建
+	}
+
+	dbType := s.dbType(info.BusinessDB)
+	table.fields = make(map[string]*request.AutoCodeField, len(table.columns))
+	for _, column := range table.columns {
+		name := strings.ToLower(column.ColumnName)
+		if skips[name] {
+			continue
+		}
+		dataType, length := s.dataType(column)
+		fieldType, ok := autoCodeImportTypes[dataType]
+		if !ok {
+			fieldType = "string"
+		}
+		switch fieldType {
+		case "string":
+			if autoCodeImportTexts[dataType] {
+				length = ""
+			}
+		case "enum":
+		default:
+			length = "" // gorm 的 size 对数字类型为位数 不使用数据库中的精度
+		}
+		fieldJson := s.hump(name)
+		comment := s.comment(column.ColumnComment)
+		desc := comment
+		if desc == "" {
+			desc = fieldJson + "字段"
+		}
+		field := &request.AutoCodeField{
+			FieldName:      utils.FirstUpper(fieldJson),
+			FieldDesc:      desc,
+			FieldType:      fieldType,
+			FieldJson:      fieldJson,
+			DataTypeLong:   length,
+			Comment:        comment,
+			ColumnName:     column.ColumnName,
+			DefaultValue:   s.defaultValue(dbType, fieldType, column.DefaultValue),
+			Front:          true,
+			Clearable:      column.Nullable,
+			PrimaryKey:     column.PrimaryKey && !code.GvaModel,
+			FieldIndexType: indexTypes[name],
+		}
+		field.Require = !column.Nullable && !column.PrimaryKey && strings.TrimSpace(column.DefaultValue) == ""
+		if dbType == "oracle" {
+			field.ColumnName = strings.ToUpper(column.ColumnName)
+		}
+		code.Fields = append(code.Fields, field)
+		table.fields[name] = field
+	}
+	table.code = code
+	return warnings
+}
+
+// relations 根据外键约束与 xxx_id 命名约定推断 belongsTo 关联 只关联同一批导入的表
+func (s *autoCodeImport) relations(info request.AutoCodeInspect, table *autoCodeImportTable, byName map[string]*autoCodeImportTable) (warnings []string) {
+	table.related = make(map[string]bool)
+	constraints := make(map[string][]response.ForeignKey)
+	var order []string
+	for _, foreignKey := range table.foreignKeys {
+		if _, ok := constraints[foreignKey.ConstraintName]; !ok {
+			order = append(order, foreignKey.ConstraintName)
+		}
+		constraints[foreignKey.ConstraintName] = append(constraints[foreignKey.ConstraintName], foreignKey)
+	}
+	var foreignKeys []response.ForeignKey
+	for _, name := range order {
+		if len(constraints[name]) > 1 {
+			warnings = append(warnings, fmt.Sprintf("[table:%s]组合外键%s不支持推断关联关系", table.name, name))
+			continue
+		}
+		foreignKeys = append(foreignKeys, constraints[name][0])
+		table.related[strings.ToLower(constraints[name][0].ColumnName)] = true
+	}
+	for _, column := range table.columns {
+		name := strings.ToLower(column.ColumnName)
+		if column.PrimaryKey || table.related[name] || !strings.HasSuffix(name, "_id") {
+			continue
+		}
+		base := strings.TrimSuffix(name, "_id")
+		for _, candidate := range []string{base, base + "s", base + "es", info.TablePrefix + base, info.TablePrefix + base + "s", info.TablePrefix + base + "es"} {
+			if reference, ok := byName[strings.ToLower(candidate)]; ok && reference.primary != "" {
+				foreignKeys = append(foreignKeys, response.ForeignKey{ColumnName: column.ColumnName, ReferencedTable: reference.name})
+				table.related[name] = true
+				warnings = append(warnings, fmt.Sprintf("[table:%s]字段%s按命名约定推断关联%s, 请确认", table.name, column.ColumnName, reference.name))
+				break
+			}
+		}
+	} // 没有外键约束时按命名约定推断
+
+	names := make(map[string]bool, len(table.code.Fields))
+	for _, field := range table.code.Fields {
+		names[field.FieldName] = true
+	}
+	for _, foreignKey := range foreignKeys {
+		field := table.fields[strings.ToLower(foreignKey.ColumnName)]
+		if field == nil {
+			continue
+		}
+		reference, ok := byName[strings.ToLower(foreignKey.ReferencedTable)]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("[table:%s]字段%s关联的表%s未导入, 未生成关联关系", table.name, foreignKey.ColumnName, foreignKey.ReferencedTable))
+			continue
+		}
+		column := strings.ToLower(foreignKey.ReferencedColumn)
+		if column == "" {
+			column = reference.primary
+		}
+		references := "ID"
+		if !reference.code.GvaModel || column != "id" {
+			referenced := reference.fields[column]
+			if referenced == nil {
+				warnings = append(warnings, fmt.Sprintf("[table:%s]字段%s引用的%s.%s不存在, 未生成关联关系", table.name, foreignKey.ColumnName, reference.name, foreignKey.ReferencedColumn))
+				continue
+			}
+			references = referenced.FieldName
+			field.FieldType = referenced.FieldType
+		} else {
+			field.FieldType = "uint" // 与 GVA_MODEL 的 ID 类型一致
+		}
+		name := strings.TrimSuffix(field.FieldName, "Id")
+		if name == "" || name == field.FieldName || names[name] {
+			name = field.FieldName + "Info"
+		}
+		names[name] = true
+		relation := &request.AutoCodeRelation{
+			Type:       request.RelationBelongsTo,
+			StructName: reference.code.StructName,
+			Table:      reference.name,
+			ForeignKey: field.FieldName,
+			References: references,
+		}
+		if onDelete := strings.ToUpper(strings.ReplaceAll(foreignKey.OnDelete, "_", " ")); onDelete == "CASCADE" || onDelete == "SET NULL" {
+			relation.OnDelete = onDelete
+		} // RESTRICT 与 NO ACTION 为数据库默认行为
+		table.code.Fields = append(table.code.Fields, &request.AutoCodeField{
+			FieldName: name,
+			FieldDesc: reference.code.Description,
+			FieldJson: utils.FirstLower(name),
+			Relation:  relation,
+		})
+	}
+	return warnings
+}
+
+// dictionaries 为枚举类型与不同值较少的字段建议字典 返回查询失败的提示
+func (s *autoCodeImport) dictionaries(ctx context.Context, info request.AutoCodeInspect, table *autoCodeImportTable) (dictionaries []request.AutoCodeImportDict, warnings []string) {
+	db := AutoCodeMigration.db(info.BusinessDB).WithContext(ctx)
+	name := table.name
+	if info.DbName != "" && s.dbType(info.BusinessDB) == "mysql" {
+		name = info.DbName + "." + table.name
+	} // mysql 可以检查连接之外的数据库
+	for _, column := range table.columns {
+		field := table.fields[strings.ToLower(column.ColumnName)]
+		if field == nil || field.PrimaryKey || table.related[strings.ToLower(column.ColumnName)] || strings.HasPrefix(field.FieldIndexType, "uniqueIndex") {
+			continue
+		}
+		dataType, _ := s.dataType(column)
+		var values []string
+		switch {
+		case field.FieldType == "enum":
+			values = s.enumValues(field.DataTypeLong)
+		case field.FieldType == "string" && !autoCodeImportTexts[dataType], field.FieldType == "int":
+			var rows []struct {
+				DictValue *string `gorm:"column:dict_value"`
+				DictTotal int64   `gorm:"column:dict_total"`
+			}
+			err := db.Table(name).Select("? AS dict_value, COUNT(*) AS dict_total", clause.Column{Name: column.ColumnName}).
+				Group(column.ColumnName).Limit(info.DictLimit + 1).Scan(&rows).Error
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("[table:%s]字段%s统计不同值失败: %v", table.name, column.ColumnName, err))
+				continue
+			}
+			var total int64
+			for _, row := range rows {
+				total += row.DictTotal
+				if row.DictValue != nil && *row.DictValue != "" {
+					values = append(values, *row.DictValue)
+				}
+			}
+			if len(rows) > info.DictLimit || total <= int64(len(rows)) {
+				continue
+			} // 不同值过多或没有重复值时不视为枚举
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if field.FieldType == "int" {
+			sort.Slice(values, func(i, j int) bool {
+				a, _ := strconv.Atoi(values[i])
+				b, _ := strconv.Atoi(values[j])
+				return a < b
+			})
+		} else if field.FieldType != "enum" {
+			sort.Strings(values)
+		}
+		dictionary := request.AutoCodeImportDict{
+			Table:  table.name,
+			Column: column.ColumnName,
+			Name:   field.FieldDesc,
+			Type:   autoCodeImportNamer.ColumnName("", table.code.StructName+field.FieldName),
+		}
+		for _, value := range values {
+			dictionary.Values = append(dictionary.Values, request.AutoCodeImportDictValue{Label: value, Value: value})
+		}
+		var count int64
+		global.GVA_DB.WithContext(ctx).Model(&model.SysDictionary{}).Where("type = ?", dictionary.Type).Count(&count)
+		dictionary.Exists = count > 0
+		field.DictType = dictionary.Type
+		dictionaries = append(dictionaries, dictionary)
+	}
+	return dictionaries, warnings
+}
+
+// referenced 被字段引用的字典
+func (s *autoCodeImport) referenced(info request.AutoCodeImport) []request.AutoCodeImportDict {
+	types := make(map[string]bool)
+	for _, code := range info.Codes {
+		for _, field := range code.Fields {
+			if field.DictType != "" {
+				types[field.DictType] = true
+			}
+		}
+	}
+	dictionaries := make([]request.AutoCodeImportDict, 0, len(info.Dictionaries))
+	for _, dictionary := range info.Dictionaries {
+		if types[dictionary.Type] {
+			dictionaries = append(dictionaries, dictionary)
+		}
+	}
+	return dictionaries
+}
+
+// dataType 小写的基础类型与长度 如 varchar(191) => varchar 191
+func (s *autoCodeImport) dataType(column response.Column) (string, string) {
+	dataType := strings.ToLower(strings.TrimSpace(column.DataType))
+	length := column.DataTypeLong
+	if matches := autoCodeImportDataType.FindStringSubmatch(dataType); matches != nil {
+		dataType = matches[1]
+		if length == "" {
+			length = matches[2]
+		}
+	}
+	if length == "0" || length == "-1" {
+		length = "" // mssql 的 max 长度为 -1
+	}
+	return dataType, length
+}
+
+// defaultValue 只保留数字、布尔与字符串字面量 函数与序列等数据库表达式不写入 gorm 标签
+func (s *autoCodeImport) defaultValue(dbType string, fieldType string, value string) string {
+	value = strings.TrimSpace(value)
+	for len(value) > 1 && value[0] == '(' && value[len(value)-1] == ')' {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	} // mssql ((0)) ('a')
+	if index := strings.Index(value, "::"); index > 0 && strings.HasPrefix(value, "'") {
+		value = value[:index]
+	} // pgsql 'a'::character varying
+	switch {
+	case value == "" || strings.EqualFold(value, "null"):
+		return ""
+	case len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'':
+		if fieldType == "string" || fieldType == "enum" {
+			return value
+		}
+		value = value[1 : len(value)-1]
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil && fieldType != "string" && fieldType != "enum" {
+		return value
+	}
+	if fieldType == "bool" && (strings.EqualFold(value, "true") || strings.EqualFold(value, "false")) {
+		return strings.ToLower(value)
+	}
+	if dbType == "mysql" && (fieldType == "string" || fieldType == "enum") && !strings.ContainsAny(value, "'();") {
+		return "'" + value + "'"
+	} // mysql 的字符串默认值不带引号
+	return ""
+}
+
+// enumValues 解析 'a','b' 形式的枚举值
+func (s *autoCodeImport) enumValues(values string) []string {
+	var result []string
+	for _, value := range strings.Split(values, ",") {
+		value = strings.Trim(strings.TrimSpace(value), "'")
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// comment 去掉会破坏结构体标签与注释的字符
+func (s *autoCodeImport) comment(comment string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ", "\"", "", "`", "", ";", " ").Replace(comment))
+}
+
+// hump 下划线命名转为小驼峰 与前端从数据库创建时的命名一致
+func (s *autoCodeImport) hump(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' || r == '.' })
+	for i := range words {
+		if i == 0 {
+			words[i] = utils.FirstLower(words[i])
+			continue
+		}
+		words[i] = utils.FirstUpper(words[i])
+	}
+	return strings.Join(words, "")
+}
+
+func (s *autoCodeImport) contains(columns map[string]bool, names []string) bool {
+	for _, name := range names {
+		if !columns[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// dbType 业务库的数据库类型
+func (s *autoCodeImport) dbType(businessDB string) string {
+	if businessDB == "" {
+		return global.GVA_CONFIG.System.DbType
+	}
+	info, _ := global.GetGlobalDBInfo(businessDB)
+	return info.Type
+}
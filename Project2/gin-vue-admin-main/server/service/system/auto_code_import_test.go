@@ -0,0 +1,52 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+)
+
+func Test_autoCodeImport_dataType(t *testing.T) {
+	tests := []struct {
+		column   response.Column
+		dataType string
+		length   string
+	}{
+		{response.Column{DataType: "varchar", DataTypeLong: "191"}, "varchar", "191"},
+		{response.Column{DataType: "VARCHAR(64)"}, "varchar", "64"},
+		{response.Column{DataType: "decimal(10,2)"}, "decimal", "10,2"},
+		{response.Column{DataType: "bigint unsigned"}, "bigint", ""},
+		{response.Column{DataType: "double precision"}, "double precision", ""},
+		{response.Column{DataType: "nvarchar", DataTypeLong: "-1"}, "nvarchar", ""},
+	}
+	for _, tt := range tests {
+		dataType, length := AutoCodeImport.dataType(tt.column)
+		if dataType != tt.dataType || length != tt.length {
+			t.Errorf("dataType(%+v) = %q %q, want %q %q", tt.column, dataType, length, tt.dataType, tt.length)
+		}
+	}
+}
+
+func Test_autoCodeImport_defaultValue(t *testing.T) {
+	tests := []struct {
+		dbType    string
+		fieldType string
+		value     string
+		want      string
+	}{
+		{"mysql", "string", "on", "'on'"},
+		{"mysql", "time.Time", "CURRENT_TIMESTAMP", ""},
+		{"pgsql", "string", "'on'::character varying", "'on'"},
+		{"pgsql", "int", "nextval('users_id_seq'::regclass)", ""},
+		{"mssql", "int", "((0))", "0"},
+		{"mssql", "string", "('on')", "'on'"},
+		{"sqlite", "bool", "true", "true"},
+		{"sqlite", "float64", "'1.5'", "1.5"},
+		{"sqlite", "string", "NULL", ""},
+	}
+	for _, tt := range tests {
+		if got := AutoCodeImport.defaultValue(tt.dbType, tt.fieldType, tt.value); got != tt.want {
+			t.Errorf("defaultValue(%s, %s, %s) = %q, want %q", tt.dbType, tt.fieldType, tt.value, got, tt.want)
+		}
+	}
+}
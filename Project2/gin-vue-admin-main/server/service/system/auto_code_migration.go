@@ -0,0 +1,326 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var AutoCodeMigration = new(autoCodeMigration)
+
+type autoCodeMigration struct{}
+
+// autoCodeFieldTypes 代码生成字段类型对应的Go类型 与 model.go.tpl 保持一致
+var autoCodeFieldTypes = map[string]reflect.Type{
+	"string":    reflect.TypeOf(""),
+	"enum":      reflect.TypeOf(""),
+	"picture":   reflect.TypeOf(""),
+	"video":     reflect.TypeOf(""),
+	"richtext":  reflect.TypeOf(""),
+	"file":      reflect.TypeOf(datatypes.JSON{}),
+	"pictures":  reflect.TypeOf(datatypes.JSON{}),
+	"json":      reflect.TypeOf(datatypes.JSON{}),
+	"array":     reflect.TypeOf(datatypes.JSON{}),
+	"int":       reflect.TypeOf(new(int)),
+	"uint":      reflect.TypeOf(new(uint)),
+	"bool":      reflect.TypeOf(new(bool)),
+	"float64":   reflect.TypeOf(new(float64)),
+	"time.Time": reflect.TypeOf(new(time.Time)),
+}
+
+// Diff 比较两个版本的结构化信息 生成迁移与回退操作
+func (s *autoCodeMigration) Diff(from, to request.AutoCode) (up []model.AutoCodeMigrationOp, down []model.AutoCodeMigrationOp, err error) {
+	db := s.db(to.BusinessDB)
+	table := s.table(db, to)
+	fromIndexes, err := s.indexes(db, table, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	toIndexes, err := s.indexes(db, table, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	fromFields := make(map[string]*request.AutoCodeField, len(from.Fields))
+	for _, field := range from.Fields {
+		if field.Relation == nil {
+			fromFields[field.ColumnName] = field
+		}
+	}
+	toFields := make(map[string]*request.AutoCodeField, len(to.Fields))
+	for _, field := range to.Fields {
+		if field.Relation == nil {
+			toFields[field.ColumnName] = field
+		}
+	}
+
+	// 字段名不变而列名变化时视为重命名 保留字段中的数据
+	toNames := make(map[string]*request.AutoCodeField, len(toFields))
+	for _, field := range toFields {
+		toNames[field.FieldName] = field
+	}
+	renames := make(map[string]string) // 原列名 => 新列名
+	renamed := make(map[string]string) // 新列名 => 原列名
+	for column, field := range fromFields {
+		if _, ok := toFields[column]; ok {
+			continue
+		}
+		if next, ok := toNames[field.FieldName]; ok {
+			if _, exists := fromFields[next.ColumnName]; !exists {
+				renames[column], renamed[next.ColumnName] = next.ColumnName, column
+			}
+		}
+	}
+
+	var dropIndexes, dropColumns, renameColumns, addColumns, alterColumns, createIndexes []model.AutoCodeMigrationOp
+	for _, field := range from.Fields {
+		if field.Relation != nil {
+			continue
+		} // 关联关系由 gorm 根据模型维护
+		column, rename := renames[field.ColumnName]
+		if !rename {
+			column = field.ColumnName
+		}
+		next, ok := toFields[column]
+		if field.FieldIndexType != "" && (!ok || next.FieldIndexType != field.FieldIndexType) {
+			dropIndexes = append(dropIndexes, model.AutoCodeMigrationOp{Action: model.AutoCodeMigrationDropIndex, Column: field.ColumnName, Index: fromIndexes[field.ColumnName]})
+		} // 索引名由字段名生成 重命名列时索引随列保留
+		switch {
+		case rename:
+			renameColumns = append(renameColumns, model.AutoCodeMigrationOp{Action: model.AutoCodeMigrationRenameColumn, Column: column, From: field.ColumnName})
+		case !ok:
+			dropColumns = append(dropColumns, model.AutoCodeMigrationOp{Action: model.AutoCodeMigrationDropColumn, Column: field.ColumnName})
+		}
+	}
+	for _, field := range to.Fields {
+		if field.Relation != nil {
+			continue
+		}
+		column, rename := renamed[field.ColumnName]
+		if !rename {
+			column = field.ColumnName
+		}
+		prev, ok := fromFields[column]
+		if !ok {
+			addColumns = append(addColumns, model.AutoCodeMigrationOp{Action: model.AutoCodeMigrationAddColumn, Column: field.ColumnName})
+		} else if prev.FieldType != field.FieldType || prev.DataTypeLong != field.DataTypeLong || prev.DefaultValue != field.DefaultValue || prev.Comment != field.Comment {
+			alterColumns = append(alterColumns, model.AutoCodeMigrationOp{Action: model.AutoCodeMigrationAlterColumn, Column: field.ColumnName})
+		}
+		if field.FieldIndexType != "" && (!ok || prev.FieldIndexType != field.FieldIndexType) {
+			createIndexes = append(createIndexes, model.AutoCodeMigrationOp{Action: model.AutoCodeMigrationCreateIndex, Column: field.ColumnName, Index: toIndexes[field.ColumnName]})
+		}
+	}
+	for _, ops := range [][]model.AutoCodeMigrationOp{dropIndexes, dropColumns, renameColumns, addColumns, alterColumns, createIndexes} {
+		up = append(up, ops...)
+	}
+	for i := len(up) - 1; i >= 0; i-- {
+		op := up[i]
+		switch op.Action {
+		case model.AutoCodeMigrationAddColumn:
+			op.Action = model.AutoCodeMigrationDropColumn
+		case model.AutoCodeMigrationDropColumn:
+			op.Action = model.AutoCodeMigrationAddColumn
+		case model.AutoCodeMigrationRenameColumn:
+			op.Column, op.From = op.From, op.Column
+		case model.AutoCodeMigrationCreateIndex:
+			op.Action = model.AutoCodeMigrationDropIndex
+		case model.AutoCodeMigrationDropIndex:
+			op.Action = model.AutoCodeMigrationCreateIndex
+		}
+		down = append(down, op)
+	} // 回退操作为迁移操作的逆序逆操作
+	return up, down, nil
+}
+
+// Apply 执行迁移
+func (s *autoCodeMigration) Apply(ctx context.Context, id uint) error {
+	return s.execute(ctx, id, false)
+}
+
+// Revert 回退迁移 已删除字段中的数据无法恢复
+func (s *autoCodeMigration) Revert(ctx context.Context, id uint) error {
+	return s.execute(ctx, id, true)
+}
+
+// GetList 获取迁移记录
+func (s *autoCodeMigration) GetList(ctx context.Context, info request.SysAutoCodeMigrationSearch) (list []model.SysAutoCodeMigration, total int64, err error) {
+	db := global.GVA_DB.WithContext(ctx).Model(&model.SysAutoCodeMigration{})
+	if info.HistoryID != 0 {
+		db = db.Where("history_id = ?", info.HistoryID)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return nil, total, err
+	}
+	err = db.Scopes(info.Paginate()).Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+func (s *autoCodeMigration) execute(ctx context.Context, id uint, revert bool) error {
+	var entity model.SysAutoCodeMigration
+	err := global.GVA_DB.WithContext(ctx).First(&entity, "id = ?", id).Error
+	if err != nil {
+		return errors.Wrap(err, "查询迁移失败!")
+	}
+	spec, ops, status := entity.To, entity.Up, model.AutoCodeMigrationApplied
+	if revert {
+		if entity.Status != model.AutoCodeMigrationApplied {
+			return errors.New("迁移未执行, 无需回退!")
+		}
+		spec, ops, status = entity.From, entity.Down, model.AutoCodeMigrationReverted
+	} else if entity.Status == model.AutoCodeMigrationApplied {
+		return errors.New("迁移已执行!")
+	}
+	var info request.AutoCode
+	if err = json.Unmarshal([]byte(spec), &info); err != nil {
+		return errors.Wrap(err, "解析结构化信息失败!")
+	}
+	if err = info.Pretreatment(); err != nil {
+		return err
+	}
+	runErr := s.run(s.db(entity.BusinessDB), entity.Table, info, ops)
+	updates := map[string]interface{}{"status": status, "error": "", "applied_at": time.Now()}
+	if runErr != nil {
+		updates = map[string]interface{}{"status": model.AutoCodeMigrationFailed, "error": runErr.Error()}
+	}
+	err = global.GVA_DB.WithContext(ctx).Model(&model.SysAutoCodeMigration{}).Where("id = ?", id).Updates(updates).Error
+	if runErr != nil {
+		return runErr
+	}
+	return err
+}
+
+// run 依次执行迁移操作 已满足的操作会被跳过 失败后可重复执行
+func (s *autoCodeMigration) run(db *gorm.DB, table string, info request.AutoCode, ops []model.AutoCodeMigrationOp) error {
+	value, err := s.model(info)
+	if err != nil {
+		return err
+	}
+	migrator := db.Table(table).Migrator()
+	for _, op := range ops {
+		switch op.Action {
+		case model.AutoCodeMigrationAddColumn:
+			if !migrator.HasColumn(value, op.Column) {
+				err = migrator.AddColumn(value, op.Column)
+			}
+		case model.AutoCodeMigrationAlterColumn:
+			err = migrator.AlterColumn(value, op.Column)
+		case model.AutoCodeMigrationRenameColumn:
+			if migrator.HasColumn(value, op.From) && !migrator.HasColumn(value, op.Column) {
+				err = migrator.RenameColumn(value, op.From, op.Column)
+			}
+		case model.AutoCodeMigrationDropColumn:
+			if migrator.HasColumn(value, op.Column) {
+				err = migrator.DropColumn(value, op.Column)
+			}
+		case model.AutoCodeMigrationCreateIndex:
+			if !migrator.HasIndex(value, op.Index) {
+				err = migrator.CreateIndex(value, op.Index)
+			}
+		case model.AutoCodeMigrationDropIndex:
+			if migrator.HasIndex(value, op.Index) {
+				err = migrator.DropIndex(value, op.Index)
+			}
+		default:
+			err = fmt.Errorf("未知的迁移操作: %s", op.Action)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "[%s:%s]迁移失败!", op.Action, op.Column)
+		}
+	}
+	return nil
+}
+
+// model 根据结构化信息构造与生成代码等价的结构体 供 gorm 迁移使用
+func (s *autoCodeMigration) model(info request.AutoCode) (interface{}, error) {
+	fields := make([]reflect.StructField, 0, len(info.Fields)+1)
+	if info.GvaModel {
+		fields = append(fields, reflect.StructField{Name: "GVA_MODEL", Type: reflect.TypeOf(global.GVA_MODEL{}), Anonymous: true})
+	}
+	for _, field := range info.Fields {
+		if field.Relation != nil {
+			continue
+		}
+		typ, ok := autoCodeFieldTypes[field.FieldType]
+		if !ok {
+			return nil, fmt.Errorf("[%s]不支持的字段类型: %s", field.FieldName, field.FieldType)
+		}
+		fields = append(fields, reflect.StructField{
+			Name: field.FieldName,
+			Type: typ,
+			Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"%s"`, s.tag(field))),
+		})
+	}
+	return reflect.New(reflect.StructOf(fields)).Interface(), nil
+}
+
+// tag 与 model.go.tpl 生成的 gorm 标签一致
+func (s *autoCodeMigration) tag(field *request.AutoCodeField) string {
+	var builder strings.Builder
+	if field.FieldIndexType != "" {
+		builder.WriteString(field.FieldIndexType + ";")
+	}
+	if field.PrimaryKey {
+		builder.WriteString("primarykey;")
+	}
+	if field.DefaultValue != "" {
+		builder.WriteString("default:" + field.DefaultValue + ";")
+	}
+	builder.WriteString("column:" + field.ColumnName + ";")
+	if field.FieldType == "enum" {
+		builder.WriteString("type:enum(" + field.DataTypeLong + ");")
+	}
+	builder.WriteString("comment:" + field.Comment + ";")
+	if field.FieldType != "enum" && field.DataTypeLong != "" {
+		builder.WriteString("size:" + field.DataTypeLong + ";")
+	}
+	switch field.FieldType {
+	case "richtext", "json", "array":
+		builder.WriteString("type:text;")
+	}
+	return builder.String()
+}
+
+// indexes 字段名与 gorm 生成的索引名的对应关系
+func (s *autoCodeMigration) indexes(db *gorm.DB, table string, info request.AutoCode) (map[string]string, error) {
+	value, err := s.model(info)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := schema.ParseWithSpecialTableName(value, &sync.Map{}, db.NamingStrategy, table)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[string]string)
+	for name, index := range parsed.ParseIndexes() {
+		for _, option := range index.Fields {
+			indexes[option.DBName] = name
+		}
+	}
+	return indexes, nil
+}
+
+func (s *autoCodeMigration) db(businessDB string) *gorm.DB {
+	if businessDB != "" {
+		return global.MustGetGlobalDBByDBName(businessDB)
+	}
+	return global.GVA_DB
+}
+
+func (s *autoCodeMigration) table(db *gorm.DB, info request.AutoCode) string {
+	if info.TableName != "" {
+		return info.TableName
+	}
+	return db.NamingStrategy.TableName(info.StructName)
+}
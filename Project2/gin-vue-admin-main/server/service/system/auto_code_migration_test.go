@@ -0,0 +1,81 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+)
+
+func migrationTestCode(fields ...*request.AutoCodeField) request.AutoCode {
+	return request.AutoCode{StructName: "Book", TableName: "books", GvaModel: true, Fields: fields}
+}
+
+func Test_autoCodeMigration_Diff(t *testing.T) {
+	testdb.Open(t, "")
+	from := migrationTestCode(
+		&request.AutoCodeField{FieldName: "Title", FieldType: "string", ColumnName: "title", FieldIndexType: "index"},
+		&request.AutoCodeField{FieldName: "Author", FieldType: "string", ColumnName: "author"},
+	)
+	to := migrationTestCode(
+		&request.AutoCodeField{FieldName: "Title", FieldType: "string", ColumnName: "book_title", FieldIndexType: "index"},
+		&request.AutoCodeField{FieldName: "Price", FieldType: "float64", ColumnName: "price"},
+	)
+	up, down, err := AutoCodeMigration.Diff(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantUp := []model.AutoCodeMigrationOp{
+		{Action: model.AutoCodeMigrationDropColumn, Column: "author"},
+		{Action: model.AutoCodeMigrationRenameColumn, Column: "book_title", From: "title"},
+		{Action: model.AutoCodeMigrationAddColumn, Column: "price"},
+	}
+	if !reflect.DeepEqual(up, wantUp) {
+		t.Fatalf("Diff() up = %+v, want %+v", up, wantUp)
+	}
+	if down[1] != (model.AutoCodeMigrationOp{Action: model.AutoCodeMigrationRenameColumn, Column: "title", From: "book_title"}) {
+		t.Fatalf("Diff() down should rename back: %+v", down)
+	}
+	if columns := (model.SysAutoCodeMigration{Up: up}).DropColumns(); !reflect.DeepEqual(columns, []string{"author"}) {
+		t.Fatalf("DropColumns() = %v", columns)
+	}
+}
+
+func Test_autoCodeMigration_runRename(t *testing.T) {
+	db := testdb.Open(t, "")
+	from := migrationTestCode(&request.AutoCodeField{FieldName: "Title", FieldType: "string", ColumnName: "title", FieldIndexType: "index"})
+	to := migrationTestCode(&request.AutoCodeField{FieldName: "Title", FieldType: "string", ColumnName: "book_title", FieldIndexType: "index"})
+	value, err := AutoCodeMigration.model(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Table("books").AutoMigrate(value); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Exec("INSERT INTO books (title) VALUES (?)", "gva").Error; err != nil {
+		t.Fatal(err)
+	}
+	up, down, err := AutoCodeMigration.Diff(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = AutoCodeMigration.run(global.GVA_DB, "books", to, up); err != nil {
+		t.Fatal(err)
+	}
+	var title string
+	if err = db.Raw("SELECT book_title FROM books").Scan(&title).Error; err != nil || title != "gva" {
+		t.Fatalf("renamed column should keep data: %q %v", title, err)
+	}
+	if !db.Table("books").Migrator().HasIndex(value, "idx_books_title") {
+		t.Fatal("index should follow the renamed column")
+	}
+	if err = AutoCodeMigration.run(global.GVA_DB, "books", from, down); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Raw("SELECT title FROM books").Scan(&title).Error; err != nil || title != "gva" {
+		t.Fatalf("reverted column should keep data: %q %v", title, err)
+	}
+}
@@ -46,6 +46,11 @@ func (s *autoCodePackage) Create(ctx context.Context, info *request.SysAutoCodeP
 		return errors.New("存在相同PackageName")
 	}
 	create := info.Create()
+	if create.Pack != "" {
+		if _, err := AutoCodeTemplatePack.Check(ctx, create, create.Pack); err != nil {
+			return err
+		}
+	}
 	return global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		err := tx.Create(&create).Error
 		if err != nil {
@@ -56,26 +61,21 @@ func (s *autoCodePackage) Create(ctx context.Context, info *request.SysAutoCodeP
 		if err != nil {
 			return err
 		}
+		transaction := ast.NewTransaction(global.GVA_CONFIG.AutoCode.Root)
 		for key, value := range creates { // key 为 模版绝对路径
 			var files *template.Template
 			files, err = template.ParseFiles(key)
 			if err != nil {
 				return errors.Wrapf(err, "[filepath:%s]读取模版文件失败!", key)
 			}
-			err = os.MkdirAll(filepath.Dir(value), os.ModePerm)
-			if err != nil {
-				return errors.Wrapf(err, "[filepath:%s]创建文件夹失败!", value)
-			}
-			var file *os.File
-			file, err = os.Create(value)
-			if err != nil {
-				return errors.Wrapf(err, "[filepath:%s]创建文件夹失败!", value)
-			}
-			err = files.Execute(file, code)
-			_ = file.Close()
+			var builder strings.Builder
+			err = files.Execute(&builder, code)
 			if err != nil {
 				return errors.Wrapf(err, "[filepath:%s]生成失败!", value)
 			}
+			if err = transaction.Write(value, []byte(builder.String())); err != nil {
+				return err
+			}
 			fmt.Printf("[template:%s][filepath:%s]生成成功!\n", key, value)
 		}
 		for key, value := range asts {
@@ -83,25 +83,35 @@ func (s *autoCodePackage) Create(ctx context.Context, info *request.SysAutoCodeP
 			if len(keys) == 2 {
 				switch keys[1] {
 				case ast.TypePluginInitializeV2, ast.TypePackageApiEnter, ast.TypePackageRouterEnter, ast.TypePackageServiceEnter:
-					file, _ := value.Parse("", nil)
-					if file != nil {
-						err = value.Injection(file)
-						if err != nil {
-							return err
-						}
-						err = value.Format("", nil, file)
-						if err != nil {
-							return err
-						}
+					if _, statErr := os.Stat(ast.Filename(value)); statErr != nil {
+						continue
+					}
+					if err = transaction.Inject(value); err != nil {
+						return err
 					}
 					fmt.Printf("[type:%s]注入成功!\n", key)
 				}
 			}
 		}
-		return nil
+		return transaction.Commit() // 生成的文件与注入的代码统一写入 失败时回滚数据库记录
 	})
 }
 
+// SetPack 设置包的默认模板包 Pack 为空时使用内置模板
+func (s *autoCodePackage) SetPack(ctx context.Context, info request.SysAutoCodePackageSetPack) error {
+	var entity model.SysAutoCodePackage
+	err := global.GVA_DB.WithContext(ctx).First(&entity, info.ID).Error
+	if err != nil {
+		return errors.Wrap(err, "包不存在!")
+	}
+	if info.Pack != "" {
+		if _, err = AutoCodeTemplatePack.Check(ctx, entity, info.Pack); err != nil {
+			return err
+		}
+	}
+	return global.GVA_DB.WithContext(ctx).Model(&entity).Update("pack", info.Pack).Error
+}
+
 // Delete 删除包记录
 // @author: [piexlmax](https://github.com/piexlmax)
 // @author: [SliverHorn](https://github.com/SliverHorn)
@@ -143,6 +153,9 @@ func (s *autoCodePackage) Templates(ctx context.Context) ([]string, error) {
 			if entries[i].Name() == "preview" {
 				continue
 			} // preview 为预览代码生成器的代码
+			if entries[i].Name() == "packs" {
+				continue
+			} // packs 为模板包
 			templates = append(templates, entries[i].Name())
 		}
 	}
@@ -150,6 +163,9 @@ func (s *autoCodePackage) Templates(ctx context.Context) ([]string, error) {
 }
 
 func (s *autoCodePackage) templates(ctx context.Context, entity model.SysAutoCodePackage, info request.AutoCode) (code map[string]string, asts map[string]ast.Ast, creates map[string]string, err error) {
+	if info.Pack != "" {
+		return AutoCodeTemplatePack.templates(ctx, entity, info)
+	} // 模板包
 	code = make(map[string]string)
 	asts = make(map[string]ast.Ast)
 	creates = make(map[string]string)
@@ -223,6 +239,9 @@ func (s *autoCodePackage) templates(ctx context.Context, entity model.SysAutoCod
 							if api != -1 {
 								create = filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, secondDirs[j].Name(), "v1", entity.PackageName, info.HumpPackageName+".go")
 							}
+							if strings.HasSuffix(strings.TrimSuffix(threeDirs[k].Name(), ext), "_test.go") {
+								create = strings.TrimSuffix(create, ".go") + "_test.go"
+							} // 测试模版生成同名的 _test.go 文件
 							if hasEnter != -1 {
 								isApi := strings.Index(secondDirs[j].Name(), "api")
 								isRouter := strings.Index(secondDirs[j].Name(), "router")
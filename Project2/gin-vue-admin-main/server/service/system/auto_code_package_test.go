@@ -4,11 +4,13 @@ import (
 	"context"
 	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
 	"reflect"
 	"testing"
 )
 
 func Test_autoCodePackage_Create(t *testing.T) {
+	testdb.Open(t, "", &model.SysAutoCodePackage{})
 	type args struct {
 		ctx  context.Context
 		info *request.SysAutoCodePackageCreate
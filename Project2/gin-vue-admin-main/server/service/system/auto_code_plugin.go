@@ -2,8 +2,12 @@ package system
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
 	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/sign"
 	"github.com/mholt/archiver/v4"
 	cp "github.com/otiai10/copy"
 	"github.com/pkg/errors"
@@ -19,8 +23,9 @@ var AutoCodePlugin = new(autoCodePlugin)
 
 type autoCodePlugin struct{}
 
-// Install 插件安装
-func (s *autoCodePlugin) Install(file *multipart.FileHeader) (web, server int, err error) {
+// Install 插件安装 检查压缩包与签名, 按插件清单校验gva版本与依赖后复制文件, 注入插件注册代码并登记到已安装插件表
+// 未由信任库中的密钥签名的插件不允许引用 plugin-security.forbidden-imports 中的包
+func (s *autoCodePlugin) Install(ctx context.Context, file *multipart.FileHeader) (web, server int, report response.PluginInstallReport, err error) {
 	const GVAPLUGPINATH = "./gva-plug-temp/"
 	defer os.RemoveAll(GVAPLUGPINATH)
 	_, err = os.Stat(GVAPLUGPINATH)
@@ -30,19 +35,30 @@ func (s *autoCodePlugin) Install(file *multipart.FileHeader) (web, server int, e
 
 	src, err := file.Open()
 	if err != nil {
-		return -1, -1, err
+		return -1, -1, report, err
 	}
 	defer src.Close()
 
-	out, err := os.Create(GVAPLUGPINATH + file.Filename)
+	zipFile := GVAPLUGPINATH + filepath.Base(file.Filename)
+	out, err := os.Create(zipFile)
 	if err != nil {
-		return -1, -1, err
+		return -1, -1, report, err
 	}
 	defer out.Close()
 
 	_, err = io.Copy(out, src)
+	if err != nil {
+		return -1, -1, report, err
+	}
+	err = s.Inspect(zipFile, &report)
+	if err != nil {
+		return -1, -1, report, err
+	}
 
-	paths, err := utils.Unzip(GVAPLUGPINATH+file.Filename, GVAPLUGPINATH)
+	paths, err := utils.Unzip(zipFile, GVAPLUGPINATH)
+	if err != nil {
+		return -1, -1, report, err
+	}
 	paths = filterFile(paths)
 	var webIndex = -1
 	var serverIndex = -1
@@ -66,24 +82,60 @@ func (s *autoCodePlugin) Install(file *multipart.FileHeader) (web, server int, e
 	}
 	if len(serverPlugin) == 0 && len(webPlugin) == 0 {
 		zap.L().Error("非标准插件，请按照文档自动迁移使用")
-		return webIndex, serverIndex, errors.New("非标准插件，请按照文档自动迁移使用")
+		return webIndex, serverIndex, report, errors.New("非标准插件，请按照文档自动迁移使用")
+	}
+
+	manifests, err := s.Manifests(serverPlugin, webPlugin)
+	if err != nil {
+		return webIndex, serverIndex, report, err
+	}
+	pluginPath := serverPlugin
+	if len(pluginPath) == 0 {
+		pluginPath = webPlugin
+	}
+	root := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, filepath.Dir(filepath.Dir(pluginPath)))
+	err = s.Verify(root, manifests, &report)
+	if err != nil {
+		return webIndex, serverIndex, report, err
+	}
+	err = s.CheckImports(root, &report)
+	if err != nil {
+		return webIndex, serverIndex, report, err
+	}
+	if len(report.Violations) > 0 {
+		if !report.Signed {
+			return webIndex, serverIndex, report, errors.Errorf("插件引用了禁止的包: %s", strings.Join(report.Violations, "; "))
+		}
+		report.Warnings = append(report.Warnings, "插件引用了禁止的包, 已由信任的密钥签名")
+	}
+	err = s.Resolve(ctx, manifests...)
+	if err != nil {
+		return webIndex, serverIndex, report, err
 	}
 
 	if len(serverPlugin) != 0 {
 		err = installation(serverPlugin, global.GVA_CONFIG.AutoCode.Server, global.GVA_CONFIG.AutoCode.Server)
 		if err != nil {
-			return webIndex, serverIndex, err
+			return webIndex, serverIndex, report, err
 		}
 	}
 
 	if len(webPlugin) != 0 {
 		err = installation(webPlugin, global.GVA_CONFIG.AutoCode.Server, global.GVA_CONFIG.AutoCode.Web)
 		if err != nil {
-			return webIndex, serverIndex, err
+			return webIndex, serverIndex, report, err
+		}
+	}
+
+	for i := range manifests {
+		err = s.Register(ctx, manifests[i])
+		if err != nil {
+			return webIndex, serverIndex, report, err
 		}
 	}
 
-	return 1, 1, err
+	report.Installed = true
+	return 1, 1, report, err
 }
 
 func installation(path string, formPath string, toPath string) error {
@@ -145,11 +197,36 @@ func (s *autoCodePlugin) PubPlug(plugName string) (zipPath string, err error) {
 	}
 
 	fileName := plugName + ".zip"
-	// 创建一个新的zip文件
-	files, err := archiver.FilesFromDisk(nil, map[string]string{
+	dirs := map[string]string{
 		webPath:    plugName + "/web/plugin/" + plugName,
 		serverPath: plugName + "/server/plugin/" + plugName,
-	})
+	}
+	// 配置了签名私钥时为插件签名 签名文件位于压缩包根目录
+	manifest := model.PluginManifest{Name: plugName, Version: "0.0.0"}
+	if bytes, rErr := os.ReadFile(filepath.Join(serverPath, model.PluginManifestName)); rErr == nil {
+		if err = json.Unmarshal(bytes, &manifest); err != nil {
+			return "", errors.Wrap(err, "解析插件清单失败!")
+		}
+	}
+	prefixes := make(map[string]string, len(dirs))
+	for dir, name := range dirs {
+		prefixes[dir] = strings.TrimPrefix(name, plugName+"/")
+	}
+	signature, err := s.signPackage(manifest, prefixes)
+	if err != nil {
+		return "", errors.Wrap(err, "插件签名失败!")
+	}
+	if signature != nil {
+		signatureFile := filepath.Join(os.TempDir(), plugName+"-"+sign.SignatureName)
+		bytes, _ := json.MarshalIndent(signature, "", "  ")
+		if err = os.WriteFile(signatureFile, bytes, 0o644); err != nil {
+			return "", err
+		}
+		defer os.Remove(signatureFile)
+		dirs[signatureFile] = plugName + "/" + sign.SignatureName
+	}
+	// 创建一个新的zip文件
+	files, err := archiver.FilesFromDisk(nil, dirs)
 
 	// create the output file we'll write to
 	out, err := os.Create(fileName)
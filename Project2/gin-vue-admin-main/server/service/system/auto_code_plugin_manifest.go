@@ -0,0 +1,407 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/flipped-aurora/gin-vue-admin/server/docs"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/migrate"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Manifests 读取压缩包中每个插件的清单 没有清单的旧版插件以目录名作为插件名, 版本视为 0.0.0
+func (s *autoCodePlugin) Manifests(serverPlugin, webPlugin string) ([]model.PluginManifest, error) {
+	root := serverPlugin
+	if root == "" {
+		root = webPlugin
+	}
+	root = filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, root)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[path:%s]读取插件目录失败!", root)
+	}
+	manifests := make([]model.PluginManifest, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if skip, _ := skipMacSpecialDocument(entry.Name()); skip {
+			continue
+		}
+		manifest := model.PluginManifest{Name: entry.Name(), Version: "0.0.0"}
+		if serverPlugin != "" {
+			var bytes []byte
+			bytes, err = os.ReadFile(filepath.Join(root, entry.Name(), model.PluginManifestName))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "[plugin:%s]读取插件清单失败!", entry.Name())
+			}
+			if err == nil {
+				if err = json.Unmarshal(bytes, &manifest); err != nil {
+					return nil, errors.Wrapf(err, "[plugin:%s]解析插件清单失败!", entry.Name())
+				}
+			}
+		}
+		if manifest.Name != entry.Name() {
+			return nil, errors.Errorf("插件清单名称[%s]与目录名称[%s]不一致!", manifest.Name, entry.Name())
+		}
+		if _, err = utils.SemverSatisfies(manifest.Version, ""); err != nil {
+			return nil, errors.Wrapf(err, "[plugin:%s]插件版本号不合法!", manifest.Name)
+		}
+		manifests = append(manifests, manifest)
+	}
+	if len(manifests) == 0 {
+		return nil, errors.New("压缩包中没有找到插件目录!")
+	}
+	return manifests, nil
+}
+
+// Resolve 校验插件是否可以安装 同一压缩包中的插件可互相满足依赖
+func (s *autoCodePlugin) Resolve(ctx context.Context, manifests ...model.PluginManifest) error {
+	var installed []model.SysPlugin
+	err := global.GVA_DB.WithContext(ctx).Find(&installed).Error
+	if err != nil {
+		return errors.Wrap(err, "获取已安装插件失败!")
+	}
+	versions := make(map[string]string, len(installed)+len(manifests))
+	for _, plugin := range installed {
+		versions[plugin.Name] = plugin.Version
+	}
+	for _, manifest := range manifests {
+		if version, ok := versions[manifest.Name]; ok {
+			return errors.Errorf("插件[%s]已安装 版本为%s, 请先卸载!", manifest.Name, version)
+		}
+		for _, dir := range []string{global.GVA_CONFIG.AutoCode.Server, global.GVA_CONFIG.AutoCode.Web} {
+			path := filepath.Join(global.GVA_CONFIG.AutoCode.Root, dir, "plugin", manifest.Name)
+			if _, err = os.Stat(path); err == nil {
+				return errors.Errorf("[path:%s]已存在同名插件，请自行手动安装", path)
+			}
+		}
+	}
+	for _, manifest := range manifests {
+		versions[manifest.Name] = manifest.Version
+	}
+	for _, manifest := range manifests {
+		ok, err := utils.SemverSatisfies(docs.SwaggerInfo.Version, manifest.Gva)
+		if err != nil {
+			return errors.Wrapf(err, "[plugin:%s]gva版本约束不合法!", manifest.Name)
+		}
+		if !ok {
+			return errors.Errorf("插件[%s]需要gva版本%s, 当前版本为%s!", manifest.Name, manifest.Gva, docs.SwaggerInfo.Version)
+		}
+		var missing []string
+		for name, constraint := range manifest.Dependencies {
+			version, has := versions[name]
+			if !has {
+				missing = append(missing, fmt.Sprintf("%s(%s 未安装)", name, constraint))
+				continue
+			}
+			ok, err = utils.SemverSatisfies(version, constraint)
+			if err != nil {
+				return errors.Wrapf(err, "[plugin:%s]依赖[%s]版本约束不合法!", manifest.Name, name)
+			}
+			if !ok {
+				missing = append(missing, fmt.Sprintf("%s(%s 已安装%s)", name, constraint, version))
+			}
+		}
+		if len(missing) > 0 {
+			return errors.Errorf("插件[%s]依赖不满足: %s", manifest.Name, strings.Join(missing, ", "))
+		}
+		for _, table := range manifest.Tables {
+			if pluginTableProtected(table) {
+				return errors.Errorf("插件[%s]声明的表[%s]为系统表!", manifest.Name, table)
+			}
+		}
+		for _, field := range manifest.Config {
+			key := manifest.Name + "." + field.Key
+			if field.Required && field.Default == nil && (global.GVA_VP == nil || !global.GVA_VP.IsSet(key)) {
+				return errors.Errorf("插件[%s]缺少必填配置[%s], 请先在配置文件中添加!", manifest.Name, key)
+			}
+		}
+	}
+	return nil
+}
+
+// Register 登记已复制的插件 注入插件注册代码, 执行迁移, 注册api与菜单并写入默认配置
+func (s *autoCodePlugin) Register(ctx context.Context, manifest model.PluginManifest) (err error) {
+	serverPath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin", manifest.Name)
+	webPath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Web, "plugin", manifest.Name)
+	_, statErr := os.Stat(serverPath)
+	server := statErr == nil
+	_, statErr = os.Stat(webPath)
+	web := statErr == nil
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(serverPath)
+			_ = os.RemoveAll(webPath)
+		}
+	}() // 登记失败时清理已复制的文件
+	entity := model.SysPlugin{
+		Name:       manifest.Name,
+		Version:    manifest.Version,
+		Manifest:   manifest,
+		Injections: make(map[string]string),
+		Server:     server,
+		Web:        web,
+	}
+	if _, statErr = os.Stat(filepath.Join(serverPath, "plugin.go")); statErr == nil {
+		injection := &ast.PluginInitializeV2{
+			Type:        ast.TypePluginInitializeV2,
+			Path:        filepath.Join(serverPath, "plugin.go"),
+			PluginPath:  filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "initialize", "plugin_biz_v2.go"),
+			ImportPath:  fmt.Sprintf(`"%s/plugin/%s"`, global.GVA_CONFIG.AutoCode.Module, manifest.Name),
+			PackageName: manifest.Name,
+		}
+		if _, statErr = os.Stat(injection.PluginPath); statErr == nil {
+			transaction := ast.NewTransaction(global.GVA_CONFIG.AutoCode.Root)
+			if err = transaction.Inject(injection); err == nil {
+				err = transaction.Commit()
+			}
+			if err != nil {
+				return errors.Wrap(err, "注入插件注册代码失败!")
+			}
+			bytes, _ := json.Marshal(injection)
+			entity.Injections[ast.TypePluginInitializeV2] = string(bytes)
+		}
+	} // v2插件注入 initialize/plugin_biz_v2.go
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existed := make(map[string]bool, len(manifest.Tables))
+		for _, table := range manifest.Tables {
+			existed[table] = tx.Migrator().HasTable(table)
+		}
+		for _, migration := range manifest.Migrations {
+			if err := s.migrate(tx, serverPath, migration.Up); err != nil {
+				return errors.Wrapf(err, "[version:%s]执行迁移失败!", migration.Version)
+			}
+		}
+		for _, table := range manifest.Tables {
+			if !existed[table] && tx.Migrator().HasTable(table) {
+				entity.Tables = append(entity.Tables, table)
+			}
+		} // 只记录本次安装新建的表 卸载时只删除这些表
+		for _, api := range manifest.Apis {
+			var count int64
+			err := tx.Model(&model.SysApi{}).Where("path = ? AND method = ?", api.Path, api.Method).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			} // 已存在的api不归属于插件 卸载时不删除
+			if err = tx.Create(&api).Error; err != nil {
+				return errors.Wrapf(err, "[path:%s]注册api失败!", api.Path)
+			}
+			entity.ApiIDs = append(entity.ApiIDs, api.ID)
+		}
+		menuIDs, err := s.createMenus(tx, 0, manifest.Menus)
+		if err != nil {
+			return err
+		}
+		entity.MenuIDs = menuIDs
+		return tx.Create(&entity).Error
+	})
+	if err != nil {
+		_ = AutocodeHistory.RollbackInjections(entity.Injections, nil, false)
+		return err
+	}
+	s.writeConfig(manifest)
+	return nil
+}
+
+// Installed 获取已安装插件
+func (s *autoCodePlugin) Installed(ctx context.Context) (list []model.SysPlugin, err error) {
+	err = global.GVA_DB.WithContext(ctx).Order("id asc").Find(&list).Error
+	return list, err
+}
+
+// Uninstall 卸载插件 回滚注入代码, 按选项删除api、菜单与表, 并将插件文件移动到 rm_file 目录
+// 数据库变更在同一事务中执行(mysql 的 DDL 无法回滚) 回滚注入代码或移动文件失败时一并撤销, 只删除安装时新建的表
+func (s *autoCodePlugin) Uninstall(ctx context.Context, info request.SysPluginUninstall) error {
+	var entity model.SysPlugin
+	err := global.GVA_DB.WithContext(ctx).Where("name = ?", info.Name).First(&entity).Error
+	if err != nil {
+		return errors.Wrapf(err, "插件[%s]未安装!", info.Name)
+	}
+	var installed []model.SysPlugin
+	err = global.GVA_DB.WithContext(ctx).Where("name <> ?", entity.Name).Find(&installed).Error
+	if err != nil {
+		return err
+	}
+	var dependents []string
+	for _, plugin := range installed {
+		if _, ok := plugin.Manifest.Dependencies[entity.Name]; ok {
+			dependents = append(dependents, plugin.Name)
+		}
+	}
+	if len(dependents) > 0 {
+		return errors.Errorf("插件[%s]被%s依赖, 请先卸载依赖插件!", entity.Name, strings.Join(dependents, ", "))
+	}
+	if info.DeleteTable {
+		for _, table := range entity.Tables {
+			if pluginTableProtected(table) {
+				return errors.Errorf("[table:%s]系统表不允许删除!", table)
+			}
+		}
+	}
+	serverPath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "plugin", entity.Name)
+	webPath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Web, "plugin", entity.Name)
+	deleteApi := info.DeleteApi && len(entity.ApiIDs) > 0
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if deleteApi {
+			var apis []model.SysApi
+			if err := tx.Find(&apis, "id in ?", entity.ApiIDs).Error; err != nil {
+				return errors.Wrap(err, "删除api失败!")
+			}
+			if err := tx.Delete(&model.SysApi{}, "id in ?", entity.ApiIDs).Error; err != nil {
+				return errors.Wrap(err, "删除api失败!")
+			}
+			for _, api := range apis {
+				if err := tx.Delete(&gormadapter.CasbinRule{}, "v1 = ? AND v2 = ?", api.Path, api.Method).Error; err != nil {
+					return errors.Wrapf(err, "[path:%s]删除api权限失败!", api.Path)
+				}
+			}
+		} // 清除API表
+		if info.DeleteMenu {
+			for i := len(entity.MenuIDs) - 1; i >= 0; i-- {
+				if err := BaseMenuServiceApp.deleteBaseMenu(tx, int(entity.MenuIDs[i])); err != nil {
+					return errors.Wrapf(err, "[id:%d]删除菜单失败!", entity.MenuIDs[i])
+				}
+			}
+		} // 子菜单先于父菜单删除
+		if info.DeleteTable {
+			migrations := entity.Manifest.Migrations
+			for i := len(migrations) - 1; i >= 0; i-- {
+				if err := s.migrate(tx, serverPath, migrations[i].Down); err != nil {
+					return errors.Wrapf(err, "[version:%s]执行回退迁移失败!", migrations[i].Version)
+				}
+			}
+			for _, table := range entity.Tables {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return errors.Wrapf(err, "[table:%s]删除表失败!", table)
+				}
+			}
+		} // 删除表
+		if err := tx.Unscoped().Delete(&model.SysPluginState{}, "name = ?", entity.Name).Error; err != nil {
+			return errors.Wrap(err, "删除插件运行状态失败!")
+		}
+		if err := tx.Unscoped().Delete(&entity).Error; err != nil {
+			return err
+		}
+		if err := AutocodeHistory.RollbackInjections(entity.Injections, nil, false); err != nil {
+			return err
+		} // 清除注入代码
+		removeBasePath := filepath.Join(global.GVA_CONFIG.AutoCode.Root, "rm_file", strconv.FormatInt(int64(time.Now().Nanosecond()), 10))
+		for _, path := range []string{serverPath, webPath} {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			removePath := filepath.Join(removeBasePath, strings.TrimPrefix(path, global.GVA_CONFIG.AutoCode.Root))
+			if err := utils.FileMove(path, removePath); err != nil {
+				return errors.Wrapf(err, "[src:%s][dst:%s]文件移动失败!", path, removePath)
+			}
+		} // 移动文件
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if deleteApi {
+		OpenApiServiceApp.Invalidate()
+		if err = CasbinServiceApp.FreshCasbin(); err != nil {
+			global.GVA_LOG.Error("刷新casbin缓存失败!", zap.String("plugin", entity.Name), zap.Error(err))
+		}
+	}
+	if info.DeleteMenu {
+		menuTreeCache.InvalidateAll()
+	}
+	return nil
+}
+
+// pluginTableProtected 系统表不允许由插件声明或删除 schema.table 按表名比较
+func pluginTableProtected(table string) bool {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	table = strings.ToLower(strings.Trim(table, "`\"[]"))
+	return strings.HasPrefix(table, "sys_") || table == "casbin_rule" || table == "jwt_blacklists"
+}
+
+// migrate 执行插件目录下的sql文件 按语句拆分后逐条执行
+func (s *autoCodePlugin) migrate(db *gorm.DB, root, file string) error {
+	if file == "" {
+		return nil
+	}
+	path := filepath.Join(root, filepath.Clean("/"+file))
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "[path:%s]读取迁移文件失败!", path)
+	}
+	for _, statement := range migrate.Statements(string(bytes), db.Dialector.Name()) {
+		if err = db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createMenus 按层级创建菜单 已存在的同名菜单不归属于插件
+func (s *autoCodePlugin) createMenus(tx *gorm.DB, parentID uint, menus []model.SysBaseMenu) ([]uint, error) {
+	var ids []uint
+	for i := range menus {
+		menu := menus[i]
+		children := menu.Children
+		menu.Children = nil
+		menu.ParentId = parentID
+		var exist model.SysBaseMenu
+		err := tx.Where("name = ?", menu.Name).First(&exist).Error
+		if err == nil {
+			menu.ID = exist.ID
+		} else {
+			if err = tx.Create(&menu).Error; err != nil {
+				return nil, errors.Wrapf(err, "[name:%s]注册菜单失败!", menu.Name)
+			}
+			ids = append(ids, menu.ID)
+		}
+		childIDs, err := s.createMenus(tx, menu.ID, children)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, childIDs...)
+	}
+	return ids, nil
+}
+
+// writeConfig 将未配置的配置项默认值写入配置文件
+func (s *autoCodePlugin) writeConfig(manifest model.PluginManifest) {
+	if global.GVA_VP == nil {
+		return
+	}
+	changed := false
+	for _, field := range manifest.Config {
+		key := manifest.Name + "." + field.Key
+		if field.Default == nil || global.GVA_VP.IsSet(key) {
+			continue
+		}
+		global.GVA_VP.Set(key, field.Default)
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	if err := global.GVA_VP.WriteConfig(); err != nil {
+		global.GVA_LOG.Error("写入插件默认配置失败!", zap.String("plugin", manifest.Name), zap.Error(err))
+	}
+}
@@ -0,0 +1,110 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+)
+
+func Test_pluginTableProtected(t *testing.T) {
+	tests := map[string]bool{
+		"sys_users":             true,
+		"mydb.SYS_USERS":        true,
+		"`casbin_rule`":         true,
+		"public.jwt_blacklists": true,
+		"email_logs":            false,
+		"mydb.email_logs":       false,
+	}
+	for table, want := range tests {
+		if got := pluginTableProtected(table); got != want {
+			t.Errorf("pluginTableProtected(%q) = %v, want %v", table, got, want)
+		}
+	}
+}
+
+func Test_autoCodePlugin_Uninstall(t *testing.T) {
+	db := testdb.Open(t, "", &model.SysPlugin{}, &model.SysPluginState{}, &model.SysApi{})
+	autoCode := global.GVA_CONFIG.AutoCode
+	t.Cleanup(func() { global.GVA_CONFIG.AutoCode = autoCode })
+	root := t.TempDir()
+	global.GVA_CONFIG.AutoCode.Root = root
+	global.GVA_CONFIG.AutoCode.Server = "server"
+	global.GVA_CONFIG.AutoCode.Web = "web"
+	pluginPath := filepath.Join(root, "server", "plugin", "demo")
+	if err := os.MkdirAll(pluginPath, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	up := "CREATE TABLE demo_items (id integer, name text DEFAULT 'a;b');\nCREATE TABLE shared_items (id integer);"
+	if err := os.WriteFile(filepath.Join(pluginPath, "up.sql"), []byte(up), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("CREATE TABLE existing_items (id integer)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	manifest := model.PluginManifest{
+		Name:       "demo",
+		Version:    "1.0.0",
+		Migrations: []model.PluginMigration{{Version: "1.0.0", Up: "up.sql"}},
+		Tables:     []string{"demo_items", "existing_items"},
+	}
+	if err := AutoCodePlugin.Register(ctx, manifest); err != nil {
+		t.Fatal(err)
+	}
+	var entity model.SysPlugin
+	if err := db.First(&entity, "name = ?", "demo").Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(entity.Tables) != 1 || entity.Tables[0] != "demo_items" {
+		t.Fatalf("only tables created by the install should be recorded: %v", entity.Tables)
+	}
+
+	// 被篡改的登记记录包含系统表 拒绝卸载且不删除任何表
+	if err := db.Model(&entity).Update("tables", `["demo_items","sys_apis"]`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := AutoCodePlugin.Uninstall(ctx, request.SysPluginUninstall{Name: "demo", DeleteTable: true}); err == nil {
+		t.Fatal("uninstall dropping a system table should fail")
+	}
+	if !db.Migrator().HasTable("sys_apis") || !db.Migrator().HasTable("demo_items") {
+		t.Fatal("no table should be dropped when uninstall is rejected")
+	}
+
+	if err := db.Model(&entity).Update("tables", `["demo_items"]`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := AutoCodePlugin.Uninstall(ctx, request.SysPluginUninstall{Name: "demo", DeleteTable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if db.Migrator().HasTable("demo_items") {
+		t.Fatal("table created by the plugin should be dropped")
+	}
+	if !db.Migrator().HasTable("existing_items") || !db.Migrator().HasTable("shared_items") {
+		t.Fatal("tables not recorded as created by the plugin should be kept")
+	}
+	if err := db.First(&model.SysPlugin{}, "name = ?", "demo").Error; err == nil {
+		t.Fatal("plugin record should be deleted")
+	}
+	if _, err := os.Stat(pluginPath); !os.IsNotExist(err) {
+		t.Fatal("plugin files should be moved to rm_file")
+	}
+}
+
+func Test_autoCodePlugin_ResolveProtectedTable(t *testing.T) {
+	testdb.Open(t, "", &model.SysPlugin{})
+	autoCode := global.GVA_CONFIG.AutoCode
+	t.Cleanup(func() { global.GVA_CONFIG.AutoCode = autoCode })
+	global.GVA_CONFIG.AutoCode.Root = t.TempDir()
+	err := AutoCodePlugin.Resolve(context.Background(), model.PluginManifest{Name: "demo", Version: "1.0.0", Tables: []string{"mydb.SYS_USERS"}})
+	if err == nil || !strings.Contains(err.Error(), "系统表") {
+		t.Fatal("manifest declaring a system table should be rejected")
+	}
+}
@@ -0,0 +1,222 @@
+package system
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/sign"
+	"github.com/pkg/errors"
+)
+
+// defaultForbiddenImports 未配置 plugin-security.forbidden-imports 时禁止未签名插件引用的包
+// net/... 包含 net/http 等可以发起外部连接的包 插件的接口通过 gin 提供 不需要直接引用
+var defaultForbiddenImports = []string{"os/exec", "unsafe", "syscall", "plugin", "net/...", "golang.org/x/sys/..."}
+
+// Inspect 解压前检查压缩包 拒绝符号链接、绝对路径、路径穿越以及插件目录之外的文件
+// 压缩包只能包含一个根目录, 其下为 server/plugin/{name}/、web/plugin/{name}/ 与签名文件
+func (s *autoCodePlugin) Inspect(zipFile string, report *response.PluginInstallReport) error {
+	reader, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return errors.Wrap(err, "读取压缩包失败!")
+	}
+	defer reader.Close()
+	root := ""
+	for _, f := range reader.File {
+		name := filepath.ToSlash(f.Name)
+		if skip, _ := skipMacSpecialDocument(name); skip {
+			continue
+		}
+		if f.Mode()&fs.ModeSymlink != 0 {
+			return errors.Errorf("[%s]压缩包中不允许包含符号链接!", f.Name)
+		}
+		if path.IsAbs(name) || filepath.IsAbs(f.Name) || filepath.VolumeName(f.Name) != "" || strings.Contains(name, ":") {
+			return errors.Errorf("[%s]压缩包中不允许包含绝对路径!", f.Name)
+		}
+		parts := strings.Split(strings.TrimSuffix(name, "/"), "/")
+		for _, part := range parts {
+			if part == ".." || part == "." || part == "" {
+				return errors.Errorf("[%s]文件名不合法!", f.Name)
+			}
+		}
+		if root == "" {
+			root = parts[0]
+		}
+		if parts[0] != root {
+			return errors.Errorf("[%s]压缩包只能包含一个根目录!", f.Name)
+		}
+		if !allowedPluginPath(parts, f.FileInfo().IsDir()) {
+			return errors.Errorf("[%s]不在插件目录中!", f.Name)
+		}
+		if !f.FileInfo().IsDir() {
+			report.Files++
+		}
+	}
+	if root == "" {
+		return errors.New("压缩包为空!")
+	}
+	return nil
+}
+
+// allowedPluginPath 文件必须位于 server/plugin/{name}/ 或 web/plugin/{name}/ 下, 根目录下只允许签名文件
+func allowedPluginPath(parts []string, dir bool) bool {
+	switch {
+	case len(parts) == 1:
+		return dir
+	case len(parts) == 2 && parts[1] == sign.SignatureName:
+		return !dir
+	case parts[1] != "server" && parts[1] != "web":
+		return false
+	case len(parts) == 2:
+		return dir
+	case parts[2] != "plugin":
+		return false
+	case len(parts) == 3 || len(parts) == 4:
+		return dir
+	}
+	return true
+}
+
+// Verify 校验压缩包签名 root 为解压后的压缩包根目录
+// 签名文件中的插件名与版本须与插件清单一致, 文件须与签名完全一致
+func (s *autoCodePlugin) Verify(root string, manifests []model.PluginManifest, report *response.PluginInstallReport) error {
+	names := make([]string, 0, len(manifests))
+	versions := make([]string, 0, len(manifests))
+	for _, manifest := range manifests {
+		names = append(names, manifest.Name)
+		versions = append(versions, manifest.Version)
+	}
+	report.Name = strings.Join(names, ",")
+	report.Version = strings.Join(versions, ",")
+
+	security := global.GVA_CONFIG.PluginSecurity
+	bytes, err := os.ReadFile(filepath.Join(root, sign.SignatureName))
+	if os.IsNotExist(err) {
+		if security.RequireSignature {
+			return errors.New("插件未签名, 当前只允许安装已签名的插件!")
+		}
+		report.Warnings = append(report.Warnings, "插件未签名")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "读取签名文件失败!")
+	}
+	var signature sign.Signature
+	if err = json.Unmarshal(bytes, &signature); err != nil {
+		return errors.Wrap(err, "解析签名文件失败!")
+	}
+	report.KeyID = signature.KeyID
+	if signature.Name != report.Name || signature.Version != report.Version {
+		return errors.Errorf("签名的插件[%s@%s]与插件清单[%s@%s]不一致!", signature.Name, signature.Version, report.Name, report.Version)
+	}
+	var key ed25519.PublicKey
+	for _, trusted := range security.TrustedKeys {
+		if trusted.ID == signature.KeyID {
+			if key, err = sign.ParsePublicKey(trusted.PublicKey); err != nil {
+				return errors.Wrapf(err, "[key:%s]信任库配置错误!", trusted.ID)
+			}
+		}
+	}
+	if key == nil {
+		if security.RequireSignature {
+			return errors.Errorf("签名密钥[%s]不在信任库中!", signature.KeyID)
+		}
+		report.Warnings = append(report.Warnings, "签名密钥["+signature.KeyID+"]不在信任库中")
+		return nil
+	}
+	if err = signature.Verify(key); err != nil {
+		return err
+	}
+	files := make(map[string]string)
+	if err = sign.Digest(root, "", files); err != nil {
+		return errors.Wrap(err, "计算文件摘要失败!")
+	}
+	if err = signature.Compare(files); err != nil {
+		return err
+	}
+	report.Signed = true
+	return nil
+}
+
+// CheckImports 检查插件源码引用的包 返回引用了禁止包的文件
+// cgo(import "C") 与 //go:linkname 可以绕过包的限制 不受 forbidden-imports 配置影响 始终检查
+func (s *autoCodePlugin) CheckImports(root string, report *response.PluginInstallReport) error {
+	forbidden := global.GVA_CONFIG.PluginSecurity.ForbiddenImports
+	if len(forbidden) == 0 {
+		forbidden = defaultForbiddenImports
+	}
+	fileSet := token.NewFileSet()
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".go" {
+			return nil
+		}
+		file, err := parser.ParseFile(fileSet, p, nil, parser.ParseComments)
+		if err != nil {
+			return errors.Wrapf(err, "[%s]解析插件源码失败!", p)
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			if importPath == "C" || forbiddenImport(importPath, forbidden) {
+				report.Violations = append(report.Violations, rel+": "+importPath)
+			}
+		}
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				if strings.HasPrefix(comment.Text, "//go:linkname") {
+					report.Violations = append(report.Violations, rel+": //go:linkname")
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func forbiddenImport(importPath string, forbidden []string) bool {
+	for _, item := range forbidden {
+		if prefix, ok := strings.CutSuffix(item, "/..."); ok {
+			if importPath == prefix || strings.HasPrefix(importPath, prefix+"/") {
+				return true
+			}
+		} else if importPath == item {
+			return true
+		}
+	}
+	return false
+}
+
+// signPackage 使用配置的私钥为打包的插件签名 未配置私钥时返回 nil
+// dirs 为磁盘目录 => 压缩包中的路径
+func (s *autoCodePlugin) signPackage(manifest model.PluginManifest, dirs map[string]string) (*sign.Signature, error) {
+	security := global.GVA_CONFIG.PluginSecurity
+	if security.SigningKey == "" {
+		return nil, nil
+	}
+	key, err := sign.ParsePrivateKey(security.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+	signature := &sign.Signature{Name: manifest.Name, Version: manifest.Version, KeyID: security.SigningKeyID, Files: make(map[string]string)}
+	for dir, prefix := range dirs {
+		if err = sign.Digest(dir, prefix, signature.Files); err != nil {
+			return nil, err
+		}
+	}
+	signature.Sign(key)
+	return signature, nil
+}
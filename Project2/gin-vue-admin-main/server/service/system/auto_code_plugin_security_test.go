@@ -0,0 +1,44 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+)
+
+func Test_autoCodePlugin_CheckImports(t *testing.T) {
+	security := global.GVA_CONFIG.PluginSecurity
+	global.GVA_CONFIG.PluginSecurity.ForbiddenImports = nil
+	t.Cleanup(func() { global.GVA_CONFIG.PluginSecurity = security })
+
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{name: "allowed", source: "package demo\n\nimport (\n\t\"strings\"\n\n\t\"github.com/gin-gonic/gin\"\n)\n\nvar _ = strings.ToLower\nvar _ gin.HandlerFunc\n"},
+		{name: "net", source: "package demo\n\nimport \"net\"\n\nvar _ = net.Dial\n", want: []string{"demo.go: net"}},
+		{name: "net subpackage", source: "package demo\n\nimport \"net/http\"\n\nvar _ = http.Get\n", want: []string{"demo.go: net/http"}},
+		{name: "cgo", source: "package demo\n\n// #include <stdlib.h>\nimport \"C\"\n", want: []string{"demo.go: C"}},
+		{name: "linkname", source: "package demo\n\nimport _ \"unsafe\"\n\n//go:linkname now runtime.nanotime\nfunc now() int64\n", want: []string{"demo.go: unsafe", "demo.go: //go:linkname"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "demo.go"), []byte(tt.source), 0666); err != nil {
+				t.Fatal(err)
+			}
+			var report response.PluginInstallReport
+			if err := AutoCodePlugin.CheckImports(root, &report); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(report.Violations, tt.want) {
+				t.Errorf("CheckImports() violations = %v, want %v", report.Violations, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package system
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+)
+
+// renderRelation 按 package 模板渲染带关联字段的结构体 返回模板相对路径与渲染结果
+func renderRelation(t *testing.T, relation *request.AutoCodeField) map[string]string {
+	t.Helper()
+	info := request.AutoCode{
+		Package:         "demo",
+		StructName:      "Book",
+		TableName:       "books",
+		Abbreviation:    "book",
+		Description:     "书",
+		HumpPackageName: "book",
+		GvaModel:        true,
+		Fields: []*request.AutoCodeField{
+			{FieldName: "Title", FieldDesc: "标题", FieldType: "string", FieldJson: "title", ColumnName: "title", FieldSearchType: "LIKE"},
+			relation,
+		},
+	}
+	if err := info.Pretreatment(); err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, name := range []string{"model/model.go.tpl", "model/request/request.go.tpl", "service/service.go.tpl"} {
+		path := filepath.Join("..", "..", "resource", "package", "server", filepath.FromSlash(name))
+		tpl, err := template.ParseFiles(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var builder strings.Builder
+		if err = tpl.Execute(&builder, info); err != nil {
+			t.Fatalf("render %s: %v", name, err)
+		}
+		if _, err = parser.ParseFile(token.NewFileSet(), name, builder.String(), parser.AllErrors); err != nil {
+			t.Fatalf("%s does not parse: %v\n%s", name, err, builder.String())
+		}
+		files[name] = builder.String()
+	}
+	return files
+}
+
+func Test_autoCodeTemplate_Relations(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    *request.AutoCodeField
+		want     map[string][]string
+		unwanted map[string][]string
+	}{
+		{
+			name: "belongsTo",
+			field: &request.AutoCodeField{FieldName: "Author", FieldDesc: "作者", FieldJson: "author", FieldSearchType: "LIKE",
+				Relation: &request.AutoCodeRelation{Type: request.RelationBelongsTo, StructName: "Author", Table: "authors", SearchColumn: "name", Preload: true, OnDelete: "CASCADE"}},
+			want: map[string][]string{
+				"model/model.go.tpl": {
+					"Author  *Author `json:\"author,omitempty\" form:\"-\" gorm:\"foreignKey:AuthorID;references:ID;constraint:OnDelete:CASCADE;\"`",
+					"AuthorID  *uint",
+				},
+				"model/request/request.go.tpl": {"Author  string `json:\"author\" form:\"author\"`"},
+				"service/service.go.tpl": {
+					`global.GVA_DB.Preload("Author").Where("id = ?", ID).First(&book)`,
+					`db = db.Preload("Author")`,
+					`db = db.Where("author_id IN (?)", global.GVA_DB.Table("authors").Select("id").Where("name LIKE ?", "%"+info.Author+"%"))`,
+				},
+			},
+			// belongsTo 的删除策略由数据库外键处理 不生成级联删除与关联同步代码
+			unwanted: map[string][]string{"service/service.go.tpl": {"Association(", "tx.Select("}},
+		},
+		{
+			name: "hasMany",
+			field: &request.AutoCodeField{FieldName: "Chapters", FieldDesc: "章节", FieldJson: "chapters",
+				Relation: &request.AutoCodeRelation{Type: request.RelationHasMany, StructName: "Chapter", Preload: true, OnDelete: "CASCADE"}},
+			want: map[string][]string{
+				"model/model.go.tpl": {"Chapters  []Chapter `json:\"chapters\" form:\"-\" gorm:\"foreignKey:BookID;constraint:OnDelete:CASCADE;\"`"},
+				"service/service.go.tpl": {
+					`global.GVA_DB.Preload("Chapters").Where("id = ?", ID).First(&book)`,
+					`Omit(clause.Associations).Updates(&book)`,
+					`tx.Model(&book).Association("Chapters").Replace(book.Chapters)`,
+					`tx.Select("Chapters").Delete(&book)`,
+					`tx.Select("Chapters").Delete(&books)`,
+				},
+			},
+			unwanted: map[string][]string{"model/request/request.go.tpl": {"Chapters"}},
+		},
+		{
+			name: "many2many",
+			field: &request.AutoCodeField{FieldName: "Tags", FieldDesc: "标签", FieldJson: "tags", FieldSearchType: "LIKE",
+				Relation: &request.AutoCodeRelation{Type: request.RelationMany2Many, Package: "common", StructName: "Tag", Table: "tags", SearchColumn: "name", OnDelete: "CASCADE"}},
+			want: map[string][]string{
+				"model/model.go.tpl": {
+					`"github.com/flipped-aurora/gin-vue-admin/server/model/common"`,
+					"Tags  []common.Tag `json:\"tags\" form:\"-\" gorm:\"many2many:book_tags;constraint:OnDelete:CASCADE;\"`",
+				},
+				"model/request/request.go.tpl": {"Tags  string `json:\"tags\" form:\"tags\"`"},
+				"service/service.go.tpl": {
+					`tx.Model(&book).Association("Tags").Replace(book.Tags)`,
+					`tx.Select("Tags").Delete(&book)`,
+					`db = db.Where("id IN (?)", global.GVA_DB.Table("book_tags").Select("book_id").Where("tag_id IN (?)", global.GVA_DB.Table("tags").Select("id").Where("name LIKE ?", "%"+info.Tags+"%")))`,
+				},
+			},
+			// 未开启预加载
+			unwanted: map[string][]string{"service/service.go.tpl": {"Preload("}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := renderRelation(t, tt.field)
+			for name, wants := range tt.want {
+				for _, want := range wants {
+					if !strings.Contains(files[name], want) {
+						t.Errorf("%s should contain %q:\n%s", name, want, files[name])
+					}
+				}
+			}
+			for name, unwanted := range tt.unwanted {
+				for _, s := range unwanted {
+					if strings.Contains(files[name], s) {
+						t.Errorf("%s should not contain %q:\n%s", name, s, files[name])
+					}
+				}
+			}
+		})
+	}
+}
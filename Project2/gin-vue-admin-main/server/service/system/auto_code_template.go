@@ -7,6 +7,8 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
 	utilsAst "github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
 	"github.com/pkg/errors"
 	"go/ast"
@@ -16,6 +18,7 @@ import (
 	"gorm.io/gorm"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 )
@@ -35,44 +38,20 @@ func (s *autoCodeTemplate) Create(ctx context.Context, info request.AutoCode) er
 
 	// 增加判断: 重复创建struct
 	if AutocodeHistory.Repeat(info.BusinessDB, info.StructName, info.Package) {
-		return errors.New("已经创建过此数据结构,请勿重复创建!")
+		return errors.New("已经创建过此数据结构,请勿重复创建! 结构变更请使用重新生成")
 	}
 
-	generate, templates, injections, err := s.generate(ctx, info, autoPkg)
+	tx, templates, injections, err := s.stage(ctx, info, autoPkg)
 	if err != nil {
 		return err
 	}
-	for key, builder := range generate {
-		err = os.MkdirAll(filepath.Dir(key), os.ModePerm)
-		if err != nil {
-			return errors.Wrapf(err, "[filepath:%s]创建文件夹失败!", key)
-		}
-		err = os.WriteFile(key, []byte(builder.String()), 0666)
-		if err != nil {
-			return errors.Wrapf(err, "[filepath:%s]写入文件失败!", key)
-		}
+	if err = tx.Commit(); err != nil {
+		return err
 	}
 
 	// 自动创建api
 	if info.AutoCreateApiToSql {
-		apis := info.Apis()
-		err := global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			for _, v := range apis {
-				var api model.SysApi
-				var id uint
-				err := tx.Where("path = ? AND method = ?", v.Path, v.Method).First(&api).Error
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					if err = tx.Create(&v).Error; err != nil { // 遇到错误时回滚事务
-						return err
-					}
-					id = v.ID
-				} else {
-					id = api.ID
-				}
-				history.ApiIDs = append(history.ApiIDs, id)
-			}
-			return nil
-		})
+		history.ApiIDs, err = s.createApis(ctx, info)
 		if err != nil {
 			return err
 		}
@@ -80,36 +59,65 @@ func (s *autoCodeTemplate) Create(ctx context.Context, info request.AutoCode) er
 
 	// 自动创建menu
 	if info.AutoCreateMenuToSql {
-		var entity model.SysBaseMenu
-		var id uint
-		err := global.GVA_DB.WithContext(ctx).First(&entity, "name = ?", info.Abbreviation).Error
-		if err == nil {
-			id = entity.ID
-		} else {
-			entity = info.Menu(autoPkg.Template)
-			err = global.GVA_DB.WithContext(ctx).Create(&entity).Error
-			id = entity.ID
-			if err != nil {
-				return errors.Wrap(err, "创建菜单失败!")
-			}
+		history.MenuID, err = s.createMenu(ctx, info, autoPkg)
+		if err != nil {
+			return err
 		}
-		history.MenuID = id
 	}
 
 	// 创建历史记录
 	history.Templates = templates
+	history.Hashes = tx.Hashes()
 	history.Injections = make(map[string]string, len(injections))
 	for key, value := range injections {
 		bytes, _ := json.Marshal(value)
 		history.Injections[key] = string(bytes)
 	}
-	err = AutocodeHistory.Create(ctx, history)
+	_, err = AutocodeHistory.Create(ctx, history)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// createApis 创建结构体的api 已存在的api直接使用 返回全部api的ID
+func (s *autoCodeTemplate) createApis(ctx context.Context, info request.AutoCode) (ids []uint, err error) {
+	apis := info.Apis()
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, v := range apis {
+			var api model.SysApi
+			var id uint
+			err := tx.Where("path = ? AND method = ?", v.Path, v.Method).First(&api).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err = tx.Create(&v).Error; err != nil { // 遇到错误时回滚事务
+					return err
+				}
+				id = v.ID
+			} else {
+				id = api.ID
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// createMenu 创建结构体的菜单 同名菜单已存在时直接使用
+func (s *autoCodeTemplate) createMenu(ctx context.Context, info request.AutoCode, autoPkg model.SysAutoCodePackage) (uint, error) {
+	var entity model.SysBaseMenu
+	err := global.GVA_DB.WithContext(ctx).First(&entity, "name = ?", info.Abbreviation).Error
+	if err == nil {
+		return entity.ID, nil
+	}
+	entity = info.Menu(autoPkg.Template)
+	err = global.GVA_DB.WithContext(ctx).Create(&entity).Error
+	if err != nil {
+		return 0, errors.Wrap(err, "创建菜单失败!")
+	}
+	return entity.ID, nil
+}
+
 // Preview 预览自动化代码
 func (s *autoCodeTemplate) Preview(ctx context.Context, info request.AutoCode) (map[string]string, error) {
 	var entity model.SysAutoCodePackage
@@ -136,49 +144,257 @@ func (s *autoCodeTemplate) Preview(ctx context.Context, info request.AutoCode) (
 	return preview, nil
 }
 
+// DryRun 试运行 不写入文件 返回每个生成与注入文件的 unified diff、已注入与冲突信息
+func (s *autoCodeTemplate) DryRun(ctx context.Context, info request.AutoCode) ([]utilsAst.Change, error) {
+	var entity model.SysAutoCodePackage
+	err := global.GVA_DB.WithContext(ctx).Where("package_name = ?", info.Package).First(&entity).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "查询包失败!")
+	}
+	tx, _, _, err := s.stage(ctx, info, entity)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Changes()
+}
+
+// Generate 离线生成代码 不访问数据库 供命令行工具使用
+// files 为文件路径与生成后的完整内容(注入文件为注入后的内容) templates 为模板与生成文件的对应关系 injections 为序列化后的注入信息 可用于回滚
+func (s *autoCodeTemplate) Generate(ctx context.Context, info request.AutoCode, entity model.SysAutoCodePackage) (files map[string]string, templates map[string]string, injections map[string]string, err error) {
+	codes, templates, asts, err := s.generate(ctx, info, entity)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files = make(map[string]string, len(codes))
+	for key, builder := range codes {
+		files[key] = builder.String()
+	}
+	injections = make(map[string]string, len(asts))
+	for key, value := range asts {
+		bytes, _ := json.Marshal(value)
+		injections[key] = string(bytes)
+	}
+	return files, templates, injections, nil
+}
+
+// Regenerate 结构变更后重新生成代码
+// 以上次的结构化信息生成的代码为基准 与本地文件和本次生成的代码三方合并 保留手工修改 冲突处写入冲突标记
+// 同时根据字段变化生成数据库迁移 Migrate 为 true 时立即执行 迁移会删除字段时需同时设置 ConfirmDrop
+func (s *autoCodeTemplate) Regenerate(ctx context.Context, info request.AutoCodeRegenerate) (result response.AutoCodeRegenerate, err error) {
+	var autoPkg model.SysAutoCodePackage
+	err = global.GVA_DB.WithContext(ctx).Where("package_name = ?", info.Package).First(&autoPkg).Error
+	if err != nil {
+		return result, errors.Wrap(err, "查询包失败!")
+	}
+	var history model.SysAutoCodeHistory
+	err = global.GVA_DB.WithContext(ctx).Where("business_db = ? and struct_name = ? and package = ? and flag = 0", info.BusinessDB, info.StructName, info.Package).First(&history).Error
+	if err != nil {
+		return result, errors.Wrap(err, "未找到此数据结构的生成记录, 请先创建!")
+	}
+	var previous request.AutoCode
+	if err = json.Unmarshal([]byte(history.Request), &previous); err != nil {
+		return result, errors.Wrap(err, "解析上次的结构化信息失败!")
+	}
+	if err = previous.Pretreatment(); err != nil {
+		return result, err
+	}
+	previous.PackageT = utils.FirstUpper(previous.Package)
+	current := info.AutoCode
+	current.PackageT = utils.FirstUpper(current.Package)
+
+	bases, err := s.render(ctx, previous, autoPkg)
+	if err != nil {
+		return result, err
+	}
+	codes, err := s.render(ctx, current, autoPkg)
+	if err != nil {
+		return result, err
+	}
+	merged := make(map[string]string, len(codes))
+	for key, code := range codes {
+		file := response.AutoCodeRegenerateFile{Path: key, Status: response.AutoCodeFileUpdated}
+		if rel, rErr := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, key); rErr == nil {
+			file.Path = filepath.ToSlash(rel)
+		}
+		local, rErr := os.ReadFile(key)
+		switch {
+		case os.IsNotExist(rErr):
+			file.Status = response.AutoCodeFileCreated
+		case rErr != nil:
+			return result, errors.Wrapf(rErr, "[filepath:%s]读取文件失败!", key)
+		case string(local) == code:
+			file.Status = response.AutoCodeFileUnchanged
+		case string(local) != bases[key]:
+			code, file.Conflicts = utils.Merge3(bases[key], string(local), code)
+			file.Status = response.AutoCodeFileMerged
+			if file.Conflicts > 0 {
+				file.Status = response.AutoCodeFileConflict
+			}
+		}
+		if info.DryRun {
+			file.Content = code
+		}
+		merged[key] = code
+		result.Files = append(result.Files, file)
+	}
+	sort.Slice(result.Files, func(i, j int) bool { return result.Files[i].Path < result.Files[j].Path })
+
+	up, down, err := AutoCodeMigration.Diff(previous, current)
+	if err != nil {
+		return result, err
+	}
+	if len(up) > 0 {
+		bytes, _ := json.Marshal(current)
+		result.Migration = &model.SysAutoCodeMigration{
+			HistoryID:  history.ID,
+			BusinessDB: current.BusinessDB,
+			Table:      AutoCodeMigration.table(AutoCodeMigration.db(current.BusinessDB), current),
+			Version:    history.Version + 1,
+			From:       history.Request,
+			To:         string(bytes),
+			Up:         up,
+			Down:       down,
+			Status:     model.AutoCodeMigrationPending,
+		}
+	}
+	if info.DryRun {
+		return result, nil
+	}
+	if info.Migrate && !info.ConfirmDrop && result.Migration != nil {
+		if columns := result.Migration.DropColumns(); len(columns) > 0 {
+			return result, errors.Errorf("迁移将删除字段[%s] 数据无法恢复, 请确认后重试!", strings.Join(columns, ","))
+		}
+	}
+
+	for key, code := range merged {
+		if err = os.MkdirAll(filepath.Dir(key), os.ModePerm); err != nil {
+			return result, errors.Wrapf(err, "[filepath:%s]创建文件夹失败!", key)
+		}
+		if err = os.WriteFile(key, []byte(code), 0666); err != nil {
+			return result, errors.Wrapf(err, "[filepath:%s]写入文件失败!", key)
+		}
+	}
+	err = global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bytes, _ := json.Marshal(current)
+		err := tx.Model(&model.SysAutoCodeHistory{}).Where("id = ?", history.ID).Updates(map[string]interface{}{
+			"request":     string(bytes),
+			"description": current.Description,
+			"version":     history.Version + 1,
+		}).Error
+		if err != nil || result.Migration == nil {
+			return err
+		}
+		return tx.Create(result.Migration).Error
+	})
+	if err != nil {
+		return result, errors.Wrap(err, "更新生成记录失败!")
+	}
+	OpenApiServiceApp.Invalidate()
+	if result.Migration != nil && info.Migrate {
+		if err = AutoCodeMigration.Apply(ctx, result.Migration.ID); err != nil {
+			return result, err
+		}
+		result.Migration.Status = model.AutoCodeMigrationApplied
+	}
+	return result, nil
+}
+
+// render 只渲染模板 不注入代码 返回文件路径与内容
+func (s *autoCodeTemplate) render(ctx context.Context, info request.AutoCode, entity model.SysAutoCodePackage) (map[string]string, error) {
+	info.AutoMigrate = false
+	codes, _, _, err := s.generate(ctx, info, entity)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(codes))
+	for key, builder := range codes {
+		files[key] = builder.String()
+	}
+	return files, nil
+}
+
 func (s *autoCodeTemplate) generate(ctx context.Context, info request.AutoCode, entity model.SysAutoCodePackage) (map[string]strings.Builder, map[string]string, map[string]utilsAst.Ast, error) {
-	templates, asts, _, err := AutoCodePackage.templates(ctx, entity, info)
+	tx, templates, injections, err := s.stage(ctx, info, entity)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 	code := make(map[string]strings.Builder)
-	for key, create := range templates {
+	for _, path := range tx.Paths() {
+		content, err := tx.Read(path)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var builder strings.Builder
+		builder.Write(content)
+		code[path] = builder
+	}
+	return code, templates, injections, nil
+}
+
+// stage 在事务中生成文件与注入代码 不写入磁盘
+func (s *autoCodeTemplate) stage(ctx context.Context, info request.AutoCode, entity model.SysAutoCodePackage) (*utilsAst.Transaction, map[string]string, map[string]utilsAst.Ast, error) {
+	tx := utilsAst.NewTransaction(global.GVA_CONFIG.AutoCode.Root)
+	templates, injections, err := s.stageIn(ctx, tx, info, entity)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tx, templates, injections, nil
+}
+
+// stageIn 在已有的事务中生成文件与注入代码 批量生成时多个结构体共用一个事务
+func (s *autoCodeTemplate) stageIn(ctx context.Context, tx *utilsAst.Transaction, info request.AutoCode, entity model.SysAutoCodePackage) (map[string]string, map[string]utilsAst.Ast, error) {
+	if err := AutoCodeTemplatePack.Prepare(ctx, entity, &info); err != nil {
+		return nil, nil, err
+	}
+	templates, asts, _, err := AutoCodePackage.templates(ctx, entity, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := make([]string, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		create := templates[key]
 		var files *template.Template
 		files, err = template.ParseFiles(key)
 		if err != nil {
-			return nil, nil, nil, errors.Wrapf(err, "[filpath:%s]读取模版文件失败!", key)
+			return nil, nil, errors.Wrapf(err, "[filpath:%s]读取模版文件失败!", key)
 		}
 		var builder strings.Builder
 		err = files.Execute(&builder, info)
 		if err != nil {
-			return nil, nil, nil, errors.Wrapf(err, "[filpath:%s]生成文件失败!", create)
+			return nil, nil, errors.Wrapf(err, "[filpath:%s]生成文件失败!", create)
+		}
+		if err = tx.Write(create, []byte(builder.String())); err != nil {
+			return nil, nil, err
 		}
-		code[create] = builder
 	} // 生成文件
 	injections := make(map[string]utilsAst.Ast, len(asts))
 	if info.AutoMigrate {
-		for key, value := range asts {
-			keys := strings.Split(key, "=>")
-			if len(keys) == 2 {
-				if keys[1] == utilsAst.TypePluginInitializeV2 {
+		keys = keys[:0]
+		for key := range asts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := asts[key]
+			names := strings.Split(key, "=>")
+			if len(names) == 2 {
+				if names[1] == utilsAst.TypePluginInitializeV2 {
 					continue
 				}
-				var builder strings.Builder
-				parse, _ := value.Parse("", &builder)
-				if parse != nil {
-					_ = value.Injection(parse)
-					err = value.Format("", &builder, parse)
-					if err != nil {
-						return nil, nil, nil, err
-					}
-					code[keys[0]] = builder
-					injections[keys[1]] = value
-					fmt.Println(keys[0], "注入成功!")
+				if err = tx.Inject(value); err != nil {
+					fmt.Println(names[0], "注入失败, 跳过:", err)
+					continue
 				}
+				injections[names[1]] = value
+				fmt.Println(names[0], "注入成功!")
 			}
 		}
 	} // 注入代码
-	return code, templates, injections, nil
+	return templates, injections, nil
 }
 
 func (s *autoCodeTemplate) AddFunc(info request.AutoFunc) error {
@@ -0,0 +1,439 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	common "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/ast"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var AutoCodeTemplatePack = new(autoCodeTemplatePack)
+
+type autoCodeTemplatePack struct{}
+
+// templatePackName 模板包名 与目录名一致
+var templatePackName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// templatePackTarget 渲染生成路径的数据 Server 与 Web 为相对项目根目录的服务端与前端目录
+type templatePackTarget struct {
+	request.AutoCode
+	Server string
+	Web    string
+}
+
+// Dir 模板包目录
+func (s *autoCodeTemplatePack) Dir(name string) string {
+	return filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "resource", "packs", name)
+}
+
+// Manifest 读取并校验模板包清单 dir 为模板包目录
+func (s *autoCodeTemplatePack) Manifest(dir string) (manifest model.TemplatePackManifest, err error) {
+	bytes, err := os.ReadFile(filepath.Join(dir, model.TemplatePackManifestName))
+	if err != nil {
+		return manifest, errors.Wrapf(err, "[dir:%s]读取模板包清单失败!", dir)
+	}
+	if err = json.Unmarshal(bytes, &manifest); err != nil {
+		return manifest, errors.Wrapf(err, "[dir:%s]解析模板包清单失败!", dir)
+	}
+	if !templatePackName.MatchString(manifest.Name) {
+		return manifest, errors.Errorf("模板包名[%s]不合法!", manifest.Name)
+	}
+	if _, err = utils.SemverSatisfies(manifest.Version, "*"); err != nil {
+		return manifest, errors.Wrapf(err, "[pack:%s]", manifest.Name)
+	}
+	switch manifest.Extends {
+	case "", "package", "plugin":
+	default:
+		return manifest, errors.Errorf("[pack:%s]只能继承 package 或 plugin 模板!", manifest.Name)
+	}
+	for _, file := range manifest.Files {
+		if file.Template == "" || file.Target == "" {
+			return manifest, errors.Errorf("[pack:%s]模板文件与生成路径不能为空!", manifest.Name)
+		}
+		if filepath.IsAbs(file.Template) || strings.Contains(file.Template, "..") {
+			return manifest, errors.Errorf("[pack:%s][template:%s]模板文件必须位于模板包目录中!", manifest.Name, file.Template)
+		}
+		if _, err = os.Stat(filepath.Join(dir, file.Template)); err != nil {
+			return manifest, errors.Wrapf(err, "[pack:%s][template:%s]模板文件不存在!", manifest.Name, file.Template)
+		}
+		if _, err = template.New(file.Template).Parse(file.Target); err != nil {
+			return manifest, errors.Wrapf(err, "[pack:%s][target:%s]生成路径不合法!", manifest.Name, file.Target)
+		}
+		for _, flag := range file.Requires {
+			if _, ok := s.flag(request.AutoCode{}, strings.TrimPrefix(flag, "!")); !ok {
+				return manifest, errors.Errorf("[pack:%s][template:%s]未知的开关[%s]!", manifest.Name, file.Template, flag)
+			}
+		}
+	}
+	for _, variable := range manifest.Variables {
+		if variable.Name == "" {
+			return manifest, errors.Errorf("[pack:%s]变量名不能为空!", manifest.Name)
+		}
+	}
+	return manifest, nil
+}
+
+// Sync 扫描 resource/packs 登记模板包 版本变化时更新登记信息, 目录已删除的模板包同时删除登记
+func (s *autoCodeTemplatePack) Sync(ctx context.Context) error {
+	root := filepath.Dir(s.Dir("_"))
+	entries, err := os.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "读取模板包文件夹失败!")
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		} // . 开头的为安装中的临时目录
+		manifest, mErr := s.Manifest(filepath.Join(root, entry.Name()))
+		if mErr != nil {
+			global.GVA_LOG.Warn("跳过不合法的模板包: " + mErr.Error())
+			continue
+		}
+		if manifest.Name != entry.Name() {
+			global.GVA_LOG.Warn("跳过模板包[" + entry.Name() + "]: 清单中的模板包名与目录名不一致")
+			continue
+		}
+		names = append(names, manifest.Name)
+		if err = s.register(ctx, manifest); err != nil {
+			return err
+		}
+	}
+	db := global.GVA_DB.WithContext(ctx).Where("1 = 1")
+	if len(names) > 0 {
+		db = db.Where("name NOT IN ?", names)
+	}
+	return db.Delete(&model.SysAutoCodeTemplatePack{}).Error
+}
+
+// register 登记模板包 已登记时更新
+func (s *autoCodeTemplatePack) register(ctx context.Context, manifest model.TemplatePackManifest) error {
+	var entity model.SysAutoCodeTemplatePack
+	err := global.GVA_DB.WithContext(ctx).Where("name = ?", manifest.Name).First(&entity).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	entity.Name = manifest.Name
+	entity.Version = manifest.Version
+	entity.Language = manifest.Language
+	entity.Extends = manifest.Extends
+	entity.Manifest = manifest
+	return global.GVA_DB.WithContext(ctx).Save(&entity).Error
+}
+
+// List 已安装的模板包
+func (s *autoCodeTemplatePack) List(ctx context.Context) (list []model.SysAutoCodeTemplatePack, err error) {
+	if err = s.Sync(ctx); err != nil {
+		return nil, err
+	}
+	err = global.GVA_DB.WithContext(ctx).Order("name").Find(&list).Error
+	return list, err
+}
+
+// Install 安装模板包 压缩包根目录或唯一的一级目录中须包含清单文件
+// 已安装相同或更高版本时拒绝安装, force 为 true 时覆盖
+func (s *autoCodeTemplatePack) Install(ctx context.Context, file *multipart.FileHeader, force bool) (entity model.SysAutoCodeTemplatePack, err error) {
+	packs := filepath.Dir(s.Dir("_"))
+	if err = os.MkdirAll(packs, os.ModePerm); err != nil {
+		return entity, errors.Wrap(err, "创建模板包文件夹失败!")
+	}
+	temp, err := os.MkdirTemp(packs, ".install-")
+	if err != nil {
+		return entity, err
+	} // 与模板包位于同一目录 安装时直接重命名
+	defer os.RemoveAll(temp)
+	src, err := file.Open()
+	if err != nil {
+		return entity, err
+	}
+	defer src.Close()
+	zipFile := filepath.Join(temp, "pack.zip")
+	out, err := os.Create(zipFile)
+	if err != nil {
+		return entity, err
+	}
+	_, err = io.Copy(out, src)
+	_ = out.Close()
+	if err != nil {
+		return entity, err
+	}
+	unzip := filepath.Join(temp, "pack")
+	if _, err = utils.Unzip(zipFile, unzip); err != nil {
+		return entity, errors.Wrap(err, "解压模板包失败!")
+	}
+	dir := unzip
+	if _, err = os.Stat(filepath.Join(dir, model.TemplatePackManifestName)); os.IsNotExist(err) {
+		entries, _ := os.ReadDir(dir)
+		entries = filterDirEntries(entries)
+		if len(entries) != 1 || !entries[0].IsDir() {
+			return entity, errors.New("压缩包中未找到模板包清单!")
+		}
+		dir = filepath.Join(dir, entries[0].Name())
+	}
+	manifest, err := s.Manifest(dir)
+	if err != nil {
+		return entity, err
+	}
+	err = global.GVA_DB.WithContext(ctx).Where("name = ?", manifest.Name).First(&entity).Error
+	if err == nil && !force && utils.SemverCompare(manifest.Version, entity.Version) <= 0 {
+		return entity, errors.Errorf("已安装模板包[%s@%s], 不能安装相同或更低的版本[%s]!", entity.Name, entity.Version, manifest.Version)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, err
+	}
+	target := s.Dir(manifest.Name)
+	backup := filepath.Join(packs, "."+manifest.Name+".bak")
+	_ = os.RemoveAll(backup)
+	if _, err = os.Stat(target); err == nil {
+		if err = os.Rename(target, backup); err != nil {
+			return entity, errors.Wrap(err, "备份已安装的模板包失败!")
+		}
+	}
+	if err = os.Rename(dir, target); err != nil {
+		_ = os.Rename(backup, target)
+		return entity, errors.Wrap(err, "安装模板包失败!")
+	}
+	if err = s.register(ctx, manifest); err != nil {
+		_ = os.RemoveAll(target)
+		_ = os.Rename(backup, target)
+		return entity, err
+	}
+	_ = os.RemoveAll(backup)
+	err = global.GVA_DB.WithContext(ctx).Where("name = ?", manifest.Name).First(&entity).Error
+	return entity, err
+}
+
+// Delete 删除模板包 仍被包设置为默认模板包时拒绝删除
+func (s *autoCodeTemplatePack) Delete(ctx context.Context, info common.GetById) error {
+	var entity model.SysAutoCodeTemplatePack
+	err := global.GVA_DB.WithContext(ctx).First(&entity, info.Uint()).Error
+	if err != nil {
+		return errors.Wrap(err, "模板包不存在!")
+	}
+	var packages []string
+	err = global.GVA_DB.WithContext(ctx).Model(&model.SysAutoCodePackage{}).Where("pack = ?", entity.Name).Pluck("package_name", &packages).Error
+	if err != nil {
+		return err
+	}
+	if len(packages) > 0 {
+		return errors.Errorf("模板包[%s]是包[%s]的默认模板包, 不能删除!", entity.Name, strings.Join(packages, ","))
+	}
+	if err = os.RemoveAll(s.Dir(entity.Name)); err != nil {
+		return errors.Wrap(err, "删除模板包文件失败!")
+	}
+	return global.GVA_DB.WithContext(ctx).Delete(&entity).Error
+}
+
+// load 读取已安装的模板包清单 未连接数据库时(如命令行工具)直接读取模板包目录
+func (s *autoCodeTemplatePack) load(ctx context.Context, name string) (model.TemplatePackManifest, error) {
+	if global.GVA_DB == nil {
+		return s.Manifest(s.Dir(name))
+	}
+	var entity model.SysAutoCodeTemplatePack
+	err := global.GVA_DB.WithContext(ctx).Where("name = ?", name).First(&entity).Error
+	if err != nil {
+		return entity.Manifest, errors.Wrapf(err, "模板包[%s]未安装!", name)
+	}
+	return entity.Manifest, nil
+}
+
+// Check 检查模板包是否已安装且可用于包
+func (s *autoCodeTemplatePack) Check(ctx context.Context, entity model.SysAutoCodePackage, name string) (model.TemplatePackManifest, error) {
+	manifest, err := s.load(ctx, name)
+	if err != nil {
+		return manifest, err
+	}
+	if manifest.Extends != "" && manifest.Extends != entity.Template {
+		return manifest, errors.Errorf("模板包[%s]继承 %s 模板, 不能用于 %s 模板的包[%s]!", manifest.Name, manifest.Extends, entity.Template, entity.PackageName)
+	}
+	return manifest, nil
+}
+
+// Prepare 确定本次生成使用的模板包 未指定时使用包的默认模板包, 并为模板包变量填充默认值
+func (s *autoCodeTemplatePack) Prepare(ctx context.Context, entity model.SysAutoCodePackage, info *request.AutoCode) error {
+	if info.Pack == "" {
+		info.Pack = entity.Pack
+	}
+	if info.Pack == "" {
+		return nil
+	}
+	manifest, err := s.Check(ctx, entity, info.Pack)
+	if err != nil {
+		return err
+	}
+	variables := make(map[string]string, len(manifest.Variables))
+	for key, value := range info.Variables {
+		variables[key] = value
+	}
+	for _, variable := range manifest.Variables {
+		if variables[variable.Name] != "" {
+			continue
+		}
+		if variable.Default != "" {
+			variables[variable.Name] = variable.Default
+			continue
+		}
+		if variable.Required {
+			return errors.Errorf("模板包[%s]需要变量[%s]%s!", manifest.Name, variable.Name, variable.Description)
+		}
+	}
+	info.Variables = variables
+	return nil
+}
+
+// Files 预览生成时使用的模板与生成路径 包含继承的内置模板与因开关未开启而不生成的文件
+func (s *autoCodeTemplatePack) Files(ctx context.Context, info request.AutoCode) ([]response.AutoCodePackFile, error) {
+	var entity model.SysAutoCodePackage
+	err := global.GVA_DB.WithContext(ctx).Where("package_name = ?", info.Package).First(&entity).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "查询包失败!")
+	}
+	if err = s.Prepare(ctx, entity, &info); err != nil {
+		return nil, err
+	}
+	var files []response.AutoCodePackFile
+	if info.Pack == "" {
+		code, _, _, err := AutoCodePackage.templates(ctx, entity, info)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range code {
+			files = append(files, response.AutoCodePackFile{Template: s.rel(key), Target: s.rel(value), Source: entity.Template, Emit: true})
+		}
+	} else {
+		_, _, _, files, err = s.plan(ctx, entity, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Target < files[j].Target })
+	return files, nil
+}
+
+// templates 使用模板包时的模板与生成路径 与 autoCodePackage.templates 的返回值一致
+func (s *autoCodeTemplatePack) templates(ctx context.Context, entity model.SysAutoCodePackage, info request.AutoCode) (code map[string]string, asts map[string]ast.Ast, creates map[string]string, err error) {
+	code, asts, creates, _, err = s.plan(ctx, entity, info)
+	return code, asts, creates, err
+}
+
+func (s *autoCodeTemplatePack) plan(ctx context.Context, entity model.SysAutoCodePackage, info request.AutoCode) (code map[string]string, asts map[string]ast.Ast, creates map[string]string, files []response.AutoCodePackFile, err error) {
+	manifest, err := s.load(ctx, info.Pack)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	pack := info.Pack
+	info.Pack = ""
+	code = make(map[string]string)
+	asts = make(map[string]ast.Ast)
+	creates = make(map[string]string)
+	if manifest.Extends != "" {
+		base := entity
+		base.Template = manifest.Extends
+		var baseCode map[string]string
+		baseCode, asts, creates, err = AutoCodePackage.templates(ctx, base, info)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		resource := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server, "resource", manifest.Extends)
+		for key, value := range baseCode {
+			file := response.AutoCodePackFile{Template: s.rel(key), Target: s.rel(value), Source: manifest.Extends, Emit: true}
+			rel, _ := filepath.Rel(resource, key)
+			for _, exclude := range manifest.Excludes {
+				exclude = strings.Trim(filepath.ToSlash(exclude), "/")
+				if rel = filepath.ToSlash(rel); rel == exclude || strings.HasPrefix(rel, exclude+"/") {
+					file.Emit = false
+					file.Reason = "模板包排除了 " + exclude
+					break
+				}
+			}
+			if file.Emit {
+				code[key] = value
+			}
+			files = append(files, file)
+		}
+	}
+	dir := s.Dir(pack)
+	data := templatePackTarget{AutoCode: info, Server: global.GVA_CONFIG.AutoCode.Server, Web: filepath.ToSlash(global.GVA_CONFIG.AutoCode.WebRoot())}
+	for _, item := range manifest.Files {
+		key := filepath.Join(dir, filepath.FromSlash(item.Template))
+		var builder strings.Builder
+		tmpl, tErr := template.New(item.Template).Parse(item.Target)
+		if tErr == nil {
+			tErr = tmpl.Execute(&builder, data)
+		}
+		if tErr != nil {
+			return nil, nil, nil, nil, errors.Wrapf(tErr, "[pack:%s][target:%s]渲染生成路径失败!", pack, item.Target)
+		}
+		target := filepath.Clean(filepath.FromSlash(builder.String()))
+		if filepath.IsAbs(target) || target == "." || strings.HasPrefix(target, "..") {
+			return nil, nil, nil, nil, errors.Errorf("[pack:%s][target:%s]生成路径必须位于项目目录中!", pack, builder.String())
+		}
+		value := filepath.Join(global.GVA_CONFIG.AutoCode.Root, target)
+		file := response.AutoCodePackFile{Template: s.rel(key), Target: s.rel(value), Source: pack, Emit: true}
+		for _, flag := range item.Requires {
+			want := !strings.HasPrefix(flag, "!")
+			name := strings.TrimPrefix(flag, "!")
+			if on, _ := s.flag(info, name); on != want {
+				file.Emit = false
+				file.Reason = "需要" + map[bool]string{true: "开启 ", false: "关闭 "}[want] + name
+				break
+			}
+		}
+		if file.Emit {
+			code[key] = value
+		}
+		files = append(files, file)
+	}
+	return code, asts, creates, files, nil
+}
+
+// flag 按 json 名读取 AutoCode 的开关
+func (s *autoCodeTemplatePack) flag(info request.AutoCode, name string) (on bool, ok bool) {
+	value := reflect.ValueOf(info)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Type.Kind() != reflect.Bool {
+			continue
+		}
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == name && tag != "-" {
+			return value.Field(i).Bool(), true
+		}
+	}
+	return false, false
+}
+
+func (s *autoCodeTemplatePack) rel(path string) string {
+	if rel, err := filepath.Rel(global.GVA_CONFIG.AutoCode.Root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// filterDirEntries 忽略 macOS 压缩时附带的文件
+func filterDirEntries(entries []os.DirEntry) []os.DirEntry {
+	result := entries[:0]
+	for _, entry := range entries {
+		if entry.Name() == "__MACOSX" || entry.Name() == ".DS_Store" {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -3,7 +3,9 @@ package system
 import (
 	"context"
 	"encoding/json"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
 	"reflect"
 	"testing"
 )
@@ -31,6 +33,7 @@ func Test_autoCodeTemplate_Create(t *testing.T) {
 }
 
 func Test_autoCodeTemplate_Preview(t *testing.T) {
+	testdb.Open(t, "", &model.SysAutoCodePackage{})
 	type args struct {
 		ctx  context.Context
 		info request.AutoCode
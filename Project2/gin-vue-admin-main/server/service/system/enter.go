@@ -16,9 +16,19 @@ type ServiceGroup struct {
 	DictionaryDetailService
 	AuthorityBtnService
 	SysExportTemplateService
+	SysExportScheduleService
+	SysJobService
+	SysRetentionService
+	OpenApiService
+	PluginLifecycleService
+	PluginHostService
+	DBConnectionService
 
-	AutoCodePlugin   autoCodePlugin
-	AutoCodePackage  autoCodePackage
-	AutoCodeHistory  autoCodeHistory
-	AutoCodeTemplate autoCodeTemplate
+	AutoCodePlugin       autoCodePlugin
+	AutoCodePackage      autoCodePackage
+	AutoCodeHistory      autoCodeHistory
+	AutoCodeTemplate     autoCodeTemplate
+	AutoCodeMigration    autoCodeMigration
+	AutoCodeTemplatePack autoCodeTemplatePack
+	AutoCodeImport       autoCodeImport
 }
@@ -22,6 +22,7 @@ type ApiService struct{}
 var ApiServiceApp = new(ApiService)
 
 func (apiService *ApiService) CreateApi(api system.SysApi) (err error) {
+	defer OpenApiServiceApp.Invalidate() // api信息变化后重新构建OpenAPI文档
 	if !errors.Is(global.GVA_DB.Where("path = ? AND method = ?", api.Path, api.Method).First(&system.SysApi{}).Error, gorm.ErrRecordNotFound) {
 		return errors.New("存在相同api")
 	}
@@ -133,6 +134,7 @@ func (apiService *ApiService) IgnoreApi(ignoreApi system.SysIgnoreApi) (err erro
 }
 
 func (apiService *ApiService) EnterSyncApi(syncApis systemRes.SysSyncApis) (err error) {
+	defer OpenApiServiceApp.Invalidate()
 	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
 		var txErr error
 		if syncApis.NewApis != nil && len(syncApis.NewApis) > 0 {
@@ -159,6 +161,7 @@ func (apiService *ApiService) EnterSyncApi(syncApis systemRes.SysSyncApis) (err
 //@return: err error
 
 func (apiService *ApiService) DeleteApi(api system.SysApi) (err error) {
+	defer OpenApiServiceApp.Invalidate()
 	var entity system.SysApi
 	err = global.GVA_DB.First(&entity, "id = ?", api.ID).Error // 根据id查询api记录
 	if errors.Is(err, gorm.ErrRecordNotFound) {                // api记录不存在
@@ -256,6 +259,7 @@ func (apiService *ApiService) GetApiById(id int) (api system.SysApi, err error)
 //@return: err error
 
 func (apiService *ApiService) UpdateApi(api system.SysApi) (err error) {
+	defer OpenApiServiceApp.Invalidate()
 	var oldA system.SysApi
 	err = global.GVA_DB.First(&oldA, "id = ?", api.ID).Error
 	if oldA.Path != api.Path || oldA.Method != api.Method {
@@ -290,6 +294,7 @@ func (apiService *ApiService) UpdateApi(api system.SysApi) (err error) {
 //@return: err error
 
 func (apiService *ApiService) DeleteApisByIds(ids request.IdsReq) (err error) {
+	defer OpenApiServiceApp.Invalidate()
 	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
 		var apis []system.SysApi
 		err = tx.Find(&apis, "id in ?", ids.Ids).Error
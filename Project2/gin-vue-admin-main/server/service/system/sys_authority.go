@@ -10,6 +10,7 @@ import (
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	cacheUtils "github.com/flipped-aurora/gin-vue-admin/server/utils/cache"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +26,16 @@ type AuthorityService struct{}
 
 var AuthorityServiceApp = new(AuthorityService)
 
+// authorityCache 角色信息及其数据权限 以角色ID区分
+var authorityCache = cacheUtils.NewKey[system.SysAuthority]("authority", 0)
+
+// invalidateAuthority 角色变更后删除角色信息与菜单树缓存
+func invalidateAuthority(authorityId uint) {
+	id := strconv.FormatUint(uint64(authorityId), 10)
+	authorityCache.Invalidate(id)
+	menuTreeCache.Invalidate(id)
+}
+
 func (authorityService *AuthorityService) CreateAuthority(auth system.SysAuthority) (authority system.SysAuthority, err error) {
 
 	if err = global.GVA_DB.Where("authority_id = ?", auth.AuthorityId).First(&system.SysAuthority{}).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -49,7 +60,9 @@ func (authorityService *AuthorityService) CreateAuthority(auth system.SysAuthori
 		}
 		return CasbinServiceApp.AddPolicies(tx, rules)
 	})
-
+	if e == nil {
+		invalidateAuthority(auth.AuthorityId)
+	}
 	return auth, e
 }
 
@@ -80,6 +93,7 @@ func (authorityService *AuthorityService) CopyAuthority(copyInfo response.SysAut
 	if err != nil {
 		return
 	}
+	invalidateAuthority(copyInfo.Authority.AuthorityId)
 
 	var btns []system.SysAuthorityBtn
 
@@ -119,6 +133,10 @@ func (authorityService *AuthorityService) UpdateAuthority(auth system.SysAuthori
 		return system.SysAuthority{}, errors.New("查询角色数据失败")
 	}
 	err = global.GVA_DB.Model(&oldAuthority).Updates(&auth).Error
+	if err == nil {
+		// 其他角色的数据权限中包含该角色的信息
+		authorityCache.InvalidateAll()
+	}
 	return auth, err
 }
 
@@ -142,6 +160,8 @@ func (authorityService *AuthorityService) DeleteAuthority(auth *system.SysAuthor
 		return errors.New("此角色存在子角色不允许删除")
 	}
 
+	defer authorityCache.InvalidateAll()
+	defer menuTreeCache.Invalidate(strconv.FormatUint(uint64(auth.AuthorityId), 10))
 	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
 		var err error
 		if err = tx.Preload("SysBaseMenus").Preload("DataAuthorityId").Where("authority_id = ?", auth.AuthorityId).First(auth).Unscoped().Delete(auth).Error; err != nil {
@@ -205,8 +225,10 @@ func (authorityService *AuthorityService) GetAuthorityInfoList(info request.Page
 //@return: sa system.SysAuthority, err error
 
 func (authorityService *AuthorityService) GetAuthorityInfo(auth system.SysAuthority) (sa system.SysAuthority, err error) {
-	err = global.GVA_DB.Preload("DataAuthorityId").Where("authority_id = ?", auth.AuthorityId).First(&sa).Error
-	return sa, err
+	return authorityCache.Get(strconv.FormatUint(uint64(auth.AuthorityId), 10), func() (sa system.SysAuthority, err error) {
+		err = cacheDB().Preload("DataAuthorityId").Where("authority_id = ?", auth.AuthorityId).First(&sa).Error
+		return sa, err
+	})
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -219,6 +241,7 @@ func (authorityService *AuthorityService) SetDataAuthority(auth system.SysAuthor
 	var s system.SysAuthority
 	global.GVA_DB.Preload("DataAuthorityId").First(&s, "authority_id = ?", auth.AuthorityId)
 	err := global.GVA_DB.Model(&s).Association("DataAuthorityId").Replace(&auth.DataAuthorityId)
+	authorityCache.Invalidate(strconv.FormatUint(uint64(auth.AuthorityId), 10))
 	return err
 }
 
@@ -232,6 +255,7 @@ func (authorityService *AuthorityService) SetMenuAuthority(auth *system.SysAutho
 	var s system.SysAuthority
 	global.GVA_DB.Preload("SysBaseMenus").First(&s, "authority_id = ?", auth.AuthorityId)
 	err := global.GVA_DB.Model(&s).Association("SysBaseMenus").Replace(&auth.SysBaseMenus)
+	menuTreeCache.Invalidate(strconv.FormatUint(uint64(auth.AuthorityId), 10))
 	return err
 }
 
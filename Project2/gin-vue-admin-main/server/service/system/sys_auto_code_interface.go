@@ -11,6 +11,8 @@ type Database interface {
 	GetDB(businessDB string) (data []response.Db, err error)
 	GetTables(businessDB string, dbName string) (data []response.Table, err error)
 	GetColumn(businessDB string, tableName string, dbName string) (data []response.Column, err error)
+	GetIndexes(businessDB string, tableName string, dbName string) (data []response.Index, err error)
+	GetForeignKeys(businessDB string, tableName string, dbName string) (data []response.ForeignKey, err error)
 }
 
 func (autoCodeService *AutoCodeService) Database(businessDB string) Database {
@@ -31,22 +33,20 @@ func (autoCodeService *AutoCodeService) Database(businessDB string) Database {
 			return AutoCodeMysql
 		}
 	} else {
-		for _, info := range global.GVA_CONFIG.DBList {
-			if info.AliasName == businessDB {
-				switch info.Type {
-				case "mysql":
-					return AutoCodeMysql
-				case "mssql":
-					return AutoCodeMssql
-				case "pgsql":
-					return AutoCodePgsql
-				case "oracle":
-					return AutoCodeOracle
-				case "sqlite":
-					return AutoCodeSqlite
-				default:
-					return AutoCodeMysql
-				}
+		if info, ok := global.GetGlobalDBInfo(businessDB); ok {
+			switch info.Type {
+			case "mysql":
+				return AutoCodeMysql
+			case "mssql":
+				return AutoCodeMssql
+			case "pgsql":
+				return AutoCodePgsql
+			case "oracle":
+				return AutoCodeOracle
+			case "sqlite":
+				return AutoCodeSqlite
+			default:
+				return AutoCodeMysql
 			}
 		}
 		return AutoCodeMysql
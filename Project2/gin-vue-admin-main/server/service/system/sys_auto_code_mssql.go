@@ -19,7 +19,7 @@ func (s *autoCodeMssql) GetDB(businessDB string) (data []response.Db, err error)
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Scan(&entities).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Scan(&entities).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
 	}
 	return entities, err
 }
@@ -34,7 +34,7 @@ func (s *autoCodeMssql) GetTables(businessDB string, dbName string) (data []resp
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Scan(&entities).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Scan(&entities).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
 	}
 
 	return entities, err
@@ -53,7 +53,9 @@ SELECT
     CASE
         WHEN pk.object_id IS NOT NULL THEN 1
         ELSE 0
-    END AS primary_key
+    END AS primary_key,
+    sc.is_nullable AS nullable,
+    dc.definition AS default_value
 FROM
     %s.sys.columns sc
 JOIN
@@ -66,14 +68,83 @@ LEFT JOIN
     %s.sys.index_columns sic ON sic.object_id = si.object_id AND sic.index_id = si.index_id AND sic.column_id = sc.column_id
 LEFT JOIN
     %s.sys.key_constraints pk ON pk.object_id = si.object_id
+LEFT JOIN
+    %s.sys.default_constraints dc ON dc.object_id = sc.default_object_id
 WHERE
     st.is_user_defined=0 AND sc.object_id = so.object_id
-`, dbName, dbName, tableName, dbName, dbName, dbName)
+`, dbName, dbName, tableName, dbName, dbName, dbName, dbName)
+
+	if businessDB == "" {
+		err = global.GVA_DB.Raw(sql).Scan(&entities).Error
+	} else {
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
+	}
+
+	return entities, err
+}
+
+// GetIndexes 获取指定数据库和指定数据表的所有索引
+func (s *autoCodeMssql) GetIndexes(businessDB string, tableName string, dbName string) (data []response.Index, err error) {
+	var entities []response.Index
+	sql := fmt.Sprintf(`
+SELECT
+    si.name AS index_name,
+    sc.name AS column_name,
+    si.is_unique AS is_unique,
+    si.is_primary_key AS primary_key
+FROM
+    %s.sys.indexes si
+JOIN
+    %s.sys.objects so ON so.object_id = si.object_id AND so.name='%s' AND so.type='U'
+JOIN
+    %s.sys.index_columns sic ON sic.object_id = si.object_id AND sic.index_id = si.index_id
+JOIN
+    %s.sys.columns sc ON sc.object_id = sic.object_id AND sc.column_id = sic.column_id
+WHERE
+    si.name IS NOT NULL AND sic.is_included_column = 0
+ORDER BY
+    si.name, sic.key_ordinal
+`, dbName, dbName, tableName, dbName, dbName)
+
+	if businessDB == "" {
+		err = global.GVA_DB.Raw(sql).Scan(&entities).Error
+	} else {
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
+	}
+
+	return entities, err
+}
+
+// GetForeignKeys 获取指定数据库和指定数据表的所有外键
+func (s *autoCodeMssql) GetForeignKeys(businessDB string, tableName string, dbName string) (data []response.ForeignKey, err error) {
+	var entities []response.ForeignKey
+	sql := fmt.Sprintf(`
+SELECT
+    fk.name AS constraint_name,
+    pc.name AS column_name,
+    ro.name AS referenced_table,
+    rc.name AS referenced_column,
+    REPLACE(fk.delete_referential_action_desc, '_', ' ') AS on_delete
+FROM
+    %s.sys.foreign_keys fk
+JOIN
+    %s.sys.objects so ON so.object_id = fk.parent_object_id AND so.name='%s' AND so.type='U'
+JOIN
+    %s.sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+JOIN
+    %s.sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+JOIN
+    %s.sys.objects ro ON ro.object_id = fkc.referenced_object_id
+JOIN
+    %s.sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+ORDER BY
+    fk.name, fkc.constraint_column_id
+`, dbName, dbName, tableName, dbName, dbName, dbName, dbName)
 
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Scan(&entities).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Scan(&entities).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
 	}
 
 	return entities, err
@@ -18,7 +18,7 @@ func (s *autoCodeMysql) GetDB(businessDB string) (data []response.Db, err error)
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Scan(&entities).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Scan(&entities).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
 	}
 	return entities, err
 }
@@ -32,7 +32,7 @@ func (s *autoCodeMysql) GetTables(businessDB string, dbName string) (data []resp
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql, dbName).Scan(&entities).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql, dbName).Scan(&entities).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, dbName).Scan(&entities).Error
 	}
 
 	return entities, err
@@ -54,10 +54,13 @@ func (s *autoCodeMysql) GetColumn(businessDB string, tableName string, dbName st
         WHEN 'decimal' THEN CONCAT_WS(',', c.NUMERIC_PRECISION, c.NUMERIC_SCALE)
         WHEN 'int' THEN c.NUMERIC_PRECISION
         WHEN 'bigint' THEN c.NUMERIC_PRECISION
+        WHEN 'enum' THEN SUBSTRING(c.COLUMN_TYPE, 6, CHAR_LENGTH(c.COLUMN_TYPE) - 6)
         ELSE '' 
     END AS data_type_long,
     c.COLUMN_COMMENT column_comment,
-    CASE WHEN kcu.COLUMN_NAME IS NOT NULL THEN 1 ELSE 0 END AS primary_key
+    CASE WHEN kcu.COLUMN_NAME IS NOT NULL THEN 1 ELSE 0 END AS primary_key,
+    CASE c.IS_NULLABLE WHEN 'YES' THEN 1 ELSE 0 END AS nullable,
+    c.COLUMN_DEFAULT default_value
 FROM 
     INFORMATION_SCHEMA.COLUMNS c
 LEFT JOIN 
@@ -73,8 +76,63 @@ WHERE
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql, tableName, dbName).Scan(&entities).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql, tableName, dbName).Scan(&entities).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, tableName, dbName).Scan(&entities).Error
 	}
 
 	return entities, err
 }
+
+// GetIndexes 获取指定数据库和指定数据表的所有索引
+func (s *autoCodeMysql) GetIndexes(businessDB string, tableName string, dbName string) (data []response.Index, err error) {
+	var entities []response.Index
+	sql := `
+SELECT
+    INDEX_NAME index_name,
+    COLUMN_NAME column_name,
+    CASE NON_UNIQUE WHEN 0 THEN 1 ELSE 0 END AS is_unique,
+    CASE INDEX_NAME WHEN 'PRIMARY' THEN 1 ELSE 0 END AS primary_key
+FROM
+    INFORMATION_SCHEMA.STATISTICS
+WHERE
+    TABLE_NAME = ?
+    AND TABLE_SCHEMA = ?
+ORDER BY
+    INDEX_NAME, SEQ_IN_INDEX;`
+	if businessDB == "" {
+		err = global.GVA_DB.Raw(sql, tableName, dbName).Scan(&entities).Error
+	} else {
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, tableName, dbName).Scan(&entities).Error
+	}
+	return entities, err
+}
+
+// GetForeignKeys 获取指定数据库和指定数据表的所有外键
+func (s *autoCodeMysql) GetForeignKeys(businessDB string, tableName string, dbName string) (data []response.ForeignKey, err error) {
+	var entities []response.ForeignKey
+	sql := `
+SELECT
+    kcu.CONSTRAINT_NAME constraint_name,
+    kcu.COLUMN_NAME column_name,
+    kcu.REFERENCED_TABLE_NAME referenced_table,
+    kcu.REFERENCED_COLUMN_NAME referenced_column,
+    rc.DELETE_RULE on_delete
+FROM
+    INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+JOIN
+    INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+ON
+    rc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA
+    AND rc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME
+WHERE
+    kcu.TABLE_NAME = ?
+    AND kcu.TABLE_SCHEMA = ?
+    AND kcu.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY
+    kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION;`
+	if businessDB == "" {
+		err = global.GVA_DB.Raw(sql, tableName, dbName).Scan(&entities).Error
+	} else {
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, tableName, dbName).Scan(&entities).Error
+	}
+	return entities, err
+}
@@ -15,7 +15,7 @@ type autoCodeOracle struct{}
 func (s *autoCodeOracle) GetDB(businessDB string) (data []response.Db, err error) {
 	var entities []response.Db
 	sql := `SELECT lower(username) AS "database" FROM all_users`
-	err = global.GVA_DBList[businessDB].Raw(sql).Scan(&entities).Error
+	err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
 	return entities, err
 }
 
@@ -26,7 +26,7 @@ func (s *autoCodeOracle) GetTables(businessDB string, dbName string) (data []res
 	var entities []response.Table
 	sql := `select lower(table_name) as "table_name" from all_tables where lower(owner) = ?`
 
-	err = global.GVA_DBList[businessDB].Raw(sql, dbName).Scan(&entities).Error
+	err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, dbName).Scan(&entities).Error
 	return entities, err
 }
 
@@ -41,7 +41,8 @@ func (s *autoCodeOracle) GetColumn(businessDB string, tableName string, dbName s
     (CASE WHEN a.DATA_TYPE = 'NUMBER' AND a.DATA_SCALE=0 THEN 'int' else lower(a.DATA_TYPE) end)  as "data_type",
     (CASE WHEN a.DATA_TYPE = 'NUMBER' THEN a.DATA_PRECISION else a.DATA_LENGTH end) as "data_type_long",
     b.COMMENTS as "column_comment",
-    (CASE WHEN pk.COLUMN_NAME IS NOT NULL THEN 1 ELSE 0 END) as "primary_key"
+    (CASE WHEN pk.COLUMN_NAME IS NOT NULL THEN 1 ELSE 0 END) as "primary_key",
+    (CASE WHEN a.NULLABLE = 'Y' THEN 1 ELSE 0 END) as "nullable"
 FROM
     all_tab_columns a
 JOIN
@@ -64,6 +65,62 @@ WHERE
     AND lower(a.OWNER) = ?;
 `
 
-	err = global.GVA_DBList[businessDB].Raw(sql, tableName, dbName).Scan(&entities).Error
+	err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, tableName, dbName).Scan(&entities).Error
+	return entities, err
+}
+
+// GetIndexes 获取指定数据库和指定数据表的所有索引
+func (s *autoCodeOracle) GetIndexes(businessDB string, tableName string, dbName string) (data []response.Index, err error) {
+	var entities []response.Index
+	sql := `
+SELECT
+    lower(ic.INDEX_NAME) as "index_name",
+    lower(ic.COLUMN_NAME) as "column_name",
+    (CASE WHEN i.UNIQUENESS = 'UNIQUE' THEN 1 ELSE 0 END) as "is_unique",
+    (CASE WHEN c.CONSTRAINT_NAME IS NOT NULL THEN 1 ELSE 0 END) as "primary_key"
+FROM
+    all_ind_columns ic
+JOIN
+    all_indexes i ON i.OWNER = ic.INDEX_OWNER AND i.INDEX_NAME = ic.INDEX_NAME
+LEFT JOIN
+    all_constraints c ON c.OWNER = i.TABLE_OWNER AND c.INDEX_NAME = i.INDEX_NAME AND c.CONSTRAINT_TYPE = 'P'
+WHERE
+    lower(ic.TABLE_NAME) = ?
+    AND lower(ic.TABLE_OWNER) = ?
+ORDER BY
+    ic.INDEX_NAME, ic.COLUMN_POSITION
+`
+
+	err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, tableName, dbName).Scan(&entities).Error
+	return entities, err
+}
+
+// GetForeignKeys 获取指定数据库和指定数据表的所有外键
+func (s *autoCodeOracle) GetForeignKeys(businessDB string, tableName string, dbName string) (data []response.ForeignKey, err error) {
+	var entities []response.ForeignKey
+	sql := `
+SELECT
+    lower(c.CONSTRAINT_NAME) as "constraint_name",
+    lower(cc.COLUMN_NAME) as "column_name",
+    lower(rc.TABLE_NAME) as "referenced_table",
+    lower(rcc.COLUMN_NAME) as "referenced_column",
+    c.DELETE_RULE as "on_delete"
+FROM
+    all_constraints c
+JOIN
+    all_cons_columns cc ON cc.OWNER = c.OWNER AND cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+JOIN
+    all_constraints rc ON rc.OWNER = c.R_OWNER AND rc.CONSTRAINT_NAME = c.R_CONSTRAINT_NAME
+JOIN
+    all_cons_columns rcc ON rcc.OWNER = rc.OWNER AND rcc.CONSTRAINT_NAME = rc.CONSTRAINT_NAME AND rcc.POSITION = cc.POSITION
+WHERE
+    c.CONSTRAINT_TYPE = 'R'
+    AND lower(c.TABLE_NAME) = ?
+    AND lower(c.OWNER) = ?
+ORDER BY
+    c.CONSTRAINT_NAME, cc.POSITION
+`
+
+	err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql, tableName, dbName).Scan(&entities).Error
 	return entities, err
 }
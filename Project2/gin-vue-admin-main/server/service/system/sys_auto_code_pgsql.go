@@ -18,7 +18,7 @@ func (a *autoCodePgsql) GetDB(businessDB string) (data []response.Db, err error)
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Scan(&entities).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Scan(&entities).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&entities).Error
 	}
 
 	return entities, err
@@ -33,7 +33,7 @@ func (a *autoCodePgsql) GetTables(businessDB string, dbName string) (data []resp
 
 	db := global.GVA_DB
 	if businessDB != "" {
-		db = global.GVA_DBList[businessDB]
+		db = global.MustGetGlobalDBByDBName(businessDB)
 	}
 
 	err = db.Raw(sql, dbName, "public").Scan(&entities).Error
@@ -111,7 +111,9 @@ SELECT
                 attrelid = conrelid
               AND attname = psc.column_name
         )]
-    ) > 0 AS primary_key
+    ) > 0 AS primary_key,
+    psc.is_nullable = 'YES' AS nullable,
+    psc.column_default AS default_value
 FROM
     INFORMATION_SCHEMA.COLUMNS psc
 WHERE
@@ -124,7 +126,74 @@ WHERE
 	//sql = strings.ReplaceAll(sql, "@table_name", tableName)
 	db := global.GVA_DB
 	if businessDB != "" {
-		db = global.GVA_DBList[businessDB]
+		db = global.MustGetGlobalDBByDBName(businessDB)
+	}
+
+	err = db.Raw(sql, dbName, tableName).Scan(&entities).Error
+	return entities, err
+}
+
+// GetIndexes 获取指定数据库和指定数据表的所有索引
+func (a *autoCodePgsql) GetIndexes(businessDB string, tableName string, dbName string) (data []response.Index, err error) {
+	sql := `
+SELECT
+    i.relname AS index_name,
+    pa.attname AS column_name,
+    ix.indisunique AS is_unique,
+    ix.indisprimary AS primary_key
+FROM
+    pg_class t
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_index ix ON ix.indrelid = t.oid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+JOIN pg_attribute pa ON pa.attrelid = t.oid AND pa.attnum = k.attnum
+WHERE
+  current_database() = ?
+  AND n.nspname = 'public'
+  AND t.relname = ?
+ORDER BY
+    i.relname, k.ord;
+`
+	var entities []response.Index
+	db := global.GVA_DB
+	if businessDB != "" {
+		db = global.MustGetGlobalDBByDBName(businessDB)
+	}
+
+	err = db.Raw(sql, dbName, tableName).Scan(&entities).Error
+	return entities, err
+}
+
+// GetForeignKeys 获取指定数据库和指定数据表的所有外键
+func (a *autoCodePgsql) GetForeignKeys(businessDB string, tableName string, dbName string) (data []response.ForeignKey, err error) {
+	sql := `
+SELECT
+    tc.constraint_name AS constraint_name,
+    kcu.column_name AS column_name,
+    ccu.table_name AS referenced_table,
+    ccu.column_name AS referenced_column,
+    rc.delete_rule AS on_delete
+FROM
+    information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+    ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+JOIN information_schema.referential_constraints rc
+    ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+JOIN information_schema.constraint_column_usage ccu
+    ON ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+WHERE
+  tc.constraint_type = 'FOREIGN KEY'
+  AND tc.table_catalog = ?
+  AND tc.table_schema = 'public'
+  AND tc.table_name = ?
+ORDER BY
+    tc.constraint_name, kcu.ordinal_position;
+`
+	var entities []response.ForeignKey
+	db := global.GVA_DB
+	if businessDB != "" {
+		db = global.MustGetGlobalDBByDBName(businessDB)
 	}
 
 	err = db.Raw(sql, dbName, tableName).Scan(&entities).Error
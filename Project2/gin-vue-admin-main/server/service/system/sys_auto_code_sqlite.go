@@ -24,7 +24,7 @@ func (a *autoCodeSqlite) GetDB(businessDB string) (data []response.Db, err error
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Find(&databaseList).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Find(&databaseList).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Find(&databaseList).Error
 	}
 	for _, database := range databaseList {
 		if database.File != "" {
@@ -49,7 +49,7 @@ func (a *autoCodeSqlite) GetTables(businessDB string, dbName string) (data []res
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Find(&tabelNames).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Find(&tabelNames).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Find(&tabelNames).Error
 	}
 	for _, tabelName := range tabelNames {
 		entities = append(entities, response.Table{tabelName})
@@ -64,21 +64,95 @@ func (a *autoCodeSqlite) GetColumn(businessDB string, tableName string, dbName s
 	var entities []response.Column
 	sql := fmt.Sprintf("PRAGMA table_info(%s);", tableName)
 	var columnInfos []struct {
-		Name string `gorm:"column:name"`
-		Type string `gorm:"column:type"`
-		Pk   int    `gorm:"column:pk"`
+		Name      string  `gorm:"column:name"`
+		Type      string  `gorm:"column:type"`
+		Pk        int     `gorm:"column:pk"`
+		NotNull   int     `gorm:"column:notnull"`
+		DfltValue *string `gorm:"column:dflt_value"`
 	}
 	if businessDB == "" {
 		err = global.GVA_DB.Raw(sql).Scan(&columnInfos).Error
 	} else {
-		err = global.GVA_DBList[businessDB].Raw(sql).Scan(&columnInfos).Error
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&columnInfos).Error
 	}
 	for _, columnInfo := range columnInfos {
-		entities = append(entities, response.Column{
+		column := response.Column{
 			ColumnName: columnInfo.Name,
 			DataType:   columnInfo.Type,
 			PrimaryKey: columnInfo.Pk == 1,
-		})
+			Nullable:   columnInfo.NotNull == 0 && columnInfo.Pk == 0,
+		}
+		if columnInfo.DfltValue != nil {
+			column.DefaultValue = *columnInfo.DfltValue
+		}
+		entities = append(entities, column)
+	}
+	return entities, err
+}
+
+// GetIndexes 获取指定数据表的所有索引
+func (a *autoCodeSqlite) GetIndexes(businessDB string, tableName string, dbName string) (data []response.Index, err error) {
+	var entities []response.Index
+	db := global.GVA_DB
+	if businessDB != "" {
+		db = global.MustGetGlobalDBByDBName(businessDB)
+	}
+	var indexList []struct {
+		Name   string `gorm:"column:name"`
+		Unique int    `gorm:"column:unique"`
+		Origin string `gorm:"column:origin"`
+	}
+	err = db.Raw(fmt.Sprintf("PRAGMA index_list(%s);", tableName)).Scan(&indexList).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexList {
+		var indexInfos []struct {
+			Name string `gorm:"column:name"`
+		}
+		err = db.Raw(fmt.Sprintf("PRAGMA index_info(%s);", index.Name)).Scan(&indexInfos).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range indexInfos {
+			entities = append(entities, response.Index{
+				IndexName:  index.Name,
+				ColumnName: info.Name,
+				Unique:     index.Unique == 1,
+				PrimaryKey: index.Origin == "pk",
+			})
+		}
+	}
+	return entities, nil
+}
+
+// GetForeignKeys 获取指定数据表的所有外键
+func (a *autoCodeSqlite) GetForeignKeys(businessDB string, tableName string, dbName string) (data []response.ForeignKey, err error) {
+	var entities []response.ForeignKey
+	sql := fmt.Sprintf("PRAGMA foreign_key_list(%s);", tableName)
+	var foreignKeys []struct {
+		Id       int     `gorm:"column:id"`
+		Table    string  `gorm:"column:table"`
+		From     string  `gorm:"column:from"`
+		To       *string `gorm:"column:to"`
+		OnDelete string  `gorm:"column:on_delete"`
+	}
+	if businessDB == "" {
+		err = global.GVA_DB.Raw(sql).Scan(&foreignKeys).Error
+	} else {
+		err = global.MustGetGlobalDBByDBName(businessDB).Raw(sql).Scan(&foreignKeys).Error
+	}
+	for _, foreignKey := range foreignKeys {
+		entity := response.ForeignKey{
+			ConstraintName:  fmt.Sprintf("fk_%s_%d", tableName, foreignKey.Id),
+			ColumnName:      foreignKey.From,
+			ReferencedTable: foreignKey.Table,
+			OnDelete:        foreignKey.OnDelete,
+		}
+		if foreignKey.To != nil {
+			entity.ReferencedColumn = *foreignKey.To
+		} // 为空时引用关联表的主键
+		entities = append(entities, entity)
 	}
 	return entities, err
 }
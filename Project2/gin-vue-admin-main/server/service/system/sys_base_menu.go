@@ -19,37 +19,39 @@ type BaseMenuService struct{}
 var BaseMenuServiceApp = new(BaseMenuService)
 
 func (baseMenuService *BaseMenuService) DeleteBaseMenu(id int) (err error) {
-	err = global.GVA_DB.First(&system.SysBaseMenu{}, "parent_id = ?", id).Error
-	if err != nil {
-		return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+	defer menuTreeCache.InvalidateAll()
+	return global.GVA_DB.Transaction(func(tx *gorm.DB) error {
+		return baseMenuService.deleteBaseMenu(tx, id)
+	})
+}
 
-			err = tx.Delete(&system.SysBaseMenu{}, "id = ?", id).Error
-			if err != nil {
-				return err
-			}
+// deleteBaseMenu 在事务中删除菜单及其参数、按钮与角色关联 存在子菜单时拒绝删除
+func (baseMenuService *BaseMenuService) deleteBaseMenu(tx *gorm.DB, id int) (err error) {
+	err = tx.First(&system.SysBaseMenu{}, "parent_id = ?", id).Error
+	if err == nil {
+		return errors.New("此菜单存在子菜单不可删除")
+	}
 
-			err = tx.Delete(&system.SysBaseMenuParameter{}, "sys_base_menu_id = ?", id).Error
-			if err != nil {
-				return err
-			}
+	err = tx.Delete(&system.SysBaseMenu{}, "id = ?", id).Error
+	if err != nil {
+		return err
+	}
 
-			err = tx.Delete(&system.SysBaseMenuBtn{}, "sys_base_menu_id = ?", id).Error
-			if err != nil {
-				return err
-			}
-			err = tx.Delete(&system.SysAuthorityBtn{}, "sys_menu_id = ?", id).Error
-			if err != nil {
-				return err
-			}
+	err = tx.Delete(&system.SysBaseMenuParameter{}, "sys_base_menu_id = ?", id).Error
+	if err != nil {
+		return err
+	}
 
-			err = tx.Delete(&system.SysAuthorityMenu{}, "sys_base_menu_id = ?", id).Error
-			if err != nil {
-				return err
-			}
-			return nil
-		})
+	err = tx.Delete(&system.SysBaseMenuBtn{}, "sys_base_menu_id = ?", id).Error
+	if err != nil {
+		return err
 	}
-	return errors.New("此菜单存在子菜单不可删除")
+	err = tx.Delete(&system.SysAuthorityBtn{}, "sys_menu_id = ?", id).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Delete(&system.SysAuthorityMenu{}, "sys_base_menu_id = ?", id).Error
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
@@ -121,6 +123,9 @@ func (baseMenuService *BaseMenuService) UpdateBaseMenu(menu system.SysBaseMenu)
 		}
 		return nil
 	})
+	if err == nil {
+		menuTreeCache.InvalidateAll()
+	}
 	return err
 }
 
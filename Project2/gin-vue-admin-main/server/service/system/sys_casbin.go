@@ -1,6 +1,7 @@
 package system
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"sync"
@@ -12,6 +13,8 @@ import (
 	gormadapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	cacheUtils "github.com/flipped-aurora/gin-vue-admin/server/utils/cache"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/replica"
 	_ "github.com/go-sql-driver/mysql"
 	"go.uber.org/zap"
 )
@@ -26,6 +29,9 @@ type CasbinService struct{}
 
 var CasbinServiceApp = new(CasbinService)
 
+// CasbinCacheNamespace 策略变更通知 其他实例收到后重新加载策略
+const CasbinCacheNamespace = "casbin"
+
 func (casbinService *CasbinService) UpdateCasbin(AuthorityID uint, casbinInfos []request.CasbinInfo) error {
 	authorityId := strconv.Itoa(int(AuthorityID))
 	casbinService.ClearCasbin(0, authorityId)
@@ -44,6 +50,7 @@ func (casbinService *CasbinService) UpdateCasbin(AuthorityID uint, casbinInfos [
 	if !success {
 		return errors.New("存在相同api,添加失败,请联系管理员")
 	}
+	cacheUtils.InvalidateAll(CasbinCacheNamespace)
 	return nil
 }
 
@@ -63,6 +70,7 @@ func (casbinService *CasbinService) UpdateCasbinApi(oldPath string, newPath stri
 	if err != nil {
 		return err
 	}
+	cacheUtils.InvalidateAll(CasbinCacheNamespace)
 	return err
 }
 
@@ -94,6 +102,9 @@ func (casbinService *CasbinService) GetPolicyPathByAuthorityId(AuthorityID uint)
 func (casbinService *CasbinService) ClearCasbin(v int, p ...string) bool {
 	e := casbinService.Casbin()
 	success, _ := e.RemoveFilteredPolicy(v, p...)
+	if success {
+		cacheUtils.InvalidateAll(CasbinCacheNamespace)
+	}
 	return success
 }
 
@@ -143,6 +154,9 @@ func (casbinService *CasbinService) AddPolicies(db *gorm.DB, rules [][]string) e
 func (CasbinService *CasbinService) FreshCasbin() (err error) {
 	e := CasbinService.Casbin()
 	err = e.LoadPolicy()
+	if err == nil {
+		cacheUtils.InvalidateAll(CasbinCacheNamespace)
+	}
 	return err
 }
 
@@ -158,7 +172,8 @@ var (
 
 func (casbinService *CasbinService) Casbin() *casbin.SyncedCachedEnforcer {
 	once.Do(func() {
-		a, err := gormadapter.NewAdapterByDB(global.GVA_DB)
+		// 策略修改后立即重新加载 必须从主库读取
+		a, err := gormadapter.NewAdapterByDB(global.GVA_DB.WithContext(replica.UsePrimary(context.Background())))
 		if err != nil {
 			zap.L().Error("适配数据库失败请检查casbin表是否为InnoDB引擎!", zap.Error(err))
 			return
@@ -0,0 +1,342 @@
+package system
+
+import (
+	"context"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
+	"github.com/glebarez/sqlite"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// dbConnectionTimeout 建立连接时的检测超时
+	dbConnectionTimeout = 5 * time.Second
+	// dbRetireDelay 替换或移除业务库后延迟关闭旧连接 让已取得旧连接的请求执行完成
+	dbRetireDelay = time.Minute
+)
+
+type DBConnectionService struct{}
+
+var DBConnectionServiceApp = new(DBConnectionService)
+
+// CreateDBConnection 新增业务库 连接成功后才保存并注册
+func (s *DBConnectionService) CreateDBConnection(req systemReq.SysDBConnection) (err error) {
+	conn := req.SysDBConnection
+	if err = s.check(conn); err != nil {
+		return err
+	}
+	if !errors.Is(global.GVA_DB.Where("alias_name = ?", conn.AliasName).First(&system.SysDBConnection{}).Error, gorm.ErrRecordNotFound) {
+		return errors.Errorf("业务库 %s 已存在", conn.AliasName)
+	}
+	key, err := s.secretKey()
+	if err != nil {
+		return err
+	}
+	if conn.Password, err = utils.AesEncrypt(key, req.Password); err != nil {
+		return err
+	}
+	info := conn.SpecializedDB(req.Password)
+	db, err := s.open(info)
+	if err != nil {
+		return err
+	}
+	if err = global.GVA_DB.Create(&conn).Error; err != nil {
+		s.close(db)
+		return err
+	}
+	s.retire(global.SetGlobalDB(info, db))
+	return nil
+}
+
+// UpdateDBConnection 更新业务库 新连接建立成功后才替换旧连接 密码为空时保留原密码
+func (s *DBConnectionService) UpdateDBConnection(req systemReq.SysDBConnection) (err error) {
+	var old system.SysDBConnection
+	if err = global.GVA_DB.Where("id = ?", req.ID).First(&old).Error; err != nil {
+		return err
+	}
+	conn := req.SysDBConnection
+	if err = s.check(conn); err != nil {
+		return err
+	}
+	if conn.AliasName != old.AliasName && !errors.Is(global.GVA_DB.Where("alias_name = ?", conn.AliasName).First(&system.SysDBConnection{}).Error, gorm.ErrRecordNotFound) {
+		return errors.Errorf("业务库 %s 已存在", conn.AliasName)
+	}
+	key, err := s.secretKey()
+	if err != nil {
+		return err
+	}
+	password := req.Password
+	if password == "" {
+		if password, err = utils.AesDecrypt(key, old.Password); err != nil {
+			return errors.Wrap(err, "读取原密码失败 请重新填写密码")
+		}
+	}
+	if conn.Password, err = utils.AesEncrypt(key, password); err != nil {
+		return err
+	}
+	info := conn.SpecializedDB(password)
+	db, err := s.open(info)
+	if err != nil {
+		return err
+	}
+	err = global.GVA_DB.Model(&system.SysDBConnection{}).Where("id = ?", old.ID).
+		Select("alias_name", "type", "path", "port", "db_name", "username", "password", "config", "prefix", "singular", "max_idle_conns", "max_open_conns", "disable", "remark").
+		Updates(&conn).Error
+	if err != nil {
+		s.close(db)
+		return err
+	}
+	if conn.AliasName != old.AliasName {
+		s.retire(global.RemoveGlobalDB(old.AliasName))
+	}
+	s.retire(global.SetGlobalDB(info, db))
+	return nil
+}
+
+// DeleteDBConnection 删除业务库 仍被导出模板使用时拒绝删除
+func (s *DBConnectionService) DeleteDBConnection(id uint) (err error) {
+	var conn system.SysDBConnection
+	if err = global.GVA_DB.Where("id = ?", id).First(&conn).Error; err != nil {
+		return err
+	}
+	var count int64
+	if err = global.GVA_DB.Model(&system.SysExportTemplate{}).Where("db_name = ?", conn.AliasName).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.Errorf("业务库 %s 仍被 %d 个导出模板使用", conn.AliasName, count)
+	}
+	// 别名唯一 物理删除以便重新添加同名业务库
+	if err = global.GVA_DB.Unscoped().Delete(&conn).Error; err != nil {
+		return err
+	}
+	s.retire(global.RemoveGlobalDB(conn.AliasName))
+	return nil
+}
+
+// TestDBConnection 测试业务库连接 不保存 更新已有业务库时密码为空则使用原密码
+func (s *DBConnectionService) TestDBConnection(req systemReq.SysDBConnection) (err error) {
+	password := req.Password
+	if password == "" && req.ID != 0 {
+		var old system.SysDBConnection
+		if err = global.GVA_DB.Where("id = ?", req.ID).First(&old).Error; err != nil {
+			return err
+		}
+		key, err := s.secretKey()
+		if err != nil {
+			return err
+		}
+		if password, err = utils.AesDecrypt(key, old.Password); err != nil {
+			return errors.Wrap(err, "读取原密码失败 请重新填写密码")
+		}
+	}
+	info := req.SpecializedDB(password)
+	info.Disable = false
+	db, err := s.open(info)
+	if err != nil {
+		return err
+	}
+	s.close(db)
+	return nil
+}
+
+// GetDBConnection 根据id获取业务库
+func (s *DBConnectionService) GetDBConnection(id uint) (conn system.SysDBConnection, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&conn).Error
+	return
+}
+
+// GetDBConnectionInfoList 分页获取业务库
+func (s *DBConnectionService) GetDBConnectionInfoList(info systemReq.SysDBConnectionSearch) (list []system.SysDBConnection, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysDBConnection{})
+	if info.AliasName != "" {
+		db = db.Where("alias_name LIKE ?", "%"+info.AliasName+"%")
+	}
+	if info.Type != "" {
+		db = db.Where("type = ?", info.Type)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	if limit != 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	err = db.Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// LoadDBConnections 启动时注册系统中维护的业务库 单个业务库连接失败不影响其他业务库
+func (s *DBConnectionService) LoadDBConnections() error {
+	var list []system.SysDBConnection
+	if err := global.GVA_DB.Find(&list).Error; err != nil {
+		return err
+	}
+	key, keyErr := s.secretKey()
+	for _, conn := range list {
+		if s.configured(conn.AliasName) {
+			global.GVA_LOG.Warn("业务库与配置文件中的同名 已忽略", zap.String("aliasName", conn.AliasName))
+			continue
+		}
+		if keyErr != nil {
+			global.GVA_LOG.Error("解密业务库密码失败!", zap.String("aliasName", conn.AliasName), zap.Error(keyErr))
+			global.SetGlobalDB(conn.SpecializedDB(""), nil)
+			continue
+		}
+		password, err := utils.AesDecrypt(key, conn.Password)
+		if err != nil {
+			global.GVA_LOG.Error("解密业务库密码失败!", zap.String("aliasName", conn.AliasName), zap.Error(err))
+			global.SetGlobalDB(conn.SpecializedDB(""), nil)
+			continue
+		}
+		info := conn.SpecializedDB(password)
+		db, err := s.open(info)
+		if err != nil {
+			global.GVA_LOG.Error("连接业务库失败!", zap.String("aliasName", conn.AliasName), zap.Error(err))
+		}
+		s.retire(global.SetGlobalDB(info, db))
+	}
+	return nil
+}
+
+// check 校验业务库 别名不能与系统库及配置文件中的业务库重名
+func (s *DBConnectionService) check(conn system.SysDBConnection) error {
+	if conn.AliasName == "" {
+		return errors.New("别名不能为空")
+	}
+	if conn.AliasName == "system" || s.configured(conn.AliasName) {
+		return errors.Errorf("业务库 %s 已在配置文件中定义", conn.AliasName)
+	}
+	switch conn.Type {
+	case "mysql", "pgsql", "mssql", "oracle", "sqlite":
+	default:
+		return errors.Errorf("不支持的数据库类型 %s", conn.Type)
+	}
+	if conn.Dbname == "" {
+		return errors.New("数据库名不能为空")
+	}
+	return nil
+}
+
+// configured 别名是否为配置文件中的业务库
+func (s *DBConnectionService) configured(aliasName string) bool {
+	for _, info := range global.GVA_CONFIG.DBList {
+		if info.AliasName == aliasName {
+			return true
+		}
+	}
+	return false
+}
+
+// secretKey 加密业务库密码的密钥 不使用 jwt.signing-key 避免更换签名密钥后无法解密 或泄露签名密钥时密码一同泄露
+func (s *DBConnectionService) secretKey() (string, error) {
+	if key := global.GVA_CONFIG.System.SecretKey; key != "" {
+		return key, nil
+	}
+	return "", errors.New("未配置 system.secret-key 无法加密保存业务库密码")
+}
+
+// open 按配置建立连接并检测 禁用的业务库返回 nil
+func (s *DBConnectionService) open(info config.SpecializedDB) (*gorm.DB, error) {
+	if info.Disable {
+		return nil, nil
+	}
+	gormConfig := &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			TablePrefix:   info.Prefix,
+			SingularTable: info.Singular,
+		},
+		DisableForeignKeyConstraintWhenMigrating: true,
+		DisableAutomaticPing:                     true,
+	}
+	if global.GVA_DB != nil {
+		gormConfig.Logger = global.GVA_DB.Config.Logger
+	} else {
+		gormConfig.Logger = logger.Default.LogMode(logger.Silent)
+	}
+	db, err := gorm.Open(s.dialector(info), gormConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "连接数据库失败")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dbConnectionTimeout)
+	defer cancel()
+	if err = sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
+		return nil, errors.Wrap(err, "连接数据库失败")
+	}
+	sqlDB.SetMaxIdleConns(info.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(info.MaxOpenConns)
+	if global.GVA_CONFIG.Metrics.Enabled && global.GVA_CONFIG.Metrics.Gorm {
+		if err = metrics.UseGorm(db, info.AliasName); err != nil {
+			global.GVA_LOG.Error("开启数据库指标失败!", zap.String("db", info.AliasName), zap.Error(err))
+		}
+	}
+	if global.GVA_CONFIG.Tracing.Enabled && global.GVA_CONFIG.Tracing.Gorm {
+		if err = tracing.UseGorm(db, info.AliasName); err != nil {
+			global.GVA_LOG.Error("开启数据库追踪失败!", zap.String("db", info.AliasName), zap.Error(err))
+		}
+	}
+	return db, nil
+}
+
+// dialector 业务库的驱动 oracle 与配置文件中的业务库一致使用 mysql 驱动
+func (s *DBConnectionService) dialector(info config.SpecializedDB) gorm.Dialector {
+	switch info.Type {
+	case "pgsql":
+		p := config.Pgsql{GeneralDB: info.GeneralDB}
+		return postgres.New(postgres.Config{DSN: p.Dsn()})
+	case "mssql":
+		m := config.Mssql{GeneralDB: info.GeneralDB}
+		return sqlserver.New(sqlserver.Config{DSN: m.Dsn(), DefaultStringSize: 191})
+	case "oracle":
+		o := config.Oracle{GeneralDB: info.GeneralDB}
+		return mysql.New(mysql.Config{DSN: o.Dsn(), DefaultStringSize: 191, SkipInitializeWithVersion: true})
+	case "sqlite":
+		l := config.Sqlite{GeneralDB: info.GeneralDB}
+		return sqlite.Open(l.Dsn())
+	default:
+		m := config.Mysql{GeneralDB: info.GeneralDB}
+		return mysql.New(mysql.Config{DSN: m.Dsn(), DefaultStringSize: 191})
+	}
+}
+
+// retire 延迟关闭被替换或移除的连接
+func (s *DBConnectionService) retire(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	time.AfterFunc(dbRetireDelay, func() { s.close(db) })
+}
+
+func (s *DBConnectionService) close(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	if err = sqlDB.Close(); err != nil {
+		global.GVA_LOG.Error("关闭业务库连接失败!", zap.Error(err))
+	}
+}
@@ -0,0 +1,115 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+)
+
+// openDBConnectionTestDB 打开系统库并设置 system.secret-key 测试结束后移除注册的业务库
+func openDBConnectionTestDB(t *testing.T, secretKey string) {
+	testdb.Open(t, "", &system.SysDBConnection{}, &system.SysExportTemplate{})
+	systemConfig := global.GVA_CONFIG.System
+	jwtConfig := global.GVA_CONFIG.JWT
+	global.GVA_CONFIG.System.SecretKey = secretKey
+	global.GVA_CONFIG.JWT.SigningKey = "signing-key"
+	t.Cleanup(func() {
+		global.GVA_CONFIG.System = systemConfig
+		global.GVA_CONFIG.JWT = jwtConfig
+		global.RemoveGlobalDB("biz")
+		global.RemoveGlobalDB("biz2")
+	})
+}
+
+func newDBConnectionRequest(t *testing.T, aliasName string) systemReq.SysDBConnection {
+	return systemReq.SysDBConnection{
+		SysDBConnection: system.SysDBConnection{AliasName: aliasName, Type: "sqlite", Path: t.TempDir(), Dbname: aliasName},
+		Password:        "db-password",
+	}
+}
+
+func TestDBConnectionService_RequireSecretKey(t *testing.T) {
+	openDBConnectionTestDB(t, "")
+	service := DBConnectionServiceApp
+	if err := service.CreateDBConnection(newDBConnectionRequest(t, "biz")); err == nil {
+		t.Fatal("create without system.secret-key should fail")
+	}
+	var count int64
+	global.GVA_DB.Model(&system.SysDBConnection{}).Count(&count)
+	if count != 0 || global.GetGlobalDBByDBName("biz") != nil {
+		t.Fatal("rejected connection must not be saved or registered")
+	}
+
+	// 密钥为空时不回退到 jwt.signing-key
+	password, err := utils.AesEncrypt("signing-key", "db-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := newDBConnectionRequest(t, "biz").SysDBConnection
+	conn.Password = password
+	if err = global.GVA_DB.Create(&conn).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err = service.UpdateDBConnection(systemReq.SysDBConnection{SysDBConnection: conn}); err == nil {
+		t.Fatal("update without system.secret-key should fail")
+	}
+	if err = service.LoadDBConnections(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := global.GetGlobalDBInfo("biz"); !ok || global.GetGlobalDBByDBName("biz") != nil {
+		t.Fatal("connection should be registered without opening it")
+	}
+}
+
+func TestDBConnectionService_Lifecycle(t *testing.T) {
+	openDBConnectionTestDB(t, "secret-key")
+	service := DBConnectionServiceApp
+	if err := service.CreateDBConnection(newDBConnectionRequest(t, "biz")); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.CreateDBConnection(newDBConnectionRequest(t, "biz")); err == nil {
+		t.Fatal("duplicate alias should fail")
+	}
+	var conn system.SysDBConnection
+	if err := global.GVA_DB.Where("alias_name = ?", "biz").First(&conn).Error; err != nil {
+		t.Fatal(err)
+	}
+	if password, err := utils.AesDecrypt("secret-key", conn.Password); err != nil || password != "db-password" {
+		t.Fatalf("password should be encrypted with system.secret-key: %q %v", password, err)
+	}
+	if global.GetGlobalDBByDBName("biz") == nil {
+		t.Fatal("created connection should be registered")
+	}
+
+	// 密码为空时保留原密码
+	conn.AliasName = "biz2"
+	if err := service.UpdateDBConnection(systemReq.SysDBConnection{SysDBConnection: conn}); err != nil {
+		t.Fatal(err)
+	}
+	var updated system.SysDBConnection
+	global.GVA_DB.First(&updated, conn.ID)
+	if password, err := utils.AesDecrypt("secret-key", updated.Password); err != nil || password != "db-password" {
+		t.Fatalf("empty password should keep the old one: %q %v", password, err)
+	}
+	if global.GetGlobalDBByDBName("biz") != nil || global.GetGlobalDBByDBName("biz2") == nil {
+		t.Fatal("renamed connection should replace the old alias")
+	}
+
+	if err := global.GVA_DB.Create(&system.SysExportTemplate{DBName: "biz2", Name: "demo"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeleteDBConnection(conn.ID); err == nil {
+		t.Fatal("connection used by export templates should not be deleted")
+	}
+	global.GVA_DB.Where("db_name = ?", "biz2").Delete(&system.SysExportTemplate{})
+	if err := service.DeleteDBConnection(conn.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := global.GetGlobalDBInfo("biz2"); ok {
+		t.Fatal("deleted connection should be unregistered")
+	}
+}
@@ -2,9 +2,11 @@ package system
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	cacheUtils "github.com/flipped-aurora/gin-vue-admin/server/utils/cache"
 	"gorm.io/gorm"
 )
 
@@ -18,11 +20,15 @@ type DictionaryService struct{}
 
 var DictionaryServiceApp = new(DictionaryService)
 
+// dictionaryCache 字典及其启用的详情 以查询条件区分 字典或详情变更时整体失效
+var dictionaryCache = cacheUtils.NewKey[system.SysDictionary]("dictionary", 0)
+
 func (dictionaryService *DictionaryService) CreateSysDictionary(sysDictionary system.SysDictionary) (err error) {
 	if (!errors.Is(global.GVA_DB.First(&system.SysDictionary{}, "type = ?", sysDictionary.Type).Error, gorm.ErrRecordNotFound)) {
 		return errors.New("存在相同的type，不允许创建")
 	}
 	err = global.GVA_DB.Create(&sysDictionary).Error
+	dictionaryCache.InvalidateAll()
 	return err
 }
 
@@ -33,6 +39,7 @@ func (dictionaryService *DictionaryService) CreateSysDictionary(sysDictionary sy
 //@return: err error
 
 func (dictionaryService *DictionaryService) DeleteSysDictionary(sysDictionary system.SysDictionary) (err error) {
+	defer dictionaryCache.InvalidateAll()
 	err = global.GVA_DB.Where("id = ?", sysDictionary.ID).Preload("SysDictionaryDetails").First(&sysDictionary).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.New("请不要搞事")
@@ -76,6 +83,7 @@ func (dictionaryService *DictionaryService) UpdateSysDictionary(sysDictionary *s
 		}
 	}
 	err = global.GVA_DB.Model(&dict).Updates(sysDictionaryMap).Error
+	dictionaryCache.InvalidateAll()
 	return err
 }
 
@@ -92,10 +100,13 @@ func (dictionaryService *DictionaryService) GetSysDictionary(Type string, Id uin
 	} else {
 		flag = *status
 	}
-	err = global.GVA_DB.Where("(type = ? OR id = ?) and status = ?", Type, Id, flag).Preload("SysDictionaryDetails", func(db *gorm.DB) *gorm.DB {
-		return db.Where("status = ?", true).Order("sort")
-	}).First(&sysDictionary).Error
-	return
+	id := Type + ":" + strconv.FormatUint(uint64(Id), 10) + ":" + strconv.FormatBool(flag)
+	return dictionaryCache.Get(id, func() (sysDictionary system.SysDictionary, err error) {
+		err = cacheDB().Where("(type = ? OR id = ?) and status = ?", Type, Id, flag).Preload("SysDictionaryDetails", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", true).Order("sort")
+		}).First(&sysDictionary).Error
+		return
+	})
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
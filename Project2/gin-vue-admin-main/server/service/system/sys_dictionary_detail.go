@@ -18,6 +18,7 @@ var DictionaryDetailServiceApp = new(DictionaryDetailService)
 
 func (dictionaryDetailService *DictionaryDetailService) CreateSysDictionaryDetail(sysDictionaryDetail system.SysDictionaryDetail) (err error) {
 	err = global.GVA_DB.Create(&sysDictionaryDetail).Error
+	dictionaryCache.InvalidateAll()
 	return err
 }
 
@@ -29,6 +30,7 @@ func (dictionaryDetailService *DictionaryDetailService) CreateSysDictionaryDetai
 
 func (dictionaryDetailService *DictionaryDetailService) DeleteSysDictionaryDetail(sysDictionaryDetail system.SysDictionaryDetail) (err error) {
 	err = global.GVA_DB.Delete(&sysDictionaryDetail).Error
+	dictionaryCache.InvalidateAll()
 	return err
 }
 
@@ -40,6 +42,7 @@ func (dictionaryDetailService *DictionaryDetailService) DeleteSysDictionaryDetai
 
 func (dictionaryDetailService *DictionaryDetailService) UpdateSysDictionaryDetail(sysDictionaryDetail *system.SysDictionaryDetail) (err error) {
 	err = global.GVA_DB.Save(sysDictionaryDetail).Error
+	dictionaryCache.InvalidateAll()
 	return err
 }
 
@@ -0,0 +1,78 @@
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/replica"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"gorm.io/gorm"
+)
+
+// openDictionaryReplica 打开主库与副本 两边各有一条同名字典 Desc 分别为 primary 与 replica
+// Sticky 设为极短 模拟写入后副本延迟超过 Sticky 的情况
+func openDictionaryReplica(t *testing.T) {
+	enabled := true
+	replicaDB := testdb.Open(t, "", &system.SysDictionary{}, &system.SysDictionaryDetail{})
+	primaryDB := testdb.Open(t, "", &system.SysDictionary{}, &system.SysDictionaryDetail{})
+	for desc, db := range map[string]*gorm.DB{"replica": replicaDB, "primary": primaryDB} {
+		if err := db.Create(&system.SysDictionary{Name: "性别", Type: "gender", Status: &enabled, Desc: desc}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolver := replica.New(replica.Options{Dialect: "sqlite", Interval: time.Hour, Sticky: time.Nanosecond}, map[string]*gorm.DB{"replica": replicaDB})
+	if err := primaryDB.Use(resolver); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = resolver.Close()
+		dictionaryCache.InvalidateAll()
+	})
+	for deadline := time.Now().Add(5 * time.Second); !resolver.Status()[0].Healthy; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("副本未通过健康检查")
+		}
+	}
+}
+
+// TestDictionaryService_ReadReplica 未传入请求 ctx 的普通查询也路由到副本
+func TestDictionaryService_ReadReplica(t *testing.T) {
+	openDictionaryReplica(t)
+	list, err := DictionaryServiceApp.GetSysDictionaryInfoList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dicts := list.([]system.SysDictionary); len(dicts) != 1 || dicts[0].Desc != "replica" {
+		t.Fatalf("plain service read should land on the replica: %+v", dicts)
+	}
+}
+
+// TestDictionaryService_GetSysDictionaryReplica 失效后重新加载缓存必须读主库 不能把延迟副本上的旧数据写回缓存
+func TestDictionaryService_GetSysDictionaryReplica(t *testing.T) {
+	enabled := true
+	openDictionaryReplica(t)
+
+	service := DictionaryServiceApp
+	if _, err := service.GetSysDictionary("gender", 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.UpdateSysDictionary(&system.SysDictionary{GVA_MODEL: global.GVA_MODEL{ID: 1}, Name: "性别", Type: "gender", Status: &enabled, Desc: "new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 副本尚未同步 请求内的查询读到旧数据
+	var stale system.SysDictionary
+	if err := global.GVA_DB.WithContext(replica.WithSession(context.Background())).First(&stale, 1).Error; err != nil || stale.Desc != "replica" {
+		t.Fatalf("session read should hit the stale replica: %q %v", stale.Desc, err)
+	}
+	dict, err := service.GetSysDictionary("gender", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dict.Desc != "new" {
+		t.Fatalf("reload after invalidate should read primary, got %q", dict.Desc)
+	}
+}
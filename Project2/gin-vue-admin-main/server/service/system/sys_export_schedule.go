@@ -0,0 +1,437 @@
+package system
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	emailUtils "github.com/flipped-aurora/gin-vue-admin/server/plugin/email/utils"
+	httpRequest "github.com/flipped-aurora/gin-vue-admin/server/utils/request"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// ExportScheduleCronName 定时报表在 GVA_Timer 中的cron名称
+	ExportScheduleCronName = "ExportSchedule"
+	// exportScheduleSyncCronName 各节点定期与数据库同步定时报表 使其他节点的增删改在本节点生效
+	exportScheduleSyncCronName = "ExportScheduleSync"
+	// exportScheduleRunningTTL 执行锁有效期 执行期间定期续期, 节点宕机时锁在此之后失效
+	exportScheduleRunningTTL = 10 * time.Minute
+	// exportScheduleDir 定时报表产出文件存放目录 不对外静态暴露 需通过接口鉴权下载
+	exportScheduleDir = "./exportReport/"
+)
+
+// exportScheduleParser 与 AddTaskByFuncWithSecond 使用的解析规则一致
+var exportScheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// exportScheduleVersions 本节点已注册的报表ID与其更新时间 用于与数据库同步
+var exportScheduleVersions sync.Map
+
+type SysExportScheduleService struct{}
+
+var SysExportScheduleServiceApp = new(SysExportScheduleService)
+
+// CreateSysExportSchedule 创建定时报表
+func (s *SysExportScheduleService) CreateSysExportSchedule(schedule *system.SysExportSchedule) (err error) {
+	if err = s.check(*schedule); err != nil {
+		return err
+	}
+	err = global.GVA_DB.Create(schedule).Error
+	if err != nil {
+		return err
+	}
+	return s.register(*schedule)
+}
+
+// DeleteSysExportSchedule 删除定时报表
+func (s *SysExportScheduleService) DeleteSysExportSchedule(id uint) (err error) {
+	err = global.GVA_DB.Delete(&system.SysExportSchedule{}, "id = ?", id).Error
+	if err != nil {
+		return err
+	}
+	s.unregister(id)
+	return nil
+}
+
+// DeleteSysExportScheduleByIds 批量删除定时报表
+func (s *SysExportScheduleService) DeleteSysExportScheduleByIds(ids request.IdsReq) (err error) {
+	err = global.GVA_DB.Delete(&[]system.SysExportSchedule{}, "id in ?", ids.Ids).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range ids.Ids {
+		s.unregister(uint(id))
+	}
+	return nil
+}
+
+// UpdateSysExportSchedule 更新定时报表 并重新注册定时任务
+func (s *SysExportScheduleService) UpdateSysExportSchedule(schedule system.SysExportSchedule) (err error) {
+	if err = s.check(schedule); err != nil {
+		return err
+	}
+	err = global.GVA_DB.Model(&system.SysExportSchedule{}).Where("id = ?", schedule.ID).
+		Select("name", "template_id", "spec", "params", "format", "channel", "recipients", "webhook_url", "alert_email", "alert_webhook", "enabled").
+		Updates(&schedule).Error
+	if err != nil {
+		return err
+	}
+	// 重新读取 使本节点记录的更新时间与数据库一致, 同步时不再重复注册
+	latest, err := s.GetSysExportSchedule(schedule.ID)
+	if err != nil {
+		return err
+	}
+	return s.register(latest)
+}
+
+// GetSysExportSchedule 根据id获取定时报表
+func (s *SysExportScheduleService) GetSysExportSchedule(id uint) (schedule system.SysExportSchedule, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&schedule).Error
+	return
+}
+
+// GetSysExportScheduleInfoList 分页获取定时报表
+func (s *SysExportScheduleService) GetSysExportScheduleInfoList(info systemReq.SysExportScheduleSearch) (list []system.SysExportSchedule, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysExportSchedule{})
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if info.TemplateID != "" {
+		db = db.Where("template_id = ?", info.TemplateID)
+	}
+	if info.Channel != "" {
+		db = db.Where("channel = ?", info.Channel)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	if limit != 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	err = db.Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// GetSysExportScheduleRunList 分页获取定时报表执行记录
+func (s *SysExportScheduleService) GetSysExportScheduleRunList(info systemReq.SysExportScheduleRunSearch) (list []system.SysExportScheduleRun, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysExportScheduleRun{})
+	if info.ScheduleID != 0 {
+		db = db.Where("schedule_id = ?", info.ScheduleID)
+	}
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	if limit != 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	err = db.Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// GetSysExportScheduleRunFile 获取执行记录的产出文件
+func (s *SysExportScheduleService) GetSysExportScheduleRunFile(id uint) (run system.SysExportScheduleRun, content []byte, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&run).Error
+	if err != nil {
+		return run, nil, err
+	}
+	if run.FileName == "" {
+		return run, nil, errors.New("该次执行没有产出文件")
+	}
+	content, err = os.ReadFile(filepath.Join(exportScheduleDir, run.FileName))
+	return run, content, err
+}
+
+// RunSysExportSchedule 立即执行一次定时报表
+func (s *SysExportScheduleService) RunSysExportSchedule(id uint) (run system.SysExportScheduleRun, err error) {
+	schedule, err := s.GetSysExportSchedule(id)
+	if err != nil {
+		return run, err
+	}
+	return s.execute(schedule, true)
+}
+
+// RegisterExportSchedules 启动时将所有启用的定时报表注册到 GVA_Timer 并开始定期同步
+func (s *SysExportScheduleService) RegisterExportSchedules() error {
+	var schedules []system.SysExportSchedule
+	err := global.GVA_DB.Find(&schedules).Error
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		if err = s.register(schedule); err != nil {
+			global.GVA_LOG.Error("注册定时报表失败!", zap.Uint("id", schedule.ID), zap.Error(err))
+		}
+	}
+	_, err = global.GVA_Timer.AddTaskByFuncWithSecond(exportScheduleSyncCronName, jobSyncSpec, s.SyncExportSchedules, "同步定时报表")
+	return err
+}
+
+// SyncExportSchedules 与数据库同步定时报表 注册新增和修改的报表 移除已删除的报表
+func (s *SysExportScheduleService) SyncExportSchedules() {
+	var schedules []system.SysExportSchedule
+	if err := global.GVA_DB.Find(&schedules).Error; err != nil {
+		global.GVA_LOG.Error("同步定时报表失败!", zap.Error(err))
+		return
+	}
+	present := make(map[uint]bool, len(schedules))
+	for _, schedule := range schedules {
+		present[schedule.ID] = true
+		if v, ok := exportScheduleVersions.Load(schedule.ID); ok && v.(int64) == schedule.UpdatedAt.UnixMilli() {
+			continue
+		}
+		if err := s.register(schedule); err != nil {
+			global.GVA_LOG.Error("注册定时报表失败!", zap.Uint("id", schedule.ID), zap.Error(err))
+		}
+	}
+	exportScheduleVersions.Range(func(k, _ any) bool {
+		if id := k.(uint); !present[id] {
+			s.unregister(id)
+		}
+		return true
+	})
+}
+
+func (s *SysExportScheduleService) check(schedule system.SysExportSchedule) error {
+	if _, err := exportScheduleParser.Parse(schedule.Spec); err != nil {
+		return fmt.Errorf("cron表达式错误: %w", err)
+	}
+	switch schedule.Format {
+	case "", system.ExportFormatExcel, system.ExportFormatCsv:
+	default:
+		return fmt.Errorf("不支持的导出格式: %s", schedule.Format)
+	}
+	switch schedule.Channel {
+	case "", system.ExportChannelEmail:
+		if strings.TrimSpace(schedule.Recipients) == "" {
+			return errors.New("邮件投递需要填写收件人")
+		}
+	case system.ExportChannelWebhook:
+		if _, err := url.ParseRequestURI(schedule.WebhookURL); err != nil {
+			return errors.New("webhook地址不合法")
+		}
+	default:
+		return fmt.Errorf("不支持的投递方式: %s", schedule.Channel)
+	}
+	var count int64
+	err := global.GVA_DB.Model(&system.SysExportTemplate{}).Where("template_id = ?", schedule.TemplateID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("导出模板不存在")
+	}
+	return nil
+}
+
+func (s *SysExportScheduleService) taskName(id uint) string {
+	return fmt.Sprintf("export_schedule_%d", id)
+}
+
+func (s *SysExportScheduleService) fireLockName(id uint, at time.Time) string {
+	return fmt.Sprintf("export_schedule:%d:%d", id, at.Unix())
+}
+
+func (s *SysExportScheduleService) runningLockName(id uint) string {
+	return fmt.Sprintf("export_schedule:%d:running", id)
+}
+
+// register 在本节点注册定时报表 已注册时先移除
+func (s *SysExportScheduleService) register(schedule system.SysExportSchedule) error {
+	global.GVA_Timer.RemoveTaskByName(ExportScheduleCronName, s.taskName(schedule.ID))
+	exportScheduleVersions.Store(schedule.ID, schedule.UpdatedAt.UnixMilli())
+	if !schedule.IsEnabled() {
+		return nil
+	}
+	id := schedule.ID
+	_, err := global.GVA_Timer.AddTaskByFuncWithSecond(ExportScheduleCronName, schedule.Spec, func() {
+		s.fire(id)
+	}, s.taskName(id))
+	return err
+}
+
+func (s *SysExportScheduleService) unregister(id uint) {
+	global.GVA_Timer.RemoveTaskByName(ExportScheduleCronName, s.taskName(id))
+	exportScheduleVersions.Delete(id)
+}
+
+// fire 调度触发 所有节点都会触发 只有取得本次调度锁的节点执行
+func (s *SysExportScheduleService) fire(id uint) {
+	now := time.Now()
+	// 每次执行时重新读取 保证使用最新的配置
+	schedule, err := s.GetSysExportSchedule(id)
+	if err != nil {
+		global.GVA_LOG.Error("读取定时报表失败!", zap.Uint("id", id), zap.Error(err))
+		return
+	}
+	if !schedule.IsEnabled() {
+		return
+	}
+	parsed, err := exportScheduleParser.Parse(schedule.Spec)
+	if err != nil {
+		return
+	}
+	// 以本次触发对应的计划时间加锁 与各节点的触发延迟无关
+	if !tryClusterLock(s.fireLockName(id, scheduledAt(parsed, now)), jobFireLockTTL) {
+		return
+	}
+	_, _ = s.execute(schedule, false)
+}
+
+// execute 导出 存档 投递 失败时告警
+// 执行锁在集群内互斥 避免同一报表在多个节点重叠执行
+func (s *SysExportScheduleService) execute(schedule system.SysExportSchedule, manual bool) (run system.SysExportScheduleRun, err error) {
+	name := s.runningLockName(schedule.ID)
+	if !tryClusterLock(name, exportScheduleRunningTTL) {
+		return run, errors.New("该报表正在执行中")
+	}
+	defer releaseClusterLock(name)
+	finished := make(chan struct{})
+	defer close(finished)
+	go s.renewRunningLock(name, finished)
+
+	run = system.SysExportScheduleRun{
+		ScheduleID: schedule.ID,
+		TemplateID: schedule.TemplateID,
+		Manual:     manual,
+		Status:     system.ExportRunRunning,
+		StartedAt:  time.Now(),
+	}
+	if err = global.GVA_DB.Create(&run).Error; err != nil {
+		return run, err
+	}
+
+	err = s.produce(schedule, &run)
+	if err == nil {
+		err = s.deliver(schedule, run)
+		run.Delivered = err == nil
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = system.ExportRunSuccess
+	if err != nil {
+		run.Status = system.ExportRunFailed
+		run.Error = err.Error()
+		global.GVA_LOG.Error("定时报表执行失败!", zap.Uint("id", schedule.ID), zap.Error(err))
+		s.alert(schedule, run)
+	}
+	if dbErr := global.GVA_DB.Save(&run).Error; dbErr != nil {
+		global.GVA_LOG.Error("保存定时报表执行记录失败!", zap.Error(dbErr))
+	}
+	// UpdateColumns 不更新 updated_at 避免触发各节点重新注册
+	global.GVA_DB.Model(&system.SysExportSchedule{}).Where("id = ?", schedule.ID).UpdateColumns(map[string]interface{}{
+		"last_run_at": run.StartedAt,
+		"last_status": run.Status,
+	})
+	return run, err
+}
+
+// renewRunningLock 执行结束前定期续期执行锁
+func (s *SysExportScheduleService) renewRunningLock(name string, finished <-chan struct{}) {
+	ticker := time.NewTicker(exportScheduleRunningTTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-finished:
+			return
+		case <-ticker.C:
+			renewClusterLock(name, exportScheduleRunningTTL)
+		}
+	}
+}
+
+// produce 按模板导出并将产出文件存档
+func (s *SysExportScheduleService) produce(schedule system.SysExportSchedule, run *system.SysExportScheduleRun) error {
+	values := url.Values{}
+	for k, v := range schedule.Params {
+		values.Set(k, v)
+	}
+	file, name, ext, err := SysExportTemplateServiceApp.ExportFile(schedule.TemplateID, values, schedule.Format)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(exportScheduleDir, os.ModePerm); err != nil {
+		return err
+	}
+	run.FileName = fmt.Sprintf("%s_%d_%s%s", name, run.ID, run.StartedAt.Format("20060102150405"), ext)
+	run.FileSize = int64(file.Len())
+	run.FilePath = exportScheduleDir + run.FileName
+	return os.WriteFile(filepath.Join(exportScheduleDir, run.FileName), file.Bytes(), 0o644)
+}
+
+func (s *SysExportScheduleService) deliver(schedule system.SysExportSchedule, run system.SysExportScheduleRun) error {
+	content, err := os.ReadFile(filepath.Join(exportScheduleDir, run.FileName))
+	if err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("[定时报表] %s %s", schedule.Name, run.StartedAt.Format("2006-01-02 15:04"))
+	switch schedule.Channel {
+	case system.ExportChannelWebhook:
+		return postWebhook(schedule.WebhookURL, map[string]interface{}{
+			"event":      "export_schedule_success",
+			"scheduleID": schedule.ID,
+			"name":       schedule.Name,
+			"runID":      run.ID,
+			"fileName":   run.FileName,
+			"fileSize":   run.FileSize,
+			"content":    base64.StdEncoding.EncodeToString(content),
+		})
+	default:
+		body := fmt.Sprintf("报表【%s】已生成, 详见附件 %s", schedule.Name, run.FileName)
+		return emailUtils.EmailWithAttach(schedule.Recipients, subject, body, run.FileName, content)
+	}
+}
+
+func (s *SysExportScheduleService) alert(schedule system.SysExportSchedule, run system.SysExportScheduleRun) {
+	subject := fmt.Sprintf("[定时报表失败] %s", schedule.Name)
+	if schedule.AlertEmail != "" {
+		body := fmt.Sprintf("报表【%s】第%d次执行失败: %s", schedule.Name, run.ID, run.Error)
+		if err := emailUtils.Email(schedule.AlertEmail, subject, body); err != nil {
+			global.GVA_LOG.Error("定时报表告警邮件发送失败!", zap.Error(err))
+		}
+	}
+	if schedule.AlertWebhook != "" {
+		err := postWebhook(schedule.AlertWebhook, map[string]interface{}{
+			"event":      "export_schedule_failed",
+			"scheduleID": schedule.ID,
+			"name":       schedule.Name,
+			"runID":      run.ID,
+			"error":      run.Error,
+		})
+		if err != nil {
+			global.GVA_LOG.Error("定时报表告警webhook发送失败!", zap.Error(err))
+		}
+	}
+}
+
+func postWebhook(webhookURL string, payload map[string]interface{}) error {
+	resp, err := httpRequest.HttpRequest(webhookURL, "POST", nil, nil, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+)
+
+func openExportScheduleTestDB(t *testing.T) {
+	testdb.Open(t, "", &system.SysExportSchedule{}, &system.SysExportScheduleRun{}, &system.SysExportTemplate{}, &system.SysJobLock{})
+	t.Cleanup(func() {
+		global.GVA_Timer.Clear(ExportScheduleCronName)
+		global.GVA_Timer.Clear(exportScheduleSyncCronName)
+		exportScheduleVersions.Range(func(k, _ any) bool {
+			exportScheduleVersions.Delete(k)
+			return true
+		})
+	})
+}
+
+func TestSysExportScheduleService_SyncExportSchedules(t *testing.T) {
+	openExportScheduleTestDB(t)
+	service := SysExportScheduleServiceApp
+	kept := system.SysExportSchedule{Name: "kept", TemplateID: "demo", Spec: "0 0 1 * * *"}
+	changed := system.SysExportSchedule{Name: "changed", TemplateID: "demo", Spec: "0 0 2 * * *"}
+	deleted := system.SysExportSchedule{Name: "deleted", TemplateID: "demo", Spec: "0 0 3 * * *"}
+	for _, schedule := range []*system.SysExportSchedule{&kept, &changed, &deleted} {
+		if err := global.GVA_DB.Create(schedule).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.RegisterExportSchedules(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := global.GVA_Timer.FindTask(exportScheduleSyncCronName, "同步定时报表"); !ok {
+		t.Fatal("RegisterExportSchedules() should start the sync task")
+	}
+
+	// 模拟其他节点的修改 只写数据库 不经过本节点的注册
+	disabled := false
+	added := system.SysExportSchedule{Name: "added", TemplateID: "demo", Spec: "0 0 4 * * *"}
+	paused := system.SysExportSchedule{Name: "paused", TemplateID: "demo", Spec: "0 0 5 * * *", Enabled: &disabled}
+	for _, schedule := range []*system.SysExportSchedule{&added, &paused} {
+		if err := global.GVA_DB.Create(schedule).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := global.GVA_DB.Model(&system.SysExportSchedule{}).Where("id = ?", changed.ID).
+		Updates(map[string]interface{}{"spec": "0 30 2 * * *", "updated_at": time.Now().Add(time.Second)}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = global.GVA_DB.Delete(&system.SysExportSchedule{}, deleted.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	service.SyncExportSchedules()
+	if task, ok := global.GVA_Timer.FindTask(ExportScheduleCronName, service.taskName(changed.ID)); !ok || task.Spec != "0 30 2 * * *" {
+		t.Fatalf("changed schedule should be registered with the new spec: %+v", task)
+	}
+	for _, id := range []uint{kept.ID, added.ID} {
+		if _, ok := global.GVA_Timer.FindTask(ExportScheduleCronName, service.taskName(id)); !ok {
+			t.Errorf("schedule %d should be registered", id)
+		}
+	}
+	for _, id := range []uint{deleted.ID, paused.ID} {
+		if _, ok := global.GVA_Timer.FindTask(ExportScheduleCronName, service.taskName(id)); ok {
+			t.Errorf("schedule %d should not be registered", id)
+		}
+	}
+	if _, ok := exportScheduleVersions.Load(deleted.ID); ok {
+		t.Error("deleted schedule should be forgotten")
+	}
+}
+
+func TestSysExportScheduleService_fire(t *testing.T) {
+	openExportScheduleTestDB(t)
+	service := SysExportScheduleServiceApp
+	schedule := system.SysExportSchedule{Name: "daily", TemplateID: "missing", Spec: "@every 24h"}
+	if err := global.GVA_DB.Create(&schedule).Error; err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := exportScheduleParser.Parse(schedule.Spec)
+
+	// 其他节点已取得本次计划时间的调度锁
+	if !tryClusterLock(service.fireLockName(schedule.ID, scheduledAt(parsed, time.Now())), jobFireLockTTL) {
+		t.Fatal("fire lock should be acquired")
+	}
+	service.fire(schedule.ID)
+	var count int64
+	global.GVA_DB.Model(&system.SysExportScheduleRun{}).Where("schedule_id = ?", schedule.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("fire() should skip a schedule fired by another node, got %d runs", count)
+	}
+
+	// 其他节点正在执行 执行锁在集群内互斥
+	if !tryClusterLock(service.runningLockName(schedule.ID), time.Minute) {
+		t.Fatal("running lock should be acquired")
+	}
+	if _, err := service.RunSysExportSchedule(schedule.ID); err == nil {
+		t.Fatal("schedule running on another node should not run again")
+	}
+	releaseClusterLock(service.runningLockName(schedule.ID))
+
+	run, err := service.RunSysExportSchedule(schedule.ID)
+	if err == nil || run.Status != system.ExportRunFailed {
+		t.Fatalf("missing template should fail the run: %+v %v", run, err)
+	}
+	if !tryClusterLock(service.runningLockName(schedule.ID), time.Minute) {
+		t.Fatal("running lock should be released after the run")
+	}
+	var latest system.SysExportSchedule
+	global.GVA_DB.First(&latest, schedule.ID)
+	if !latest.UpdatedAt.Equal(schedule.UpdatedAt) || latest.LastStatus != system.ExportRunFailed {
+		t.Fatalf("run result should be saved without touching updated_at: %+v", latest)
+	}
+}
@@ -2,6 +2,7 @@ package system
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
@@ -126,8 +127,7 @@ func (sysExportTemplateService *SysExportTemplateService) GetSysExportTemplateIn
 // ExportExcel 导出Excel
 // Author [piexlmax](https://github.com/piexlmax)
 func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID string, values url.Values) (file *bytes.Buffer, name string, err error) {
-	var template system.SysExportTemplate
-	err = global.GVA_DB.Preload("Conditions").Preload("JoinTemplate").First(&template, "template_id = ?", templateID).Error
+	rows, name, err := sysExportTemplateService.exportRows(templateID, values)
 	if err != nil {
 		return nil, "", err
 	}
@@ -143,6 +143,61 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 		fmt.Println(err)
 		return
 	}
+	for i, row := range rows {
+		for j, colCell := range row {
+			sErr := f.SetCellValue("Sheet1", fmt.Sprintf("%s%d", getColumnName(j+1), i+1), colCell)
+			if sErr != nil {
+				return nil, "", sErr
+			}
+		}
+	}
+	f.SetActiveSheet(index)
+	file, err = f.WriteToBuffer()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, name, nil
+}
+
+// ExportCsv 导出CSV
+func (sysExportTemplateService *SysExportTemplateService) ExportCsv(templateID string, values url.Values) (file *bytes.Buffer, name string, err error) {
+	rows, name, err := sysExportTemplateService.exportRows(templateID, values)
+	if err != nil {
+		return nil, "", err
+	}
+	file = new(bytes.Buffer)
+	// 写入BOM 避免excel打开中文乱码
+	file.WriteString("\xEF\xBB\xBF")
+	w := csv.NewWriter(file)
+	err = w.WriteAll(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, name, nil
+}
+
+// ExportFile 按照指定格式导出 format为空时默认xlsx
+func (sysExportTemplateService *SysExportTemplateService) ExportFile(templateID string, values url.Values, format string) (file *bytes.Buffer, name string, ext string, err error) {
+	switch format {
+	case system.ExportFormatCsv:
+		file, name, err = sysExportTemplateService.ExportCsv(templateID, values)
+		return file, name, ".csv", err
+	case "", system.ExportFormatExcel:
+		file, name, err = sysExportTemplateService.ExportExcel(templateID, values)
+		return file, name, ".xlsx", err
+	default:
+		return nil, "", "", fmt.Errorf("不支持的导出格式: %s", format)
+	}
+}
+
+// exportRows 根据模板查询导出数据 第一行为表头
+func (sysExportTemplateService *SysExportTemplateService) exportRows(templateID string, values url.Values) (rows [][]string, name string, err error) {
+	var template system.SysExportTemplate
+	err = global.GVA_DB.Preload("Conditions").Preload("JoinTemplate").First(&template, "template_id = ?", templateID).Error
+	if err != nil {
+		return nil, "", err
+	}
 	var templateInfoMap = make(map[string]string)
 	columns, err := utils.GetJSONKeys(template.TemplateInfo)
 	if err != nil {
@@ -251,7 +306,6 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 	if err != nil {
 		return nil, "", err
 	}
-	var rows [][]string
 	rows = append(rows, tableTitle)
 	for _, exTable := range tableMap {
 		var row []string
@@ -276,21 +330,7 @@ func (sysExportTemplateService *SysExportTemplateService) ExportExcel(templateID
 		}
 		rows = append(rows, row)
 	}
-	for i, row := range rows {
-		for j, colCell := range row {
-			sErr := f.SetCellValue("Sheet1", fmt.Sprintf("%s%d", getColumnName(j+1), i+1), colCell)
-			if sErr != nil {
-				return nil, "", sErr
-			}
-		}
-	}
-	f.SetActiveSheet(index)
-	file, err = f.WriteToBuffer()
-	if err != nil {
-		return nil, "", err
-	}
-
-	return file, template.Name, nil
+	return rows, template.Name, nil
 }
 
 // ExportTemplate 导出Excel模板
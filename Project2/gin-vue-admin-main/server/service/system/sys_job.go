@@ -0,0 +1,459 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/timer"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// JobCronName 持久化定时任务在 GVA_Timer 中的cron名称
+	JobCronName = "SysJob"
+	// jobSyncCronName 各节点定期与数据库同步任务定义 使运行时的增删改在集群内生效
+	jobSyncCronName = "SysJobSync"
+	jobSyncSpec     = "@every 30s"
+	// jobFireLockTTL 单次调度的去重锁有效期 需大于各节点间的时钟误差
+	jobFireLockTTL = 10 * time.Minute
+	// jobFireWindow 向前查找本次触发对应计划时间的范围 需大于触发延迟
+	jobFireWindow = time.Minute
+	// jobOutputLimit 执行记录保存的日志输出上限
+	jobOutputLimit = 64 * 1024
+)
+
+// jobSpecParser 与 AddTaskByFuncWithSecond 使用的解析规则一致
+var jobSpecParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// jobVersions 本节点已注册的任务ID与其更新时间 用于与数据库同步
+var jobVersions sync.Map
+
+type SysJobService struct{}
+
+var SysJobServiceApp = new(SysJobService)
+
+// CreateSysJob 创建定时任务
+func (s *SysJobService) CreateSysJob(job *system.SysJob) (err error) {
+	if err = s.check(*job); err != nil {
+		return err
+	}
+	if err = global.GVA_DB.Create(job).Error; err != nil {
+		return err
+	}
+	return s.register(*job)
+}
+
+// DeleteSysJob 删除定时任务 保留执行记录
+func (s *SysJobService) DeleteSysJob(id uint) (err error) {
+	err = global.GVA_DB.Delete(&system.SysJob{}, "id = ?", id).Error
+	if err != nil {
+		return err
+	}
+	s.unregister(id)
+	return nil
+}
+
+// DeleteSysJobByIds 批量删除定时任务
+func (s *SysJobService) DeleteSysJobByIds(ids request.IdsReq) (err error) {
+	err = global.GVA_DB.Delete(&[]system.SysJob{}, "id in ?", ids.Ids).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range ids.Ids {
+		s.unregister(uint(id))
+	}
+	return nil
+}
+
+// UpdateSysJob 更新定时任务 并重新注册 其他节点在下次同步时生效
+func (s *SysJobService) UpdateSysJob(job system.SysJob) (err error) {
+	if err = s.check(job); err != nil {
+		return err
+	}
+	err = global.GVA_DB.Model(&system.SysJob{}).Where("id = ?", job.ID).
+		Select("name", "spec", "handler", "args", "enabled", "timeout", "misfire", "concurrent", "remark", "updated_at").
+		Updates(&job).Error
+	if err != nil {
+		return err
+	}
+	latest, err := s.GetSysJob(job.ID)
+	if err != nil {
+		return err
+	}
+	return s.register(latest)
+}
+
+// GetSysJob 根据id获取定时任务
+func (s *SysJobService) GetSysJob(id uint) (job system.SysJob, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&job).Error
+	return
+}
+
+// GetSysJobInfoList 分页获取定时任务
+func (s *SysJobService) GetSysJobInfoList(info systemReq.SysJobSearch) (list []system.SysJob, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysJob{})
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if info.Handler != "" {
+		db = db.Where("handler = ?", info.Handler)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	if limit != 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	err = db.Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// GetSysJobRunList 分页获取定时任务执行记录
+func (s *SysJobService) GetSysJobRunList(info systemReq.SysJobRunSearch) (list []system.SysJobRun, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysJobRun{})
+	if info.JobID != 0 {
+		db = db.Where("job_id = ?", info.JobID)
+	}
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if info.Trigger != "" {
+		db = db.Where("trigger_type = ?", info.Trigger)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	if limit != 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	err = db.Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// GetJobHandlers 获取本节点已注册的处理函数
+func (s *SysJobService) GetJobHandlers() []timer.JobHandlerInfo {
+	return timer.JobHandlers()
+}
+
+// RunSysJob 立即执行一次定时任务 异步执行 返回执行记录
+func (s *SysJobService) RunSysJob(id uint) (run system.SysJobRun, err error) {
+	job, err := s.GetSysJob(id)
+	if err != nil {
+		return run, err
+	}
+	run, err = s.begin(job, system.JobTriggerManual)
+	if err != nil {
+		return run, err
+	}
+	go s.execute(job, run)
+	return run, nil
+}
+
+// EnsureSysJob 按处理函数名称初始化内置任务 已存在(含已删除)时不再创建 保留用户的修改
+func (s *SysJobService) EnsureSysJob(job system.SysJob) error {
+	var count int64
+	err := global.GVA_DB.Unscoped().Model(&system.SysJob{}).Where("handler = ?", job.Handler).Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	return global.GVA_DB.Create(&job).Error
+}
+
+// StartSysJobs 启动时补执行错过的任务 注册所有任务并开始定期同步
+func (s *SysJobService) StartSysJobs() error {
+	var jobs []system.SysJob
+	err := global.GVA_DB.Find(&jobs).Error
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, job := range jobs {
+		if job.IsEnabled() && job.Misfire == system.JobMisfireOnce && job.NextRunAt != nil && job.NextRunAt.Before(now.Add(-time.Second)) {
+			// 与正常调度使用同一把锁 多个节点同时启动时只补执行一次
+			if tryClusterLock(s.fireLockName(job.ID, *job.NextRunAt), jobFireLockTTL) {
+				global.GVA_LOG.Info("补执行错过的定时任务", zap.Uint("id", job.ID), zap.Time("scheduledAt", *job.NextRunAt))
+				if run, bErr := s.begin(job, system.JobTriggerMisfire); bErr == nil {
+					go s.execute(job, run)
+				}
+			}
+		}
+		if err = s.register(job); err != nil {
+			global.GVA_LOG.Error("注册定时任务失败!", zap.Uint("id", job.ID), zap.Error(err))
+		}
+	}
+	_, err = global.GVA_Timer.AddTaskByFuncWithSecond(jobSyncCronName, jobSyncSpec, s.SyncSysJobs, "同步定时任务")
+	return err
+}
+
+// SyncSysJobs 与数据库同步任务定义 注册新增和修改的任务 移除已删除的任务
+func (s *SysJobService) SyncSysJobs() {
+	var jobs []system.SysJob
+	if err := global.GVA_DB.Find(&jobs).Error; err != nil {
+		global.GVA_LOG.Error("同步定时任务失败!", zap.Error(err))
+		return
+	}
+	present := make(map[uint]bool, len(jobs))
+	for _, job := range jobs {
+		present[job.ID] = true
+		if v, ok := jobVersions.Load(job.ID); ok && v.(int64) == job.UpdatedAt.UnixMilli() {
+			continue
+		}
+		if err := s.register(job); err != nil {
+			global.GVA_LOG.Error("注册定时任务失败!", zap.Uint("id", job.ID), zap.Error(err))
+		}
+	}
+	jobVersions.Range(func(k, _ any) bool {
+		if id := k.(uint); !present[id] {
+			s.unregister(id)
+		}
+		return true
+	})
+	cleanClusterLocks()
+}
+
+func (s *SysJobService) check(job system.SysJob) error {
+	if _, err := jobSpecParser.Parse(job.Spec); err != nil {
+		return fmt.Errorf("cron表达式错误: %w", err)
+	}
+	if _, ok := timer.GetJobHandler(job.Handler); !ok {
+		return fmt.Errorf("处理函数 %s 未注册", job.Handler)
+	}
+	switch job.Misfire {
+	case "", system.JobMisfireSkip, system.JobMisfireOnce:
+	default:
+		return fmt.Errorf("不支持的错过执行策略: %s", job.Misfire)
+	}
+	if job.Timeout < 0 {
+		return errors.New("超时时间不能为负数")
+	}
+	return nil
+}
+
+func (s *SysJobService) taskName(id uint) string {
+	return fmt.Sprintf("sys_job_%d", id)
+}
+
+func (s *SysJobService) fireLockName(id uint, at time.Time) string {
+	return fmt.Sprintf("job:%d:%d", id, at.Unix())
+}
+
+func (s *SysJobService) runningLockName(id uint) string {
+	return fmt.Sprintf("job:%d:running", id)
+}
+
+// register 在本节点注册任务 已注册时先移除
+func (s *SysJobService) register(job system.SysJob) error {
+	global.GVA_Timer.RemoveTaskByName(JobCronName, s.taskName(job.ID))
+	jobVersions.Store(job.ID, job.UpdatedAt.UnixMilli())
+	if !job.IsEnabled() {
+		global.GVA_DB.Model(&system.SysJob{}).Where("id = ?", job.ID).UpdateColumn("next_run_at", nil)
+		return nil
+	}
+	schedule, err := jobSpecParser.Parse(job.Spec)
+	if err != nil {
+		return err
+	}
+	id := job.ID
+	_, err = global.GVA_Timer.AddTaskByFuncWithSecond(JobCronName, job.Spec, func() {
+		s.fire(id)
+	}, s.taskName(id))
+	if err != nil {
+		return err
+	}
+	global.GVA_DB.Model(&system.SysJob{}).Where("id = ?", id).UpdateColumn("next_run_at", schedule.Next(time.Now()))
+	return nil
+}
+
+func (s *SysJobService) unregister(id uint) {
+	global.GVA_Timer.RemoveTaskByName(JobCronName, s.taskName(id))
+	jobVersions.Delete(id)
+}
+
+// fire 调度触发 所有节点都会触发 只有取得本次调度锁的节点执行
+func (s *SysJobService) fire(id uint) {
+	now := time.Now()
+	job, err := s.GetSysJob(id)
+	if err != nil || !job.IsEnabled() {
+		return
+	}
+	schedule, err := jobSpecParser.Parse(job.Spec)
+	if err != nil {
+		return
+	}
+	// 以本次触发对应的计划时间加锁 与触发延迟无关, 与启动时补执行使用的 next_run_at 一致
+	fireAt := scheduledAt(schedule, now)
+	if !tryClusterLock(s.fireLockName(id, fireAt), jobFireLockTTL) {
+		return
+	}
+	global.GVA_DB.Model(&system.SysJob{}).Where("id = ?", id).UpdateColumn("next_run_at", schedule.Next(fireAt))
+	run, err := s.begin(job, system.JobTriggerSchedule)
+	if err != nil {
+		global.GVA_LOG.Warn("跳过定时任务", zap.Uint("id", id), zap.Error(err))
+		return
+	}
+	s.execute(job, run)
+}
+
+// scheduledAt 不晚于 now 的最近一次计划时间
+// @every 的计划时间相对于注册时间 各节点不一致, 按间隔对齐到整点
+func scheduledAt(schedule cron.Schedule, now time.Time) time.Time {
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return now.Truncate(every.Delay)
+	}
+	at := now.Truncate(time.Second)
+	for next := schedule.Next(now.Add(-jobFireWindow)); !next.After(now); next = schedule.Next(next) {
+		at = next
+	}
+	return at
+}
+
+// begin 创建执行记录 不允许并发的任务需先取得执行锁
+func (s *SysJobService) begin(job system.SysJob, trigger string) (run system.SysJobRun, err error) {
+	if !job.Concurrent && !tryClusterLock(s.runningLockName(job.ID), s.runningLockTTL(job)) {
+		return run, errors.New("任务正在执行中")
+	}
+	run = system.SysJobRun{
+		JobID:     job.ID,
+		Handler:   job.Handler,
+		Trigger:   trigger,
+		Node:      jobNode,
+		Status:    system.JobRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err = global.GVA_DB.Create(&run).Error; err != nil && !job.Concurrent {
+		releaseClusterLock(s.runningLockName(job.ID))
+	}
+	return run, err
+}
+
+// runningLockTTL 执行锁有效期 节点宕机时锁在此之后失效
+func (s *SysJobService) runningLockTTL(job system.SysJob) time.Duration {
+	if job.Timeout > 0 {
+		return time.Duration(job.Timeout)*time.Second + time.Minute
+	}
+	return 24 * time.Hour
+}
+
+// execute 执行处理函数 记录耗时、结果与日志输出
+// 超时后立即记录结果, 但执行锁保持到处理函数真正返回 避免其他节点在处理函数仍在运行时再次执行
+func (s *SysJobService) execute(job system.SysJob, run system.SysJobRun) {
+	finished := make(chan struct{})
+	if !job.Concurrent {
+		go s.holdRunningLock(job, finished)
+	}
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if job.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(job.Timeout)*time.Second)
+	}
+	defer cancel()
+
+	out := &jobOutput{limit: jobOutputLimit}
+	done := make(chan error, 1)
+	if handler, ok := timer.GetJobHandler(job.Handler); !ok {
+		done <- fmt.Errorf("处理函数 %s 未在节点 %s 注册", job.Handler, jobNode)
+		close(finished)
+	} else {
+		go func() {
+			defer close(finished)
+			defer func() {
+				if r := recover(); r != nil {
+					done <- fmt.Errorf("panic: %v", r)
+				}
+			}()
+			done <- handler(ctx, job.Args, out)
+		}()
+	}
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// 处理函数未响应取消时不再等待 其后续输出不会被记录, 执行锁在其返回后释放
+		err = ctx.Err()
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Duration = finishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Output = out.String()
+	run.Status = system.JobRunSuccess
+	if err != nil {
+		run.Status = system.JobRunFailed
+		if errors.Is(err, context.DeadlineExceeded) {
+			run.Status = system.JobRunTimeout
+		}
+		run.Error = err.Error()
+		global.GVA_LOG.Error("定时任务执行失败!", zap.Uint("id", job.ID), zap.String("handler", job.Handler), zap.Error(err))
+	}
+	if dbErr := global.GVA_DB.Save(&run).Error; dbErr != nil {
+		global.GVA_LOG.Error("保存定时任务执行记录失败!", zap.Error(dbErr))
+	}
+	// UpdateColumns 不更新 updated_at 避免触发各节点重新注册
+	global.GVA_DB.Model(&system.SysJob{}).Where("id = ?", job.ID).UpdateColumns(map[string]interface{}{
+		"last_run_at": run.StartedAt,
+		"last_status": run.Status,
+	})
+}
+
+// holdRunningLock 处理函数返回前定期续期执行锁 返回后释放
+func (s *SysJobService) holdRunningLock(job system.SysJob, finished <-chan struct{}) {
+	name := s.runningLockName(job.ID)
+	ttl := s.runningLockTTL(job)
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-finished:
+			releaseClusterLock(name)
+			return
+		case <-ticker.C:
+			renewClusterLock(name, ttl)
+		}
+	}
+}
+
+// jobOutput 并发安全的日志输出缓冲 超出上限的内容丢弃
+type jobOutput struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (o *jobOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if remain := o.limit - o.buf.Len(); remain < len(p) {
+		o.truncated = true
+		if remain > 0 {
+			o.buf.Write(p[:remain])
+		}
+		return len(p), nil
+	}
+	o.buf.Write(p)
+	return len(p), nil
+}
+
+func (o *jobOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.truncated {
+		return o.buf.String() + "\n...(输出过长已截断)"
+	}
+	return o.buf.String()
+}
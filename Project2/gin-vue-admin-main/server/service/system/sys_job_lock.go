@@ -0,0 +1,82 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// jobLockPrefix redis 中集群锁的key前缀
+const jobLockPrefix = "gva:job-lock:"
+
+// jobNode 当前节点标识 用于集群锁的持有者与执行记录
+var jobNode = func() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}()
+
+// jobUnlockScript 只释放自己持有的锁
+const jobUnlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+// jobRenewScript 只续期自己持有的锁
+const jobRenewScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+
+// tryClusterLock 获取集群锁 启用redis时使用redis 否则使用数据库
+// 锁在 ttl 后自动失效 节点宕机不会导致任务永久无法执行
+func tryClusterLock(name string, ttl time.Duration) bool {
+	if global.GVA_CONFIG.System.UseRedis && global.GVA_REDIS != nil {
+		ok, err := global.GVA_REDIS.SetNX(context.Background(), jobLockPrefix+name, jobNode, ttl).Result()
+		if err != nil {
+			global.GVA_LOG.Error("获取集群锁失败!", zap.String("name", name), zap.Error(err))
+		}
+		return ok
+	}
+	now := time.Now()
+	global.GVA_DB.Where("name = ? AND expires_at < ?", name, now).Delete(&system.SysJobLock{})
+	// 主键冲突即锁已被其他节点持有 使用 DoNothing 避免冲突时报错, 以影响行数判断是否取得
+	result := global.GVA_DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&system.SysJobLock{Name: name, Owner: jobNode, ExpiresAt: now.Add(ttl)})
+	if result.Error != nil {
+		global.GVA_LOG.Error("获取集群锁失败!", zap.String("name", name), zap.Error(result.Error))
+		return false
+	}
+	return result.RowsAffected == 1
+}
+
+// releaseClusterLock 释放当前节点持有的集群锁
+func releaseClusterLock(name string) {
+	if global.GVA_CONFIG.System.UseRedis && global.GVA_REDIS != nil {
+		err := global.GVA_REDIS.Eval(context.Background(), jobUnlockScript, []string{jobLockPrefix + name}, jobNode).Err()
+		if err != nil {
+			global.GVA_LOG.Error("释放集群锁失败!", zap.String("name", name), zap.Error(err))
+		}
+		return
+	}
+	global.GVA_DB.Where("name = ? AND owner = ?", name, jobNode).Delete(&system.SysJobLock{})
+}
+
+// renewClusterLock 延长当前节点持有的集群锁的有效期
+func renewClusterLock(name string, ttl time.Duration) {
+	if global.GVA_CONFIG.System.UseRedis && global.GVA_REDIS != nil {
+		err := global.GVA_REDIS.Eval(context.Background(), jobRenewScript, []string{jobLockPrefix + name}, jobNode, ttl.Milliseconds()).Err()
+		if err != nil {
+			global.GVA_LOG.Error("续期集群锁失败!", zap.String("name", name), zap.Error(err))
+		}
+		return
+	}
+	global.GVA_DB.Model(&system.SysJobLock{}).Where("name = ? AND owner = ?", name, jobNode).UpdateColumn("expires_at", time.Now().Add(ttl))
+}
+
+// cleanClusterLocks 清理数据库中已过期的集群锁
+func cleanClusterLocks() {
+	if global.GVA_CONFIG.System.UseRedis && global.GVA_REDIS != nil {
+		return
+	}
+	global.GVA_DB.Where("expires_at < ?", time.Now()).Delete(&system.SysJobLock{})
+}
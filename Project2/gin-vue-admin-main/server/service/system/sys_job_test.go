@@ -0,0 +1,162 @@
+package system
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/timer"
+)
+
+func openJobTestDB(t *testing.T) {
+	testdb.Open(t, "", &system.SysJob{}, &system.SysJobRun{}, &system.SysJobLock{})
+	t.Cleanup(func() {
+		global.GVA_Timer.Clear(JobCronName)
+		global.GVA_Timer.Clear(jobSyncCronName)
+	})
+}
+
+// waitJobRun 等待任务的执行记录达到 status
+func waitJobRun(t *testing.T, jobID uint, status string) system.SysJobRun {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var run system.SysJobRun
+		if err := global.GVA_DB.Where("job_id = ? AND status = ?", jobID, status).Last(&run).Error; err == nil {
+			return run
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %d has no %s run", jobID, status)
+	return system.SysJobRun{}
+}
+
+func Test_scheduledAt(t *testing.T) {
+	cronSchedule, _ := jobSpecParser.Parse("0 */5 * * * *")
+	everySchedule, _ := jobSpecParser.Parse("@every 10s")
+	base := time.Date(2026, 10, 19, 12, 5, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "on time", now: base.Add(800 * time.Millisecond), want: base},
+		{name: "delayed", now: base.Add(1600 * time.Millisecond), want: base},
+	}
+	for _, tt := range tests {
+		if got := scheduledAt(cronSchedule, tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: scheduledAt() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := scheduledAt(everySchedule, base.Add(13*time.Second)); !got.Equal(base.Add(10 * time.Second)) {
+		t.Errorf("@every: scheduledAt() = %v", got)
+	}
+}
+
+func Test_tryClusterLock(t *testing.T) {
+	openJobTestDB(t)
+	if !tryClusterLock("test", time.Minute) {
+		t.Fatal("first lock should succeed")
+	}
+	if tryClusterLock("test", time.Minute) {
+		t.Fatal("held lock should not be acquired again")
+	}
+	releaseClusterLock("test")
+	if !tryClusterLock("test", time.Minute) {
+		t.Fatal("released lock should be acquired")
+	}
+
+	if !tryClusterLock("expired", -time.Second) {
+		t.Fatal("first lock should succeed")
+	}
+	if !tryClusterLock("expired", time.Minute) {
+		t.Fatal("expired lock should be acquired")
+	}
+
+	if !tryClusterLock("renew", time.Millisecond) {
+		t.Fatal("first lock should succeed")
+	}
+	renewClusterLock("renew", time.Minute)
+	time.Sleep(5 * time.Millisecond)
+	if tryClusterLock("renew", time.Minute) {
+		t.Fatal("renewed lock should still be held")
+	}
+}
+
+func TestSysJobService_executeTimeout(t *testing.T) {
+	openJobTestDB(t)
+	release := make(chan struct{})
+	timer.RegisterJobHandler("test.ignoreCancel", "忽略取消的任务", func(ctx context.Context, args string, out io.Writer) error {
+		<-release
+		return nil
+	})
+	job := system.SysJob{Name: "timeout", Spec: "0 0 0 1 1 *", Handler: "test.ignoreCancel", Timeout: 1}
+	if err := global.GVA_DB.Create(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+	run, err := SysJobServiceApp.begin(job, system.JobTriggerManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SysJobServiceApp.execute(job, run) // 超时后返回 处理函数仍在运行
+	waitJobRun(t, job.ID, system.JobRunTimeout)
+
+	if _, err = SysJobServiceApp.begin(job, system.JobTriggerManual); err == nil {
+		t.Fatal("running lock should be held until the handler returns")
+	}
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if run, err = SysJobServiceApp.begin(job, system.JobTriggerManual); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("running lock should be released after the handler returns")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	releaseClusterLock(SysJobServiceApp.runningLockName(job.ID))
+}
+
+func TestSysJobService_StartSysJobsMisfire(t *testing.T) {
+	openJobTestDB(t)
+	timer.RegisterJobHandler("test.noop", "空任务", func(ctx context.Context, args string, out io.Writer) error {
+		_, err := io.WriteString(out, args)
+		return err
+	})
+	missed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	once := system.SysJob{Name: "once", Spec: "0 0 0 1 1 *", Handler: "test.noop", Args: "once", Misfire: system.JobMisfireOnce, NextRunAt: &missed}
+	skip := system.SysJob{Name: "skip", Spec: "0 0 0 1 1 *", Handler: "test.noop", Misfire: system.JobMisfireSkip, NextRunAt: &missed}
+	taken := system.SysJob{Name: "taken", Spec: "0 0 0 1 1 *", Handler: "test.noop", Misfire: system.JobMisfireOnce, NextRunAt: &missed}
+	for _, job := range []*system.SysJob{&once, &skip, &taken} {
+		if err := global.GVA_DB.Create(job).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 其他节点已按计划时间执行过 taken 补执行与正常调度使用同一把锁
+	if !tryClusterLock(SysJobServiceApp.fireLockName(taken.ID, missed), jobFireLockTTL) {
+		t.Fatal("fire lock should be acquired")
+	}
+
+	if err := SysJobServiceApp.StartSysJobs(); err != nil {
+		t.Fatal(err)
+	}
+	run := waitJobRun(t, once.ID, system.JobRunSuccess)
+	if run.Trigger != system.JobTriggerMisfire || run.Output != "once" {
+		t.Fatalf("unexpected misfire run: %+v", run)
+	}
+	var count int64
+	global.GVA_DB.Model(&system.SysJobRun{}).Where("job_id IN ?", []uint{skip.ID, taken.ID}).Count(&count)
+	if count != 0 {
+		t.Fatalf("skip and already fired jobs should not run, got %d runs", count)
+	}
+	var latest system.SysJob
+	global.GVA_DB.First(&latest, once.ID)
+	if latest.NextRunAt == nil || !latest.NextRunAt.After(time.Now()) {
+		t.Fatalf("next run should be rescheduled: %v", latest.NextRunAt)
+	}
+}
@@ -1,11 +1,15 @@
 package system
 
 import (
+	"context"
 	"errors"
+	"strconv"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
 	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	cacheUtils "github.com/flipped-aurora/gin-vue-admin/server/utils/cache"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/replica"
 	"gorm.io/gorm"
 )
 
@@ -19,14 +23,22 @@ type MenuService struct{}
 
 var MenuServiceApp = new(MenuService)
 
-func (menuService *MenuService) getMenuTreeMap(authorityId uint) (treeMap map[uint][]system.SysMenu, err error) {
+// menuTreeCache 角色的动态菜单树 以角色ID区分 修改菜单 角色菜单与角色按钮时失效
+var menuTreeCache = cacheUtils.NewKey[[]system.SysMenu]("menu-tree", 0)
+
+// cacheDB 缓存加载使用主库 避免失效后从延迟的副本读到旧数据并重新写入缓存
+func cacheDB() *gorm.DB {
+	return global.GVA_DB.WithContext(replica.UsePrimary(context.Background()))
+}
+
+func (menuService *MenuService) getMenuTreeMap(db *gorm.DB, authorityId uint) (treeMap map[uint][]system.SysMenu, err error) {
 	var allMenus []system.SysMenu
 	var baseMenu []system.SysBaseMenu
 	var btns []system.SysAuthorityBtn
 	treeMap = make(map[uint][]system.SysMenu)
 
 	var SysAuthorityMenus []system.SysAuthorityMenu
-	err = global.GVA_DB.Where("sys_authority_authority_id = ?", authorityId).Find(&SysAuthorityMenus).Error
+	err = db.Where("sys_authority_authority_id = ?", authorityId).Find(&SysAuthorityMenus).Error
 	if err != nil {
 		return
 	}
@@ -37,7 +49,7 @@ func (menuService *MenuService) getMenuTreeMap(authorityId uint) (treeMap map[ui
 		MenuIds = append(MenuIds, SysAuthorityMenus[i].MenuId)
 	}
 
-	err = global.GVA_DB.Where("id in (?)", MenuIds).Order("sort").Preload("Parameters").Find(&baseMenu).Error
+	err = db.Where("id in (?)", MenuIds).Order("sort").Preload("Parameters").Find(&baseMenu).Error
 	if err != nil {
 		return
 	}
@@ -51,7 +63,7 @@ func (menuService *MenuService) getMenuTreeMap(authorityId uint) (treeMap map[ui
 		})
 	}
 
-	err = global.GVA_DB.Where("authority_id = ?", authorityId).Preload("SysBaseMenuBtn").Find(&btns).Error
+	err = db.Where("authority_id = ?", authorityId).Preload("SysBaseMenuBtn").Find(&btns).Error
 	if err != nil {
 		return
 	}
@@ -76,7 +88,13 @@ func (menuService *MenuService) getMenuTreeMap(authorityId uint) (treeMap map[ui
 //@return: menus []system.SysMenu, err error
 
 func (menuService *MenuService) GetMenuTree(authorityId uint) (menus []system.SysMenu, err error) {
-	menuTree, err := menuService.getMenuTreeMap(authorityId)
+	return menuTreeCache.Get(strconv.FormatUint(uint64(authorityId), 10), func() ([]system.SysMenu, error) {
+		return menuService.getMenuTree(cacheDB(), authorityId)
+	})
+}
+
+func (menuService *MenuService) getMenuTree(db *gorm.DB, authorityId uint) (menus []system.SysMenu, err error) {
+	menuTree, err := menuService.getMenuTreeMap(db, authorityId)
 	menus = menuTree[0]
 	for i := 0; i < len(menus); i++ {
 		err = menuService.getChildrenList(&menus[i], menuTree)
@@ -137,7 +155,11 @@ func (menuService *MenuService) AddBaseMenu(menu system.SysBaseMenu) error {
 	if !errors.Is(global.GVA_DB.Where("name = ?", menu.Name).First(&system.SysBaseMenu{}).Error, gorm.ErrRecordNotFound) {
 		return errors.New("存在重复name，请修改name")
 	}
-	return global.GVA_DB.Create(&menu).Error
+	err := global.GVA_DB.Create(&menu).Error
+	if err == nil {
+		menuTreeCache.InvalidateAll()
+	}
+	return err
 }
 
 //@author: [piexlmax](https://github.com/piexlmax)
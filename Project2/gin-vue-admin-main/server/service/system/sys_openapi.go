@@ -0,0 +1,309 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/docs"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	commonReq "github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/openapi"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const openApiTitle = "Gin-Vue-Admin OpenAPI接口文档"
+
+// openApiEntry 缓存的文档及构建开始时的缓存代数
+type openApiEntry struct {
+	generation uint64
+	doc        *openapi.Document
+}
+
+var (
+	// openApiCache 文档依赖路由与数据库 构建后缓存 代码生成与api变更时失效
+	openApiCache atomic.Pointer[openApiEntry]
+	// openApiGeneration 每次失效加一 失效前开始的构建结果不再写入缓存
+	openApiGeneration atomic.Uint64
+)
+
+type OpenApiService struct{}
+
+var OpenApiServiceApp = new(OpenApiService)
+
+// Spec 获取 OpenAPI 文档 优先使用缓存
+func (s *OpenApiService) Spec(ctx context.Context) (*openapi.Document, error) {
+	generation := openApiGeneration.Load()
+	if entry := openApiCache.Load(); entry != nil && entry.generation == generation {
+		return entry.doc, nil
+	}
+	doc, err := s.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 构建期间发生失效时不缓存 旧代数的条目即使写入也不会被读取
+	if old := openApiCache.Load(); openApiGeneration.Load() == generation && (old == nil || old.generation < generation) {
+		openApiCache.CompareAndSwap(old, &openApiEntry{generation: generation, doc: doc})
+	}
+	return doc, nil
+}
+
+// Invalidate 使缓存的文档失效 下次获取时重新构建
+func (s *OpenApiService) Invalidate() {
+	openApiGeneration.Add(1)
+	openApiCache.Store(nil)
+}
+
+// Build 根据已注册的路由、sys_apis 中的接口信息、登记的请求结构与代码生成记录构建文档
+// 存在于 sys_apis 的接口受 casbin 管控 标记为需要 x-token 鉴权
+func (s *OpenApiService) Build(ctx context.Context) (*openapi.Document, error) {
+	doc := openapi.New(openApiTitle, docs.SwaggerInfo.Version)
+	prefix := global.GVA_CONFIG.System.RouterPrefix
+
+	var apis []system.SysApi
+	if err := global.GVA_DB.WithContext(ctx).Find(&apis).Error; err != nil {
+		return nil, errors.Wrap(err, "获取api失败!")
+	}
+	managed := make(map[string]system.SysApi, len(apis))
+	for _, api := range apis {
+		managed[api.Method+" "+api.Path] = api
+	}
+	seen := make(map[string]bool, len(global.GVA_ROUTERS))
+	for _, route := range global.GVA_ROUTERS {
+		operation := doc.Operation(route.Method, route.Path)
+		operation.OperationID = s.operationID(route.Handler, route.Method, seen)
+		if api, ok := managed[route.Method+" "+route.Path]; ok {
+			operation.Summary = api.Description
+			if api.ApiGroup != "" {
+				operation.Tags = []string{api.ApiGroup}
+			}
+			operation.Secure()
+		}
+	}
+	doc.Apply(prefix)
+
+	var histories []system.SysAutoCodeHistory
+	err := global.GVA_DB.WithContext(ctx).Where("flag = ?", 0).Find(&histories).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "获取代码生成记录失败!")
+	}
+	for _, history := range histories {
+		var info request.AutoCode
+		if err = json.Unmarshal([]byte(history.Request), &info); err != nil {
+			global.GVA_LOG.Warn("解析代码生成记录失败, 跳过!", zap.Uint("id", history.ID), zap.Error(err))
+			continue
+		}
+		if err = info.Pretreatment(); err != nil {
+			global.GVA_LOG.Warn("解析代码生成记录失败, 跳过!", zap.Uint("id", history.ID), zap.Error(err))
+			continue
+		}
+		s.AutoCode(doc, info, prefix)
+	}
+	if len(global.GVA_ROUTERS) > 0 {
+		s.prune(doc)
+	}
+	return doc, nil
+}
+
+// AutoCode 根据代码生成的结构化信息写入接口与模型结构 info 需经过 Pretreatment
+// 与 resource/package 下的 router、api 模版保持一致
+func (s *OpenApiService) AutoCode(doc *openapi.Document, info request.AutoCode, prefix string) {
+	name := info.Package + "." + info.StructName
+	doc.Components.Schemas[name] = s.model(info)
+	base := prefix + "/" + info.Abbreviation + "/"
+	add := func(method, path, summary string, secure bool) *openapi.Operation {
+		operation := doc.Operation(method, base+path)
+		operation.Tags = []string{info.Description}
+		operation.Summary = summary + info.Description
+		if operation.OperationID == "" {
+			operation.OperationID = info.Package + "." + path
+		}
+		if secure {
+			operation.Secure()
+		}
+		return operation
+	}
+	primary := &openapi.Parameter{Name: info.PrimaryField.FieldJson, In: "query", Required: true, Description: info.PrimaryField.FieldDesc, Schema: &openapi.Schema{Type: openapi.Types{"string"}}}
+
+	add(http.MethodPost, "create"+info.StructName, "新增", true).Body(openapi.Ref(name))
+	add(http.MethodDelete, "delete"+info.StructName, "删除", true).Param(primary)
+	add(http.MethodDelete, "delete"+info.StructName+"ByIds", "批量删除", true).Param(&openapi.Parameter{
+		Name:     info.PrimaryField.FieldJson + "s[]",
+		In:       "query",
+		Required: true,
+		Schema:   &openapi.Schema{Type: openapi.Types{"array"}, Items: &openapi.Schema{Type: openapi.Types{"string"}}},
+	})
+	add(http.MethodPut, "update"+info.StructName, "更新", true).Body(openapi.Ref(name))
+	find := add(http.MethodGet, "find"+info.StructName, "用id查询", true)
+	find.Param(primary)
+	find.Data(openapi.Ref(name))
+	list := add(http.MethodGet, "get"+info.StructName+"List", "分页获取", true)
+	for _, param := range s.search(doc, info) {
+		list.Param(param)
+	}
+	list.Data(&openapi.Schema{
+		Type: openapi.Types{"object"},
+		Properties: map[string]*openapi.Schema{
+			"list":     {Type: openapi.Types{"array"}, Items: openapi.Ref(name)},
+			"total":    {Type: openapi.Types{"integer"}},
+			"page":     {Type: openapi.Types{"integer"}},
+			"pageSize": {Type: openapi.Types{"integer"}},
+		},
+	})
+	if info.HasDataSource {
+		add(http.MethodGet, "get"+info.StructName+"DataSource", "获取数据源", false).Data(&openapi.Schema{
+			Type: openapi.Types{"object"},
+			AdditionalProperties: &openapi.Schema{Type: openapi.Types{"array"}, Items: &openapi.Schema{
+				Type:       openapi.Types{"object"},
+				Properties: map[string]*openapi.Schema{"label": {}, "value": {}},
+			}},
+		})
+	}
+	add(http.MethodGet, "get"+info.StructName+"Public", "不需要鉴权的", false)
+}
+
+// model 与 model.go.tpl 生成的结构体的 JSON 结构一致
+func (s *OpenApiService) model(info request.AutoCode) *openapi.Schema {
+	schema := &openapi.Schema{Type: openapi.Types{"object"}, Description: info.Description, Properties: make(map[string]*openapi.Schema)}
+	if info.GvaModel {
+		schema.Properties["ID"] = &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: new(float64), Description: "主键ID"}
+		schema.Properties["CreatedAt"] = &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time", Description: "创建时间"}
+		schema.Properties["UpdatedAt"] = &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time", Description: "更新时间"}
+	}
+	for _, field := range info.Fields {
+		if field.Relation != nil {
+			related := &openapi.Schema{Type: openapi.Types{"object"}, Description: field.FieldDesc + " " + field.Relation.Model}
+			if field.Relation.Type == "belongsTo" {
+				related.Type = append(related.Type, "null")
+				schema.Properties[field.FieldJson] = related
+			} else {
+				schema.Properties[field.FieldJson] = &openapi.Schema{Type: openapi.Types{"array", "null"}, Items: related}
+			}
+			continue
+		}
+		schema.Properties[field.FieldJson] = s.field(field, !field.Require)
+		if field.Require {
+			schema.Required = append(schema.Required, field.FieldJson)
+		}
+	}
+	if info.AutoCreateResource {
+		for _, name := range []string{"CreatedBy", "UpdatedBy", "DeletedBy"} {
+			schema.Properties[name] = &openapi.Schema{Type: openapi.Types{"integer"}, Minimum: new(float64)}
+		}
+	}
+	return schema
+}
+
+// field 字段类型对应的结构 nullable 为 true 时指针类型的字段允许为 null
+func (s *OpenApiService) field(field *request.AutoCodeField, nullable bool) *openapi.Schema {
+	schema := &openapi.Schema{Description: field.FieldDesc}
+	switch field.FieldType {
+	case "string", "richtext", "picture", "video":
+		schema.Type = openapi.Types{"string"}
+		if size, err := strconv.Atoi(field.DataTypeLong); err == nil && size > 0 && field.FieldType != "richtext" {
+			schema.MaxLength = &size
+		}
+		return schema
+	case "enum":
+		schema.Type = openapi.Types{"string"}
+		for _, value := range strings.Split(field.DataTypeLong, ",") {
+			schema.Enum = append(schema.Enum, strings.Trim(value, "' "))
+		}
+		return schema
+	case "json":
+		return schema
+	case "array", "file", "pictures":
+		schema.Type = openapi.Types{"array"}
+		schema.Items = &openapi.Schema{}
+		return schema
+	case "int":
+		schema.Type = openapi.Types{"integer"}
+	case "uint":
+		schema.Type = openapi.Types{"integer"}
+		schema.Minimum = new(float64)
+	case "float64":
+		schema.Type = openapi.Types{"number"}
+	case "bool":
+		schema.Type = openapi.Types{"boolean"}
+	case "time.Time":
+		schema.Type = openapi.Types{"string"}
+		schema.Format = "date-time"
+	default:
+		return schema
+	}
+	if nullable {
+		schema.Type = append(schema.Type, "null")
+	}
+	return schema
+}
+
+// search 与 request.go.tpl 生成的搜索结构体的查询参数一致
+func (s *OpenApiService) search(doc *openapi.Document, info request.AutoCode) []*openapi.Parameter {
+	params := doc.Query(commonReq.PageInfo{})
+	query := func(name, description string, schema *openapi.Schema) {
+		schema.Description = ""
+		params = append(params, &openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema})
+	}
+	if info.GvaModel {
+		query("startCreatedAt", "创建时间起", &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time"})
+		query("endCreatedAt", "创建时间止", &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time"})
+	}
+	for _, field := range info.Fields {
+		switch {
+		case field.FieldSearchType == "":
+		case field.Relation != nil:
+			query(field.FieldJson, "按"+field.FieldDesc+"的"+field.Relation.SearchColumn+"搜索", &openapi.Schema{Type: openapi.Types{"string"}})
+		case field.FieldSearchType == "BETWEEN" || field.FieldSearchType == "NOT BETWEEN":
+			query("start"+field.FieldName, field.FieldDesc+"起", s.field(field, false))
+			query("end"+field.FieldName, field.FieldDesc+"止", s.field(field, false))
+		default:
+			schema := s.field(field, false)
+			schema.MaxLength = nil // LIKE 搜索可以只传部分内容
+			if field.FieldType == "enum" {
+				schema.Enum = nil
+			}
+			query(field.FieldJson, "按"+field.FieldDesc+"搜索", schema)
+		}
+	}
+	if info.NeedSort {
+		query("sort", "排序字段", &openapi.Schema{Type: openapi.Types{"string"}})
+		query("order", "排序方式 descending 为倒序", &openapi.Schema{Type: openapi.Types{"string"}})
+	}
+	return params
+}
+
+// prune 移除未注册路由的接口 如已生成但尚未重启服务的代码
+func (s *OpenApiService) prune(doc *openapi.Document) {
+	registered := make(map[string]bool, len(global.GVA_ROUTERS))
+	for _, route := range global.GVA_ROUTERS {
+		path, _ := openapi.Path(route.Path)
+		registered[strings.ToLower(route.Method)+" "+path] = true
+	}
+	for path, item := range doc.Paths {
+		for method := range item {
+			if !registered[method+" "+path] {
+				delete(item, method)
+			}
+		}
+		if len(item) == 0 {
+			delete(doc.Paths, path)
+		}
+	}
+}
+
+// operationID 由处理函数名生成 如 system.SystemApiApi.CreateApi 重复时追加请求方法
+func (s *OpenApiService) operationID(handler, method string, seen map[string]bool) string {
+	id := handler[strings.LastIndex(handler, "/")+1:]
+	id = strings.NewReplacer("(*", "", ")", "", "-fm", "").Replace(id)
+	if seen[id] {
+		id += "." + strings.ToLower(method)
+	}
+	seen[id] = true
+	return id
+}
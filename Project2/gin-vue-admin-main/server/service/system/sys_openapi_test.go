@@ -0,0 +1,56 @@
+package system
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"github.com/gin-gonic/gin"
+)
+
+func TestOpenApiService_SpecInvalidate(t *testing.T) {
+	testdb.Open(t, "", &system.SysApi{}, &system.SysAutoCodeHistory{})
+	routers, routerPrefix := global.GVA_ROUTERS, global.GVA_CONFIG.System.RouterPrefix
+	global.GVA_ROUTERS = gin.RoutesInfo{{Method: http.MethodGet, Path: "/demo/list", Handler: "demo.List"}}
+	global.GVA_CONFIG.System.RouterPrefix = ""
+	service := OpenApiServiceApp
+	service.Invalidate()
+	t.Cleanup(func() {
+		global.GVA_ROUTERS, global.GVA_CONFIG.System.RouterPrefix = routers, routerPrefix
+		service.Invalidate()
+	})
+	ctx := context.Background()
+
+	first, err := service.Spec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := service.Spec(ctx); cached != first {
+		t.Fatal("Spec() should return the cached document")
+	}
+
+	if err = global.GVA_DB.Create(&system.SysApi{Path: "/demo/list", Method: http.MethodGet, Description: "演示列表", ApiGroup: "demo"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 失效前开始的构建在失效后才写入缓存 结果不能再被读取
+	generation := openApiGeneration.Load()
+	service.Invalidate()
+	openApiCache.Store(&openApiEntry{generation: generation, doc: first})
+
+	doc, err := service.Spec(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc == first {
+		t.Fatal("Spec() should not return a document built before Invalidate()")
+	}
+	if operation := doc.Paths["/demo/list"]["get"]; operation == nil || operation.Summary != "演示列表" {
+		t.Fatalf("rebuilt document should include the new api: %+v", operation)
+	}
+	if cached, _ := service.Spec(ctx); cached != doc {
+		t.Fatal("rebuilt document should be cached")
+	}
+}
@@ -79,6 +79,9 @@ func (operationRecordService *OperationRecordService) GetSysOperationRecordInfoL
 	if info.Status != 0 {
 		db = db.Where("status = ?", info.Status)
 	}
+	if info.TraceID != "" {
+		db = db.Where("trace_id = ?", info.TraceID)
+	}
 	err = db.Count(&total).Error
 	if err != nil {
 		return
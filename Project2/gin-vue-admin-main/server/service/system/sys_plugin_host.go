@@ -0,0 +1,397 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/docs"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/response"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote/pb"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// remotePlugin 进程外插件 崩溃后按退避时长自动重启, 重启后重新下发配置
+type remotePlugin struct {
+	config   config.RemotePlugin
+	mu       sync.RWMutex
+	name     string
+	process  *remote.Process
+	info     *pb.DescribeResponse
+	settings json.RawMessage
+	stopped  bool
+}
+
+type PluginHostService struct{}
+
+var PluginHostServiceApp = new(PluginHostService)
+
+// Start 启动配置的进程外插件 代理插件声明的路由并注册api与菜单
+// 非公开路由挂载在 privateGroup 上, 与内置接口一样经过 JWT 与 Casbin 鉴权
+func (s *PluginHostService) Start(ctx context.Context, privateGroup *gin.RouterGroup, publicGroup *gin.RouterGroup) {
+	interval := time.Duration(global.GVA_CONFIG.PluginHost.HealthInterval) * time.Second
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	for _, item := range global.GVA_CONFIG.PluginHost.Plugins {
+		p := &remotePlugin{config: item, name: item.Name}
+		if err := p.start(ctx); err != nil {
+			global.GVA_LOG.Error("启动进程外插件失败!", zap.String("plugin", item.Name), zap.Error(err))
+			continue
+		}
+		routes := s.mount(p, privateGroup, publicGroup)
+		if err := s.register(ctx, p.info); err != nil {
+			global.GVA_LOG.Error("注册进程外插件的api与菜单失败!", zap.String("plugin", p.Name()), zap.Error(err))
+		}
+		PluginLifecycleServiceApp.Register(p, "", routes...)
+		go p.monitor(interval)
+		global.GVA_LOG.Info("进程外插件启动成功", zap.String("plugin", p.Name()), zap.String("version", p.info.Version))
+	}
+}
+
+// mount 为插件声明的路由挂载代理 返回挂载后的完整路由
+func (s *PluginHostService) mount(p *remotePlugin, privateGroup *gin.RouterGroup, publicGroup *gin.RouterGroup) gin.RoutesInfo {
+	var routes gin.RoutesInfo
+	for _, route := range p.info.Routes {
+		group := privateGroup
+		if route.Public {
+			group = publicGroup
+		}
+		if err := handle(group, route.Method, route.Path, p.proxy); err != nil {
+			global.GVA_LOG.Error("挂载插件路由失败!", zap.String("plugin", p.Name()), zap.String("route", route.Method+" "+route.Path), zap.Error(err))
+			continue
+		}
+		routes = append(routes, gin.RouteInfo{Method: route.Method, Path: joinPath(group.BasePath(), route.Path)})
+	}
+	return routes
+}
+
+// register 注册插件声明的api与菜单 已存在的不重复创建
+func (s *PluginHostService) register(ctx context.Context, info *pb.DescribeResponse) error {
+	return global.GVA_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range info.Apis {
+			api := model.SysApi{Path: item.Path, Method: item.Method, ApiGroup: item.ApiGroup, Description: item.Description}
+			err := tx.Where("path = ? AND method = ?", api.Path, api.Method).FirstOrCreate(&api).Error
+			if err != nil {
+				return errors.Wrapf(err, "[path:%s method:%s]注册api失败!", api.Path, api.Method)
+			}
+		}
+		_, err := AutoCodePlugin.createMenus(tx, 0, remoteMenus(info.Menus))
+		return err
+	})
+}
+
+func remoteMenus(menus []*pb.Menu) []model.SysBaseMenu {
+	list := make([]model.SysBaseMenu, 0, len(menus))
+	for _, menu := range menus {
+		list = append(list, model.SysBaseMenu{
+			ParentId:  uint(menu.ParentId),
+			Path:      menu.Path,
+			Name:      menu.Name,
+			Hidden:    menu.Hidden,
+			Component: menu.Component,
+			Sort:      int(menu.Sort),
+			Meta:      model.Meta{Title: menu.Title, Icon: menu.Icon},
+			Children:  remoteMenus(menu.Children),
+		})
+	}
+	return list
+}
+
+// handle 挂载路由 路由冲突时 gin 会 panic, 此处转换为错误
+func handle(group *gin.RouterGroup, method, relativePath string, handler gin.HandlerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	group.Handle(method, relativePath, handler)
+	return nil
+}
+
+func joinPath(basePath, relativePath string) string {
+	full := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(full, "/") {
+		return full + "/"
+	}
+	return full
+}
+
+func (p *remotePlugin) Name() string {
+	return p.name
+}
+
+func (p *remotePlugin) Version() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.info == nil {
+		return ""
+	}
+	return p.info.Version
+}
+
+// spawn 启动插件进程 获取插件描述并下发已保存的配置
+func (p *remotePlugin) spawn(ctx context.Context) (*remote.Process, *pb.DescribeResponse, error) {
+	timeout := time.Duration(global.GVA_CONFIG.PluginHost.StartTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	process := &remote.Process{Path: p.config.Path, Args: p.config.Args, Env: p.config.Env}
+	if err := process.Start(ctx, timeout); err != nil {
+		return nil, nil, err
+	}
+	rpcCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	info, err := process.Client().Describe(rpcCtx, &pb.DescribeRequest{HostVersion: docs.SwaggerInfo.Version})
+	if err != nil {
+		process.Kill()
+		return nil, nil, errors.Wrap(err, "获取插件描述失败!")
+	}
+	if p.config.Name != "" && info.Name != p.config.Name {
+		process.Kill()
+		return nil, nil, errors.Errorf("插件名不一致, 配置为%s, 插件为%s!", p.config.Name, info.Name)
+	}
+	p.mu.RLock()
+	settings := p.settings
+	p.mu.RUnlock()
+	if len(settings) > 0 {
+		if _, err = process.Client().Configure(rpcCtx, &pb.ConfigureRequest{Config: settings}); err != nil {
+			process.Kill()
+			return nil, nil, errors.Wrap(err, "下发插件配置失败!")
+		}
+	}
+	return process, info, nil
+}
+
+// start 启动插件 已在运行时不做处理
+func (p *remotePlugin) start(ctx context.Context) error {
+	p.mu.Lock()
+	p.stopped = false
+	running := p.process != nil
+	p.mu.Unlock()
+	if running {
+		return nil
+	}
+	process, info, err := p.spawn(ctx)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.process = process
+	if p.info == nil {
+		p.info = info
+		p.name = info.Name
+	}
+	p.mu.Unlock()
+	go p.watch(process)
+	return nil
+}
+
+// stop 停止插件 停止后不会自动重启
+func (p *remotePlugin) stop() {
+	p.mu.Lock()
+	p.stopped = true
+	process := p.process
+	p.process = nil
+	p.mu.Unlock()
+	if process != nil {
+		process.Stop(5 * time.Second)
+	}
+}
+
+func (p *remotePlugin) current() *remote.Process {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.process
+}
+
+// watch 插件进程意外退出时按 1s 到 30s 的退避时长重启
+func (p *remotePlugin) watch(process *remote.Process) {
+	<-process.Done()
+	global.GVA_LOG.Warn("进程外插件已退出", zap.String("plugin", p.Name()), zap.Error(process.Err()))
+	backoff := time.Second
+	for {
+		p.mu.RLock()
+		replaced := p.stopped || p.process != process
+		p.mu.RUnlock()
+		if replaced {
+			return
+		}
+		time.Sleep(backoff)
+		next, _, err := p.spawn(context.Background())
+		if err != nil {
+			global.GVA_LOG.Error("重启进程外插件失败!", zap.String("plugin", p.Name()), zap.Error(err))
+			if backoff *= 2; backoff > 30*time.Second {
+				backoff = 30 * time.Second
+			}
+			continue
+		}
+		p.mu.Lock()
+		if p.stopped || p.process != process {
+			p.mu.Unlock()
+			next.Stop(5 * time.Second)
+			return
+		}
+		p.process = next
+		p.mu.Unlock()
+		global.GVA_LOG.Info("进程外插件重启成功", zap.String("plugin", p.Name()))
+		go p.watch(next)
+		return
+	}
+}
+
+// monitor 定时健康检查 连续3次失败时结束进程, 由 watch 负责重启
+func (p *remotePlugin) monitor(interval time.Duration) {
+	failures := 0
+	for range time.Tick(interval) {
+		process := p.current()
+		if process == nil {
+			failures = 0
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := p.health(ctx, process)
+		cancel()
+		if err == nil {
+			failures = 0
+			continue
+		}
+		failures++
+		global.GVA_LOG.Warn("进程外插件健康检查失败", zap.String("plugin", p.Name()), zap.Int("failures", failures), zap.Error(err))
+		if failures >= 3 {
+			failures = 0
+			process.Kill()
+		}
+	}
+}
+
+func (p *remotePlugin) health(ctx context.Context, process *remote.Process) error {
+	resp, err := process.Client().Health(ctx, &pb.HealthRequest{})
+	if err != nil {
+		return err
+	}
+	if !resp.Ok {
+		return errors.New(resp.Message)
+	}
+	return nil
+}
+
+// 以下为插件生命周期钩子 启停插件时启动或停止进程, 配置与健康检查通过 gRPC 转发给插件
+
+func (p *remotePlugin) OnEnable(ctx context.Context) error {
+	return p.start(ctx)
+}
+
+func (p *remotePlugin) OnDisable(ctx context.Context) error {
+	p.stop()
+	return nil
+}
+
+func (p *remotePlugin) OnConfigChange(ctx context.Context, config json.RawMessage) error {
+	if process := p.current(); process != nil {
+		if _, err := process.Client().Configure(ctx, &pb.ConfigureRequest{Config: config}); err != nil {
+			return err
+		}
+	}
+	p.mu.Lock()
+	p.settings = config
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *remotePlugin) HealthCheck(ctx context.Context) error {
+	process := p.current()
+	if process == nil {
+		return errors.New("插件未运行")
+	}
+	return p.health(ctx, process)
+}
+
+// proxyStripHeaders 不转发给插件的凭证请求头 插件只能通过 pb.User 获取当前用户
+var proxyStripHeaders = map[string]bool{
+	"X-Token":             true,
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+}
+
+// proxy 将请求转发给插件 携带当前用户, 插件未运行时返回503
+func (p *remotePlugin) proxy(c *gin.Context) {
+	process := p.current()
+	if process == nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, response.Response{Code: response.ERROR, Data: gin.H{}, Msg: "插件[" + p.Name() + "]未运行"})
+		return
+	}
+	maxBodySize := int64(global.GVA_CONFIG.PluginHost.MaxBodySize) << 20
+	if maxBodySize <= 0 {
+		maxBodySize = 10 << 20
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, response.Response{Code: response.ERROR, Data: gin.H{}, Msg: "请求体过大"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{Code: response.ERROR, Data: gin.H{}, Msg: "读取请求失败"})
+		return
+	}
+	prefix := global.GVA_CONFIG.System.RouterPrefix
+	req := &pb.HttpRequest{
+		Method:     c.Request.Method,
+		Path:       strings.TrimPrefix(c.Request.URL.Path, prefix),
+		Route:      strings.TrimPrefix(c.FullPath(), prefix),
+		Query:      c.Request.URL.RawQuery,
+		Params:     make(map[string]string, len(c.Params)),
+		Body:       body,
+		RemoteAddr: c.ClientIP(),
+	}
+	for _, param := range c.Params {
+		req.Params[param.Key] = param.Value
+	}
+	for key, values := range c.Request.Header {
+		if proxyStripHeaders[http.CanonicalHeaderKey(key)] {
+			continue
+		}
+		req.Headers = append(req.Headers, &pb.Header{Key: key, Values: values})
+	}
+	if claims, ok := c.Get("claims"); ok {
+		if user, ok := claims.(*request.CustomClaims); ok {
+			req.User = &pb.User{Id: uint64(user.BaseClaims.ID), Uuid: user.UUID.String(), Username: user.Username, AuthorityId: uint64(user.AuthorityId)}
+		}
+	}
+	timeout := time.Duration(global.GVA_CONFIG.PluginHost.RequestTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	resp, err := process.Client().Handle(ctx, req)
+	if err != nil {
+		global.GVA_LOG.Error("插件处理请求失败!", zap.String("plugin", p.Name()), zap.String("path", req.Path), zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusBadGateway, response.Response{Code: response.ERROR, Data: gin.H{}, Msg: "插件[" + p.Name() + "]处理请求失败"})
+		return
+	}
+	for _, header := range resp.Headers {
+		if header.Key == "Content-Length" {
+			continue
+		}
+		c.Writer.Header()[header.Key] = header.Values
+	}
+	c.Status(int(resp.Status))
+	_, _ = c.Writer.Write(resp.Body)
+}
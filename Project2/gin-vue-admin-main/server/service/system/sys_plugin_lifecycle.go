@@ -0,0 +1,277 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system/response"
+	plugin "github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// pluginEntry 已加载的插件 v1插件按路由前缀归属路由, v2插件记录注册前后新增的路由
+type pluginEntry struct {
+	name     string
+	kind     string
+	instance interface{}
+	prefix   string
+	routes   []string
+}
+
+var (
+	pluginMu       sync.RWMutex
+	pluginEntries  = make(map[string]*pluginEntry)
+	pluginRoutes   = make(map[string]string) // METHOD path => 插件名
+	pluginDisabled = make(map[string]bool)
+)
+
+type PluginLifecycleService struct{}
+
+var PluginLifecycleServiceApp = new(PluginLifecycleService)
+
+// PluginName 插件名 进程外插件为自身声明的名称, v1插件为路由前缀, v2插件为所在包名
+func (s *PluginLifecycleService) PluginName(instance interface{}) string {
+	if p, ok := instance.(interface{ Name() string }); ok {
+		return p.Name()
+	}
+	if p, ok := instance.(interface{ RouterPath() string }); ok {
+		return p.RouterPath()
+	}
+	typ := reflect.TypeOf(instance)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	path := typ.PkgPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// Register 登记已加载的插件 routes 为v2插件注册的路由, v1插件通过 prefix 在 Bind 时归属路由
+func (s *PluginLifecycleService) Register(instance interface{}, prefix string, routes ...gin.RouteInfo) {
+	entry := &pluginEntry{name: s.PluginName(instance), kind: "v1", instance: instance, prefix: prefix}
+	switch instance.(type) {
+	case plugin.Plugin:
+		entry.kind = "v2"
+	case *remotePlugin:
+		entry.kind = "remote"
+	}
+	pluginMu.Lock()
+	defer pluginMu.Unlock()
+	pluginEntries[entry.name] = entry
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		pluginRoutes[key] = entry.name
+		entry.routes = append(entry.routes, key)
+	}
+}
+
+// Bind 按路由前缀将全部路由归属到v1插件
+func (s *PluginLifecycleService) Bind(routes gin.RoutesInfo) {
+	pluginMu.Lock()
+	defer pluginMu.Unlock()
+	for _, entry := range pluginEntries {
+		if entry.prefix == "" {
+			continue
+		}
+		for _, route := range routes {
+			if route.Path != entry.prefix && !strings.HasPrefix(route.Path, entry.prefix+"/") {
+				continue
+			}
+			key := route.Method + " " + route.Path
+			pluginRoutes[key] = entry.name
+			entry.routes = append(entry.routes, key)
+		}
+	}
+}
+
+// Load 读取插件运行状态 未登记的插件默认启用, 已保存的配置与停用状态会通知插件
+func (s *PluginLifecycleService) Load(ctx context.Context) error {
+	var states []model.SysPluginState
+	err := global.GVA_DB.WithContext(ctx).Find(&states).Error
+	if err != nil {
+		return errors.Wrap(err, "获取插件运行状态失败!")
+	}
+	saved := make(map[string]model.SysPluginState, len(states))
+	for _, state := range states {
+		saved[state.Name] = state
+	}
+	// 钩子与数据库操作不持有 pluginMu 避免插件钩子回调生命周期服务时死锁 也不阻塞路由的停用判断
+	pluginMu.RLock()
+	entries := make(map[string]*pluginEntry, len(pluginEntries))
+	for name, entry := range pluginEntries {
+		entries[name] = entry
+	}
+	pluginMu.RUnlock()
+	for name := range entries {
+		if _, ok := saved[name]; ok {
+			continue
+		}
+		state := model.SysPluginState{Name: name, Enabled: true}
+		if err = global.GVA_DB.WithContext(ctx).Create(&state).Error; err != nil {
+			return errors.Wrapf(err, "[plugin:%s]登记插件运行状态失败!", name)
+		}
+		saved[name] = state
+	}
+	pluginMu.Lock()
+	for name := range entries {
+		pluginDisabled[name] = !saved[name].Enabled
+	}
+	pluginMu.Unlock()
+	for name, entry := range entries {
+		hooks, ok := entry.instance.(plugin.Lifecycle)
+		if !ok {
+			continue
+		}
+		state := saved[name]
+		if len(state.Config) > 0 {
+			if err = hooks.OnConfigChange(ctx, state.Config); err != nil {
+				global.GVA_LOG.Error("插件加载配置失败!", zap.String("plugin", name), zap.Error(err))
+			}
+		}
+		if !state.Enabled {
+			if err = hooks.OnDisable(ctx); err != nil {
+				global.GVA_LOG.Error("插件停用失败!", zap.String("plugin", name), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// Disabled 路由是否属于已停用的插件
+func (s *PluginLifecycleService) Disabled(method, path string) bool {
+	pluginMu.RLock()
+	defer pluginMu.RUnlock()
+	name, ok := pluginRoutes[method+" "+path]
+	return ok && pluginDisabled[name]
+}
+
+// SetEnabled 启用或停用插件 先调用插件钩子, 钩子返回错误时不改变状态
+func (s *PluginLifecycleService) SetEnabled(ctx context.Context, name string, enabled bool) error {
+	entry, err := s.entry(name)
+	if err != nil {
+		return err
+	}
+	if hooks, ok := entry.instance.(plugin.Lifecycle); ok {
+		if enabled {
+			err = hooks.OnEnable(ctx)
+		} else {
+			err = hooks.OnDisable(ctx)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "[plugin:%s]插件钩子执行失败!", name)
+		}
+	}
+	err = global.GVA_DB.WithContext(ctx).Model(&model.SysPluginState{}).Where("name = ?", name).Update("enabled", enabled).Error
+	if err != nil {
+		return errors.Wrap(err, "更新插件状态失败!")
+	}
+	pluginMu.Lock()
+	pluginDisabled[name] = !enabled
+	pluginMu.Unlock()
+	return nil
+}
+
+// UpdateConfig 修改插件配置 按插件清单校验必填项后通知插件并保存
+func (s *PluginLifecycleService) UpdateConfig(ctx context.Context, name string, config json.RawMessage) error {
+	entry, err := s.entry(name)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err = json.Unmarshal(config, &values); err != nil {
+		return errors.Wrap(err, "插件配置必须为JSON对象!")
+	}
+	var installed model.SysPlugin
+	err = global.GVA_DB.WithContext(ctx).Where("name = ?", name).First(&installed).Error
+	if err == nil {
+		for _, field := range installed.Manifest.Config {
+			if value, ok := values[field.Key]; field.Required && (!ok || value == nil) {
+				return errors.Errorf("缺少必填配置[%s]!", field.Key)
+			}
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if hooks, ok := entry.instance.(plugin.Lifecycle); ok {
+		if err = hooks.OnConfigChange(ctx, config); err != nil {
+			return errors.Wrapf(err, "[plugin:%s]插件拒绝了配置!", name)
+		}
+	}
+	return global.GVA_DB.WithContext(ctx).Model(&model.SysPluginState{}).Where("name = ?", name).Update("config", config).Error
+}
+
+// Status 获取已加载插件的运行状态 启用的插件会执行健康检查
+func (s *PluginLifecycleService) Status(ctx context.Context) ([]response.SysPluginStatus, error) {
+	var states []model.SysPluginState
+	err := global.GVA_DB.WithContext(ctx).Find(&states).Error
+	if err != nil {
+		return nil, err
+	}
+	var installed []model.SysPlugin
+	err = global.GVA_DB.WithContext(ctx).Find(&installed).Error
+	if err != nil {
+		return nil, err
+	}
+	configs := make(map[string]json.RawMessage, len(states))
+	for _, state := range states {
+		configs[state.Name] = state.Config
+	}
+	versions := make(map[string]string, len(installed))
+	for _, item := range installed {
+		versions[item.Name] = item.Version
+	}
+	pluginMu.RLock()
+	entries := make([]pluginEntry, 0, len(pluginEntries))
+	disabled := make(map[string]bool, len(pluginDisabled))
+	for name, entry := range pluginEntries {
+		entries = append(entries, *entry)
+		disabled[name] = pluginDisabled[name]
+	}
+	pluginMu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	list := make([]response.SysPluginStatus, 0, len(entries))
+	for _, entry := range entries {
+		status := response.SysPluginStatus{
+			Name:    entry.name,
+			Kind:    entry.kind,
+			Version: versions[entry.name],
+			Enabled: !disabled[entry.name],
+			Routes:  entry.routes,
+			Config:  configs[entry.name],
+		}
+		if v, ok := entry.instance.(interface{ Version() string }); ok && status.Version == "" {
+			status.Version = v.Version()
+		}
+		if hooks, ok := entry.instance.(plugin.Lifecycle); ok && status.Enabled {
+			checkCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+			if err = hooks.HealthCheck(checkCtx); err != nil {
+				status.Error = err.Error()
+			}
+			cancel()
+		}
+		status.Healthy = status.Enabled && status.Error == ""
+		list = append(list, status)
+	}
+	return list, nil
+}
+
+func (s *PluginLifecycleService) entry(name string) (*pluginEntry, error) {
+	pluginMu.RLock()
+	defer pluginMu.RUnlock()
+	entry, ok := pluginEntries[name]
+	if !ok {
+		return nil, errors.Errorf("插件[%s]未加载!", name)
+	}
+	return entry, nil
+}
@@ -0,0 +1,162 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"github.com/gin-gonic/gin"
+)
+
+// lifecyclePlugin 记录钩子调用 钩子内会回调 Disabled 以确认不持有 pluginMu
+type lifecyclePlugin struct {
+	calls  []string
+	config json.RawMessage
+	reject bool
+}
+
+func (p *lifecyclePlugin) Name() string { return "demo" }
+
+func (p *lifecyclePlugin) OnEnable(ctx context.Context) error {
+	p.calls = append(p.calls, "enable")
+	if p.reject {
+		return errors.New("rejected")
+	}
+	return nil
+}
+
+func (p *lifecyclePlugin) OnDisable(ctx context.Context) error {
+	p.calls = append(p.calls, "disable")
+	PluginLifecycleServiceApp.Disabled(http.MethodGet, "/demo/list")
+	return nil
+}
+
+func (p *lifecyclePlugin) OnConfigChange(ctx context.Context, config json.RawMessage) error {
+	p.calls = append(p.calls, "config")
+	PluginLifecycleServiceApp.Disabled(http.MethodGet, "/demo/list")
+	if p.reject {
+		return errors.New("rejected")
+	}
+	p.config = config
+	return nil
+}
+
+func (p *lifecyclePlugin) HealthCheck(ctx context.Context) error { return nil }
+
+func registerLifecyclePlugin(t *testing.T) *lifecyclePlugin {
+	testdb.Open(t, "", &model.SysPluginState{}, &model.SysPlugin{})
+	p := &lifecyclePlugin{}
+	PluginLifecycleServiceApp.Register(p, "", gin.RouteInfo{Method: http.MethodGet, Path: "/demo/list"})
+	t.Cleanup(func() {
+		pluginMu.Lock()
+		delete(pluginEntries, "demo")
+		delete(pluginRoutes, "GET /demo/list")
+		delete(pluginDisabled, "demo")
+		pluginMu.Unlock()
+	})
+	return p
+}
+
+func TestPluginLifecycleService_Load(t *testing.T) {
+	p := registerLifecyclePlugin(t)
+	ctx := context.Background()
+	state := model.SysPluginState{Name: "demo", Config: json.RawMessage(`{"key":"v"}`)}
+	if err := global.GVA_DB.Create(&state).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := PluginLifecycleServiceApp.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.calls) != 2 || p.calls[0] != "config" || p.calls[1] != "disable" || string(p.config) != `{"key":"v"}` {
+		t.Fatalf("Load() should apply saved config then disable: %v %s", p.calls, p.config)
+	}
+	if !PluginLifecycleServiceApp.Disabled(http.MethodGet, "/demo/list") {
+		t.Fatal("routes of a disabled plugin should be gated")
+	}
+	if PluginLifecycleServiceApp.Disabled(http.MethodGet, "/other") {
+		t.Fatal("routes of other plugins should not be gated")
+	}
+}
+
+func TestPluginLifecycleService_LoadDefaultEnabled(t *testing.T) {
+	p := registerLifecyclePlugin(t)
+	if err := PluginLifecycleServiceApp.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var state model.SysPluginState
+	if err := global.GVA_DB.Where("name = ?", "demo").First(&state).Error; err != nil || !state.Enabled {
+		t.Fatalf("missing state should be created as enabled: %+v %v", state, err)
+	}
+	if len(p.calls) != 0 || PluginLifecycleServiceApp.Disabled(http.MethodGet, "/demo/list") {
+		t.Fatalf("enabled plugin without config should not be notified: %v", p.calls)
+	}
+}
+
+func TestPluginLifecycleService_SetEnabled(t *testing.T) {
+	p := registerLifecyclePlugin(t)
+	ctx := context.Background()
+	if err := PluginLifecycleServiceApp.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := PluginLifecycleServiceApp.SetEnabled(ctx, "demo", false); err != nil {
+		t.Fatal(err)
+	}
+	if !PluginLifecycleServiceApp.Disabled(http.MethodGet, "/demo/list") {
+		t.Fatal("disabled plugin routes should be gated")
+	}
+	p.reject = true
+	if err := PluginLifecycleServiceApp.SetEnabled(ctx, "demo", true); err == nil {
+		t.Fatal("rejected OnEnable should fail")
+	}
+	if !PluginLifecycleServiceApp.Disabled(http.MethodGet, "/demo/list") {
+		t.Fatal("rejected OnEnable should keep the plugin disabled")
+	}
+	p.reject = false
+	if err := PluginLifecycleServiceApp.SetEnabled(ctx, "demo", true); err != nil {
+		t.Fatal(err)
+	}
+	var state model.SysPluginState
+	global.GVA_DB.Where("name = ?", "demo").First(&state)
+	if !state.Enabled || PluginLifecycleServiceApp.Disabled(http.MethodGet, "/demo/list") {
+		t.Fatal("enabled plugin should be saved and ungated")
+	}
+	if err := PluginLifecycleServiceApp.SetEnabled(ctx, "missing", true); err == nil {
+		t.Fatal("unknown plugin should fail")
+	}
+}
+
+func TestPluginLifecycleService_UpdateConfig(t *testing.T) {
+	p := registerLifecyclePlugin(t)
+	ctx := context.Background()
+	if err := PluginLifecycleServiceApp.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	installed := model.SysPlugin{Name: "demo", Manifest: model.PluginManifest{Config: []model.PluginConfigField{{Key: "token", Required: true}}}}
+	if err := global.GVA_DB.Create(&installed).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := PluginLifecycleServiceApp.UpdateConfig(ctx, "demo", json.RawMessage(`{}`)); err == nil {
+		t.Fatal("missing required config should fail")
+	}
+	if err := PluginLifecycleServiceApp.UpdateConfig(ctx, "demo", json.RawMessage(`[]`)); err == nil {
+		t.Fatal("config must be a JSON object")
+	}
+	p.reject = true
+	if err := PluginLifecycleServiceApp.UpdateConfig(ctx, "demo", json.RawMessage(`{"token":"a"}`)); err == nil {
+		t.Fatal("config rejected by the plugin should fail")
+	}
+	p.reject = false
+	if err := PluginLifecycleServiceApp.UpdateConfig(ctx, "demo", json.RawMessage(`{"token":"b"}`)); err != nil {
+		t.Fatal(err)
+	}
+	var state model.SysPluginState
+	global.GVA_DB.Where("name = ?", "demo").First(&state)
+	if string(state.Config) != `{"token":"b"}` || string(p.config) != `{"token":"b"}` {
+		t.Fatalf("accepted config should be saved and applied: %s %s", state.Config, p.config)
+	}
+}
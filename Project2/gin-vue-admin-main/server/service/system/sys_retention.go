@@ -0,0 +1,472 @@
+package system
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/common/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	systemReq "github.com/flipped-aurora/gin-vue-admin/server/model/system/request"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/upload"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultRetentionBatchSize = 1000
+
+// retentionIdentifier 表名与字段名只允许字母数字下划线 表名可带库名前缀
+var retentionIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// retentionProtected 不允许配置保留策略的核心表
+var retentionProtected = map[string]bool{
+	"sys_users":          true,
+	"sys_authorities":    true,
+	"sys_user_authority": true,
+	"sys_apis":           true,
+	"sys_base_menus":     true,
+	"casbin_rule":        true,
+	"sys_jobs":           true,
+	"sys_job_runs":       true,
+	"sys_job_locks":      true,
+	"sys_migrations":     true,
+	"sys_db_connections": true,
+}
+
+// retentionProtectedPrefixes 不允许配置保留策略的表名前缀
+var retentionProtectedPrefixes = []string{"sys_retention_"}
+
+// retentionTableProtected 是否为受保护的表 带库名前缀时按表名判断, 不区分大小写
+func retentionTableProtected(table string) bool {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	table = strings.ToLower(table)
+	if retentionProtected[table] {
+		return true
+	}
+	for _, prefix := range retentionProtectedPrefixes {
+		if strings.HasPrefix(table, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// retentionRunning 正在执行的策略ID 避免同一策略重叠执行
+var retentionRunning sync.Map
+
+type SysRetentionService struct{}
+
+var SysRetentionServiceApp = new(SysRetentionService)
+
+// CreateSysRetentionPolicy 创建数据保留策略
+func (s *SysRetentionService) CreateSysRetentionPolicy(policy *system.SysRetentionPolicy) error {
+	if err := s.check(*policy); err != nil {
+		return err
+	}
+	return global.GVA_DB.Create(policy).Error
+}
+
+// DeleteSysRetentionPolicy 删除数据保留策略 保留执行报告
+func (s *SysRetentionService) DeleteSysRetentionPolicy(id uint) error {
+	return global.GVA_DB.Delete(&system.SysRetentionPolicy{}, "id = ?", id).Error
+}
+
+// DeleteSysRetentionPolicyByIds 批量删除数据保留策略
+func (s *SysRetentionService) DeleteSysRetentionPolicyByIds(ids request.IdsReq) error {
+	return global.GVA_DB.Delete(&[]system.SysRetentionPolicy{}, "id in ?", ids.Ids).Error
+}
+
+// UpdateSysRetentionPolicy 更新数据保留策略
+func (s *SysRetentionService) UpdateSysRetentionPolicy(policy system.SysRetentionPolicy) error {
+	if err := s.check(policy); err != nil {
+		return err
+	}
+	return global.GVA_DB.Model(&system.SysRetentionPolicy{}).Where("id = ?", policy.ID).
+		Select("name", "table_name", "age_column", "key_column", "interval_value", "archive", "batch_size", "enabled", "remark").
+		Updates(&policy).Error
+}
+
+// GetSysRetentionPolicy 根据id获取数据保留策略
+func (s *SysRetentionService) GetSysRetentionPolicy(id uint) (policy system.SysRetentionPolicy, err error) {
+	err = global.GVA_DB.Where("id = ?", id).First(&policy).Error
+	return
+}
+
+// GetSysRetentionPolicyInfoList 分页获取数据保留策略
+func (s *SysRetentionService) GetSysRetentionPolicyInfoList(info systemReq.SysRetentionPolicySearch) (list []system.SysRetentionPolicy, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysRetentionPolicy{})
+	if info.Name != "" {
+		db = db.Where("name LIKE ?", "%"+info.Name+"%")
+	}
+	if info.Table != "" {
+		db = db.Where("table_name = ?", info.Table)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	if limit != 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	err = db.Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// GetSysRetentionRunList 分页获取数据保留执行报告
+func (s *SysRetentionService) GetSysRetentionRunList(info systemReq.SysRetentionRunSearch) (list []system.SysRetentionRun, total int64, err error) {
+	limit := info.PageSize
+	offset := info.PageSize * (info.Page - 1)
+	db := global.GVA_DB.Model(&system.SysRetentionRun{})
+	if info.PolicyID != 0 {
+		db = db.Where("policy_id = ?", info.PolicyID)
+	}
+	if info.Status != "" {
+		db = db.Where("status = ?", info.Status)
+	}
+	if info.DryRun != nil {
+		db = db.Where("dry_run = ?", *info.DryRun)
+	}
+	err = db.Count(&total).Error
+	if err != nil {
+		return
+	}
+	if limit != 0 {
+		db = db.Limit(limit).Offset(offset)
+	}
+	err = db.Order("id desc").Find(&list).Error
+	return list, total, err
+}
+
+// RunSysRetentionPolicy 执行一次数据保留策略 dryRun 时只统计将被删除的行数
+func (s *SysRetentionService) RunSysRetentionPolicy(info systemReq.RunSysRetention) (run system.SysRetentionRun, err error) {
+	policy, err := s.GetSysRetentionPolicy(info.ID)
+	if err != nil {
+		return run, err
+	}
+	return s.execute(policy, info.DryRun)
+}
+
+// RunSysRetentionPolicies 依次执行启用的数据保留策略 names 为空时执行全部 报告写入 out
+func (s *SysRetentionService) RunSysRetentionPolicies(names []string, out io.Writer) error {
+	db := global.GVA_DB.Model(&system.SysRetentionPolicy{})
+	if len(names) > 0 {
+		db = db.Where("name in ?", names)
+	}
+	var policies []system.SysRetentionPolicy
+	if err := db.Order("id").Find(&policies).Error; err != nil {
+		return err
+	}
+	var failed []string
+	for _, policy := range policies {
+		if !policy.IsEnabled() {
+			continue
+		}
+		run, err := s.execute(policy, false)
+		if err != nil {
+			failed = append(failed, policy.Name)
+			_, _ = fmt.Fprintf(out, "[%s] %s 失败: %v\n", policy.Name, policy.Table, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "[%s] %s 删除 %d 行 归档 %d 行 %s\n", policy.Name, policy.Table, run.Deleted, run.Archived, run.ArchiveUrl)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d 个策略执行失败: %s", len(failed), strings.Join(failed, ","))
+	}
+	return nil
+}
+
+// EnsureSysRetentionPolicies 将配置文件中的内置规则写入数据库 已存在(含已删除)时不再创建 保留用户的修改
+func (s *SysRetentionService) EnsureSysRetentionPolicies() error {
+	for _, rule := range global.GVA_CONFIG.Retention.Rules {
+		var count int64
+		err := global.GVA_DB.Unscoped().Model(&system.SysRetentionPolicy{}).
+			Where("table_name = ? AND age_column = ?", rule.Table, rule.Column).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		policy := system.SysRetentionPolicy{
+			Name:      "清理" + rule.Table,
+			Table:     rule.Table,
+			AgeColumn: rule.Column,
+			KeyColumn: "id",
+			Interval:  rule.Interval,
+			Archive:   rule.Archive,
+			Remark:    "由配置文件初始化",
+		}
+		if err = s.check(policy); err != nil {
+			global.GVA_LOG.Error("数据保留规则无效!", zap.String("table", rule.Table), zap.Error(err))
+			continue
+		}
+		if err = global.GVA_DB.Create(&policy).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRetentionInterval 解析保留时长 支持 time.ParseDuration 的格式与 d(天)
+func parseRetentionInterval(interval string) (time.Duration, error) {
+	interval = strings.TrimSpace(interval)
+	if days, ok := strings.CutSuffix(interval, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("保留时长格式错误: %s", interval)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("保留时长格式错误: %s", interval)
+	}
+	return d, nil
+}
+
+func (s *SysRetentionService) check(policy system.SysRetentionPolicy) error {
+	if !retentionIdentifier.MatchString(policy.Table) {
+		return errors.New("表名不合法")
+	}
+	if retentionTableProtected(policy.Table) {
+		return fmt.Errorf("不允许为 %s 配置保留策略", policy.Table)
+	}
+	ageColumn, keyColumn := s.columns(policy)
+	if !retentionIdentifier.MatchString(ageColumn) || !retentionIdentifier.MatchString(keyColumn) {
+		return errors.New("字段名不合法")
+	}
+	d, err := parseRetentionInterval(policy.Interval)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return errors.New("保留时长必须大于0")
+	}
+	switch policy.Archive {
+	case system.RetentionArchiveNone, system.RetentionArchiveFile, system.RetentionArchiveOss:
+	default:
+		return fmt.Errorf("不支持的归档方式: %s", policy.Archive)
+	}
+	if policy.BatchSize < 0 {
+		return errors.New("每批删除行数不能为负数")
+	}
+	// 插件或代码生成的表可能在策略创建后才迁移 此处只在表已存在时校验字段
+	migrator := global.GVA_DB.Migrator()
+	if migrator.HasTable(policy.Table) {
+		for _, column := range []string{ageColumn, keyColumn} {
+			if !migrator.HasColumn(policy.Table, column) {
+				return fmt.Errorf("表 %s 不存在字段 %s", policy.Table, column)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SysRetentionService) columns(policy system.SysRetentionPolicy) (ageColumn, keyColumn string) {
+	ageColumn, keyColumn = policy.AgeColumn, policy.KeyColumn
+	if ageColumn == "" {
+		ageColumn = "created_at"
+	}
+	if keyColumn == "" {
+		keyColumn = "id"
+	}
+	return
+}
+
+func (s *SysRetentionService) batchSize(policy system.SysRetentionPolicy) int {
+	if policy.BatchSize > 0 {
+		return policy.BatchSize
+	}
+	if n := global.GVA_CONFIG.Retention.BatchSize; n > 0 {
+		return n
+	}
+	return defaultRetentionBatchSize
+}
+
+// execute 统计、归档并分批删除 结果记录为执行报告
+func (s *SysRetentionService) execute(policy system.SysRetentionPolicy, dryRun bool) (run system.SysRetentionRun, err error) {
+	if err = s.check(policy); err != nil {
+		return run, err
+	}
+	if _, loaded := retentionRunning.LoadOrStore(policy.ID, struct{}{}); loaded {
+		return run, errors.New("该策略正在执行中")
+	}
+	defer retentionRunning.Delete(policy.ID)
+
+	interval, _ := parseRetentionInterval(policy.Interval)
+	run = system.SysRetentionRun{
+		PolicyID:  policy.ID,
+		Table:     policy.Table,
+		DryRun:    dryRun,
+		Cutoff:    time.Now().Add(-interval),
+		Status:    system.RetentionRunRunning,
+		StartedAt: time.Now(),
+	}
+	if err = global.GVA_DB.Create(&run).Error; err != nil {
+		return run, err
+	}
+
+	err = s.purge(policy, &run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = system.RetentionRunSuccess
+	if err != nil {
+		run.Status = system.RetentionRunFailed
+		run.Error = err.Error()
+		global.GVA_LOG.Error("数据保留策略执行失败!", zap.Uint("id", policy.ID), zap.String("table", policy.Table), zap.Error(err))
+	}
+	if dbErr := global.GVA_DB.Save(&run).Error; dbErr != nil {
+		global.GVA_LOG.Error("保存数据保留执行报告失败!", zap.Error(dbErr))
+	}
+	if !dryRun {
+		global.GVA_DB.Model(&system.SysRetentionPolicy{}).Where("id = ?", policy.ID).UpdateColumn("last_run_at", run.StartedAt)
+	}
+	return run, err
+}
+
+func (s *SysRetentionService) purge(policy system.SysRetentionPolicy, run *system.SysRetentionRun) (err error) {
+	ageColumn, keyColumn := s.columns(policy)
+	table, age, key := clause.Table{Name: policy.Table}, clause.Column{Name: ageColumn}, clause.Column{Name: keyColumn}
+	expired := func() *gorm.DB {
+		return global.GVA_DB.Table(policy.Table).Where("? < ?", age, run.Cutoff)
+	}
+	if err = expired().Count(&run.Matched).Error; err != nil {
+		return err
+	}
+	if run.DryRun || run.Matched == 0 {
+		return nil
+	}
+
+	var archive *retentionArchive
+	if policy.Archive != system.RetentionArchiveNone {
+		if archive, err = newRetentionArchive(policy.Table, run.ID); err != nil {
+			return err
+		}
+		defer func() {
+			url, aErr := archive.close(policy.Archive)
+			run.ArchiveUrl = url
+			if err == nil {
+				err = aErr
+			}
+		}()
+	}
+
+	size := s.batchSize(policy)
+	for {
+		var ids []interface{}
+		if err = expired().Order(clause.OrderByColumn{Column: key}).Limit(size).Pluck(keyColumn, &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if archive != nil {
+			var rows []map[string]interface{}
+			if err = global.GVA_DB.Table(policy.Table).Where("? IN ?", key, ids).Find(&rows).Error; err != nil {
+				return err
+			}
+			// 先归档再删除 归档失败时不删除
+			if err = archive.write(rows); err != nil {
+				return err
+			}
+			run.Archived += int64(len(rows))
+		}
+		result := global.GVA_DB.Exec("DELETE FROM ? WHERE ? IN ?", table, key, ids)
+		if result.Error != nil {
+			return result.Error
+		}
+		run.Deleted += result.RowsAffected
+		global.GVA_DB.Model(run).UpdateColumns(map[string]interface{}{"deleted": run.Deleted, "archived": run.Archived})
+		if len(ids) < size {
+			return nil
+		}
+	}
+}
+
+// retentionArchive 以 gzip 压缩的 JSON Lines 格式归档被删除的行
+type retentionArchive struct {
+	path string
+	file *os.File
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+}
+
+func newRetentionArchive(table string, runID uint) (*retentionArchive, error) {
+	dir := global.GVA_CONFIG.Retention.ArchiveDir
+	if dir == "" {
+		dir = "./retentionArchive/"
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%s_%d_%s.jsonl.gz", strings.ReplaceAll(table, ".", "_"), runID, time.Now().Format("20060102150405"))
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(file)
+	return &retentionArchive{path: path, file: file, gz: gz, buf: bufio.NewWriter(gz)}, nil
+}
+
+func (a *retentionArchive) write(rows []map[string]interface{}) error {
+	enc := json.NewEncoder(a.buf)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	// 每批落盘 保证已删除的数据都已归档
+	if err := a.buf.Flush(); err != nil {
+		return err
+	}
+	if err := a.gz.Flush(); err != nil {
+		return err
+	}
+	return a.file.Sync()
+}
+
+// close 结束归档 归档到对象存储时上传后删除本地文件 上传失败时保留本地文件
+func (a *retentionArchive) close(archive string) (string, error) {
+	err := a.buf.Flush()
+	if cErr := a.gz.Close(); err == nil {
+		err = cErr
+	}
+	if cErr := a.file.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil || archive != system.RetentionArchiveOss {
+		return a.path, err
+	}
+	content, err := os.ReadFile(a.path)
+	if err != nil {
+		return a.path, err
+	}
+	fh, err := upload.NewFileHeader(filepath.Base(a.path), content)
+	if err != nil {
+		return a.path, err
+	}
+	url, _, err := upload.NewOss().UploadFile(fh)
+	if err != nil {
+		return a.path, fmt.Errorf("归档上传失败 已保留本地文件: %w", err)
+	}
+	_ = os.Remove(a.path)
+	return url, nil
+}
@@ -0,0 +1,156 @@
+package system
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+)
+
+// retentionTestLog 数据保留测试表
+type retentionTestLog struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Msg       string
+}
+
+func (retentionTestLog) TableName() string {
+	return "retention_test_logs"
+}
+
+// openRetentionTestDB 写入 expired 条过期数据与 fresh 条未过期数据
+func openRetentionTestDB(t *testing.T, expired, fresh int) {
+	testdb.Open(t, "", &system.SysRetentionPolicy{}, &system.SysRetentionRun{}, &retentionTestLog{})
+	retention := global.GVA_CONFIG.Retention
+	t.Cleanup(func() { global.GVA_CONFIG.Retention = retention })
+	var logs []retentionTestLog
+	for i := 0; i < expired; i++ {
+		logs = append(logs, retentionTestLog{CreatedAt: time.Now().Add(-48 * time.Hour), Msg: "expired"})
+	}
+	for i := 0; i < fresh; i++ {
+		logs = append(logs, retentionTestLog{CreatedAt: time.Now(), Msg: "fresh"})
+	}
+	if len(logs) == 0 {
+		return
+	}
+	if err := global.GVA_DB.Create(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_parseRetentionInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{interval: "90d", want: 90 * 24 * time.Hour},
+		{interval: " 7d ", want: 7 * 24 * time.Hour},
+		{interval: "2160h", want: 2160 * time.Hour},
+		{interval: "1h30m", want: 90 * time.Minute},
+		{interval: "d", wantErr: true},
+		{interval: "1.5d", wantErr: true},
+		{interval: "90", wantErr: true},
+		{interval: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseRetentionInterval(tt.interval)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseRetentionInterval(%q) = %v, %v", tt.interval, got, err)
+		}
+	}
+}
+
+func TestSysRetentionService_checkProtected(t *testing.T) {
+	openRetentionTestDB(t, 0, 0)
+	for _, table := range []string{"sys_users", "mydb.sys_users", "MYDB.SYS_USERS", "sys_job_runs", "sys_migrations", "sys_db_connections", "sys_retention_runs", "sys_retention_other"} {
+		if err := SysRetentionServiceApp.check(system.SysRetentionPolicy{Table: table, Interval: "1d"}); err == nil {
+			t.Errorf("policy for %s should be rejected", table)
+		}
+	}
+	for _, table := range []string{"sys_operation_records", "mydb.retention_test_logs"} {
+		if err := SysRetentionServiceApp.check(system.SysRetentionPolicy{Table: table, Interval: "1d"}); err != nil {
+			t.Errorf("policy for %s should be allowed: %v", table, err)
+		}
+	}
+}
+
+func TestSysRetentionService_executeBatches(t *testing.T) {
+	openRetentionTestDB(t, 25, 5)
+	policy := system.SysRetentionPolicy{Name: "logs", Table: "retention_test_logs", Interval: "1d", BatchSize: 10}
+	if err := SysRetentionServiceApp.CreateSysRetentionPolicy(&policy); err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := SysRetentionServiceApp.execute(policy, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Matched != 25 || run.Deleted != 0 {
+		t.Fatalf("dry run should only count: %+v", run)
+	}
+
+	run, err = SysRetentionServiceApp.execute(policy, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Matched != 25 || run.Deleted != 25 || run.Status != system.RetentionRunSuccess {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	var remain int64
+	global.GVA_DB.Model(&retentionTestLog{}).Count(&remain)
+	if remain != 5 {
+		t.Fatalf("fresh rows should be kept, got %d rows", remain)
+	}
+	var saved system.SysRetentionRun
+	if err = global.GVA_DB.First(&saved, run.ID).Error; err != nil || saved.Deleted != 25 || saved.FinishedAt == nil {
+		t.Fatalf("run report should be saved: %+v %v", saved, err)
+	}
+}
+
+func TestSysRetentionService_executeArchive(t *testing.T) {
+	openRetentionTestDB(t, 12, 3)
+	global.GVA_CONFIG.Retention.ArchiveDir = t.TempDir()
+	policy := system.SysRetentionPolicy{Name: "logs", Table: "retention_test_logs", Interval: "1d", BatchSize: 5, Archive: system.RetentionArchiveFile}
+	if err := SysRetentionServiceApp.CreateSysRetentionPolicy(&policy); err != nil {
+		t.Fatal(err)
+	}
+	run, err := SysRetentionServiceApp.execute(policy, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Archived != 12 || run.Deleted != 12 || run.ArchiveUrl == "" {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+
+	file, err := os.Open(run.ArchiveUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(gz)
+	lines := 0
+	for scanner.Scan() {
+		var row map[string]interface{}
+		if err = json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatal(err)
+		}
+		if row["msg"] != "expired" {
+			t.Fatalf("only expired rows should be archived: %v", row)
+		}
+		lines++
+	}
+	if lines != 12 {
+		t.Fatalf("archive should contain every deleted row, got %d", lines)
+	}
+}
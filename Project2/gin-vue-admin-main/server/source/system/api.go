@@ -97,8 +97,35 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/deleteFile", Description: "删除文件"},
 		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/editFileName", Description: "文件名或者备注编辑"},
 		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/getFileList", Description: "获取上传文件列表"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/searchFile", Description: "媒体库搜索文件"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/moveFiles", Description: "批量移动文件到文件夹"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/createFolder", Description: "创建文件夹"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/updateFolder", Description: "重命名或移动文件夹"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/deleteFolder", Description: "删除空文件夹"},
+		{ApiGroup: "文件上传与下载", Method: "GET", Path: "/fileUploadAndDownload/getFolderTree", Description: "获取文件夹树"},
+		{ApiGroup: "文件上传与下载", Method: "GET", Path: "/fileUploadAndDownload/getUsage", Description: "获取存储用量与配额"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/setQuota", Description: "设置存储配额"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/deleteQuota", Description: "删除存储配额"},
+		{ApiGroup: "文件上传与下载", Method: "GET", Path: "/fileUploadAndDownload/getQuotaList", Description: "获取存储配额列表"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/presignUpload", Description: "申请直传地址"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/presignParts", Description: "获取分片直传地址"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/presignComplete", Description: "直传完成回调"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/presignAbort", Description: "取消直传"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/presignDownload", Description: "获取签名下载地址"},
+		{ApiGroup: "文件上传与下载", Method: "POST", Path: "/fileUploadAndDownload/tus", Description: "tus创建上传"},
+		{ApiGroup: "文件上传与下载", Method: "HEAD", Path: "/fileUploadAndDownload/tus/:id", Description: "tus查询上传进度"},
+		{ApiGroup: "文件上传与下载", Method: "PATCH", Path: "/fileUploadAndDownload/tus/:id", Description: "tus追加上传数据"},
+		{ApiGroup: "文件上传与下载", Method: "DELETE", Path: "/fileUploadAndDownload/tus/:id", Description: "tus取消上传"},
+		{ApiGroup: "存储迁移", Method: "POST", Path: "/ossMigration/createOssMigration", Description: "创建存储迁移任务"},
+		{ApiGroup: "存储迁移", Method: "POST", Path: "/ossMigration/resumeOssMigration", Description: "继续存储迁移任务"},
+		{ApiGroup: "存储迁移", Method: "POST", Path: "/ossMigration/stopOssMigration", Description: "停止存储迁移任务"},
+		{ApiGroup: "存储迁移", Method: "POST", Path: "/ossMigration/applyOssMigration", Description: "应用存储迁移"},
+		{ApiGroup: "存储迁移", Method: "DELETE", Path: "/ossMigration/deleteOssMigration", Description: "删除存储迁移任务"},
+		{ApiGroup: "存储迁移", Method: "GET", Path: "/ossMigration/getOssMigrationList", Description: "获取存储迁移任务列表"},
+		{ApiGroup: "存储迁移", Method: "GET", Path: "/ossMigration/getOssMigrationItemList", Description: "获取迁移对象列表"},
 
 		{ApiGroup: "系统服务", Method: "POST", Path: "/system/getServerInfo", Description: "获取服务器信息"},
+		{ApiGroup: "系统服务", Method: "GET", Path: "/system/getMetrics", Description: "获取运行指标"},
 		{ApiGroup: "系统服务", Method: "POST", Path: "/system/getSystemConfig", Description: "获取配置文件内容"},
 		{ApiGroup: "系统服务", Method: "POST", Path: "/system/setSystemConfig", Description: "设置配置文件内容"},
 
@@ -112,20 +139,34 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getTables", Description: "获取数据库表"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/createTemp", Description: "自动化代码"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/preview", Description: "预览自动化代码"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/dryRun", Description: "试运行代码生成"},
 		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getColumn", Description: "获取所选table的所有字段"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/inspectTables", Description: "检查数据库推断批量导入信息"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/importTables", Description: "批量导入数据库表生成代码"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/installPlugin", Description: "安装插件"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/uninstallPlugin", Description: "卸载插件"},
+		{ApiGroup: "代码生成器", Method: "GET", Path: "/autoCode/getInstalledPlugins", Description: "获取已安装插件"},
 		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/pubPlug", Description: "打包插件"},
 
 		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/createPackage", Description: "配置模板"},
 		{ApiGroup: "模板配置", Method: "GET", Path: "/autoCode/getTemplates", Description: "获取模板文件"},
 		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/getPackage", Description: "获取所有模板"},
 		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/delPackage", Description: "删除模板"},
+		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/setPackagePack", Description: "设置包的默认模板包"},
+		{ApiGroup: "模板配置", Method: "GET", Path: "/autoCode/getPacks", Description: "获取已安装的模板包"},
+		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/installPack", Description: "安装模板包"},
+		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/delPack", Description: "删除模板包"},
+		{ApiGroup: "模板配置", Method: "POST", Path: "/autoCode/getPackFiles", Description: "预览模板包将生成的文件"},
 
 		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/getMeta", Description: "获取meta信息"},
 		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/rollback", Description: "回滚自动生成代码"},
 		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/getSysHistory", Description: "查询回滚记录"},
 		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/delSysHistory", Description: "删除回滚记录"},
 		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/addFunc", Description: "增加模板方法"},
+		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/getMigrationList", Description: "查询数据库迁移"},
+		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/applyMigration", Description: "执行数据库迁移"},
+		{ApiGroup: "代码生成器历史", Method: "POST", Path: "/autoCode/revertMigration", Description: "回退数据库迁移"},
+		{ApiGroup: "代码生成器", Method: "POST", Path: "/autoCode/regenerate", Description: "重新生成自动化代码"},
 
 		{ApiGroup: "系统字典详情", Method: "PUT", Path: "/sysDictionaryDetail/updateSysDictionaryDetail", Description: "更新字典内容"},
 		{ApiGroup: "系统字典详情", Method: "POST", Path: "/sysDictionaryDetail/createSysDictionaryDetail", Description: "新增字典内容"},
@@ -166,6 +207,48 @@ func (i *initApi) InitializeData(ctx context.Context) (context.Context, error) {
 		{ApiGroup: "表格模板", Method: "GET", Path: "/sysExportTemplate/exportTemplate", Description: "下载模板"},
 		{ApiGroup: "表格模板", Method: "POST", Path: "/sysExportTemplate/importExcel", Description: "导入Excel"},
 
+		{ApiGroup: "定时报表", Method: "POST", Path: "/sysExportSchedule/createSysExportSchedule", Description: "新增定时报表"},
+		{ApiGroup: "定时报表", Method: "DELETE", Path: "/sysExportSchedule/deleteSysExportSchedule", Description: "删除定时报表"},
+		{ApiGroup: "定时报表", Method: "DELETE", Path: "/sysExportSchedule/deleteSysExportScheduleByIds", Description: "批量删除定时报表"},
+		{ApiGroup: "定时报表", Method: "PUT", Path: "/sysExportSchedule/updateSysExportSchedule", Description: "更新定时报表"},
+		{ApiGroup: "定时报表", Method: "POST", Path: "/sysExportSchedule/runSysExportSchedule", Description: "立即执行定时报表"},
+		{ApiGroup: "定时报表", Method: "GET", Path: "/sysExportSchedule/findSysExportSchedule", Description: "根据ID获取定时报表"},
+		{ApiGroup: "定时报表", Method: "GET", Path: "/sysExportSchedule/getSysExportScheduleList", Description: "获取定时报表列表"},
+		{ApiGroup: "定时报表", Method: "GET", Path: "/sysExportSchedule/getSysExportScheduleRunList", Description: "获取定时报表执行记录"},
+		{ApiGroup: "定时报表", Method: "GET", Path: "/sysExportSchedule/downloadSysExportScheduleRun", Description: "下载定时报表产出文件"},
+
+		{ApiGroup: "定时任务", Method: "POST", Path: "/sysJob/createSysJob", Description: "新增定时任务"},
+		{ApiGroup: "定时任务", Method: "DELETE", Path: "/sysJob/deleteSysJob", Description: "删除定时任务"},
+		{ApiGroup: "定时任务", Method: "DELETE", Path: "/sysJob/deleteSysJobByIds", Description: "批量删除定时任务"},
+		{ApiGroup: "定时任务", Method: "PUT", Path: "/sysJob/updateSysJob", Description: "更新定时任务"},
+		{ApiGroup: "定时任务", Method: "POST", Path: "/sysJob/runSysJob", Description: "立即执行定时任务"},
+		{ApiGroup: "定时任务", Method: "GET", Path: "/sysJob/findSysJob", Description: "根据ID获取定时任务"},
+		{ApiGroup: "定时任务", Method: "GET", Path: "/sysJob/getSysJobList", Description: "获取定时任务列表"},
+		{ApiGroup: "定时任务", Method: "GET", Path: "/sysJob/getSysJobRunList", Description: "获取定时任务执行记录"},
+		{ApiGroup: "定时任务", Method: "GET", Path: "/sysJob/getSysJobHandlers", Description: "获取定时任务处理函数"},
+
+		{ApiGroup: "数据保留", Method: "POST", Path: "/sysRetention/createSysRetentionPolicy", Description: "新增数据保留策略"},
+		{ApiGroup: "数据保留", Method: "DELETE", Path: "/sysRetention/deleteSysRetentionPolicy", Description: "删除数据保留策略"},
+		{ApiGroup: "数据保留", Method: "DELETE", Path: "/sysRetention/deleteSysRetentionPolicyByIds", Description: "批量删除数据保留策略"},
+		{ApiGroup: "数据保留", Method: "PUT", Path: "/sysRetention/updateSysRetentionPolicy", Description: "更新数据保留策略"},
+		{ApiGroup: "数据保留", Method: "POST", Path: "/sysRetention/runSysRetentionPolicy", Description: "执行数据保留策略"},
+		{ApiGroup: "数据保留", Method: "GET", Path: "/sysRetention/findSysRetentionPolicy", Description: "根据ID获取数据保留策略"},
+		{ApiGroup: "数据保留", Method: "GET", Path: "/sysRetention/getSysRetentionPolicyList", Description: "获取数据保留策略列表"},
+		{ApiGroup: "数据保留", Method: "GET", Path: "/sysRetention/getSysRetentionRunList", Description: "获取数据保留执行报告"},
+
+		{ApiGroup: "OpenAPI", Method: "GET", Path: "/openapi/getSpec", Description: "获取OpenAPI文档"},
+
+		{ApiGroup: "插件运行状态", Method: "GET", Path: "/plugin/getPluginStatus", Description: "获取插件运行状态"},
+		{ApiGroup: "插件运行状态", Method: "POST", Path: "/plugin/setPluginEnabled", Description: "启用或停用插件"},
+		{ApiGroup: "插件运行状态", Method: "PUT", Path: "/plugin/setPluginConfig", Description: "修改插件配置"},
+
+		{ApiGroup: "业务库", Method: "POST", Path: "/dbConnection/createDBConnection", Description: "新增业务库"},
+		{ApiGroup: "业务库", Method: "PUT", Path: "/dbConnection/updateDBConnection", Description: "更新业务库"},
+		{ApiGroup: "业务库", Method: "DELETE", Path: "/dbConnection/deleteDBConnection", Description: "删除业务库"},
+		{ApiGroup: "业务库", Method: "POST", Path: "/dbConnection/testDBConnection", Description: "测试业务库连接"},
+		{ApiGroup: "业务库", Method: "GET", Path: "/dbConnection/findDBConnection", Description: "根据ID获取业务库"},
+		{ApiGroup: "业务库", Method: "GET", Path: "/dbConnection/getDBConnectionList", Description: "获取业务库列表"},
+
 		{ApiGroup: "公告", Method: "POST", Path: "/info/createInfo", Description: "新建公告"},
 		{ApiGroup: "公告", Method: "DELETE", Path: "/info/deleteInfo", Description: "删除公告"},
 		{ApiGroup: "公告", Method: "DELETE", Path: "/info/deleteInfoByIds", Description: "批量删除公告"},
@@ -95,6 +95,32 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/deleteFile", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/editFileName", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/getFileList", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/searchFile", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/moveFiles", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/createFolder", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/updateFolder", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/deleteFolder", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/getFolderTree", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/getUsage", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/setQuota", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/deleteQuota", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/getQuotaList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/presignUpload", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/presignParts", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/presignComplete", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/presignAbort", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/presignDownload", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/tus", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/tus/:id", V2: "HEAD"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/tus/:id", V2: "PATCH"},
+		{Ptype: "p", V0: "888", V1: "/fileUploadAndDownload/tus/:id", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/ossMigration/createOssMigration", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/ossMigration/resumeOssMigration", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/ossMigration/stopOssMigration", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/ossMigration/applyOssMigration", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/ossMigration/deleteOssMigration", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/ossMigration/getOssMigrationList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/ossMigration/getOssMigrationItemList", V2: "GET"},
 
 		{Ptype: "p", V0: "888", V1: "/casbin/updateCasbin", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/casbin/getPolicyPathByAuthorityId", V2: "POST"},
@@ -104,6 +130,7 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/system/getSystemConfig", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/system/setSystemConfig", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/system/getServerInfo", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/system/getMetrics", V2: "GET"},
 
 		{Ptype: "p", V0: "888", V1: "/customer/customer", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/customer/customer", V2: "PUT"},
@@ -114,8 +141,11 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/autoCode/getDB", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/getMeta", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/preview", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/dryRun", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/getTables", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/getColumn", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/inspectTables", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/importTables", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/rollback", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/createTemp", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/delSysHistory", V2: "POST"},
@@ -124,10 +154,21 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/autoCode/getTemplates", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/getPackage", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/delPackage", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/setPackagePack", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/getPacks", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/installPack", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/delPack", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/getPackFiles", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/createPlug", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/installPlugin", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/uninstallPlugin", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/getInstalledPlugins", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/pubPlug", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/autoCode/addFunc", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/regenerate", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/getMigrationList", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/applyMigration", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/autoCode/revertMigration", V2: "POST"},
 
 		{Ptype: "p", V0: "888", V1: "/sysDictionaryDetail/findSysDictionaryDetail", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/sysDictionaryDetail/updateSysDictionaryDetail", V2: "PUT"},
@@ -169,6 +210,46 @@ func (i *initCasbin) InitializeData(ctx context.Context) (context.Context, error
 		{Ptype: "p", V0: "888", V1: "/sysExportTemplate/exportTemplate", V2: "GET"},
 		{Ptype: "p", V0: "888", V1: "/sysExportTemplate/importExcel", V2: "POST"},
 
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/createSysExportSchedule", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/deleteSysExportSchedule", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/deleteSysExportScheduleByIds", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/updateSysExportSchedule", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/runSysExportSchedule", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/findSysExportSchedule", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/getSysExportScheduleList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/getSysExportScheduleRunList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysExportSchedule/downloadSysExportScheduleRun", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/createSysJob", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/deleteSysJob", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/deleteSysJobByIds", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/updateSysJob", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/runSysJob", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/findSysJob", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/getSysJobList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/getSysJobRunList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysJob/getSysJobHandlers", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/createSysRetentionPolicy", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/deleteSysRetentionPolicy", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/deleteSysRetentionPolicyByIds", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/updateSysRetentionPolicy", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/runSysRetentionPolicy", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/findSysRetentionPolicy", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/getSysRetentionPolicyList", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/sysRetention/getSysRetentionRunList", V2: "GET"},
+
+		{Ptype: "p", V0: "888", V1: "/openapi/getSpec", V2: "GET"},
+
+		{Ptype: "p", V0: "888", V1: "/plugin/getPluginStatus", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/plugin/setPluginEnabled", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/plugin/setPluginConfig", V2: "PUT"},
+
+		{Ptype: "p", V0: "888", V1: "/dbConnection/createDBConnection", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dbConnection/updateDBConnection", V2: "PUT"},
+		{Ptype: "p", V0: "888", V1: "/dbConnection/deleteDBConnection", V2: "DELETE"},
+		{Ptype: "p", V0: "888", V1: "/dbConnection/testDBConnection", V2: "POST"},
+		{Ptype: "p", V0: "888", V1: "/dbConnection/findDBConnection", V2: "GET"},
+		{Ptype: "p", V0: "888", V1: "/dbConnection/getDBConnectionList", V2: "GET"},
+
 		{Ptype: "p", V0: "888", V1: "/info/createInfo", V2: "POST"},
 		{Ptype: "p", V0: "888", V1: "/info/deleteInfo", V2: "DELETE"},
 		{Ptype: "p", V0: "888", V1: "/info/deleteInfoByIds", V2: "DELETE"},
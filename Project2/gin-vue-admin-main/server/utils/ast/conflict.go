@@ -0,0 +1,193 @@
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// Conflicts 检查文件中会导致编译失败或重复注册的内容
+// 导入名冲突、重复导入、重复的顶层声明、结构体重复字段、同一代码块中重复定义的变量与重复的调用语句
+func Conflicts(file *ast.File) []string {
+	var conflicts []string
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := importName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		if exist, ok := imports[name]; ok {
+			if exist == importPath {
+				conflicts = append(conflicts, fmt.Sprintf("重复导入[%s]", importPath))
+			} else {
+				conflicts = append(conflicts, fmt.Sprintf("导入名[%s]冲突: %s, %s", name, exist, importPath))
+			}
+			continue
+		}
+		imports[name] = importPath
+	}
+
+	declared := make(map[string]bool)
+	declare := func(name string) {
+		if name == "_" || name == "init" {
+			return
+		}
+		if declared[name] {
+			conflicts = append(conflicts, fmt.Sprintf("重复声明[%s]", name))
+		}
+		declared[name] = true
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				declare(exprString(d.Recv.List[0].Type) + "." + d.Name.Name)
+				continue
+			}
+			declare(d.Name.Name)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					declare(s.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						declare(name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.TypeSpec:
+			if s, ok := n.Type.(*ast.StructType); ok {
+				conflicts = append(conflicts, structConflicts(n.Name.Name, s)...)
+			}
+		case *ast.BlockStmt:
+			conflicts = append(conflicts, blockConflicts(n)...)
+		}
+		return true
+	})
+	return conflicts
+}
+
+// newConflicts 注入后新增的冲突
+func newConflicts(filename, before, after string) []string {
+	parse := func(src string) []string {
+		file, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.ParseComments)
+		if err != nil {
+			return []string{"注入后的代码无法解析: " + err.Error()}
+		}
+		return Conflicts(file)
+	}
+	exist := make(map[string]int)
+	for _, conflict := range parse(before) {
+		exist[conflict]++
+	}
+	var conflicts []string
+	for _, conflict := range parse(after) {
+		if exist[conflict] > 0 {
+			exist[conflict]--
+			continue
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+func structConflicts(name string, s *ast.StructType) []string {
+	var conflicts []string
+	fields := make(map[string]bool)
+	for _, field := range s.Fields.List {
+		names := make([]string, 0, len(field.Names))
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+		}
+		if len(names) == 0 {
+			typ := field.Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			switch t := typ.(type) {
+			case *ast.Ident:
+				names = append(names, t.Name)
+			case *ast.SelectorExpr:
+				names = append(names, t.Sel.Name)
+			}
+		}
+		for _, field := range names {
+			if fields[field] {
+				conflicts = append(conflicts, fmt.Sprintf("结构体[%s]字段[%s]重复", name, field))
+			}
+			fields[field] = true
+		}
+	}
+	return conflicts
+}
+
+func blockConflicts(block *ast.BlockStmt) []string {
+	var conflicts []string
+	defined := make(map[string]bool)
+	calls := make(map[string]bool)
+	for _, stmt := range block.List {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE {
+				continue
+			}
+			hasNew := false
+			var names []string
+			for _, lhs := range s.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+					names = append(names, ident.Name)
+					if !defined[ident.Name] {
+						hasNew = true
+					}
+					defined[ident.Name] = true
+				}
+			}
+			if !hasNew && len(names) > 0 {
+				conflicts = append(conflicts, fmt.Sprintf("变量[%s]重复定义", strings.Join(names, ",")))
+			}
+		case *ast.ExprStmt:
+			if _, ok := s.X.(*ast.CallExpr); !ok {
+				continue
+			}
+			call := exprString(s.X)
+			if calls[call] {
+				conflicts = append(conflicts, fmt.Sprintf("重复调用[%s]", call))
+			}
+			calls[call] = true
+		}
+	}
+	return conflicts
+}
+
+// importName 未指定别名时的包名 按导入路径的最后一段推断, 主版本后缀取上一段
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersion.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(name, "go-"), "-", "_")
+}
+
+func exprString(expr ast.Expr) string {
+	var builder strings.Builder
+	_ = printer.Fprint(&builder, token.NewFileSet(), expr)
+	return builder.String()
+}
@@ -0,0 +1,90 @@
+package ast
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// TestInjection_Idempotent 重复注入不改变文件内容 输出写入内存不修改磁盘文件
+func TestInjection_Idempotent(t *testing.T) {
+	server := filepath.Join(global.GVA_CONFIG.AutoCode.Root, global.GVA_CONFIG.AutoCode.Server)
+	tests := []struct {
+		name string
+		ast  Ast
+		path string
+		want string
+	}{
+		{
+			name: "测试 example.ExaIdempotent{} 重复注入",
+			ast: &PackageInitializeGorm{
+				Type:        TypePackageInitializeGorm,
+				ImportPath:  `"github.com/flipped-aurora/gin-vue-admin/server/model/example"`,
+				StructName:  "ExaIdempotent",
+				PackageName: "example",
+			},
+			path: filepath.Join(server, "initialize", "gorm_biz.go"),
+			want: "example.ExaIdempotent{}",
+		},
+		{
+			name: "测试 InitIdempotentRouter 重复注入",
+			ast: &PackageInitializeRouter{
+				Type:                 TypePackageInitializeRouter,
+				ImportPath:           `"github.com/flipped-aurora/gin-vue-admin/server/router"`,
+				AppName:              "RouterGroupApp",
+				GroupName:            "Example",
+				ModuleName:           "exampleRouter",
+				PackageName:          "router",
+				FunctionName:         "InitIdempotentRouter",
+				LeftRouterGroupName:  "privateGroup",
+				RightRouterGroupName: "publicGroup",
+			},
+			path: filepath.Join(server, "initialize", "router_biz.go"),
+			want: "exampleRouter.InitIdempotentRouter(privateGroup, publicGroup)",
+		},
+		{
+			name: "测试 exaIdempotentApi 重复注入",
+			ast: &PackageModuleEnter{
+				Type:        TypePackageRouterModuleEnter,
+				ImportPath:  `api "github.com/flipped-aurora/gin-vue-admin/server/api/v1"`,
+				StructName:  "IdempotentRouter",
+				AppName:     "ApiGroupApp",
+				GroupName:   "ExampleApiGroup",
+				ModuleName:  "exaIdempotentApi",
+				PackageName: "api",
+				ServiceName: "IdempotentApi",
+			},
+			path: filepath.Join(server, "router", "example", "enter.go"),
+			want: "exaIdempotentApi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := tt.ast.Parse(tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var once, twice strings.Builder
+			if err = tt.ast.Injection(file); err != nil {
+				t.Fatal(err)
+			}
+			if err = tt.ast.Format(tt.path, &once, file); err != nil {
+				t.Fatal(err)
+			}
+			if err = tt.ast.Injection(file); err != nil {
+				t.Fatal(err)
+			}
+			if err = tt.ast.Format(tt.path, &twice, file); err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(once.String(), tt.want); n != 1 {
+				t.Fatalf("Injection() should add %q once, got %d:\n%s", tt.want, n, once.String())
+			}
+			if twice.String() != once.String() {
+				t.Errorf("second Injection() should be a no-op:\n%s", twice.String())
+			}
+		})
+	}
+}
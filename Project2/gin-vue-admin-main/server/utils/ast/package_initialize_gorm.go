@@ -118,6 +118,17 @@ func (a *PackageInitializeGorm) Injection(file *ast.File) error {
 			return true
 		}
 
+		// 已注入时不再重复添加
+		for _, arg := range callExpr.Args {
+			if lit, o := arg.(*ast.CompositeLit); o {
+				if sel, o := lit.Type.(*ast.SelectorExpr); o {
+					if x, o := sel.X.(*ast.Ident); o && x.Name == a.PackageName && sel.Sel.Name == a.StructName {
+						return true
+					}
+				}
+			}
+		}
+
 		// 添加结构体参数
 		callExpr.Args = append(callExpr.Args, &ast.CompositeLit{
 			Type: &ast.SelectorExpr{
@@ -109,6 +109,9 @@ func (a *PackageInitializeRouter) Injection(file *ast.File) error {
 			},
 		}
 	}
+	if hasRouter && a.hasCall(varBlock) {
+		return nil
+	} // 已注入时不再重复注入
 	routerStmt := CreateStmt(fmt.Sprintf("%s.%s(%s,%s)", a.ModuleName, a.FunctionName, a.LeftRouterGroupName, a.RightRouterGroupName))
 	varBlock.List = append(varBlock.List, routerStmt)
 	if !hasRouter {
@@ -148,3 +151,19 @@ func (a *PackageInitializeRouter) CreateAssignStmt() *ast.AssignStmt {
 
 	return stmt
 }
+
+// hasCall 代码块中是否已调用 ModuleName.FunctionName
+func (a *PackageInitializeRouter) hasCall(block *ast.BlockStmt) bool {
+	var has bool
+	ast.Inspect(block, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return !has
+		}
+		if x, o := sel.X.(*ast.Ident); o && x.Name == a.ModuleName && sel.Sel.Name == a.FunctionName {
+			has = true
+		}
+		return !has
+	})
+	return has
+}
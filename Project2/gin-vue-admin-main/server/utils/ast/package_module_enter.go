@@ -81,7 +81,7 @@ func (a *PackageModuleEnter) Rollback(file *ast.File) error {
 
 func (a *PackageModuleEnter) Injection(file *ast.File) error {
 	_ = NewImport(a.ImportPath).Injection(file)
-	var hasValue bool
+	hasValue := a.hasModule(file) // 已注入时不再重复注入
 	var hasVariables bool
 	for i := 0; i < len(file.Decls); i++ {
 		v1, o1 := file.Decls[i].(*ast.GenDecl)
@@ -178,3 +178,20 @@ func (a *PackageModuleEnter) Format(filename string, writer io.Writer, file *ast
 	}
 	return a.Base.Format(filename, writer, file)
 }
+
+// hasModule 文件中是否已声明 ModuleName 变量
+func (a *PackageModuleEnter) hasModule(file *ast.File) bool {
+	for i := 0; i < len(file.Decls); i++ {
+		v1, o1 := file.Decls[i].(*ast.GenDecl)
+		if !o1 || v1.Tok != token.VAR {
+			continue
+		}
+		for j := 0; j < len(v1.Specs); j++ {
+			v2, o2 := v1.Specs[j].(*ast.ValueSpec)
+			if o2 && len(v2.Names) == 1 && v2.Names[0].Name == a.ModuleName {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"io"
+	"strings"
 )
 
 type PluginInitializeV2 struct {
@@ -31,7 +32,7 @@ func (a *PluginInitializeV2) Parse(filename string, writer io.Writer) (file *ast
 }
 
 func (a *PluginInitializeV2) Injection(file *ast.File) error {
-	if !CheckImport(file, a.ImportPath) {
+	if !CheckImport(file, strings.Trim(a.ImportPath, `"`)) {
 		NewImport(a.ImportPath).Injection(file)
 		funcDecl := FindFunction(file, "bizPluginV2")
 		stmt := CreateStmt(fmt.Sprintf("PluginInitV2(engine, %s.Plugin)", a.PackageName))
@@ -40,8 +41,41 @@ func (a *PluginInitializeV2) Injection(file *ast.File) error {
 	return nil
 }
 
+// Rollback 移除 bizPluginV2 中对插件的注册 当 PluginInitV2 只剩 engine 参数时删除整条语句
 func (a *PluginInitializeV2) Rollback(file *ast.File) error {
-	return nil
+	funcDecl := FindFunction(file, "bizPluginV2")
+	if funcDecl == nil {
+		return nil
+	}
+	for i := 0; i < len(funcDecl.Body.List); i++ {
+		exprStmt, ok := funcDecl.Body.List[i].(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		callExpr, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		if ident, o := callExpr.Fun.(*ast.Ident); !o || ident.Name != "PluginInitV2" {
+			continue
+		}
+		args := make([]ast.Expr, 0, len(callExpr.Args))
+		for _, arg := range callExpr.Args {
+			selExpr, o := arg.(*ast.SelectorExpr)
+			if o {
+				if x, o := selExpr.X.(*ast.Ident); o && x.Name == a.PackageName && selExpr.Sel.Name == "Plugin" {
+					continue
+				}
+			}
+			args = append(args, arg)
+		}
+		callExpr.Args = args
+		if len(args) <= 1 {
+			funcDecl.Body.List = append(funcDecl.Body.List[:i], funcDecl.Body.List[i+1:]...)
+			i--
+		}
+	}
+	return NewImport(a.ImportPath).Rollback(file)
 }
 
 func (a *PluginInitializeV2) Format(filename string, writer io.Writer, file *ast.File) error {
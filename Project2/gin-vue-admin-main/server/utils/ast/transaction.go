@@ -0,0 +1,385 @@
+package ast
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	ChangeCreated   = "created"   // 新建文件
+	ChangeModified  = "modified"  // 修改文件
+	ChangeUnchanged = "unchanged" // 内容未变化 如已注入
+)
+
+// Change 事务中单个文件的变更
+type Change struct {
+	Path      string   `json:"path"`      // 文件路径
+	Status    string   `json:"status"`    // created modified unchanged
+	Diff      string   `json:"diff"`      // unified diff
+	Conflicts []string `json:"conflicts"` // 冲突 存在冲突时事务不允许提交
+	Warnings  []string `json:"warnings"`  // 警告 如已注入、文件生成后被修改
+}
+
+// Transaction 多文件事务 注入、回滚与生成的文件先在内存中暂存, Commit 时统一写入
+// 任一文件写入失败时恢复事务中已写入的全部文件
+type Transaction struct {
+	files map[string]*txFile
+	order []string
+	root  string
+	// Force 为 true 时忽略冲突提交
+	Force bool
+}
+
+type txFile struct {
+	original []byte // 事务开始前的内容
+	exists   bool   // 事务开始前文件是否存在
+	content  []byte // 当前内容
+	expected string // 生成时记录的文件摘要 为空时不校验
+	staged   bool   // 是否已暂存内容 仅暂存过的文件参与变更与提交
+	change   Change
+}
+
+// NewTransaction root 为项目根目录 变更与摘要中的路径相对于 root
+func NewTransaction(root string) *Transaction {
+	return &Transaction{files: make(map[string]*txFile), root: root}
+}
+
+// Hash 文件内容摘要 用于判断生成后是否被手工修改
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Filename 注入信息对应的文件 与各注入信息的 Parse 保持一致: RelativePath 为空时使用 Path 并补全 RelativePath, 否则由 RelativePath 得到 Path
+func Filename(a Ast) string {
+	value := reflect.Indirect(reflect.ValueOf(a))
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+	path := value.FieldByName("Path")
+	if _, ok := a.(*PluginInitializeV2); ok {
+		path = value.FieldByName("PluginPath")
+	}
+	relativePath := value.FieldByName("RelativePath")
+	if !path.IsValid() || path.Kind() != reflect.String {
+		return ""
+	}
+	if !relativePath.IsValid() || relativePath.Kind() != reflect.String {
+		return path.String()
+	}
+	var base Base
+	if relativePath.String() == "" {
+		if relativePath.CanSet() {
+			relativePath.SetString(base.RelativePath(path.String()))
+		}
+		return path.String()
+	}
+	filename := base.AbsolutePath(relativePath.String())
+	if path.CanSet() {
+		path.SetString(filename)
+	}
+	return filename
+}
+
+// load 读取文件到事务中 已读取过时返回事务中的当前内容
+// 仅读取不暂存 Expect 与 Read 不会使文件参与提交
+func (t *Transaction) load(path string) (*txFile, error) {
+	path = filepath.Clean(path)
+	if file, ok := t.files[path]; ok {
+		return file, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "[filepath:%s]读取文件失败!", path)
+	}
+	file := &txFile{original: content, exists: err == nil, content: content}
+	file.change.Path = t.Rel(path)
+	t.files[path] = file
+	return file, nil
+}
+
+// stage 标记文件已暂存 按首次暂存的顺序参与变更与提交
+func (t *Transaction) stage(path string, file *txFile) {
+	if file.staged {
+		return
+	}
+	file.staged = true
+	t.order = append(t.order, filepath.Clean(path))
+}
+
+// Expect 记录文件生成时的摘要 文件内容与摘要不一致时说明生成后被手工修改, 回滚时会做三方校验
+func (t *Transaction) Expect(path string, hash string) error {
+	file, err := t.load(path)
+	if err != nil {
+		return err
+	}
+	if file.expected == "" && hash != "" && file.exists && Hash(file.original) != hash {
+		file.change.Warnings = append(file.change.Warnings, "文件在生成后被修改")
+	}
+	file.expected = hash
+	return nil
+}
+
+// Read 事务中文件的当前内容
+func (t *Transaction) Read(path string) ([]byte, error) {
+	file, err := t.load(path)
+	if err != nil {
+		return nil, err
+	}
+	return file.content, nil
+}
+
+// Write 暂存文件内容
+func (t *Transaction) Write(path string, content []byte) error {
+	file, err := t.load(path)
+	if err != nil {
+		return err
+	}
+	file.content = content
+	t.stage(path, file)
+	return nil
+}
+
+// Inject 在事务中执行注入 注入前后内容一致时视为已注入, 注入后出现的重复声明视为冲突
+func (t *Transaction) Inject(a Ast) error {
+	path := Filename(a)
+	if path == "" {
+		return errors.New("注入信息未指定文件!")
+	}
+	file, err := t.load(path)
+	if err != nil {
+		return err
+	}
+	if !file.exists && file.content == nil {
+		return errors.Errorf("[filepath:%s]注入的文件不存在!", path)
+	}
+	t.stage(path, file)
+	before, err := t.format(a, path, file.content, nil)
+	if err != nil {
+		return err
+	}
+	after, err := t.format(a, path, file.content, a.Injection)
+	if err != nil {
+		return err
+	}
+	if after == before {
+		file.change.Warnings = append(file.change.Warnings, "已注入, 跳过")
+		return nil
+	}
+	file.change.Conflicts = append(file.change.Conflicts, newConflicts(path, before, after)...)
+	file.content = []byte(after)
+	return nil
+}
+
+// Revert 在事务中回滚注入
+// 文件在生成后被修改时做三方校验: 回滚后重新注入的结果须与当前文件一致(不计行序), 否则说明注入的代码被手工修改, 视为冲突
+func (t *Transaction) Revert(a Ast) error {
+	path := Filename(a)
+	if path == "" {
+		return errors.New("注入信息未指定文件!")
+	}
+	file, err := t.load(path)
+	if err != nil {
+		return err
+	}
+	if !file.exists && file.content == nil {
+		return nil // 文件已删除 无需回滚
+	}
+	t.stage(path, file)
+	current, err := t.format(a, path, file.content, nil)
+	if err != nil {
+		return err
+	}
+	reverted, err := t.format(a, path, file.content, a.Rollback)
+	if err != nil {
+		return err
+	}
+	if reverted == current {
+		file.change.Warnings = append(file.change.Warnings, "未找到注入的代码, 跳过")
+		return nil
+	}
+	if file.expected != "" && Hash(file.original) != file.expected {
+		reinjected, rErr := t.format(a, path, []byte(reverted), a.Injection)
+		if rErr != nil {
+			return rErr
+		}
+		if !sameLines(reinjected, current) {
+			file.change.Conflicts = append(file.change.Conflicts, "注入的代码在生成后被修改, 回滚结果无法确认")
+		}
+	}
+	file.content = []byte(reverted)
+	return nil
+}
+
+// format 解析内容 执行 apply 后以注入信息的格式输出
+func (t *Transaction) format(a Ast, path string, content []byte, apply func(*ast.File) error) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, content, parser.ParseComments)
+	if err != nil {
+		return "", errors.Wrapf(err, "[filepath:%s]解析文件失败!", path)
+	}
+	if apply != nil {
+		if err = apply(file); err != nil {
+			return "", err
+		}
+	}
+	var builder strings.Builder
+	if err = a.Format(path, &builder, file); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// Changes 事务中全部文件的变更与 unified diff 按操作顺序返回
+func (t *Transaction) Changes() ([]Change, error) {
+	changes := make([]Change, 0, len(t.order))
+	for _, path := range t.order {
+		file := t.files[path]
+		change := file.change
+		from := "a/" + change.Path
+		switch {
+		case !file.exists && file.content == nil:
+			change.Status = ChangeUnchanged
+		case !file.exists:
+			change.Status = ChangeCreated
+			from = "/dev/null"
+		case bytes.Equal(file.original, file.content):
+			change.Status = ChangeUnchanged
+		default:
+			change.Status = ChangeModified
+		}
+		if change.Status != ChangeUnchanged {
+			var original []string
+			if file.exists {
+				original = difflib.SplitLines(string(file.original))
+			}
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        original,
+				B:        difflib.SplitLines(string(file.content)),
+				FromFile: from,
+				ToFile:   "b/" + change.Path,
+				Context:  3,
+			})
+			if err != nil {
+				return nil, err
+			}
+			change.Diff = diff
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Conflicts 事务中的全部冲突
+func (t *Transaction) Conflicts() []string {
+	var conflicts []string
+	for _, path := range t.order {
+		for _, conflict := range t.files[path].change.Conflicts {
+			conflicts = append(conflicts, "["+t.Rel(path)+"]"+conflict)
+		}
+	}
+	return conflicts
+}
+
+// Paths 事务中的文件 按操作顺序返回
+func (t *Transaction) Paths() []string {
+	return append([]string(nil), t.order...)
+}
+
+// Hashes 事务中文件提交后的摘要 以相对 root 的路径为键
+func (t *Transaction) Hashes() map[string]string {
+	hashes := make(map[string]string, len(t.order))
+	for _, path := range t.order {
+		if file := t.files[path]; file.exists || file.content != nil {
+			hashes[t.Rel(path)] = Hash(file.content)
+		}
+	}
+	return hashes
+}
+
+// Rel 相对 root 的路径 与 Hashes 的键一致
+func (t *Transaction) Rel(path string) string {
+	if t.root != "" {
+		if rel, err := filepath.Rel(t.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// Commit 写入全部变更 存在冲突且未设置 Force 时拒绝提交, 写入失败时恢复已写入的文件
+func (t *Transaction) Commit() (err error) {
+	if conflicts := t.Conflicts(); len(conflicts) > 0 && !t.Force {
+		return errors.Errorf("存在冲突, 未写入任何文件: %s", strings.Join(conflicts, "; "))
+	}
+	var written []string
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(written) - 1; i >= 0; i-- {
+			file := t.files[written[i]]
+			if file.exists {
+				_ = writeFile(written[i], file.original)
+			} else {
+				_ = os.Remove(written[i])
+			}
+		}
+	}()
+	for _, path := range t.order {
+		file := t.files[path]
+		if !file.exists && file.content == nil {
+			continue // 不存在且未写入内容 不创建空文件
+		}
+		if file.exists && bytes.Equal(file.original, file.content) {
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return errors.Wrapf(err, "[filepath:%s]创建文件夹失败!", path)
+		}
+		written = append(written, path)
+		if err = writeFile(path, file.content); err != nil {
+			return errors.Wrapf(err, "[filepath:%s]写入文件失败!", path)
+		}
+	}
+	return nil
+}
+
+// writeFile 先写入临时文件再重命名 避免写入中断时留下不完整的文件
+func writeFile(path string, content []byte) error {
+	tmp := path + ".gva-tmp"
+	if err := os.WriteFile(tmp, content, 0666); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// sameLines 不计行序与空行比较两段代码
+func sameLines(a, b string) bool {
+	split := func(s string) []string {
+		var lines []string
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		sort.Strings(lines)
+		return lines
+	}
+	return reflect.DeepEqual(split(a), split(b))
+}
@@ -0,0 +1,249 @@
+package ast
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const transactionSource = `package initialize
+
+func bizRouter() {
+	systemRouter.InitApiRouter()
+}
+`
+
+// callInjection 在 bizRouter 中注入一条调用语句 已存在时跳过, 回滚时删除全部相同的调用
+type callInjection struct {
+	Base
+	Path string
+	Call string
+}
+
+func (a *callInjection) Injection(file *ast.File) error {
+	decl := FindFunction(file, "bizRouter")
+	for _, stmt := range decl.Body.List {
+		if expr, ok := stmt.(*ast.ExprStmt); ok && exprString(expr.X) == a.Call {
+			return nil
+		}
+	}
+	decl.Body.List = append(decl.Body.List, CreateStmt(a.Call))
+	return nil
+}
+
+func (a *callInjection) Rollback(file *ast.File) error {
+	decl := FindFunction(file, "bizRouter")
+	list := decl.Body.List[:0]
+	for _, stmt := range decl.Body.List {
+		if expr, ok := stmt.(*ast.ExprStmt); ok && exprString(expr.X) == a.Call {
+			continue
+		}
+		list = append(list, stmt)
+	}
+	decl.Body.List = list
+	return nil
+}
+
+func writeTransactionSource(t *testing.T, content string) (string, string) {
+	root := t.TempDir()
+	path := filepath.Join(root, "router_biz.go")
+	if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return root, path
+}
+
+func TestTransaction_DryRun(t *testing.T) {
+	root, path := writeTransactionSource(t, transactionSource)
+	tx := NewTransaction(root)
+	if err := tx.Write(filepath.Join(root, "router", "demo.go"), []byte("package router\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Inject(&callInjection{Path: path, Call: "exampleRouter.InitDemoRouter()"}); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := tx.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Status != ChangeCreated || changes[1].Status != ChangeModified {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	if changes[1].Path != "router_biz.go" || !strings.Contains(changes[1].Diff, "+\texampleRouter.InitDemoRouter()") {
+		t.Fatalf("unexpected diff: %s", changes[1].Diff)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != transactionSource {
+		t.Fatal("dry run must not write files")
+	}
+}
+
+func TestTransaction_Inject(t *testing.T) {
+	root, path := writeTransactionSource(t, transactionSource)
+	tx := NewTransaction(root)
+	if err := tx.Inject(&callInjection{Path: path, Call: "systemRouter.InitApiRouter()"}); err != nil {
+		t.Fatal(err)
+	}
+	changes, _ := tx.Changes()
+	if changes[0].Status != ChangeUnchanged || len(changes[0].Warnings) != 1 {
+		t.Fatalf("already injected code should be skipped: %+v", changes[0])
+	}
+
+	if err := tx.Inject(&callInjection{Path: path, Call: "exampleRouter.InitDemoRouter()"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Write(path, []byte(strings.Replace(string(mustRead(t, tx, path)), "exampleRouter.InitDemoRouter()", "exampleRouter.InitDemoRouter()\n\texampleRouter.InitDemoRouter()", 1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Inject(&callInjection{Path: path, Call: "exampleRouter.InitOtherRouter()"}); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := tx.Conflicts(); len(conflicts) != 0 {
+		t.Fatalf("conflicts existing before injection should be ignored: %v", conflicts)
+	}
+}
+
+func TestTransaction_Conflict(t *testing.T) {
+	root, path := writeTransactionSource(t, transactionSource)
+	tx := NewTransaction(root)
+	if err := tx.Write(path, []byte(strings.Replace(transactionSource, "package initialize\n", "package initialize\n\nimport \"github.com/a/router\"\n\nvar _ = router.A\n", 1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Inject(&importInjection{Path: path, ImportPath: `"github.com/b/router"`}); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := tx.Conflicts(); len(conflicts) != 1 || !strings.Contains(conflicts[0], "导入名[router]冲突") {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+	if err := tx.Commit(); err == nil {
+		t.Fatal("commit with conflicts should fail")
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != transactionSource {
+		t.Fatal("failed commit must not write files")
+	}
+}
+
+func TestTransaction_Commit(t *testing.T) {
+	root, path := writeTransactionSource(t, transactionSource)
+	tx := NewTransaction(root)
+	if err := tx.Inject(&callInjection{Path: path, Call: "exampleRouter.InitDemoRouter()"}); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(root, "router", "demo.go")
+	if err := tx.Write(created, []byte("package router\n")); err != nil {
+		t.Fatal(err)
+	}
+	blocked := filepath.Join(root, "blocked")
+	if err := tx.Write(filepath.Join(blocked, "demo.go"), []byte("package blocked\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blocked, nil, 0666); err != nil {
+		t.Fatal(err)
+	} // 暂存后目录位置被文件占用 写入失败
+	if err := tx.Commit(); err == nil {
+		t.Fatal("commit into a file path should fail")
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != transactionSource {
+		t.Fatal("modified file should be restored")
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Fatal("created file should be removed")
+	}
+}
+
+func TestTransaction_Revert(t *testing.T) {
+	root, path := writeTransactionSource(t, transactionSource)
+	injection := &callInjection{Path: path, Call: "exampleRouter.InitDemoRouter()"}
+	tx := NewTransaction(root)
+	if err := tx.Inject(injection); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	hash := tx.Hashes()["router_biz.go"]
+	injected := string(mustRead(t, tx, path))
+
+	// 生成后注入了其他代码 回滚结果可以确认
+	other := strings.Replace(injected, "exampleRouter.InitDemoRouter()", "exampleRouter.InitDemoRouter()\n\tsystemRouter.InitMenuRouter()", 1)
+	if err := os.WriteFile(path, []byte(other), 0666); err != nil {
+		t.Fatal(err)
+	}
+	tx = NewTransaction(root)
+	if err := tx.Expect(path, hash); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Revert(injection); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := tx.Conflicts(); len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+
+	// 注入的代码被手工复制 回滚会删除手工添加的代码 拒绝回滚
+	edited := strings.Replace(injected, "exampleRouter.InitDemoRouter()", "exampleRouter.InitDemoRouter()\n\texampleRouter.InitDemoRouter()", 1)
+	if err := os.WriteFile(path, []byte(edited), 0666); err != nil {
+		t.Fatal(err)
+	}
+	tx = NewTransaction(root)
+	if err := tx.Expect(path, hash); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Revert(injection); err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Conflicts()) == 0 || tx.Commit() == nil {
+		t.Fatal("reverting hand edited injection should conflict")
+	}
+	tx.Force = true
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransaction_RevertMissing(t *testing.T) {
+	root, path := writeTransactionSource(t, transactionSource)
+	missing := filepath.Join(root, "router", "deleted.go")
+	tx := NewTransaction(root)
+	if err := tx.Expect(missing, Hash([]byte(transactionSource))); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Revert(&callInjection{Path: missing, Call: "exampleRouter.InitDemoRouter()"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Read(path); err != nil {
+		t.Fatal(err)
+	}
+	if paths := tx.Paths(); len(paths) != 0 {
+		t.Fatalf("files without staged content should not be tracked: %v", paths)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Fatalf("deleted file should not be recreated: %v", err)
+	}
+}
+
+// importInjection 注入一条导入
+type importInjection struct {
+	Base
+	Path       string
+	ImportPath string
+}
+
+func (a *importInjection) Injection(file *ast.File) error {
+	return NewImport(a.ImportPath).Injection(file)
+}
+
+func mustRead(t *testing.T, tx *Transaction, path string) []byte {
+	content, err := tx.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
@@ -0,0 +1,213 @@
+// Package cache 多级缓存
+//
+// 一级为进程内 LRU 二级为可选的 Redis 读取顺序为 进程内 -> Redis -> 加载函数
+// 同一条目的并发加载通过 global.GVA_Concurrency_Control 合并 加载结果同时写入两级缓存
+// 写入方在修改数据后调用 Invalidate 或 InvalidateAll 删除两级缓存
+// 并通过 Redis 发布订阅通知其他实例删除各自的进程内缓存
+//
+// 值以 JSON 保存 每次读取得到独立的副本 调用方修改结果不会影响缓存
+// 未开启 Redis 时多实例部署之间只能依靠过期时间同步
+package cache
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultSize = 10000
+	DefaultTTL  = 10 * time.Minute
+)
+
+// Options 缓存配置
+type Options struct {
+	Size  int                   // 进程内缓存的最大条目数 默认 DefaultSize
+	TTL   time.Duration         // 未指定过期时间的键使用的过期时间 默认 DefaultTTL
+	Redis redis.UniversalClient // 二级缓存与失效通知使用的 Redis 为 nil 时只使用进程内缓存
+}
+
+// Key 带类型的缓存键 Namespace 为一类数据 同一类数据以 id 区分多个条目
+type Key[T any] struct {
+	Namespace string
+	TTL       time.Duration // 为0时使用 Options.TTL
+}
+
+// NewKey 创建缓存键 并登记命名空间以便统计
+func NewKey[T any](namespace string, ttl time.Duration) Key[T] {
+	namespaceOf(namespace)
+	return Key[T]{Namespace: namespace, TTL: ttl}
+}
+
+// Get 读取缓存 未命中时调用 load 加载并写入缓存 load 返回错误时不缓存
+func (k Key[T]) Get(id string, load func() (T, error)) (value T, err error) {
+	c := current()
+	ns := namespaceOf(k.Namespace)
+	key := k.Namespace + ":" + id
+	if data, ok := c.local.get(key); ok && json.Unmarshal(data, &value) == nil {
+		ns.localHits.Add(1)
+		return value, nil
+	}
+	if data, ok := c.remote.get(key); ok && json.Unmarshal(data, &value) == nil {
+		ns.redisHits.Add(1)
+		c.local.set(key, data, c.ttl(k.TTL))
+		return value, nil
+	}
+	ns.misses.Add(1)
+	// 失效后代数变化 新的请求不会合并到失效前开始的加载中
+	generation := ns.generation.Load()
+	flight := "cache:" + strconv.FormatUint(generation, 10) + ":" + key
+	data, err, _ := global.GVA_Concurrency_Control.Do(flight, func() (interface{}, error) {
+		loaded, err := load()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, err
+		}
+		// 加载期间发生了失效 结果可能是旧数据 只返回不缓存
+		if ns.generation.Load() == generation {
+			ttl := c.ttl(k.TTL)
+			c.local.set(key, data, ttl)
+			c.remote.set(key, data, ttl)
+		}
+		return data, nil
+	})
+	if err != nil {
+		ns.errors.Add(1)
+		return value, err
+	}
+	err = json.Unmarshal(data.([]byte), &value)
+	return value, err
+}
+
+// Invalidate 删除指定条目
+func (k Key[T]) Invalidate(ids ...string) {
+	Invalidate(k.Namespace, ids...)
+}
+
+// InvalidateAll 删除命名空间下的所有条目
+func (k Key[T]) InvalidateAll() {
+	InvalidateAll(k.Namespace)
+}
+
+// Invalidate 删除命名空间下的指定条目 并通知其他实例
+func Invalidate(namespace string, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	c := current()
+	c.drop(namespace, ids)
+	c.remote.delete(namespace, ids)
+	c.remote.publish(namespace, ids)
+}
+
+// InvalidateAll 删除命名空间下的所有条目 并通知其他实例
+func InvalidateAll(namespace string) {
+	c := current()
+	c.drop(namespace, nil)
+	c.remote.delete(namespace, nil)
+	c.remote.publish(namespace, nil)
+}
+
+var (
+	hooks   = make(map[string][]func())
+	hooksMu sync.RWMutex
+)
+
+// OnInvalidate 注册其他实例使命名空间失效时的回调 用于同步缓存之外的进程内状态 如 casbin 策略
+func OnInvalidate(namespace string, fn func()) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks[namespace] = append(hooks[namespace], fn)
+}
+
+type cache struct {
+	options Options
+	local   *lru
+	remote  *remote
+}
+
+var instance atomic.Pointer[cache]
+
+func current() *cache {
+	if c := instance.Load(); c != nil {
+		return c
+	}
+	instance.CompareAndSwap(nil, newCache(Options{}))
+	return instance.Load()
+}
+
+func newCache(options Options) *cache {
+	if options.Size <= 0 {
+		options.Size = DefaultSize
+	}
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+	c := &cache{options: options, local: newLRU(options.Size)}
+	c.remote = newRemote(options.Redis, c)
+	return c
+}
+
+// Setup 按配置初始化缓存 替换之前的缓存 已缓存的条目被丢弃
+func Setup(options Options) {
+	c := newCache(options)
+	if old := instance.Swap(c); old != nil {
+		old.remote.close()
+	}
+	c.remote.subscribe()
+}
+
+// Close 停止接收失效通知
+func Close() {
+	if c := instance.Load(); c != nil {
+		c.remote.close()
+	}
+}
+
+func (c *cache) ttl(ttl time.Duration) time.Duration {
+	if ttl > 0 {
+		return ttl
+	}
+	return c.options.TTL
+}
+
+// drop 删除进程内缓存 ids 为空时删除整个命名空间
+func (c *cache) drop(namespace string, ids []string) {
+	ns := namespaceOf(namespace)
+	ns.generation.Add(1)
+	ns.invalidations.Add(1)
+	if len(ids) == 0 {
+		c.local.deletePrefix(namespace + ":")
+		return
+	}
+	for _, id := range ids {
+		c.local.delete(namespace + ":" + id)
+	}
+}
+
+// received 收到其他实例的失效通知
+func (c *cache) received(namespace string, ids []string) {
+	c.drop(namespace, ids)
+	hooksMu.RLock()
+	fns := hooks[namespace]
+	hooksMu.RUnlock()
+	for _, fn := range fns {
+		func() {
+			defer func() {
+				if r := recover(); r != nil && global.GVA_LOG != nil {
+					global.GVA_LOG.Error("缓存失效回调异常", zap.String("namespace", namespace), zap.Any("panic", r))
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type menu struct {
+	Name     string
+	Children []string
+}
+
+func TestKey(t *testing.T) {
+	Setup(Options{})
+	key := NewKey[menu]("test-key", 0)
+	var loads atomic.Int32
+	load := func() (menu, error) {
+		loads.Add(1)
+		return menu{Name: "root", Children: []string{"a"}}, nil
+	}
+
+	first, err := key.Get("1", load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Children[0] = "changed"
+	second, _ := key.Get("1", load)
+	if loads.Load() != 1 {
+		t.Errorf("命中缓存时不应加载 loads=%d", loads.Load())
+	}
+	if second.Children[0] != "a" {
+		t.Errorf("修改读取结果不应影响缓存 got %v", second.Children)
+	}
+
+	key.Invalidate("1")
+	_, _ = key.Get("1", load)
+	_, _ = key.Get("2", load)
+	if loads.Load() != 3 {
+		t.Errorf("失效后应重新加载 loads=%d", loads.Load())
+	}
+	key.InvalidateAll()
+	_, _ = key.Get("2", load)
+	if loads.Load() != 4 {
+		t.Errorf("命名空间失效后应重新加载 loads=%d", loads.Load())
+	}
+
+	failed := errors.New("db down")
+	if _, err = key.Get("3", func() (menu, error) { return menu{}, failed }); !errors.Is(err, failed) {
+		t.Errorf("应返回加载错误 got %v", err)
+	}
+	_, _ = key.Get("3", load)
+	if loads.Load() != 5 {
+		t.Errorf("加载失败不应缓存 loads=%d", loads.Load())
+	}
+
+	for _, stat := range Stats() {
+		if stat.Namespace == "test-key" && (stat.LocalHits != 1 || stat.Misses != 6 || stat.Errors != 1 || stat.Invalidations != 2) {
+			t.Errorf("统计不正确 %+v", stat)
+		}
+	}
+}
+
+func TestKeySingleflight(t *testing.T) {
+	Setup(Options{})
+	key := NewKey[int]("test-flight", 0)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = key.Get("1", func() (int, error) {
+				loads.Add(1)
+				<-release
+				return 1, nil
+			})
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if loads.Load() != 1 {
+		t.Errorf("并发未命中应只加载一次 loads=%d", loads.Load())
+	}
+}
+
+func TestKeyInvalidateDuringLoad(t *testing.T) {
+	Setup(Options{})
+	key := NewKey[int]("test-stale", 0)
+	_, _ = key.Get("1", func() (int, error) {
+		key.Invalidate("1") // 加载期间数据被修改
+		return 1, nil
+	})
+	value, _ := key.Get("1", func() (int, error) { return 2, nil })
+	if value != 2 {
+		t.Errorf("加载期间失效的旧数据不应被缓存 got %d", value)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	l := newLRU(2)
+	l.set("a", []byte("1"), time.Minute)
+	l.set("b", []byte("2"), time.Minute)
+	l.get("a")
+	l.set("c", []byte("3"), time.Minute)
+	if _, ok := l.get("b"); ok {
+		t.Error("应淘汰最久未使用的条目")
+	}
+	if _, ok := l.get("a"); !ok {
+		t.Error("最近使用的条目不应被淘汰")
+	}
+	l.set("d", []byte("4"), -time.Second)
+	if _, ok := l.get("d"); ok {
+		t.Error("过期的条目不应返回")
+	}
+}
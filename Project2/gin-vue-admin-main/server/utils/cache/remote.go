@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/gofrs/uuid/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	keyPrefix = "gva:cache:"
+	channel   = "gva:cache:invalidate"
+	// redisTimeout Redis 不可用时尽快回退到加载函数
+	redisTimeout = 500 * time.Millisecond
+)
+
+// message 失效通知 IDs 为空时为整个命名空间
+type message struct {
+	Node      string   `json:"node"`
+	Namespace string   `json:"namespace"`
+	IDs       []string `json:"ids,omitempty"`
+}
+
+// remote Redis 二级缓存与失效通知 client 为 nil 时所有操作为空
+type remote struct {
+	client redis.UniversalClient
+	cache  *cache
+	node   string
+	pubsub *redis.PubSub
+	once   sync.Once
+}
+
+func newRemote(client redis.UniversalClient, c *cache) *remote {
+	return &remote{client: client, cache: c, node: uuid.Must(uuid.NewV4()).String()}
+}
+
+func (r *remote) get(key string) ([]byte, bool) {
+	if r.client == nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	data, err := r.client.Get(ctx, keyPrefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.warn("读取缓存失败", err)
+		}
+		return nil, false
+	}
+	return data, true
+}
+
+func (r *remote) set(key string, data []byte, ttl time.Duration) {
+	if r.client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := r.client.Set(ctx, keyPrefix+key, data, ttl).Err(); err != nil {
+		r.warn("写入缓存失败", err)
+	}
+}
+
+// delete 删除 Redis 中的条目 ids 为空时扫描删除整个命名空间
+func (r *remote) delete(namespace string, ids []string) {
+	if r.client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*redisTimeout)
+	defer cancel()
+	if len(ids) > 0 {
+		keys := make([]string, 0, len(ids))
+		for _, id := range ids {
+			keys = append(keys, keyPrefix+namespace+":"+id)
+		}
+		if err := r.client.Del(ctx, keys...).Err(); err != nil {
+			r.warn("删除缓存失败", err)
+		}
+		return
+	}
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		iter := client.Scan(ctx, 0, keyPrefix+namespace+":*", 100).Iterator()
+		for iter.Next(ctx) {
+			if err := client.Del(ctx, iter.Val()).Err(); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+	var err error
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+	} else {
+		err = scan(ctx, r.client)
+	}
+	if err != nil {
+		r.warn("删除缓存失败", err)
+	}
+}
+
+func (r *remote) publish(namespace string, ids []string) {
+	if r.client == nil {
+		return
+	}
+	payload, _ := json.Marshal(message{Node: r.node, Namespace: namespace, IDs: ids})
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := r.client.Publish(ctx, channel, payload).Err(); err != nil {
+		r.warn("发送缓存失效通知失败", err)
+	}
+}
+
+// subscribe 接收其他实例的失效通知 断线期间错过的通知由过期时间兜底
+func (r *remote) subscribe() {
+	if r.client == nil {
+		return
+	}
+	r.pubsub = r.client.Subscribe(context.Background(), channel)
+	go func() {
+		for msg := range r.pubsub.Channel() {
+			var m message
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil || m.Node == r.node {
+				continue
+			}
+			r.cache.received(m.Namespace, m.IDs)
+		}
+	}()
+}
+
+func (r *remote) close() {
+	r.once.Do(func() {
+		if r.pubsub != nil {
+			_ = r.pubsub.Close()
+		}
+	})
+}
+
+func (r *remote) warn(msg string, err error) {
+	if global.GVA_LOG != nil {
+		global.GVA_LOG.Warn(msg, zap.Error(err))
+	}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// namespace 命名空间的失效代数与统计
+type namespace struct {
+	generation    atomic.Uint64
+	localHits     atomic.Int64
+	redisHits     atomic.Int64
+	misses        atomic.Int64
+	errors        atomic.Int64
+	invalidations atomic.Int64
+}
+
+var namespaces sync.Map
+
+func namespaceOf(name string) *namespace {
+	if ns, ok := namespaces.Load(name); ok {
+		return ns.(*namespace)
+	}
+	ns, _ := namespaces.LoadOrStore(name, new(namespace))
+	return ns.(*namespace)
+}
+
+// Stat 命名空间的命中统计 自进程启动起累计
+type Stat struct {
+	Namespace     string  `json:"namespace"`
+	LocalHits     int64   `json:"localHits"`     // 进程内缓存命中次数
+	RedisHits     int64   `json:"redisHits"`     // Redis 缓存命中次数
+	Misses        int64   `json:"misses"`        // 未命中次数 即调用加载函数的次数 并发合并前
+	Errors        int64   `json:"errors"`        // 加载失败次数
+	Invalidations int64   `json:"invalidations"` // 失效次数 包含其他实例的通知
+	HitRate       float64 `json:"hitRate"`       // 命中率
+}
+
+// Stats 各命名空间的命中统计 按名称排序
+func Stats() []Stat {
+	var list []Stat
+	namespaces.Range(func(key, value interface{}) bool {
+		ns := value.(*namespace)
+		stat := Stat{
+			Namespace:     key.(string),
+			LocalHits:     ns.localHits.Load(),
+			RedisHits:     ns.redisHits.Load(),
+			Misses:        ns.misses.Load(),
+			Errors:        ns.errors.Load(),
+			Invalidations: ns.invalidations.Load(),
+		}
+		if total := stat.LocalHits + stat.RedisHits + stat.Misses; total > 0 {
+			stat.HitRate = float64(stat.LocalHits+stat.RedisHits) / float64(total)
+		}
+		list = append(list, stat)
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].Namespace < list[j].Namespace })
+	return list
+}
+
+// Len 进程内缓存的条目数
+func Len() int {
+	return current().local.len()
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// AesEncrypt 使用 AES-GCM 加密 key 为任意长度的密钥 经 sha256 派生为 32 字节
+// 返回 base64 编码的 nonce+密文
+func AesEncrypt(key, plaintext string) (string, error) {
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// AesDecrypt 解密 AesEncrypt 的结果 密钥不一致或密文被篡改时返回错误
+func AesDecrypt(key, ciphertext string) (string, error) {
+	gcm, err := aesGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("密文长度不正确")
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.Wrap(err, "解密失败 密钥可能已变更")
+	}
+	return string(plaintext), nil
+}
+
+func aesGCM(key string) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, errors.New("密钥不能为空")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
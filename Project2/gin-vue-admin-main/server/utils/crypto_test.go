@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestAesEncrypt(t *testing.T) {
+	ciphertext, err := AesEncrypt("key", "p@ssw0rd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := AesEncrypt("key", "p@ssw0rd"); again == ciphertext {
+		t.Error("相同明文的密文应不同")
+	}
+	plaintext, err := AesDecrypt("key", ciphertext)
+	if err != nil || plaintext != "p@ssw0rd" {
+		t.Errorf("解密结果不正确 got %q %v", plaintext, err)
+	}
+	if _, err = AesDecrypt("other", ciphertext); err == nil {
+		t.Error("密钥不一致时应解密失败")
+	}
+	if _, err = AesEncrypt("", "p@ssw0rd"); err == nil {
+		t.Error("密钥为空时应返回错误")
+	}
+}
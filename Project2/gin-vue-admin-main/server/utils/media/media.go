@@ -0,0 +1,201 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"net/http"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// SniffLen http.DetectContentType 最多读取的字节数
+const SniffLen = 512
+
+// Sniff 根据文件内容嗅探MIME类型 不信任客户端传入的 Content-Type 与扩展名
+func Sniff(content []byte) string {
+	if len(content) > SniffLen {
+		content = content[:SniffLen]
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// MatchMime 判断MIME是否命中规则 支持 image/* 与 */* 通配
+func MatchMime(mimeType string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "":
+			continue
+		case p == "*/*" || p == "*":
+			return true
+		case strings.HasSuffix(p, "/*"):
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		case p == mimeType:
+			return true
+		}
+	}
+	return false
+}
+
+// CheckMime 黑名单优先 白名单为空时不限制
+func CheckMime(mimeType string, allow, deny []string) error {
+	if MatchMime(mimeType, deny) {
+		return fmt.Errorf("不允许上传的文件类型: %s", mimeType)
+	}
+	if len(allow) > 0 && !MatchMime(mimeType, allow) {
+		return fmt.Errorf("不允许上传的文件类型: %s", mimeType)
+	}
+	return nil
+}
+
+// IsImage 是否为可以解析尺寸和生成缩略图的图片
+func IsImage(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Dimensions 读取图片宽高 不解码整张图片
+func Dimensions(content []byte) (width int, height int, err error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// StripJpegMetadata 去除JPEG中的EXIF/XMP(APP1)、IPTC(APP13)与注释段 不重新编码图像数据
+// 保留 APP0(JFIF)、APP2(ICC色彩配置)与 APP14(Adobe) 以免影响显示
+func StripJpegMetadata(content []byte) ([]byte, error) {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, errors.New("不是合法的JPEG文件")
+	}
+	out := make([]byte, 0, len(content))
+	out = append(out, 0xFF, 0xD8)
+	i := 2
+	for i < len(content) {
+		if content[i] != 0xFF {
+			return nil, errors.New("JPEG段标记错误")
+		}
+		// 段标记前允许有填充的 0xFF
+		for i < len(content) && content[i] == 0xFF {
+			i++
+		}
+		if i >= len(content) {
+			return nil, errors.New("JPEG文件不完整")
+		}
+		marker := content[i]
+		i++
+		// SOS之后为压缩数据 直接原样拷贝剩余部分
+		if marker == 0xDA {
+			out = append(out, 0xFF, marker)
+			out = append(out, content[i:]...)
+			return out, nil
+		}
+		// 无长度的独立标记
+		if marker == 0xD8 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, 0xFF, marker)
+			continue
+		}
+		if marker == 0xD9 {
+			out = append(out, 0xFF, marker)
+			return out, nil
+		}
+		if i+2 > len(content) {
+			return nil, errors.New("JPEG文件不完整")
+		}
+		length := int(binary.BigEndian.Uint16(content[i : i+2]))
+		if length < 2 || i+length > len(content) {
+			return nil, errors.New("JPEG段长度错误")
+		}
+		segment := content[i : i+length]
+		i += length
+		if marker == 0xE1 || marker == 0xED || marker == 0xFE {
+			continue
+		}
+		out = append(out, 0xFF, marker)
+		out = append(out, segment...)
+	}
+	return out, nil
+}
+
+// maxThumbnailPixels 生成缩略图的原图像素上限 解码时每像素至少占用4字节 避免小文件声明超大尺寸耗尽内存
+var maxThumbnailPixels = 50 * 1000 * 1000
+
+// Thumbnail 等比缩放生成缩略图 原图小于限制时按原尺寸重新编码
+// 透明格式输出png 其余输出jpeg
+func Thumbnail(content []byte, maxWidth, maxHeight int) (thumb []byte, ext string, err error) {
+	width, height, err := Dimensions(content)
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(width)*int64(height) > int64(maxThumbnailPixels) {
+		return nil, "", fmt.Errorf("图片尺寸 %dx%d 超过缩略图的像素上限", width, height)
+	}
+	src, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", err
+	}
+	b := src.Bounds()
+	w, h := fitSize(b.Dx(), b.Dy(), maxWidth, maxHeight)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	buf := new(bytes.Buffer)
+	switch format {
+	case "png", "gif", "webp":
+		err = png.Encode(buf, dst)
+		ext = ".png"
+	default:
+		err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: 85})
+		ext = ".jpg"
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), ext, nil
+}
+
+func fitSize(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return 1, 1
+	}
+	if maxWidth <= 0 {
+		maxWidth = width
+	}
+	if maxHeight <= 0 {
+		maxHeight = height
+	}
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+	ratio := float64(maxWidth) / float64(width)
+	if r := float64(maxHeight) / float64(height); r < ratio {
+		ratio = r
+	}
+	w, h := int(float64(width)*ratio), int(float64(height)*ratio)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
@@ -0,0 +1,126 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func testJpeg(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withExif 在SOI之后插入一个APP1段
+func withExif(content []byte) []byte {
+	exif := append([]byte("Exif\x00\x00"), bytes.Repeat([]byte{0x42}, 32)...)
+	length := len(exif) + 2
+	segment := append([]byte{0xFF, 0xE1, byte(length >> 8), byte(length)}, exif...)
+	out := append([]byte{}, content[:2]...)
+	out = append(out, segment...)
+	return append(out, content[2:]...)
+}
+
+func TestMatchMime(t *testing.T) {
+	tests := []struct {
+		name     string
+		mimeType string
+		patterns []string
+		want     bool
+	}{
+		{name: "精确匹配", mimeType: "image/png", patterns: []string{"image/png"}, want: true},
+		{name: "通配匹配", mimeType: "image/png", patterns: []string{"image/*"}, want: true},
+		{name: "全部匹配", mimeType: "application/pdf", patterns: []string{"*/*"}, want: true},
+		{name: "未命中", mimeType: "application/pdf", patterns: []string{"image/*", "text/plain"}, want: false},
+		{name: "空规则", mimeType: "application/pdf", patterns: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchMime(tt.mimeType, tt.patterns); got != tt.want {
+				t.Errorf("MatchMime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMime(t *testing.T) {
+	if err := CheckMime("image/png", []string{"image/*"}, []string{"image/svg+xml"}); err != nil {
+		t.Errorf("CheckMime() unexpected error = %v", err)
+	}
+	if err := CheckMime("image/svg+xml", []string{"image/*"}, []string{"image/svg+xml"}); err == nil {
+		t.Error("CheckMime() 黑名单应优先于白名单")
+	}
+	if err := CheckMime("application/zip", []string{"image/*"}, nil); err == nil {
+		t.Error("CheckMime() 未在白名单中的类型应被拒绝")
+	}
+}
+
+func TestStripJpegMetadata(t *testing.T) {
+	origin := testJpeg(t, 16, 16)
+	dirty := withExif(origin)
+	if !bytes.Contains(dirty, []byte("Exif")) {
+		t.Fatal("测试数据构造失败")
+	}
+	clean, err := StripJpegMetadata(dirty)
+	if err != nil {
+		t.Fatalf("StripJpegMetadata() error = %v", err)
+	}
+	if bytes.Contains(clean, []byte("Exif")) {
+		t.Error("StripJpegMetadata() EXIF未被去除")
+	}
+	if !bytes.Equal(clean, origin) {
+		t.Error("StripJpegMetadata() 不应改动图像数据")
+	}
+	if _, err = StripJpegMetadata([]byte("not a jpeg")); err == nil {
+		t.Error("StripJpegMetadata() 非JPEG应返回错误")
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	thumb, ext, err := Thumbnail(testJpeg(t, 400, 200), 100, 100)
+	if err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+	if ext != ".jpg" {
+		t.Errorf("Thumbnail() ext = %s, want .jpg", ext)
+	}
+	w, h, err := Dimensions(thumb)
+	if err != nil {
+		t.Fatalf("Dimensions() error = %v", err)
+	}
+	if w != 100 || h != 50 {
+		t.Errorf("Thumbnail() size = %dx%d, want 100x50", w, h)
+	}
+}
+
+func TestThumbnailPixelLimit(t *testing.T) {
+	limit := maxThumbnailPixels
+	maxThumbnailPixels = 400*200 - 1
+	defer func() { maxThumbnailPixels = limit }()
+	if _, _, err := Thumbnail(testJpeg(t, 400, 200), 100, 100); err == nil {
+		t.Fatal("Thumbnail() should reject images over the pixel limit")
+	}
+	if _, _, err := Thumbnail(testJpeg(t, 200, 200), 100, 100); err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	if got := Sniff(testJpeg(t, 8, 8)); got != "image/jpeg" {
+		t.Errorf("Sniff() = %s, want image/jpeg", got)
+	}
+	if got := Sniff([]byte("hello world")); got != "text/plain" {
+		t.Errorf("Sniff() = %s, want text/plain", got)
+	}
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// MergeCustomBegin 自定义区域开始标记 标记之间的内容始终以本地文件为准
+	MergeCustomBegin = "gva:custom-begin"
+	// MergeCustomEnd 自定义区域结束标记
+	MergeCustomEnd = "gva:custom-end"
+
+	mergeConflictOurs   = "<<<<<<< 本地修改"
+	mergeConflictSep    = "======="
+	mergeConflictTheirs = ">>>>>>> 重新生成"
+)
+
+// Merge3 以行为单位三方合并
+// base 为上次生成的内容 ours 为本地文件(含手工修改) theirs 为本次生成的内容
+// 双方都修改了同一区域时 若该区域位于本地文件的自定义区域(gva:custom-begin 与 gva:custom-end 之间)则保留本地修改
+// 否则以冲突标记输出双方内容 返回合并结果与冲突数量
+func Merge3(base, ours, theirs string) (merged string, conflicts int) {
+	baseLines := mergeLines(base)
+	ourLines := mergeLines(ours)
+	theirLines := mergeLines(theirs)
+	ourMatch := mergeMatch(baseLines, ourLines)
+	theirMatch := mergeMatch(baseLines, theirLines)
+	custom := mergeCustomRegions(ourLines)
+
+	var builder strings.Builder
+	write := func(lines []string) {
+		for _, line := range lines {
+			builder.WriteString(line)
+		}
+	}
+	i0, a0, b0 := 0, 0, 0
+	for i := 0; i <= len(baseLines); i++ {
+		a, b := len(ourLines), len(theirLines)
+		if i < len(baseLines) {
+			a, b = ourMatch[i], theirMatch[i]
+			if a < 0 || b < 0 {
+				continue
+			}
+		} // 同步点: 三方都存在的行 以及文件末尾
+		baseChunk, ourChunk, theirChunk := baseLines[i0:i], ourLines[a0:a], theirLines[b0:b]
+		switch {
+		case mergeEqual(ourChunk, baseChunk):
+			write(theirChunk)
+		case mergeEqual(theirChunk, baseChunk), mergeEqual(ourChunk, theirChunk):
+			write(ourChunk)
+		case mergeInside(custom, a0, a):
+			write(ourChunk)
+		default:
+			conflicts++
+			builder.WriteString(mergeConflictOurs + "\n")
+			write(mergeTerminate(ourChunk))
+			builder.WriteString(mergeConflictSep + "\n")
+			write(mergeTerminate(theirChunk))
+			builder.WriteString(mergeConflictTheirs + "\n")
+		}
+		if i < len(baseLines) {
+			builder.WriteString(ourLines[a])
+		}
+		i0, a0, b0 = i+1, a+1, b+1
+	}
+	return builder.String(), conflicts
+}
+
+// mergeLines 按行拆分 保留换行符
+func mergeLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// mergeMatch 返回 base 每一行在 other 中对应的行号 不存在为 -1
+func mergeMatch(base, other []string) []int {
+	match := make([]int, len(base))
+	for i := range match {
+		match[i] = -1
+	}
+	matcher := difflib.NewMatcherWithJunk(base, other, false, nil)
+	for _, block := range matcher.GetMatchingBlocks() {
+		for k := 0; k < block.Size; k++ {
+			match[block.A+k] = block.B + k
+		}
+	}
+	return match
+}
+
+// mergeCustomRegions 返回本地文件中自定义区域的行号范围 [begin, end]
+func mergeCustomRegions(lines []string) [][2]int {
+	var regions [][2]int
+	begin := -1
+	for i, line := range lines {
+		switch {
+		case strings.Contains(line, MergeCustomBegin):
+			begin = i
+		case strings.Contains(line, MergeCustomEnd) && begin >= 0:
+			regions = append(regions, [2]int{begin, i})
+			begin = -1
+		}
+	}
+	return regions
+}
+
+// mergeInside 本地文件 [from, to) 是否位于某个自定义区域之内(含标记行)
+func mergeInside(regions [][2]int, from, to int) bool {
+	for _, region := range regions {
+		if from >= region[0] && to <= region[1]+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTerminate 保证冲突内容以换行结束 避免与冲突标记连成一行
+func mergeTerminate(lines []string) []string {
+	if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		lines = append(lines[:n-1:n-1], lines[n-1]+"\n")
+	}
+	return lines
+}
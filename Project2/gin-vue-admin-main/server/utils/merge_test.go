@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "package a\n\ntype A struct {\n\tName string\n}\n\nfunc (A) Get() {}\n"
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "只有重新生成修改",
+			ours:   base,
+			theirs: "package a\n\ntype A struct {\n\tName string\n\tAge  int\n}\n\nfunc (A) Get() {}\n",
+			want:   "package a\n\ntype A struct {\n\tName string\n\tAge  int\n}\n\nfunc (A) Get() {}\n",
+		},
+		{
+			name:   "保留本地修改并合并重新生成",
+			ours:   "package a\n\ntype A struct {\n\tName string\n}\n\nfunc (A) Get() {}\n\nfunc (A) Custom() {}\n",
+			theirs: "package a\n\ntype A struct {\n\tName string\n\tAge  int\n}\n\nfunc (A) Get() {}\n",
+			want:   "package a\n\ntype A struct {\n\tName string\n\tAge  int\n}\n\nfunc (A) Get() {}\n\nfunc (A) Custom() {}\n",
+		},
+		{
+			name:      "同一区域冲突",
+			ours:      "package a\n\ntype A struct {\n\tTitle string\n}\n\nfunc (A) Get() {}\n",
+			theirs:    "package a\n\ntype A struct {\n\tLabel string\n}\n\nfunc (A) Get() {}\n",
+			want:      "package a\n\ntype A struct {\n<<<<<<< 本地修改\n\tTitle string\n=======\n\tLabel string\n>>>>>>> 重新生成\n}\n\nfunc (A) Get() {}\n",
+			conflicts: 1,
+		},
+		{
+			name:   "自定义区域以本地为准",
+			ours:   "package a\n\ntype A struct {\n\t// gva:custom-begin\n\tTitle string\n\t// gva:custom-end\n}\n\nfunc (A) Get() {}\n",
+			theirs: "package a\n\ntype A struct {\n\tLabel string\n}\n\nfunc (A) Get() {}\n",
+			want:   "package a\n\ntype A struct {\n\t// gva:custom-begin\n\tTitle string\n\t// gva:custom-end\n}\n\nfunc (A) Get() {}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(base, tt.ours, tt.theirs)
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("Merge3() = %q, %d, want %q, %d", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
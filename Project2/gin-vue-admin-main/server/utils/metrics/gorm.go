@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+var (
+	gormDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gorm_query_duration_seconds",
+		Help:      "数据库语句耗时 table 为空时为原生SQL",
+		Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"db", "table", "operation"})
+	gormErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gorm_query_errors_total",
+		Help:      "数据库语句错误数 不包含记录不存在",
+	}, []string{"db", "table", "operation"})
+)
+
+const gormStartKey = "gva:metrics:start"
+
+// gormPlugin 在各类语句执行前后记录耗时
+type gormPlugin struct {
+	db string
+}
+
+// UseGorm 为数据库开启语句指标 db 为指标中的数据库名
+func UseGorm(db *gorm.DB, name string) error {
+	if db == nil {
+		return nil
+	}
+	return db.Use(&gormPlugin{db: name})
+}
+
+func (p *gormPlugin) Name() string {
+	return "gva:metrics"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("gva:metrics:before_create", p.before),
+		cb.Create().After("gorm:create").Register("gva:metrics:after_create", p.after("create")),
+		cb.Query().Before("gorm:query").Register("gva:metrics:before_query", p.before),
+		cb.Query().After("gorm:query").Register("gva:metrics:after_query", p.after("query")),
+		cb.Update().Before("gorm:update").Register("gva:metrics:before_update", p.before),
+		cb.Update().After("gorm:update").Register("gva:metrics:after_update", p.after("update")),
+		cb.Delete().Before("gorm:delete").Register("gva:metrics:before_delete", p.before),
+		cb.Delete().After("gorm:delete").Register("gva:metrics:after_delete", p.after("delete")),
+		cb.Row().Before("gorm:row").Register("gva:metrics:before_row", p.before),
+		cb.Row().After("gorm:row").Register("gva:metrics:after_row", p.after("row")),
+		cb.Raw().Before("gorm:raw").Register("gva:metrics:before_raw", p.before),
+		cb.Raw().After("gorm:raw").Register("gva:metrics:after_raw", p.after("raw")),
+	)
+}
+
+func (p *gormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		gormDuration.WithLabelValues(p.db, table, operation).Observe(time.Since(value.(time.Time)).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			gormErrors.WithLabelValues(p.db, table, operation).Inc()
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package metrics Prometheus 指标
+//
+// 所有指标注册在独立的 Registry 中 以 gva_ 为前缀 通过 Handler 输出
+// 接口 数据库 redis 的采集需要分别挂载 Middleware UseGorm UseRedis
+// 定时任务 登录 操作记录的指标由对应模块直接记录
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gva"
+
+// Registry 指标注册表 包含 Go 运行时(协程数 GC 内存)与进程指标
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "接口请求数 route 为路由模板",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "接口耗时",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "正在处理的请求数",
+	})
+
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cron_job_runs_total",
+		Help:      "定时任务执行次数 status 为 ok 或 panic",
+	}, []string{"cron", "task", "status"})
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "cron_job_duration_seconds",
+		Help:      "定时任务耗时",
+		Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+	}, []string{"cron", "task"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_total",
+		Help:      "登录次数 result 为 success 或失败原因",
+	}, []string{"result"})
+
+	// OperationRecordPending 等待写入数据库的操作记录数
+	OperationRecordPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "operation_record_pending",
+		Help:      "等待写入数据库的操作记录数",
+	})
+)
+
+// 登录结果
+const (
+	LoginSuccess  = "success"
+	LoginPassword = "invalid_credentials" // 用户名不存在或者密码错误
+	LoginDisabled = "disabled"            // 用户被禁止登录
+	LoginCaptcha  = "invalid_captcha"     // 验证码错误
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, httpInFlight,
+		gormDuration, gormErrors,
+		redisCommands, redisDuration,
+		jobRuns, jobDuration,
+		logins,
+		OperationRecordPending,
+	)
+}
+
+// Handler 以 Prometheus 文本格式输出指标
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// StartRequest 记录开始处理请求 返回的函数在请求结束时调用
+// route 为空(未匹配到路由)时记为 unmatched 避免路径作为标签导致指标数量膨胀
+func StartRequest(method string) func(route string, status int) {
+	httpInFlight.Inc()
+	start := time.Now()
+	return func(route string, status int) {
+		httpInFlight.Dec()
+		if route == "" {
+			route = "unmatched"
+		}
+		code := strconv.Itoa(status)
+		httpRequests.WithLabelValues(method, route, code).Inc()
+		httpDuration.WithLabelValues(method, route, code).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Job 包装定时任务 记录执行次数与耗时 任务 panic 时记录后继续抛出
+func Job(cron, task string, fn func()) func() {
+	return func() {
+		start := time.Now()
+		status := "panic"
+		defer func() {
+			jobRuns.WithLabelValues(cron, task, status).Inc()
+			jobDuration.WithLabelValues(cron, task).Observe(time.Since(start).Seconds())
+		}()
+		fn()
+		status = "ok"
+	}
+}
+
+// Login 记录登录结果
+func Login(result string) {
+	logins.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID   uint
+	Name string
+}
+
+func TestUseGorm(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:metrics?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = UseGorm(db, "test"); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&item{Name: "a"})
+	db.First(&item{}, 1)
+	db.First(&item{}, 2) // 记录不存在不计为错误
+	db.Exec("SELECT * FROM not_exists")
+
+	if n := testutil.CollectAndCount(gormDuration, "gva_gorm_query_duration_seconds"); n != 3 {
+		t.Errorf("应按表与语句类型区分 got %d", n)
+	}
+	if v := testutil.ToFloat64(gormErrors.WithLabelValues("test", "items", "query")); v != 0 {
+		t.Errorf("记录不存在不应计为错误 got %v", v)
+	}
+	if v := testutil.ToFloat64(gormErrors.WithLabelValues("test", "", "raw")); v != 1 {
+		t.Errorf("语句错误应计数 got %v", v)
+	}
+}
+
+func TestJob(t *testing.T) {
+	Job("test", "ok", func() {})()
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("任务的 panic 应继续抛出")
+			}
+		}()
+		Job("test", "fail", func() { panic(errors.New("boom")) })()
+	}()
+	if v := testutil.ToFloat64(jobRuns.WithLabelValues("test", "ok", "ok")); v != 1 {
+		t.Errorf("ok got %v", v)
+	}
+	if v := testutil.ToFloat64(jobRuns.WithLabelValues("test", "fail", "panic")); v != 1 {
+		t.Errorf("panic got %v", v)
+	}
+}
+
+func TestStartRequest(t *testing.T) {
+	StartRequest("GET")("", 404)
+	StartRequest("GET")("/user/:id", 200)
+	if v := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404")); v != 1 {
+		t.Errorf("未匹配的路由应记为 unmatched got %v", v)
+	}
+	if v := testutil.ToFloat64(httpInFlight); v != 0 {
+		t.Errorf("请求结束后 in flight 应归零 got %v", v)
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	redisCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_commands_total",
+		Help:      "redis命令数 status 为 ok 或 error 键不存在记为 ok",
+	}, []string{"command", "status"})
+	redisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "redis命令耗时 管道记为 pipeline",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command"})
+)
+
+// UseRedis 为 redis 客户端开启命令指标与连接池指标
+func UseRedis(client redis.UniversalClient) error {
+	if client == nil {
+		return nil
+	}
+	client.AddHook(redisHook{})
+	return Registry.Register(&redisPoolCollector{client: client})
+}
+
+type redisHook struct{}
+
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		observeRedis(cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		observeRedis("pipeline", start, err)
+		return err
+	}
+}
+
+func observeRedis(command string, start time.Time, err error) {
+	status := "ok"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = "error"
+	}
+	redisCommands.WithLabelValues(command, status).Inc()
+	redisDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+}
+
+var (
+	redisPoolHits     = prometheus.NewDesc(namespace+"_redis_pool_hits_total", "从连接池取得空闲连接的次数", nil, nil)
+	redisPoolMisses   = prometheus.NewDesc(namespace+"_redis_pool_misses_total", "连接池无空闲连接的次数", nil, nil)
+	redisPoolTimeouts = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total", "等待连接超时的次数", nil, nil)
+	redisPoolConns    = prometheus.NewDesc(namespace+"_redis_pool_connections", "连接池中的连接数 state 为 idle 或 total", []string{"state"}, nil)
+)
+
+// redisPoolCollector 采集时读取连接池状态
+type redisPoolCollector struct {
+	client redis.UniversalClient
+}
+
+func (r *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisPoolHits
+	ch <- redisPoolMisses
+	ch <- redisPoolTimeouts
+	ch <- redisPoolConns
+}
+
+func (r *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, ok := r.client.(interface{ PoolStats() *redis.PoolStats })
+	if !ok {
+		return
+	}
+	s := stats.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisPoolHits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(redisPoolMisses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(redisPoolTimeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisPoolConns, prometheus.GaugeValue, float64(s.IdleConns), "idle")
+	ch <- prometheus.MustNewConstMetric(redisPoolConns, prometheus.GaugeValue, float64(s.TotalConns), "total")
+}
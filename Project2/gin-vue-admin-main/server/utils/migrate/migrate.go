@@ -0,0 +1,210 @@
+// Package migrate 版本化的数据库迁移
+//
+// 每个模块或插件通过 Register 注册一个迁移目录 目录中的脚本命名为
+//
+//	<版本>_<名称>.up.sql          通用的迁移脚本
+//	<版本>_<名称>.down.sql        通用的回退脚本 缺省时该迁移不可回退
+//	<版本>_<名称>.up.<方言>.sql   指定方言的脚本 优先于通用脚本 方言为 mysql|pgsql|mssql|oracle|sqlite
+//
+// 版本为纯数字 按数值大小顺序执行 执行记录保存在 sys_migrations 表中 并记录脚本的校验和
+// 已执行的脚本被修改后启动与继续迁移都会被拒绝
+//
+// 提供 Schema 的模块首次纳入迁移管理时 先按当前模型建表 再把已有的迁移全部记为已执行
+// 因此修改模型时必须同时新增迁移 使按模型建表与依次执行迁移得到相同的结构
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	Mysql  = "mysql"
+	Pgsql  = "pgsql"
+	Mssql  = "mssql"
+	Oracle = "oracle"
+	Sqlite = "sqlite"
+)
+
+// Dialects 支持的方言
+var Dialects = []string{Mysql, Pgsql, Mssql, Oracle, Sqlite}
+
+const (
+	OrderSystem = 10   // 系统模块
+	OrderPlugin = 1000 // 插件 在系统模块之后执行
+)
+
+// BaselineVersion 按模型建表的基线版本
+const BaselineVersion = "0"
+
+// Module 一个模块或插件的迁移
+type Module struct {
+	Name   string                  // 模块名 同时作为迁移记录的 module 字段
+	FS     fs.FS                   // 迁移脚本所在目录
+	Dir    string                  // 迁移脚本目录相对 server 的路径 供 create 命令写入新脚本
+	Schema func(db *gorm.DB) error // 按当前模型建表 为空时从第一个迁移开始执行
+}
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Module   string
+	Version  string
+	Name     string
+	Up       string // 当前方言的迁移脚本
+	Down     string // 当前方言的回退脚本 为空时不可回退
+	Checksum string // 迁移脚本的 sha256
+}
+
+type orderedModule struct {
+	order int
+	Module
+}
+
+var modules []*orderedModule
+
+// Register 注册模块的迁移 order 小的模块先执行 通常在 init() 中调用
+func Register(order int, module Module) {
+	for _, m := range modules {
+		if m.Name == module.Name {
+			panic(fmt.Sprintf("Name conflict on migration module %s", module.Name))
+		}
+	}
+	modules = append(modules, &orderedModule{order, module})
+	sort.SliceStable(modules, func(i, j int) bool {
+		return modules[i].order < modules[j].order
+	})
+}
+
+// Modules 已注册的模块 按执行顺序排列
+func Modules() []Module {
+	list := make([]Module, 0, len(modules))
+	for _, m := range modules {
+		list = append(list, m.Module)
+	}
+	return list
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)(?:\.(\w+))?\.sql$`)
+
+// Load 读取模块在指定方言下的迁移 按版本排序
+func Load(module Module, dialect string) ([]Migration, error) {
+	if module.FS == nil {
+		return nil, nil
+	}
+	entries, err := fs.ReadDir(module.FS, ".")
+	if err != nil {
+		return nil, errors.Wrapf(err, "[%s]读取迁移目录失败!", module.Name)
+	}
+	type scripts struct {
+		name                   string
+		up, down               *string
+		dialectUp, dialectDown *string
+		dialects               []string
+	}
+	versions := make(map[string]*scripts)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := fileName.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue // 说明文档等非迁移文件
+		}
+		version, name, direction, target := strings.TrimLeft(matches[1], "0"), matches[2], matches[3], matches[4]
+		if version == "" {
+			return nil, fmt.Errorf("[%s]%s: 版本不能为0", module.Name, entry.Name())
+		}
+		if target != "" && !contains(Dialects, target) {
+			return nil, fmt.Errorf("[%s]%s: 不支持的方言 %s", module.Name, entry.Name(), target)
+		}
+		s, ok := versions[version]
+		if !ok {
+			s = &scripts{name: name}
+			versions[version] = s
+		}
+		if s.name != name {
+			return nil, fmt.Errorf("[%s]版本 %s 对应了多个迁移: %s, %s", module.Name, version, s.name, name)
+		}
+		if target != "" && !contains(s.dialects, target) {
+			s.dialects = append(s.dialects, target)
+		}
+		if target != "" && target != dialect {
+			continue
+		}
+		content, err := fs.ReadFile(module.FS, entry.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "[%s]读取迁移脚本失败!", module.Name)
+		}
+		text := string(content)
+		switch {
+		case direction == "up" && target == "":
+			s.up = &text
+		case direction == "up":
+			s.dialectUp = &text
+		case target == "":
+			s.down = &text
+		default:
+			s.dialectDown = &text
+		}
+	}
+	migrations := make([]Migration, 0, len(versions))
+	for version, s := range versions {
+		up, down := s.up, s.down
+		if s.dialectUp != nil {
+			up = s.dialectUp
+		}
+		if s.dialectDown != nil {
+			down = s.dialectDown
+		}
+		if up == nil {
+			return nil, fmt.Errorf("[%s]%s_%s 缺少 %s 方言的迁移脚本 已有方言: %s", module.Name, version, s.name, dialect, strings.Join(s.dialects, ","))
+		}
+		migration := Migration{Module: module.Name, Version: version, Name: s.name, Up: *up, Checksum: checksum(*up)}
+		if down != nil {
+			migration.Down = *down
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return less(migrations[i].Version, migrations[j].Version)
+	})
+	return migrations, nil
+}
+
+// File 迁移脚本的文件名
+func File(version, name, direction, dialect string) string {
+	if dialect == "" {
+		return fmt.Sprintf("%s_%s.%s.sql", version, name, direction)
+	}
+	return fmt.Sprintf("%s_%s.%s.%s.sql", version, name, direction, dialect)
+}
+
+// less 按数值比较版本 版本已去除前导0
+func less(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(strings.ReplaceAll(content, "\r\n", "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
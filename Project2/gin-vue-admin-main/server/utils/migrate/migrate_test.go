@@ -0,0 +1,168 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/testdb"
+	"gorm.io/gorm"
+)
+
+func TestStatements(t *testing.T) {
+	script := `-- 注释中的分号; 不拆分
+CREATE TABLE a (id int, name varchar(20) DEFAULT 'x;y');
+/* 块注释; */ INSERT INTO a VALUES (1, 'it''s');
+
+UPDATE a SET name = "b;c";`
+	want := []string{
+		"CREATE TABLE a (id int, name varchar(20) DEFAULT 'x;y')",
+		"INSERT INTO a VALUES (1, 'it''s')",
+		`UPDATE a SET name = "b;c"`,
+	}
+	if got := Statements(script, Sqlite); !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements() = %q, want %q", got, want)
+	}
+
+	// mysql 引号中的反斜杠为转义符 其他方言中反斜杠为普通字符
+	script = `INSERT INTO a VALUES (1, 'it\'s;'); INSERT INTO a VALUES (2, "say \"hi;\"", 'c:\\');`
+	want = []string{
+		`INSERT INTO a VALUES (1, 'it\'s;')`,
+		`INSERT INTO a VALUES (2, "say \"hi;\"", 'c:\\')`,
+	}
+	if got := Statements(script, Mysql); !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements(mysql) = %q, want %q", got, want)
+	}
+	script = `INSERT INTO a VALUES ('c:\'); INSERT INTO a VALUES ('d')`
+	want = []string{`INSERT INTO a VALUES ('c:\')`, `INSERT INTO a VALUES ('d')`}
+	if got := Statements(script, Pgsql); !reflect.DeepEqual(got, want) {
+		t.Errorf("Statements(pgsql) = %q, want %q", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	module := Module{Name: "test", FS: fstest.MapFS{
+		"10_second.up.sql":             {Data: []byte("second")},
+		"2_first.up.sql":               {Data: []byte("first")},
+		"2_first.up.mysql.sql":         {Data: []byte("first mysql")},
+		"2_first.down.sql":             {Data: []byte("undo first")},
+		"README.md":                    {Data: []byte("说明")},
+		"0011_only_pgsql.up.pgsql.sql": {Data: []byte("pgsql")},
+	}}
+	migrations, err := Load(module, Sqlite)
+	if err == nil {
+		t.Fatalf("缺少方言脚本时应返回错误 got %+v", migrations)
+	}
+	migrations, err = Load(module, Pgsql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []string
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version+":"+migration.Up)
+	}
+	if want := []string{"2:first", "10:second", "11:pgsql"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("Load(pgsql) = %v, want %v", versions, want)
+	}
+	migrations, err = Load(Module{Name: "test", FS: fstest.MapFS{
+		"2_first.up.sql":       {Data: []byte("first")},
+		"2_first.up.mysql.sql": {Data: []byte("first mysql")},
+		"2_first.down.sql":     {Data: []byte("undo first")},
+	}}, Mysql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 1 || migrations[0].Up != "first mysql" || migrations[0].Down != "undo first" {
+		t.Errorf("Load(mysql) = %+v, 方言脚本应优先于通用脚本", migrations)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	db := testdb.Open(t, "")
+	scripts := fstest.MapFS{
+		"1_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id integer);")},
+		"1_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"2_add_b.up.sql":      {Data: []byte("ALTER TABLE a ADD COLUMN b varchar(20);\nINSERT INTO a (id, b) VALUES (1, 'x');")},
+		"2_add_b.down.sql":    {Data: []byte("ALTER TABLE a DROP COLUMN b;")},
+	}
+	m := &Migrator{db: db, dialect: Sqlite, modules: []Module{{Name: "test", FS: scripts}}}
+	ctx := context.Background()
+
+	if err := m.Check(ctx); !errors.Is(err, ErrBehind) {
+		t.Fatalf("未迁移时 Check() = %v, want ErrBehind", err)
+	}
+	done, err := m.Up(ctx)
+	if err != nil || len(done) != 2 {
+		t.Fatalf("Up() = %v, %v", done, err)
+	}
+	if err = m.Check(ctx); err != nil {
+		t.Fatalf("迁移后 Check() = %v", err)
+	}
+	if !db.Migrator().HasColumn("a", "b") {
+		t.Fatal("迁移未执行")
+	}
+
+	done, err = m.Down(ctx, "", 1)
+	if err != nil || len(done) != 1 || done[0].Version != "2" {
+		t.Fatalf("Down() = %v, %v", done, err)
+	}
+	if db.Migrator().HasColumn("a", "b") {
+		t.Fatal("回退未执行")
+	}
+	if err = m.Check(ctx); !errors.Is(err, ErrBehind) {
+		t.Fatalf("回退后 Check() = %v, want ErrBehind", err)
+	}
+	if _, err = m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	scripts["1_create_a.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id bigint);")}
+	states, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if states[0].Status != StatusModified {
+		t.Errorf("修改已执行的脚本后状态为 %s, want %s", states[0].Status, StatusModified)
+	}
+	if _, err = m.Up(ctx); !errors.Is(err, ErrBehind) {
+		t.Errorf("脚本被修改后 Up() = %v, want ErrBehind", err)
+	}
+}
+
+func TestMigratorBaseline(t *testing.T) {
+	db := testdb.Open(t, "")
+	module := Module{
+		Name: "test",
+		FS: fstest.MapFS{
+			"1_add_b.up.sql":   {Data: []byte("ALTER TABLE a ADD COLUMN b varchar(20);")},
+			"1_add_b.down.sql": {Data: []byte("ALTER TABLE a DROP COLUMN b;")},
+		},
+		Schema: func(db *gorm.DB) error {
+			return db.Exec("CREATE TABLE a (id integer, b varchar(20))").Error
+		},
+	}
+	m := &Migrator{db: db, dialect: Sqlite, modules: []Module{module}}
+	ctx := context.Background()
+
+	done, err := m.Up(ctx)
+	if err != nil || len(done) != 1 || done[0].Version != BaselineVersion {
+		t.Fatalf("Up() = %v, %v", done, err)
+	}
+	states, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, state := range states {
+		if state.Status != StatusApplied {
+			t.Errorf("基线后 %s 的状态为 %s", state.Version, state.Status)
+		}
+	}
+	if _, err = m.Down(ctx, "test", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Down(ctx, "test", 1); err == nil {
+		t.Error("基线不应可回退")
+	}
+}
@@ -0,0 +1,334 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	model "github.com/flipped-aurora/gin-vue-admin/server/model/system"
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/replica"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusApplied  = "applied"  // 已执行
+	StatusPending  = "pending"  // 未执行
+	StatusModified = "modified" // 执行后脚本被修改
+	StatusMissing  = "missing"  // 已执行但脚本不存在 通常是数据库被更新版本的程序迁移过
+)
+
+// ErrBehind 数据库结构落后于当前程序
+var ErrBehind = errors.New("数据库结构落后于当前版本")
+
+// State 迁移的执行状态
+type State struct {
+	Module    string     `json:"module"`
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	AppliedAt *time.Time `json:"appliedAt"`
+}
+
+// Migrator 在一个数据库上执行已注册模块的迁移
+type Migrator struct {
+	db      *gorm.DB
+	dialect string
+	modules []Module
+}
+
+// New 创建迁移器 dialect 为配置中的 db-type 为空时按 mysql 处理
+func New(db *gorm.DB, dialect string) *Migrator {
+	if dialect == "" {
+		dialect = Mysql
+	}
+	return &Migrator{db: db, dialect: dialect, modules: Modules()}
+}
+
+// Status 所有模块的迁移状态 按模块执行顺序与版本排列
+func (m *Migrator) Status(ctx context.Context) ([]State, error) {
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var states []State
+	for _, module := range m.modules {
+		migrations, err := Load(module, m.dialect)
+		if err != nil {
+			return nil, err
+		}
+		applied := make(map[string]model.SysMigration)
+		for _, record := range records {
+			if record.Module == module.Name {
+				applied[record.Version] = record
+			}
+		}
+		if module.Schema != nil {
+			state := State{Module: module.Name, Version: BaselineVersion, Name: "baseline", Status: StatusPending}
+			if record, ok := applied[BaselineVersion]; ok {
+				state.Status, state.AppliedAt = StatusApplied, &record.AppliedAt
+			}
+			states = append(states, state)
+		}
+		known := make(map[string]bool, len(migrations))
+		for _, migration := range migrations {
+			known[migration.Version] = true
+			state := State{Module: module.Name, Version: migration.Version, Name: migration.Name, Status: StatusPending}
+			if record, ok := applied[migration.Version]; ok {
+				state.Status, state.AppliedAt = StatusApplied, &record.AppliedAt
+				if record.Checksum != migration.Checksum {
+					state.Status = StatusModified
+				}
+			}
+			states = append(states, state)
+		}
+		for _, record := range records {
+			if record.Module == module.Name && record.Version != BaselineVersion && !known[record.Version] {
+				appliedAt := record.AppliedAt
+				states = append(states, State{Module: module.Name, Version: record.Version, Name: record.Name, Status: StatusMissing, AppliedAt: &appliedAt})
+			}
+		}
+	}
+	return states, nil
+}
+
+// Check 存在未执行或执行后被修改的迁移时返回 ErrBehind
+func (m *Migrator) Check(ctx context.Context) error {
+	states, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return Behind(states)
+}
+
+// Behind 根据迁移状态判断数据库结构是否落后
+func Behind(states []State) error {
+	var pending, modified []string
+	for _, state := range states {
+		switch state.Status {
+		case StatusPending:
+			pending = append(pending, state.Module+":"+state.Version+"_"+state.Name)
+		case StatusModified:
+			modified = append(modified, state.Module+":"+state.Version+"_"+state.Name)
+		}
+	}
+	if len(modified) > 0 {
+		return errors.Wrapf(ErrBehind, "已执行的迁移脚本被修改: %s", strings.Join(modified, ", "))
+	}
+	if len(pending) > 0 {
+		return errors.Wrapf(ErrBehind, "存在未执行的迁移: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up 按模块顺序执行所有未执行的迁移 返回执行了的迁移
+func (m *Migrator) Up(ctx context.Context) ([]State, error) {
+	states, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		if state.Status == StatusModified {
+			return nil, Behind([]State{state})
+		} // 不在被修改过的历史上继续迁移
+	}
+	if err = m.session(ctx).AutoMigrate(&model.SysMigration{}); err != nil {
+		return nil, errors.Wrap(err, "创建迁移记录表失败!")
+	}
+	var done []State
+	for _, module := range m.modules {
+		migrations, err := Load(module, m.dialect)
+		if err != nil {
+			return done, err
+		}
+		records, err := m.records(ctx)
+		if err != nil {
+			return done, err
+		}
+		applied := make(map[string]bool)
+		for _, record := range records {
+			if record.Module == module.Name {
+				applied[record.Version] = true
+			}
+		}
+		if module.Schema != nil && !applied[BaselineVersion] {
+			if err = m.baseline(ctx, module, migrations); err != nil {
+				return done, err
+			}
+			done = append(done, State{Module: module.Name, Version: BaselineVersion, Name: "baseline", Status: StatusApplied})
+			continue
+		}
+		for _, migration := range migrations {
+			if applied[migration.Version] {
+				continue
+			}
+			if err = m.run(ctx, migration); err != nil {
+				return done, err
+			}
+			done = append(done, State{Module: module.Name, Version: migration.Version, Name: migration.Name, Status: StatusApplied})
+		}
+	}
+	return done, nil
+}
+
+// Down 按执行的逆序回退 steps 个迁移 module 不为空时只回退该模块 基线不可回退
+func (m *Migrator) Down(ctx context.Context, module string, steps int) ([]State, error) {
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	migrations := make(map[string]Migration)
+	for _, mod := range m.modules {
+		list, err := Load(mod, m.dialect)
+		if err != nil {
+			return nil, err
+		}
+		for _, migration := range list {
+			migrations[mod.Name+":"+migration.Version] = migration
+		}
+	}
+	var done []State
+	for i := len(records) - 1; i >= 0 && len(done) < steps; i-- {
+		record := records[i]
+		if module != "" && record.Module != module {
+			continue
+		}
+		if record.Version == BaselineVersion {
+			if len(done) == 0 {
+				return nil, fmt.Errorf("[%s]最近的迁移为基线 基线不可回退", record.Module)
+			}
+			break
+		}
+		migration, ok := migrations[record.Module+":"+record.Version]
+		if !ok {
+			return done, fmt.Errorf("[%s]%s_%s 的迁移脚本不存在 无法回退", record.Module, record.Version, record.Name)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return done, fmt.Errorf("[%s]%s_%s 没有 %s 方言的回退脚本 无法回退", record.Module, record.Version, record.Name, m.dialect)
+		}
+		err = m.session(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := m.exec(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&model.SysMigration{}, "id = ?", record.ID).Error
+		})
+		if err != nil {
+			return done, errors.Wrapf(err, "[%s]%s_%s 回退失败!", record.Module, record.Version, record.Name)
+		}
+		done = append(done, State{Module: record.Module, Version: record.Version, Name: record.Name, Status: StatusPending})
+	}
+	return done, nil
+}
+
+var migrationName = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create 在模块的迁移目录下创建新的迁移脚本 root 为 server 目录 dialects 为空时创建通用脚本
+func Create(root string, module string, name string, dialects []string, now time.Time) ([]string, error) {
+	var target *Module
+	for _, m := range Modules() {
+		if m.Name == module {
+			target = &m
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("迁移模块 %s 不存在", module)
+	}
+	if target.Dir == "" {
+		return nil, fmt.Errorf("迁移模块 %s 未设置迁移目录", module)
+	}
+	name = strings.Trim(migrationName.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("迁移名称不能为空")
+	}
+	for _, dialect := range dialects {
+		if !contains(Dialects, dialect) {
+			return nil, fmt.Errorf("不支持的方言 %s", dialect)
+		}
+	}
+	if len(dialects) == 0 {
+		dialects = []string{""}
+	}
+	dir := filepath.Join(root, target.Dir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	version := now.Format("20060102150405")
+	var files []string
+	for _, dialect := range dialects {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, File(version, name, direction, dialect))
+			content := fmt.Sprintf("-- %s %s %s\n", module, name, direction)
+			if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+				return files, err
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// baseline 按当前模型建表 并把已有的迁移记为已执行
+func (m *Migrator) baseline(ctx context.Context, module Module, migrations []Migration) error {
+	start := time.Now()
+	err := m.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := module.Schema(tx); err != nil {
+			return err
+		}
+		records := []model.SysMigration{{Module: module.Name, Version: BaselineVersion, Name: "baseline", AppliedAt: start}}
+		for _, migration := range migrations {
+			records = append(records, model.SysMigration{Module: module.Name, Version: migration.Version, Name: migration.Name, Checksum: migration.Checksum, AppliedAt: start})
+		}
+		records[0].Duration = time.Since(start).Milliseconds()
+		return tx.Create(&records).Error
+	})
+	return errors.Wrapf(err, "[%s]建立迁移基线失败!", module.Name)
+}
+
+// run 在事务中执行迁移并记录 mysql 与 oracle 的 DDL 会隐式提交 失败时需要手动修复
+func (m *Migrator) run(ctx context.Context, migration Migration) error {
+	start := time.Now()
+	err := m.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := m.exec(tx, migration.Up); err != nil {
+			return err
+		}
+		return tx.Create(&model.SysMigration{
+			Module:    migration.Module,
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			Duration:  time.Since(start).Milliseconds(),
+			AppliedAt: start,
+		}).Error
+	})
+	return errors.Wrapf(err, "[%s]%s_%s 迁移失败!", migration.Module, migration.Version, migration.Name)
+}
+
+func (m *Migrator) exec(tx *gorm.DB, script string) error {
+	for _, statement := range Statements(script, m.dialect) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return errors.Wrap(err, statement)
+		}
+	}
+	return nil
+}
+
+// session 迁移记录在执行后立即读取 始终使用主库
+func (m *Migrator) session(ctx context.Context) *gorm.DB {
+	return m.db.WithContext(replica.UsePrimary(ctx))
+}
+
+// records 按执行顺序排列的迁移记录 记录表不存在时为空
+func (m *Migrator) records(ctx context.Context) ([]model.SysMigration, error) {
+	db := m.session(ctx)
+	if !db.Migrator().HasTable(&model.SysMigration{}) {
+		return nil, nil
+	}
+	var records []model.SysMigration
+	err := db.Order("id").Find(&records).Error
+	return records, errors.Wrap(err, "查询迁移记录失败!")
+}
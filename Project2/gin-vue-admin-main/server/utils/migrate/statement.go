@@ -0,0 +1,64 @@
+package migrate
+
+import (
+	"strings"
+)
+
+// Statements 按语句末尾的分号拆分脚本 忽略注释 引号与反引号中的分号不拆分
+// mysql 驱动默认不支持一次执行多条语句 oracle 不接受语句末尾的分号 因此逐条执行
+// 不支持包含分号的存储过程或触发器语句块 以及 mssql 的 GO 批处理分隔符
+// dialect 为 mysql 时引号中的反斜杠为转义符 如 'it\'s'
+func Statements(script string, dialect string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+	)
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == '\\' && dialect == Mysql && quote != '`' && next != 0 {
+				current.WriteRune(next)
+				i++
+			} else if r == quote {
+				if next == quote {
+					current.WriteRune(next)
+					i++ // 连续两个引号为转义
+				} else {
+					quote = 0
+				}
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == '-' && next == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case r == '/' && next == '*':
+			for i += 2; i < len(runes) && !(runes[i-1] == '*' && runes[i] == '/'); i++ {
+			}
+			current.WriteRune(' ')
+		case r == ';':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,422 @@
+// Package openapi 构建 OpenAPI 3.1 文档 并根据文档校验请求
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	Version = "3.1.0"
+	// SecurityApiKey 与 swag 注释中的 ApiKeyAuth 保持一致
+	SecurityApiKey = "ApiKeyAuth"
+	// ResponseSchema 统一响应结构 response.Response 在文档中的名称
+	ResponseSchema = "response.Response"
+)
+
+// Types 类型列表 单个类型序列化为字符串 可为空时序列化为 ["integer","null"]
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// Has 是否包含类型
+func (t Types) Has(name string) bool {
+	for _, item := range t {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// Ref 引用 components 中的结构
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // query|path|header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Secure 标记需要 x-token 鉴权
+func (o *Operation) Secure() {
+	o.Security = []map[string][]string{{SecurityApiKey: {}}}
+}
+
+// Body 设置 JSON 请求体
+func (o *Operation) Body(schema *Schema) {
+	o.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+// Data 设置成功响应 data 字段的结构
+func (o *Operation) Data(schema *Schema) {
+	envelope := Ref(ResponseSchema)
+	if schema != nil {
+		envelope = &Schema{AllOf: []*Schema{envelope, {Type: Types{"object"}, Properties: map[string]*Schema{"data": schema}}}}
+	}
+	o.Responses["200"] = &Response{Description: "OK", Content: map[string]*MediaType{"application/json": {Schema: envelope}}}
+}
+
+// Param 添加参数 已存在同名参数时替换
+func (o *Operation) Param(param *Parameter) {
+	for i := range o.Parameters {
+		if o.Parameters[i].Name == param.Name && o.Parameters[i].In == param.In {
+			o.Parameters[i] = param
+			return
+		}
+	}
+	o.Parameters = append(o.Parameters, param)
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+// Document OpenAPI 文档 Paths 的键为 OpenAPI 路径 值为小写请求方法到操作的映射
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// New 创建文档 预置统一响应结构与 x-token 鉴权方式
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: map[string]*Schema{
+				ResponseSchema: {
+					Type: Types{"object"},
+					Properties: map[string]*Schema{
+						"code": {Type: Types{"integer"}, Description: "0 成功 7 失败"},
+						"data": {},
+						"msg":  {Type: Types{"string"}},
+					},
+				},
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				SecurityApiKey: {Type: "apiKey", In: "header", Name: "x-token"},
+			},
+		},
+	}
+}
+
+// Operation 获取或创建操作 path 可以使用 gin 的路由格式 如 /user/:id
+func (d *Document) Operation(method, path string) *Operation {
+	path, params := Path(path)
+	method = strings.ToLower(method)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(map[string]*Operation)
+		d.Paths[path] = item
+	}
+	operation, ok := item[method]
+	if !ok {
+		operation = &Operation{Responses: make(map[string]*Response)}
+		operation.Data(nil)
+		for _, param := range params {
+			operation.Param(&Parameter{Name: param, In: "path", Required: true, Schema: &Schema{Type: Types{"string"}}})
+		}
+		item[method] = operation
+	}
+	return operation
+}
+
+// Find 查找操作 path 可以使用 gin 的路由格式 不存在返回 nil
+func (d *Document) Find(method, path string) *Operation {
+	path, _ = Path(path)
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// Resolve 解析 $ref 引用
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// Path 将 gin 路由转换为 OpenAPI 路径 返回路径与路径参数
+func Path(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+	marshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// SchemaOf 根据 Go 类型生成结构 具名结构体注册到 components 并返回引用
+// 字段名取 json 标签 binding:"required" 的字段为必填
+func (d *Document) SchemaOf(value interface{}) *Schema {
+	if value == nil {
+		return nil
+	}
+	return d.schemaOf(reflect.TypeOf(value))
+}
+
+func (d *Document) schemaOf(typ reflect.Type) *Schema {
+	if typ.Kind() == reflect.Pointer {
+		schema := d.schemaOf(typ.Elem())
+		if schema.Ref == "" && len(schema.Type) == 1 {
+			schema.Type = append(schema.Type, "null")
+		}
+		return schema
+	}
+	switch {
+	case typ == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case typ == rawJSONType:
+		return &Schema{}
+	case typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8 && typ.Len() == 16:
+		return &Schema{Type: Types{"string"}, Format: "uuid"}
+	case typ.Implements(marshaler) || reflect.PointerTo(typ).Implements(marshaler):
+		return &Schema{} // 如 datatypes.JSON 自定义序列化 无法推断结构
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}, Minimum: new(float64)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: d.schemaOf(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: d.schemaOf(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return d.structOf(typ)
+		}
+		name := strings.ReplaceAll(typ.String(), " ", "")
+		if _, ok := d.Components.Schemas[name]; !ok {
+			d.Components.Schemas[name] = &Schema{} // 先占位 避免循环引用时无限递归
+			d.Components.Schemas[name] = d.structOf(typ)
+		}
+		return Ref(name)
+	}
+	return &Schema{}
+}
+
+func (d *Document) structOf(typ reflect.Type) *Schema {
+	schema := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		elem := field.Type
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if field.Anonymous && name == "" && elem.Kind() == reflect.Struct && elem != timeType {
+			embedded := d.structOf(elem) // 匿名嵌入的字段展开到当前结构
+			for key, value := range embedded.Properties {
+				schema.Properties[key] = value
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := d.schemaOf(field.Type)
+		if description := field.Tag.Get("example"); description != "" && property.Ref == "" {
+			property.Description = description
+		}
+		schema.Properties[name] = property
+		if Required(field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// Required binding 标签是否包含 required
+func Required(binding string) bool {
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// Query 根据查询参数结构体的 form 标签生成参数
+func (d *Document) Query(value interface{}) []*Parameter {
+	typ := reflect.TypeOf(value)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return d.query(typ)
+}
+
+func (d *Document) query(typ reflect.Type) []*Parameter {
+	var params []*Parameter
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+		elem := field.Type
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if field.Anonymous && name == "" && elem.Kind() == reflect.Struct {
+			params = append(params, d.query(elem)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		schema := d.schemaOf(field.Type)
+		if schema.Ref != "" {
+			continue // 查询参数不支持嵌套结构
+		}
+		params = append(params, &Parameter{Name: name, In: "query", Required: Required(field.Tag.Get("binding")), Schema: schema})
+	}
+	return params
+}
+
+// Binding 手写接口的请求与响应结构 用于补充文档
+type Binding struct {
+	Body     interface{} // JSON 请求体
+	Query    interface{} // 查询参数结构体
+	Response interface{} // 成功响应 data 字段
+}
+
+var (
+	bindingMu sync.RWMutex
+	bindings  = make(map[string]Binding)
+)
+
+// Bind 登记接口的请求与响应结构 path 为不含路由前缀的 gin 路由
+func Bind(method, path string, binding Binding) {
+	bindingMu.Lock()
+	defer bindingMu.Unlock()
+	bindings[strings.ToUpper(method)+" "+path] = binding
+}
+
+// Apply 将登记的结构写入文档 prefix 为路由前缀
+func (d *Document) Apply(prefix string) {
+	bindingMu.RLock()
+	defer bindingMu.RUnlock()
+	for key, binding := range bindings {
+		method, path, _ := strings.Cut(key, " ")
+		operation := d.Operation(method, prefix+path)
+		if binding.Body != nil {
+			operation.Body(d.SchemaOf(binding.Body))
+		}
+		if binding.Query != nil {
+			for _, param := range d.Query(binding.Query) {
+				operation.Param(param)
+			}
+		}
+		if binding.Response != nil {
+			operation.Data(d.SchemaOf(binding.Response))
+		}
+	}
+}
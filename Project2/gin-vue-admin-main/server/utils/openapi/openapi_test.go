@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testModel struct {
+	ID      uint       `json:"ID"`
+	Name    string     `json:"name" binding:"required"`
+	Age     *int       `json:"age"`
+	Tags    []string   `json:"tags"`
+	Born    *time.Time `json:"born"`
+	Parent  *testModel `json:"parent"`
+	Ignored string     `json:"-"`
+}
+
+type testSearch struct {
+	testPage
+	Name string `form:"name" binding:"required"`
+}
+
+type testPage struct {
+	Page int `form:"page"`
+}
+
+func decode(t *testing.T, body string) interface{} {
+	t.Helper()
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewBufferString(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestDocument_SchemaOf(t *testing.T) {
+	doc := New("test", "v1")
+	ref := doc.SchemaOf(testModel{})
+	if ref.Ref != "#/components/schemas/openapi.testModel" {
+		t.Fatalf("SchemaOf() ref = %q", ref.Ref)
+	}
+	schema := doc.Resolve(ref)
+	if _, ok := schema.Properties["Ignored"]; ok {
+		t.Error("json:\"-\" 的字段不应出现在结构中")
+	}
+	if got := schema.Properties["age"].Type; len(got) != 2 || !got.Has("null") {
+		t.Errorf("指针字段 type = %v, want [integer null]", got)
+	}
+	if got := schema.Properties["parent"].Ref; got != ref.Ref {
+		t.Errorf("循环引用 ref = %q, want %q", got, ref.Ref)
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "name" {
+		t.Errorf("required = %v, want [name]", schema.Required)
+	}
+	if path, params := Path("/user/:id/*file"); path != "/user/{id}/{file}" || len(params) != 2 {
+		t.Errorf("Path() = %s %v", path, params)
+	}
+}
+
+func TestDocument_Validate(t *testing.T) {
+	doc := New("test", "v1")
+	schema := doc.SchemaOf(testModel{})
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "合法", body: `{"name":"a","age":1,"tags":["x"],"born":"2024-01-01T00:00:00Z","parent":{"name":"b"}}`},
+		{name: "可为空的字段传null", body: `{"name":"a","age":null}`},
+		{name: "缺少必填字段", body: `{"age":1}`, wantErr: true},
+		{name: "类型错误", body: `{"name":"a","age":"1"}`, wantErr: true},
+		{name: "整数传小数", body: `{"name":"a","age":1.5}`, wantErr: true},
+		{name: "无符号整数传负数", body: `{"name":"a","ID":-1}`, wantErr: true},
+		{name: "数组元素类型错误", body: `{"name":"a","tags":[1]}`, wantErr: true},
+		{name: "时间格式错误", body: `{"name":"a","born":"2024-01-01"}`, wantErr: true},
+		{name: "嵌套结构缺少必填字段", body: `{"name":"a","parent":{}}`, wantErr: true},
+		{name: "请求体不是对象", body: `[]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := doc.Validate(schema, decode(t, tt.body)); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDocument_ValidateQuery(t *testing.T) {
+	doc := New("test", "v1")
+	operation := doc.Operation("GET", "/test/list")
+	for _, param := range doc.Query(testSearch{}) {
+		operation.Param(param)
+	}
+	if found := doc.Find("get", "/test/list"); found != operation {
+		t.Fatal("Find() 未找到操作")
+	}
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "合法", query: "name=a&page=1"},
+		{name: "空的数字参数视为未传", query: "name=a&page="},
+		{name: "缺少必填参数", query: "page=1", wantErr: true},
+		{name: "数字参数类型错误", query: "name=a&page=abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			if err := doc.ValidateQuery(operation, query); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
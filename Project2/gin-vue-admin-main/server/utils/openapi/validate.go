@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validate 校验 JSON 解码后的值是否符合结构 解码时需使用 UseNumber 以区分整数与小数
+func (d *Document) Validate(schema *Schema, value interface{}) error {
+	return d.validate(schema, value, "")
+}
+
+// ValidateQuery 校验查询参数
+func (d *Document) ValidateQuery(operation *Operation, query url.Values) error {
+	for _, param := range operation.Parameters {
+		if param.In != "query" {
+			continue
+		}
+		values := query[param.Name]
+		if len(values) == 0 {
+			if param.Required {
+				return validateError(param.Name, "必填")
+			}
+			continue
+		}
+		schema := d.Resolve(param.Schema)
+		if schema == nil {
+			continue
+		}
+		if schema.Type.Has("array") {
+			schema = d.Resolve(schema.Items)
+		}
+		for _, raw := range values {
+			value, ok := queryValue(schema, raw)
+			if !ok {
+				continue
+			}
+			if err := d.validate(schema, value, param.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// queryValue 按结构的类型转换查询参数 非字符串类型的空值与 gin 绑定一致视为未传
+func queryValue(schema *Schema, raw string) (interface{}, bool) {
+	if schema == nil || schema.Type.Has("string") || len(schema.Type) == 0 {
+		return raw, true
+	}
+	if raw == "" {
+		return nil, false
+	}
+	if schema.Type.Has("integer") || schema.Type.Has("number") {
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw), true
+		}
+	}
+	if schema.Type.Has("boolean") {
+		if value, err := strconv.ParseBool(raw); err == nil {
+			return value, true
+		}
+	}
+	return raw, true
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, path string) error {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return nil
+	}
+	for _, item := range schema.AllOf {
+		if err := d.validate(item, value, path); err != nil {
+			return err
+		}
+	}
+	if value == nil {
+		if len(schema.Type) == 0 || schema.Type.Has("null") {
+			return nil
+		}
+		return validateError(path, "不能为空")
+	}
+	actual := typeOf(value)
+	if len(schema.Type) > 0 && !schema.Type.Has(actual) && !(actual == "integer" && schema.Type.Has("number")) {
+		return validateError(path, "类型应为"+strings.Join(schema.Type, "|"))
+	}
+	if len(schema.Enum) > 0 {
+		matched := false
+		for _, item := range schema.Enum {
+			if fmt.Sprint(item) == fmt.Sprint(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return validateError(path, fmt.Sprintf("取值应为%v之一", schema.Enum))
+		}
+	}
+	switch value := value.(type) {
+	case string:
+		if schema.MaxLength != nil && utf8.RuneCountInString(value) > *schema.MaxLength {
+			return validateError(path, fmt.Sprintf("长度不能超过%d", *schema.MaxLength))
+		}
+		if schema.Format == "date-time" && value != "" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return validateError(path, "时间格式应为RFC3339")
+			}
+		}
+	case json.Number:
+		if number, err := value.Float64(); err == nil && schema.Minimum != nil && number < *schema.Minimum {
+			return validateError(path, fmt.Sprintf("不能小于%v", *schema.Minimum))
+		}
+	case []interface{}:
+		for i, item := range value {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if item, ok := value[name]; !ok || item == nil {
+				return validateError(join(path, name), "必填")
+			}
+		}
+		for name, item := range value {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if err := d.validate(property, item, join(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func typeOf(value interface{}) string {
+	switch value := value.(type) {
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(value.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case float64:
+		if value == float64(int64(value)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func validateError(path, message string) error {
+	if path == "" {
+		path = "请求体"
+	}
+	return fmt.Errorf("%s: %s", path, message)
+}
@@ -0,0 +1,48 @@
+package remote
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AuthTokenKey 由服务端注入的环境变量 每次启动随机生成, 插件只接受携带该令牌的调用
+	AuthTokenKey = "GVA_PLUGIN_AUTH_TOKEN"
+	// authMetadataKey 调用时携带令牌的 metadata 键
+	authMetadataKey = "gva-plugin-token"
+)
+
+// newAuthToken 生成本次启动使用的令牌
+func newAuthToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// serverAuth 校验调用方携带的令牌 本机其他进程无法伪造当前用户调用插件
+func serverAuth(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(authMetadataKey)
+		if len(values) != 1 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid plugin token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// clientAuth 为每次调用附加令牌
+func clientAuth(token string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(metadata.AppendToOutgoingContext(ctx, authMetadataKey, token), method, req, reply, cc, opts...)
+	}
+}
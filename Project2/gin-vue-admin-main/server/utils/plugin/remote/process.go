@@ -0,0 +1,179 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote/pb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// ProtocolVersion 插件协议版本 握手时校验
+	ProtocolVersion = 1
+	// HandshakePrefix 插件启动后向标准输出打印的握手行前缀 格式为 GVA-PLUGIN|协议版本|监听地址
+	HandshakePrefix = "GVA-PLUGIN"
+	// MagicCookieKey 由服务端注入的环境变量 用于区分插件是否由服务端启动
+	MagicCookieKey   = "GVA_PLUGIN_MAGIC_COOKIE"
+	MagicCookieValue = "d3c6b1f0-gva-plugin"
+	// MaxMessageSize gRPC 消息大小上限 需大于服务端代理请求体的上限
+	MaxMessageSize = 64 << 20
+)
+
+// Process 进程外插件 由服务端启动并通过 gRPC 通信
+type Process struct {
+	Path string
+	Args []string
+	Env  []string
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	conn   *grpc.ClientConn
+	client pb.PluginClient
+	done   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// Start 启动插件进程 等待握手完成后建立 gRPC 连接
+func (p *Process) Start(ctx context.Context, timeout time.Duration) error {
+	token, err := newAuthToken()
+	if err != nil {
+		return errors.Wrap(err, "生成插件令牌失败!")
+	}
+	cmd := exec.Command(p.Path, p.Args...)
+	cmd.Env = append(append(os.Environ(), p.Env...), MagicCookieKey+"="+MagicCookieValue, AuthTokenKey+"="+token)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	// 插件在标准输入关闭时退出 服务端异常退出后不会遗留插件进程
+	p.stdin, err = cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return errors.Wrapf(err, "[%s]启动插件进程失败!", p.Path)
+	}
+	p.cmd = cmd
+	p.done = make(chan struct{})
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+
+	lines := make(chan string, 1)
+	reader := bufio.NewReader(stdout)
+	go func() {
+		line, _ := reader.ReadString('\n')
+		lines <- strings.TrimSpace(line)
+		// 握手之后的输出原样转发
+		_, _ = io.Copy(os.Stdout, reader)
+	}()
+
+	var addr string
+	select {
+	case line := <-lines:
+		addr, err = parseHandshake(line)
+	case <-p.done:
+		err = errors.Errorf("插件进程已退出: %v", p.err)
+	case <-time.After(timeout):
+		err = errors.New("等待插件握手超时!")
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		p.Kill()
+		return errors.Wrapf(err, "[%s]插件握手失败!", p.Path)
+	}
+
+	p.conn, err = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(clientAuth(token)),
+		grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(MaxMessageSize), grpc.MaxCallRecvMsgSize(MaxMessageSize)))
+	if err != nil {
+		p.Kill()
+		return errors.Wrapf(err, "[%s]连接插件失败!", p.Path)
+	}
+	p.client = pb.NewPluginClient(p.conn)
+	return nil
+}
+
+// Client 插件 gRPC 客户端
+func (p *Process) Client() pb.PluginClient {
+	return p.client
+}
+
+// Done 插件进程退出时关闭
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err 插件进程的退出原因
+func (p *Process) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+// Stop 通知插件退出 超时后强制结束
+func (p *Process) Stop(timeout time.Duration) {
+	if p.cmd == nil || p.cmd.Process == nil {
+		return
+	}
+	p.closeConn()
+	_ = p.cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-p.done:
+	case <-time.After(timeout):
+		p.Kill()
+	}
+}
+
+// Kill 强制结束插件进程
+func (p *Process) Kill() {
+	if p.cmd == nil || p.cmd.Process == nil {
+		return
+	}
+	p.closeConn()
+	_ = p.cmd.Process.Kill()
+	<-p.done
+}
+
+func (p *Process) closeConn() {
+	p.once.Do(func() {
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+		_ = p.stdin.Close()
+	})
+}
+
+func parseHandshake(line string) (string, error) {
+	parts := strings.Split(line, "|")
+	if len(parts) != 3 || parts[0] != HandshakePrefix {
+		return "", errors.Errorf("无法识别的握手信息: %q", line)
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version != ProtocolVersion {
+		return "", errors.Errorf("插件协议版本不兼容: %s, 服务端为 %d", parts[1], ProtocolVersion)
+	}
+	return parts[2], nil
+}
+
+func handshake(addr string) string {
+	return fmt.Sprintf("%s|%d|%s", HandshakePrefix, ProtocolVersion, addr)
+}
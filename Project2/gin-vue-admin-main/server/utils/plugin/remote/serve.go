@@ -0,0 +1,154 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote/pb"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// Serve 在插件可执行文件的 main 中调用 监听本地随机端口并完成握手, 收到退出信号后优雅停止
+// 只接受携带服务端启动时注入的令牌的调用
+func Serve(server pb.PluginServer) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return errors.New("该程序为gin-vue-admin插件, 需在服务端配置 plugin-host 后由服务端启动!")
+	}
+	token := os.Getenv(AuthTokenKey)
+	if token == "" {
+		return errors.New("未获取到插件令牌, 请升级服务端!")
+	}
+	_ = os.Unsetenv(AuthTokenKey) // 插件启动的子进程不继承令牌
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(serverAuth(token)), grpc.MaxRecvMsgSize(MaxMessageSize), grpc.MaxSendMsgSize(MaxMessageSize))
+	pb.RegisterPluginServer(s, server)
+	fmt.Println(handshake(lis.Addr().String()))
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		quit <- syscall.SIGTERM
+	}()
+	go func() {
+		<-quit
+		s.GracefulStop()
+	}()
+	return s.Serve(lis)
+}
+
+// HTTPPlugin 将 http.Handler 适配为进程外插件 服务端转发的请求交由 Handler 处理
+type HTTPPlugin struct {
+	pb.UnimplementedPluginServer
+	Info        *pb.DescribeResponse
+	Handler     http.Handler
+	OnConfigure func(ctx context.Context, config json.RawMessage) error
+	OnHealth    func(ctx context.Context) error
+}
+
+func (p *HTTPPlugin) Describe(ctx context.Context, req *pb.DescribeRequest) (*pb.DescribeResponse, error) {
+	return p.Info, nil
+}
+
+func (p *HTTPPlugin) Configure(ctx context.Context, req *pb.ConfigureRequest) (*pb.ConfigureResponse, error) {
+	if p.OnConfigure != nil {
+		if err := p.OnConfigure(ctx, req.Config); err != nil {
+			return nil, err
+		}
+	}
+	return &pb.ConfigureResponse{}, nil
+}
+
+func (p *HTTPPlugin) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
+	if p.OnHealth != nil {
+		if err := p.OnHealth(ctx); err != nil {
+			return &pb.HealthResponse{Ok: false, Message: err.Error()}, nil
+		}
+	}
+	return &pb.HealthResponse{Ok: true}, nil
+}
+
+func (p *HTTPPlugin) Handle(ctx context.Context, req *pb.HttpRequest) (*pb.HttpResponse, error) {
+	url := req.Path
+	if req.Query != "" {
+		url += "?" + req.Query
+	}
+	r, err := http.NewRequestWithContext(ctx, req.Method, url, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range req.Headers {
+		r.Header[header.Key] = header.Values
+	}
+	r.RemoteAddr = req.RemoteAddr
+	if req.User != nil {
+		r = r.WithContext(context.WithValue(r.Context(), userKey{}, req.User))
+	}
+	w := &responseRecorder{header: make(http.Header)}
+	p.Handler.ServeHTTP(w, r)
+	resp := &pb.HttpResponse{Status: int32(w.status), Body: w.body.Bytes()}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	for key, values := range w.header {
+		resp.Headers = append(resp.Headers, &pb.Header{Key: key, Values: values})
+	}
+	return resp, nil
+}
+
+type userKey struct{}
+
+// User 获取服务端转发的当前用户 公开路由返回 nil
+func User(r *http.Request) *pb.User {
+	user, _ := r.Context().Value(userKey{}).(*pb.User)
+	return user
+}
+
+// Routes 将 gin 注册的路由转换为需要代理的路由 public 中的路由无需鉴权
+func Routes(routes gin.RoutesInfo, public ...string) []*pb.Route {
+	open := make(map[string]bool, len(public))
+	for _, path := range public {
+		open[path] = true
+	}
+	list := make([]*pb.Route, 0, len(routes))
+	for _, route := range routes {
+		list = append(list, &pb.Route{Method: route.Method, Path: route.Path, Public: open[route.Path]})
+	}
+	return list
+}
+
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
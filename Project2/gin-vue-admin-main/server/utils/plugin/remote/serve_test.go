@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/plugin/remote/pb"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPPlugin_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/demo/:id", func(c *gin.Context) {
+		user := User(c.Request)
+		if user == nil {
+			c.String(http.StatusUnauthorized, "no user")
+			return
+		}
+		body, _ := c.GetRawData()
+		c.Header("X-Demo", c.Query("q"))
+		c.String(http.StatusCreated, "%s %s %s", c.Param("id"), user.Username, body)
+	})
+	plugin := &HTTPPlugin{Handler: engine}
+
+	resp, err := plugin.Handle(context.Background(), &pb.HttpRequest{
+		Method: http.MethodPost,
+		Path:   "/demo/7",
+		Query:  "q=1",
+		Body:   []byte("hello"),
+		User:   &pb.User{Id: 1, Username: "admin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != http.StatusCreated || string(resp.Body) != "7 admin hello" {
+		t.Fatalf("unexpected response: %d %s", resp.Status, resp.Body)
+	}
+	var header string
+	for _, h := range resp.Headers {
+		if h.Key == "X-Demo" {
+			header = h.Values[0]
+		}
+	}
+	if header != "1" {
+		t.Fatalf("unexpected header: %q", header)
+	}
+
+	resp, err = plugin.Handle(context.Background(), &pb.HttpRequest{Method: http.MethodPost, Path: "/demo/7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != http.StatusUnauthorized {
+		t.Fatalf("unexpected status: %d", resp.Status)
+	}
+}
+
+func TestParseHandshake(t *testing.T) {
+	tests := []struct {
+		line    string
+		addr    string
+		wantErr bool
+	}{
+		{line: handshake("127.0.0.1:4000"), addr: "127.0.0.1:4000"},
+		{line: "GVA-PLUGIN|2|127.0.0.1:4000", wantErr: true},
+		{line: "listening on 127.0.0.1:4000", wantErr: true},
+	}
+	for _, tt := range tests {
+		addr, err := parseHandshake(tt.line)
+		if (err != nil) != tt.wantErr || addr != tt.addr {
+			t.Errorf("parseHandshake(%q) = %q, %v", tt.line, addr, err)
+		}
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(serverAuth("secret")))
+	pb.RegisterPluginServer(s, &HTTPPlugin{Info: &pb.DescribeResponse{Name: "demo"}})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	tests := []struct {
+		name string
+		opts []grpc.DialOption
+		code codes.Code
+	}{
+		{name: "without token", code: codes.Unauthenticated},
+		{name: "forged token", opts: []grpc.DialOption{grpc.WithUnaryInterceptor(clientAuth("guess"))}, code: codes.Unauthenticated},
+		{name: "launch token", opts: []grpc.DialOption{grpc.WithUnaryInterceptor(clientAuth("secret"))}, code: codes.OK},
+	}
+	for _, tt := range tests {
+		conn, err := grpc.NewClient(lis.Addr().String(), append(tt.opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = pb.NewPluginClient(conn).Describe(context.Background(), &pb.DescribeRequest{})
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.code)
+		}
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,156 @@
+// Package sign 插件包签名 对插件名、版本与包内全部文件的 sha256 做 ed25519 签名
+package sign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SignatureName 签名文件名 位于插件压缩包根目录下
+const SignatureName = "signature.json"
+
+// Signature 插件包签名
+type Signature struct {
+	Name      string            `json:"name"`      // 插件名 多个插件以英文逗号分隔 与插件清单一致
+	Version   string            `json:"version"`   // 插件版本 多个插件以英文逗号分隔 与插件清单一致
+	KeyID     string            `json:"keyId"`     // 签名密钥ID 对应信任库中的公钥
+	Files     map[string]string `json:"files"`     // 文件路径(相对压缩包根目录) => sha256
+	Signature string            `json:"signature"` // base64 编码的 ed25519 签名
+}
+
+// Payload 被签名的内容 每行一项, 文件按路径排序
+func (s *Signature) Payload() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "name:%s\nversion:%s\nkey:%s\n", s.Name, s.Version, s.KeyID)
+	paths := make([]string, 0, len(s.Files))
+	for p := range s.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&b, "%s  %s\n", s.Files[p], p)
+	}
+	return []byte(b.String())
+}
+
+// Sign 使用私钥签名
+func (s *Signature) Sign(key ed25519.PrivateKey) {
+	s.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, s.Payload()))
+}
+
+// Verify 使用公钥校验签名
+func (s *Signature) Verify(key ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return errors.Wrap(err, "签名格式不正确!")
+	}
+	if !ed25519.Verify(key, s.Payload(), sig) {
+		return errors.New("签名校验失败!")
+	}
+	return nil
+}
+
+// Compare 比较签名中的文件与实际文件 缺失、多余或被修改的文件均返回错误
+func (s *Signature) Compare(files map[string]string) error {
+	for p, sum := range files {
+		signed, ok := s.Files[p]
+		if !ok {
+			return errors.Errorf("文件[%s]未签名!", p)
+		}
+		if signed != sum {
+			return errors.Errorf("文件[%s]已被修改!", p)
+		}
+	}
+	for p := range s.Files {
+		if _, ok := files[p]; !ok {
+			return errors.Errorf("文件[%s]缺失!", p)
+		}
+	}
+	return nil
+}
+
+// Digest 计算 dir 下全部文件的 sha256 以 prefix 加相对路径为键写入 files
+// 签名文件本身与 macOS 生成的特殊文件不参与计算
+func Digest(dir string, prefix string, files map[string]string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "__MACOSX" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		key := path.Join(prefix, filepath.ToSlash(rel))
+		if key == SignatureName || d.Name() == ".DS_Store" {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return errors.Errorf("文件[%s]不是普通文件!", key)
+		}
+		sum, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		files[key] = sum
+		return nil
+	})
+}
+
+func hashFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// GenerateKey 生成 base64 编码的密钥对
+func GenerateKey() (publicKey string, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv), nil
+}
+
+// ParsePublicKey 解析 base64 编码的公钥
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("公钥格式不正确!")
+	}
+	return b, nil
+}
+
+// ParsePrivateKey 解析 base64 编码的私钥
+func ParsePrivateKey(key string) (ed25519.PrivateKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(b) != ed25519.PrivateKeySize {
+		return nil, errors.New("私钥格式不正确!")
+	}
+	return b, nil
+}
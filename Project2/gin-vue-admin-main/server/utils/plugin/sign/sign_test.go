@@ -0,0 +1,62 @@
+package sign
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "server", "plugin", "demo"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "server", "plugin", "demo", "plugin.go")
+	if err := os.WriteFile(file, []byte("package demo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	public, private, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, _ := ParsePublicKey(public)
+	privateKey, _ := ParsePrivateKey(private)
+
+	signature := &Signature{Name: "demo", Version: "1.0.0", KeyID: "test", Files: make(map[string]string)}
+	if err = Digest(dir, "", signature.Files); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := signature.Files["server/plugin/demo/plugin.go"]; !ok || len(signature.Files) != 1 {
+		t.Fatalf("unexpected files: %v", signature.Files)
+	}
+	signature.Sign(privateKey)
+	if err = signature.Verify(publicKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// 篡改版本
+	tampered := *signature
+	tampered.Version = "1.0.1"
+	if err = tampered.Verify(publicKey); err == nil {
+		t.Fatal("expected signature error for tampered version")
+	}
+
+	// 篡改文件
+	if err = os.WriteFile(file, []byte("package demo\n\nimport _ \"os/exec\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	if err = Digest(dir, "", files); err != nil {
+		t.Fatal(err)
+	}
+	if err = signature.Compare(files); err == nil {
+		t.Fatal("expected compare error for modified file")
+	}
+
+	// 多余的文件
+	files["server/plugin/demo/extra.go"] = signature.Files["server/plugin/demo/plugin.go"]
+	files["server/plugin/demo/plugin.go"] = signature.Files["server/plugin/demo/plugin.go"]
+	if err = signature.Compare(files); err == nil {
+		t.Fatal("expected compare error for unsigned file")
+	}
+}
@@ -1,11 +1,31 @@
 package plugin
 
 import (
+	"context"
+	"encoding/json"
+
 	"github.com/gin-gonic/gin"
 )
 
 // Plugin 插件模式接口化v2
 type Plugin interface {
+	Lifecycle
+
 	// Register 注册路由
 	Register(group *gin.Engine)
 }
+
+// Lifecycle 插件生命周期钩子 v1插件可按需实现
+type Lifecycle interface {
+	// OnEnable 启用插件时调用 返回错误时保持停用
+	OnEnable(ctx context.Context) error
+
+	// OnDisable 停用插件时调用 停用后插件路由返回404
+	OnDisable(ctx context.Context) error
+
+	// OnConfigChange 启动时及通过接口修改配置时调用 config 为数据库中保存的插件配置
+	OnConfigChange(ctx context.Context, config json.RawMessage) error
+
+	// HealthCheck 健康检查 返回错误表示插件不可用
+	HealthCheck(ctx context.Context) error
+}
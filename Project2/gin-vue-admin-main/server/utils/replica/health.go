@@ -0,0 +1,142 @@
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// node 一个只读副本
+type node struct {
+	name    string
+	db      *gorm.DB
+	pool    gorm.ConnPool
+	healthy atomic.Bool
+	lag     atomic.Int64
+}
+
+// Status 副本的健康状态
+type Status struct {
+	Name    string        `json:"name"`
+	Healthy bool          `json:"healthy"`
+	Lag     time.Duration `json:"lag"`
+}
+
+// Status 各副本的健康状态
+func (r *Resolver) Status() []Status {
+	list := make([]Status, 0, len(r.nodes))
+	for _, n := range r.nodes {
+		list = append(list, Status{Name: n.name, Healthy: n.healthy.Load(), Lag: time.Duration(n.lag.Load())})
+	}
+	return list
+}
+
+func (r *Resolver) watch() {
+	r.check()
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.check()
+		}
+	}
+}
+
+// check 检查各副本 连接失败或复制延迟超过阈值的副本被摘除 恢复后重新加入
+func (r *Resolver) check() {
+	for _, n := range r.nodes {
+		ctx, cancel := context.WithTimeout(context.Background(), r.options.Interval)
+		lag, err := r.lag(ctx, n)
+		cancel()
+		if err == nil && r.options.MaxLag > 0 && lag > r.options.MaxLag {
+			err = fmt.Errorf("复制延迟 %s 超过 %s", lag, r.options.MaxLag)
+		}
+		n.lag.Store(int64(lag))
+		healthy := err == nil
+		if n.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if global.GVA_LOG == nil {
+			continue
+		}
+		if healthy {
+			global.GVA_LOG.Info("只读副本恢复", zap.String("replica", n.name), zap.Duration("lag", lag))
+		} else {
+			global.GVA_LOG.Warn("只读副本已摘除", zap.String("replica", n.name), zap.Error(err))
+		}
+	}
+}
+
+// lag 副本的复制延迟 无法查询延迟的方言只检查连接
+func (r *Resolver) lag(ctx context.Context, n *node) (time.Duration, error) {
+	sqlDB, err := n.db.DB()
+	if err != nil {
+		return 0, err
+	}
+	if err = sqlDB.PingContext(ctx); err != nil {
+		return 0, err
+	}
+	switch r.options.Dialect {
+	case "mysql", "":
+		return mysqlLag(ctx, sqlDB)
+	case "pgsql":
+		var seconds float64
+		err = sqlDB.QueryRowContext(ctx, `SELECT CASE WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END`).Scan(&seconds)
+		return time.Duration(seconds * float64(time.Second)), err
+	default:
+		return 0, nil
+	}
+}
+
+// mysqlLag 读取 SHOW REPLICA STATUS 的 Seconds_Behind_Source 旧版本使用 SHOW SLAVE STATUS
+// 不是复制实例时延迟为0 复制线程停止时返回错误
+func mysqlLag(ctx context.Context, sqlDB *sql.DB) (time.Duration, error) {
+	rows, err := sqlDB.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = sqlDB.QueryContext(ctx, "SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err = rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, errors.New("复制线程未运行")
+		}
+		var seconds int64
+		if _, err = fmt.Sscan(values[i].String, &seconds); err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, nil
+}
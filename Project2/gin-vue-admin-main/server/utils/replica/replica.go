@@ -0,0 +1,206 @@
+// Package replica 为 gorm 提供读写分离
+//
+// 查询按轮询路由到健康的只读副本 写入始终使用主库 以下情况查询仍使用主库:
+//   - 事务中 以及带有 FOR UPDATE 等锁定子句的查询
+//   - ctx 经过 UsePrimary 处理
+//   - ctx 经过 WithSession 处理 且同一会话中已执行过写入 保证请求内读到自己的写入
+//   - ctx 未经过 WithSession 处理 且本进程在 Sticky 时长内执行过写入
+//   - 所有副本都不健康
+//
+// 大多数服务未通过 WithContext 传入请求 ctx 无法按请求区分写入 因此以进程内最近一次写入为准
+// 写入后的 Sticky 时长内 这类查询都使用主库 之后副本已追上复制延迟 再路由到副本
+package replica
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type (
+	primaryKey struct{}
+	sessionKey struct{}
+)
+
+type session struct {
+	written atomic.Bool
+}
+
+// UsePrimary 使 ctx 中的所有查询都使用主库
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// WithSession 为 ctx 开启会话 会话中执行过写入后 之后的查询都使用主库 通常每个请求一个会话
+func WithSession(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(sessionKey{}).(*session); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionKey{}, new(session))
+}
+
+// readable ctx 中的查询是否可以使用副本 会话中未写入过时可以 没有会话时需距本进程最近一次写入超过 Sticky
+func (r *Resolver) readable(ctx context.Context) bool {
+	if ctx != nil {
+		if force, _ := ctx.Value(primaryKey{}).(bool); force {
+			return false
+		}
+		if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+			return !s.written.Load()
+		}
+	}
+	return time.Since(time.Unix(0, r.lastWrite.Load())) > r.options.Sticky
+}
+
+// written 记录会话与本进程中执行过写入
+func (r *Resolver) written(ctx context.Context) {
+	r.lastWrite.Store(time.Now().UnixNano())
+	if ctx == nil {
+		return
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.written.Store(true)
+	}
+}
+
+// Options 读写分离配置
+type Options struct {
+	Dialect  string        // 方言 mysql|pgsql|mssql|oracle|sqlite 用于查询复制延迟
+	MaxLag   time.Duration // 允许的最大复制延迟 超过时摘除副本 0为不检查延迟
+	Interval time.Duration // 健康检查间隔 默认10秒
+	Sticky   time.Duration // 写入后未传入会话的查询使用主库的时长 默认与 MaxLag 相同 未检查延迟时为1秒
+}
+
+// Resolver 读写分离插件 通过 db.Use 注册
+type Resolver struct {
+	options Options
+	primary gorm.ConnPool
+	nodes   []*node
+	next    atomic.Uint64
+	// lastWrite 本进程最近一次写入的时间 纳秒
+	lastWrite atomic.Int64
+	stop      chan struct{}
+	once      sync.Once
+}
+
+var _ gorm.Plugin = (*Resolver)(nil)
+
+// New 创建读写分离插件 replicas 为各副本的连接
+func New(options Options, replicas map[string]*gorm.DB) *Resolver {
+	if options.Interval <= 0 {
+		options.Interval = 10 * time.Second
+	}
+	if options.Sticky <= 0 {
+		options.Sticky = options.MaxLag
+	}
+	if options.Sticky <= 0 {
+		options.Sticky = time.Second
+	}
+	r := &Resolver{options: options, stop: make(chan struct{})}
+	for name, db := range replicas {
+		r.nodes = append(r.nodes, &node{name: name, db: db, pool: db.Config.ConnPool})
+	}
+	return r
+}
+
+func (r *Resolver) Name() string {
+	return "gva:replica"
+}
+
+// Initialize 注册路由回调 并在后台开始健康检查 首次检查完成后才会使用副本
+func (r *Resolver) Initialize(db *gorm.DB) error {
+	r.primary = db.Config.ConnPool
+	callback := db.Callback()
+	if err := callback.Query().Before("gorm:query").Register("gva:replica_read", r.read); err != nil {
+		return err
+	}
+	if err := callback.Query().After("gorm:after_query").Register("gva:replica_reset", r.reset); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("gva:replica_read", r.read); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("gva:replica_reset", r.reset); err != nil {
+		return err
+	}
+	if err := callback.Create().Before("*").Register("gva:replica_write", r.write); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("*").Register("gva:replica_write", r.write); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("*").Register("gva:replica_write", r.write); err != nil {
+		return err
+	}
+	if err := callback.Raw().Before("*").Register("gva:replica_write", r.write); err != nil {
+		return err
+	}
+	go r.watch()
+	return nil
+}
+
+// Close 停止健康检查并关闭副本连接
+func (r *Resolver) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	for _, n := range r.nodes {
+		if sqlDB, err := n.db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	return nil
+}
+
+// read 查询语句切换到副本 执行后由 reset 切回主库 避免同一语句对象后续的写入或开启事务落到副本上
+func (r *Resolver) read(db *gorm.DB) {
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+	if sql := strings.TrimSpace(db.Statement.SQL.String()); sql != "" && !r.readonly(sql) {
+		r.written(db.Statement.Context)
+		return
+	}
+	if _, locking := db.Statement.Clauses["FOR"]; locking || !r.readable(db.Statement.Context) {
+		return
+	}
+	if n := r.pick(); n != nil {
+		db.Statement.ConnPool = n.pool
+	}
+}
+
+func (r *Resolver) reset(db *gorm.DB) {
+	for _, n := range r.nodes {
+		if db.Statement.ConnPool == n.pool {
+			db.Statement.ConnPool = r.primary
+			return
+		}
+	}
+}
+
+func (r *Resolver) write(db *gorm.DB) {
+	r.written(db.Statement.Context)
+}
+
+// readonly 原生 SQL 是否为只读查询
+func (r *Resolver) readonly(sql string) bool {
+	lower := strings.ToLower(sql)
+	return strings.HasPrefix(lower, "select") && !strings.HasSuffix(lower, "for update")
+}
+
+// pick 轮询选择健康的副本 都不健康时返回 nil 使用主库
+func (r *Resolver) pick() *node {
+	total := uint64(len(r.nodes))
+	if total == 0 {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := uint64(0); i < total; i++ {
+		if n := r.nodes[(start+i)%total]; n.healthy.Load() {
+			return n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package replica
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID   uint
+	Name string
+}
+
+func open(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name+".db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Create(&item{Name: name}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func setup(t *testing.T) (*gorm.DB, *gorm.DB, *Resolver) {
+	primaryDB, replicaDB := open(t, "primary"), open(t, "replica")
+	resolver := New(Options{Dialect: "sqlite", Interval: time.Hour}, map[string]*gorm.DB{"replica": replicaDB})
+	if err := primaryDB.Use(resolver); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resolver.Close() })
+	resolver.check()
+	return primaryDB, replicaDB, resolver
+}
+
+func name(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var i item
+	if err := db.First(&i).Error; err != nil {
+		t.Fatal(err)
+	}
+	return i.Name
+}
+
+func TestResolver(t *testing.T) {
+	db, _, _ := setup(t)
+	ctx := context.Background()
+
+	if got := name(t, db); got != "replica" {
+		t.Errorf("未传入会话且近期没有写入的查询应路由到副本 got %s", got)
+	}
+	if got := name(t, db.WithContext(WithSession(ctx))); got != "replica" {
+		t.Errorf("会话中的查询应路由到副本 got %s", got)
+	}
+	if got := name(t, db.WithContext(UsePrimary(WithSession(ctx)))); got != "primary" {
+		t.Errorf("UsePrimary 应使用主库 got %s", got)
+	}
+	_ = db.Transaction(func(tx *gorm.DB) error {
+		if got := name(t, tx); got != "primary" {
+			t.Errorf("事务中应使用主库 got %s", got)
+		}
+		return nil
+	})
+	var count int64
+	if err := db.WithContext(WithSession(ctx)).Raw("SELECT count(*) FROM items WHERE name = ?", "replica").Scan(&count).Error; err != nil || count != 1 {
+		t.Errorf("原生查询应路由到副本 got %d %v", count, err)
+	}
+
+	session := WithSession(ctx)
+	if got := name(t, db.WithContext(session)); got != "replica" {
+		t.Errorf("会话中写入前应使用副本 got %s", got)
+	}
+	if err := db.WithContext(session).Create(&item{Name: "new"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var i item
+	if err := db.WithContext(session).Where("name = ?", "new").First(&i).Error; err != nil {
+		t.Errorf("会话中写入后应读到自己的写入: %v", err)
+	}
+	if got := name(t, db.WithContext(WithSession(ctx))); got != "replica" {
+		t.Errorf("其他请求不受会话影响 got %s", got)
+	}
+
+	query := db.WithContext(WithSession(ctx)).Model(&item{}).Where("id > ?", 0)
+	if err := query.Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := query.Update("name", "updated").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := name(t, db.WithContext(UsePrimary(ctx))); got != "updated" {
+		t.Errorf("查询后复用的语句对象应在主库上写入 got %s", got)
+	}
+}
+
+func TestResolverWithoutSession(t *testing.T) {
+	db, _, resolver := setup(t)
+	if err := db.Create(&item{Name: "new"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var i item
+	if err := db.Where("name = ?", "new").First(&i).Error; err != nil {
+		t.Errorf("未传入会话 ctx 的查询应读到之前的写入: %v", err)
+	}
+	if got := name(t, db.WithContext(WithSession(context.Background()))); got != "replica" {
+		t.Errorf("进程内的写入不影响未写入过的会话 got %s", got)
+	}
+
+	// 超过 Sticky 后副本已追上 未传入会话的查询重新路由到副本
+	resolver.lastWrite.Store(time.Now().Add(-2 * resolver.options.Sticky).UnixNano())
+	if got := name(t, db); got != "replica" {
+		t.Errorf("写入超过 Sticky 后应路由到副本 got %s", got)
+	}
+}
+
+func TestResolverEject(t *testing.T) {
+	db, replicaDB, resolver := setup(t)
+	sqlDB, _ := replicaDB.DB()
+	_ = sqlDB.Close()
+	resolver.check()
+	if status := resolver.Status(); status[0].Healthy {
+		t.Fatalf("连接失败的副本应被摘除 %+v", status)
+	}
+	if got := name(t, db.WithContext(WithSession(context.Background()))); got != "primary" {
+		t.Errorf("没有健康的副本时应使用主库 got %s", got)
+	}
+}
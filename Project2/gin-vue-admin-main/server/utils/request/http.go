@@ -2,12 +2,29 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/tracing"
 )
 
+// client 外部请求创建 span 并在请求头中传递链路
+var client = &http.Client{Transport: tracing.Transport(nil)}
+
 func HttpRequest(
+	urlStr string,
+	method string,
+	headers map[string]string,
+	params map[string]string,
+	data any) (*http.Response, error) {
+	return HttpRequestWithContext(context.Background(), urlStr, method, headers, params, data)
+}
+
+// HttpRequestWithContext 同 HttpRequest 传入请求的 ctx 时外部请求挂在接口的链路之下
+func HttpRequestWithContext(
+	ctx context.Context,
 	urlStr string,
 	method string,
 	headers map[string]string,
@@ -37,7 +54,7 @@ func HttpRequest(
 	}
 
 	// 创建请求
-	req, err := http.NewRequest(method, u.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
 
 	if err != nil {
 		return nil, err
@@ -52,7 +69,7 @@ func HttpRequest(
 	}
 
 	// 发送请求
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// SemverSatisfies 判断版本是否满足约束
+// 约束以空格分隔表示同时满足, 以 || 分隔表示满足其一, 支持 = > >= < <= ^ ~ 以及 1.x 1.2.* 这类省略写法, 运算符与版本号之间可以有空格
+func SemverSatisfies(version, constraint string) (bool, error) {
+	v := canonicalSemver(version)
+	if !semver.IsValid(v) {
+		return false, fmt.Errorf("非法版本号: %s", version)
+	}
+	for _, group := range strings.Split(constraint, "||") {
+		terms, err := semverTerms(group)
+		if err != nil {
+			return false, err
+		}
+		satisfied := true
+		for _, term := range terms {
+			ok, err := semverMatch(v, term)
+			if err != nil {
+				return false, err
+			}
+			satisfied = satisfied && ok
+		}
+		if satisfied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// semverTerms 拆分约束条件 运算符与版本号之间有空格时合并, 如 ">= 2.7.0"
+func semverTerms(group string) ([]string, error) {
+	var terms []string
+	var op string
+	for _, field := range strings.Fields(group) {
+		if strings.Trim(field, "<>=^~") == "" {
+			if op != "" {
+				return nil, fmt.Errorf("非法版本约束: %s", strings.TrimSpace(group))
+			}
+			op = field
+			continue
+		}
+		terms = append(terms, op+field)
+		op = ""
+	}
+	if op != "" {
+		return nil, fmt.Errorf("非法版本约束: %s", strings.TrimSpace(group))
+	}
+	return terms, nil
+}
+
+// SemverCompare 比较两个版本 返回 -1 0 1
+func SemverCompare(a, b string) int {
+	return semver.Compare(canonicalSemver(a), canonicalSemver(b))
+}
+
+func canonicalSemver(version string) string {
+	version = strings.TrimSpace(version)
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+// semverMatch 判断版本是否满足单个约束条件
+func semverMatch(v, term string) (bool, error) {
+	var op string
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			break
+		}
+	}
+	target := strings.TrimPrefix(strings.TrimSpace(term[len(op):]), "v")
+	if target == "" || target == "*" || target == "x" {
+		return true, nil
+	}
+	var pre string
+	if i := strings.IndexAny(target, "-+"); i != -1 {
+		target, pre = target[:i], target[i:]
+	}
+	parts := strings.Split(target, ".")
+	if len(parts) > 3 {
+		return false, fmt.Errorf("非法版本约束: %s", term)
+	}
+	numbers := make([]int, 3)
+	specified := 0
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return false, fmt.Errorf("非法版本约束: %s", term)
+		}
+		numbers[i] = number
+		specified++
+	}
+	if specified == 0 {
+		return true, nil
+	}
+	lower := fmt.Sprintf("v%d.%d.%d%s", numbers[0], numbers[1], numbers[2], pre)
+	compare := semver.Compare(v, lower)
+	switch op {
+	case ">=":
+		return compare >= 0, nil
+	case ">":
+		return compare > 0, nil
+	case "<=":
+		return compare <= 0, nil
+	case "<":
+		return compare < 0, nil
+	case "^":
+		switch {
+		case numbers[0] > 0 || specified == 1:
+			return compare >= 0 && semver.Compare(v, bumpSemver(numbers, 0)) < 0, nil
+		case numbers[1] > 0 || specified == 2:
+			return compare >= 0 && semver.Compare(v, bumpSemver(numbers, 1)) < 0, nil
+		default:
+			return compare >= 0 && semver.Compare(v, bumpSemver(numbers, 2)) < 0, nil
+		}
+	case "~":
+		if specified == 1 {
+			return compare >= 0 && semver.Compare(v, bumpSemver(numbers, 0)) < 0, nil
+		}
+		return compare >= 0 && semver.Compare(v, bumpSemver(numbers, 1)) < 0, nil
+	default:
+		if specified == 3 {
+			return compare == 0, nil
+		}
+		return compare >= 0 && semver.Compare(v, bumpSemver(numbers, specified-1)) < 0, nil
+	}
+}
+
+// bumpSemver 递增第 index 位版本号并将其后的版本号置零 用作区间上界
+func bumpSemver(numbers []int, index int) string {
+	bumped := make([]int, 3)
+	copy(bumped, numbers[:index+1])
+	bumped[index]++
+	// 上界使用 -0 排除该版本的预发布版本
+	return fmt.Sprintf("v%d.%d.%d-0", bumped[0], bumped[1], bumped[2])
+}
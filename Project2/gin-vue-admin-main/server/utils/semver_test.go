@@ -0,0 +1,44 @@
+package utils
+
+import "testing"
+
+func TestSemverSatisfies(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+		wantErr    bool
+	}{
+		{version: "2.7.1", constraint: "", want: true},
+		{version: "v2.7.1", constraint: ">=2.7.0 <3.0.0", want: true},
+		{version: "3.0.0", constraint: ">=2.7.0 <3.0.0", want: false},
+		{version: "2.7.1", constraint: "^2.5", want: true},
+		{version: "3.0.0-beta", constraint: "^2.5", want: false},
+		{version: "0.3.1", constraint: "^0.2.0", want: false},
+		{version: "1.2.9", constraint: "~1.2.3", want: true},
+		{version: "1.3.0", constraint: "~1.2.3", want: false},
+		{version: "1.4.2", constraint: "1.x", want: true},
+		{version: "1.4.2", constraint: "1.3.*", want: false},
+		{version: "1.0.0", constraint: "=1.0.0", want: true},
+		{version: "1.5.0", constraint: "<1.0.0 || >=1.5.0", want: true},
+		{version: "2.8.0", constraint: ">= 2.7.0", want: true},
+		{version: "2.6.0", constraint: ">= 2.7.0", want: false},
+		{version: "2.8.0", constraint: ">= 2.7.0 < 3.0.0", want: true},
+		{version: "3.1.0", constraint: "< 2.0.0 || >= 3.0.0", want: true},
+		{version: "2.8.0", constraint: ">=", wantErr: true},
+		{version: "2.8.0", constraint: ">= < 3.0.0", wantErr: true},
+		{version: "1.0.0", constraint: ">=a.b", wantErr: true},
+		{version: "latest", constraint: "*", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			got, err := SemverSatisfies(tt.version, tt.constraint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SemverSatisfies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SemverSatisfies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package testdb 为代码生成器生成的测试提供内存 SQLite 数据库与接口请求辅助方法
+package testdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/config"
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var sequence atomic.Int64
+
+// Open 打开内存 SQLite 数据库 迁移 models 并设置为全局数据库 businessDB 不为空时同时注册为业务库
+// 测试结束后自动关闭 并恢复原来的全局数据库与业务库
+func Open(t testing.TB, businessDB string, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared", sequence.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // 内存数据库在最后一个连接关闭时销毁 使用单连接避免锁冲突
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, model := range models {
+		if err = replaceEnum(db, model); err != nil {
+			t.Fatalf("解析模型失败: %v", err)
+		}
+	}
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatalf("迁移测试表失败: %v", err)
+	}
+	if global.GVA_LOG == nil {
+		global.GVA_LOG = zap.NewNop()
+	}
+	previous := global.GVA_DB
+	t.Cleanup(func() { global.GVA_DB = previous })
+	global.GVA_DB = db
+	if businessDB != "" {
+		info, registered := global.GetGlobalDBInfo(businessDB)
+		old := global.SetGlobalDB(config.SpecializedDB{Type: "sqlite", AliasName: businessDB}, db)
+		t.Cleanup(func() {
+			if registered {
+				global.SetGlobalDB(info, old)
+			} else {
+				global.RemoveGlobalDB(businessDB)
+			}
+		})
+	}
+	return db
+}
+
+// replaceEnum SQLite 不支持 enum 类型 迁移前替换为 text
+func replaceEnum(db *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	for _, field := range stmt.Schema.Fields {
+		if strings.HasPrefix(strings.ToLower(string(field.DataType)), "enum") {
+			field.DataType = "text"
+		}
+	}
+	return nil
+}
+
+// Result 接口响应 Data 保留原始 JSON 以便解析为具体类型
+type Result struct {
+	Code int             `json:"code"`
+	Data json.RawMessage `json:"data"`
+	Msg  string          `json:"msg"`
+}
+
+// Do 向 handler 发送请求并解析响应 body 为 []byte 时原样发送 否则序列化为 JSON
+func Do(t testing.TB, handler http.Handler, method, target string, body interface{}) Result {
+	t.Helper()
+	var reader io.Reader
+	switch value := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(value)
+	default:
+		content, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("序列化请求失败: %v", err)
+		}
+		reader = bytes.NewReader(content)
+	}
+	request := httptest.NewRequest(method, target, reader)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	var result Result
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("[%s %s]解析响应失败: %v, status=%d body=%s", method, target, err, recorder.Code, recorder.Body.String())
+	}
+	return result
+}
+
+// Ptr 返回值的指针 用于填充生成结构体中的指针字段
+func Ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,55 @@
+package timer
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+)
+
+// JobHandler 可持久化调度的任务处理函数
+// ctx 在任务超时或取消时结束 args 为任务配置的参数 写入 out 的内容会保存到执行记录
+type JobHandler func(ctx context.Context, args string, out io.Writer) error
+
+// JobHandlerInfo 已注册的任务处理函数信息
+type JobHandlerInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type jobHandler struct {
+	info    JobHandlerInfo
+	handler JobHandler
+}
+
+var (
+	jobHandlers   = make(map[string]jobHandler)
+	jobHandlersMu sync.RWMutex
+)
+
+// RegisterJobHandler 注册任务处理函数 任务通过 name 引用处理函数 重复注册时覆盖
+func RegisterJobHandler(name string, description string, handler JobHandler) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	jobHandlers[name] = jobHandler{info: JobHandlerInfo{Name: name, Description: description}, handler: handler}
+}
+
+// GetJobHandler 获取任务处理函数
+func GetJobHandler(name string) (JobHandler, bool) {
+	jobHandlersMu.RLock()
+	defer jobHandlersMu.RUnlock()
+	h, ok := jobHandlers[name]
+	return h.handler, ok
+}
+
+// JobHandlers 获取所有已注册的任务处理函数 按名称排序
+func JobHandlers() []JobHandlerInfo {
+	jobHandlersMu.RLock()
+	defer jobHandlersMu.RUnlock()
+	list := make([]JobHandlerInfo, 0, len(jobHandlers))
+	for _, h := range jobHandlers {
+		list = append(list, h.info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,27 @@
+package timer
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterJobHandler(t *testing.T) {
+	RegisterJobHandler("b", "second", func(ctx context.Context, args string, out io.Writer) error {
+		_, err := io.WriteString(out, args)
+		return err
+	})
+	RegisterJobHandler("a", "first", func(ctx context.Context, args string, out io.Writer) error { return nil })
+
+	h, ok := GetJobHandler("b")
+	assert.True(t, ok)
+	assert.Nil(t, h(context.Background(), "", io.Discard))
+
+	_, ok = GetJobHandler("missing")
+	assert.False(t, ok)
+
+	list := JobHandlers()
+	assert.Equal(t, []JobHandlerInfo{{Name: "a", Description: "first"}, {Name: "b", Description: "second"}}, list)
+}
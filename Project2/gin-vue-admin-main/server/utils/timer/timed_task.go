@@ -1,8 +1,10 @@
 package timer
 
 import (
-	"github.com/robfig/cron/v3"
 	"sync"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/utils/metrics"
+	"github.com/robfig/cron/v3"
 )
 
 type Timer interface {
@@ -62,7 +64,7 @@ func (t *timer) AddTaskByFunc(cronName string, spec string, fun func(), taskName
 			tasks: tasks,
 		}
 	}
-	id, err := t.cronList[cronName].corn.AddFunc(spec, fun)
+	id, err := t.cronList[cronName].corn.AddFunc(spec, metrics.Job(cronName, taskName, fun))
 	t.cronList[cronName].corn.Start()
 	t.cronList[cronName].tasks[id] = &task{
 		EntryID:  id,
@@ -84,7 +86,7 @@ func (t *timer) AddTaskByFuncWithSecond(cronName string, spec string, fun func()
 			tasks: tasks,
 		}
 	}
-	id, err := t.cronList[cronName].corn.AddFunc(spec, fun)
+	id, err := t.cronList[cronName].corn.AddFunc(spec, metrics.Job(cronName, taskName, fun))
 	t.cronList[cronName].corn.Start()
 	t.cronList[cronName].tasks[id] = &task{
 		EntryID:  id,
@@ -105,7 +107,7 @@ func (t *timer) AddTaskByJob(cronName string, spec string, job interface{ Run()
 			tasks: tasks,
 		}
 	}
-	id, err := t.cronList[cronName].corn.AddJob(spec, job)
+	id, err := t.cronList[cronName].corn.AddJob(spec, cron.FuncJob(metrics.Job(cronName, taskName, job.Run)))
 	t.cronList[cronName].corn.Start()
 	t.cronList[cronName].tasks[id] = &task{
 		EntryID:  id,
@@ -127,7 +129,7 @@ func (t *timer) AddTaskByJobWithSeconds(cronName string, spec string, job interf
 			tasks: tasks,
 		}
 	}
-	id, err := t.cronList[cronName].corn.AddJob(spec, job)
+	id, err := t.cronList[cronName].corn.AddJob(spec, cron.FuncJob(metrics.Job(cronName, taskName, job.Run)))
 	t.cronList[cronName].corn.Start()
 	t.cronList[cronName].tasks[id] = &task{
 		EntryID:  id,
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"errors"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	gormSpanKey = "gva:tracing:span"
+	// maxStatement 批量写入的语句可能很长 超出部分截断
+	maxStatement = 2048
+)
+
+// gormPlugin 为每条语句创建 span 使用 db.WithContext 传入的 ctx 作为上级
+type gormPlugin struct {
+	db string
+}
+
+// UseGorm 为数据库开启语句追踪 name 为 span 中的数据库名
+func UseGorm(db *gorm.DB, name string) error {
+	if db == nil {
+		return nil
+	}
+	return db.Use(&gormPlugin{db: name})
+}
+
+func (p *gormPlugin) Name() string {
+	return "gva:tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("gva:tracing:before_create", p.before("create")),
+		cb.Create().After("gorm:create").Register("gva:tracing:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("gva:tracing:before_query", p.before("query")),
+		cb.Query().After("gorm:query").Register("gva:tracing:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("gva:tracing:before_update", p.before("update")),
+		cb.Update().After("gorm:update").Register("gva:tracing:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("gva:tracing:before_delete", p.before("delete")),
+		cb.Delete().After("gorm:delete").Register("gva:tracing:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("gva:tracing:before_row", p.before("row")),
+		cb.Row().After("gorm:row").Register("gva:tracing:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("gva:tracing:before_raw", p.before("raw")),
+		cb.Raw().After("gorm:raw").Register("gva:tracing:after_raw", p.after),
+	)
+}
+
+func (p *gormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := Tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBName(p.db), semconv.DBOperation(operation)),
+		)
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (p *gormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(
+		semconv.DBSystemKey.String(db.Dialector.Name()),
+		semconv.DBSQLTable(db.Statement.Table),
+		semconv.DBStatement(Sanitize(db.Dialector.Name(), db.Statement.SQL.String())),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+var (
+	// quoted 标准 SQL 字符串 两个单引号为转义 pgsql 的 E'...' 中反斜杠为转义
+	quoted = regexp.MustCompile(`\b[eE]'(?:[^'\\]|''|\\.)*'|'(?:[^']|'')*'`)
+	// mysqlQuoted mysql 单双引号均为字符串 反斜杠为转义
+	mysqlQuoted = regexp.MustCompile(`'(?:[^'\\]|''|\\.)*'|"(?:[^"\\]|""|\\.)*"`)
+	numbers     = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+)
+
+// Sanitize 将语句中的字符串与数字常量替换为 ? 避免在链路中记录数据 dialect 为 gorm 的方言名
+// 参数化的语句中参数本就以占位符出现 主要处理 Raw 与 Exec 拼接的语句
+func Sanitize(dialect string, sql string) string {
+	if dialect == "mysql" {
+		sql = mysqlQuoted.ReplaceAllString(sql, "?")
+	} else {
+		sql = quoted.ReplaceAllString(sql, "?")
+	}
+	sql = numbers.ReplaceAllString(sql, "?")
+	if len(sql) > maxStatement {
+		sql = sql[:maxStatement] + "..."
+	}
+	return sql
+}
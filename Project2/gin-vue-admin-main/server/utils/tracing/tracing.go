@@ -0,0 +1,85 @@
+// Package tracing OpenTelemetry 链路追踪
+//
+// 未调用 Setup 时使用 otel 默认的空实现 span 不记录也不导出 测试无需采集端
+// 接口 数据库 redis 外部请求的 span 分别由 middleware.Tracing UseGorm UseRedis Transport 创建
+// 数据库与 redis 调用需要传入请求的 ctx 才会挂在接口的 span 之下 否则各自成为独立的链路
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const instrumentation = "github.com/flipped-aurora/gin-vue-admin/server"
+
+// Options 导出配置
+type Options struct {
+	ServiceName string            // 服务名
+	Endpoint    string            // OTLP/HTTP 采集端地址 host:port
+	URLPath     string            // 采集端路径 为空时为 /v1/traces
+	Insecure    bool              // 使用 http 而不是 https
+	Headers     map[string]string // 附加的请求头 如采集端的鉴权信息
+	SampleRatio float64           // 没有上游链路时的采样比例 0-1 上游已采样的请求始终采样
+}
+
+// Setup 开启 OTLP 导出 返回的函数在程序退出前调用 导出尚未发送的 span
+func Setup(ctx context.Context, options Options) (shutdown func(context.Context) error, err error) {
+	exporterOptions := []otlptracehttp.Option{otlptracehttp.WithEndpoint(options.Endpoint)}
+	if options.URLPath != "" {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithURLPath(options.URLPath))
+	}
+	if options.Insecure {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+	if len(options.Headers) > 0 {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithHeaders(options.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(options.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(5*time.Second)),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Tracer 本项目使用的 Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// TraceID ctx 中的链路ID 没有链路时为空
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// Logger 带有链路ID的日志 没有链路时为 global.GVA_LOG
+func Logger(ctx context.Context) *zap.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return global.GVA_LOG
+	}
+	return global.GVA_LOG.With(zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String()))
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		dialect string
+		sql     string
+		want    string
+	}{
+		{
+			dialect: "sqlite",
+			sql:     "SELECT * FROM users WHERE name = 'o''neil' AND id = 12 AND t1.age > 3.5 AND x = ?",
+			want:    "SELECT * FROM users WHERE name = ? AND id = ? AND t1.age > ? AND x = ?",
+		},
+		{
+			dialect: "mysql",
+			sql:     `SELECT * FROM users WHERE name = 'it\'s secret' AND path = 'c:\\' AND nick = "say \"secret\"" AND id = 1`,
+			want:    "SELECT * FROM users WHERE name = ? AND path = ? AND nick = ? AND id = ?",
+		},
+		{
+			dialect: "postgres",
+			sql:     `SELECT * FROM "users" WHERE path = 'c:\' AND note = E'it\'s secret'`,
+			want:    `SELECT * FROM "users" WHERE path = ? AND note = ?`,
+		},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.dialect, tt.sql); got != tt.want {
+			t.Errorf("Sanitize(%s) = %s, want %s", tt.dialect, got, tt.want)
+		}
+	}
+}
+
+type item struct {
+	ID   uint
+	Name string
+}
+
+func TestUseGorm(t *testing.T) {
+	recorder := setupRecorder(t)
+	db, err := gorm.Open(sqlite.Open("file:tracing?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = UseGorm(db, "test"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	db.WithContext(ctx).Create(&item{Name: "a"})
+	db.WithContext(ctx).Exec("UPDATE items SET name = 'b' WHERE id = 1")
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("应有3个span got %d", len(spans))
+	}
+	for _, span := range spans[:2] {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("%s 应挂在请求的 span 之下", span.Name())
+		}
+	}
+	for _, kv := range spans[1].Attributes() {
+		if kv.Key == "db.statement" && kv.Value.AsString() != "UPDATE items SET name = ? WHERE id = ?" {
+			t.Errorf("语句应去除常量 got %s", kv.Value.AsString())
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := setupRecorder(t)
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/hook?token=secret", nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	parent.End()
+
+	if traceparent == "" || TraceID(ctx) == "" || traceparent[3:35] != TraceID(ctx) {
+		t.Errorf("应在请求头中传递链路 got %q", traceparent)
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Error("不应修改传入的请求")
+	}
+	for _, kv := range recorder.Ended()[0].Attributes() {
+		if kv.Key == "url.full" && kv.Value.AsString() != server.URL+"/hook" {
+			t.Errorf("不应记录查询参数 got %s", kv.Value.AsString())
+		}
+	}
+}
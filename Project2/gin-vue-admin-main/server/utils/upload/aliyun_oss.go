@@ -3,6 +3,7 @@ package upload
 import (
 	"errors"
 	"mime/multipart"
+	"strconv"
 	"time"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
@@ -73,3 +74,76 @@ func NewBucket() (*oss.Bucket, error) {
 
 	return bucket, nil
 }
+
+// PresignPut 生成直传签名地址 key 为完整的对象路径 规则与 UploadFile 一致
+func (a *AliyunOSS) PresignPut(filename string, expire time.Duration) (obj PresignedObject, err error) {
+	bucket, err := NewBucket()
+	if err != nil {
+		return obj, err
+	}
+	obj.Key, obj.Url = a.object(filename)
+	obj.UploadUrl, err = bucket.SignURL(obj.Key, oss.HTTPPut, int64(expire.Seconds()))
+	return obj, err
+}
+
+func (*AliyunOSS) PresignGet(key string, expire time.Duration) (string, error) {
+	bucket, err := NewBucket()
+	if err != nil {
+		return "", err
+	}
+	return bucket.SignURL(key, oss.HTTPGet, int64(expire.Seconds()))
+}
+
+func (a *AliyunOSS) InitiateMultipart(filename, contentType string) (obj PresignedObject, uploadID string, err error) {
+	bucket, err := NewBucket()
+	if err != nil {
+		return obj, "", err
+	}
+	obj.Key, obj.Url = a.object(filename)
+	var options []oss.Option
+	if contentType != "" {
+		options = append(options, oss.ContentType(contentType))
+	}
+	imur, err := bucket.InitiateMultipartUpload(obj.Key, options...)
+	if err != nil {
+		return obj, "", err
+	}
+	return obj, imur.UploadID, nil
+}
+
+func (*AliyunOSS) PresignPart(key, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	bucket, err := NewBucket()
+	if err != nil {
+		return "", err
+	}
+	return bucket.SignURL(key, oss.HTTPPut, int64(expire.Seconds()),
+		oss.AddParam("partNumber", strconv.Itoa(partNumber)), oss.AddParam("uploadId", uploadID))
+}
+
+func (*AliyunOSS) CompleteMultipart(key, uploadID string, parts []Part) error {
+	bucket, err := NewBucket()
+	if err != nil {
+		return err
+	}
+	uploadParts := make([]oss.UploadPart, 0, len(parts))
+	for _, p := range parts {
+		uploadParts = append(uploadParts, oss.UploadPart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	imur := oss.InitiateMultipartUploadResult{Bucket: bucket.BucketName, Key: key, UploadID: uploadID}
+	_, err = bucket.CompleteMultipartUpload(imur, uploadParts)
+	return err
+}
+
+func (*AliyunOSS) AbortMultipart(key, uploadID string) error {
+	bucket, err := NewBucket()
+	if err != nil {
+		return err
+	}
+	imur := oss.InitiateMultipartUploadResult{Bucket: bucket.BucketName, Key: key, UploadID: uploadID}
+	return bucket.AbortMultipartUpload(imur)
+}
+
+func (*AliyunOSS) object(filename string) (key string, url string) {
+	key = global.GVA_CONFIG.AliyunOSS.BasePath + "/" + "uploads" + "/" + time.Now().Format("2006-01-02") + "/" + filename
+	return key, global.GVA_CONFIG.AliyunOSS.BucketUrl + "/" + key
+}
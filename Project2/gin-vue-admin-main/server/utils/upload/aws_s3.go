@@ -95,3 +95,40 @@ func newSession() *session.Session {
 	})
 	return sess
 }
+
+// PresignPut 生成直传签名地址 key 规则与 UploadFile 一致
+func (a *AwsS3) PresignPut(filename string, expire time.Duration) (obj PresignedObject, err error) {
+	obj.Key = fmt.Sprintf("%d%s", time.Now().Unix(), filename)
+	object := a.objectName(obj.Key)
+	obj.Url = global.GVA_CONFIG.AwsS3.BaseURL + "/" + object
+	obj.UploadUrl, err = s3PresignPut(s3.New(newSession()), global.GVA_CONFIG.AwsS3.Bucket, object, expire)
+	return obj, err
+}
+
+func (a *AwsS3) PresignGet(key string, expire time.Duration) (string, error) {
+	return s3PresignGet(s3.New(newSession()), global.GVA_CONFIG.AwsS3.Bucket, a.objectName(key), expire)
+}
+
+func (a *AwsS3) InitiateMultipart(filename, contentType string) (obj PresignedObject, uploadID string, err error) {
+	obj.Key = fmt.Sprintf("%d%s", time.Now().Unix(), filename)
+	object := a.objectName(obj.Key)
+	obj.Url = global.GVA_CONFIG.AwsS3.BaseURL + "/" + object
+	uploadID, err = s3InitiateMultipart(s3.New(newSession()), global.GVA_CONFIG.AwsS3.Bucket, object, contentType)
+	return obj, uploadID, err
+}
+
+func (a *AwsS3) PresignPart(key, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	return s3PresignPart(s3.New(newSession()), global.GVA_CONFIG.AwsS3.Bucket, a.objectName(key), uploadID, partNumber, expire)
+}
+
+func (a *AwsS3) CompleteMultipart(key, uploadID string, parts []Part) error {
+	return s3CompleteMultipart(s3.New(newSession()), global.GVA_CONFIG.AwsS3.Bucket, a.objectName(key), uploadID, parts)
+}
+
+func (a *AwsS3) AbortMultipart(key, uploadID string) error {
+	return s3AbortMultipart(s3.New(newSession()), global.GVA_CONFIG.AwsS3.Bucket, a.objectName(key), uploadID)
+}
+
+func (*AwsS3) objectName(key string) string {
+	return global.GVA_CONFIG.AwsS3.PathPrefix + "/" + key
+}
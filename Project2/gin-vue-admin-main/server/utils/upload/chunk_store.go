@@ -0,0 +1,108 @@
+package upload
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+// ChunkStore 断点续传的分片存储 分片索引由调用方记录在数据库中
+type ChunkStore interface {
+	// PutChunk 保存分片 读取中断时返回已保存的字节数与错误
+	PutChunk(name string, r io.Reader) (key string, size int64, err error)
+	GetChunk(key string) (io.ReadCloser, error)
+	DeleteChunk(key string) error
+}
+
+// NewChunkStore 分片存储的实例化方法
+func NewChunkStore() ChunkStore {
+	switch global.GVA_CONFIG.Tus.ChunkStore {
+	case "oss":
+		return &OssChunkStore{}
+	default:
+		return &DiskChunkStore{}
+	}
+}
+
+// DiskChunkStore 分片保存在本地磁盘 适用于单实例部署
+type DiskChunkStore struct{}
+
+func (*DiskChunkStore) dir() string {
+	if global.GVA_CONFIG.Tus.ChunkDir == "" {
+		return "./breakpointDir/"
+	}
+	return global.GVA_CONFIG.Tus.ChunkDir
+}
+
+func (d *DiskChunkStore) PutChunk(name string, r io.Reader) (string, int64, error) {
+	if err := checkLocalKey(name); err != nil {
+		return "", 0, err
+	}
+	if err := os.MkdirAll(d.dir(), os.ModePerm); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(d.dir(), name+".*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	n, copyErr := io.Copy(tmp, r)
+	if err = tmp.Close(); err != nil {
+		return "", 0, err
+	}
+	if n == 0 && copyErr != nil {
+		return "", 0, copyErr
+	}
+	if err = os.Rename(tmp.Name(), filepath.Join(d.dir(), name)); err != nil {
+		return "", 0, err
+	}
+	return name, n, copyErr
+}
+
+func (d *DiskChunkStore) GetChunk(key string) (io.ReadCloser, error) {
+	if err := checkLocalKey(key); err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(d.dir(), key))
+}
+
+func (d *DiskChunkStore) DeleteChunk(key string) error {
+	if err := checkLocalKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(d.dir(), key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// OssChunkStore 分片保存在当前配置的对象存储中 多实例部署时各节点共享分片
+// 读取分片通过 OpenObject 存储需支持 Reader 或 Presigner
+type OssChunkStore struct{}
+
+func (*OssChunkStore) PutChunk(name string, r io.Reader) (string, int64, error) {
+	content, readErr := io.ReadAll(r)
+	if len(content) == 0 && readErr != nil {
+		return "", 0, readErr
+	}
+	fh, err := NewFileHeader(name, content)
+	if err != nil {
+		return "", 0, err
+	}
+	_, key, err := NewOss().UploadFile(fh)
+	if err != nil {
+		return "", 0, err
+	}
+	return key, int64(len(content)), readErr
+}
+
+func (*OssChunkStore) GetChunk(key string) (io.ReadCloser, error) {
+	return OpenObject(NewOss(), key)
+}
+
+func (*OssChunkStore) DeleteChunk(key string) error {
+	return NewOss().DeleteFile(key)
+}
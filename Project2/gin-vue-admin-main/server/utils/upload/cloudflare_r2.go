@@ -83,3 +83,40 @@ func (*CloudflareR2) newSession() *session.Session {
 		),
 	}))
 }
+
+// PresignPut 生成直传签名地址 key 规则与 UploadFile 一致
+func (c *CloudflareR2) PresignPut(filename string, expire time.Duration) (obj PresignedObject, err error) {
+	obj.Key = fmt.Sprintf("%d_%s", time.Now().Unix(), filename)
+	object := c.objectName(obj.Key)
+	obj.Url = fmt.Sprintf("%s/%s", global.GVA_CONFIG.CloudflareR2.BaseURL, object)
+	obj.UploadUrl, err = s3PresignPut(s3.New(c.newSession()), global.GVA_CONFIG.CloudflareR2.Bucket, object, expire)
+	return obj, err
+}
+
+func (c *CloudflareR2) PresignGet(key string, expire time.Duration) (string, error) {
+	return s3PresignGet(s3.New(c.newSession()), global.GVA_CONFIG.CloudflareR2.Bucket, c.objectName(key), expire)
+}
+
+func (c *CloudflareR2) InitiateMultipart(filename, contentType string) (obj PresignedObject, uploadID string, err error) {
+	obj.Key = fmt.Sprintf("%d_%s", time.Now().Unix(), filename)
+	object := c.objectName(obj.Key)
+	obj.Url = fmt.Sprintf("%s/%s", global.GVA_CONFIG.CloudflareR2.BaseURL, object)
+	uploadID, err = s3InitiateMultipart(s3.New(c.newSession()), global.GVA_CONFIG.CloudflareR2.Bucket, object, contentType)
+	return obj, uploadID, err
+}
+
+func (c *CloudflareR2) PresignPart(key, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	return s3PresignPart(s3.New(c.newSession()), global.GVA_CONFIG.CloudflareR2.Bucket, c.objectName(key), uploadID, partNumber, expire)
+}
+
+func (c *CloudflareR2) CompleteMultipart(key, uploadID string, parts []Part) error {
+	return s3CompleteMultipart(s3.New(c.newSession()), global.GVA_CONFIG.CloudflareR2.Bucket, c.objectName(key), uploadID, parts)
+}
+
+func (c *CloudflareR2) AbortMultipart(key, uploadID string) error {
+	return s3AbortMultipart(s3.New(c.newSession()), global.GVA_CONFIG.CloudflareR2.Bucket, c.objectName(key), uploadID)
+}
+
+func (*CloudflareR2) objectName(key string) string {
+	return global.GVA_CONFIG.CloudflareR2.Path + "/" + key
+}
@@ -0,0 +1,52 @@
+package upload
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+)
+
+// NewFileHeader 将内存中的文件内容包装为 *multipart.FileHeader
+// 用于将服务端处理过的内容(去除EXIF、缩略图等)交给 OSS.UploadFile 上传
+func NewFileHeader(filename string, content []byte) (*multipart.FileHeader, error) {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(content); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	form, err := multipart.NewReader(body, w.Boundary()).ReadForm(int64(len(content)) + 1024)
+	if err != nil {
+		return nil, err
+	}
+	return form.File["file"][0], nil
+}
+
+// StreamFileHeader 将 write 写入的内容流式包装为 *multipart.FileHeader 不在内存中保存完整内容
+// 超过 maxMemory 的部分写入临时文件 使用完毕后需调用 remove 删除临时文件
+func StreamFileHeader(filename string, maxMemory int64, write func(w io.Writer) error) (header *multipart.FileHeader, remove func(), err error) {
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	go func() {
+		part, err := w.CreateFormFile("file", filename)
+		if err == nil {
+			err = write(part)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	form, err := multipart.NewReader(pr, w.Boundary()).ReadForm(maxMemory)
+	if err != nil {
+		_ = pr.CloseWithError(err)
+		return nil, nil, err
+	}
+	return form.File["file"][0], func() { _ = form.RemoveAll() }, nil
+}
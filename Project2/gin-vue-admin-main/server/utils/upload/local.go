@@ -4,8 +4,11 @@ import (
 	"errors"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -79,14 +82,8 @@ func (*Local) UploadFile(file *multipart.FileHeader) (string, string, error) {
 //@return: error
 
 func (*Local) DeleteFile(key string) error {
-	// 检查 key 是否为空
-	if key == "" {
-		return errors.New("key不能为空")
-	}
-
-	// 验证 key 是否包含非法字符或尝试访问存储路径之外的文件
-	if strings.Contains(key, "..") || strings.ContainsAny(key, `\/:*?"<>|`) {
-		return errors.New("非法的key")
+	if err := checkLocalKey(key); err != nil {
+		return err
 	}
 
 	p := filepath.Join(global.GVA_CONFIG.Local.StorePath, key)
@@ -107,3 +104,88 @@ func (*Local) DeleteFile(key string) error {
 
 	return nil
 }
+
+// LocalSignedPath 本地存储签名直传直下的路由 注册在公开路由组 由签名代替登录鉴权
+const LocalSignedPath = "/fileUploadAndDownload/localObject"
+
+// checkLocalKey 校验 key 为空、包含非法字符或尝试访问存储路径之外的文件
+func checkLocalKey(key string) error {
+	if key == "" {
+		return errors.New("key不能为空")
+	}
+	if strings.Contains(key, "..") || strings.ContainsAny(key, `\/:*?"<>|`) {
+		return errors.New("非法的key")
+	}
+	return nil
+}
+
+// PresignPut 生成本地存储的签名直传地址 客户端向该地址 PUT 文件内容
+func (*Local) PresignPut(filename string, expire time.Duration) (obj PresignedObject, err error) {
+	ext := filepath.Ext(filename)
+	name := utils.MD5V([]byte(strings.TrimSuffix(filename, ext)))
+	obj.Key = name + "_" + time.Now().Format("20060102150405") + ext
+	obj.Url = global.GVA_CONFIG.Local.Path + "/" + obj.Key
+	obj.UploadUrl = localSignedURL(http.MethodPut, obj.Key, expire)
+	return obj, nil
+}
+
+// PresignGet 生成本地存储的签名下载地址
+func (*Local) PresignGet(key string, expire time.Duration) (string, error) {
+	if err := checkLocalKey(key); err != nil {
+		return "", err
+	}
+	return localSignedURL(http.MethodGet, key, expire), nil
+}
+
+func localSignedURL(method, key string, expire time.Duration) string {
+	expires := time.Now().Add(expire).Unix()
+	query := url.Values{}
+	query.Set("key", key)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sign", Sign(expires, method, key))
+	return global.GVA_CONFIG.System.RouterPrefix + LocalSignedPath + "?" + query.Encode()
+}
+
+// VerifyLocalSign 校验本地存储签名地址
+func VerifyLocalSign(method, key string, expires int64, sign string) error {
+	if err := checkLocalKey(key); err != nil {
+		return err
+	}
+	return VerifySign(sign, expires, method, key)
+}
+
+// PutObject 写入签名直传的文件内容 先写临时文件再重命名 避免读到写了一半的文件
+func (*Local) PutObject(key string, r io.Reader) error {
+	if err := checkLocalKey(key); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(global.GVA_CONFIG.Local.StorePath, os.ModePerm); err != nil {
+		return errors.New("function os.MkdirAll() failed, err:" + err.Error())
+	}
+	p := filepath.Join(global.GVA_CONFIG.Local.StorePath, key)
+	tmp, err := os.CreateTemp(global.GVA_CONFIG.Local.StorePath, key+".*.tmp")
+	if err != nil {
+		return errors.New("function os.CreateTemp() failed, err:" + err.Error())
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return errors.New("function io.Copy() failed, err:" + err.Error())
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// ObjectPath 返回 key 对应的本地文件路径
+func (*Local) ObjectPath(key string) (string, error) {
+	if err := checkLocalKey(key); err != nil {
+		return "", err
+	}
+	p := filepath.Join(global.GVA_CONFIG.Local.StorePath, key)
+	if _, err := os.Stat(p); err != nil {
+		return "", errors.New("文件不存在")
+	}
+	return p, nil
+}
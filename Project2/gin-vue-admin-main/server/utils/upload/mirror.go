@@ -0,0 +1,154 @@
+package upload
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+	"go.uber.org/zap"
+)
+
+// mirrorKeySep 镜像模式下 key 由主存储key与镜像key拼接而成
+const mirrorKeySep = "#mirror#"
+
+// Mirror 镜像存储 同时写入主存储与镜像存储 读取时主存储不可用则回退到镜像
+// 镜像写入失败不影响上传结果 仅记录日志 可通过存储迁移补齐
+type Mirror struct {
+	Primary   OSS
+	Secondary OSS
+}
+
+// SplitMirrorKey 拆分镜像模式下的 key 非镜像 key 的 secondary 为空
+func SplitMirrorKey(key string) (primary string, secondary string) {
+	if i := strings.Index(key, mirrorKeySep); i >= 0 {
+		return key[:i], key[i+len(mirrorKeySep):]
+	}
+	return key, ""
+}
+
+func (m *Mirror) UploadFile(file *multipart.FileHeader) (string, string, error) {
+	url, key, err := m.Primary.UploadFile(file)
+	if err != nil {
+		return "", "", err
+	}
+	_, mirrorKey, err := m.Secondary.UploadFile(file)
+	if err != nil {
+		global.GVA_LOG.Warn("镜像存储写入失败", zap.String("key", key), zap.Error(err))
+		return url, key, nil
+	}
+	return url, key + mirrorKeySep + mirrorKey, nil
+}
+
+func (m *Mirror) DeleteFile(key string) error {
+	primary, secondary := SplitMirrorKey(key)
+	if secondary != "" {
+		if err := m.Secondary.DeleteFile(secondary); err != nil {
+			global.GVA_LOG.Warn("镜像存储删除失败", zap.String("key", secondary), zap.Error(err))
+		}
+	}
+	return m.Primary.DeleteFile(primary)
+}
+
+// Open 读取对象 主存储读取失败时回退到镜像
+func (m *Mirror) Open(key string) (io.ReadCloser, error) {
+	primary, secondary := SplitMirrorKey(key)
+	r, err := OpenObject(m.Primary, primary)
+	if err == nil || secondary == "" {
+		return r, err
+	}
+	global.GVA_LOG.Warn("主存储读取失败, 回退到镜像存储", zap.String("key", primary), zap.Error(err))
+	return OpenObject(m.Secondary, secondary)
+}
+
+// Stat 查询主存储中的对象大小 直传只写入主存储
+func (m *Mirror) Stat(key string) (int64, error) {
+	primary, _ := SplitMirrorKey(key)
+	size, _, err := StatObject(m.Primary, primary, 1)
+	return size, err
+}
+
+// PresignPut 直传只写入主存储
+func (m *Mirror) PresignPut(filename string, expire time.Duration) (PresignedObject, error) {
+	p, ok := m.Primary.(Presigner)
+	if !ok {
+		return PresignedObject{}, errUnsupportedPresign
+	}
+	return p.PresignPut(filename, expire)
+}
+
+// InitiateMultipart 分片直传只写入主存储
+func (m *Mirror) InitiateMultipart(filename, contentType string) (PresignedObject, string, error) {
+	mu, ok := m.Primary.(MultipartUploader)
+	if !ok {
+		return PresignedObject{}, "", errUnsupportedMultipart
+	}
+	return mu.InitiateMultipart(filename, contentType)
+}
+
+func (m *Mirror) PresignPart(key, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	mu, ok := m.Primary.(MultipartUploader)
+	if !ok {
+		return "", errUnsupportedMultipart
+	}
+	return mu.PresignPart(key, uploadID, partNumber, expire)
+}
+
+func (m *Mirror) CompleteMultipart(key, uploadID string, parts []Part) error {
+	mu, ok := m.Primary.(MultipartUploader)
+	if !ok {
+		return errUnsupportedMultipart
+	}
+	return mu.CompleteMultipart(key, uploadID, parts)
+}
+
+func (m *Mirror) AbortMultipart(key, uploadID string) error {
+	mu, ok := m.Primary.(MultipartUploader)
+	if !ok {
+		return errUnsupportedMultipart
+	}
+	return mu.AbortMultipart(key, uploadID)
+}
+
+// PresignGet 主存储中对象可用时返回主存储地址 否则返回镜像地址
+func (m *Mirror) PresignGet(key string, expire time.Duration) (string, error) {
+	primary, secondary := SplitMirrorKey(key)
+	if secondary == "" || objectAvailable(m.Primary, primary) {
+		return presignGet(m.Primary, primary, expire)
+	}
+	return presignGet(m.Secondary, secondary, expire)
+}
+
+func presignGet(oss OSS, key string, expire time.Duration) (string, error) {
+	p, ok := oss.(Presigner)
+	if !ok {
+		return "", errUnsupportedPresign
+	}
+	return p.PresignGet(key, expire)
+}
+
+// objectAvailable 探测对象是否可读 只请求首字节
+func objectAvailable(oss OSS, key string) bool {
+	if local, ok := oss.(*Local); ok {
+		_, err := local.ObjectPath(key)
+		return err == nil
+	}
+	u, err := presignGet(oss, key, time.Minute)
+	if err != nil {
+		return false
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent
+}
@@ -2,6 +2,8 @@ package upload
 
 import (
 	"mime/multipart"
+	"strconv"
+	"time"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
 	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
@@ -65,3 +67,92 @@ func (o *Obs) DeleteFile(key string) error {
 	}
 	return nil
 }
+
+// PresignPut 生成直传签名地址 key 规则与 UploadFile 一致
+func (o *Obs) PresignPut(filename string, expire time.Duration) (obj PresignedObject, err error) {
+	obj.Key = filename
+	obj.Url = global.GVA_CONFIG.HuaWeiObs.Path + "/" + filename
+	obj.UploadUrl, err = o.presign(obs.HttpMethodPut, filename, expire, nil)
+	return obj, err
+}
+
+func (o *Obs) PresignGet(key string, expire time.Duration) (string, error) {
+	return o.presign(obs.HttpMethodGet, key, expire, nil)
+}
+
+func (o *Obs) InitiateMultipart(filename, contentType string) (obj PresignedObject, uploadID string, err error) {
+	client, err := NewHuaWeiObsClient()
+	if err != nil {
+		return obj, "", errors.Wrap(err, "获取华为对象存储对象失败!")
+	}
+	obj.Key = filename
+	obj.Url = global.GVA_CONFIG.HuaWeiObs.Path + "/" + filename
+	input := &obs.InitiateMultipartUploadInput{ContentType: contentType}
+	input.Bucket = global.GVA_CONFIG.HuaWeiObs.Bucket
+	input.Key = filename
+	output, err := client.InitiateMultipartUpload(input)
+	if err != nil {
+		return obj, "", errors.Wrap(err, "初始化分片上传失败!")
+	}
+	return obj, output.UploadId, nil
+}
+
+func (o *Obs) PresignPart(key, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	return o.presign(obs.HttpMethodPut, key, expire, map[string]string{
+		"partNumber": strconv.Itoa(partNumber),
+		"uploadId":   uploadID,
+	})
+}
+
+func (o *Obs) CompleteMultipart(key, uploadID string, parts []Part) error {
+	client, err := NewHuaWeiObsClient()
+	if err != nil {
+		return errors.Wrap(err, "获取华为对象存储对象失败!")
+	}
+	input := &obs.CompleteMultipartUploadInput{
+		Bucket:   global.GVA_CONFIG.HuaWeiObs.Bucket,
+		Key:      key,
+		UploadId: uploadID,
+	}
+	for _, p := range parts {
+		input.Parts = append(input.Parts, obs.Part{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	if _, err = client.CompleteMultipartUpload(input); err != nil {
+		return errors.Wrap(err, "合并分片失败!")
+	}
+	return nil
+}
+
+func (o *Obs) AbortMultipart(key, uploadID string) error {
+	client, err := NewHuaWeiObsClient()
+	if err != nil {
+		return errors.Wrap(err, "获取华为对象存储对象失败!")
+	}
+	_, err = client.AbortMultipartUpload(&obs.AbortMultipartUploadInput{
+		Bucket:   global.GVA_CONFIG.HuaWeiObs.Bucket,
+		Key:      key,
+		UploadId: uploadID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "取消分片上传失败!")
+	}
+	return nil
+}
+
+func (o *Obs) presign(method obs.HttpMethodType, key string, expire time.Duration, query map[string]string) (string, error) {
+	client, err := NewHuaWeiObsClient()
+	if err != nil {
+		return "", errors.Wrap(err, "获取华为对象存储对象失败!")
+	}
+	output, err := client.CreateSignedUrl(&obs.CreateSignedUrlInput{
+		Method:      method,
+		Bucket:      global.GVA_CONFIG.HuaWeiObs.Bucket,
+		Key:         key,
+		Expires:     int(expire.Seconds()),
+		QueryParams: query,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "生成签名地址失败!")
+	}
+	return output.SignedUrl, nil
+}
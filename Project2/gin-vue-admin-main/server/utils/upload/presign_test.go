@@ -0,0 +1,99 @@
+package upload
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flipped-aurora/gin-vue-admin/server/global"
+)
+
+func setSigningKey(t *testing.T) {
+	t.Helper()
+	jwt, local, prefix := global.GVA_CONFIG.JWT, global.GVA_CONFIG.Local, global.GVA_CONFIG.System.RouterPrefix
+	t.Cleanup(func() {
+		global.GVA_CONFIG.JWT, global.GVA_CONFIG.Local, global.GVA_CONFIG.System.RouterPrefix = jwt, local, prefix
+	})
+	global.GVA_CONFIG.JWT.SigningKey = "test-signing-key"
+	global.GVA_CONFIG.Local.Path = "uploads/file"
+	global.GVA_CONFIG.Local.StorePath = t.TempDir()
+	global.GVA_CONFIG.System.RouterPrefix = ""
+}
+
+func TestVerifySign(t *testing.T) {
+	setSigningKey(t)
+	expires := time.Now().Add(time.Minute).Unix()
+	sign := Sign(expires, "presign", "a.png", "1024", "1")
+	tests := []struct {
+		name    string
+		sign    string
+		expires int64
+		fields  []string
+		wantErr string
+	}{
+		{name: "签名有效", sign: sign, expires: expires, fields: []string{"presign", "a.png", "1024", "1"}},
+		{name: "篡改路径", sign: sign, expires: expires, fields: []string{"presign", "b.png", "1024", "1"}, wantErr: "签名校验失败"},
+		{name: "篡改大小", sign: sign, expires: expires, fields: []string{"presign", "a.png", "2048", "1"}, wantErr: "签名校验失败"},
+		{name: "其他用户", sign: sign, expires: expires, fields: []string{"presign", "a.png", "1024", "2"}, wantErr: "签名校验失败"},
+		{name: "篡改过期时间", sign: sign, expires: expires + 3600, fields: []string{"presign", "a.png", "1024", "1"}, wantErr: "签名校验失败"},
+		{name: "移动字段边界", sign: sign, expires: expires, fields: []string{"presign", "a.png\n1024", "1"}, wantErr: "签名校验失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySign(tt.sign, tt.expires, tt.fields...)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("VerifySign() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	past := time.Now().Add(-time.Second).Unix()
+	if err := VerifySign(Sign(past, "presign", "a.png"), past, "presign", "a.png"); err == nil || err.Error() != "签名已过期" {
+		t.Fatalf("expired signature should be rejected: %v", err)
+	}
+	global.GVA_CONFIG.JWT.SigningKey = "another-key"
+	if err := VerifySign(sign, expires, "presign", "a.png", "1024", "1"); err == nil {
+		t.Fatal("signature made with another key should be rejected")
+	}
+}
+
+func TestVerifyLocalSign(t *testing.T) {
+	setSigningKey(t)
+	obj, err := (&Local{}).PresignPut("a.png", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(obj.UploadUrl)
+	if err != nil || u.Path != LocalSignedPath {
+		t.Fatalf("unexpected upload url %q: %v", obj.UploadUrl, err)
+	}
+	query := u.Query()
+	key, sign := query.Get("key"), query.Get("sign")
+	expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if key != obj.Key || !strings.HasSuffix(obj.Url, "/"+key) {
+		t.Fatalf("upload url should sign the object key: %+v", obj)
+	}
+	if err = VerifyLocalSign(http.MethodPut, key, expires, sign); err != nil {
+		t.Fatalf("signed upload url should verify: %v", err)
+	}
+	if err = VerifyLocalSign(http.MethodGet, key, expires, sign); err == nil {
+		t.Fatal("upload signature should not allow downloads")
+	}
+	if err = VerifyLocalSign(http.MethodPut, "other_"+key, expires, sign); err == nil {
+		t.Fatal("signature should not be valid for another key")
+	}
+	if err = VerifyLocalSign(http.MethodPut, "../"+key, expires, Sign(expires, http.MethodPut, "../"+key)); err == nil {
+		t.Fatal("key escaping the store path should be rejected even when signed")
+	}
+
+	expired := time.Now().Add(-time.Second).Unix()
+	if err = VerifyLocalSign(http.MethodGet, key, expired, Sign(expired, http.MethodGet, key)); err == nil {
+		t.Fatal("expired download url should be rejected")
+	}
+	if _, err = (&Local{}).PresignGet("a/../b", time.Minute); err == nil {
+		t.Fatal("PresignGet() should reject an illegal key")
+	}
+}
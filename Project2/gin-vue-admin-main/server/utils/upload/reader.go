@@ -0,0 +1,126 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader 可直接读取对象内容的存储
+type Reader interface {
+	Open(key string) (io.ReadCloser, error)
+}
+
+// Stater 可直接查询对象大小的存储
+type Stater interface {
+	Stat(key string) (int64, error)
+}
+
+// presignReadExpire 服务端读取对象时签名地址的有效期
+const presignReadExpire = 10 * time.Minute
+
+// OpenObject 读取对象内容 优先使用存储自身的 Reader 其次通过 Presigner 生成的下载地址读取
+func OpenObject(oss OSS, key string) (io.ReadCloser, error) {
+	if r, ok := oss.(Reader); ok {
+		return r.Open(key)
+	}
+	p, ok := oss.(Presigner)
+	if !ok {
+		return nil, errors.New("当前存储不支持读取对象")
+	}
+	u, err := p.PresignGet(key, presignReadExpire)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("读取对象失败, status: %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// StatObject 读取对象大小与开头最多 n 字节的内容 用于校验客户端直传的文件
+// 优先使用存储自身的 Stater 与 Reader 其次通过 Presigner 生成的下载地址按 Range 读取
+func StatObject(oss OSS, key string, n int) (size int64, head []byte, err error) {
+	if s, ok := oss.(Stater); ok {
+		if size, err = s.Stat(key); err != nil {
+			return 0, nil, err
+		}
+		r, err := OpenObject(oss, key)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer r.Close()
+		head, err = io.ReadAll(io.LimitReader(r, int64(n)))
+		return size, head, err
+	}
+	p, ok := oss.(Presigner)
+	if !ok {
+		return 0, nil, errors.New("当前存储不支持读取对象")
+	}
+	u, err := p.PresignGet(key, presignReadExpire)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", n-1))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Content-Range: bytes 0-511/12345
+		contentRange := resp.Header.Get("Content-Range")
+		i := strings.LastIndex(contentRange, "/")
+		if i < 0 {
+			return 0, nil, fmt.Errorf("无法解析 Content-Range: %q", contentRange)
+		}
+		if size, err = strconv.ParseInt(contentRange[i+1:], 10, 64); err != nil {
+			return 0, nil, fmt.Errorf("无法解析 Content-Range: %q", contentRange)
+		}
+	case http.StatusOK:
+		size = resp.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, nil, nil // 空对象
+	default:
+		return 0, nil, fmt.Errorf("读取对象失败, status: %d", resp.StatusCode)
+	}
+	head, err = io.ReadAll(io.LimitReader(resp.Body, int64(n)))
+	return size, head, err
+}
+
+// Stat 查询本地存储的文件大小
+func (l *Local) Stat(key string) (int64, error) {
+	p, err := l.ObjectPath(key)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Open 读取本地存储的文件
+func (l *Local) Open(key string) (io.ReadCloser, error) {
+	p, err := l.ObjectPath(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
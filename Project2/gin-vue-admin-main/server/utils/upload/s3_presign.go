@@ -0,0 +1,77 @@
+package upload
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3协议通用的预签名与分片操作 AwsS3 与 CloudflareR2 共用
+
+func s3PresignPut(svc *s3.S3, bucket, object string, expire time.Duration) (string, error) {
+	req, _ := svc.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	})
+	return req.Presign(expire)
+}
+
+func s3PresignGet(svc *s3.S3, bucket, object string, expire time.Duration) (string, error) {
+	req, _ := svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	})
+	return req.Presign(expire)
+}
+
+func s3InitiateMultipart(svc *s3.S3, bucket, object, contentType string) (string, error) {
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	out, err := svc.CreateMultipartUpload(input)
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.UploadId), nil
+}
+
+func s3PresignPart(svc *s3.S3, bucket, object, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	req, _ := svc.UploadPartRequest(&s3.UploadPartInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(object),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int64(int64(partNumber)),
+	})
+	return req.Presign(expire)
+}
+
+func s3CompleteMultipart(svc *s3.S3, bucket, object, uploadID string, parts []Part) error {
+	completed := make([]*s3.CompletedPart, 0, len(parts))
+	for _, p := range parts {
+		completed = append(completed, &s3.CompletedPart{
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int64(int64(p.PartNumber)),
+		})
+	}
+	_, err := svc.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(object),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+	return err
+}
+
+func s3AbortMultipart(svc *s3.S3, bucket, object, uploadID string) error {
+	_, err := svc.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(object),
+		UploadId: aws.String(uploadID),
+	})
+	return err
+}
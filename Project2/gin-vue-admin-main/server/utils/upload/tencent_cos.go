@@ -7,6 +7,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/flipped-aurora/gin-vue-admin/server/global"
@@ -59,3 +60,74 @@ func NewClient() *cos.Client {
 	})
 	return client
 }
+
+// PresignPut 生成直传签名地址 key 规则与 UploadFile 一致
+func (t *TencentCOS) PresignPut(filename string, expire time.Duration) (obj PresignedObject, err error) {
+	obj.Key, obj.Url = t.object(filename)
+	u, err := t.presign(http.MethodPut, obj.Key, expire, nil)
+	if err != nil {
+		return obj, err
+	}
+	obj.UploadUrl = u
+	return obj, nil
+}
+
+func (t *TencentCOS) PresignGet(key string, expire time.Duration) (string, error) {
+	return t.presign(http.MethodGet, key, expire, nil)
+}
+
+func (t *TencentCOS) InitiateMultipart(filename, contentType string) (obj PresignedObject, uploadID string, err error) {
+	obj.Key, obj.Url = t.object(filename)
+	var opt *cos.InitiateMultipartUploadOptions
+	if contentType != "" {
+		opt = &cos.InitiateMultipartUploadOptions{ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{ContentType: contentType}}
+	}
+	result, _, err := NewClient().Object.InitiateMultipartUpload(context.Background(), t.objectName(obj.Key), opt)
+	if err != nil {
+		return obj, "", err
+	}
+	return obj, result.UploadID, nil
+}
+
+func (t *TencentCOS) PresignPart(key, uploadID string, partNumber int, expire time.Duration) (string, error) {
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(partNumber))
+	query.Set("uploadId", uploadID)
+	return t.presign(http.MethodPut, key, expire, &query)
+}
+
+func (t *TencentCOS) CompleteMultipart(key, uploadID string, parts []Part) error {
+	opt := &cos.CompleteMultipartUploadOptions{}
+	for _, p := range parts {
+		opt.Parts = append(opt.Parts, cos.Object{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	_, _, err := NewClient().Object.CompleteMultipartUpload(context.Background(), t.objectName(key), uploadID, opt)
+	return err
+}
+
+func (t *TencentCOS) AbortMultipart(key, uploadID string) error {
+	_, err := NewClient().Object.AbortMultipartUpload(context.Background(), t.objectName(key), uploadID)
+	return err
+}
+
+func (t *TencentCOS) presign(method, key string, expire time.Duration, query *url.Values) (string, error) {
+	var opt *cos.PresignedURLOptions
+	if query != nil {
+		opt = &cos.PresignedURLOptions{Query: query}
+	}
+	u, err := NewClient().Object.GetPresignedURL(context.Background(), method, t.objectName(key),
+		global.GVA_CONFIG.TencentCOS.SecretID, global.GVA_CONFIG.TencentCOS.SecretKey, expire, opt)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (t *TencentCOS) object(filename string) (key string, fileUrl string) {
+	key = fmt.Sprintf("%d%s", time.Now().Unix(), filename)
+	return key, global.GVA_CONFIG.TencentCOS.BaseURL + "/" + t.objectName(key)
+}
+
+func (*TencentCOS) objectName(key string) string {
+	return global.GVA_CONFIG.TencentCOS.PathPrefix + "/" + key
+}
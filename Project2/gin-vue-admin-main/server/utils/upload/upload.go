@@ -18,7 +18,17 @@ type OSS interface {
 // Author [SliverHorn](https://github.com/SliverHorn)
 // Author [ccfish86](https://github.com/ccfish86)
 func NewOss() OSS {
-	switch global.GVA_CONFIG.System.OssType {
+	oss := NewOssByType(global.GVA_CONFIG.System.OssType)
+	mirror := global.GVA_CONFIG.System.OssMirror
+	if mirror != "" && mirror != global.GVA_CONFIG.System.OssType {
+		return &Mirror{Primary: oss, Secondary: NewOssByType(mirror)}
+	}
+	return oss
+}
+
+// NewOssByType 按类型实例化OSS 用于存储迁移等需要同时操作多个存储的场景
+func NewOssByType(ossType string) OSS {
+	switch ossType {
 	case "local":
 		return &Local{}
 	case "qiniu":
@@ -37,3 +47,6 @@ func NewOss() OSS {
 		return &Local{}
 	}
 }
+
+// OssTypes 支持的OSS类型
+var OssTypes = []string{"local", "qiniu", "tencent-cos", "aliyun-oss", "huawei-obs", "aws-s3", "cloudflare-r2"}